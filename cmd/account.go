@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"zonekit/pkg/config"
+	"zonekit/pkg/render"
 )
 
 // accountCmd represents the account command
@@ -21,15 +23,32 @@ var accountListCmd = &cobra.Command{
 	Short: "List all configured accounts",
 	Long:  `Display all configured DNS provider accounts and show which one is currently active.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
 
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
 		accounts := configManager.ListAccounts()
+		if output.Structured() {
+			views := make([]accountView, 0, len(accounts))
+			for _, accountName := range accounts {
+				account, err := configManager.GetAccount(accountName)
+				if err != nil {
+					return fmt.Errorf("failed to load account '%s': %w", accountName, err)
+				}
+				views = append(views, newAccountView(accountName, accountName == configManager.GetCurrentAccountName(), account))
+			}
+			return render.Encode(os.Stdout, output, views)
+		}
+
 		if len(accounts) == 0 {
-			fmt.Println("No accounts configured.")
-			fmt.Println("Run 'zonekit account add' to add your first account.")
+			statusln("No accounts configured.")
+			statusln("Run 'zonekit account add' to add your first account.")
 			return nil
 		}
 
@@ -38,14 +57,14 @@ var accountListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get current account: %w", err)
 		}
 
-		fmt.Println("Configured Accounts:")
-		fmt.Println("====================")
-		fmt.Println()
+		statusln("Configured Accounts:")
+		statusln("====================")
+		statusln()
 
 		for _, accountName := range accounts {
 			account, err := configManager.GetAccount(accountName)
 			if err != nil {
-				fmt.Printf("⚠️  %s: Error loading account details\n", accountName)
+				statusf("⚠️  %s: Error loading account details\n", accountName)
 				continue
 			}
 
@@ -60,6 +79,9 @@ var accountListCmd = &cobra.Command{
 			fmt.Printf("   API User: %s\n", account.APIUser)
 			fmt.Printf("   Client IP: %s\n", account.ClientIP)
 			fmt.Printf("   Sandbox: %t\n", account.UseSandbox)
+			if account.ProductionGuard {
+				fmt.Printf("   Production Guard: %t\n", account.ProductionGuard)
+			}
 			if account.Description != "" {
 				fmt.Printf("   Description: %s\n", account.Description)
 			}
@@ -77,7 +99,7 @@ var accountAddCmd = &cobra.Command{
 	Long:  `Add a new DNS provider account configuration with an interactive prompt.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
@@ -93,31 +115,48 @@ var accountAddCmd = &cobra.Command{
 			return fmt.Errorf("account '%s' already exists", accountName)
 		}
 
-		fmt.Printf("Adding new account: %s\n", accountName)
-		fmt.Println("================================")
-		fmt.Println()
+		statusf("Adding new account: %s\n", accountName)
+		statusln("================================")
+		statusln()
 
 		// Interactive input
 		account := &config.AccountConfig{}
 
-		fmt.Print("Provider Username: ")
-		fmt.Scanln(&account.Username)
+		// The account's provider can be given with --provider instead of the prompt
+		account.Provider, _ = cmd.Flags().GetString("provider")
+		if account.Provider == "" {
+			statusf("Provider [%s]: ", config.DefaultProvider)
+			fmt.Scanln(&account.Provider)
+		}
 
-		fmt.Print("API User: ")
-		fmt.Scanln(&account.APIUser)
+		// Other providers take their credentials from the environment or the providers section
+		if account.UsesNamecheap() {
+			statusf("Provider Username: ")
+			fmt.Scanln(&account.Username)
 
-		fmt.Print("API Key: ")
-		fmt.Scanln(&account.APIKey)
+			statusf("API User: ")
+			fmt.Scanln(&account.APIUser)
 
-		fmt.Print("Client IP Address: ")
-		fmt.Scanln(&account.ClientIP)
+			statusf("API Key: ")
+			fmt.Scanln(&account.APIKey)
 
-		var sandboxInput string
-		fmt.Print("Use Sandbox Environment? (y/N): ")
-		fmt.Scanln(&sandboxInput)
-		account.UseSandbox = strings.ToLower(sandboxInput) == "y" || strings.ToLower(sandboxInput) == "yes"
+			statusf("Client IP Address: ")
+			fmt.Scanln(&account.ClientIP)
 
-		fmt.Print("Description (optional): ")
+			var sandboxInput string
+			statusf("Use Sandbox Environment? (y/N): ")
+			fmt.Scanln(&sandboxInput)
+			account.UseSandbox = strings.ToLower(sandboxInput) == "y" || strings.ToLower(sandboxInput) == "yes"
+		}
+
+		if !account.UseSandbox {
+			var guardInput string
+			statusf("Require confirmation for destructive operations? (y/N): ")
+			fmt.Scanln(&guardInput)
+			account.ProductionGuard = strings.ToLower(guardInput) == "y" || strings.ToLower(guardInput) == "yes"
+		}
+
+		statusf("Description (optional): ")
 		fmt.Scanln(&account.Description)
 
 		// Validate account
@@ -130,17 +169,17 @@ var accountAddCmd = &cobra.Command{
 			return fmt.Errorf("failed to add account: %w", err)
 		}
 
-		fmt.Printf("✅ Account '%s' added successfully!\n", accountName)
+		statusf("✅ Account '%s' added successfully!\n", accountName)
 
 		// Ask if user wants to switch to this account
 		var switchInput string
-		fmt.Printf("Switch to account '%s'? (Y/n): ", accountName)
+		statusf("Switch to account '%s'? (Y/n): ", accountName)
 		fmt.Scanln(&switchInput)
 		if switchInput == "" || strings.ToLower(switchInput) == "y" || strings.ToLower(switchInput) == "yes" {
 			if err := configManager.SetCurrentAccount(accountName); err != nil {
 				return fmt.Errorf("failed to switch to account '%s': %w", accountName, err)
 			}
-			fmt.Printf("✅ Switched to account '%s'\n", accountName)
+			statusf("✅ Switched to account '%s'\n", accountName)
 		}
 
 		return nil
@@ -156,7 +195,7 @@ var accountSwitchCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
 
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
@@ -166,14 +205,9 @@ var accountSwitchCmd = &cobra.Command{
 			return fmt.Errorf("account '%s' not found: %w", accountName, err)
 		}
 
-		// Get current account for comparison
-		currentAccount, err := configManager.GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get current account: %w", err)
-		}
-
-		if accountName == configManager.GetCurrentAccountName() {
-			fmt.Printf("Already using account '%s'\n", accountName)
+		previousAccount := configManager.GetCurrentAccountName()
+		if accountName == previousAccount {
+			statusf("Already using account '%s'\n", accountName)
 			return nil
 		}
 
@@ -182,7 +216,7 @@ var accountSwitchCmd = &cobra.Command{
 			return fmt.Errorf("failed to switch to account '%s': %w", accountName, err)
 		}
 
-		fmt.Printf("✅ Switched from account '%s' to '%s'\n", currentAccount.Username, accountName)
+		statusf("✅ Switched from account '%s' to '%s'\n", previousAccount, accountName)
 		return nil
 	},
 }
@@ -196,7 +230,7 @@ var accountRemoveCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
 
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
@@ -206,18 +240,14 @@ var accountRemoveCmd = &cobra.Command{
 			return fmt.Errorf("account '%s' not found: %w", accountName, err)
 		}
 
-		// Get current account for comparison
-		currentAccount, err := configManager.GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get current account: %w", err)
-		}
+		previousAccount := configManager.GetCurrentAccountName()
 
 		// Confirm removal
-		fmt.Printf("Are you sure you want to remove account '%s'? (y/N): ", accountName)
+		statusf("Are you sure you want to remove account '%s'? (y/N): ", accountName)
 		var confirm string
 		fmt.Scanln(&confirm)
 		if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
-			fmt.Println("Aborted.")
+			statusln("Aborted.")
 			return nil
 		}
 
@@ -226,20 +256,52 @@ var accountRemoveCmd = &cobra.Command{
 			return fmt.Errorf("failed to remove account '%s': %w", accountName, err)
 		}
 
-		fmt.Printf("✅ Account '%s' removed successfully!\n", accountName)
+		statusf("✅ Account '%s' removed successfully!\n", accountName)
 
 		// Show new current account if it changed
-		if accountName == currentAccount.Username {
-			newCurrent, err := configManager.GetCurrentAccount()
-			if err == nil {
-				fmt.Printf("Switched to account '%s'\n", newCurrent.Username)
-			}
+		if currentAccount := configManager.GetCurrentAccountName(); currentAccount != previousAccount {
+			statusf("Switched to account '%s'\n", currentAccount)
 		}
 
 		return nil
 	},
 }
 
+// accountExportCmd represents the account export command
+var accountExportCmd = &cobra.Command{
+	Use:   "export <account-name>",
+	Short: "Export an account configuration",
+	Long: `Print an account as a config file fragment, e.g. to back it up or copy it
+to another machine. The fragment can be merged into the accounts section of
+another config file.
+
+The API key is redacted unless --with-secrets is given. Secret references such
+as env:NC_API_KEY or vault:secret/dns#api_key are always exported as they are.`,
+	Example: `  zonekit account export work > work-account.yaml
+  zonekit account export work --with-secrets > work-account.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+
+		configManager, err := GetConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to create config manager: %w", err)
+		}
+
+		withSecrets, _ := cmd.Flags().GetBool("with-secrets")
+		data, err := configManager.ExportAccount(accountName, withSecrets)
+		if err != nil {
+			return err
+		}
+
+		if withSecrets {
+			fmt.Fprintln(os.Stderr, "Warning: the export contains the account's API key; keep it private")
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
 // accountShowCmd represents the account show command
 var accountShowCmd = &cobra.Command{
 	Use:   "show [account-name]",
@@ -247,7 +309,7 @@ var accountShowCmd = &cobra.Command{
 	Long:  `Display detailed information about a specific DNS provider account configuration.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
@@ -266,6 +328,14 @@ var accountShowCmd = &cobra.Command{
 			return fmt.Errorf("account '%s' not found: %w", accountName, err)
 		}
 
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, newAccountView(accountName, accountName == configManager.GetCurrentAccountName(), account))
+		}
+
 		// Display account details
 		fmt.Printf("Account: %s\n", accountName)
 		if accountName == configManager.GetCurrentAccountName() {
@@ -273,14 +343,15 @@ var accountShowCmd = &cobra.Command{
 		} else {
 			fmt.Println("Status: Inactive")
 		}
-		fmt.Println("========================")
-		fmt.Println()
+		statusln("========================")
+		statusln()
 
 		fmt.Printf("Username: %s\n", account.Username)
 		fmt.Printf("API User: %s\n", account.APIUser)
 		fmt.Printf("API Key: %s\n", config.MaskAPIKey(account.APIKey))
 		fmt.Printf("Client IP: %s\n", account.ClientIP)
 		fmt.Printf("Sandbox: %t\n", account.UseSandbox)
+		fmt.Printf("Production Guard: %t\n", account.ProductionGuard)
 		if account.Description != "" {
 			fmt.Printf("Description: %s\n", account.Description)
 		}
@@ -296,7 +367,7 @@ var accountEditCmd = &cobra.Command{
 	Long:  `Edit an existing DNS provider account configuration with an interactive prompt.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewManager()
+		configManager, err := GetConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to create config manager: %w", err)
 		}
@@ -309,65 +380,86 @@ var accountEditCmd = &cobra.Command{
 			accountName = configManager.GetCurrentAccountName()
 		}
 
-		// Get existing account
-		existingAccount, err := configManager.GetAccount(accountName)
+		// Get the account as stored: environment overrides must not be saved
+		existingAccount, err := configManager.GetAccountRaw(accountName)
 		if err != nil {
 			return fmt.Errorf("account '%s' not found: %w", accountName, err)
 		}
 
-		fmt.Printf("Editing account: %s\n", accountName)
-		fmt.Println("================================")
-		fmt.Println()
+		statusf("Editing account: %s\n", accountName)
+		statusln("================================")
+		statusln()
 
 		// Interactive input with current values as defaults
 		account := &config.AccountConfig{}
 
-		fmt.Printf("Provider Username [%s]: ", existingAccount.Username)
 		var input string
+		statusf("Provider [%s]: ", existingAccount.GetProvider())
 		fmt.Scanln(&input)
 		if input != "" {
-			account.Username = input
+			account.Provider = input
 		} else {
-			account.Username = existingAccount.Username
+			account.Provider = existingAccount.Provider
 		}
 
-		fmt.Printf("API User [%s]: ", existingAccount.APIUser)
-		fmt.Scanln(&input)
-		if input != "" {
-			account.APIUser = input
-		} else {
-			account.APIUser = existingAccount.APIUser
-		}
+		// Other providers take their credentials from the environment or the providers section
+		if account.UsesNamecheap() {
+			input = ""
+			statusf("Provider Username [%s]: ", existingAccount.Username)
+			fmt.Scanln(&input)
+			if input != "" {
+				account.Username = input
+			} else {
+				account.Username = existingAccount.Username
+			}
 
-		masked := existingAccount.APIKey
-		if len(existingAccount.APIKey) > 4 {
-			masked = existingAccount.APIKey[:4]
-		}
-		fmt.Printf("API Key [%s***]: ", masked)
-		fmt.Scanln(&input)
-		if input != "" {
-			account.APIKey = input
-		} else {
-			account.APIKey = existingAccount.APIKey
-		}
+			statusf("API User [%s]: ", existingAccount.APIUser)
+			fmt.Scanln(&input)
+			if input != "" {
+				account.APIUser = input
+			} else {
+				account.APIUser = existingAccount.APIUser
+			}
 
-		fmt.Printf("Client IP Address [%s]: ", existingAccount.ClientIP)
-		fmt.Scanln(&input)
-		if input != "" {
-			account.ClientIP = input
-		} else {
-			account.ClientIP = existingAccount.ClientIP
+			masked := existingAccount.APIKey
+			if len(existingAccount.APIKey) > 4 {
+				masked = existingAccount.APIKey[:4]
+			}
+			statusf("API Key [%s***]: ", masked)
+			fmt.Scanln(&input)
+			if input != "" {
+				account.APIKey = input
+			} else {
+				account.APIKey = existingAccount.APIKey
+			}
+
+			statusf("Client IP Address [%s]: ", existingAccount.ClientIP)
+			fmt.Scanln(&input)
+			if input != "" {
+				account.ClientIP = input
+			} else {
+				account.ClientIP = existingAccount.ClientIP
+			}
+
+			statusf("Use Sandbox Environment? [%t] (y/N): ", existingAccount.UseSandbox)
+			fmt.Scanln(&input)
+			if input != "" {
+				account.UseSandbox = strings.ToLower(input) == "y" || strings.ToLower(input) == "yes"
+			} else {
+				account.UseSandbox = existingAccount.UseSandbox
+			}
 		}
 
-		fmt.Printf("Use Sandbox Environment? [%t] (y/N): ", existingAccount.UseSandbox)
+		statusf("Require confirmation for destructive operations? [%t] (y/N): ", existingAccount.ProductionGuard)
+		input = ""
 		fmt.Scanln(&input)
 		if input != "" {
-			account.UseSandbox = strings.ToLower(input) == "y" || strings.ToLower(input) == "yes"
+			account.ProductionGuard = strings.ToLower(input) == "y" || strings.ToLower(input) == "yes"
 		} else {
-			account.UseSandbox = existingAccount.UseSandbox
+			account.ProductionGuard = existingAccount.ProductionGuard
 		}
 
-		fmt.Printf("Description [%s]: ", existingAccount.Description)
+		statusf("Description [%s]: ", existingAccount.Description)
 		fmt.Scanln(&input)
 		if input != "" {
 			account.Description = input
@@ -385,7 +477,7 @@ var accountEditCmd = &cobra.Command{
 			return fmt.Errorf("failed to update account: %w", err)
 		}
 
-		fmt.Printf("✅ Account '%s' updated successfully!\n", accountName)
+		statusf("✅ Account '%s' updated successfully!\n", accountName)
 		return nil
 	},
 }
@@ -398,4 +490,7 @@ func init() {
 	accountCmd.AddCommand(accountRemoveCmd)
 	accountCmd.AddCommand(accountShowCmd)
 	accountCmd.AddCommand(accountEditCmd)
+	accountCmd.AddCommand(accountExportCmd)
+
+	accountExportCmd.Flags().Bool("with-secrets", false, "Include the API key instead of redacting it")
 }
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/internal/cmdutil"
+	"zonekit/pkg/challenge"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/secret"
+)
+
+// acmeCmd represents the acme command
+var acmeCmd = &cobra.Command{
+	Use:   "acme",
+	Short: "Help ACME clients solve DNS-01 challenges",
+	Long:  `Commands that let ACME clients such as reverse proxies publish DNS-01 challenge records through zonekit.`,
+}
+
+// acmeServeCmd represents the acme serve command
+var acmeServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP endpoint that creates and removes ACME challenge records",
+	Long: `Serve the HTTP protocol of lego's httpreq DNS provider, so reverse proxies that
+obtain certificates with lego, such as Traefik, can publish their DNS-01
+challenge records through the account's DNS provider or any --provider:
+
+  POST /present  {"fqdn": "_acme-challenge.example.com.", "value": "..."}
+  POST /cleanup  {"fqdn": "_acme-challenge.example.com.", "value": "..."}
+
+lego's raw mode ({"domain", "token", "keyAuth"}) is accepted as well. Only
+_acme-challenge TXT records in the served zones can be written; the zones are
+those given with --zone, or every zone the provider lists. Other TXT values at
+the same name are kept, so a certificate for a domain and its wildcard works.
+
+The endpoint listens on 127.0.0.1 by default. Anyone who can write challenge
+records can get certificates issued for the zones, so listening on any other
+address requires --username and --password for HTTP basic auth.
+
+Point the client at the endpoint, e.g. for Traefik:
+
+  HTTPREQ_ENDPOINT=http://zonekit:8053 HTTPREQ_USERNAME=traefik HTTPREQ_PASSWORD=...
+
+Examples:
+  zonekit acme serve --zone example.com
+  zonekit acme serve --listen 0.0.0.0:8053 --username traefik --password env:HTTPREQ_PASSWORD
+  zonekit acme serve --provider cloudflare-home --zone home.example.com`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, _ := cmd.Flags().GetString("listen")
+		zones, _ := cmd.Flags().GetStringArray("zone")
+		providerName, _ := cmd.Flags().GetString("provider")
+		username, _ := cmd.Flags().GetString("username")
+		password, _ := cmd.Flags().GetString("password")
+		ttl, _ := cmd.Flags().GetInt("ttl")
+
+		for _, zone := range zones {
+			if err := dns.ValidateDomain(zone); err != nil {
+				return fmt.Errorf("invalid zone %s: %w", zone, err)
+			}
+		}
+		password, err := secret.Resolve(password)
+		if err != nil {
+			return fmt.Errorf("failed to resolve --password: %w", err)
+		}
+		if (username == "") != (password == "") {
+			return fmt.Errorf("--username and --password must be set together")
+		}
+		if username == "" && !isLoopbackListen(listen) {
+			return fmt.Errorf("--username and --password are required to serve challenges on %s: anyone who can reach the endpoint could get certificates issued for the zones", listen)
+		}
+
+		newService, err := requestDNSService(cmd, args, providerName)
+		if err != nil {
+			return err
+		}
+		if ttl == 0 {
+			ttl = newService().DefaultTTL()
+		}
+		if len(zones) == 0 {
+			zones, err = newService().ListZones()
+			if err != nil {
+				return fmt.Errorf("failed to list zones, pass them with --zone: %w", err)
+			}
+			if len(zones) == 0 {
+				return fmt.Errorf("the provider has no zones to serve")
+			}
+		}
+
+		handler := challenge.New(challenge.Config{
+			Zones:    zones,
+			NewStore: func() challenge.RecordStore { return newService() },
+			TTL:      ttl,
+			Username: username,
+			Password: password,
+			Log:      os.Stderr,
+		})
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &http.Server{Addr: listen, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- server.ListenAndServe()
+		}()
+		statusf("Serving ACME challenges for %s on http://%s\n", strings.Join(zones, ", "), displayAddr(listen))
+		if username == "" {
+			fmt.Fprintln(os.Stderr, "Warning: no --username and --password set; every local user and process can write challenge records")
+		}
+
+		select {
+		case err := <-errCh:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve ACME challenges: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		}
+	},
+}
+
+// isLoopbackListen reports whether a listen address only accepts connections
+// from the local host. An empty host listens on every interface.
+func isLoopbackListen(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requestDNSService returns a function creating the DNS service for one request,
+// from the named provider or the current account. Each request gets its own
+// service so records cached by an earlier request are not reused.
+func requestDNSService(cmd *cobra.Command, args []string, providerName string) (func() *dns.Service, error) {
+	if providerName != "" {
+		if _, err := newProviderDNSService(cmd, args, providerName); err != nil {
+			return nil, err
+		}
+		return func() *dns.Service {
+			dnsService, _ := newProviderDNSService(cmd, args, providerName)
+			return dnsService
+		}, nil
+	}
+
+	// Get current account configuration
+	accountConfig, err := GetCurrentAccount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account configuration: %w", err)
+	}
+
+	if _, _, err := accountDNSService(cmd, args, accountConfig); err != nil {
+		return nil, err
+	}
+	cmdutil.DisplayAccountInfo(accountConfig)
+
+	return func() *dns.Service {
+		dnsService, _, _ := accountDNSService(cmd, args, accountConfig)
+		return dnsService
+	}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(acmeCmd)
+	acmeCmd.AddCommand(acmeServeCmd)
+
+	acmeServeCmd.Flags().String("listen", "127.0.0.1:8053", "Address to serve the endpoint on; other than loopback, basic auth is required")
+	acmeServeCmd.Flags().StringArray("zone", nil, "Zone to serve challenges for (repeatable; default: every zone the provider lists)")
+	acmeServeCmd.Flags().String("username", "", "Require HTTP basic auth with this username")
+	acmeServeCmd.Flags().String("password", "", "Basic auth password, or a secret reference such as env:HTTPREQ_PASSWORD")
+	acmeServeCmd.Flags().Int("ttl", 0, "TTL of challenge records in seconds (default: the provider's)")
+}
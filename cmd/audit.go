@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/audit"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/history"
+	"zonekit/pkg/render"
+	"zonekit/pkg/secret"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of changes",
+	Long: `Inspect the audit log: every change zonekit makes, with the time, OS user,
+host, account, provider, domain and command. DNS record changes list each
+record before and after; registrar changes (nameservers, contacts, glue
+records, registrations and renewals) list the state before and after. Changes
+that failed are logged with their error.
+
+The log is an append-only JSON Lines file, ~/.zonekit/audit.jsonl unless
+audit.path is set in the config file; state prune does not touch it. With
+audit.webhook set, each entry is also posted as JSON to a URL as it is
+written, e.g. to ship it to a log collector; entries that cannot be shipped
+are still logged locally.`,
+}
+
+// auditListCmd represents the audit list command
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the logged changes, newest first",
+	Example: `  zonekit audit list
+  zonekit audit list --domain example.com --since 7d
+  zonekit audit list --account prod -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		filter := audit.Filter{}
+		filter.Domain, _ = cmd.Flags().GetString("domain")
+		// The global --account selects whose changes are listed
+		filter.Account = accountName
+		if sinceValue, _ := cmd.Flags().GetString("since"); sinceValue != "" {
+			window, err := history.ParseSince(sinceValue)
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+			filter.Since = time.Now().Add(-window)
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		log, err := openAuditLog()
+		if err != nil {
+			return err
+		}
+		entries, err := log.Read(filter)
+		if err != nil {
+			return err
+		}
+		// Newest first
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+
+		if len(entries) == 0 && !output.Structured() {
+			statusln("No changes logged.")
+			return nil
+		}
+
+		table := render.NewTable("ID", "TIME", "USER", "ACCOUNT", "DOMAIN", "OPERATION", "CHANGES", "COMMAND")
+		for _, entry := range entries {
+			changes := strconv.Itoa(len(entry.Changes))
+			if len(entry.Changes) == 0 {
+				changes = "-"
+			}
+			if failed := entry.Failed(); failed > 0 {
+				changes += fmt.Sprintf(" (%d failed)", failed)
+			}
+			command := entry.Command
+			if entry.Emergency {
+				command = "[emergency] " + command
+			}
+			table.AddRow(entry.ID, timeFormatter().Timestamp(entry.Time), entry.User, entry.Account, entry.Domain, entry.Operation, changes, command)
+		}
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		return writeOutput(output, table, entries)
+	},
+}
+
+// auditShowCmd represents the audit show command
+var auditShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a logged change with the state before and after",
+	Long: `Show a logged change set with each record, or the registrar state, before
+and after it. The ID is listed by audit list; a unique prefix of it is
+enough.`,
+	Example: `  zonekit audit show 3f2a9c1b
+  zonekit audit show 3f2a9c1b04de -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		log, err := openAuditLog()
+		if err != nil {
+			return err
+		}
+		entry, err := log.Find(args[0])
+		if err != nil {
+			return err
+		}
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, entry)
+		}
+
+		fmt.Printf("ID:        %s\n", entry.ID)
+		fmt.Printf("Time:      %s\n", timeFormatter().Timestamp(entry.Time))
+		who := entry.User
+		if entry.Host != "" {
+			who += "@" + entry.Host
+		}
+		if who != "" {
+			fmt.Printf("User:      %s\n", who)
+		}
+		if entry.Account != "" {
+			fmt.Printf("Account:   %s\n", entry.Account)
+		}
+		if entry.Provider != "" {
+			fmt.Printf("Provider:  %s\n", entry.Provider)
+		}
+		fmt.Printf("Domain:    %s\n", entry.Domain)
+		fmt.Printf("Operation: %s\n", entry.Operation)
+		if entry.Command != "" {
+			fmt.Printf("Command:   %s\n", entry.Command)
+		}
+		if entry.Emergency {
+			fmt.Println("Emergency: yes")
+		}
+		if len(entry.Changes) == 0 {
+			fmt.Printf("Before:    %s\n", auditStateText(entry.Before))
+			fmt.Printf("After:     %s\n", auditStateText(entry.After))
+			if entry.Error != "" {
+				fmt.Printf("Error:     %s\n", entry.Error)
+			}
+			return nil
+		}
+		fmt.Println()
+
+		table := render.NewTable("ACTION", "BEFORE", "AFTER", "ERROR")
+		for _, change := range entry.Changes {
+			table.AddRow(string(change.Action), auditRecordText(change.Before), auditRecordText(change.After), change.Error)
+		}
+		table.WriteText(os.Stdout)
+		return nil
+	},
+}
+
+// auditRecordText formats a logged record with its TTL, or "-" if there is none
+func auditRecordText(r *history.Record) string {
+	if r == nil {
+		return "-"
+	}
+	if r.TTL > 0 {
+		return fmt.Sprintf("%s (TTL %s)", r, dnsrecord.FormatTTL(r.TTL))
+	}
+	return r.String()
+}
+
+// auditStateText formats the logged state of a registrar change, or "-" if
+// there is none
+func auditStateText(state string) string {
+	if state == "" {
+		return "-"
+	}
+	return state
+}
+
+// openAuditLog opens the audit log configured in the config file, or the
+// default one
+func openAuditLog() (*audit.Log, error) {
+	path := audit.DefaultPath()
+	if configManager, err := GetConfigManager(); err == nil {
+		if configured := configManager.GetAudit().Path; configured != "" {
+			path = configured
+		}
+	}
+	if path == "" {
+		return nil, fmt.Errorf("audit log path is unknown: set audit.path in the config file")
+	}
+	return audit.NewLog(path), nil
+}
+
+// newAuditLogger creates the logger that records the changes made by cmd,
+// shipping them to the configured webhook, if any
+func newAuditLogger(cmd *cobra.Command, args []string) (*audit.Logger, error) {
+	log, err := openAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	command := strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))
+	logger := audit.NewLogger(log, GetCurrentAccountName(), command)
+	logger.Emergency = emergencyChange
+
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return logger, nil
+	}
+	if webhook := configManager.GetAudit().Webhook; webhook != nil && webhook.URL != "" {
+		headers := make(map[string]string, len(webhook.Headers))
+		for name, value := range webhook.Headers {
+			resolved, err := secret.Resolve(value)
+			if err != nil {
+				return nil, fmt.Errorf("audit webhook header %s: %w", name, err)
+			}
+			headers[name] = resolved
+		}
+		logger.Webhook = &audit.Webhook{URL: webhook.URL, Headers: headers}
+	}
+	return logger, nil
+}
+
+// auditRegistrar logs a registrar change made by cmd to domainName, or the
+// error it failed with. Failing to log it only prints a warning, as the change
+// itself has already been made.
+func auditRegistrar(cmd *cobra.Command, args []string, domainName, operation, before, after string, opErr error) {
+	logger, err := newAuditLogger(cmd, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s of %s was not audited: %v\n", operation, domainName, err)
+		return
+	}
+	// Registrar changes are made through the Namecheap API
+	entry := audit.Entry{Provider: "namecheap", Domain: domainName, Operation: operation, Before: before, After: after}
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+	if err := logger.Record(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s of %s: %v\n", operation, domainName, err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditShowCmd)
+
+	auditListCmd.Flags().String("domain", "", "Only changes to this domain")
+	auditListCmd.Flags().String("since", "", "Only changes in this period, e.g. 7d, 2w or 12h")
+	auditListCmd.Flags().Int("limit", 0, "Show at most this many changes (0 for all)")
+}
@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/internal/cmdutil"
+	"zonekit/pkg/backup"
+	"zonekit/pkg/diffview"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/render"
+)
+
+// dnsBackupCmd represents the dns backup command
+var dnsBackupCmd = &cobra.Command{
+	Use:   "backup <domain>",
+	Short: "Save a snapshot of a domain's records",
+	Long: `Save a timestamped snapshot of a domain's records under ~/.zonekit/backups,
+which dns restore puts back.
+
+zonekit also takes a snapshot automatically before every change it applies to
+a zone's records, which dns undo restores; the newest 50 of those are kept per
+domain. A snapshot is a desired-state file, so it can be applied with dns sync
+as well.`,
+	Example: `  zonekit dns backup example.com
+  zonekit dns backup example.com --reason "before the mail migration"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		reason, _ := cmd.Flags().GetString("reason")
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		// Use the account's provider, or the one selected with --provider
+		dnsService, _, err := resolveDNSService(cmd, args, domainName, true)
+		if err != nil {
+			return err
+		}
+		records, err := dnsService.GetRecords(domainName)
+		if err != nil {
+			return fmt.Errorf("failed to get DNS records: %w", err)
+		}
+
+		store := newBackupStore()
+		snapshot, err := store.Save(domainName, records, newSnapshot(dnsService, reason))
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		statusf("✅ Saved snapshot %s of %s (%d records) to %s\n", snapshot.ID, domainName, len(records), store.Path(domainName, snapshot.ID))
+		return nil
+	},
+}
+
+// dnsRestoreCmd represents the dns restore command
+var dnsRestoreCmd = &cobra.Command{
+	Use:   "restore <domain> <snapshot>",
+	Short: "Put a domain's records back as they were in a snapshot",
+	Long: `Make a domain's records match a snapshot taken with dns backup or before a
+destructive change. The snapshot is its ID (see dns backups list), latest for
+the newest snapshot of the domain, or the path of a snapshot file.
+
+The records to add, change and delete are previewed as a diff; with --confirm
+only those records are written, after a snapshot of the current records is
+taken, so a restore can be undone too. NS records at the apex are skipped, as
+the provider manages them.`,
+	Example: `  zonekit dns restore example.com latest
+  zonekit dns restore example.com 20240301T120000Z --confirm`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		id := args[1]
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		snapshot, records, err := newBackupStore().Load(domainName, id)
+		if err != nil {
+			return err
+		}
+		return restoreSnapshot(cmd, args, domainName, snapshot, records, snapshotRestore{
+			intro:   fmt.Sprintf("Restoring %s from snapshot %s taken %s", domainName, snapshot.ID, timeFormatter().Timestamp(snapshot.Time)),
+			action:  "restore DNS records from a snapshot",
+			done:    fmt.Sprintf("%s matches snapshot %s", domainName, snapshot.ID),
+			nothing: "Nothing to restore, the zone already matches the snapshot.",
+		})
+	},
+}
+
+// dnsUndoCmd represents the dns undo command
+var dnsUndoCmd = &cobra.Command{
+	Use:   "undo <domain>",
+	Short: "Revert the last change made to a domain's records",
+	Long: `Revert the most recent change zonekit applied to a domain's records, e.g. a
+bad dns bulk run, by restoring the snapshot taken automatically before it.
+
+The records to add, change and delete are previewed as a diff; with --confirm
+only those records are written. Changes made to the zone since, outside
+zonekit or on another machine, are reverted as well, so check the diff. Undoing
+is a change too: running dns undo again reverts the undo. To go back further,
+pick an older snapshot from dns backups list and use dns restore.
+
+This works across runs, unlike undo in an interactive session (zonekit shell),
+which only reverts changes made in that session.`,
+	Example: `  zonekit dns undo example.com
+  zonekit dns undo example.com --confirm`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		store := newBackupStore()
+		snapshots, err := store.List(domainName)
+		if err != nil {
+			return err
+		}
+		// Snapshots taken on demand do not mark a change
+		var last *backup.Snapshot
+		for i := range snapshots {
+			if snapshots[i].Automatic {
+				last = &snapshots[i]
+				break
+			}
+		}
+		if last == nil {
+			return fmt.Errorf("there is no change to %s to undo: no snapshot was taken before a change (see dns backups list)", domainName)
+		}
+		snapshot, records, err := store.Load(domainName, last.ID)
+		if err != nil {
+			return err
+		}
+
+		change := snapshot.Reason
+		if change == "" {
+			change = "the last change"
+		} else {
+			change = "`" + change + "`"
+		}
+		return restoreSnapshot(cmd, args, domainName, snapshot, records, snapshotRestore{
+			intro:   fmt.Sprintf("Undoing %s on %s, made %s (snapshot %s)", change, domainName, timeFormatter().Timestamp(snapshot.Time), snapshot.ID),
+			action:  "undo the last DNS change",
+			done:    fmt.Sprintf("Undid %s on %s", change, domainName),
+			nothing: "Nothing to undo, the zone already matches the records before the change.",
+		})
+	},
+}
+
+// snapshotRestore holds the messages of a command that restores a snapshot
+type snapshotRestore struct {
+	// intro heads the diff
+	intro string
+	// action is confirmed on accounts with production_guard enabled
+	action string
+	// done is printed once the records are restored
+	done string
+	// nothing is printed when the zone already matches the snapshot
+	nothing string
+}
+
+// restoreSnapshot previews, and with --confirm applies, the changes that make a
+// domain's records match a snapshot
+func restoreSnapshot(cmd *cobra.Command, args []string, domainName string, snapshot backup.Snapshot, records []dnsrecord.Record, messages snapshotRestore) error {
+	output, err := outputFormat()
+	if err != nil {
+		return err
+	}
+
+	// Use the account's provider, or the one selected with --provider
+	dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+	if err != nil {
+		return err
+	}
+
+	// A snapshot of an empty zone, e.g. taken before records were first
+	// added, restores to an empty zone
+	var desired []dnsrecord.Record
+	skipped := 0
+	if len(records) > 0 {
+		desired, skipped, err = importRecords(dnsService, domainName, "snapshot "+snapshot.ID, records)
+		if err != nil {
+			return err
+		}
+	}
+	current, desired, err := dnsService.PlanImport(domainName, desired, dns.ImportPrune)
+	if err != nil {
+		return err
+	}
+	diff := diffview.Compute(domainName, current, desired)
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	warning, err := dnsService.CheckRecordLimit(len(desired))
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		printChangeWarnings(domainName, warning, current, desired)
+	}
+
+	if output.Structured() {
+		if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+			return err
+		}
+	} else {
+		statusln(messages.intro)
+		if skipped > 0 {
+			statusf("Skipping %d apex NS records managed by the provider\n", skipped)
+		}
+		statusln("=====================================")
+		diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+		statusln()
+	}
+
+	if !diff.HasChanges() {
+		if !output.Structured() {
+			statusln(messages.nothing)
+		}
+		return nil
+	}
+	if !confirm {
+		if !output.Structured() {
+			statusln("Use --confirm to apply these changes.")
+		}
+		return nil
+	}
+
+	if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, messages.action); err != nil {
+		return err
+	}
+
+	result, err := dnsService.ApplyRecords(domainName, desired)
+	if result != nil {
+		printApplyResult(result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore DNS records: %w", err)
+	}
+
+	statusf("✅ %s\n", messages.done)
+	return confirmPropagation(cmd, dnsService, domainName, changedRecords(result))
+}
+
+// dnsBackupsCmd represents the dns backups command
+var dnsBackupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Manage zone snapshots",
+	Long:  `Commands for the zone snapshots taken with dns backup and before destructive changes.`,
+}
+
+// dnsBackupsListCmd represents the dns backups list command
+var dnsBackupsListCmd = &cobra.Command{
+	Use:   "list [domain]",
+	Short: "List the saved snapshots, newest first",
+	Long:  `List the snapshots of a domain, or of every domain, newest first.`,
+	Example: `  zonekit dns backups list
+  zonekit dns backups list example.com -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		domainName := ""
+		if len(args) > 0 {
+			domainName = args[0]
+		}
+		snapshots, err := newBackupStore().List(domainName)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 && !output.Structured() {
+			statusln("No snapshots found.")
+			return nil
+		}
+
+		views := make([]snapshotView, 0, len(snapshots))
+		table := render.NewTable("ID", "DOMAIN", "TAKEN", "RECORDS", "REASON")
+		for _, snapshot := range snapshots {
+			views = append(views, newSnapshotView(snapshot))
+			table.AddRow(snapshot.ID, snapshot.Domain, timeFormatter().Timestamp(snapshot.Time), strconv.Itoa(snapshot.Count), snapshot.Reason)
+		}
+		return writeOutput(output, table, views)
+	},
+}
+
+// snapshotView is the structured form of a saved snapshot
+type snapshotView struct {
+	ID        string    `json:"id"`
+	Domain    string    `json:"domain"`
+	Time      time.Time `json:"time"`
+	Provider  string    `json:"provider,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Automatic bool      `json:"automatic"`
+	Records   int       `json:"records"`
+}
+
+func newSnapshotView(snapshot backup.Snapshot) snapshotView {
+	return snapshotView{
+		ID:        snapshot.ID,
+		Domain:    snapshot.Domain,
+		Time:      snapshot.Time,
+		Provider:  snapshot.Provider,
+		Account:   snapshot.Account,
+		Reason:    snapshot.Reason,
+		Automatic: snapshot.Automatic,
+		Records:   snapshot.Count,
+	}
+}
+
+// newBackupStore returns the snapshot store, indexed in the local state when
+// it can be opened
+func newBackupStore() *backup.Store {
+	store := backup.NewStore(backup.DefaultDir())
+	if stateStore, err := openState(); err == nil {
+		store.SetIndex(stateStore)
+	}
+	return store
+}
+
+// newSnapshot returns the metadata of a snapshot of a zone served by dnsService
+func newSnapshot(dnsService *dns.Service, reason string) backup.Snapshot {
+	return backup.Snapshot{Provider: dnsService.ProviderName(), Account: GetCurrentAccountName(), Reason: reason}
+}
+
+// changeSnapshots saves a snapshot of a zone before each change applied to
+// it, so dns undo and dns restore can put the records back. Failing to save it
+// is not fatal, like failing to record the change history.
+type changeSnapshots struct {
+	store   *backup.Store
+	account string
+	command string
+}
+
+func (c changeSnapshots) Snapshot(domainName, providerName string, before []dnsrecord.Record) {
+	snapshot, err := c.store.Save(domainName, before, backup.Snapshot{Provider: providerName, Account: c.account, Reason: c.command, Automatic: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no snapshot was taken before the change: %v\n", err)
+		return
+	}
+	if err := c.store.Prune(domainName, backup.DefaultKeep); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to delete old snapshots: %v\n", err)
+	}
+	statusf("Saved snapshot %s; undo with: zonekit dns undo %s\n", snapshot.ID, domainName)
+}
+
+func init() {
+	dnsCmd.AddCommand(dnsBackupCmd)
+	dnsCmd.AddCommand(dnsRestoreCmd)
+	dnsCmd.AddCommand(dnsUndoCmd)
+	dnsCmd.AddCommand(dnsBackupsCmd)
+	dnsBackupsCmd.AddCommand(dnsBackupsListCmd)
+
+	dnsBackupCmd.Flags().String("reason", "", "Note stored with the snapshot")
+	dnsRestoreCmd.Flags().BoolP("confirm", "y", false, "Apply the changes")
+	addPropagationFlags(dnsRestoreCmd)
+	dnsUndoCmd.Flags().BoolP("confirm", "y", false, "Apply the changes")
+	addPropagationFlags(dnsUndoCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/domaincache"
+)
+
+// cacheDomains refreshes the cached domain names of the current account, which
+// complete domain arguments and suggest corrections for mistyped ones. The
+// cache is a convenience, so failing to write it is not reported.
+func cacheDomains(domains []string) {
+	account := GetCurrentAccountName()
+	if account == "" {
+		return
+	}
+	store, err := openState()
+	if err != nil {
+		return
+	}
+	_ = domaincache.New(store).Save(account, domains)
+}
+
+// warnUnknownDomain warns when domainName is not among the cached domains of
+// the current account and one of them is a likely correction. Without a
+// cache nothing is checked.
+func warnUnknownDomain(domainName string) {
+	warnUnknownAccountDomain(GetCurrentAccountName(), domainName)
+}
+
+// warnUnknownAccountDomain is warnUnknownDomain for the domains of account
+func warnUnknownAccountDomain(account, domainName string) {
+	store, err := openState()
+	if err != nil {
+		return
+	}
+	entry, err := domaincache.New(store).Load(account)
+	if err != nil || entry == nil || entry.Contains(domainName) {
+		return
+	}
+	if suggestion := domaincache.Suggest(domainName, entry.Domains); suggestion != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s is not a known domain of this account; did you mean %s?\n", domainName, suggestion)
+	}
+}
+
+// registerDomainCompletion completes the domain arguments of every command
+// from the cached domains of the current account. Domain arguments are found
+// by the <domain> or [domain] placeholder in a command's usage line.
+func registerDomainCompletion(cmd *cobra.Command) {
+	if cmd.ValidArgsFunction == nil {
+		if position := domainArgPosition(cmd.Use); position >= 0 {
+			cmd.ValidArgsFunction = completeDomainArg(position)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerDomainCompletion(sub)
+	}
+}
+
+// domainArgPosition returns the index of the domain argument in a usage line
+// such as "add <domain> <hostname> <type> <value>", or -1 if there is none
+func domainArgPosition(use string) int {
+	fields := strings.Fields(use)
+	for i, field := range fields[1:] {
+		name := strings.Trim(field, "<>[]")
+		if name == "domain" || strings.HasPrefix(name, "domain|") {
+			return i
+		}
+	}
+	return -1
+}
+
+// completeDomainArg completes the argument at position with cached domains
+func completeDomainArg(position int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != position {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		store, err := openState()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return domaincache.New(store).Complete(GetCurrentAccountName(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"zonekit/internal/cmdutil"
 	"zonekit/pkg/config"
+	dnsprovider "zonekit/pkg/dns/provider"
 	"zonekit/pkg/domain"
+	"zonekit/pkg/render"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,16 +39,16 @@ var configSetCmd = &cobra.Command{
 		clientIP := viper.GetString("client-ip")
 		sandbox := viper.GetBool("sandbox")
 
-		fmt.Println("DNS Provider Configuration Setup")
-		fmt.Println("=================================")
-		fmt.Println()
+		statusln("DNS Provider Configuration Setup")
+		statusln("=================================")
+		statusln()
 
 		// Username
-		fmt.Print("Provider Username")
+		statusf("Provider Username")
 		if username != "" {
-			fmt.Printf(" [%s]", username)
+			statusf(" [%s]", username)
 		}
-		fmt.Print(": ")
+		statusf(": ")
 		var input string
 		fmt.Scanln(&input)
 		if input != "" {
@@ -56,11 +59,11 @@ var configSetCmd = &cobra.Command{
 		}
 
 		// API User
-		fmt.Print("API User")
+		statusf("API User")
 		if apiUser != "" {
-			fmt.Printf(" [%s]", apiUser)
+			statusf(" [%s]", apiUser)
 		}
-		fmt.Print(": ")
+		statusf(": ")
 		fmt.Scanln(&input)
 		if input != "" {
 			apiUser = input
@@ -70,15 +73,15 @@ var configSetCmd = &cobra.Command{
 		}
 
 		// API Key
-		fmt.Print("API Key")
+		statusf("API Key")
 		if apiKey != "" {
 			masked := apiKey
 			if len(apiKey) > 4 {
 				masked = apiKey[:4]
 			}
-			fmt.Printf(" [%s***]", masked)
+			statusf(" [%s***]", masked)
 		}
-		fmt.Print(": ")
+		statusf(": ")
 		fmt.Scanln(&input)
 		if input != "" {
 			apiKey = input
@@ -88,11 +91,11 @@ var configSetCmd = &cobra.Command{
 		}
 
 		// Client IP
-		fmt.Print("Client IP Address")
+		statusf("Client IP Address")
 		if clientIP != "" {
-			fmt.Printf(" [%s]", clientIP)
+			statusf(" [%s]", clientIP)
 		}
-		fmt.Print(": ")
+		statusf(": ")
 		fmt.Scanln(&input)
 		if input != "" {
 			clientIP = input
@@ -102,13 +105,13 @@ var configSetCmd = &cobra.Command{
 		}
 
 		// Sandbox
-		fmt.Print("Use Sandbox Environment? (y/N)")
+		statusf("Use Sandbox Environment? (y/N)")
 		if sandbox {
-			fmt.Print(" [y]")
+			statusf(" [y]")
 		} else {
-			fmt.Print(" [N]")
+			statusf(" [N]")
 		}
-		fmt.Print(": ")
+		statusf(": ")
 		fmt.Scanln(&input)
 		if input != "" {
 			sandbox = (input == "y" || input == "Y" || input == "yes" || input == "Yes")
@@ -126,8 +129,8 @@ var configShowCmd = &cobra.Command{
 	Short: "Show current configuration",
 	Long:  `Display the current configuration values.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Current Configuration:")
-		fmt.Println("=====================")
+		statusln("Current Configuration:")
+		statusln("=====================")
 
 		if configFile := viper.ConfigFileUsed(); configFile != "" {
 			fmt.Printf("Config file: %s\n", configFile)
@@ -151,10 +154,10 @@ var configShowCmd = &cobra.Command{
 
 		fmt.Println()
 		if username == "" || apiUser == "" || apiKey == "" || clientIP == "" {
-			fmt.Println("⚠️  Some required configuration values are missing.")
-			fmt.Println("   Run 'zonekit config set' to configure them.")
+			statusln("⚠️  Some required configuration values are missing.")
+			statusln("   Run 'zonekit config set' to configure them.")
 		} else {
-			fmt.Println("✅ Configuration appears complete.")
+			statusln("✅ Configuration appears complete.")
 		}
 
 		return nil
@@ -176,12 +179,12 @@ var configInitCmd = &cobra.Command{
 
 		// Check if file already exists
 		if _, err := os.Stat(configPath); err == nil {
-			fmt.Printf("Configuration file already exists at %s\n", configPath)
-			fmt.Print("Overwrite? (y/N): ")
+			statusf("Configuration file already exists at %s\n", configPath)
+			statusf("Overwrite? (y/N): ")
 			var input string
 			fmt.Scanln(&input)
 			if input != "y" && input != "Y" && input != "yes" && input != "Yes" {
-				fmt.Println("Aborted.")
+				statusln("Aborted.")
 				return nil
 			}
 		}
@@ -205,10 +208,59 @@ var configInitCmd = &cobra.Command{
 			return fmt.Errorf("failed to write config file: %w", err)
 		}
 
-		fmt.Printf("Configuration file created at %s\n", configPath)
-		fmt.Println("Please edit the file with your actual values, then run:")
-		fmt.Println("  zonekit config show")
+		statusf("Configuration file created at %s\n", configPath)
+		statusln("Please edit the file with your actual values, then run:")
+		statusln("  zonekit config show")
+
+		return nil
+	},
+}
+
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [path]",
+	Short: "Migrate namecheap-dns config files to zonekit",
+	Long: `Move the config files and local state of namecheap-dns releases to zonekit's
+locations: ~/.namecheap-dns.yaml to ~/.zonekit.yaml, the ~/.namecheap-dns
+directory to ~/.zonekit, and a project's configs/.namecheap-dns.yaml to
+configs/.zonekit.yaml beside it. Legacy config files are kept with a
+.migrated suffix, and nothing is moved where a zonekit file already exists.
+
+The home config and state are migrated automatically on every run, and a
+project config when it is the config file in use. This command migrates the
+project config at path, or the one found from the current directory, even
+when another config file is in use.`,
+	Example: `  zonekit config migrate
+  zonekit config migrate ~/src/infra/configs/.namecheap-dns.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		home, _ := os.UserHomeDir()
+		migrations, err := config.MigrateLegacy(home)
+		for _, m := range migrations {
+			statusf("Migrated %s to %s\n", m.From, m.To)
+		}
+		if err != nil {
+			return err
+		}
+
+		project := config.FindLegacyProjectConfig()
+		if len(args) > 0 {
+			project = args[0]
+		}
+		if project != "" {
+			migration, err := config.MigrateLegacyProjectConfig(project)
+			if err != nil {
+				return err
+			}
+			if migration != nil {
+				statusf("Migrated %s to %s\n", migration.From, migration.To)
+				migrations = append(migrations, *migration)
+			}
+		}
 
+		if len(migrations) == 0 {
+			statusf("Nothing to migrate: no %s files found\n", config.LegacyName)
+		}
 		return nil
 	},
 }
@@ -219,7 +271,7 @@ var configValidateCmd = &cobra.Command{
 	Short: "Validate configuration and test API connection",
 	Long:  `Validate the current configuration and test the connection to DNS provider API.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Validating configuration...")
+		statusln("Validating configuration...")
 
 		// Check required fields
 		username := viper.GetString("username")
@@ -240,10 +292,10 @@ var configValidateCmd = &cobra.Command{
 			return fmt.Errorf("client-ip is required")
 		}
 
-		fmt.Println("✅ All required fields are present")
+		statusln("✅ All required fields are present")
 
 		// Test API connection
-		fmt.Println("Testing API connection...")
+		statusln("Testing API connection...")
 
 		// Create a test client to validate credentials
 		testClient, err := cmdutil.CreateClient(&config.AccountConfig{
@@ -263,14 +315,147 @@ var configValidateCmd = &cobra.Command{
 			return fmt.Errorf("API connection test failed: %w", err)
 		}
 
-		fmt.Printf("✅ API connection successful - Account: %s\n", testClient.GetAccountName())
+		statusf("✅ API connection successful - Account: %s\n", testClient.GetAccountName())
+		statusln()
+		statusln("Note: Run 'zonekit domain list' to test the actual API connection.")
+
+		return nil
+	},
+}
+
+// configDoctorCmd represents the config doctor command
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common configuration problems",
+	Long: `Check the configuration for common problems and print suggested fixes.
+
+Checks performed:
+- config file permissions
+- YAML validity, duplicate and unknown keys
+- empty, placeholder and duplicate accounts, dangling current account
+- accounts using unavailable providers
+- stale legacy top-level fields
+- OS keyring availability
+- conflicting project and home config files`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := config.DefaultDoctorOptions(cfgFile)
+		opts.KnownProviders = append([]string{"namecheap"}, dnsprovider.Names()...)
+
+		fmt.Printf("Config file: %s\n", opts.ConfigPath)
+		fmt.Println()
+
+		findings := config.Diagnose(opts)
+		problems := 0
+		for _, f := range findings {
+			icon := "✅"
+			switch f.Severity {
+			case config.SeverityInfo:
+				icon = "ℹ️ "
+			case config.SeverityWarning:
+				icon = "⚠️ "
+				problems++
+			case config.SeverityError:
+				icon = "❌"
+				problems++
+			}
+			fmt.Printf("%s %s: %s\n", icon, f.Check, f.Message)
+			if f.Fix != "" {
+				fmt.Printf("   Fix: %s\n", f.Fix)
+			}
+		}
+
 		fmt.Println()
-		fmt.Println("Note: Run 'zonekit domain list' to test the actual API connection.")
+		if config.HasErrors(findings) {
+			return fmt.Errorf("configuration has %d problem(s)", problems)
+		}
+		if problems > 0 {
+			statusf("Configuration is usable but has %d warning(s).\n", problems)
+		} else {
+			statusln("✅ No problems found.")
+		}
 
 		return nil
 	},
 }
 
+// configWhichCmd represents the config which command
+var configWhichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Show which config file is used and where values come from",
+	Long: `Show every config file location that was considered, which one is used,
+and where each setting of the selected account comes from.
+
+Config files are resolved in this order, first match wins:
+  1. --config flag
+  2. ZONEKIT_CONFIG environment variable
+  3. configs/.zonekit.yaml in the current directory or a parent directory
+     (or a namecheap-dns configs/.namecheap-dns.yaml, migrated when used)
+  4. ~/.zonekit.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resolution := config.ResolveConfigPath(cfgFile)
+		printConfigResolution(os.Stdout, resolution)
+		fmt.Println()
+
+		configManager, err := config.NewManagerWithPath(resolution.Path)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name := accountName
+		reason := "--account flag"
+		if name == "" {
+			name = configManager.GetCurrentAccountName()
+			reason = "current_account in config file"
+		}
+
+		fmt.Printf("Account: %s (from %s)\n", name, reason)
+		sources, err := configManager.AccountSources(name)
+		if err != nil {
+			return err
+		}
+
+		table := render.NewTable("SETTING", "VALUE", "SOURCE")
+		for _, v := range sources {
+			table.AddRow(v.Field, getValueOrEmpty(v.Value), v.Source)
+		}
+		return table.WriteText(os.Stdout)
+	},
+}
+
+// printConfigResolution writes the config file candidates in precedence order
+func printConfigResolution(out io.Writer, resolution *config.ConfigResolution) {
+	fmt.Fprintln(out, "Config files considered (highest precedence first):")
+	for i, c := range resolution.Candidates {
+		marker := " "
+		if c.Selected {
+			marker = "→"
+		}
+
+		path := c.Path
+		status := ""
+		switch {
+		case path == "" && c.Source == config.SourceProject:
+			path = "(not found)"
+		case path == "":
+			path = "(not set)"
+		case c.Exists && c.Legacy:
+			status = " [exists, " + config.LegacyName + " config, migrated when used]"
+		case c.Exists:
+			status = " [exists]"
+		default:
+			status = " [missing]"
+		}
+
+		fmt.Fprintf(out, "%s %d. %-37s %s%s\n", marker, i+1, c.Source, path, status)
+	}
+
+	if resolution.Path != "" {
+		fmt.Fprintf(out, "Using: %s (%s)\n", resolution.Path, resolution.Source)
+	} else {
+		fmt.Fprintln(out, "Using: no config file")
+	}
+}
+
 func saveConfig(config map[string]interface{}) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -289,7 +474,7 @@ func saveConfig(config map[string]interface{}) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	fmt.Printf("Configuration saved to %s\n", configPath)
+	statusf("Configuration saved to %s\n", configPath)
 	return nil
 }
 
@@ -321,5 +506,8 @@ func init() {
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configMigrateCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configDoctorCmd)
+	configCmd.AddCommand(configWhichCmd)
 }
@@ -1,15 +1,43 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"text/tabwriter"
+	"sync"
+	"syscall"
+	"time"
 
 	"zonekit/internal/cmdutil"
+	"zonekit/pkg/client"
+	"zonekit/pkg/config"
+	"zonekit/pkg/ddns"
+	"zonekit/pkg/diffview"
 	"zonekit/pkg/dns"
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dns/mxcheck"
+	"zonekit/pkg/dns/provider"
 	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/domain"
+	"zonekit/pkg/history"
+	"zonekit/pkg/propagation"
+	"zonekit/pkg/render"
+	"zonekit/pkg/stale"
+	"zonekit/pkg/state"
+	"zonekit/pkg/terraform"
+	"zonekit/pkg/undo"
+	"zonekit/pkg/watch"
+	"zonekit/pkg/zonecompare"
+	"zonekit/pkg/zonefile"
+	"zonekit/pkg/zoneimport"
+	"zonekit/pkg/zonestate"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -19,119 +47,542 @@ import (
 var dnsCmd = &cobra.Command{
 	Use:   "dns",
 	Short: "Manage DNS records",
-	Long:  `Commands for managing DNS records for your domains.`,
+	Long: `Commands for managing DNS records for your domains.
+
+Commands use the current account's provider: Namecheap, or the provider the
+account is bound to with provider in the config file. Pass --provider with the
+name of a registered provider or provider instance to use it instead for one
+invocation, e.g. to fix records on the old provider while a domain is
+mid-migration:
+
+  zonekit dns list example.com --provider cloudflare-work
+  zonekit dns add example.com www A 192.0.2.1 --provider cloudflare-work`,
 }
 
 // dnsListCmd represents the dns list command
 var dnsListCmd = &cobra.Command{
-	Use:   "list <domain>",
+	Use:   "list [domain]",
 	Short: "List DNS records for a domain",
-	Long:  `List all DNS records for the specified domain.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		domainName := args[0]
+	Long: `List all DNS records for the specified domain.
 
-		// Validate domain
-		if err := dns.ValidateDomain(domainName); err != nil {
-			return fmt.Errorf("invalid domain: %w", err)
-		}
+With --all-domains, list records across every domain in the account. Combine
+with --type, --host, --value and --tag to audit records, e.g. every TXT
+verification record:
 
-		recordType, _ := cmd.Flags().GetString("type")
+  zonekit dns list --all-domains --type TXT --value verification -o json
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+Record comments and tags, set with dns add and dns update, are shown with
+--show-comments and included in JSON and YAML output.
+
+With --raw and -o json or yaml, the provider's API response for each domain is
+included untouched under "raw", keyed by domain, for provider-specific fields
+zonekit does not model. The records are read again for it; the Namecheap and
+REST-configured providers support it.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allDomains, _ := cmd.Flags().GetBool("all-domains")
+		if allDomains && len(args) > 0 {
+			return fmt.Errorf("cannot combine a domain argument with --all-domains")
+		}
+		if !allDomains && len(args) == 0 {
+			return fmt.Errorf("requires a domain argument or --all-domains")
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		recordType, _ := cmd.Flags().GetString("type")
+		host, _ := cmd.Flags().GetString("host")
+		value, _ := cmd.Flags().GetString("value")
+		showSeconds, _ := cmd.Flags().GetBool("seconds")
+		showIDs, _ := cmd.Flags().GetBool("ids")
+		showComments, _ := cmd.Flags().GetBool("show-comments")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		raw, _ := cmd.Flags().GetBool("raw")
+		output, err := tableOutputFormat()
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if raw && (output != render.FormatJSON && output != render.FormatYAML) {
+			return fmt.Errorf("--raw requires -o json or -o yaml")
+		}
 
-		dnsService := dns.NewService(client)
+		filter := dns.RecordFilter{Type: strings.ToUpper(recordType), Host: host, Value: value, Tags: tags}
 
-		var records []dnsrecord.Record
-		if recordType != "" {
-			records, err = dnsService.GetRecordsByType(domainName, strings.ToUpper(recordType))
+		var domains []string
+		var dnsService *dns.Service
+		if allDomains {
+			dnsService, domains, err = resolveAllDomains(cmd, args, !output.Structured())
+			if err != nil {
+				return err
+			}
 		} else {
-			records, err = dnsService.GetRecords(domainName)
+			domainName := args[0]
+
+			// Validate domain
+			if err := dns.ValidateDomain(domainName); err != nil {
+				return fmt.Errorf("invalid domain: %w", err)
+			}
+			domains = []string{domainName}
+
+			// Use the account's provider, or the one selected with --provider
+			dnsService, _, err = resolveDNSService(cmd, args, domainName, !output.Structured())
+			if err != nil {
+				return err
+			}
 		}
 
-		if err != nil {
-			return fmt.Errorf("failed to get DNS records: %w", err)
+		inventory := dnsService.Inventory(domains, filter)
+		changes := observeZoneVersions(dnsService.ProviderName(), inventory)
+
+		if raw {
+			addRawRecords(dnsService, inventory, domains)
 		}
 
-		if len(records) == 0 {
-			fmt.Printf("No DNS records found for %s", domainName)
-			if recordType != "" {
-				fmt.Printf(" (type: %s)", recordType)
+		table := newRecordTable(inventory, allDomains, showIDs, showSeconds, showComments)
+		if output.Structured() {
+			if err := writeOutput(output, table, inventory); err != nil {
+				return err
+			}
+			// CSV has no place for lookup errors; report them without mixing them into the rows
+			if output == render.FormatCSV {
+				for _, e := range inventory.Errors {
+					fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", e.Domain, e.Error)
+				}
 			}
-			fmt.Println()
 			return nil
 		}
 
-		// Create table writer
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "HOSTNAME\tTYPE\tVALUE\tTTL\tMX_PREF")
+		if !allDomains {
+			if len(inventory.Errors) > 0 {
+				return fmt.Errorf("failed to get DNS records: %s", inventory.Errors[0].Error)
+			}
+			if len(inventory.Records) == 0 {
+				statusf("No DNS records found for %s", domains[0])
+				if recordType != "" {
+					statusf(" (type: %s)", recordType)
+				}
+				statusln()
+				return nil
+			}
+		}
+
+		if err := writeOutput(output, table, inventory); err != nil {
+			return err
+		}
 
-		for _, record := range records {
-			mxPref := ""
-			if record.MXPref > 0 {
-				mxPref = strconv.Itoa(record.MXPref)
+		if !allDomains {
+			statusf("\nZone version: %s%s\n", inventory.Versions[domains[0]], changes[domains[0]])
+		}
+
+		if allDomains {
+			statusf("\n%d record(s) across %d domain(s)\n", len(inventory.Records), len(domains)-len(inventory.Errors))
+			for _, e := range inventory.Errors {
+				statusf("⚠️  %s: %s\n", e.Domain, e.Error)
+			}
+		}
+		return nil
+	},
+}
+
+// addRawRecords adds the provider's untouched responses for the domains that
+// were read to the inventory; a domain whose response cannot be read is
+// reported as an error
+func addRawRecords(dnsService *dns.Service, inventory *dns.Inventory, domains []string) {
+	failed := make(map[string]bool)
+	for _, e := range inventory.Errors {
+		failed[e.Domain] = true
+	}
+
+	inventory.Raw = make(map[string]interface{})
+	for _, domainName := range domains {
+		if failed[domainName] {
+			continue
+		}
+		data, err := dnsService.RawRecords(domainName)
+		if err != nil {
+			inventory.Errors = append(inventory.Errors, dns.InventoryError{Domain: domainName, Error: err.Error()})
+			continue
+		}
+		inventory.Raw[domainName] = data
+	}
+}
+
+// newRecordTable builds the table printed by dns list. The DOMAIN and ID
+// columns are only included for --all-domains and --ids, COMMENT and TAGS for
+// --show-comments. PRIORITY is the MX preference or the SRV priority.
+func newRecordTable(inventory *dns.Inventory, allDomains, showIDs, showSeconds, showComments bool) *render.Table {
+	var columns []string
+	if allDomains {
+		columns = append(columns, "DOMAIN")
+	}
+	if showIDs {
+		columns = append(columns, "ID")
+	}
+	columns = append(columns, "HOSTNAME", "TYPE", "VALUE", "TTL", "PRIORITY")
+	if showComments {
+		columns = append(columns, "COMMENT", "TAGS")
+	}
+	table := render.NewTable(columns...)
+
+	for _, record := range inventory.Records {
+		value := record.Value
+		priority := ""
+		if record.MXPref > 0 {
+			priority = strconv.Itoa(record.MXPref)
+		}
+		// SRV priority goes with the MX preference; the rest reads as an address
+		if record.SRV != nil {
+			value = fmt.Sprintf("%s:%d (weight %d)", record.SRV.Target, record.SRV.Port, record.SRV.Weight)
+			priority = strconv.Itoa(record.SRV.Priority)
+		}
+
+		ttl := dnsrecord.FormatTTL(record.TTL)
+		if showSeconds && record.TTL > 0 {
+			ttl = strconv.Itoa(record.TTL)
+		}
+
+		var cells []string
+		if allDomains {
+			cells = append(cells, record.Domain)
+		}
+		if showIDs {
+			cells = append(cells, record.ID)
+		}
+		cells = append(cells, record.HostName, record.Type, value, ttl, priority)
+		if showComments {
+			cells = append(cells, record.Comment, strings.Join(record.Tags, ", "))
+		}
+		table.AddRow(cells...)
+	}
+	return table
+}
+
+// dnsFindCmd represents the dns find command
+var dnsFindCmd = &cobra.Command{
+	Use:   "find [value]",
+	Short: "Find records across all domains",
+	Long: `Search every domain in the account for records matching a hostname glob, a
+record type, a value substring or tags, e.g. to find where an old server's
+address still appears. A value may be given as the argument or with --value.
+
+With --account-all every configured account is searched, each with its own
+provider. Zones are read concurrently; domains that cannot be read are
+reported after the matches.
+
+Examples:
+  zonekit dns find 192.0.2.10
+  zonekit dns find --host '_acme-challenge*' --type TXT
+  zonekit dns find old-lb.example.net --account-all -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountAll, _ := cmd.Flags().GetBool("account-all")
+		recordType, _ := cmd.Flags().GetString("type")
+		host, _ := cmd.Flags().GetString("host")
+		value, _ := cmd.Flags().GetString("value")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if len(args) > 0 {
+			if value != "" {
+				return fmt.Errorf("cannot combine a value argument with --value")
+			}
+			value = args[0]
+		}
+		filter := dns.RecordFilter{Type: strings.ToUpper(recordType), Host: host, Value: value, Tags: tags}
+		if filter.Type == "" && filter.Host == "" && filter.Value == "" && len(filter.Tags) == 0 {
+			return fmt.Errorf("give a value, --host, --type or --tag to search for")
+		}
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		var found *foundRecords
+		if accountAll {
+			if providerName, _ := cmd.Flags().GetString("provider"); providerName != "" {
+				return fmt.Errorf("cannot combine --provider with --account-all")
 			}
+			found, err = findAcrossAccounts(cmd, args, filter)
+		} else {
+			found, err = findInAccount(cmd, args, filter, !output.Structured())
+		}
+		if err != nil {
+			return err
+		}
 
-			ttl := ""
-			if record.TTL > 0 {
-				ttl = strconv.Itoa(record.TTL)
+		var columns []string
+		if accountAll {
+			columns = append(columns, "ACCOUNT")
+		}
+		columns = append(columns, "DOMAIN", "HOSTNAME", "TYPE", "VALUE", "TTL")
+		table := render.NewTable(columns...)
+		for _, record := range found.Records {
+			var cells []string
+			if accountAll {
+				cells = append(cells, record.Account)
 			}
+			cells = append(cells, record.Domain, record.HostName, record.Type, record.Value, dnsrecord.FormatTTL(record.TTL))
+			table.AddRow(cells...)
+		}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-				record.HostName, record.RecordType, record.Address, ttl, mxPref)
+		if output.Structured() {
+			if err := writeOutput(output, table, found); err != nil {
+				return err
+			}
+			if output == render.FormatCSV {
+				for _, e := range found.Errors {
+					fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", e.where(), e.Error)
+				}
+			}
+			return nil
 		}
 
-		w.Flush()
+		if len(found.Records) == 0 {
+			statusf("No matching records in %d domain(s)\n", found.Domains)
+		} else {
+			if err := writeOutput(output, table, found); err != nil {
+				return err
+			}
+			statusf("\n%d record(s) in %d domain(s)\n", len(found.Records), found.Domains)
+		}
+		for _, e := range found.Errors {
+			statusf("⚠️  %s: %s\n", e.where(), e.Error)
+		}
 		return nil
 	},
 }
 
+// foundRecord is a record found by dns find
+type foundRecord struct {
+	Account string `json:"account,omitempty"`
+	dns.InventoryRecord
+}
+
+// findError records an account or domain dns find could not search
+type findError struct {
+	Account string `json:"account,omitempty"`
+	Domain  string `json:"domain,omitempty"`
+	Error   string `json:"error"`
+}
+
+// where names the account and domain of an error
+func (e findError) where() string {
+	switch {
+	case e.Account == "":
+		return e.Domain
+	case e.Domain == "":
+		return e.Account
+	}
+	return e.Account + "/" + e.Domain
+}
+
+// foundRecords is the result of dns find
+type foundRecords struct {
+	Records []foundRecord `json:"records"`
+	// Domains is the number of domains searched
+	Domains int         `json:"domains"`
+	Errors  []findError `json:"errors,omitempty"`
+}
+
+// add adds the matches of an account's inventory
+func (f *foundRecords) add(account string, domains []string, inventory *dns.Inventory) {
+	f.Domains += len(domains) - len(inventory.Errors)
+	for _, record := range inventory.Records {
+		f.Records = append(f.Records, foundRecord{Account: account, InventoryRecord: record})
+	}
+	for _, e := range inventory.Errors {
+		f.Errors = append(f.Errors, findError{Account: account, Domain: e.Domain, Error: e.Error})
+	}
+}
+
+// findInAccount searches the domains of the current account, or the zones of
+// the provider selected with --provider
+func findInAccount(cmd *cobra.Command, args []string, filter dns.RecordFilter, showTarget bool) (*foundRecords, error) {
+	dnsService, domains, err := resolveAllDomains(cmd, args, showTarget)
+	if err != nil {
+		return nil, err
+	}
+	found := &foundRecords{Records: []foundRecord{}}
+	found.add("", domains, dnsService.Inventory(domains, filter))
+	return found, nil
+}
+
+// findAcrossAccounts searches the domains of every configured account
+// concurrently. Accounts that cannot be searched are reported in Errors.
+func findAcrossAccounts(cmd *cobra.Command, args []string, filter dns.RecordFilter) (*foundRecords, error) {
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	accounts := configManager.ListAccounts()
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts configured")
+	}
+
+	type accountResult struct {
+		domains   []string
+		inventory *dns.Inventory
+		err       error
+	}
+	results := make([]accountResult, len(accounts))
+	var wg sync.WaitGroup
+	for i, name := range accounts {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			accountConfig, err := configManager.GetAccount(name)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			dnsService, registrar, err := accountDNSService(cmd, args, accountConfig)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			domains, err := listDomains(dnsService, registrar)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i] = accountResult{domains: domains, inventory: dnsService.Inventory(domains, filter)}
+		}(i, name)
+	}
+	wg.Wait()
+
+	found := &foundRecords{Records: []foundRecord{}}
+	for i, name := range accounts {
+		if results[i].err != nil {
+			found.Errors = append(found.Errors, findError{Account: name, Error: results[i].err.Error()})
+			continue
+		}
+		found.add(name, results[i].domains, results[i].inventory)
+	}
+	return found, nil
+}
+
 // dnsAddCmd represents the dns add command
 var dnsAddCmd = &cobra.Command{
 	Use:   "add <domain> <hostname> <type> <value>",
 	Short: "Add a DNS record",
-	Long:  `Add a new DNS record to the specified domain.`,
-	Args:  cobra.ExactArgs(4),
+	Long: `Add a new DNS record to the specified domain.
+
+If the hostname already has a record of the same type, or the record would conflict
+with a CNAME, the command fails. Choose what to do instead with:
+  --if-absent         leave the existing records alone and do nothing
+  --replace-existing  replace the conflicting records with the new one
+  --append            add another record of the same type (e.g. a second MX or TXT)
+
+With --all-domains, leave out the domain to add the record to every domain in
+the account. Domains are changed at a capped rate (--rate, --provider-rate) and
+progress is kept in local state: an interrupted run continues with --resume,
+which also retries the domains that failed.
+
+SRV values are given as "priority weight port target", or field by field with
+--priority, --weight, --port and --target in place of the value.
+
+--comment and --tag annotate the record. Providers with record comments, such
+as Cloudflare, store them with the record; for the others they are kept in
+local state.
+
+With --check-mx, the target of an MX record is resolved before it is written,
+and a target without an A or AAAA record, a CNAME target (which RFC 2181
+forbids) and a target that refuses SMTP connections, such as a web host, are
+warned about. Targets within the domain are checked against its records.
+
+Examples:
+  zonekit dns add example.com www A 192.0.2.1
+  zonekit dns add example.com @ MX mail.example.com --mx-pref 10 --check-mx
+  zonekit dns add example.com www A 192.0.2.1 --comment "web server" --tag prod --tag owner:web
+  zonekit dns add example.com _sip._tcp SRV "10 5 5060 sip.example.com"
+  zonekit dns add example.com _sip._tcp SRV --priority 10 --weight 5 --port 5060 --target sip.example.com
+  zonekit dns add --all-domains @ TXT "v=spf1 include:_spf.example.net -all" --if-absent
+  zonekit dns add --all-domains @ TXT "v=spf1 include:_spf.example.net -all" --if-absent --resume`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		n := 4
+		if allDomains, _ := cmd.Flags().GetBool("all-domains"); allDomains {
+			n--
+		}
+		// The SRV flags take the place of the value; srvValue reports misuse
+		for _, name := range srvFlags {
+			if cmd.Flags().Changed(name) {
+				return cobra.RangeArgs(n-1, n)(cmd, args)
+			}
+		}
+		return cobra.ExactArgs(n)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		domainName := args[0]
-		hostname := args[1]
-		recordType := strings.ToUpper(args[2])
-		value := args[3]
+		allDomains, _ := cmd.Flags().GetBool("all-domains")
+
+		var domainName string
+		fields := args
+		if !allDomains {
+			domainName, fields = args[0], args[1:]
+		}
+		hostname := fields[0]
+		recordType := strings.ToUpper(fields[1])
+		value, err := srvValue(cmd, recordType, fields[2:])
+		if err != nil {
+			return err
+		}
 
 		// Validate inputs
-		if err := dns.ValidateDomain(domainName); err != nil {
-			return fmt.Errorf("invalid domain: %w", err)
+		if !allDomains {
+			if err := dns.ValidateDomain(domainName); err != nil {
+				return fmt.Errorf("invalid domain: %w", err)
+			}
 		}
 		if err := dns.ValidateHostname(hostname); err != nil {
 			return fmt.Errorf("invalid hostname: %w", err)
 		}
 
-		ttl, _ := cmd.Flags().GetInt("ttl")
+		ttlValue, _ := cmd.Flags().GetString("ttl")
+		ttl, err := dnsrecord.ParseTTL(ttlValue)
+		if err != nil {
+			return err
+		}
 		mxPref, _ := cmd.Flags().GetInt("mx-pref")
+		comment, _ := cmd.Flags().GetString("comment")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+		addMode := dns.AddFail
+		if ifAbsent, _ := cmd.Flags().GetBool("if-absent"); ifAbsent {
+			addMode = dns.AddSkip
+		}
+		if replaceExisting, _ := cmd.Flags().GetBool("replace-existing"); replaceExisting {
+			addMode = dns.AddReplace
+		}
+		if appendRecord, _ := cmd.Flags().GetBool("append"); appendRecord {
+			addMode = dns.AddAppend
+		}
+
+		if allDomains {
+			record := dnsrecord.Record{HostName: hostname, RecordType: recordType, Address: value, TTL: ttl, MXPref: mxPref, Comment: comment, Tags: tags}
+			runID := fmt.Sprintf("dns add %s %s %s", hostname, recordType, value)
+			return runAllDomains(cmd, args, runID, func(dnsService *dns.Service, domainName string) (string, error) {
+				if err := dnsService.ValidateRecordInZone(domainName, record); err != nil {
+					return "", fmt.Errorf("invalid record: %w", err)
+				}
+				checkMXTarget(cmd, dnsService, domainName, record)
+				added, err := dnsService.AddRecordWithMode(domainName, resolveRecordTTL(dnsService, record), addMode)
+				if err != nil {
+					return "", err
+				}
+				if !added {
+					return "already exists, nothing changed", nil
+				}
+				return "added", nil
+			})
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+
+		if addMode == dns.AddReplace {
+			if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "replace existing DNS records"); err != nil {
+				return err
+			}
+		}
 
 		record := dnsrecord.Record{
 			HostName:   hostname,
@@ -139,31 +590,94 @@ var dnsAddCmd = &cobra.Command{
 			Address:    value,
 			TTL:        ttl,
 			MXPref:     mxPref,
+			Comment:    comment,
+			Tags:       tags,
 		}
 
-		dnsService := dns.NewService(client)
-
 		// Validate record
-		if err := dnsService.ValidateRecord(record); err != nil {
+		if err := dnsService.ValidateRecordInZone(domainName, record); err != nil {
 			return fmt.Errorf("invalid record: %w", err)
 		}
+		record = resolveRecordTTL(dnsService, record)
+		checkMXTarget(cmd, dnsService, domainName, record)
 
-		err = dnsService.AddRecord(domainName, record)
+		added, err := dnsService.AddRecordWithMode(domainName, record, addMode)
 		if err != nil {
 			return fmt.Errorf("failed to add DNS record: %w", err)
 		}
 
-		fmt.Printf("Successfully added %s record: %s -> %s\n", recordType, hostname, value)
-		return nil
+		if !added {
+			statusf("%s record for %s already exists, nothing changed\n", recordType, hostname)
+			return nil
+		}
+
+		statusf("Successfully added %s record: %s -> %s (TTL %s)\n", recordType, hostname, value, dnsrecord.FormatTTL(record.TTL))
+		return confirmPropagation(cmd, dnsService, domainName, []dnsrecord.Record{record})
 	},
 }
 
+// srvFlags are the dns add flags that give an SRV value field by field
+var srvFlags = []string{"priority", "weight", "port", "target"}
+
+// srvValue returns the value of a record added with dns add: the value
+// argument, or for SRV records one built from --priority, --weight, --port and
+// --target. SRV values given as "priority weight port target" are checked and
+// their spacing normalized.
+func srvValue(cmd *cobra.Command, recordType string, rest []string) (string, error) {
+	var used []string
+	for _, name := range srvFlags {
+		if cmd.Flags().Changed(name) {
+			used = append(used, "--"+name)
+		}
+	}
+
+	if len(used) == 0 {
+		if recordType != dnsrecord.RecordTypeSRV {
+			return rest[0], nil
+		}
+		srv, err := dnsrecord.ParseSRV(rest[0])
+		if err != nil {
+			return "", fmt.Errorf("%w (or use --priority, --weight, --port and --target)", err)
+		}
+		return srv.String(), nil
+	}
+
+	if recordType != dnsrecord.RecordTypeSRV {
+		return "", fmt.Errorf("%s can only be used with SRV records", strings.Join(used, ", "))
+	}
+	if len(rest) > 0 {
+		return "", fmt.Errorf("give the SRV value either as an argument or with %s, not both", strings.Join(used, ", "))
+	}
+	if !cmd.Flags().Changed("port") || !cmd.Flags().Changed("target") {
+		return "", fmt.Errorf("--port and --target are required to give an SRV value with flags")
+	}
+	priority, _ := cmd.Flags().GetInt("priority")
+	weight, _ := cmd.Flags().GetInt("weight")
+	port, _ := cmd.Flags().GetInt("port")
+	target, _ := cmd.Flags().GetString("target")
+	srv, err := dnsrecord.ParseSRV(dnsrecord.SRV{Priority: priority, Weight: weight, Port: port, Target: target}.String())
+	if err != nil {
+		return "", err
+	}
+	return srv.String(), nil
+}
+
 // dnsUpdateCmd represents the dns update command
 var dnsUpdateCmd = &cobra.Command{
 	Use:   "update <domain> <hostname> <type> <new-value>",
 	Short: "Update a DNS record",
-	Long:  `Update an existing DNS record.`,
-	Args:  cobra.ExactArgs(4),
+	Long: `Update an existing DNS record.
+
+The first record of the hostname and type is updated. When the hostname has
+several records of that type, select one with --id (see dns list --ids).
+
+The record keeps its comment and tags unless new ones are given with --comment
+and --tag.
+
+With --check-mx, the target of an MX record is checked before it is written:
+a target without an A or AAAA record, a CNAME target and a target that does
+not answer SMTP on port 25 are warned about.`,
+	Args: cobra.ExactArgs(4),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
 		hostname := args[1]
@@ -178,21 +692,24 @@ var dnsUpdateCmd = &cobra.Command{
 			return fmt.Errorf("invalid hostname: %w", err)
 		}
 
-		ttl, _ := cmd.Flags().GetInt("ttl")
+		ttlValue, _ := cmd.Flags().GetString("ttl")
+		ttl, err := dnsrecord.ParseTTL(ttlValue)
+		if err != nil {
+			return err
+		}
 		mxPref, _ := cmd.Flags().GetInt("mx-pref")
+		comment, _ := cmd.Flags().GetString("comment")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
-		if err != nil {
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "update a DNS record"); err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
 
 		newRecord := dnsrecord.Record{
 			HostName:   hostname,
@@ -200,57 +717,224 @@ var dnsUpdateCmd = &cobra.Command{
 			Address:    newValue,
 			TTL:        ttl,
 			MXPref:     mxPref,
+			Comment:    comment,
+			Tags:       tags,
 		}
 
-		dnsService := dns.NewService(client)
-
 		// Validate record
-		if err := dnsService.ValidateRecord(newRecord); err != nil {
+		if err := dnsService.ValidateRecordInZone(domainName, newRecord); err != nil {
 			return fmt.Errorf("invalid record: %w", err)
 		}
+		newRecord = resolveRecordTTL(dnsService, newRecord)
+		checkMXTarget(cmd, dnsService, domainName, newRecord)
 
-		err = dnsService.UpdateRecord(domainName, hostname, recordType, newRecord)
+		if id, _ := cmd.Flags().GetString("id"); id != "" {
+			err = dnsService.UpdateRecordByID(domainName, id, newRecord)
+		} else {
+			err = dnsService.UpdateRecord(domainName, hostname, recordType, newRecord)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to update DNS record: %w", err)
 		}
 
-		fmt.Printf("Successfully updated %s record: %s -> %s\n", recordType, hostname, newValue)
-		return nil
+		statusf("Successfully updated %s record: %s -> %s (TTL %s)\n", recordType, hostname, newValue, dnsrecord.FormatTTL(newRecord.TTL))
+		return confirmPropagation(cmd, dnsService, domainName, []dnsrecord.Record{newRecord})
 	},
 }
 
+// resolveRecordTTL fills in the default TTL of a record given without --ttl,
+// warning when the provider's default differs from zonekit's
+func resolveRecordTTL(dnsService *dns.Service, record dnsrecord.Record) dnsrecord.Record {
+	record, warning := dnsService.ResolveTTL(record)
+	if warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return record
+}
+
+// addPropagationFlags registers the flags of commands that can wait for their
+// changes to reach the authoritative name servers and prime resolver caches
+func addPropagationFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "After applying, wait until the authoritative name servers serve changed MX, NS and apex A records")
+	cmd.Flags().Duration("wait-timeout", propagation.DefaultTimeout, "How long --wait polls the name servers")
+	cmd.Flags().Bool("warm", false, "After applying, query public resolvers for the changed records to prime their caches and report the TTL they serve")
+	cmd.Flags().StringSlice("warm-resolver", nil, "Additional resolver for --warm, e.g. a corporate one (repeatable; adds to warm_resolvers in the config)")
+}
+
+// checkMXTarget warns, with --check-mx, when the target of an MX record about
+// to be written has no address, is a CNAME or refuses SMTP connections. The
+// record is written anyway.
+func checkMXTarget(cmd *cobra.Command, dnsService *dns.Service, domainName string, record dnsrecord.Record) {
+	if check, _ := cmd.Flags().GetBool("check-mx"); !check || record.RecordType != dnsrecord.RecordTypeMX {
+		return
+	}
+	records, err := dnsService.GetRecords(domainName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: MX target not checked: %v\n", err)
+		return
+	}
+	records = append(records, record)
+	for _, warning := range mxcheck.New(lookup.NewResolver("")).Check(cmd.Context(), domainName, record.Address, records) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// confirmPropagation follows up on an applied change: with --wait it waits for
+// the authoritative name servers to serve it, then with --warm it primes
+// resolver caches
+func confirmPropagation(cmd *cobra.Command, dnsService *dns.Service, domainName string, changed []dnsrecord.Record) error {
+	if err := waitForPropagation(cmd, dnsService, domainName, changed); err != nil {
+		return err
+	}
+	return warmCaches(cmd, dnsService, domainName, changed)
+}
+
+// waitForPropagation polls the zone's authoritative name servers after a change
+// made with --wait until they serve the new values of its critical records, and
+// reports how long each record set took
+func waitForPropagation(cmd *cobra.Command, dnsService *dns.Service, domainName string, changed []dnsrecord.Record) error {
+	if wait, _ := cmd.Flags().GetBool("wait"); !wait {
+		return nil
+	}
+	critical := false
+	for _, r := range changed {
+		critical = critical || propagation.Critical(r)
+	}
+	if !critical {
+		statusln("No MX, NS or apex A records changed, not waiting for propagation.")
+		return nil
+	}
+	timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+
+	records, err := dnsService.GetRecords(domainName)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS records: %w", err)
+	}
+	servers, err := propagation.NameServers(cmd.Context(), lookup.NewResolver(""), domainName)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(servers))
+	for _, server := range servers {
+		names = append(names, server.Name)
+	}
+	statusf("\nWaiting up to %s for %s to serve the changes...\n", timeout, strings.Join(names, ", "))
+
+	waiter := &propagation.Waiter{Servers: servers, Timeout: timeout}
+	pending := 0
+	for _, status := range waiter.Wait(cmd.Context(), domainName, changed, records) {
+		if status.Done {
+			statusf("✅ %s %s propagated in %s\n", status.HostName, status.Type, status.Elapsed.Round(time.Second))
+			continue
+		}
+		pending++
+		statusf("⏳ %s %s not served by %s after %s\n", status.HostName, status.Type, strings.Join(status.Pending, ", "), status.Elapsed.Round(time.Second))
+	}
+
+	if pending > 0 {
+		// The changes were applied; only their propagation is late
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d record set(s) did not propagate within %s", pending, timeout)
+	}
+	return nil
+}
+
+// warmCaches queries the public resolvers, and the configured and --warm-resolver
+// ones, for the changed record sets after a change made with --warm. Resolvers
+// cache the answer, so the next client asking gets it at once; each answer is
+// reported with the TTL it is cached for. Resolvers still serving old values
+// keep them until that TTL runs out, which is not an error.
+func warmCaches(cmd *cobra.Command, dnsService *dns.Service, domainName string, changed []dnsrecord.Record) error {
+	if warm, _ := cmd.Flags().GetBool("warm"); !warm || len(changed) == 0 {
+		return nil
+	}
+
+	addresses, _ := cmd.Flags().GetStringSlice("warm-resolver")
+	if configManager, err := GetConfigManager(); err == nil {
+		addresses = append(configManager.GetWarmResolvers(), addresses...)
+	}
+	servers := append(propagation.PublicResolvers(), propagation.Resolvers(addresses)...)
+
+	records, err := dnsService.GetRecords(domainName)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS records: %w", err)
+	}
+
+	statusf("\nPriming %d resolvers...\n", len(servers))
+	for _, answer := range propagation.Warm(cmd.Context(), servers, domainName, changed, records) {
+		name := answer.HostName + " " + answer.Type
+		switch {
+		case answer.Err != nil:
+			statusf("❌ %s at %s: %v\n", name, answer.Server, answer.Err)
+		case answer.Current && len(answer.Values) == 0:
+			statusf("✅ %s at %s: no records\n", name, answer.Server)
+		case answer.Current:
+			statusf("✅ %s at %s: cached for %s\n", name, answer.Server, dnsrecord.FormatTTL(answer.TTL))
+		case len(answer.Values) == 0:
+			statusf("⏳ %s at %s: still cached as missing\n", name, answer.Server)
+		default:
+			statusf("⏳ %s at %s: old values (%s) cached for %s\n", name, answer.Server, strings.Join(answer.Values, ", "), dnsrecord.FormatTTL(answer.TTL))
+		}
+	}
+	return nil
+}
+
+// changedRecords returns the records an apply created, updated or deleted
+func changedRecords(result *provider.ApplyResult) []dnsrecord.Record {
+	var changed []dnsrecord.Record
+	for _, r := range result.Records {
+		switch r.Status {
+		case provider.ApplyCreated, provider.ApplyUpdated, provider.ApplyDeleted:
+			changed = append(changed, r.Record)
+		}
+	}
+	return changed
+}
+
 // dnsDeleteCmd represents the dns delete command
 var dnsDeleteCmd = &cobra.Command{
-	Use:   "delete <domain> <hostname> <type>",
+	Use:   "delete <domain> [<hostname> <type>]",
 	Short: "Delete a DNS record",
-	Long:  `Delete a DNS record from the specified domain.`,
-	Args:  cobra.ExactArgs(3),
+	Long: `Delete a DNS record from the specified domain.
+
+Records are selected by hostname and type, or by ID with --id (see dns list --ids).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if id, _ := cmd.Flags().GetString("id"); id != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(3)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
-		hostname := args[1]
-		recordType := strings.ToUpper(args[2])
+		id, _ := cmd.Flags().GetString("id")
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
-		if err != nil {
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "delete a DNS record"); err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
 
-		dnsService := dns.NewService(client)
+		if id != "" {
+			if err := dnsService.DeleteRecordByID(domainName, id); err != nil {
+				return fmt.Errorf("failed to delete DNS record: %w", err)
+			}
+			statusf("Successfully deleted record %s\n", id)
+			return nil
+		}
+
+		hostname := args[1]
+		recordType := strings.ToUpper(args[2])
 		err = dnsService.DeleteRecord(domainName, hostname, recordType)
 		if err != nil {
 			return fmt.Errorf("failed to delete DNS record: %w", err)
 		}
 
-		fmt.Printf("Successfully deleted %s record: %s\n", recordType, hostname)
-		return nil
+		statusf("Successfully deleted %s record: %s\n", recordType, hostname)
+		return confirmPropagation(cmd, dnsService, domainName, []dnsrecord.Record{{HostName: hostname, RecordType: recordType}})
 	},
 }
 
@@ -270,30 +954,26 @@ var dnsClearCmd = &cobra.Command{
 
 		confirm, _ := cmd.Flags().GetBool("confirm")
 		if !confirm {
-			fmt.Printf("This will delete ALL DNS records for %s. Use --confirm to proceed.\n", domainName)
+			statusf("This will delete ALL DNS records for %s. Use --confirm to proceed.\n", domainName)
 			return nil
 		}
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
-		if err != nil {
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "delete ALL DNS records"); err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
 
-		dnsService := dns.NewService(client)
 		err = dnsService.DeleteAllRecords(domainName)
 		if err != nil {
 			return fmt.Errorf("failed to clear DNS records: %w", err)
 		}
 
-		fmt.Printf("Successfully cleared all DNS records for %s\n", domainName)
+		statusf("Successfully cleared all DNS records for %s\n", domainName)
 		return nil
 	},
 }
@@ -315,6 +995,7 @@ operations:
     hostname: mail
     type: A
     value: 192.168.1.2
+    ttl: 1h   # seconds or a duration such as 30m, 1h, 1d
   - action: delete
     hostname: old
     type: CNAME`,
@@ -323,176 +1004,1712 @@ operations:
 		domainName := args[0]
 		operationsFile := args[1]
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		output, err := outputFormat()
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
+		}
+
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+
+		// Parse the operations file
+		operations, err := parseBulkOperationsFile(operationsFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse operations file: %w", err)
+		}
+
+		if len(operations) == 0 {
+			return fmt.Errorf("no operations found in file %s", operationsFile)
+		}
+
+		// Show what will be done
+		current, desired, err := dnsService.PlanBulk(domainName, operations)
+		if err != nil {
+			return fmt.Errorf("failed to plan bulk operations: %w", err)
+		}
+		diff := diffview.Compute(domainName, current, desired)
+		confirm, _ := cmd.Flags().GetBool("confirm")
+
+		// Zones over the provider's record limit fail here rather than mid-write;
+		// when applying, the service repeats the warning
+		warning, err := dnsService.CheckRecordLimit(len(desired))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			printChangeWarnings(domainName, warning, current, desired)
+		}
+
+		if output.Structured() {
+			if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+				return err
+			}
+			if !confirm {
+				return nil
+			}
+		} else {
+			statusf("Applying %d bulk operations to %s\n", len(operations), domainName)
+			statusln("=====================================")
+			diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+			statusln()
+
+			// Confirm before proceeding
+			if !confirm {
+				statusln("Use --confirm to apply these changes.")
+				return nil
+			}
+		}
+
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "apply bulk DNS operations"); err != nil {
+			return err
+		}
+
+		// Apply the operations
+		result, err := dnsService.BulkApply(domainName, operations)
+		if result != nil {
+			printApplyResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply bulk operations: %w", err)
+		}
+
+		statusf("✅ Successfully applied %d bulk operations to %s\n", len(operations), domainName)
+		return confirmPropagation(cmd, dnsService, domainName, changedRecords(result))
+	},
+}
+
+// dnsImportCmd represents the dns import command
+var dnsImportCmd = &cobra.Command{
+	Use:   "import <domain> <zone-file>",
+	Short: "Import DNS records from a zone file",
+	Long: `Import DNS records from a BIND zone file, or another provider's export, into
+a domain.
+
+A, AAAA, CNAME, MX, TXT, NS, SRV, CAA, PTR, TLSA, SSHFP, NAPTR and DS records
+are imported; $ORIGIN, $TTL and records spanning several lines in parentheses
+are supported. The SOA record and NS records at the apex are skipped, as the
+provider manages them. Record types the provider does not serve are rejected.
+
+The format of the file is detected from its content, or set with --format:
+  bind          a BIND zone file
+  cloudflare    a Cloudflare export: names without $ORIGIN, and TTL 1 (automatic)
+                imported as the provider's default TTL
+  digitalocean  the JSON record list of doctl or the DigitalOcean API
+  octodns       an OctoDNS YAML zone file
+
+By default the file's records are added to the zone, and the import fails if it
+would change or remove any existing record. Choose what to do instead with:
+  --merge  replace existing records with the same hostname and type, keep others
+  --prune  make the zone match the file, removing records that are not in it
+
+The changes are previewed as a diff first and only applied with --confirm. Use
+--provider to import into a provider other than the account's.`,
+	Example: `  zonekit dns import example.com example.com.zone
+  zonekit dns import example.com example.com.zone --merge --confirm
+  zonekit dns import example.com example.com.zone --prune --confirm
+  zonekit dns import example.com example.com.yaml --format octodns`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		zoneFile := args[1]
+
+		// Validate domain
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		formatValue, _ := cmd.Flags().GetString("format")
+		format, err := zoneimport.ParseFormat(formatValue)
+		if err != nil {
+			return err
+		}
+		parsed, format, err := zoneimport.ParseFile(zoneFile, domainName, format)
+		if err != nil {
+			return err
+		}
+
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+
+		imported, skipped, err := importRecords(dnsService, domainName, zoneFile, parsed)
+		if err != nil {
+			return err
+		}
+		mode := importMode(cmd)
+
+		// A conflicting safe import is still previewed before it fails
+		current, desired, conflictErr := dnsService.PlanImport(domainName, imported, mode)
+		if conflictErr != nil && desired == nil {
+			return conflictErr
+		}
+		diff := diffview.Compute(domainName, current, desired)
+		confirm, _ := cmd.Flags().GetBool("confirm")
+
+		// Zones over the provider's record limit fail here rather than mid-write;
+		// when applying, the service repeats the warning
+		warning, err := dnsService.CheckRecordLimit(len(desired))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			printChangeWarnings(domainName, warning, current, desired)
+		}
+
+		if output.Structured() {
+			if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+				return err
+			}
+		} else {
+			statusf("Importing %d records from %s (%s) into %s (%s)\n", len(imported), zoneFile, format.Description(), domainName, mode)
+			if skipped > 0 {
+				statusf("Skipping %d apex NS records managed by the provider\n", skipped)
+			}
+			statusln("=====================================")
+			diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+			statusln()
+		}
+
+		if conflictErr != nil {
+			// The diff was printed; a usage message would only bury it
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w; use --merge to overwrite them or --prune to replace the zone", conflictErr)
+		}
+		if !diff.HasChanges() {
+			if !output.Structured() {
+				statusln("Nothing to import, the zone already matches.")
+			}
+			return nil
+		}
+		if !confirm {
+			if !output.Structured() {
+				statusln("Use --confirm to apply these changes.")
+			}
+			return nil
+		}
+
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "import DNS records from a zone file"); err != nil {
+			return err
+		}
+
+		result, err := dnsService.ApplyRecords(domainName, desired)
+		if result != nil {
+			printApplyResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import DNS records: %w", err)
+		}
+
+		statusf("✅ Successfully imported %d records into %s\n", len(imported), domainName)
+		return confirmPropagation(cmd, dnsService, domainName, changedRecords(result))
+	},
+}
+
+// dnsPlanCmd represents the dns plan command
+var dnsPlanCmd = &cobra.Command{
+	Use:   "plan <domain> [zone-file]",
+	Short: "Preview the changes of a zone or state file and save them for review",
+	Long: `Preview the changes importing a zone file or a desired-state file would make,
+as dns import and dns sync do without --confirm, and optionally save them.
+
+The records come from a BIND zone file, or with -f from a zone file or a YAML or
+JSON state file as read by dns sync (.yaml, .yml and .json files). A state file
+lists every record of the zone, so records not in it are removed unless --merge
+is given; for zone files --merge and --prune select the import mode as for dns
+import.
+
+--out saves the plan as a JSON file that can be reviewed or approved, e.g. by
+someone else or in CI, and is then applied exactly as planned with
+'zonekit dns apply'. The plan is refused if the zone changed in the meantime.
+
+The file written with --emit-bulk instead holds one operation per changed record
+set for 'zonekit dns bulk'. Sets of one record become updates; other changed
+sets are deleted and added back with their new values.`,
+	Example: `  zonekit dns plan example.com -f example.com.yaml --out plan.json
+  zonekit dns apply plan.json
+  zonekit dns plan example.com example.com.zone --prune --emit-bulk ops.yaml
+  zonekit dns bulk example.com ops.yaml --confirm`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		sourceFile, _ := cmd.Flags().GetString("file")
+		if len(args) == 2 {
+			if sourceFile != "" {
+				return fmt.Errorf("give the zone file as an argument or with --file, not both")
+			}
+			sourceFile = args[1]
+		}
+		if sourceFile == "" {
+			return fmt.Errorf("requires a zone file argument or --file")
+		}
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		mode := importMode(cmd)
+		var parsed []dnsrecord.Record
+		if isStateFile(sourceFile) {
+			parsed, err = zonestate.ParseFile(sourceFile, domainName)
+			if err != nil {
+				return err
+			}
+			// A state file holds the whole zone
+			if merge, _ := cmd.Flags().GetBool("merge"); !merge {
+				mode = dns.ImportPrune
+			}
+		} else {
+			parsed, err = zonefile.ParseFile(sourceFile, domainName)
+			if err != nil {
+				return fmt.Errorf("failed to parse zone file: %w", err)
+			}
+		}
+
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+
+		imported, skipped, err := importRecords(dnsService, domainName, sourceFile, parsed)
+		if err != nil {
+			return err
+		}
+
+		current, desired, conflictErr := dnsService.PlanImport(domainName, imported, mode)
+		if conflictErr != nil && desired == nil {
+			return conflictErr
+		}
+		diff := diffview.Compute(domainName, current, desired)
+		printChangeWarnings(domainName, "", current, desired)
+
+		if output.Structured() {
+			if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+				return err
+			}
+		} else {
+			statusf("Planning %d records from %s for %s (%s)\n", len(imported), sourceFile, domainName, mode)
+			if skipped > 0 {
+				statusf("Skipping %d apex NS records managed by the provider\n", skipped)
+			}
+			statusln("=====================================")
+			diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+			statusln()
+		}
+
+		if conflictErr != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w; use --merge to overwrite them or --prune to replace the zone", conflictErr)
+		}
+
+		if planFile, _ := cmd.Flags().GetString("out"); planFile != "" {
+			plan := dns.NewPlan(domainName, dnsService.ProviderName(), current, desired)
+			plan.Source = sourceFile
+			if accountConfig != nil {
+				plan.Account = GetCurrentAccountName()
+			}
+			if err := dns.SavePlan(planFile, plan); err != nil {
+				return err
+			}
+			statusf("Saved the plan to %s. Review it, then apply it with:\n", planFile)
+			statusf("  zonekit dns apply %s\n", planFile)
+		}
+
+		bulkFile, _ := cmd.Flags().GetString("emit-bulk")
+		if bulkFile == "" {
+			return nil
+		}
+		operations := dns.BulkOperationsFor(domainName, current, desired)
+		if len(operations) == 0 {
+			statusf("Nothing to change, %s was not written.\n", bulkFile)
+			return nil
+		}
+		if err := writeBulkOperationsFile(bulkFile, operations); err != nil {
+			return err
+		}
+		statusf("Wrote %d bulk operation(s) to %s. Review them, then apply with:\n", len(operations), bulkFile)
+		statusf("  zonekit dns bulk %s %s --confirm\n", domainName, bulkFile)
+		return nil
+	},
+}
+
+// isStateFile reports whether a file given to dns plan is a YAML or JSON
+// desired-state file rather than a BIND zone file
+func isStateFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// dnsApplyCmd represents the dns apply command
+var dnsApplyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a plan saved with dns plan --out",
+	Long: `Apply a plan saved with 'zonekit dns plan --out', writing exactly the records
+that were reviewed.
+
+The plan is only applied to the provider and account it was made for, and only
+if the zone has not changed since: otherwise it is refused and a new plan has to
+be made. The saved plan is the approval, so no --confirm is needed; accounts
+with production_guard still ask for confirmation.`,
+	Example: `  zonekit dns plan example.com -f example.com.yaml --out plan.json
+  zonekit dns apply plan.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		plan, err := dns.LoadPlan(args[0])
+		if err != nil {
+			return err
+		}
+		domainName := plan.Domain
+
+		providerName, _ := cmd.Flags().GetString("provider")
+		if providerName == "" && plan.Account != "" && plan.Account != GetCurrentAccountName() {
+			return fmt.Errorf("the plan was made for account %s; pass --account %s", plan.Account, plan.Account)
+		}
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+		if dnsService.ProviderName() != plan.Provider {
+			return fmt.Errorf("the plan was made for provider %s, not %s", plan.Provider, dnsService.ProviderName())
+		}
+
+		desired := plan.DesiredRecords()
+		for _, record := range desired {
+			if err := dnsService.ValidateRecordInZone(domainName, record); err != nil {
+				return fmt.Errorf("invalid %s record %s in plan: %w", record.RecordType, record.HostName, err)
+			}
+		}
+
+		current, err := dnsService.CheckPlan(plan)
+		if err != nil {
+			// A plan that was already applied changed the zone itself
+			if current != nil && !diffview.Compute(domainName, current, desired).HasChanges() {
+				statusf("Nothing to apply, %s already matches the plan.\n", domainName)
+				return nil
+			}
+			return err
+		}
+		diff := diffview.Compute(domainName, current, desired)
+
+		if output.Structured() {
+			if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+				return err
+			}
+		} else {
+			statusf("Applying the plan for %s made %s from %s\n", domainName, timeFormatter().Timestamp(plan.CreatedAt), plan.Source)
+			statusln("=====================================")
+			diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+			statusln()
+		}
+
+		if !diff.HasChanges() {
+			if !output.Structured() {
+				statusln("Nothing to apply, the zone already matches the plan.")
+			}
+			return nil
+		}
+
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "apply a saved DNS plan"); err != nil {
+			return err
+		}
+
+		result, err := dnsService.ApplyRecords(domainName, desired)
+		if result != nil {
+			printApplyResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply the plan: %w", err)
+		}
+
+		statusf("✅ Applied the plan to %s\n", domainName)
+		return confirmPropagation(cmd, dnsService, domainName, changedRecords(result))
+	},
+}
+
+// dnsSyncCmd represents the dns sync command
+var dnsSyncCmd = &cobra.Command{
+	Use:   "sync <domain> <state-file>",
+	Short: "Make a domain's records match a YAML or JSON state file",
+	Long: `Make the records of a domain match a desired-state file, replacing the
+clear-then-add workflow with the minimal set of changes.
+
+The state file lists every record the zone should have, in YAML or JSON:
+
+  domain: example.com
+  ttl: 30m
+  records:
+    - hostname: "@"
+      type: A
+      value: 192.0.2.1
+    - hostname: mail
+      type: MX
+      value: mx.example.com
+      mx_pref: 10
+
+The file is compared with the live records and the records to add, change and
+delete are previewed as a diff. With --confirm, only those records are written;
+unchanged records are left alone. NS records at the apex are skipped, as the
+provider manages them.`,
+	Example: `  zonekit dns sync example.com example.com.yaml
+  zonekit dns sync example.com example.com.yaml --confirm
+  zonekit dns sync example.com example.com.json -o json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		stateFile := args[1]
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		parsed, err := zonestate.ParseFile(stateFile, domainName)
+		if err != nil {
+			return err
+		}
+
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+
+		desired, skipped, err := importRecords(dnsService, domainName, stateFile, parsed)
+		if err != nil {
+			return err
+		}
+		current, desired, err := dnsService.PlanImport(domainName, desired, dns.ImportPrune)
+		if err != nil {
+			return err
+		}
+		diff := diffview.Compute(domainName, current, desired)
+		confirm, _ := cmd.Flags().GetBool("confirm")
+
+		warning, err := dnsService.CheckRecordLimit(len(desired))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			printChangeWarnings(domainName, warning, current, desired)
+		}
+
+		if output.Structured() {
+			if err := render.Encode(os.Stdout, output, diffview.NewDocument(diff, diffview.Options{})); err != nil {
+				return err
+			}
+		} else {
+			statusf("Syncing %s with %d records from %s\n", domainName, len(desired), stateFile)
+			if skipped > 0 {
+				statusf("Skipping %d apex NS records managed by the provider\n", skipped)
+			}
+			statusln("=====================================")
+			diffview.Render(os.Stdout, diff, diffview.Options{Color: colorOutput()})
+			statusln()
+		}
+
+		if !diff.HasChanges() {
+			if !output.Structured() {
+				statusln("Nothing to sync, the zone already matches.")
+			}
+			return nil
+		}
+		if !confirm {
+			if !output.Structured() {
+				statusln("Use --confirm to apply these changes.")
+			}
+			return nil
+		}
+
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "sync DNS records with a state file"); err != nil {
+			return err
+		}
+
+		result, err := dnsService.ApplyRecords(domainName, desired)
+		if result != nil {
+			printApplyResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to sync DNS records: %w", err)
+		}
+
+		statusf("✅ %s matches %s\n", domainName, stateFile)
+		return confirmPropagation(cmd, dnsService, domainName, changedRecords(result))
+	},
+}
+
+// importRecords returns the records of a parsed zone or state file that dns
+// import, dns plan and dns sync write, validated for the provider. Apex NS records are left out, as
+// the provider manages them; skipped is their number.
+func importRecords(dnsService *dns.Service, domainName, zoneFile string, parsed []dnsrecord.Record) (imported []dnsrecord.Record, skipped int, err error) {
+	for _, record := range parsed {
+		if record.HostName == "@" && record.RecordType == dnsrecord.RecordTypeNS {
+			skipped++
+			continue
+		}
+		if err := dnsService.ValidateRecordInZone(domainName, record); err != nil {
+			return nil, 0, fmt.Errorf("invalid %s record %s: %w", record.RecordType, record.HostName, err)
+		}
+		imported = append(imported, record)
+	}
+	if len(imported) == 0 {
+		return nil, 0, fmt.Errorf("no records to import in %s", zoneFile)
+	}
+	return imported, skipped, nil
+}
+
+// printChangeWarnings prints the record limit warning and the warnings about
+// suspicious changes of a previewed change, unless --no-warnings is set. When
+// the change is applied, the service repeats them.
+func printChangeWarnings(domainName, limitWarning string, current, desired []dnsrecord.Record) {
+	if noWarnings {
+		return
+	}
+	if limitWarning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", limitWarning)
+	}
+	for _, warning := range dns.ChangeWarnings(domainName, current, desired) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// importMode returns the import mode selected with --merge or --prune
+func importMode(cmd *cobra.Command) dns.ImportMode {
+	if merge, _ := cmd.Flags().GetBool("merge"); merge {
+		return dns.ImportMerge
+	}
+	if prune, _ := cmd.Flags().GetBool("prune"); prune {
+		return dns.ImportPrune
+	}
+	return dns.ImportSafe
+}
+
+// Formats of dns export
+const (
+	exportBIND      = "bind"
+	exportJSON      = "json"
+	exportYAML      = "yaml"
+	exportCSV       = "csv"
+	exportTerraform = "terraform"
+)
+
+// dnsExportCmd represents the dns export command
+var dnsExportCmd = &cobra.Command{
+	Use:   "export <domain> [output-file]",
+	Short: "Export DNS records to a zone file",
+	Long: `Export all DNS records of a domain, to stdout or a file.
+
+--format selects the format:
+  bind       a standard DNS zone file (default)
+  json, yaml a desired-state file that dns sync applies as is
+  csv        one row per record for spreadsheets
+  terraform  resource blocks for --terraform-provider cloudflare (one per
+             record) or route53 (one per record set), using var.zone_id`,
+	Example: `  zonekit dns export example.com example.com.zone
+  zonekit dns export example.com example.com.yaml --format yaml
+  zonekit dns export example.com --format csv > records.csv
+  zonekit dns export example.com dns.tf --format terraform --terraform-provider route53`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		outputFile := ""
+		if len(args) > 1 {
+			outputFile = args[1]
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		tfProvider, _ := cmd.Flags().GetString("terraform-provider")
+		switch format {
+		case exportBIND, exportJSON, exportYAML, exportCSV:
+		case exportTerraform:
+			if !slices.Contains(terraform.Providers(), tfProvider) {
+				return fmt.Errorf("invalid --terraform-provider %q (must be %s)", tfProvider, strings.Join(terraform.Providers(), " or "))
+			}
+		default:
+			return fmt.Errorf("invalid --format %q (must be bind, json, yaml, csv or terraform)", format)
+		}
+
+		// Use the account's provider, or the one selected with --provider
+		dnsService, _, err := resolveDNSService(cmd, args, domainName, true)
+		if err != nil {
+			return err
+		}
+
+		records, err := dnsService.GetRecords(domainName)
+		if err != nil {
+			return fmt.Errorf("failed to get DNS records: %w", err)
+		}
+
+		var content bytes.Buffer
+		if err := writeExport(&content, format, tfProvider, domainName, records); err != nil {
+			return err
+		}
+
+		if outputFile != "" {
+			// Write to file
+			err = os.WriteFile(outputFile, content.Bytes(), 0644)
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputFile, err)
+			}
+			statusf("✅ Exported %d records from %s to %s\n", len(records), domainName, outputFile)
+		} else {
+			// Write to stdout
+			if format == exportBIND {
+				statusf("Zone file for %s:\n", domainName)
+				statusln("=====================================")
+			}
+			_, err = os.Stdout.Write(content.Bytes())
+			return err
+		}
+
+		return nil
+	},
+}
+
+// writeExport writes the records of a domain in an export format
+func writeExport(w io.Writer, format, tfProvider, domainName string, records []dnsrecord.Record) error {
+	switch format {
+	case exportJSON:
+		return render.Encode(w, render.FormatJSON, zonestate.FromRecords(domainName, records))
+	case exportYAML:
+		return render.Encode(w, render.FormatYAML, zonestate.FromRecords(domainName, records))
+	case exportCSV:
+		table := render.NewTable("HOSTNAME", "TYPE", "VALUE", "TTL", "MX_PREF")
+		for _, r := range records {
+			mxPref := ""
+			if r.RecordType == dnsrecord.RecordTypeMX {
+				mxPref = strconv.Itoa(r.MXPref)
+			}
+			table.AddRow(r.HostName, r.RecordType, r.Address, strconv.Itoa(r.TTL), mxPref)
+		}
+		return table.WriteCSV(w)
+	case exportTerraform:
+		return terraform.Write(w, tfProvider, domainName, records)
+	default:
+		_, err := io.WriteString(w, formatAsZoneFile(domainName, records))
+		return err
+	}
+}
+
+// dnsChangelogCmd represents the dns changelog command
+var dnsChangelogCmd = &cobra.Command{
+	Use:   "changelog <domain>",
+	Short: "Show recorded DNS changes as Markdown",
+	Long: `Render the DNS changes zonekit has applied to a domain as a Markdown changelog
+(who, when, what), suitable for incident reviews and change-management tickets.
+
+Changes are recorded in the local state (see zonekit state) whenever zonekit
+applies records, so only changes made through zonekit on this machine are included.`,
+	Example: `  zonekit dns changelog example.com --since 30d
+  zonekit dns changelog example.com --since 2w > changes.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		sinceValue, _ := cmd.Flags().GetString("since")
+
+		var since time.Time
+		if sinceValue != "" {
+			window, err := history.ParseSince(sinceValue)
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+			since = time.Now().Add(-window)
+		}
+
+		store, err := openState()
+		if err != nil {
+			return err
+		}
+		entries, err := history.NewStore(store).Query(domainName, since)
+		if err != nil {
+			return fmt.Errorf("failed to read change history: %w", err)
+		}
+
+		fmt.Print(history.RenderMarkdown(domainName, since, entries, timeFormatter().Timestamp))
+		return nil
+	},
+}
+
+// dnsVerifyCmd represents the dns verify command
+var dnsVerifyCmd = &cobra.Command{
+	Use:   "verify <domain>",
+	Short: "Compare provider records, live DNS answers and a zone file",
+	Long: `Compare two or three views of a zone record set by record set and exit with an
+error if any of them differ:
+
+  provider  the records the DNS provider's API returns
+  dns       the answers of a resolver (the system's, or --resolver), queried for
+            every record set the other sources hold
+  file      a BIND zone file given with --file
+
+A single --against source is compared with the provider. TTLs are not compared,
+as resolvers count them down, and neither are NS records at the apex, which
+belong to the delegation. Record types resolvers cannot be asked for, such as
+URL redirects, are only compared between provider and file.`,
+	Example: `  zonekit dns verify example.com
+  zonekit dns verify example.com --against file --file example.com.zone
+  zonekit dns verify example.com --against provider,dns,file --file example.com.zone
+  zonekit dns verify example.com --against dns,file --file example.com.zone --resolver 1.1.1.1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		against, _ := cmd.Flags().GetStringSlice("against")
+		zoneFile, _ := cmd.Flags().GetString("file")
+		server, _ := cmd.Flags().GetString("resolver")
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		sourceNames, err := zonecompare.ParseSources(against)
+		if err != nil {
+			return fmt.Errorf("invalid --against value: %w", err)
+		}
+		uses := make(map[string]bool, len(sourceNames))
+		for _, name := range sourceNames {
+			uses[name] = true
+		}
+		if uses[zonecompare.SourceFile] && zoneFile == "" {
+			return fmt.Errorf("--file is required to compare against a zone file")
+		}
+		if !uses[zonecompare.SourceFile] && zoneFile != "" {
+			return fmt.Errorf("--file is only used with --against file")
+		}
+
+		records := make(map[string][]dnsrecord.Record)
+		if uses[zonecompare.SourceFile] {
+			parsed, err := zonefile.ParseFile(zoneFile, domainName)
+			if err != nil {
+				return fmt.Errorf("failed to parse zone file: %w", err)
+			}
+			records[zonecompare.SourceFile] = parsed
+		}
+		if uses[zonecompare.SourceProvider] {
+			// Use the account's provider, or the one selected with --provider
+			dnsService, _, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+			if err != nil {
+				return err
+			}
+			current, err := dnsService.GetRecords(domainName)
+			if err != nil {
+				return fmt.Errorf("failed to get DNS records: %w", err)
+			}
+			records[zonecompare.SourceProvider] = current
+		}
+		if uses[zonecompare.SourceDNS] {
+			resolver := lookup.NewResolver(server)
+			answers, err := zonecompare.Resolve(cmd.Context(), resolver, lookup.Supports, domainName,
+				records[zonecompare.SourceProvider], records[zonecompare.SourceFile])
+			if err != nil {
+				return err
+			}
+			records[zonecompare.SourceDNS] = answers
+		}
+
+		sources := make([]zonecompare.Source, 0, len(sourceNames))
+		for _, name := range sourceNames {
+			source := zonecompare.Source{Name: name, Records: records[name]}
+			if name == zonecompare.SourceDNS {
+				source.Covers = lookup.Supports
+			}
+			sources = append(sources, source)
+		}
+		report := zonecompare.Compare(domainName, sources...)
+		mismatches := report.Mismatches()
+
+		if output.Structured() {
+			if err := writeOutput(output, newVerifyTable(report), newVerifyView(report)); err != nil {
+				return err
+			}
+		} else if err := printVerifyReport(report); err != nil {
+			return err
+		}
+
+		if mismatches > 0 {
+			// The comparison was printed; a usage message would only bury it
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d record set(s) differ between %s", mismatches, strings.Join(sourceNames, ", "))
+		}
+		return nil
+	},
+}
+
+// newVerifyTable builds the comparison table with one column per source
+func newVerifyTable(report *zonecompare.Report) *render.Table {
+	columns := []string{"HOSTNAME", "TYPE"}
+	for _, source := range report.Sources {
+		columns = append(columns, strings.ToUpper(source))
+	}
+	table := render.NewTable(append(columns, "STATUS")...)
+
+	for _, row := range report.Rows {
+		cells := []string{row.HostName, row.Type}
+		for _, source := range report.Sources {
+			values, covered := row.Values[source]
+			switch {
+			case !covered:
+				cells = append(cells, "n/a")
+			case len(values) == 0:
+				cells = append(cells, "-")
+			default:
+				cells = append(cells, strings.Join(values, ", "))
+			}
+		}
+		status := "ok"
+		if !row.Match {
+			status = "MISMATCH"
+		}
+		table.AddRow(append(cells, status)...)
+	}
+	return table
+}
+
+// printVerifyReport prints a comparison as a table followed by a summary
+func printVerifyReport(report *zonecompare.Report) error {
+	if len(report.Rows) == 0 {
+		statusln("No records to compare.")
+		return nil
+	}
+
+	if err := writeOutput(render.FormatTable, newVerifyTable(report), nil); err != nil {
+		return err
+	}
+
+	statusf("\n%d of %d record set(s) match across %s\n",
+		len(report.Rows)-report.Mismatches(), len(report.Rows), strings.Join(report.Sources, ", "))
+	return nil
+}
+
+// dnsWatchCmd represents the dns watch command
+var dnsWatchCmd = &cobra.Command{
+	Use:   "watch <domain>",
+	Short: "Report changes to a domain's records as they happen",
+	Long: `Poll a domain's records on an interval and report every record set that
+changes between polls, to catch edits made outside zonekit and hijacked
+delegations. The sources watched are:
+
+  provider  the records the DNS provider's API returns
+  dns       the answers of a resolver (the system's, or --resolver) for the
+            provider's record sets and the NS records at the apex
+
+Provider changes not applied by zonekit on this machine (as found in the
+change history) are reported as external. On every change, --webhook posts
+the changes as JSON and --exec runs a shell command with them on its stdin
+and ZONEKIT_DOMAIN and ZONEKIT_CHANGES in its environment. The watch runs
+until interrupted; failed polls are reported and retried at the next one.`,
+	Example: `  zonekit dns watch example.com
+  zonekit dns watch example.com --interval 1m --source dns --resolver 1.1.1.1
+  zonekit dns watch example.com --webhook https://hooks.example.net/dns
+  zonekit dns watch example.com --exec 'mail -s "DNS change" ops@example.com' -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		interval, _ := cmd.Flags().GetDuration("interval")
+		sourceValues, _ := cmd.Flags().GetStringSlice("source")
+		server, _ := cmd.Flags().GetString("resolver")
+		webhook, _ := cmd.Flags().GetString("webhook")
+		command, _ := cmd.Flags().GetString("exec")
+
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+		if interval < time.Minute {
+			return fmt.Errorf("interval must be at least 1m to stay within API rate limits")
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		output, err := outputFormat()
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
-
-		dnsService := dns.NewService(client)
 
-		// Parse the operations file
-		operations, err := parseBulkOperationsFile(operationsFile)
+		sources, err := watch.ParseSources(sourceValues)
 		if err != nil {
-			return fmt.Errorf("failed to parse operations file: %w", err)
+			return fmt.Errorf("invalid --source value: %w", err)
+		}
+		uses := make(map[string]bool, len(sources))
+		for _, name := range sources {
+			uses[name] = true
 		}
 
-		if len(operations) == 0 {
-			return fmt.Errorf("no operations found in file %s", operationsFile)
+		// The dns source queries the provider's record sets, so the provider
+		// is read either way
+		dnsService, _, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
 		}
+		resolver := lookup.NewResolver(server)
 
-		// Show what will be done
-		fmt.Printf("Applying %d bulk operations to %s\n", len(operations), domainName)
-		fmt.Println("=====================================")
+		poll := func(ctx context.Context) (watch.Snapshot, error) {
+			current, err := dnsService.RefreshRecords(domainName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get DNS records: %w", err)
+			}
 
-		for i, op := range operations {
-			action := strings.Title(op.Action)
-			fmt.Printf("%d. %s %s %s → %s", i+1, action, op.Record.HostName, op.Record.RecordType, op.Record.Address)
-			if op.Record.TTL > 0 {
-				fmt.Printf(" (TTL: %d)", op.Record.TTL)
+			snapshot := watch.Snapshot{}
+			if uses[watch.SourceProvider] {
+				snapshot[watch.SourceProvider] = current
 			}
-			if op.Record.MXPref > 0 {
-				fmt.Printf(" (Priority: %d)", op.Record.MXPref)
+			if uses[watch.SourceDNS] {
+				answers, err := zonecompare.Resolve(ctx, resolver, lookup.Supports, domainName, current)
+				if err != nil {
+					return nil, err
+				}
+				delegation, err := resolver.Lookup(ctx, domainName, "@", dnsrecord.RecordTypeNS)
+				if err != nil {
+					return nil, err
+				}
+				snapshot[watch.SourceDNS] = append(answers, delegation...)
 			}
-			fmt.Println()
-		}
-		fmt.Println()
-
-		// Confirm before proceeding
-		confirm, _ := cmd.Flags().GetBool("confirm")
-		if !confirm {
-			fmt.Println("Use --confirm to apply these changes.")
-			return nil
+			return snapshot, nil
 		}
 
-		// Apply the operations
-		err = dnsService.BulkUpdate(domainName, operations)
-		if err != nil {
-			return fmt.Errorf("failed to apply bulk operations: %w", err)
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		watcher := &watch.Watcher{
+			Domain:   domainName,
+			Interval: interval,
+			Poll:     poll,
+			Recorded: func(since time.Time) bool {
+				store, err := openState()
+				if err != nil {
+					return false
+				}
+				entries, err := history.NewStore(store).Query(domainName, since)
+				if err != nil {
+					return false
+				}
+				for _, entry := range entries {
+					if entry.Failed() < len(entry.Changes) {
+						return true
+					}
+				}
+				return false
+			},
+			Changed: func(event watch.Event) {
+				if output.Structured() {
+					if err := render.Encode(os.Stdout, output, event); err != nil {
+						statusf("Error: %v\n", err)
+					}
+				} else {
+					printWatchEvent(event)
+				}
+				if webhook != "" {
+					if err := watch.PostWebhook(ctx, nil, webhook, event); err != nil {
+						statusf("Error: %v\n", err)
+					}
+				}
+				if command != "" {
+					if err := watch.RunCommand(ctx, command, event); err != nil {
+						statusf("Error: %v\n", err)
+					}
+				}
+			},
+			Failed: func(err error) {
+				statusf("%s Error: %v\n", timeFormatter().Timestamp(time.Now()), err)
+			},
 		}
 
-		fmt.Printf("✅ Successfully applied %d bulk operations to %s\n", len(operations), domainName)
-		return nil
+		statusf("Watching %s (%s) every %s; press Ctrl+C to stop\n", domainName, strings.Join(sources, ", "), interval)
+		return watcher.Run(ctx)
 	},
 }
 
-// dnsImportCmd represents the dns import command
-var dnsImportCmd = &cobra.Command{
-	Use:   "import <domain> <zone-file>",
-	Short: "Import DNS records from a zone file",
-	Long:  `Import DNS records from a standard DNS zone file format.`,
-	Args:  cobra.ExactArgs(2),
+// printWatchEvent prints the changes of a watch event, one record set per line
+func printWatchEvent(event watch.Event) {
+	origin := "external"
+	if event.Recorded {
+		origin = "applied by zonekit"
+	}
+	for _, c := range event.Changes {
+		what := "changed"
+		switch {
+		case len(c.Before) == 0:
+			what = "added"
+		case len(c.After) == 0:
+			what = "removed"
+		}
+		line := fmt.Sprintf("%s %s %s %s %s", timeFormatter().Timestamp(event.Time), c.Source, c.HostName, c.Type, what)
+		if c.Source == watch.SourceProvider {
+			line += " (" + origin + ")"
+		}
+		fmt.Println(line)
+		if len(c.Before) > 0 {
+			fmt.Printf("  - %s\n", strings.Join(c.Before, ", "))
+		}
+		if len(c.After) > 0 {
+			fmt.Printf("  + %s\n", strings.Join(c.After, ", "))
+		}
+	}
+}
+
+// dnsStaleCmd represents the dns stale command
+var dnsStaleCmd = &cobra.Command{
+	Use:   "stale <domain>",
+	Short: "Find records that point to dead infrastructure",
+	Long: `Probe the targets of a domain's records and flag those that no longer answer
+as candidates for cleanup:
+
+  A, AAAA    a TCP connection to one of --ports is accepted or refused
+  CNAME, MX  the target name resolves
+  NS         the name server answers a query for the delegated zone
+
+Private addresses are skipped, as they cannot be reached from everywhere, and
+other record types are not checked. A stale record is only a candidate: a host
+may be down for maintenance or firewalled, so review before deleting.`,
+	Example: `  zonekit dns stale example.com
+  zonekit dns stale example.com --ports 22,80,443 --timeout 5s
+  zonekit dns stale example.com -o json`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
-		zoneFile := args[1]
+		ports, _ := cmd.Flags().GetIntSlice("ports")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		if err := dns.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+		for _, port := range ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("invalid port %d", port)
+			}
+		}
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		dnsService, _, err := resolveDNSService(cmd, args, domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+		records, err := dnsService.GetRecords(domainName)
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return fmt.Errorf("failed to get DNS records: %w", err)
 		}
 
-		// Show which account is being used
-		fmt.Printf("Using account: %s (%s)\n", accountConfig.Username, accountConfig.Description)
-		fmt.Println()
+		checker := stale.NewChecker()
+		checker.Ports = ports
+		checker.Timeout = timeout
+		results := checker.Check(cmd.Context(), domainName, records)
 
-		// TODO: Implement zone file import
-		// This would involve:
-		// 1. Parsing the zone file format
-		// 2. Converting to DNS records
-		// 3. Setting all records at once
+		if output.Structured() {
+			return writeOutput(output, newStaleTable(results), newStaleView(domainName, results))
+		}
 
-		return fmt.Errorf("zone file import not yet implemented - TODO: parse %s and import to %s", zoneFile, domainName)
+		if len(results) == 0 {
+			statusln("No records to check.")
+			return nil
+		}
+		if err := writeOutput(render.FormatTable, newStaleTable(results), nil); err != nil {
+			return err
+		}
+		statusf("\n%d of %d record(s) look stale\n", countStale(results), len(results))
+		return nil
 	},
 }
 
-// dnsExportCmd represents the dns export command
-var dnsExportCmd = &cobra.Command{
-	Use:   "export <domain> [output-file]",
-	Short: "Export DNS records to a zone file",
-	Long:  `Export all DNS records to a standard DNS zone file format.`,
-	Args:  cobra.RangeArgs(1, 2),
+// newStaleTable builds the table of checked records
+func newStaleTable(results []stale.Result) *render.Table {
+	table := render.NewTable("HOSTNAME", "TYPE", "VALUE", "STATUS", "REASON")
+	for _, result := range results {
+		status := string(result.Status)
+		if result.Status == stale.StatusStale {
+			status = "STALE"
+		}
+		table.AddRow(result.Record.HostName, result.Record.RecordType, result.Record.Address, status, result.Reason)
+	}
+	return table
+}
+
+func countStale(results []stale.Result) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == stale.StatusStale {
+			count++
+		}
+	}
+	return count
+}
+
+// dnsDDNSCmd represents the dns ddns command
+var dnsDDNSCmd = &cobra.Command{
+	Use:   "ddns <domain> [hostname]",
+	Short: "Point a host at this machine's public IP address",
+	Long: `Update the A record of a host to this machine's public address, like a dynamic
+DNS client. The address is detected over HTTPS unless it is given with --ip.
+Nothing is written if the record already has it.
+
+--ipv6 also updates the AAAA record in the same run, and --ipv4=false skips the
+A record; the ipv4.enabled and ipv6.enabled settings of the ddns section of the
+config file set the defaults. --type updates a single record type instead. A
+failed update of one record type does not stop the other.
+
+Address sources are tried in order until one answers. --source replaces the
+sources of the ddns section of the config file for every updated record type and
+can be repeated:
+  https://...        an HTTPS endpoint answering with the address as plain text
+  iface:<name>       the first global address of a local network interface
+  upnp[:<url>]       the router's external address over UPnP IGD (IPv4 only)
+  exec:<command>     the first line a shell command prints
+With --consensus 2, a record is only updated once two sources report the same
+address.
+
+Hosts with IPv6 privacy extensions connect from temporary addresses that change
+every day or so. A temporary address of this machine is replaced with the stable
+address of the same interface before it is published, and interface sources
+pick the stable address; --prefer-ipv6-privacy publishes the temporary address.
+
+The hostname defaults to @. With --provider, the update goes through a registered
+provider instead of the account, including the update-only providers dynu
+(DYNU_USERNAME, DYNU_PASSWORD) and duckdns (DUCKDNS_TOKEN).`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		domainName := args[0]
-		outputFile := ""
+		req := ddns.Request{Domain: args[0], Host: "@"}
 		if len(args) > 1 {
-			outputFile = args[1]
+			req.Host = args[1]
 		}
+		req.Address, _ = cmd.Flags().GetString("ip")
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+		if err := dns.ValidateDomain(req.Domain); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+		if err := dns.ValidateHostname(req.Host); err != nil {
+			return fmt.Errorf("invalid hostname: %w", err)
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		settings := ddnsSettings()
+		recordTypes, err := ddnsRecordTypes(cmd, settings)
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if req.Address != "" && len(recordTypes) > 1 {
+			return fmt.Errorf("--ip sets the address of one record type; select it with --type")
+		}
 
-		dnsService := dns.NewService(client)
-		records, err := dnsService.GetRecords(domainName)
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, req.Domain, true)
 		if err != nil {
-			return fmt.Errorf("failed to get DNS records: %w", err)
+			return err
 		}
 
-		// Convert records to zone file format
-		zoneContent := formatAsZoneFile(domainName, records)
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, req.Domain, "update a DNS record"); err != nil {
+			return err
+		}
 
-		if outputFile != "" {
-			// Write to file
-			err = os.WriteFile(outputFile, []byte(zoneContent), 0644)
-			if err != nil {
-				return fmt.Errorf("failed to write zone file: %w", err)
+		results, err := ddns.UpdateAll(cmd.Context(), dnsService, ddnsDetector(cmd, settings, recordTypes), req, recordTypes)
+		for _, result := range results {
+			if result.Changed {
+				statusf("Updated %s record: %s -> %s\n", result.RecordType, result.Host, result.Address)
+			} else {
+				statusf("%s record %s already points to %s\n", result.RecordType, result.Host, result.Address)
 			}
-			fmt.Printf("✅ Exported %d records from %s to %s\n", len(records), domainName, outputFile)
-		} else {
-			// Write to stdout
-			fmt.Printf("Zone file for %s:\n", domainName)
-			fmt.Println("=====================================")
-			fmt.Print(zoneContent)
+			recordDDNSAddress(result)
 		}
-
-		return nil
+		return err
 	},
 }
 
+// ddnsSettings returns the ddns section of the config file
+func ddnsSettings() config.DDNSConfig {
+	if configManager, err := GetConfigManager(); err == nil {
+		return configManager.GetDDNS()
+	}
+	return config.DDNSConfig{}
+}
+
+// ddnsRecordTypes returns the record types to update: the one given with --type,
+// or the families enabled with --ipv4 and --ipv6, which default to the config
+// file and to IPv4 only
+func ddnsRecordTypes(cmd *cobra.Command, settings config.DDNSConfig) ([]string, error) {
+	if cmd.Flags().Changed("type") {
+		recordType, _ := cmd.Flags().GetString("type")
+		return []string{strings.ToUpper(recordType)}, nil
+	}
+
+	enabled := func(flag string, setting *bool, fallback bool) bool {
+		if cmd.Flags().Changed(flag) {
+			value, _ := cmd.Flags().GetBool(flag)
+			return value
+		}
+		if setting != nil {
+			return *setting
+		}
+		return fallback
+	}
+
+	var recordTypes []string
+	if enabled("ipv4", settings.IPv4.Enabled, true) {
+		recordTypes = append(recordTypes, dnsrecord.RecordTypeA)
+	}
+	if enabled("ipv6", settings.IPv6.Enabled, false) {
+		recordTypes = append(recordTypes, dnsrecord.RecordTypeAAAA)
+	}
+	if len(recordTypes) == 0 {
+		return nil, fmt.Errorf("both IPv4 and IPv6 are disabled; enable one with --ipv4 or --ipv6")
+	}
+	return recordTypes, nil
+}
+
+// ddnsDetector returns the address detector configured by the ddns section of the
+// config file, --source, --consensus and --prefer-ipv6-privacy. Record types
+// without configured sources use the default HTTPS endpoints.
+func ddnsDetector(cmd *cobra.Command, settings config.DDNSConfig, recordTypes []string) *ddns.Detector {
+	detector := ddns.NewDetector()
+	detector.Consensus = settings.Consensus
+	detector.PreferIPv6Privacy = settings.IPv6.PreferPrivacy
+	detector.Sources = map[string][]string{
+		dnsrecord.RecordTypeA:    ddns.DefaultSources[dnsrecord.RecordTypeA],
+		dnsrecord.RecordTypeAAAA: ddns.DefaultSources[dnsrecord.RecordTypeAAAA],
+	}
+	if len(settings.IPv4.Sources) > 0 {
+		detector.Sources[dnsrecord.RecordTypeA] = settings.IPv4.Sources
+	}
+	if len(settings.IPv6.Sources) > 0 {
+		detector.Sources[dnsrecord.RecordTypeAAAA] = settings.IPv6.Sources
+	}
+
+	if sources, _ := cmd.Flags().GetStringArray("source"); len(sources) > 0 {
+		for _, recordType := range recordTypes {
+			detector.Sources[recordType] = sources
+		}
+	}
+	if cmd.Flags().Changed("consensus") {
+		detector.Consensus, _ = cmd.Flags().GetInt("consensus")
+	}
+	if cmd.Flags().Changed("prefer-ipv6-privacy") {
+		detector.PreferIPv6Privacy, _ = cmd.Flags().GetBool("prefer-ipv6-privacy")
+	}
+	return detector
+}
+
+// ddnsAddress is the last address dns ddns published for a record
+type ddnsAddress struct {
+	Address string `json:"address"`
+}
+
+// recordDDNSAddress keeps the published address in the local state. Failing to
+// write it is not fatal.
+func recordDDNSAddress(result *ddns.Result) {
+	store, err := openState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	key := fmt.Sprintf("%s/%s/%s", strings.ToLower(result.Domain), strings.ToLower(result.Host), result.RecordType)
+	if err := store.Put(state.BucketDDNS, key, ddnsAddress{Address: result.Address}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(dnsCmd)
 	dnsCmd.AddCommand(dnsListCmd)
+	dnsCmd.AddCommand(dnsFindCmd)
 	dnsCmd.AddCommand(dnsAddCmd)
 	dnsCmd.AddCommand(dnsUpdateCmd)
 	dnsCmd.AddCommand(dnsDeleteCmd)
 	dnsCmd.AddCommand(dnsClearCmd)
 	dnsCmd.AddCommand(dnsBulkCmd)
 	dnsCmd.AddCommand(dnsImportCmd)
+	dnsCmd.AddCommand(dnsPlanCmd)
+	dnsCmd.AddCommand(dnsApplyCmd)
+	dnsCmd.AddCommand(dnsSyncCmd)
 	dnsCmd.AddCommand(dnsExportCmd)
+	dnsCmd.AddCommand(dnsChangelogCmd)
+	dnsCmd.AddCommand(dnsDDNSCmd)
+	dnsCmd.AddCommand(dnsVerifyCmd)
+	dnsCmd.AddCommand(dnsStaleCmd)
+	dnsCmd.AddCommand(dnsWatchCmd)
+
+	// Flags for all dns commands
 
 	// Flags for dns list
 	dnsListCmd.Flags().StringP("type", "t", "", "Filter by record type (A, AAAA, CNAME, MX, TXT, etc.)")
+	dnsListCmd.Flags().Bool("all-domains", false, "List records across all domains in the account")
+	dnsListCmd.Flags().String("host", "", "Filter by hostname (supports globs like '_acme*')")
+	dnsListCmd.Flags().String("value", "", "Filter by records whose value contains this text")
+	dnsListCmd.Flags().Bool("seconds", false, "Show TTLs in seconds instead of durations like 30m or 1d")
+	dnsListCmd.Flags().Bool("ids", false, "Show record IDs, which dns update and dns delete accept with --id")
+	dnsListCmd.Flags().Bool("show-comments", false, "Show record comments and tags")
+	dnsListCmd.Flags().StringSlice("tag", nil, "Only list records with this tag (repeatable; records must have all)")
+	dnsListCmd.Flags().Bool("raw", false, "Include the provider's untouched API responses under \"raw\" (with -o json or yaml)")
+
+	dnsFindCmd.Flags().Bool("account-all", false, "Search the domains of every configured account")
+	dnsFindCmd.Flags().StringP("type", "t", "", "Only find records of this type")
+	dnsFindCmd.Flags().String("host", "", "Only find records at hostnames matching this glob, e.g. '_acme*'")
+	dnsFindCmd.Flags().String("value", "", "Find records whose value contains this text")
+	dnsFindCmd.Flags().StringSlice("tag", nil, "Only find records with this tag (repeatable; records must have all)")
 
 	// Flags for dns add
-	dnsAddCmd.Flags().IntP("ttl", "", 0, "TTL value (Time To Live)")
+	dnsAddCmd.Flags().String("ttl", "", "TTL in seconds or as a duration (e.g. 300, 30m, 1h, 1d); defaults to the provider's default TTL")
 	dnsAddCmd.Flags().IntP("mx-pref", "", 0, "MX preference value (for MX records)")
+	dnsAddCmd.Flags().Int("priority", 0, "SRV priority; lower is tried first (for SRV records)")
+	dnsAddCmd.Flags().Int("weight", 0, "SRV weight among targets of the same priority (for SRV records)")
+	dnsAddCmd.Flags().Int("port", 0, "SRV port of the service (for SRV records)")
+	dnsAddCmd.Flags().String("target", "", "SRV target host, or \".\" when the service is not offered (for SRV records)")
+	dnsAddCmd.Flags().Bool("if-absent", false, "Do nothing if a record of this type already exists for the hostname")
+	dnsAddCmd.Flags().Bool("replace-existing", false, "Replace existing records of this type (or a conflicting CNAME) for the hostname")
+	dnsAddCmd.Flags().Bool("append", false, "Add alongside existing records of the same type")
+	dnsAddCmd.Flags().String("comment", "", "Comment on the record")
+	dnsAddCmd.Flags().StringSlice("tag", nil, "Tag for the record (repeatable)")
+	dnsAddCmd.Flags().Bool("check-mx", false, "Warn if an MX target has no address, is a CNAME or refuses SMTP connections")
+	dnsAddCmd.MarkFlagsMutuallyExclusive("if-absent", "replace-existing", "append")
+	addQueueFlags(dnsAddCmd)
 
 	// Flags for dns update
-	dnsUpdateCmd.Flags().IntP("ttl", "", 0, "TTL value (Time To Live)")
+	dnsUpdateCmd.Flags().String("ttl", "", "TTL in seconds or as a duration (e.g. 300, 30m, 1h, 1d); defaults to the provider's default TTL")
 	dnsUpdateCmd.Flags().IntP("mx-pref", "", 0, "MX preference value (for MX records)")
+	dnsUpdateCmd.Flags().String("id", "", "ID of the record to update (see dns list --ids)")
+	dnsUpdateCmd.Flags().String("comment", "", "New comment on the record")
+	dnsUpdateCmd.Flags().StringSlice("tag", nil, "New tags for the record, replacing its tags (repeatable)")
+	dnsUpdateCmd.Flags().Bool("check-mx", false, "Warn if an MX target has no address, is a CNAME or refuses SMTP connections")
+	dnsDeleteCmd.Flags().String("id", "", "ID of the record to delete instead of a hostname and type (see dns list --ids)")
 
 	// Flags for dns clear
 	dnsClearCmd.Flags().BoolP("confirm", "y", false, "Confirm deletion of all records")
 
 	// Flags for dns bulk
 	dnsBulkCmd.Flags().BoolP("confirm", "y", false, "Confirm the bulk operations")
+
+	// Flags for dns import
+	dnsImportCmd.Flags().BoolP("confirm", "y", false, "Apply the imported records")
+	dnsImportCmd.Flags().Bool("merge", false, "Replace existing records with the same hostname and type, keep all others")
+	dnsImportCmd.Flags().Bool("prune", false, "Remove existing records that are not in the zone file")
+	dnsImportCmd.Flags().String("format", string(zoneimport.FormatAuto), "Format of the file: auto, bind, cloudflare, digitalocean or octodns")
+	dnsImportCmd.MarkFlagsMutuallyExclusive("merge", "prune")
+
+	// Flags for dns plan
+	dnsPlanCmd.Flags().Bool("merge", false, "Replace existing records with the same hostname and type, keep all others")
+	dnsPlanCmd.Flags().Bool("prune", false, "Remove existing records that are not in the zone file")
+	dnsPlanCmd.MarkFlagsMutuallyExclusive("merge", "prune")
+	dnsPlanCmd.Flags().String("emit-bulk", "", "Write the changes as a bulk operations file for 'dns bulk'")
+	dnsPlanCmd.Flags().StringP("file", "f", "", "Zone file, or YAML or JSON state file, to plan")
+	dnsPlanCmd.Flags().String("out", "", "Save the plan as a JSON file for 'dns apply'")
+
+	// Flags for dns sync
+	dnsSyncCmd.Flags().BoolP("confirm", "y", false, "Apply the changes")
+
+	// Flags for dns export
+	dnsExportCmd.Flags().String("format", exportBIND, "Export format: bind, json, yaml, csv or terraform")
+	dnsExportCmd.Flags().String("terraform-provider", terraform.ProviderCloudflare, "Terraform provider of --format terraform: cloudflare or route53")
+
+	// Commands that can wait for critical changes to propagate and warm caches
+	for _, c := range []*cobra.Command{dnsAddCmd, dnsUpdateCmd, dnsDeleteCmd, dnsBulkCmd, dnsImportCmd, dnsSyncCmd, dnsApplyCmd} {
+		addPropagationFlags(c)
+	}
+
+	// Flags for dns changelog
+	dnsChangelogCmd.Flags().String("since", "30d", "Only include changes newer than this (e.g. 30d, 2w, 12h; empty for all)")
+
+	// Flags for dns ddns
+	dnsDDNSCmd.Flags().StringP("type", "t", "A", "Single record type to update: A or AAAA")
+	dnsDDNSCmd.Flags().String("ip", "", "Address to publish instead of detecting the public address")
+	dnsDDNSCmd.Flags().StringArray("source", nil, "Address source to try, in order: an https:// URL, iface:<name>, upnp or exec:<command> (repeatable)")
+	dnsDDNSCmd.Flags().Int("consensus", 0, "Number of sources that must report the same address before updating")
+	dnsDDNSCmd.Flags().Bool("ipv4", true, "Update the A record")
+	dnsDDNSCmd.Flags().Bool("ipv6", false, "Update the AAAA record")
+	dnsDDNSCmd.Flags().Bool("prefer-ipv6-privacy", false, "Publish a temporary IPv6 privacy address instead of the stable address")
+	dnsDDNSCmd.MarkFlagsMutuallyExclusive("type", "ipv4")
+	dnsDDNSCmd.MarkFlagsMutuallyExclusive("type", "ipv6")
+
+	// Flags for dns verify
+	dnsVerifyCmd.Flags().StringSlice("against", []string{"provider", "dns"}, "Sources to compare: provider, dns and/or file (a single source is compared with the provider)")
+	dnsVerifyCmd.Flags().String("file", "", "Zone file to compare against")
+	dnsVerifyCmd.Flags().String("resolver", "", "Resolver to query for dns (host or host:port; default: the system's)")
+
+	// Flags for dns stale
+	dnsWatchCmd.Flags().Duration("interval", 5*time.Minute, "How often to poll the records")
+	dnsWatchCmd.Flags().StringSlice("source", []string{"provider", "dns"}, "Sources to watch: provider and/or dns")
+	dnsWatchCmd.Flags().String("resolver", "", "Resolver to query for dns (host or host:port; default: the system's)")
+	dnsWatchCmd.Flags().String("webhook", "", "URL to POST the changes to as JSON")
+	dnsWatchCmd.Flags().String("exec", "", "Shell command to run on changes, with the changes as JSON on stdin")
+
+	dnsStaleCmd.Flags().IntSlice("ports", stale.DefaultPorts, "TCP ports to probe on A and AAAA targets")
+	dnsStaleCmd.Flags().Duration("timeout", stale.DefaultTimeout, "Time to wait for each probe")
+}
+
+// newDNSService creates a DNS service that records applied changes in the local
+// change history, tagged with the account and the command that made them, and
+// checks them against the configured policy.
+func newDNSService(cmd *cobra.Command, args []string, client *client.Client) *dns.Service {
+	dnsService := dns.NewService(client)
+	dnsService.SetQuiet(noWarnings)
+	attachHistory(cmd, args, dnsService)
+	attachPolicy(cmd, dnsService)
+	return dnsService
+}
+
+// resolveDNSService returns the DNS service a dns command operates on. By default
+// that is the current account's, and the account is returned for the production
+// guard. With --provider the named provider is used for this invocation instead,
+// after checking that it hosts the zone, and the returned account is nil.
+func resolveDNSService(cmd *cobra.Command, args []string, domainName string, showTarget bool) (*dns.Service, *config.AccountConfig, error) {
+	if providerName, _ := cmd.Flags().GetString("provider"); providerName != "" {
+		dnsService, err := newProviderDNSService(cmd, args, providerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := verifyZone(dnsService, domainName); err != nil {
+			return nil, nil, err
+		}
+		if showTarget {
+			statusf("Using provider: %s\n", providerName)
+			statusln()
+		}
+		return dnsService, nil, nil
+	}
+
+	// Get current account configuration
+	accountConfig, err := GetCurrentAccount()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account configuration: %w", err)
+	}
+
+	dnsService, _, err := accountDNSService(cmd, args, accountConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if showTarget {
+		cmdutil.DisplayAccountInfo(accountConfig)
+	}
+	warnUnknownDomain(domainName)
+
+	return dnsService, accountConfig, nil
+}
+
+// accountDNSService returns the DNS service of an account: the registered
+// provider or provider instance the account names with provider, or Namecheap
+// through the account's API credentials. The Namecheap client is returned for
+// the registrar API, and is nil for accounts bound to another provider.
+func accountDNSService(cmd *cobra.Command, args []string, accountConfig *config.AccountConfig) (*dns.Service, *client.Client, error) {
+	if !accountConfig.UsesNamecheap() {
+		dnsService, err := newProviderDNSService(cmd, args, accountConfig.GetProvider())
+		if err != nil {
+			return nil, nil, fmt.Errorf("account provider: %w", err)
+		}
+		return dnsService, nil, nil
+	}
+
+	client, err := cmdutil.CreateClient(accountConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newDNSService(cmd, args, client), client, nil
+}
+
+// resolveAllDomains returns the DNS service and the domains a command run with
+// --all-domains operates on: the zones of the provider selected with --provider,
+// or else the current account's domains. Domains of a Namecheap account are
+// those registered in it; for accounts bound to another provider they are the
+// zones it serves.
+func resolveAllDomains(cmd *cobra.Command, args []string, showTarget bool) (*dns.Service, []string, error) {
+	var dnsService *dns.Service
+	var registrar *client.Client
+	if providerName, _ := cmd.Flags().GetString("provider"); providerName != "" {
+		var err error
+		dnsService, err = newProviderDNSService(cmd, args, providerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if showTarget {
+			statusf("Using provider: %s\n", providerName)
+			statusln()
+		}
+	} else {
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account configuration: %w", err)
+		}
+		dnsService, registrar, err = accountDNSService(cmd, args, accountConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		if showTarget {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
+	}
+
+	domains, err := listDomains(dnsService, registrar)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The zones of a provider selected with --provider are not the account's
+	if providerName, _ := cmd.Flags().GetString("provider"); providerName == "" {
+		cacheDomains(domains)
+	}
+	return dnsService, domains, nil
+}
+
+// listDomains returns the domains of a DNS service: those registered in the
+// Namecheap account when registrar is set, the zones the provider serves
+// otherwise
+func listDomains(dnsService *dns.Service, registrar *client.Client) ([]string, error) {
+	if registrar == nil {
+		zones, err := dnsService.ListZones()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list zones: %w", err)
+		}
+		return zones, nil
+	}
+
+	domainList, err := domain.NewService(registrar).ListDomains()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	domains := make([]string, 0, len(domainList))
+	for _, d := range domainList {
+		domains = append(domains, d.Name)
+	}
+	return domains, nil
+}
+
+// verifyZone checks that the service's provider hosts the zone. Update-only
+// providers cannot read zones and are not checked.
+func verifyZone(dnsService *dns.Service, domainName string) error {
+	if dnsService.Capabilities().UpdateOnly {
+		return nil
+	}
+	if _, err := dnsService.GetRecords(domainName); err != nil {
+		return fmt.Errorf("zone %s not found at provider %s: %w", domainName, dnsService.ProviderName(), err)
+	}
+	return nil
+}
+
+// newProviderDNSService creates a DNS service for a registered provider rather
+// than the account, recording changes like newDNSService.
+func newProviderDNSService(cmd *cobra.Command, args []string, providerName string) (*dns.Service, error) {
+	dnsService, err := dns.NewServiceWithProviderName(providerName)
+	if err != nil {
+		return nil, err
+	}
+	dnsService.SetQuiet(noWarnings)
+	attachHistory(cmd, args, dnsService)
+	attachPolicy(cmd, dnsService)
+	return dnsService, nil
+}
+
+// attachHistory records the changes applied by dnsService in the local change
+// history and the audit log and, within an interactive session, on the
+// session's undo stack, and snapshots each zone before it changes for dns undo. It also keeps record comments and tags in local state for providers that cannot
+// store them.
+func attachHistory(cmd *cobra.Command, args []string, dnsService *dns.Service) {
+	command := strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))
+	if sessionUndo != nil {
+		dnsService.SetUndoRecorder(undo.NewRecorder(sessionUndo, dnsService, GetCurrentAccountName(), command))
+	}
+
+	dnsService.SetSnapshotter(changeSnapshots{store: newBackupStore(), account: GetCurrentAccountName(), command: command})
+
+	recorder := history.NewRecorder(nil, GetCurrentAccountName(), command)
+	recorder.Emergency = emergencyChange
+	if logger, err := newAuditLogger(cmd, args); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: changes will not be audited: %v\n", err)
+	} else {
+		recorder.Audit = logger
+	}
+	if store, err := openState(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: changes will not be recorded: %v\n", err)
+	} else {
+		recorder.Store = history.NewStore(store)
+		dnsService.SetNoteStore(dns.NewNoteStore(store))
+	}
+	if recorder.Store != nil || recorder.Audit != nil {
+		dnsService.SetRecorder(recorder)
+	}
+}
+
+// observeZoneVersions records the zone versions seen in an inventory and returns,
+// per domain, a note describing whether the zone changed since it was last seen.
+// Failing to read or write the local version store is not fatal.
+func observeZoneVersions(providerName string, inventory *dns.Inventory) map[string]string {
+	notes := make(map[string]string)
+
+	st, err := openState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return notes
+	}
+	store, err := dns.NewVersionStore(st)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return notes
+	}
+
+	times := timeFormatter()
+	for domainName, version := range inventory.Versions {
+		previous, known, changed := store.Observe(providerName, domainName, version)
+		switch {
+		case !known:
+			notes[domainName] = " (first seen)"
+		case changed:
+			notes[domainName] = fmt.Sprintf(" (changed since last seen %s)", times.Timestamp(previous.SeenAt))
+		default:
+			notes[domainName] = fmt.Sprintf(" (unchanged since %s)", times.Timestamp(previous.ChangedAt))
+		}
+	}
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	return notes
+}
+
+// printApplyResult prints the per-record outcome of applying DNS records.
+// Unchanged records are omitted from the table.
+func printApplyResult(result *provider.ApplyResult) {
+	table := render.NewTable("STATUS", "HOSTNAME", "TYPE", "VALUE", "DETAILS")
+	for _, r := range result.Records {
+		if r.Status == provider.ApplyUnchanged {
+			continue
+		}
+
+		details := ""
+		switch {
+		case r.Status == provider.ApplyFailed:
+			details = fmt.Sprintf("%s failed: %v", r.Action, r.Err)
+		case r.Status == provider.ApplyUpdated && r.Previous != nil:
+			details = "was " + r.Previous.Address
+		}
+
+		table.AddRow(string(r.Status), r.Record.HostName, r.Record.RecordType, r.Record.Address, details)
+	}
+	table.WriteText(os.Stdout)
+
+	statusf("\nResult: %s\n\n", result.Summary())
 }
 
 // formatAsZoneFile converts DNS records to BIND zone file format
@@ -551,8 +2768,21 @@ func formatAsZoneFile(domainName string, records []dnsrecord.Record) string {
 		case dnsrecord.RecordTypeNS:
 			sb.WriteString(fmt.Sprintf("%s%s IN NS %s\n", hostname, ttl, record.Address))
 		case dnsrecord.RecordTypeSRV:
-			// SRV records need special parsing, for now just output as-is
-			sb.WriteString(fmt.Sprintf("%s%s IN SRV %s\n", hostname, ttl, record.Address))
+			srv, err := dnsrecord.ParseSRV(record.Address)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("; %s: %v\n", hostname, err))
+				continue
+			}
+			// The target is a fully qualified name, not one relative to $ORIGIN
+			if !strings.HasSuffix(srv.Target, ".") {
+				srv.Target += "."
+			}
+			sb.WriteString(fmt.Sprintf("%s%s IN SRV %d %d %d %s\n", hostname, ttl, srv.Priority, srv.Weight, srv.Port, srv.Target))
+		case dnsrecord.RecordTypePTR:
+			sb.WriteString(fmt.Sprintf("%s%s IN PTR %s\n", hostname, ttl, record.Address))
+		case dnsrecord.RecordTypeTLSA, dnsrecord.RecordTypeSSHFP, dnsrecord.RecordTypeDS, dnsrecord.RecordTypeNAPTR:
+			// Hex data in lower case and the NAPTR strings quoted
+			sb.WriteString(fmt.Sprintf("%s%s IN %s %s\n", hostname, ttl, record.RecordType, dnsrecord.CanonicalValue(record.RecordType, record.Address)))
 		default:
 			// For unknown types, output as generic record
 			sb.WriteString(fmt.Sprintf("%s%s IN %s %s\n", hostname, ttl, record.RecordType, record.Address))
@@ -563,23 +2793,47 @@ func formatAsZoneFile(domainName string, records []dnsrecord.Record) string {
 }
 
 // parseBulkOperationsFile parses a YAML file containing bulk DNS operations
+// bulkOperationEntry is one operation of a bulk operations file
+type bulkOperationEntry struct {
+	Action   string        `yaml:"action"`
+	Hostname string        `yaml:"hostname"`
+	Type     string        `yaml:"type"`
+	Value    string        `yaml:"value"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty"`
+	MXPref   int           `yaml:"mx_pref,omitempty"`
+}
+
+// writeBulkOperationsFile writes operations in the format parseBulkOperationsFile reads
+func writeBulkOperationsFile(filePath string, operations []dns.BulkOperation) error {
+	entries := make([]bulkOperationEntry, 0, len(operations))
+	for _, op := range operations {
+		entries = append(entries, bulkOperationEntry{
+			Action:   op.Action,
+			Hostname: op.Record.HostName,
+			Type:     op.Record.RecordType,
+			Value:    op.Record.Address,
+			TTL:      dnsrecord.TTL(op.Record.TTL),
+			MXPref:   op.Record.MXPref,
+		})
+	}
+
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode bulk operations: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bulk operations file: %w", err)
+	}
+	return nil
+}
+
 func parseBulkOperationsFile(filePath string) ([]dns.BulkOperation, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read operations file: %w", err)
 	}
 
-	// Define the structure for parsing
-	type OperationInput struct {
-		Action   string `yaml:"action"`
-		Hostname string `yaml:"hostname"`
-		Type     string `yaml:"type"`
-		Value    string `yaml:"value"`
-		TTL      int    `yaml:"ttl,omitempty"`
-		MXPref   int    `yaml:"mx_pref,omitempty"`
-	}
-
-	var inputs []OperationInput
+	var inputs []bulkOperationEntry
 	if err := yaml.Unmarshal(data, &inputs); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
@@ -611,7 +2865,7 @@ func parseBulkOperationsFile(filePath string) ([]dns.BulkOperation, error) {
 			HostName:   input.Hostname,
 			RecordType: input.Type,
 			Address:    input.Value,
-			TTL:        input.TTL,
+			TTL:        int(input.TTL),
 			MXPref:     input.MXPref,
 		}
 
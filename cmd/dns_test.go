@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zonekit/pkg/testutil"
+	"zonekit/pkg/zonefile"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exportConfig is a config with one account on a REST provider served by api
+const exportConfig = `current_account: work
+accounts:
+  work:
+    provider: fake
+providers:
+  fake:
+    type: rest
+    base_url: %s
+    auth: bearer
+    credentials:
+      token: x
+    endpoints:
+      list_zones: /zones
+      get_records: /zones/{domain}/records
+      create_record: /zones/{domain}/records
+      update_record: /zones/{domain}/records/{record_id}
+      delete_record: /zones/{domain}/records/{record_id}
+    mappings:
+      list_path: result
+      request:
+        hostname: name
+        record_type: type
+        address: content
+        ttl: ttl
+        mx_pref: priority
+      response:
+        id: id
+        hostname: name
+        record_type: type
+        address: content
+        ttl: ttl
+        mx_pref: priority
+`
+
+// captureStdout returns what run writes to os.Stdout
+func captureStdout(t *testing.T, run func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	runErr := run()
+	w.Close()
+	return <-output, runErr
+}
+
+func TestDNSExport_StdoutIsOnlyTheZone(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zone := testutil.StandardZone("example.com").TTL(300).WithIDs()
+	api := testutil.NewFakeAPI(t, testutil.CloudflareShape(), zone)
+	configPath := filepath.Join(home, "zonekit.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(exportConfig, api.URL)), 0600))
+
+	rootCmd.SetArgs([]string{"--config", configPath, "dns", "export", "example.com"})
+	defer rootCmd.SetArgs(nil)
+	output, err := captureStdout(t, func() error {
+		return rootCmd.ExecuteContext(context.Background())
+	})
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(output, "$ORIGIN example.com."), "stdout does not start with the zone: %q", output)
+	require.NotContains(t, output, "Using account")
+	require.NotContains(t, output, "Zone file for")
+	records, err := zonefile.Parse(strings.NewReader(output), "example.com")
+	require.NoError(t, err, "stdout is not a zone file: %q", output)
+	require.NotEmpty(t, records)
+}
+
+// writeGuardedConfig writes exportConfig with production_guard set on the
+// account to home and returns its path; the provider is never reached
+func writeGuardedConfig(t *testing.T, home string) string {
+	t.Helper()
+	configPath := filepath.Join(home, "zonekit.yaml")
+	guarded := strings.Replace(fmt.Sprintf(exportConfig, "http://127.0.0.1:1"), "    provider: fake\n", "    provider: fake\n    production_guard: true\n", 1)
+	require.NoError(t, os.WriteFile(configPath, []byte(guarded), 0600))
+	return configPath
+}
+
+func TestServe_GuardedAccountNeedsProduction(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := writeGuardedConfig(t, home)
+
+	rootCmd.SetArgs([]string{"--config", configPath, "serve", "--token", "secret", "--domain", "example.com"})
+	defer rootCmd.SetArgs(nil)
+	err := rootCmd.ExecuteContext(context.Background())
+	require.ErrorContains(t, err, "production account")
+}
+
+func TestDNSAdd_AllDomainsOfGuardedAccountNeedsProduction(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := writeGuardedConfig(t, home)
+
+	rootCmd.SetArgs([]string{"--config", configPath, "dns", "add", "--all-domains", "www", "A", "192.0.2.1"})
+	defer rootCmd.SetArgs(nil)
+	err := rootCmd.ExecuteContext(context.Background())
+	require.ErrorContains(t, err, "production account")
+}
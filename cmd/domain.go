@@ -1,13 +1,22 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 	"zonekit/internal/cmdutil"
+	"zonekit/pkg/config"
+	"zonekit/pkg/diffview"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dnsrecord"
 	"zonekit/pkg/domain"
+	"zonekit/pkg/history"
+	"zonekit/pkg/render"
 )
 
 // domainCmd represents the domain command
@@ -23,6 +32,11 @@ var domainListCmd = &cobra.Command{
 	Short: "List all domains",
 	Long:  `List all domains in your account with their details.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
 		// Get current account configuration
 		accountConfig, err := GetCurrentAccount()
 		if err != nil {
@@ -34,52 +48,73 @@ var domainListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
 
 		domainService := domain.NewService(client)
 		domains, err := domainService.ListDomains()
 		if err != nil {
 			return fmt.Errorf("failed to list domains: %w", err)
 		}
+		names := make([]string, 0, len(domains))
+		for _, d := range domains {
+			names = append(names, d.Name)
+		}
+		cacheDomains(names)
+
+		if output.Structured() {
+			views := make([]domainView, 0, len(domains))
+			for _, d := range domains {
+				views = append(views, newDomainView(d))
+			}
+			return writeOutput(output, newDomainTable(domains), views)
+		}
 
 		if len(domains) == 0 {
-			fmt.Println("No domains found in your account.")
+			statusln("No domains found in your account.")
 			return nil
 		}
 
-		// Create table writer
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "DOMAIN\tCREATED\tEXPIRES\tAUTO-RENEW\tLOCKED\tDNS")
+		return writeOutput(output, newDomainTable(domains), nil)
+	},
+}
 
-		for _, d := range domains {
-			autoRenew := "No"
-			if d.AutoRenew {
-				autoRenew = "Yes"
-			}
-			locked := "No"
-			if d.IsLocked {
-				locked = "Yes"
-			}
-			dns := "External"
-			if d.IsOurDNS {
-				dns = "Provider"
-			}
+// newDomainTable builds the table printed by domain list
+func newDomainTable(domains []domain.Domain) *render.Table {
+	table := render.NewTable("DOMAIN", "CREATED", "EXPIRES", "AUTO-RENEW", "LOCKED", "DNS")
+	dates := timeFormatter()
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-				d.Name, d.Created, d.Expires, autoRenew, locked, dns)
+	for _, d := range domains {
+		autoRenew := "No"
+		if d.AutoRenew {
+			autoRenew = "Yes"
+		}
+		locked := "No"
+		if d.IsLocked {
+			locked = "Yes"
+		}
+		dns := "External"
+		if d.IsOurDNS {
+			dns = "Provider"
 		}
 
-		w.Flush()
-		return nil
-	},
+		table.AddRow(d.Name, dates.DateString(d.Created), dates.DateString(d.Expires), autoRenew, locked, dns)
+	}
+	return table
 }
 
 // domainInfoCmd represents the domain info command
 var domainInfoCmd = &cobra.Command{
 	Use:   "info <domain>",
 	Short: "Get detailed information about a domain",
-	Long:  `Get detailed information about a specific domain.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Get detailed information about a specific domain.
+
+With --raw and -o json or yaml, the registrar's API response is included
+untouched under "raw", for fields zonekit does not show yet.`,
+	Example: `  zonekit domain info example.com
+  zonekit domain info example.com -o json --raw`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
 
@@ -88,6 +123,15 @@ var domainInfoCmd = &cobra.Command{
 			return fmt.Errorf("invalid domain: %w", err)
 		}
 
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		raw, _ := cmd.Flags().GetBool("raw")
+		if raw && !output.Structured() {
+			return fmt.Errorf("--raw requires -o json or -o yaml")
+		}
+
 		// Get current account configuration
 		accountConfig, err := GetCurrentAccount()
 		if err != nil {
@@ -99,7 +143,9 @@ var domainInfoCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
 
 		domainService := domain.NewService(client)
 		domainInfo, err := domainService.GetDomainInfo(domainName)
@@ -107,15 +153,48 @@ var domainInfoCmd = &cobra.Command{
 			return fmt.Errorf("failed to get domain info: %w", err)
 		}
 
+		// URL forwarding is served by the provider's DNS only
+		var forwards []dnsrecord.Record
+		if domainInfo.IsOurDNS {
+			if forwards, err = dns.NewService(client).Forwards(domainName); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to get forwarding: %v\n", err)
+			}
+		}
+
+		if output.Structured() {
+			view := newDomainView(*domainInfo)
+			if len(forwards) > 0 {
+				view.Forwarding = newForwardViews(forwards)
+			}
+			if raw {
+				view.Raw = domainInfo.Raw
+			}
+			return render.Encode(os.Stdout, output, view)
+		}
+
+		dates := timeFormatter()
 		fmt.Printf("Domain: %s\n", domainInfo.Name)
+		if domainInfo.Status != "" {
+			fmt.Printf("Status: %s\n", domainInfo.Status)
+		}
 		fmt.Printf("Owner: %s\n", domainInfo.User)
-		fmt.Printf("Created: %s\n", domainInfo.Created)
-		fmt.Printf("Expires: %s\n", domainInfo.Expires)
+		fmt.Printf("Created: %s\n", dates.DateString(domainInfo.Created))
+		fmt.Printf("Expires: %s\n", dates.DateString(domainInfo.Expires))
+		fmt.Printf("Expired: %t\n", domainInfo.IsExpired)
 		fmt.Printf("Auto-Renew: %t\n", domainInfo.AutoRenew)
 		fmt.Printf("Locked: %t\n", domainInfo.IsLocked)
 		fmt.Printf("WhoisGuard: %s\n", domainInfo.WhoisGuard)
+		if domainInfo.WhoisGuardExpires != "" {
+			fmt.Printf("WhoisGuard Expires: %s\n", dates.DateString(domainInfo.WhoisGuardExpires))
+		}
 		fmt.Printf("Premium: %t\n", domainInfo.IsPremium)
 		fmt.Printf("Using Provider DNS: %t\n", domainInfo.IsOurDNS)
+		if len(domainInfo.Nameservers) > 0 {
+			fmt.Printf("Nameservers: %s\n", strings.Join(domainInfo.Nameservers, ", "))
+		}
+		for _, f := range forwards {
+			fmt.Printf("Forwarding: %s -> %s (%s)\n", forwardHost(f.HostName, domainName), f.Address, forwardMode(f.RecordType))
+		}
 
 		return nil
 	},
@@ -123,16 +202,43 @@ var domainInfoCmd = &cobra.Command{
 
 // domainCheckCmd represents the domain check command
 var domainCheckCmd = &cobra.Command{
-	Use:   "check <domain>",
+	Use:   "check <domain|name>",
 	Short: "Check domain availability",
-	Long:  `Check if a domain is available for registration.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Check if a domain is available for registration.
+
+Pass a full domain to check it alone, or a base name together with --tlds
+(or --popular for a built-in set) to check several TLDs in parallel:
+
+  zonekit domain check example.com
+  zonekit domain check example --tlds com,net,io,dev
+  zonekit domain check example --popular`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		domainName := args[0]
+		name := args[0]
 
-		// Validate domain
-		if err := domain.ValidateDomain(domainName); err != nil {
-			return fmt.Errorf("invalid domain: %w", err)
+		tlds, _ := cmd.Flags().GetStringSlice("tlds")
+		popular, _ := cmd.Flags().GetBool("popular")
+		if popular {
+			tlds = append(tlds, domain.PopularTLDs...)
+		}
+
+		var candidates []string
+		if len(tlds) > 0 {
+			candidates = domain.ExpandCandidates(name, tlds)
+		} else {
+			candidates = []string{name}
+		}
+
+		// Validate domains
+		for _, candidate := range candidates {
+			if err := domain.ValidateDomain(candidate); err != nil {
+				return fmt.Errorf("invalid domain %s: %w", candidate, err)
+			}
+		}
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
 		}
 
 		// Get current account configuration
@@ -146,24 +252,75 @@ var domainCheckCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
 
 		domainService := domain.NewService(client)
-		available, err := domainService.CheckAvailability(domainName)
-		if err != nil {
-			return fmt.Errorf("failed to check domain availability: %w", err)
+		results := domainService.CheckAvailabilityMany(candidates)
+
+		if output.Structured() {
+			views := make([]availabilityView, 0, len(results))
+			for _, r := range results {
+				views = append(views, newAvailabilityView(r))
+			}
+			return writeOutput(output, newAvailabilityTable(results), views)
 		}
 
-		if available {
-			fmt.Printf("Domain '%s' is AVAILABLE for registration.\n", domainName)
-		} else {
-			fmt.Printf("Domain '%s' is NOT AVAILABLE.\n", domainName)
+		if len(results) == 1 {
+			result := results[0]
+			if result.Err != nil {
+				return fmt.Errorf("failed to check domain availability: %w", result.Err)
+			}
+			if result.Available {
+				fmt.Printf("Domain '%s' is AVAILABLE for registration.\n", result.Domain)
+				if result.Price > 0 {
+					fmt.Printf("Price: %s\n", formatPrice(result))
+				}
+			} else {
+				fmt.Printf("Domain '%s' is NOT AVAILABLE.\n", result.Domain)
+			}
+			return nil
 		}
 
-		return nil
+		return writeOutput(output, newAvailabilityTable(results), nil)
 	},
 }
 
+// newAvailabilityTable builds the table printed by domain check
+func newAvailabilityTable(results []domain.Availability) *render.Table {
+	table := render.NewTable("DOMAIN", "AVAILABLE", "PREMIUM", "PRICE")
+
+	for _, r := range results {
+		available := "No"
+		if r.Err != nil {
+			available = "Error: " + r.Err.Error()
+		} else if r.Available {
+			available = "Yes"
+		}
+		premium := "No"
+		if r.IsPremium {
+			premium = "Yes"
+		}
+		price := "-"
+		if r.Available && r.Price > 0 {
+			price = formatPrice(r)
+		}
+
+		table.AddRow(r.Domain, available, premium, price)
+	}
+	return table
+}
+
+// formatPrice renders an availability price with its currency
+func formatPrice(a domain.Availability) string {
+	currency := a.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	return fmt.Sprintf("%.2f %s/year", a.Price, currency)
+}
+
 // domainNameserversCmd represents the domain nameservers command
 var domainNameserversCmd = &cobra.Command{
 	Use:   "nameservers",
@@ -185,6 +342,11 @@ var domainNameserversGetCmd = &cobra.Command{
 			return fmt.Errorf("invalid domain: %w", err)
 		}
 
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
 		// Get current account configuration
 		accountConfig, err := GetCurrentAccount()
 		if err != nil {
@@ -196,7 +358,9 @@ var domainNameserversGetCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
 
 		domainService := domain.NewService(client)
 		nameservers, err := domainService.GetNameservers(domainName)
@@ -204,7 +368,11 @@ var domainNameserversGetCmd = &cobra.Command{
 			return fmt.Errorf("failed to get nameservers: %w", err)
 		}
 
-		fmt.Printf("Nameservers for %s:\n", domainName)
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, nameserversView{Domain: domainName, Nameservers: nameservers})
+		}
+
+		statusf("Nameservers for %s:\n", domainName)
 		for i, ns := range nameservers {
 			fmt.Printf("%d. %s\n", i+1, ns)
 		}
@@ -215,13 +383,31 @@ var domainNameserversGetCmd = &cobra.Command{
 
 // domainNameserversSetCmd represents the domain nameservers set command
 var domainNameserversSetCmd = &cobra.Command{
-	Use:   "set <domain> <ns1> [ns2] [ns3] [ns4]",
+	Use:   "set <domain> <ns1> <ns2> [ns3...]",
 	Short: "Set custom nameservers for a domain",
-	Long:  `Set custom nameservers for a domain. You can specify 2-4 nameservers.`,
-	Args:  cobra.RangeArgs(3, 5), // domain + 2-4 nameservers
+	Long: `Set custom nameservers for a domain. You can specify 2-12 nameservers.
+
+Each nameserver must be a fully qualified hostname that resolves; duplicates are
+removed. Use --skip-resolve to set nameservers that do not resolve yet.`,
+	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
-		nameservers := args[1:]
+		skipResolve, _ := cmd.Flags().GetBool("skip-resolve")
+
+		if err := domain.ValidateDomain(domainName); err != nil {
+			return err
+		}
+
+		nameservers, err := domain.NormalizeNameservers(args[1:])
+		if err != nil {
+			return err
+		}
+
+		if !skipResolve {
+			if err := domain.CheckNameserversResolve(nameservers); err != nil {
+				return fmt.Errorf("%w (use --skip-resolve to set them anyway)", err)
+			}
+		}
 
 		// Get current account configuration
 		accountConfig, err := GetCurrentAccount()
@@ -236,21 +422,185 @@ var domainNameserversSetCmd = &cobra.Command{
 		}
 		cmdutil.DisplayAccountInfo(accountConfig)
 
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "change nameservers"); err != nil {
+			return err
+		}
+
 		domainService := domain.NewService(client)
+		before := currentNameservers(domainService, domainName)
 		err = domainService.SetNameservers(domainName, nameservers)
+		auditRegistrar(cmd, args, domainName, "nameservers", before, strings.Join(nameservers, ", "), err)
 		if err != nil {
 			return fmt.Errorf("failed to set nameservers: %w", err)
 		}
 
-		fmt.Printf("Successfully set nameservers for %s:\n", domainName)
+		statusf("Successfully set nameservers for %s:\n", domainName)
 		for i, ns := range nameservers {
-			fmt.Printf("%d. %s\n", i+1, ns)
+			statusf("%d. %s\n", i+1, ns)
+		}
+
+		return nil
+	},
+}
+
+// domainNameserversSetBatchCmd represents the domain nameservers set-batch command
+var domainNameserversSetBatchCmd = &cobra.Command{
+	Use:   "set-batch <ns1> <ns2> [ns3...]",
+	Short: "Point many domains at the same nameservers",
+	Long: `Set the same custom nameservers for every domain listed in --domains-file,
+e.g. to point dozens of domains at a new DNS host after a provider migration.
+
+The file lists one domain per line; blank lines and lines starting with # are
+skipped, and - reads the list from standard input. Domains are changed
+--concurrency at a time, and domains that already use the nameservers are left
+alone. Each domain's result is printed as it completes; the command exits with
+an error if any domain failed. --dry-run reads the current nameservers and
+shows which domains would change without changing them.
+
+Examples:
+  zonekit domain nameservers set-batch --domains-file domains.txt ns1.example.net ns2.example.net --dry-run
+  zonekit domain nameservers set-batch --domains-file domains.txt ns1.example.net ns2.example.net --concurrency 8
+  grep -v legacy domains.txt | zonekit domain nameservers set-batch --domains-file - ns1.example.net ns2.example.net -o json`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainsFile, _ := cmd.Flags().GetString("domains-file")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		skipResolve, _ := cmd.Flags().GetBool("skip-resolve")
+
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		nameservers, err := domain.NormalizeNameservers(args)
+		if err != nil {
+			return err
+		}
+		domains, err := readDomainsFile(domainsFile)
+		if err != nil {
+			return err
+		}
+		if len(domains) == 0 {
+			return fmt.Errorf("%s lists no domains", domainsFile)
+		}
+
+		if !skipResolve {
+			if err := domain.CheckNameserversResolve(nameservers); err != nil {
+				return fmt.Errorf("%w (use --skip-resolve to set them anyway)", err)
+			}
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+		if accountConfig.IsGuarded() && !productionConfirmed && !dryRun {
+			return fmt.Errorf("this is a production account: re-run with --production to change the nameservers of %d domain(s)", len(domains))
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+			action := "Setting"
+			if dryRun {
+				action = "Checking"
+			}
+			statusf("%s nameservers %s for %d domain(s)\n\n", action, strings.Join(nameservers, ", "), len(domains))
 		}
 
+		domainService := domain.NewService(client)
+		results, err := domainService.SetNameserversBatch(domains, nameservers, concurrency, dryRun, func(r domain.NameserverResult) {
+			if r.Status == domain.NameserversChanged || (r.Status == domain.NameserversFailed && !dryRun) {
+				auditRegistrar(cmd, args, r.Domain, "nameservers", strings.Join(r.Previous, ", "), strings.Join(nameservers, ", "), r.Err)
+			}
+			if !output.Structured() {
+				printNameserverResult(r)
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		counts := make(map[domain.NameserverStatus]int)
+		for _, r := range results {
+			counts[r.Status]++
+		}
+		if output.Structured() {
+			if err := writeOutput(output, newNameserverBatchTable(results), newNameserverBatchViews(results)); err != nil {
+				return err
+			}
+		} else if dryRun {
+			statusf("\n%d domain(s) would change, %d already use the nameservers, %d failed\n",
+				counts[domain.NameserversPending], counts[domain.NameserversUnchanged], counts[domain.NameserversFailed])
+		} else {
+			statusf("\n%d domain(s) changed, %d already used the nameservers, %d failed\n",
+				counts[domain.NameserversChanged], counts[domain.NameserversUnchanged], counts[domain.NameserversFailed])
+		}
+
+		if failed := counts[domain.NameserversFailed]; failed > 0 {
+			// Every result was printed; a usage message would only bury them
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d domain(s) failed", failed)
+		}
 		return nil
 	},
 }
 
+// readDomainsFile reads the domain list of a batch command from path, or from
+// standard input if path is -
+func readDomainsFile(path string) ([]string, error) {
+	if path == "-" {
+		return domain.ReadDomainList(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domains file: %w", err)
+	}
+	defer file.Close()
+	domains, err := domain.ReadDomainList(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return domains, nil
+}
+
+// printNameserverResult prints the outcome of one domain of a nameserver batch
+// as it happens
+func printNameserverResult(r domain.NameserverResult) {
+	switch r.Status {
+	case domain.NameserversChanged:
+		fmt.Printf("✅ %s changed (was %s)\n", r.Domain, strings.Join(r.Previous, ", "))
+	case domain.NameserversPending:
+		fmt.Printf("📝 %s would change (now %s)\n", r.Domain, strings.Join(r.Previous, ", "))
+	case domain.NameserversUnchanged:
+		fmt.Printf("⏭️  %s already uses the nameservers\n", r.Domain)
+	default:
+		fmt.Printf("❌ %s failed: %v\n", r.Domain, r.Err)
+	}
+}
+
+// newNameserverBatchTable builds the table of a nameserver batch
+func newNameserverBatchTable(results []domain.NameserverResult) *render.Table {
+	table := render.NewTable("DOMAIN", "STATUS", "PREVIOUS", "ERROR")
+	for _, r := range results {
+		errText := ""
+		if r.Err != nil {
+			errText = r.Err.Error()
+		}
+		table.AddRow(r.Domain, string(r.Status), strings.Join(r.Previous, ", "), errText)
+	}
+	return table
+}
+
 // domainNameserversDefaultCmd represents the domain nameservers default command
 var domainNameserversDefaultCmd = &cobra.Command{
 	Use:   "default <domain>",
@@ -278,79 +628,1153 @@ var domainNameserversDefaultCmd = &cobra.Command{
 		}
 		cmdutil.DisplayAccountInfo(accountConfig)
 
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "reset nameservers to Namecheap defaults"); err != nil {
+			return err
+		}
+
 		domainService := domain.NewService(client)
+		before := currentNameservers(domainService, domainName)
 		err = domainService.SetToNamecheapDNS(domainName)
+		auditRegistrar(cmd, args, domainName, "nameservers", before, "Namecheap default DNS", err)
 		if err != nil {
 			return fmt.Errorf("failed to set to provider DNS: %w", err)
 		}
 
-		fmt.Printf("Successfully set %s to use provider DNS servers.\n", domainName)
+		statusf("Successfully set %s to use provider DNS servers.\n", domainName)
 		return nil
 	},
 }
 
-// domainRenewCmd represents the domain renew command
-var domainRenewCmd = &cobra.Command{
-	Use:   "renew <domain> [years]",
-	Short: "Renew a domain",
-	Long:  `Renew a domain for the specified number of years (default: 1 year).`,
-	Args:  cobra.RangeArgs(1, 2),
+// domainForwardCmd represents the domain forward command
+var domainForwardCmd = &cobra.Command{
+	Use:   "forward <domain>",
+	Short: "Forward a domain to a URL",
+	Long: `Redirect visitors of a domain (or of one of its hosts with --host) to a URL
+with the provider's URL forwarding. Without --to or --remove, the current
+forwarding of the domain is shown.
+
+The redirect is temporary (302) by default; --permanent makes it a 301 and
+--mask keeps the domain in the address bar, showing the target in a frame.
+The host's A, AAAA and CNAME records are replaced, as they would answer
+instead of the redirect. Only Namecheap DNS serves URL forwarding, so the
+domain must use the provider's nameservers.
+
+Examples:
+  zonekit domain forward example.com
+  zonekit domain forward example.com --to https://example.org
+  zonekit domain forward example.com --to https://example.org --mask
+  zonekit domain forward example.com --host www --to https://example.org --permanent
+  zonekit domain forward example.com --remove`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domainName := args[0]
-		years := 1
+		target, _ := cmd.Flags().GetString("to")
+		hostname, _ := cmd.Flags().GetString("host")
+		mask, _ := cmd.Flags().GetBool("mask")
+		permanent, _ := cmd.Flags().GetBool("permanent")
+		remove, _ := cmd.Flags().GetBool("remove")
 
-		if len(args) > 1 {
-			var err error
-			if years, err = parseYears(args[1]); err != nil {
-				return fmt.Errorf("invalid years value: %w", err)
+		// Validate domain
+		if err := domain.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+		if (mask || permanent) && target == "" {
+			return fmt.Errorf("--mask and --permanent need --to")
+		}
+		if target != "" {
+			if err := dns.ValidateForwardTarget(target); err != nil {
+				return err
 			}
 		}
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		output, err := tableOutputFormat()
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
 		}
+		showing := target == "" && !remove
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, !showing || !output.Structured())
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
 
-		domainService := domain.NewService(client)
-		err = domainService.RenewDomain(domainName, years)
+		if showing {
+			forwards, err := dnsService.Forwards(domainName)
+			if err != nil {
+				return fmt.Errorf("failed to get forwarding: %w", err)
+			}
+			if len(forwards) == 0 && !output.Structured() {
+				statusf("%s is not forwarded.\n", domainName)
+				return nil
+			}
+			table := render.NewTable("HOSTNAME", "MODE", "TARGET")
+			for _, f := range forwards {
+				table.AddRow(f.HostName, forwardMode(f.RecordType), f.Address)
+			}
+			return writeOutput(output, table, newForwardViews(forwards))
+		}
+
+		current, desired, err := dnsService.PlanForward(domainName, hostname, target, dns.ForwardType(mask, permanent))
 		if err != nil {
-			return fmt.Errorf("failed to renew domain: %w", err)
+			return err
+		}
+		if !diffview.Compute(domainName, current, desired).HasChanges() {
+			statusf("%s is already forwarded to %s, nothing changed\n", forwardHost(hostname, domainName), target)
+			return nil
+		}
+
+		action := "forward the domain to " + target
+		if remove {
+			action = "remove forwarding"
+		}
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, action); err != nil {
+			return err
+		}
+
+		result, err := dnsService.ApplyRecords(domainName, desired)
+		if result != nil {
+			printApplyResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update forwarding: %w", err)
 		}
 
-		fmt.Printf("Successfully renewed %s for %d year(s).\n", domainName, years)
+		if remove {
+			statusf("Successfully removed the forwarding of %s.\n", forwardHost(hostname, domainName))
+		} else {
+			statusf("Successfully forwarded %s to %s (%s).\n", forwardHost(hostname, domainName), target, forwardMode(dns.ForwardType(mask, permanent)))
+		}
 		return nil
 	},
 }
 
-func parseYears(yearsStr string) (int, error) {
-	var years int
-	_, err := fmt.Sscanf(yearsStr, "%d", &years)
-	if err != nil {
-		return 0, err
+// forwardHost returns the name a forwarding record of domainName redirects
+func forwardHost(hostname, domainName string) string {
+	if hostname == "@" || hostname == "" {
+		return domainName
 	}
-	if years < 1 || years > 10 {
-		return 0, fmt.Errorf("years must be between 1 and 10")
+	return hostname + "." + domainName
+}
+
+// forwardMode describes the redirect of a forwarding record type
+func forwardMode(recordType string) string {
+	switch strings.ToUpper(recordType) {
+	case dnsrecord.RecordTypeFRAME:
+		return "masked"
+	case dnsrecord.RecordTypeURL301:
+		return "permanent"
+	default:
+		return "temporary"
 	}
-	return years, nil
 }
 
-func init() {
-	rootCmd.AddCommand(domainCmd)
-	domainCmd.AddCommand(domainListCmd)
-	domainCmd.AddCommand(domainInfoCmd)
-	domainCmd.AddCommand(domainCheckCmd)
-	domainCmd.AddCommand(domainNameserversCmd)
-	domainCmd.AddCommand(domainRenewCmd)
+// domainRegisterCmd represents the domain register command
+var domainRegisterCmd = &cobra.Command{
+	Use:   "register <domain>",
+	Short: "Register a domain",
+	Long: `Register a domain with the contacts of a contact profile from the configuration.
 
-	domainNameserversCmd.AddCommand(domainNameserversGetCmd)
-	domainNameserversCmd.AddCommand(domainNameserversSetCmd)
-	domainNameserversCmd.AddCommand(domainNameserversDefaultCmd)
+The domain's availability and price are shown first; the domain is only
+registered, and charged to the account's balance, with --confirm.
+
+Examples:
+  zonekit domain register example.com --contact-profile personal
+  zonekit domain register example.com --years 2 --contact-profile personal --confirm`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+
+		// Validate domain
+		if err := domain.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		years, _ := cmd.Flags().GetInt("years")
+		if years < 1 || years > 10 {
+			return fmt.Errorf("invalid years value: years must be between 1 and 10")
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		configManager, err := GetConfigManager()
+		if err != nil {
+			return err
+		}
+		profileName, _ := cmd.Flags().GetString("contact-profile")
+		contacts, err := configManager.GetContactProfile(profileName)
+		if err != nil {
+			return err
+		}
+		if err := contacts.Validate(); err != nil {
+			return fmt.Errorf("invalid contact profile '%s': %w", profileName, err)
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
+
+		domainService := domain.NewService(client)
+		availability := domainService.CheckAvailabilityMany([]string{domainName})[0]
+		if availability.Err != nil {
+			return fmt.Errorf("failed to check availability of %s: %w", domainName, availability.Err)
+		}
+		if !availability.Available {
+			return fmt.Errorf("%s is not available for registration", domainName)
+		}
+		if availability.IsPremium {
+			return fmt.Errorf("%s is a premium domain; register it through the Namecheap website", domainName)
+		}
+
+		confirm, _ := cmd.Flags().GetBool("confirm")
+		if !confirm {
+			if output.Structured() {
+				fmt.Fprintln(os.Stderr, "Use --confirm to register the domain.")
+				return render.Encode(os.Stdout, output, newAvailabilityView(availability))
+			}
+
+			registrant := contacts.Registrant
+			fmt.Printf("Domain:      %s\n", domainName)
+			fmt.Printf("Period:      %d year(s)\n", years)
+			if availability.Price > 0 {
+				fmt.Printf("Price:       %s\n", formatPrice(availability))
+			}
+			fmt.Printf("Registrant:  %s %s <%s>\n", registrant.FirstName, registrant.LastName, registrant.Email)
+			statusln()
+			statusln("Use --confirm to register the domain. The registration is charged to the account's balance.")
+			return nil
+		}
+
+		registration, err := domainService.RegisterDomain(domainName, years, contacts)
+		if err != nil {
+			auditRegistrar(cmd, args, domainName, "register", "", fmt.Sprintf("%d year(s)", years), err)
+			return err
+		}
+		var incomplete error
+		if !registration.Registered {
+			incomplete = fmt.Errorf("registration of %s was not completed (order %s)", domainName, registration.OrderID)
+		}
+		auditRegistrar(cmd, args, domainName, "register", "",
+			fmt.Sprintf("registered for %d year(s), charged %.2f, order %s", years, registration.ChargedAmount, registration.OrderID), incomplete)
+
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, newRegistrationView(registration, years))
+		}
+
+		if !registration.Registered {
+			return fmt.Errorf("registration of %s was not completed (order %s)", domainName, registration.OrderID)
+		}
+		statusf("Successfully registered %s for %d year(s).\n", registration.Domain, years)
+		fmt.Printf("Charged: %.2f, order %s, transaction %s\n", registration.ChargedAmount, registration.OrderID, registration.TransactionID)
+		return nil
+	},
+}
+
+// domainRenewCmd represents the domain renew command
+var domainRenewCmd = &cobra.Command{
+	Use:   "renew <domain> [years]",
+	Short: "Renew a domain",
+	Long: `Renew a domain for the specified number of years (default: 1 year).
+
+The renewal is charged to the account's balance; the charged amount and the new
+expiry date are shown. Apply a promotion code with --promo-code.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		years := 1
+
+		if len(args) > 1 {
+			var err error
+			if years, err = parseYears(args[1]); err != nil {
+				return fmt.Errorf("invalid years value: %w", err)
+			}
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		promoCode, _ := cmd.Flags().GetString("promo-code")
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
+
+		domainService := domain.NewService(client)
+		renewal, err := domainService.RenewDomain(domainName, years, promoCode)
+		if err != nil {
+			auditRegistrar(cmd, args, domainName, "renew", "", renewalAuditText(nil, years), err)
+			return fmt.Errorf("failed to renew domain: %w", err)
+		}
+		var incomplete error
+		if !renewal.Renewed {
+			incomplete = fmt.Errorf("renewal of %s was not completed (order %s)", domainName, renewal.OrderID)
+		}
+		auditRegistrar(cmd, args, domainName, "renew", "", renewalAuditText(renewal, years), incomplete)
+
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, newRenewalView(renewal, years))
+		}
+
+		if !renewal.Renewed {
+			return fmt.Errorf("renewal of %s was not completed (order %s)", domainName, renewal.OrderID)
+		}
+		statusf("Successfully renewed %s for %d year(s).\n", renewal.Domain, years)
+		fmt.Printf("Charged: %.2f, order %s, transaction %s\n", renewal.ChargedAmount, renewal.OrderID, renewal.TransactionID)
+		if renewal.Expires != "" {
+			fmt.Printf("New expiry date: %s\n", timeFormatter().DateString(renewal.Expires))
+		}
+		return nil
+	},
+}
+
+// domainRenewBatchCmd represents the domain renew-batch command
+var domainRenewBatchCmd = &cobra.Command{
+	Use:   "renew-batch",
+	Short: "Renew all domains that expire soon",
+	Long: `Renew every domain of the account that expires within --expiring-within.
+
+The candidates are listed soonest first with their renewal prices and the
+account balance, and renewed one at a time after a single confirmation (or
+with --confirm). --max-cost caps the total: domains that would exceed it are
+left out, soonest-expiring first. The balance is checked before each renewal;
+once it no longer covers the next domain, that domain and all later ones are
+skipped instead of attempted. The command exits with an error if any domain
+was not renewed.
+
+Examples:
+  zonekit domain renew-batch --expiring-within 30d
+  zonekit domain renew-batch --expiring-within 2w --max-cost 200 --confirm
+  zonekit domain renew-batch --expiring-within 60d --years 2 -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		withinValue, _ := cmd.Flags().GetString("expiring-within")
+		years, _ := cmd.Flags().GetInt("years")
+		maxCost, _ := cmd.Flags().GetFloat64("max-cost")
+		confirm, _ := cmd.Flags().GetBool("confirm")
+
+		within, err := history.ParseSince(withinValue)
+		if err != nil {
+			return fmt.Errorf("invalid --expiring-within value: %w", err)
+		}
+		if years < 1 || years > 10 {
+			return fmt.Errorf("invalid years value: years must be between 1 and 10")
+		}
+		if maxCost < 0 {
+			return fmt.Errorf("--max-cost must not be negative")
+		}
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
+
+		domainService := domain.NewService(client)
+		candidates, err := domainService.RenewalCandidates(within, years, time.Now())
+		if err != nil {
+			return err
+		}
+		candidates, overBudget := withinBudget(candidates, maxCost)
+		if len(candidates) == 0 {
+			if output.Structured() {
+				return writeOutput(output, newRenewBatchTable(nil), newRenewBatchView(nil, nil))
+			}
+			if len(overBudget) > 0 {
+				return fmt.Errorf("renewing %s alone exceeds --max-cost %.2f", overBudget[0].Domain.Name, maxCost)
+			}
+			statusf("No domains expire within %s.\n", withinValue)
+			return nil
+		}
+
+		balance, err := domainService.GetBalance()
+		if err != nil {
+			return err
+		}
+
+		if !confirm && output.Structured() {
+			fmt.Fprintln(os.Stderr, "Use --confirm to renew the domains.")
+			preview := make([]domain.BatchResult, 0, len(candidates))
+			for _, c := range candidates {
+				preview = append(preview, domain.BatchResult{Candidate: c})
+			}
+			return writeOutput(output, newRenewBatchTable(preview), newRenewBatchView(preview, balance))
+		}
+
+		if !output.Structured() {
+			printRenewBatchPlan(candidates, overBudget, balance, years)
+			if !confirm {
+				statusf("Renew %d domain(s)? (y/N): ", len(candidates))
+				var answer string
+				fmt.Scanln(&answer)
+				if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+					statusln("Aborted.")
+					return nil
+				}
+				statusln()
+			}
+		}
+
+		results := domainService.RenewBatch(candidates, years, balance.Available, func(r domain.BatchResult) {
+			// Skipped domains were not attempted
+			if r.Status != domain.BatchSkipped {
+				auditRegistrar(cmd, args, r.Candidate.Domain.Name, "renew", "", renewalAuditText(r.Renewal, years), r.Err)
+			}
+			if !output.Structured() {
+				printBatchResult(r)
+			}
+		})
+
+		failed := 0
+		for _, r := range results {
+			if r.Status != domain.BatchRenewed {
+				failed++
+			}
+		}
+
+		if output.Structured() {
+			if err := writeOutput(output, newRenewBatchTable(results), newRenewBatchView(results, balance)); err != nil {
+				return err
+			}
+		} else {
+			statusf("\n%d of %d domain(s) renewed\n", len(results)-failed, len(results))
+		}
+
+		if failed > 0 {
+			// Every result was printed; a usage message would only bury them
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d domain(s) were not renewed", failed)
+		}
+		return nil
+	},
+}
+
+// withinBudget keeps candidates, soonest first, while their total price stays
+// within maxCost and returns the rest separately. A maxCost of 0 keeps all.
+func withinBudget(candidates []domain.RenewalCandidate, maxCost float64) (kept, over []domain.RenewalCandidate) {
+	if maxCost == 0 {
+		return candidates, nil
+	}
+	total := 0.0
+	for _, c := range candidates {
+		if total+c.Price > maxCost {
+			over = append(over, c)
+			continue
+		}
+		total += c.Price
+		kept = append(kept, c)
+	}
+	return kept, over
+}
+
+// printRenewBatchPlan prints the domains a batch will renew and what it costs
+func printRenewBatchPlan(candidates, overBudget []domain.RenewalCandidate, balance *domain.Balance, years int) {
+	table := render.NewTable("DOMAIN", "EXPIRES", "PRICE")
+	total := 0.0
+	for _, c := range candidates {
+		table.AddRow(c.Domain.Name, timeFormatter().Date(c.Expires), formatRenewalPrice(c))
+		total += c.Price
+	}
+	_ = table.WriteText(os.Stdout)
+	fmt.Println()
+
+	fmt.Printf("Period:   %d year(s)\n", years)
+	fmt.Printf("Total:    %.2f %s\n", total, balance.Currency)
+	fmt.Printf("Balance:  %.2f %s\n", balance.Available, balance.Currency)
+	for _, c := range overBudget {
+		fmt.Printf("Left out: %s (%s) would exceed --max-cost\n", c.Domain.Name, formatRenewalPrice(c))
+	}
+	if total > balance.Available {
+		fmt.Fprintln(os.Stderr, "Warning: the balance does not cover every renewal; the batch stops at the first domain it cannot pay for")
+	}
+	fmt.Println()
+}
+
+// printBatchResult prints the outcome of one renewal as it happens
+func printBatchResult(r domain.BatchResult) {
+	name := r.Candidate.Domain.Name
+	switch r.Status {
+	case domain.BatchRenewed:
+		line := fmt.Sprintf("✅ %s renewed, charged %.2f", name, r.Renewal.ChargedAmount)
+		if r.Renewal.Expires != "" {
+			line += ", expires " + timeFormatter().DateString(r.Renewal.Expires)
+		}
+		fmt.Println(line)
+	case domain.BatchFailed:
+		fmt.Printf("❌ %s failed: %v\n", name, r.Err)
+	default:
+		fmt.Printf("⏭️  %s skipped: %v\n", name, r.Err)
+	}
+}
+
+// formatRenewalPrice formats the price of a candidate, which may be unknown
+func formatRenewalPrice(c domain.RenewalCandidate) string {
+	if c.Price == 0 {
+		return "unknown"
+	}
+	currency := c.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	return fmt.Sprintf("%.2f %s", c.Price, currency)
+}
+
+// newRenewBatchTable builds the table of a batch renewal or its preview
+func newRenewBatchTable(results []domain.BatchResult) *render.Table {
+	table := render.NewTable("DOMAIN", "EXPIRES", "PRICE", "STATUS", "DETAIL")
+	for _, r := range results {
+		status, detail := "pending", ""
+		if r.Status != "" {
+			status = string(r.Status)
+		}
+		switch {
+		case r.Err != nil:
+			detail = r.Err.Error()
+		case r.Renewal != nil:
+			detail = fmt.Sprintf("charged %.2f, order %s", r.Renewal.ChargedAmount, r.Renewal.OrderID)
+		}
+		table.AddRow(r.Candidate.Domain.Name, timeFormatter().Date(r.Candidate.Expires), formatRenewalPrice(r.Candidate), status, detail)
+	}
+	return table
+}
+
+// domainContactsCmd represents the domain contacts command
+var domainContactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Manage domain contacts",
+	Long:  `Commands for reading and updating the registrant, admin, tech and billing contacts of a domain.`,
+}
+
+// domainContactsGetCmd represents the domain contacts get command
+var domainContactsGetCmd = &cobra.Command{
+	Use:   "get <domain>",
+	Short: "Get domain contacts",
+	Long: `Get the registrant, admin, tech and billing contacts of a domain.
+
+The YAML output can be edited and passed to 'domain contacts set --file'.
+
+Examples:
+  zonekit domain contacts get example.com
+  zonekit domain contacts get example.com -o yaml > contacts.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+
+		// Validate domain
+		if err := domain.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			cmdutil.DisplayAccountInfo(accountConfig)
+		}
+
+		contacts, err := domain.NewService(client).GetContacts(domainName)
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			return render.Encode(os.Stdout, output, newContactsView(domainName, contacts))
+		}
+
+		statusf("Contacts for %s:\n", domainName)
+		for _, c := range []struct {
+			role    string
+			contact *config.Contact
+		}{
+			{"Registrant", contacts.Registrant},
+			{"Admin", contacts.Admin},
+			{"Tech", contacts.Tech},
+			{"Billing", contacts.Billing},
+		} {
+			fmt.Println()
+			printContact(c.role, c.contact)
+		}
+		return nil
+	},
+}
+
+// domainContactsSetCmd represents the domain contacts set command
+var domainContactsSetCmd = &cobra.Command{
+	Use:   "set <domain>",
+	Short: "Update domain contacts",
+	Long: `Update the registrant, admin, tech and billing contacts of a domain.
+
+The new contacts are read from a YAML file with --file (in the format of
+'domain contacts get -o yaml'), taken from a contact profile of the
+configuration with --contact-profile, or entered interactively starting from
+the current contacts. Contacts left out of a file or profile default to the
+registrant.
+
+Changing the registrant of a gTLD domain may lock it against transfers for 60
+days under ICANN's change of registrant policy.
+
+Examples:
+  zonekit domain contacts set example.com
+  zonekit domain contacts set example.com --file contacts.yaml
+  zonekit domain contacts set example.com --contact-profile company`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+
+		// Validate domain
+		if err := domain.ValidateDomain(domainName); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+
+		file, _ := cmd.Flags().GetString("file")
+		profileName, _ := cmd.Flags().GetString("contact-profile")
+
+		var contacts *config.ContactProfile
+		switch {
+		case file != "":
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read contacts file: %w", err)
+			}
+			contacts = &config.ContactProfile{}
+			if err := yaml.Unmarshal(data, contacts); err != nil {
+				return fmt.Errorf("failed to parse contacts file: %w", err)
+			}
+		case profileName != "":
+			configManager, err := GetConfigManager()
+			if err != nil {
+				return err
+			}
+			if contacts, err = configManager.GetContactProfile(profileName); err != nil {
+				return err
+			}
+		}
+		if contacts != nil {
+			if err := contacts.Validate(); err != nil {
+				return fmt.Errorf("invalid contacts: %w", err)
+			}
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create client and display account info
+		client, err := cmdutil.CreateClient(accountConfig)
+		if err != nil {
+			return err
+		}
+		cmdutil.DisplayAccountInfo(accountConfig)
+
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "change domain contacts"); err != nil {
+			return err
+		}
+
+		domainService := domain.NewService(client)
+		current, err := domainService.GetContacts(domainName)
+		if err != nil {
+			return err
+		}
+
+		if contacts == nil {
+			if contacts, err = promptContacts(bufio.NewReader(os.Stdin), current); err != nil {
+				return err
+			}
+			if err := contacts.Validate(); err != nil {
+				return fmt.Errorf("invalid contacts: %w", err)
+			}
+		}
+
+		if current.Registrant != nil && *current.Registrant != *contacts.Registrant {
+			statusln("Note: the registrant changes; gTLD domains may be locked against transfers for 60 days.")
+		}
+
+		err = domainService.SetContacts(domainName, contacts)
+		auditRegistrar(cmd, args, domainName, "contacts", contactsAuditText(current), contactsAuditText(contacts), err)
+		if err != nil {
+			return err
+		}
+
+		statusf("Successfully updated the contacts of %s.\n", domainName)
+		return nil
+	},
+}
+
+// domainGlueCmd represents the domain glue command
+var domainGlueCmd = &cobra.Command{
+	Use:   "glue",
+	Short: "Manage nameservers registered under a domain",
+	Long: `Commands for managing the nameservers registered under a domain (glue records).
+
+Domains that delegate to nameservers under themselves, such as example.com
+using ns1.example.com, need the registry to publish the nameservers' addresses.
+Nameservers may be given as full names or as labels ("ns1") under the domain.`,
+}
+
+// domainGlueListCmd represents the domain glue list command
+var domainGlueListCmd = &cobra.Command{
+	Use:   "list <domain> [ns...]",
+	Short: "List nameservers registered under a domain",
+	Long: `List the nameservers registered under a domain with their IP addresses.
+
+The API cannot enumerate them, so without nameserver arguments the
+nameservers the domain delegates to that are under the domain are shown.
+
+Examples:
+  zonekit domain glue list example.com
+  zonekit domain glue list example.com ns1 ns2`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		domainService, _, err := glueDomainService(domainName, !output.Structured())
+		if err != nil {
+			return err
+		}
+
+		records, err := domainService.ListGlue(domainName, args[1:])
+		if err != nil {
+			return err
+		}
+
+		if !output.Structured() && len(records) == 0 {
+			statusf("No nameservers under %s found; name them to look them up.\n", domainName)
+			return nil
+		}
+
+		views := make([]glueView, 0, len(records))
+		table := render.NewTable("NAMESERVER", "IP", "STATUS")
+		for _, r := range records {
+			views = append(views, glueView{Nameserver: r.Nameserver, IP: r.IP, Statuses: r.Statuses})
+			table.AddRow(r.Nameserver, r.IP, strings.Join(r.Statuses, ", "))
+		}
+		return writeOutput(output, table, views)
+	},
+}
+
+// domainGlueAddCmd represents the domain glue add command
+var domainGlueAddCmd = &cobra.Command{
+	Use:   "add <domain> <ns> <ip>",
+	Short: "Register a nameserver under a domain",
+	Long: `Register a nameserver under a domain with its IP address.
+
+Examples:
+  zonekit domain glue add example.com ns1 192.0.2.53`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName, ip := args[0], args[2]
+		nameserver, err := domain.GlueNameserver(domainName, args[1])
+		if err != nil {
+			return err
+		}
+
+		domainService, accountConfig, err := glueDomainService(domainName, true)
+		if err != nil {
+			return err
+		}
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "register nameserver "+nameserver); err != nil {
+			return err
+		}
+
+		err = domainService.CreateGlue(domainName, nameserver, ip)
+		auditRegistrar(cmd, args, domainName, "glue", "", nameserver+" "+ip, err)
+		if err != nil {
+			return err
+		}
+
+		statusf("Successfully registered %s at %s.\n", nameserver, ip)
+		return nil
+	},
+}
+
+// domainGlueUpdateCmd represents the domain glue update command
+var domainGlueUpdateCmd = &cobra.Command{
+	Use:   "update <domain> <ns> <ip>",
+	Short: "Change the IP address of a nameserver under a domain",
+	Long: `Change the IP address of a nameserver registered under a domain.
+
+Examples:
+  zonekit domain glue update example.com ns1 192.0.2.54`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName, ip := args[0], args[2]
+		nameserver, err := domain.GlueNameserver(domainName, args[1])
+		if err != nil {
+			return err
+		}
+
+		domainService, accountConfig, err := glueDomainService(domainName, true)
+		if err != nil {
+			return err
+		}
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "change the address of nameserver "+nameserver); err != nil {
+			return err
+		}
+
+		before := currentGlue(domainService, domainName, nameserver)
+		err = domainService.UpdateGlue(domainName, nameserver, ip)
+		auditRegistrar(cmd, args, domainName, "glue", before, nameserver+" "+ip, err)
+		if err != nil {
+			return err
+		}
+
+		statusf("Successfully changed the address of %s to %s.\n", nameserver, ip)
+		return nil
+	},
+}
+
+// domainGlueDeleteCmd represents the domain glue delete command
+var domainGlueDeleteCmd = &cobra.Command{
+	Use:   "delete <domain> <ns>",
+	Short: "Remove a nameserver registered under a domain",
+	Long: `Remove a nameserver registered under a domain. The registry refuses while
+domains still delegate to it, so change their nameservers first.
+
+Examples:
+  zonekit domain glue delete example.com ns1`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domainName := args[0]
+		nameserver, err := domain.GlueNameserver(domainName, args[1])
+		if err != nil {
+			return err
+		}
+
+		domainService, accountConfig, err := glueDomainService(domainName, true)
+		if err != nil {
+			return err
+		}
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "remove nameserver "+nameserver); err != nil {
+			return err
+		}
+
+		before := currentGlue(domainService, domainName, nameserver)
+		err = domainService.DeleteGlue(domainName, nameserver)
+		auditRegistrar(cmd, args, domainName, "glue", before, "", err)
+		if err != nil {
+			return err
+		}
+
+		statusf("Successfully removed %s.\n", nameserver)
+		return nil
+	},
+}
+
+// currentNameservers returns the nameservers of a domain for the audit log, or
+// "" if they cannot be read
+func currentNameservers(domainService *domain.Service, domainName string) string {
+	nameservers, err := domainService.GetNameservers(domainName)
+	if err != nil {
+		return ""
+	}
+	return strings.Join(nameservers, ", ")
+}
+
+// currentGlue returns a nameserver registered under a domain with its address
+// for the audit log, or "" if it cannot be read
+func currentGlue(domainService *domain.Service, domainName, nameserver string) string {
+	record, err := domainService.GetGlue(domainName, nameserver)
+	if err != nil {
+		return ""
+	}
+	return record.Nameserver + " " + record.IP
+}
+
+// renewalAuditText describes a renewal for the audit log
+func renewalAuditText(renewal *domain.Renewal, years int) string {
+	if renewal == nil {
+		return fmt.Sprintf("%d year(s)", years)
+	}
+	text := fmt.Sprintf("renewed for %d year(s), charged %.2f, order %s", years, renewal.ChargedAmount, renewal.OrderID)
+	if renewal.Expires != "" {
+		text += ", expires " + renewal.Expires
+	}
+	return text
+}
+
+// contactsAuditText describes the contacts of a domain for the audit log by
+// each contact's name and email address
+func contactsAuditText(contacts *config.ContactProfile) string {
+	if contacts == nil {
+		return ""
+	}
+	var parts []string
+	for _, role := range []struct {
+		name    string
+		contact *config.Contact
+	}{
+		{"registrant", contacts.Registrant},
+		{"admin", contacts.Admin},
+		{"tech", contacts.Tech},
+		{"billing", contacts.Billing},
+	} {
+		if role.contact != nil {
+			parts = append(parts, fmt.Sprintf("%s %s %s <%s>", role.name, role.contact.FirstName, role.contact.LastName, role.contact.Email))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// glueDomainService validates domainName and returns the domain service of
+// the current account, optionally displaying the account
+func glueDomainService(domainName string, showAccount bool) (*domain.Service, *config.AccountConfig, error) {
+	if err := domain.ValidateDomain(domainName); err != nil {
+		return nil, nil, fmt.Errorf("invalid domain: %w", err)
+	}
+
+	// Get current account configuration
+	accountConfig, err := GetCurrentAccount()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account configuration: %w", err)
+	}
+
+	// Create client and display account info
+	client, err := cmdutil.CreateClient(accountConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if showAccount {
+		cmdutil.DisplayAccountInfo(accountConfig)
+	}
+
+	return domain.NewService(client), accountConfig, nil
+}
+
+// printContact prints a contact with its role as heading
+func printContact(role string, c *config.Contact) {
+	fmt.Printf("%s:\n", role)
+	if c == nil {
+		fmt.Println("  (not set)")
+		return
+	}
+	fmt.Printf("  Name:         %s %s\n", c.FirstName, c.LastName)
+	if c.Organization != "" {
+		fmt.Printf("  Organization: %s\n", c.Organization)
+	}
+	if c.JobTitle != "" {
+		fmt.Printf("  Job title:    %s\n", c.JobTitle)
+	}
+	fmt.Printf("  Address:      %s\n", c.Address1)
+	if c.Address2 != "" {
+		fmt.Printf("                %s\n", c.Address2)
+	}
+	fmt.Printf("                %s, %s %s, %s\n", c.City, c.StateProvince, c.PostalCode, c.Country)
+	fmt.Printf("  Phone:        %s\n", c.Phone)
+	fmt.Printf("  Email:        %s\n", c.Email)
+}
+
+// promptContacts asks for new contacts, offering the current ones as defaults
+func promptContacts(reader *bufio.Reader, current *config.ContactProfile) (*config.ContactProfile, error) {
+	statusln("Enter the new contacts. Press Enter to keep the value in brackets, or '-' to clear it.")
+
+	registrant, err := promptContact(reader, "Registrant", current.Registrant)
+	if err != nil {
+		return nil, err
+	}
+	contacts := &config.ContactProfile{Registrant: registrant}
+
+	statusf("\nUse the registrant as admin, tech and billing contact? (Y/n): ")
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "" || answer == "y" || answer == "yes" {
+		return contacts, nil
+	}
+
+	if contacts.Admin, err = promptContact(reader, "Admin", current.Admin); err != nil {
+		return nil, err
+	}
+	if contacts.Tech, err = promptContact(reader, "Tech", current.Tech); err != nil {
+		return nil, err
+	}
+	if contacts.Billing, err = promptContact(reader, "Billing", current.Billing); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// promptContact asks for every field of a contact, offering current as defaults
+func promptContact(reader *bufio.Reader, role string, current *config.Contact) (*config.Contact, error) {
+	contact := &config.Contact{}
+	if current != nil {
+		*contact = *current
+	}
+
+	statusf("\n%s contact\n", role)
+	for _, field := range []struct {
+		label string
+		value *string
+	}{
+		{"First name", &contact.FirstName},
+		{"Last name", &contact.LastName},
+		{"Organization", &contact.Organization},
+		{"Job title", &contact.JobTitle},
+		{"Address line 1", &contact.Address1},
+		{"Address line 2", &contact.Address2},
+		{"City", &contact.City},
+		{"State/province", &contact.StateProvince},
+		{"Postal code", &contact.PostalCode},
+		{"Country (two-letter code)", &contact.Country},
+		{"Phone (+CountryCode.Number)", &contact.Phone},
+		{"Email", &contact.Email},
+	} {
+		if *field.value != "" {
+			statusf("%s [%s]: ", field.label, *field.value)
+		} else {
+			statusf("%s: ", field.label)
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("failed to read %s: %w", strings.ToLower(field.label), err)
+		}
+		switch line = strings.TrimSpace(line); line {
+		case "":
+		case "-":
+			*field.value = ""
+		default:
+			*field.value = line
+		}
+	}
+	return contact, nil
+}
+
+func parseYears(yearsStr string) (int, error) {
+	var years int
+	_, err := fmt.Sscanf(yearsStr, "%d", &years)
+	if err != nil {
+		return 0, err
+	}
+	if years < 1 || years > 10 {
+		return 0, fmt.Errorf("years must be between 1 and 10")
+	}
+	return years, nil
+}
+
+func init() {
+	rootCmd.AddCommand(domainCmd)
+	domainCmd.AddCommand(domainListCmd)
+	domainCmd.AddCommand(domainInfoCmd)
+	domainCmd.AddCommand(domainCheckCmd)
+	domainCmd.AddCommand(domainNameserversCmd)
+	domainCmd.AddCommand(domainRegisterCmd)
+	domainCmd.AddCommand(domainRenewCmd)
+	domainCmd.AddCommand(domainRenewBatchCmd)
+	domainCmd.AddCommand(domainContactsCmd)
+	domainCmd.AddCommand(domainGlueCmd)
+	domainCmd.AddCommand(domainForwardCmd)
+
+	// Flags for domain info
+	domainInfoCmd.Flags().Bool("raw", false, "Include the registrar's untouched API response (with -o json or yaml)")
+
+	// Flags for domain check
+	domainCheckCmd.Flags().StringSlice("tlds", nil, "Comma-separated TLDs to check for the given name (e.g. com,net,io)")
+	domainCheckCmd.Flags().Bool("popular", false, "Check the name against a built-in set of popular TLDs")
+
+	// Flags for domain register
+	domainRegisterCmd.Flags().Int("years", 1, "Registration period in years (1-10)")
+	domainRegisterCmd.Flags().String("contact-profile", "", "Name of the contact profile in the configuration")
+	domainRegisterCmd.Flags().BoolP("confirm", "y", false, "Register the domain and charge the account")
+	_ = domainRegisterCmd.MarkFlagRequired("contact-profile")
+
+	// Flags for domain renew
+	domainRenewCmd.Flags().String("promo-code", "", "Promotion code to apply to the renewal")
+
+	// Flags for domain renew-batch
+	domainRenewBatchCmd.Flags().String("expiring-within", "30d", "Renew domains expiring within this window (e.g. 30d, 2w)")
+	domainRenewBatchCmd.Flags().Int("years", 1, "Renewal period in years (1-10)")
+	domainRenewBatchCmd.Flags().Float64("max-cost", 0, "Maximum total cost of the batch (0 for no limit)")
+	domainRenewBatchCmd.Flags().BoolP("confirm", "y", false, "Renew without asking for confirmation")
+
+	// Flags for domain contacts set
+	domainContactsSetCmd.Flags().String("file", "", "Read the contacts from a YAML file")
+	domainContactsSetCmd.Flags().String("contact-profile", "", "Use the contacts of a contact profile in the configuration")
+	domainContactsSetCmd.MarkFlagsMutuallyExclusive("file", "contact-profile")
+
+	// Flags for domain forward
+	domainForwardCmd.Flags().String("to", "", "URL to forward to (http:// or https://)")
+	domainForwardCmd.Flags().String("host", "@", "Host of the domain to forward")
+	domainForwardCmd.Flags().Bool("mask", false, "Keep the domain in the address bar, showing the target in a frame")
+	domainForwardCmd.Flags().Bool("permanent", false, "Redirect permanently (301) instead of temporarily (302)")
+	domainForwardCmd.Flags().Bool("remove", false, "Remove the forwarding")
+	domainForwardCmd.MarkFlagsMutuallyExclusive("to", "remove")
+	domainForwardCmd.MarkFlagsMutuallyExclusive("mask", "permanent")
+
+	// Flags for domain nameservers set
+	domainNameserversSetCmd.Flags().Bool("skip-resolve", false, "Do not require the nameservers to resolve")
+	domainNameserversSetBatchCmd.Flags().String("domains-file", "", "File listing the domains, one per line (- for standard input)")
+	domainNameserversSetBatchCmd.Flags().Int("concurrency", 4, "Domains changed at once")
+	domainNameserversSetBatchCmd.Flags().Bool("dry-run", false, "Show which domains would change without changing them")
+	domainNameserversSetBatchCmd.Flags().Bool("skip-resolve", false, "Do not require the nameservers to resolve")
+	_ = domainNameserversSetBatchCmd.MarkFlagRequired("domains-file")
+
+	domainNameserversCmd.AddCommand(domainNameserversGetCmd)
+	domainNameserversCmd.AddCommand(domainNameserversSetCmd)
+	domainNameserversCmd.AddCommand(domainNameserversSetBatchCmd)
+	domainNameserversCmd.AddCommand(domainNameserversDefaultCmd)
+
+	domainContactsCmd.AddCommand(domainContactsGetCmd)
+	domainContactsCmd.AddCommand(domainContactsSetCmd)
+
+	domainGlueCmd.AddCommand(domainGlueListCmd)
+	domainGlueCmd.AddCommand(domainGlueAddCmd)
+	domainGlueCmd.AddCommand(domainGlueUpdateCmd)
+	domainGlueCmd.AddCommand(domainGlueDeleteCmd)
 }
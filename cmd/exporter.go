@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/internal/cmdutil"
+	"zonekit/pkg/config"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/domain"
+	"zonekit/pkg/exporter"
+)
+
+// exporterCmd represents the exporter command
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve domain and DNS metrics for Prometheus",
+	Long: `Serve metrics about the current account on /metrics in the Prometheus text
+format, refreshed on an interval:
+
+  zonekit_domain_expiry_days{domain}            days until each domain expires
+  zonekit_zone_drift_records{zone,provider}     records differing from a zone file
+  zonekit_provider_up{provider}                 whether the last refresh reached the provider
+  zonekit_last_refresh_timestamp_seconds        time of the last refresh
+
+Drift is only reported for zones given with --zone-file, which compares the live
+zone with a BIND zone file the same way dns import does. Where the provider
+exposes the zone's SOA serial, a refresh only reads the zone's records again
+once the serial or the zone file changed.
+
+Examples:
+  zonekit exporter
+  zonekit exporter --listen :9153 --interval 5m --zone-file example.com=zones/example.com.zone`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, _ := cmd.Flags().GetString("listen")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		zoneFileFlags, _ := cmd.Flags().GetStringArray("zone-file")
+
+		if interval < time.Minute {
+			return fmt.Errorf("interval must be at least 1m to stay within API rate limits")
+		}
+
+		zoneFiles := make(map[string]string, len(zoneFileFlags))
+		for _, value := range zoneFileFlags {
+			zone, path, found := strings.Cut(value, "=")
+			if !found || zone == "" || path == "" {
+				return fmt.Errorf("invalid --zone-file %q: expected zone=path", value)
+			}
+			if err := dns.ValidateDomain(zone); err != nil {
+				return fmt.Errorf("invalid zone %s: %w", zone, err)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("zone file of %s: %w", zone, err)
+			}
+			zoneFiles[strings.ToLower(zone)] = path
+		}
+
+		// Get current account configuration
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+
+		// Create the account's DNS service and display account info
+		dnsService, client, err := accountDNSService(cmd, args, accountConfig)
+		if err != nil {
+			return err
+		}
+		cmdutil.DisplayAccountInfo(accountConfig)
+
+		exporterConfig := exporter.Config{
+			Provider: dnsService.ProviderName(),
+			// Each refresh reads the zones afresh, bypassing the service cache
+			NewRecordGetter: func() exporter.RecordGetter {
+				if client != nil {
+					return dns.NewService(client)
+				}
+				fresh, _ := dns.NewServiceWithProviderName(dnsService.ProviderName())
+				return fresh
+			},
+			ZoneFiles: zoneFiles,
+			Log:       os.Stderr,
+		}
+		// Expiry dates come from the registrar, which only Namecheap accounts have
+		if client != nil {
+			exporterConfig.Registrar = config.DefaultProvider
+			exporterConfig.Domains = domain.NewService(client)
+		}
+		exp := exporter.New(exporterConfig)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go exp.Run(interval, ctx.Done())
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", exp)
+		server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- server.ListenAndServe()
+		}()
+		statusf("Serving metrics on http://%s/metrics (refresh every %s)\n", displayAddr(listen), interval)
+
+		select {
+		case err := <-errCh:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve metrics: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		}
+	},
+}
+
+// displayAddr turns a listen address such as :9153 into one that can be browsed
+func displayAddr(listen string) string {
+	if strings.HasPrefix(listen, ":") {
+		return "localhost" + listen
+	}
+	return listen
+}
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().String("listen", ":9153", "Address to serve metrics on")
+	exporterCmd.Flags().Duration("interval", 15*time.Minute, "How often to refresh the metrics")
+	exporterCmd.Flags().StringArray("zone-file", nil, "Report drift of a zone against a zone file (zone=path, repeatable)")
+}
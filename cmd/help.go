@@ -31,27 +31,98 @@ var helpCmd = &cobra.Command{
 		fmt.Println("  zonekit account show [name]             - Show account details")
 		fmt.Println("  zonekit account edit [name]             - Edit account configuration")
 		fmt.Println("  zonekit account remove <name>           - Remove an account")
+		fmt.Println("  zonekit account export <name>           - Export an account (secrets redacted)")
 		fmt.Println()
 
 		fmt.Println("🌐 Domain Management Commands:")
 		fmt.Println("  zonekit domain list                     - List all domains")
 		fmt.Println("  zonekit domain info <domain>            - Get domain details")
 		fmt.Println("  zonekit domain check <domain>           - Check domain availability")
+		fmt.Println("  zonekit domain check <name> --tlds com,net,io")
+		fmt.Println("  zonekit domain register <domain> --contact-profile <name> [--years N]")
 		fmt.Println("  zonekit domain renew <domain> [years]   - Renew a domain")
+		fmt.Println("  zonekit domain renew-batch              - Renew all domains expiring soon")
+		fmt.Println("  zonekit domain contacts get <domain>    - Show domain contacts")
+		fmt.Println("  zonekit domain contacts set <domain> [--file contacts.yaml | --contact-profile <name>]")
 		fmt.Println("  zonekit domain nameservers get <domain> - Get nameservers")
-		fmt.Println("  zonekit domain nameservers set <domain> <ns1> [ns2] [ns3] [ns4]")
+		fmt.Println("  zonekit domain nameservers set <domain> <ns1> <ns2> [ns3...]")
+		fmt.Println("  zonekit domain nameservers set-batch --domains-file <file> <ns1> <ns2> [--dry-run]")
 		fmt.Println("  zonekit domain nameservers default <domain>")
+		fmt.Println("  zonekit domain glue list|add|update|delete <domain> <ns> <ip>")
+		fmt.Println("  zonekit domain forward <domain> --to <url> [--mask | --permanent]")
 		fmt.Println()
 
 		fmt.Println("🔧 DNS Management Commands:")
 		fmt.Println("  zonekit dns list <domain>               - List DNS records")
+		fmt.Println("  zonekit dns list --all-domains          - List records across all domains")
+		fmt.Println("  zonekit dns find <value> [--account-all] - Find records across all domains")
+		fmt.Println("  zonekit dns list <domain> --show-comments [--tag <tag>] - Show record comments and tags")
+		fmt.Println("  zonekit dns add --all-domains @ TXT ... - Add a record to every domain (resumable)")
 		fmt.Println("  zonekit dns add <domain> <host> <type> <value>")
 		fmt.Println("  zonekit dns update <domain> <host> <type> <value>")
 		fmt.Println("  zonekit dns delete <domain> <host> <type>")
+		fmt.Println("  zonekit dns delete <domain> --id <id>   - Delete a record by ID (see dns list --ids)")
 		fmt.Println("  zonekit dns clear <domain>              - Clear all records")
 		fmt.Println("  zonekit dns bulk <domain> <file>        - Bulk operations")
 		fmt.Println("  zonekit dns import <domain> <file>      - Import zone file")
-		fmt.Println("  zonekit dns export <domain> [file]      - Export zone file")
+		fmt.Println("  zonekit dns sync <domain> <state-file>  - Make the zone match a YAML/JSON state file")
+		fmt.Println("  zonekit dns plan <domain> -f <file> --out plan.json - Save a plan for review")
+		fmt.Println("  zonekit dns apply plan.json             - Apply a reviewed plan")
+		fmt.Println("  zonekit dns export <domain> [file]      - Export zone file (--format json|yaml|csv|terraform)")
+		fmt.Println("  zonekit dns changelog <domain>          - Markdown changelog of recorded changes")
+		fmt.Println("  zonekit dns backup <domain>             - Save a snapshot of the records")
+		fmt.Println("  zonekit dns restore <domain> <snapshot> - Restore records from a snapshot")
+		fmt.Println("  zonekit dns backups list [domain]       - List saved snapshots")
+		fmt.Println("  zonekit dns undo <domain>               - Revert the last change")
+		fmt.Println("  zonekit dns ddns <domain> [host]        - Point a host at this machine's public IP")
+		fmt.Println("  zonekit dns verify <domain>             - Compare provider, live DNS and a zone file")
+		fmt.Println("  zonekit dns stale <domain>              - Find records pointing at dead infrastructure")
+		fmt.Println("  zonekit dns watch <domain>              - Report record changes as they happen")
+		fmt.Println("  Add --provider <name> to use another provider than the account's for one run")
+		fmt.Println("  zonekit policy validate                 - Validate the configured policy file")
+		fmt.Println()
+
+		fmt.Println("💬 Interactive Shell:")
+		fmt.Println("  zonekit shell                           - Run commands interactively (use <domain>, then list, add ...)")
+		fmt.Println("  undo | redo                             - Revert or reapply a change made in the shell")
+		fmt.Println()
+
+		fmt.Println("🗂️  Zone Commands:")
+		fmt.Println("  zonekit zone list                       - List zones of the account's provider")
+		fmt.Println("  zonekit zone list --all-providers       - List zones across all providers")
+		fmt.Println()
+
+		fmt.Println("📈 Monitoring Commands:")
+		fmt.Println("  zonekit exporter [--listen :9153]       - Serve expiry, drift and provider metrics")
+		fmt.Println()
+
+		fmt.Println("🔐 ACME Commands:")
+		fmt.Println("  zonekit acme serve [--listen :8053]     - Serve DNS-01 challenge records to ACME clients")
+		fmt.Println()
+
+		fmt.Println("🌐 Server Commands:")
+		fmt.Println("  zonekit serve --token <token> [--ui]    - Serve the REST API and the web UI")
+		fmt.Println()
+
+		fmt.Println("💾 Local State Commands:")
+		fmt.Println("  zonekit state info                      - Show where local state is kept")
+		fmt.Println("  zonekit state list <bucket>             - List the items of a state bucket")
+		fmt.Println("  zonekit state prune --older-than 90d    - Remove old local state")
+		fmt.Println()
+
+		fmt.Println("🔍 Audit Commands:")
+		fmt.Println("  zonekit audit list                      - List the logged DNS and registrar changes")
+		fmt.Println("  zonekit audit show <id>                 - Show a change with its state before and after")
+		fmt.Println()
+
+		fmt.Println("📜 Schema Commands:")
+		fmt.Println("  zonekit schema dump                     - Describe all commands and flags as JSON")
+		fmt.Println()
+
+		fmt.Println("🧩 Service Template Commands:")
+		fmt.Println("  zonekit service setup <service> <domain> - Create a service's DNS records")
+		fmt.Println("  zonekit service pack export <file>       - Bundle templates into a pack")
+		fmt.Println("  zonekit service pack install <file|url>  - Install templates from a pack")
 		fmt.Println()
 
 		fmt.Println("⚙️  Configuration Commands:")
@@ -59,6 +130,12 @@ var helpCmd = &cobra.Command{
 		fmt.Println("  zonekit config set                       - Set configuration (legacy)")
 		fmt.Println("  zonekit config show                      - Show configuration (legacy)")
 		fmt.Println("  zonekit config validate                  - Validate configuration")
+		fmt.Println("  zonekit config doctor                    - Diagnose configuration problems")
+		fmt.Println("  zonekit config which                     - Show which config file is used")
+		fmt.Println("  zonekit config migrate                   - Migrate namecheap-dns config files")
+		fmt.Println("  zonekit provider validate <name> [domain] - Check a provider's response mappings")
+		fmt.Println("  zonekit provider conformance --all       - Check providers against the conformance suite")
+		fmt.Println("  zonekit completion bash                  - Shell completion (domains from the local cache)")
 		fmt.Println()
 
 		fmt.Println("🚀 Quick Start Examples:")
@@ -91,6 +168,7 @@ var helpCmd = &cobra.Command{
 		fmt.Println("5. Manage DNS records:")
 		fmt.Println("   zonekit dns add example.com www A 192.168.1.1")
 		fmt.Println("   zonekit dns add example.com mail MX 192.168.1.2 --mx-pref 10")
+		fmt.Println("   zonekit dns add example.com _sip._tcp SRV --priority 10 --weight 5 --port 5060 --target sip.example.com")
 		fmt.Println("   zonekit dns list example.com")
 		fmt.Println()
 
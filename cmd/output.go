@@ -0,0 +1,462 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"zonekit/pkg/client"
+	"zonekit/pkg/config"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/domain"
+	"zonekit/pkg/plugin"
+	"zonekit/pkg/stale"
+	"zonekit/pkg/state"
+	"zonekit/pkg/zonecompare"
+)
+
+// The types below are the --output json/yaml schemas of commands whose table
+// output is free-form text. Field names are part of the CLI's interface and
+// must not be renamed.
+
+// domainView is the structured form of a domain
+type domainView struct {
+	Name              string   `json:"name"`
+	Owner             string   `json:"owner,omitempty"`
+	Status            string   `json:"status,omitempty"`
+	Created           string   `json:"created"`
+	Expires           string   `json:"expires"`
+	Expired           bool     `json:"expired"`
+	Locked            bool     `json:"locked"`
+	AutoRenew         bool     `json:"auto_renew"`
+	WhoisGuard        string   `json:"whois_guard,omitempty"`
+	WhoisGuardExpires string   `json:"whois_guard_expires,omitempty"`
+	Premium           bool     `json:"premium"`
+	ProviderDNS       bool     `json:"provider_dns"`
+	Nameservers       []string `json:"nameservers,omitempty"`
+	// Forwarding is only read for domains using the provider's DNS
+	Forwarding []forwardView `json:"forwarding,omitempty"`
+	// Raw is the provider's API response, only included with --raw
+	Raw client.RawXML `json:"raw,omitempty"`
+}
+
+func newDomainView(d domain.Domain) domainView {
+	return domainView{
+		Name:              d.Name,
+		Owner:             d.User,
+		Status:            d.Status,
+		Created:           d.Created,
+		Expires:           d.Expires,
+		Expired:           d.IsExpired,
+		Locked:            d.IsLocked,
+		AutoRenew:         d.AutoRenew,
+		WhoisGuard:        d.WhoisGuard,
+		WhoisGuardExpires: d.WhoisGuardExpires,
+		Premium:           d.IsPremium,
+		ProviderDNS:       d.IsOurDNS,
+		Nameservers:       d.Nameservers,
+	}
+}
+
+// availabilityView is the structured form of a domain availability check
+type availabilityView struct {
+	Domain    string  `json:"domain"`
+	Available bool    `json:"available"`
+	Premium   bool    `json:"premium"`
+	Price     float64 `json:"price,omitempty"`
+	Currency  string  `json:"currency,omitempty"`
+	// Premium prices and fees are in USD, as returned by the registrar
+	PremiumRegistrationPrice float64 `json:"premium_registration_price,omitempty"`
+	PremiumRenewalPrice      float64 `json:"premium_renewal_price,omitempty"`
+	EAPFee                   float64 `json:"eap_fee,omitempty"`
+	ICANNFee                 float64 `json:"icann_fee,omitempty"`
+	Error                    string  `json:"error,omitempty"`
+}
+
+func newAvailabilityView(a domain.Availability) availabilityView {
+	view := availabilityView{
+		Domain:    a.Domain,
+		Available: a.Available,
+		Premium:   a.IsPremium,
+		Price:     a.Price,
+		Currency:  a.Currency,
+
+		PremiumRegistrationPrice: a.PremiumRegistrationPrice,
+		PremiumRenewalPrice:      a.PremiumRenewalPrice,
+		EAPFee:                   a.EAPFee,
+		ICANNFee:                 a.ICANNFee,
+	}
+	if a.Err != nil {
+		view.Error = a.Err.Error()
+	}
+	return view
+}
+
+// registrationView is the structured form of a domain registration
+type registrationView struct {
+	Domain        string  `json:"domain"`
+	Years         int     `json:"years"`
+	Registered    bool    `json:"registered"`
+	ChargedAmount float64 `json:"charged_amount"`
+	DomainID      string  `json:"domain_id,omitempty"`
+	OrderID       string  `json:"order_id,omitempty"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+}
+
+func newRegistrationView(r *domain.Registration, years int) registrationView {
+	return registrationView{
+		Domain:        r.Domain,
+		Years:         years,
+		Registered:    r.Registered,
+		ChargedAmount: r.ChargedAmount,
+		DomainID:      r.DomainID,
+		OrderID:       r.OrderID,
+		TransactionID: r.TransactionID,
+	}
+}
+
+// renewalView is the structured form of a domain renewal
+type renewalView struct {
+	Domain        string  `json:"domain"`
+	Years         int     `json:"years"`
+	Renewed       bool    `json:"renewed"`
+	ChargedAmount float64 `json:"charged_amount"`
+	Expires       string  `json:"expires,omitempty"`
+	OrderID       string  `json:"order_id,omitempty"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+}
+
+func newRenewalView(r *domain.Renewal, years int) renewalView {
+	return renewalView{
+		Domain:        r.Domain,
+		Years:         years,
+		Renewed:       r.Renewed,
+		ChargedAmount: r.ChargedAmount,
+		Expires:       r.Expires,
+		OrderID:       r.OrderID,
+		TransactionID: r.TransactionID,
+	}
+}
+
+// renewBatchView is the structured form of a batch renewal or, before it is
+// confirmed, of its candidates
+type renewBatchView struct {
+	Currency string                 `json:"currency,omitempty"`
+	Balance  float64                `json:"balance"`
+	Domains  []renewBatchDomainView `json:"domains"`
+}
+
+// renewBatchDomainView is the structured form of one domain in a batch renewal
+type renewBatchDomainView struct {
+	Domain        string    `json:"domain"`
+	Expires       time.Time `json:"expires"`
+	Price         float64   `json:"price"`
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	ChargedAmount float64   `json:"charged_amount,omitempty"`
+	NewExpires    string    `json:"new_expires,omitempty"`
+	OrderID       string    `json:"order_id,omitempty"`
+}
+
+func newRenewBatchView(results []domain.BatchResult, balance *domain.Balance) renewBatchView {
+	view := renewBatchView{Domains: []renewBatchDomainView{}}
+	if balance != nil {
+		view.Currency, view.Balance = balance.Currency, balance.Available
+	}
+	for _, r := range results {
+		d := renewBatchDomainView{
+			Domain:  r.Candidate.Domain.Name,
+			Expires: r.Candidate.Expires,
+			Price:   r.Candidate.Price,
+			Status:  string(r.Status),
+		}
+		if d.Status == "" {
+			d.Status = "pending"
+		}
+		if r.Err != nil {
+			d.Error = r.Err.Error()
+		}
+		if r.Renewal != nil {
+			d.ChargedAmount, d.NewExpires, d.OrderID = r.Renewal.ChargedAmount, r.Renewal.Expires, r.Renewal.OrderID
+		}
+		view.Domains = append(view.Domains, d)
+	}
+	return view
+}
+
+// contactsView is the structured form of a domain's contacts. Its fields match
+// the contact profile configuration, so the YAML output can be edited and
+// passed back to domain contacts set --file.
+type contactsView struct {
+	Domain     string       `json:"domain"`
+	Registrant *contactView `json:"registrant"`
+	Admin      *contactView `json:"admin"`
+	Tech       *contactView `json:"tech"`
+	Billing    *contactView `json:"billing"`
+}
+
+// contactView is the structured form of a contact
+type contactView struct {
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Organization  string `json:"organization,omitempty"`
+	JobTitle      string `json:"job_title,omitempty"`
+	Address1      string `json:"address1"`
+	Address2      string `json:"address2,omitempty"`
+	City          string `json:"city"`
+	StateProvince string `json:"state_province"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+	Phone         string `json:"phone"`
+	Email         string `json:"email"`
+}
+
+func newContactsView(domainName string, p *config.ContactProfile) contactsView {
+	return contactsView{
+		Domain:     domainName,
+		Registrant: newContactView(p.Registrant),
+		Admin:      newContactView(p.Admin),
+		Tech:       newContactView(p.Tech),
+		Billing:    newContactView(p.Billing),
+	}
+}
+
+func newContactView(c *config.Contact) *contactView {
+	if c == nil {
+		return nil
+	}
+	return &contactView{
+		FirstName:     c.FirstName,
+		LastName:      c.LastName,
+		Organization:  c.Organization,
+		JobTitle:      c.JobTitle,
+		Address1:      c.Address1,
+		Address2:      c.Address2,
+		City:          c.City,
+		StateProvince: c.StateProvince,
+		PostalCode:    c.PostalCode,
+		Country:       c.Country,
+		Phone:         c.Phone,
+		Email:         c.Email,
+	}
+}
+
+// nameserversView is the structured form of a domain's nameservers
+type nameserversView struct {
+	Domain      string   `json:"domain"`
+	Nameservers []string `json:"nameservers"`
+}
+
+// nameserverBatchView is the structured form of one domain in a nameserver batch
+type nameserverBatchView struct {
+	Domain   string   `json:"domain"`
+	Status   string   `json:"status"`
+	Previous []string `json:"previous,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func newNameserverBatchViews(results []domain.NameserverResult) []nameserverBatchView {
+	views := make([]nameserverBatchView, 0, len(results))
+	for _, r := range results {
+		view := nameserverBatchView{Domain: r.Domain, Status: string(r.Status), Previous: r.Previous}
+		if r.Err != nil {
+			view.Error = r.Err.Error()
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// forwardView is the structured form of a URL forwarding record
+type forwardView struct {
+	Hostname string `json:"hostname"`
+	Mode     string `json:"mode"`
+	Target   string `json:"target"`
+}
+
+func newForwardViews(records []dnsrecord.Record) []forwardView {
+	views := make([]forwardView, 0, len(records))
+	for _, r := range records {
+		views = append(views, forwardView{Hostname: r.HostName, Mode: forwardMode(r.RecordType), Target: r.Address})
+	}
+	return views
+}
+
+// glueView is the structured form of a nameserver registered under a domain
+type glueView struct {
+	Nameserver string   `json:"nameserver"`
+	IP         string   `json:"ip"`
+	Statuses   []string `json:"statuses"`
+}
+
+// accountView is the structured form of an account. The API key is never
+// included, only whether one is set.
+type accountView struct {
+	Name            string `json:"name"`
+	Current         bool   `json:"current"`
+	Provider        string `json:"provider,omitempty"`
+	Username        string `json:"username"`
+	APIUser         string `json:"api_user"`
+	APIKeySet       bool   `json:"api_key_set"`
+	ClientIP        string `json:"client_ip"`
+	Sandbox         bool   `json:"sandbox"`
+	ProductionGuard bool   `json:"production_guard"`
+	Description     string `json:"description,omitempty"`
+}
+
+func newAccountView(name string, current bool, account *config.AccountConfig) accountView {
+	return accountView{
+		Name:            name,
+		Current:         current,
+		Provider:        account.Provider,
+		Username:        account.Username,
+		APIUser:         account.APIUser,
+		APIKeySet:       account.APIKey != "",
+		ClientIP:        account.ClientIP,
+		Sandbox:         account.UseSandbox,
+		ProductionGuard: account.ProductionGuard,
+		Description:     account.Description,
+	}
+}
+
+// pluginView is the structured form of a plugin
+type pluginView struct {
+	Name        string              `json:"name"`
+	Version     string              `json:"version"`
+	Description string              `json:"description"`
+	Commands    []pluginCommandView `json:"commands"`
+}
+
+// pluginCommandView is the structured form of a plugin command
+type pluginCommandView struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newPluginView(p plugin.Plugin) pluginView {
+	view := pluginView{
+		Name:        p.Name(),
+		Version:     p.Version(),
+		Description: p.Description(),
+		Commands:    []pluginCommandView{},
+	}
+	for _, c := range p.Commands() {
+		view.Commands = append(view.Commands, pluginCommandView{Name: c.Name, Description: c.Description})
+	}
+	return view
+}
+
+// zoneView is the structured form of a zone served by a provider. Registered
+// is omitted when the account's domains could not be listed.
+type zoneView struct {
+	Zone       string `json:"zone"`
+	Provider   string `json:"provider"`
+	Registered *bool  `json:"registered,omitempty"`
+}
+
+func newZoneView(zone, providerName string, registered map[string]bool) zoneView {
+	view := zoneView{Zone: zone, Provider: providerName}
+	if registered != nil {
+		inAccount := registered[strings.ToLower(zone)]
+		view.Registered = &inAccount
+	}
+	return view
+}
+
+// stateInfoView is the structured form of the local state store
+type stateInfoView struct {
+	Backend         string            `json:"backend"`
+	Location        string            `json:"location"`
+	SQLiteAvailable bool              `json:"sqlite_available"`
+	Buckets         []stateBucketView `json:"buckets"`
+}
+
+// stateBucketView is the structured form of a state bucket
+type stateBucketView struct {
+	Name   string    `json:"name"`
+	Count  int       `json:"count"`
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+}
+
+func newStateInfoView(store state.Store, buckets []state.BucketInfo) stateInfoView {
+	view := stateInfoView{
+		Backend:         store.Backend(),
+		Location:        store.Location(),
+		SQLiteAvailable: state.SQLiteAvailable(),
+		Buckets:         []stateBucketView{},
+	}
+	for _, b := range buckets {
+		view.Buckets = append(view.Buckets, stateBucketView{Name: b.Name, Count: b.Count, Oldest: b.Oldest, Newest: b.Newest})
+	}
+	return view
+}
+
+// stateItemView is the structured form of a state value
+type stateItemView struct {
+	Key       string          `json:"key"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// verifyView is the structured form of a dns verify comparison
+type verifyView struct {
+	Domain     string          `json:"domain"`
+	Sources    []string        `json:"sources"`
+	Match      bool            `json:"match"`
+	Mismatches int             `json:"mismatches"`
+	RecordSets []verifySetView `json:"record_sets"`
+}
+
+// verifySetView is the structured form of one compared record set. Values has
+// an entry per source that can hold the record type.
+type verifySetView struct {
+	HostName string              `json:"hostname"`
+	Type     string              `json:"type"`
+	Values   map[string][]string `json:"values"`
+	Match    bool                `json:"match"`
+}
+
+func newVerifyView(report *zonecompare.Report) verifyView {
+	view := verifyView{
+		Domain:     report.Domain,
+		Sources:    report.Sources,
+		Match:      report.Mismatches() == 0,
+		Mismatches: report.Mismatches(),
+		RecordSets: []verifySetView{},
+	}
+	for _, row := range report.Rows {
+		view.RecordSets = append(view.RecordSets, verifySetView{HostName: row.HostName, Type: row.Type, Values: row.Values, Match: row.Match})
+	}
+	return view
+}
+
+// staleView is the structured form of a dns stale check
+type staleView struct {
+	Domain  string            `json:"domain"`
+	Stale   int               `json:"stale"`
+	Records []staleRecordView `json:"records"`
+}
+
+// staleRecordView is the structured form of one checked record
+type staleRecordView struct {
+	HostName string `json:"hostname"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason"`
+}
+
+func newStaleView(domain string, results []stale.Result) staleView {
+	view := staleView{Domain: domain, Stale: countStale(results), Records: []staleRecordView{}}
+	for _, result := range results {
+		view.Records = append(view.Records, staleRecordView{
+			HostName: result.Record.HostName,
+			Type:     result.Record.RecordType,
+			Value:    result.Record.Address,
+			Status:   string(result.Status),
+			Reason:   result.Reason,
+		})
+	}
+	return view
+}
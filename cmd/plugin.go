@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
-	"zonekit/internal/cmdutil"
 	"zonekit/pkg/dns"
 	"zonekit/pkg/dnsrecord"
 	"zonekit/pkg/plugin"
+	"zonekit/pkg/render"
 )
 
 // pluginCmd represents the plugin command
@@ -23,16 +25,29 @@ var pluginListCmd = &cobra.Command{
 	Short: "List all available plugins",
 	Long:  `Display all registered plugins and their commands.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+
 		plugins := plugin.List()
 
+		if output.Structured() {
+			views := make([]pluginView, 0, len(plugins))
+			for _, p := range plugins {
+				views = append(views, newPluginView(p))
+			}
+			return render.Encode(os.Stdout, output, views)
+		}
+
 		if len(plugins) == 0 {
-			fmt.Println("No plugins registered.")
+			statusln("No plugins registered.")
 			return nil
 		}
 
-		fmt.Println("Available Plugins:")
-		fmt.Println("==================")
-		fmt.Println()
+		statusln("Available Plugins:")
+		statusln("==================")
+		statusln()
 
 		for _, p := range plugins {
 			fmt.Printf("📦 %s (v%s)\n", p.Name(), p.Version())
@@ -77,7 +92,7 @@ var pluginInfoCmd = &cobra.Command{
 			fmt.Println("Commands:")
 			fmt.Println("=========")
 			for _, cmd := range commands {
-				fmt.Printf("\n%s\n", cmd.Name)
+				fmt.Printf("\n%s %s\n", cmd.Name, cmd.Usage)
 				fmt.Printf("  %s\n", cmd.Description)
 				if cmd.LongDescription != "" {
 					fmt.Printf("  %s\n", cmd.LongDescription)
@@ -89,84 +104,147 @@ var pluginInfoCmd = &cobra.Command{
 	},
 }
 
-// pluginExecuteCmd executes a plugin command
-var pluginExecuteCmd = &cobra.Command{
-	Use:   "<plugin-name> <command> <domain> [args...]",
-	Short: "Execute a plugin command",
-	Long:  `Execute a command from a specific plugin.`,
-	Args:  cobra.MinimumNArgs(3), // plugin-name, command, domain
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pluginName := args[0]
-		commandName := args[1]
-		domainName := args[2]
-		extraArgs := args[3:]
+// pluginRunCmd holds one subcommand per registered plugin
+var pluginRunCmd = &cobra.Command{
+	Use:   "run <plugin-name> <command> [args...]",
+	Short: "Run a plugin command",
+	Long: `Run a command from a specific plugin.
 
-		// Get plugin
-		p, err := plugin.Get(pluginName)
+Every registered plugin is available as a subcommand, with its own help, flags and
+shell completion. Plugins whose name does not clash with a built-in command are also
+available at the top level, e.g. 'zonekit <plugin-name> <command>'.`,
+}
+
+// registerPluginCommands adds a cobra command tree for every registered plugin under
+// 'plugin run', and at the top level when the plugin name is not already taken
+func registerPluginCommands() {
+	names := plugin.Names()
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, err := plugin.Get(name)
 		if err != nil {
-			return fmt.Errorf("plugin not found: %w", err)
+			continue
 		}
 
-		// Find command
-		var pluginCmd plugin.Command
-		found := false
-		for _, c := range p.Commands() {
-			if c.Name == commandName {
-				pluginCmd = c
-				found = true
-				break
-			}
-		}
+		pluginRunCmd.AddCommand(newPluginCommand(p))
 
-		if !found {
-			return fmt.Errorf("command '%s' not found in plugin '%s'", commandName, pluginName)
+		if !hasSubcommand(rootCmd, name) {
+			rootCmd.AddCommand(newPluginCommand(p))
 		}
+	}
+}
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+// hasSubcommand reports whether parent already has a subcommand or alias called name
+func hasSubcommand(parent *cobra.Command, name string) bool {
+	for _, c := range parent.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
-		if err != nil {
-			return err
-		}
-		cmdutil.DisplayAccountInfo(accountConfig)
+// newPluginCommand builds the cobra command for a plugin with one subcommand per plugin command
+func newPluginCommand(p plugin.Plugin) *cobra.Command {
+	pluginCommand := &cobra.Command{
+		Use:   p.Name(),
+		Short: p.Description(),
+		Long:  fmt.Sprintf("%s (v%s)", p.Description(), p.Version()),
+	}
 
-		// Create DNS service
-		dnsService := dns.NewService(client)
+	for _, pc := range p.Commands() {
+		pluginCommand.AddCommand(newPluginSubcommand(pc))
+	}
+
+	return pluginCommand
+}
+
+// newPluginSubcommand builds the cobra command for a single plugin command
+func newPluginSubcommand(pc plugin.Command) *cobra.Command {
+	use := pc.Name
+	if pc.Usage != "" {
+		use += " " + pc.Usage
+	}
+
+	c := &cobra.Command{
+		Use:   use,
+		Short: pc.Description,
+		Long:  pc.LongDescription,
+		Args:  cobra.MinimumNArgs(pc.MinArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginCommand(cmd, pc, args)
+		},
+	}
+
+	if pc.Complete != nil {
+		complete := pc.Complete
+		c.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return complete(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	for _, f := range pc.Flags {
+		switch def := f.Default.(type) {
+		case bool:
+			c.Flags().BoolP(f.Name, f.Shorthand, def, f.Description)
+		case string:
+			c.Flags().StringP(f.Name, f.Shorthand, def, f.Description)
+		case []string:
+			c.Flags().StringArrayP(f.Name, f.Shorthand, def, f.Description)
+		case nil:
+			c.Flags().StringP(f.Name, f.Shorthand, "", f.Description)
+		}
+	}
 
-		// Build flags map from cobra command flags
-		flags := make(map[string]interface{})
+	return c
+}
 
-		// Get common flags
-		if cmd.Flags().Changed("dry-run") {
-			val, _ := cmd.Flags().GetBool("dry-run")
-			flags["dry-run"] = val
+// runPluginCommand executes a plugin command, creating a DNS service when it operates on a domain
+func runPluginCommand(cmd *cobra.Command, pc plugin.Command, args []string) error {
+	// Pass the flags the user set, with their declared types
+	flags := make(map[string]interface{})
+	flags["color"] = colorOutput()
+	for _, f := range pc.Flags {
+		if !cmd.Flags().Changed(f.Name) {
+			continue
 		}
-		if cmd.Flags().Changed("replace") {
-			val, _ := cmd.Flags().GetBool("replace")
-			flags["replace"] = val
+		switch f.Default.(type) {
+		case bool:
+			val, _ := cmd.Flags().GetBool(f.Name)
+			flags[f.Name] = val
+		case []string:
+			val, _ := cmd.Flags().GetStringArray(f.Name)
+			flags[f.Name] = val
+		default:
+			val, _ := cmd.Flags().GetString(f.Name)
+			flags[f.Name] = val
 		}
-		if cmd.Flags().Changed("confirm") {
-			val, _ := cmd.Flags().GetBool("confirm")
-			flags["confirm"] = val
+	}
+
+	ctx := &plugin.Context{
+		Args:   args,
+		Flags:  flags,
+		Output: &outputWriter{},
+	}
+
+	if pc.DomainArg > 0 {
+		if len(args) < pc.DomainArg {
+			return fmt.Errorf("missing domain argument")
 		}
+		ctx.Domain = args[pc.DomainArg-1]
 
-		// Create context - wrap DNS service to match interface
-		ctx := &plugin.Context{
-			Domain: domainName,
-			DNS:    &dnsServiceWrapper{service: dnsService},
-			Args:   extraArgs,
-			Flags:  flags,
-			Output: &outputWriter{},
+		// Use the account's provider, or the one selected with --provider
+		dnsService, _, err := resolveDNSService(cmd, args, ctx.Domain, true)
+		if err != nil {
+			return err
 		}
 
-		// Execute command
-		return pluginCmd.Execute(ctx)
-	},
+		// Wrap the DNS service to match the plugin interface
+		ctx.DNS = &dnsServiceWrapper{service: dnsService}
+	}
+
+	return pc.Execute(ctx)
 }
 
 // outputWriter implements plugin.OutputWriter
@@ -229,10 +307,5 @@ func init() {
 	rootCmd.AddCommand(pluginCmd)
 	pluginCmd.AddCommand(pluginListCmd)
 	pluginCmd.AddCommand(pluginInfoCmd)
-	pluginCmd.AddCommand(pluginExecuteCmd)
-
-	// Add common flags for plugin commands
-	pluginExecuteCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
-	pluginExecuteCmd.Flags().Bool("replace", false, "Replace existing records")
-	pluginExecuteCmd.Flags().BoolP("confirm", "y", false, "Confirm the operation")
+	pluginCmd.AddCommand(pluginRunCmd)
 }
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/changewindow"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/policy"
+	"zonekit/pkg/render"
+)
+
+var (
+	approvedBy      string
+	emergencyChange bool
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Check organization DNS rules",
+	Long: `Check DNS changes against the organization's rules before they are applied.
+
+The policy file is set with policy: in the config file, relative to the config
+file's directory. It holds YAML rules, or a Rego policy for files ending in
+.rego, evaluated with the opa command. Violations of error rules block a
+change; violations of warning rules are printed and the change is applied.
+
+YAML rules match changes by domain, account, provider, production (accounts
+with production_guard enabled), host, type and private_address, and require a
+min_ttl or max_ttl, hosts the records may be at, that the records are absent,
+or an approver given with --approved-by:
+
+  rules:
+    - name: prod-min-ttl
+      match: {production: true}
+      require: {min_ttl: 300}
+    - name: no-apex-cname
+      match: {hosts: ["@"], types: [CNAME]}
+      require: {absent: true}
+    - name: mx-approval
+      match: {types: [MX]}
+      require: {approval: true}
+    - name: internal-ips
+      severity: warning
+      match: {private_address: true}
+      require: {hosts: ["*.corp"]}
+
+Rego policies define deny and warn message sets in package zonekit. Their
+input holds domain, account, provider, production, approved_by and the
+written, removed and resulting records (host, type, value, ttl, priority).`,
+}
+
+// policyValidateCmd represents the policy validate command
+var policyValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a policy file and list its rules",
+	Long: `Validate a policy file and list its rules. Without a file the configured
+policy is validated.
+
+Examples:
+  zonekit policy validate
+  zonekit policy validate policy.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		var file string
+		if len(args) > 0 {
+			file = args[0]
+		} else {
+			configManager, err := GetConfigManager()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			if file = configManager.GetPolicyPath(); file == "" {
+				return fmt.Errorf("no policy configured; set policy: in the config file or pass a file")
+			}
+		}
+
+		p, err := policy.Load(file)
+		if err != nil {
+			return err
+		}
+		if p.Rego() != "" {
+			statusf("%s is a Rego policy, evaluated with opa when changes are applied\n", file)
+			return nil
+		}
+
+		table := render.NewTable("NAME", "SEVERITY", "REQUIRES", "DESCRIPTION")
+		for _, rule := range p.Rules {
+			table.AddRow(rule.Name, string(rule.Severity), describeRequire(rule.Require), rule.Description)
+		}
+		statusf("%s is valid: %d rules\n", file, len(p.Rules))
+		return writeOutput(output, table, p.Rules)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyValidateCmd)
+}
+
+// describeRequire summarizes what a rule requires
+func describeRequire(r policy.Require) string {
+	var parts []string
+	if r.MinTTL > 0 {
+		parts = append(parts, fmt.Sprintf("ttl >= %d", r.MinTTL))
+	}
+	if r.MaxTTL > 0 {
+		parts = append(parts, fmt.Sprintf("ttl <= %d", r.MaxTTL))
+	}
+	if len(r.Hosts) > 0 {
+		parts = append(parts, "hosts "+strings.Join(r.Hosts, ","))
+	}
+	if r.Absent {
+		parts = append(parts, "absent")
+	}
+	if r.Approval {
+		parts = append(parts, "approval")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// failedPolicy rejects every change because the configured policy could not
+// be loaded; changes are not applied unchecked
+type failedPolicy struct {
+	err error
+}
+
+func (p failedPolicy) Check(domainName string, before, after []dnsrecord.Record) ([]string, error) {
+	return nil, p.err
+}
+
+// policies runs several policies in turn; the first that rejects a change
+// rejects it
+type policies []dns.Policy
+
+func (ps policies) Check(domainName string, before, after []dnsrecord.Record) ([]string, error) {
+	var warnings []string
+	for _, p := range ps {
+		w, err := p.Check(domainName, before, after)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
+
+// attachPolicy checks the changes applied by dnsService against the
+// configured change windows and policy, if any
+func attachPolicy(cmd *cobra.Command, dnsService *dns.Service) {
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return
+	}
+
+	// A provider selected with --provider is not the account's
+	account := GetCurrentAccountName()
+	providerName, _ := cmd.Flags().GetString("provider")
+	if providerName != "" {
+		account = ""
+	}
+
+	var checks policies
+	if windows := configManager.GetChangeWindows(); len(windows) > 0 {
+		checks = append(checks, changewindow.NewChecker(windows, account, emergencyChange))
+	}
+	if file := configManager.GetPolicyPath(); file != "" {
+		checks = append(checks, loadPolicy(file, account, providerName == "", dnsService))
+	}
+
+	switch len(checks) {
+	case 0:
+	case 1:
+		dnsService.SetPolicy(checks[0])
+	default:
+		dnsService.SetPolicy(checks)
+	}
+}
+
+// loadPolicy loads the policy file for changes made in account; a policy that
+// cannot be loaded rejects every change
+func loadPolicy(file, account string, ownProvider bool, dnsService *dns.Service) dns.Policy {
+	p, err := policy.Load(file)
+	if err != nil {
+		return failedPolicy{err: fmt.Errorf("policy not applied, changes are blocked: %w", err)}
+	}
+
+	ctx := policy.Context{
+		Account:    account,
+		Provider:   dnsService.ProviderName(),
+		ApprovedBy: approvedBy,
+	}
+	if ownProvider {
+		if accountConfig, err := GetCurrentAccount(); err == nil {
+			ctx.Production = accountConfig.IsGuarded()
+		}
+	}
+	return policy.NewChecker(p, ctx)
+}
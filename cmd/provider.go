@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/conformance"
+	"zonekit/pkg/render"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// providerCmd represents the provider command
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Inspect registered DNS providers",
+	Long:  `Commands for checking the DNS providers registered from provider specs, the environment and the config file.`,
+}
+
+// providerValidateCmd represents the provider validate command
+var providerValidateCmd = &cobra.Command{
+	Use:   "validate <provider> [domain]",
+	Short: "Check a provider's configuration and response mappings",
+	Long: `Check that a registered provider is configured, and read its zones and the
+records of domain to check that its response mappings match the API.
+
+Responses are parsed strictly: a list path missing from a response is an error
+naming the keys the response has, instead of an empty zone. Pass --lenient to
+parse responses the way commands do at runtime.
+
+Examples:
+  zonekit provider validate cloudflare
+  zonekit provider validate cloudflare example.com
+  zonekit provider validate cloudflare example.com --lenient`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lenient, _ := cmd.Flags().GetBool("lenient")
+
+		p, err := dnsprovider.Get(args[0])
+		if err != nil {
+			return fmt.Errorf("%w (registered providers: %s)", err, strings.Join(dnsprovider.Names(), ", "))
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("provider %s is misconfigured: %w", args[0], err)
+		}
+		fmt.Printf("✅ %s: configuration is valid\n", args[0])
+
+		if sp, ok := p.(dnsprovider.StrictParser); ok {
+			sp.SetStrict(!lenient)
+			defer sp.SetStrict(false)
+		}
+
+		if lister, ok := p.(dnsprovider.ZoneLister); ok {
+			zones, err := lister.ListZones()
+			if err != nil {
+				return fmt.Errorf("failed to list zones: %w", err)
+			}
+			fmt.Printf("✅ %s: listed %d zone(s)\n", args[0], len(zones))
+		}
+
+		if len(args) == 2 {
+			records, err := p.GetRecords(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to read records of %s: %w", args[1], err)
+			}
+			fmt.Printf("✅ %s: read %d record(s) of %s\n", args[0], len(records), args[1])
+		} else {
+			statusln("Pass a domain to also check that its records can be read.")
+		}
+
+		return nil
+	},
+}
+
+// providerConformanceCmd represents the provider conformance command
+var providerConformanceCmd = &cobra.Command{
+	Use:   "conformance [<provider> <zone>]",
+	Short: "Check that providers behave as zonekit expects",
+	Long: `Run the conformance suite against a provider: create, read back, update and
+delete test records at zonekit-conformance hosts, write a record of each common
+type, check that TTLs are kept, that applying the same records again changes
+nothing and that the zone's other records are left untouched.
+
+The suite writes to the zone, so use a zone set aside for testing. The test
+records are removed again, also those left behind by an interrupted run.
+
+With --all the suite runs against every registered provider concurrently, each
+with the test zone --zone-map assigns it, and prints a matrix of the checks
+each provider passed. The zone map is a YAML file of provider names to zones:
+
+  cloudflare: zonekit-test.example.com
+  digitalocean: zonekit-test.example.net
+
+Providers without a test zone are listed as skipped.
+
+Examples:
+  zonekit provider conformance cloudflare zonekit-test.example.com --confirm
+  zonekit provider conformance --all --zone-map zones.yaml --confirm`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		zoneMap, _ := cmd.Flags().GetString("zone-map")
+		confirm, _ := cmd.Flags().GetBool("confirm")
+
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		var targets []conformance.Target
+		var untested []string
+		switch {
+		case all && len(args) > 0:
+			return fmt.Errorf("pass either a provider and zone or --all")
+		case all:
+			if zoneMap == "" {
+				return fmt.Errorf("--all requires --zone-map with a test zone per provider")
+			}
+			zones, err := loadZoneMap(zoneMap)
+			if err != nil {
+				return err
+			}
+			for _, name := range dnsprovider.Names() {
+				zone, ok := zones[name]
+				if !ok {
+					untested = append(untested, name)
+					continue
+				}
+				p, err := dnsprovider.Get(name)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, conformance.Target{Name: name, Provider: p, Zone: zone})
+			}
+			for name := range zones {
+				if _, err := dnsprovider.Get(name); err != nil {
+					return fmt.Errorf("%s in %s: %w (registered providers: %s)", name, zoneMap, err, strings.Join(dnsprovider.Names(), ", "))
+				}
+			}
+		case len(args) == 2:
+			p, err := dnsprovider.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("%w (registered providers: %s)", err, strings.Join(dnsprovider.Names(), ", "))
+			}
+			targets = append(targets, conformance.Target{Name: args[0], Provider: p, Zone: args[1]})
+		default:
+			return fmt.Errorf("pass a provider and a test zone, or --all with --zone-map")
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("no provider has a test zone in %s", zoneMap)
+		}
+
+		if !confirm {
+			for _, target := range targets {
+				statusf("Would write and remove test records in %s at %s\n", target.Zone, target.Name)
+			}
+			statusf("Use --confirm to proceed.\n")
+			return nil
+		}
+
+		statusf("Running the conformance suite against %d provider(s)...\n", len(targets))
+		reports := conformance.RunAll(targets)
+
+		checks := conformance.Checks()
+		columns := []string{"PROVIDER", "ZONE"}
+		for _, check := range checks {
+			columns = append(columns, strings.ToUpper(check))
+		}
+		table := render.NewTable(columns...)
+		failed := 0
+		for _, report := range reports {
+			row := []string{report.Provider, report.Zone}
+			for _, check := range checks {
+				result, _ := report.Result(check)
+				row = append(row, conformanceCell(result.Status))
+			}
+			table.AddRow(row...)
+			if len(report.Failed()) > 0 {
+				failed++
+			}
+		}
+		for _, name := range untested {
+			row := []string{name, "-"}
+			for range checks {
+				row = append(row, conformanceCell(conformance.StatusSkip))
+			}
+			table.AddRow(row...)
+		}
+		if err := writeOutput(output, table, reports); err != nil {
+			return err
+		}
+
+		for _, report := range reports {
+			for _, result := range report.Failed() {
+				fmt.Fprintf(os.Stderr, "❌ %s %s: %s\n", report.Provider, result.Check, result.Detail)
+			}
+		}
+		if len(untested) > 0 {
+			statusf("No test zone for: %s\n", strings.Join(untested, ", "))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d provider(s) failed conformance checks", failed, len(reports))
+		}
+		statusf("✅ All %d provider(s) passed\n", len(reports))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(providerCmd)
+	providerCmd.AddCommand(providerValidateCmd)
+	providerCmd.AddCommand(providerConformanceCmd)
+
+	providerValidateCmd.Flags().Bool("lenient", false, "Read a missing list path as an empty zone, as at runtime")
+
+	providerConformanceCmd.Flags().Bool("all", false, "Run against every registered provider concurrently")
+	providerConformanceCmd.Flags().String("zone-map", "", "YAML file mapping provider names to test zones (with --all)")
+	providerConformanceCmd.Flags().BoolP("confirm", "y", false, "Write and remove test records")
+}
+
+// loadZoneMap reads a YAML file mapping provider names to test zones
+func loadZoneMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zone map: %w", err)
+	}
+	zones := make(map[string]string)
+	if err := yaml.Unmarshal(data, &zones); err != nil {
+		return nil, fmt.Errorf("failed to parse zone map %s: %w", path, err)
+	}
+	names := make([]string, 0, len(zones))
+	for name, zone := range zones {
+		if strings.TrimSpace(zone) == "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return nil, fmt.Errorf("zone map %s has no zone for: %s", path, strings.Join(names, ", "))
+	}
+	return zones, nil
+}
+
+// conformanceCell renders the status of a check in the conformance matrix
+func conformanceCell(status conformance.Status) string {
+	switch status {
+	case conformance.StatusPass:
+		return "✅"
+	case conformance.StatusFail:
+		return "❌"
+	}
+	return "-"
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/jobqueue"
+)
+
+// addQueueFlags registers the flags of commands that run through the job queue
+// with --all-domains
+func addQueueFlags(cmd *cobra.Command) {
+	defaults := jobqueue.DefaultLimits()
+	cmd.Flags().Bool("all-domains", false, "Run for every domain in the account, rate limited and resumable")
+	cmd.Flags().Bool("resume", false, "With --all-domains, continue an interrupted run, skipping the domains already done")
+	cmd.Flags().Bool("restart", false, "With --all-domains, discard the progress of an interrupted run and start over")
+	cmd.Flags().Float64("rate", defaults.Rate, "With --all-domains, domains started per second across providers (0 for no cap)")
+	cmd.Flags().StringToString("provider-rate", nil, "With --all-domains, domains started per second for a provider, e.g. namecheap=0.1 (API calls are always paced by the provider's rate_limits)")
+	cmd.Flags().Int("concurrency", defaults.Concurrency, "With --all-domains, domains changed at once")
+	cmd.MarkFlagsMutuallyExclusive("resume", "restart")
+}
+
+// queueLimits returns the job queue limits set by the queue flags
+func queueLimits(cmd *cobra.Command) (jobqueue.Limits, error) {
+	limits := jobqueue.DefaultLimits()
+	limits.Rate, _ = cmd.Flags().GetFloat64("rate")
+	limits.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+
+	providerRates, _ := cmd.Flags().GetStringToString("provider-rate")
+	for name, value := range providerRates {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return limits, fmt.Errorf("invalid --provider-rate for %s: %q", name, value)
+		}
+		limits.ProviderRates[name] = rate
+	}
+	return limits, nil
+}
+
+// runAllDomains runs change for every domain of the current account, or every
+// zone of the provider selected with --provider, through the job queue. runID
+// identifies the run for --resume; change returns a short description of what
+// it did to the domain. A production account needs --production, as the
+// domains cannot be confirmed one by one.
+func runAllDomains(cmd *cobra.Command, args []string, runID string, change func(dnsService *dns.Service, domainName string) (string, error)) error {
+	resume, _ := cmd.Flags().GetBool("resume")
+	restart, _ := cmd.Flags().GetBool("restart")
+	limits, err := queueLimits(cmd)
+	if err != nil {
+		return err
+	}
+
+	if providerName, _ := cmd.Flags().GetString("provider"); providerName == "" && !productionConfirmed {
+		accountConfig, err := GetCurrentAccount()
+		if err != nil {
+			return fmt.Errorf("failed to get account configuration: %w", err)
+		}
+		if accountConfig.IsGuarded() {
+			return fmt.Errorf("this is a production account: re-run with --production to change every domain of the account")
+		}
+	}
+
+	dnsService, domainList, err := resolveAllDomains(cmd, args, true)
+	if err != nil {
+		return err
+	}
+
+	store, err := openState()
+	if err != nil {
+		return err
+	}
+	queue := jobqueue.New(GetCurrentAccountName()+": "+dnsService.ProviderName()+": "+runID, store, limits)
+
+	saved, err := queue.Load()
+	if err != nil {
+		return err
+	}
+	if saved != nil && !saved.Finished && !resume && !restart {
+		return fmt.Errorf("an earlier run of this command stopped with %d of %d domains done: pass --resume to continue it or --restart to start over",
+			len(saved.Done), saved.Total)
+	}
+
+	var mu sync.Mutex
+	jobs := make([]jobqueue.Job, 0, len(domainList))
+	results := make(map[string]string, len(domainList))
+	for _, domainName := range domainList {
+		domainName := domainName
+		jobs = append(jobs, jobqueue.Job{
+			Key:      domainName,
+			Provider: dnsService.ProviderName(),
+			Run: func(ctx context.Context) error {
+				result, err := change(dnsService, domainName)
+				mu.Lock()
+				results[domainName] = result
+				mu.Unlock()
+				return err
+			},
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	statusf("Running for %d domain(s); interrupt with Ctrl-C and continue later with --resume\n", len(jobs))
+	progress, err := queue.Run(ctx, jobs, resume, func(key string, err error) {
+		if err != nil {
+			statusf("❌ %s: %v\n", key, err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		statusf("✅ %s: %s\n", key, results[key])
+	})
+	if progress != nil {
+		statusf("\n%d of %d domain(s) done, %d failed\n", len(progress.Done), progress.Total, len(progress.Failed))
+	}
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("interrupted; rerun with --resume to continue")
+	}
+	if err != nil {
+		return err
+	}
+	if len(progress.Failed) > 0 {
+		return fmt.Errorf("%d domain(s) failed; rerun with --resume to retry them", len(progress.Failed))
+	}
+	return nil
+}
@@ -1,21 +1,49 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"zonekit/pkg/config"
+	"zonekit/pkg/diffview"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/alidns"
 	"zonekit/pkg/dns/provider/autodiscover"
+	"zonekit/pkg/dns/provider/builder"
+	"zonekit/pkg/dns/provider/bunny"
+	"zonekit/pkg/dns/provider/cloudns"
+	"zonekit/pkg/dns/provider/desec"
+	"zonekit/pkg/dns/provider/duckdns"
+	"zonekit/pkg/dns/provider/dynu"
+	"zonekit/pkg/dns/provider/gandi"
+	"zonekit/pkg/dns/provider/he"
+	"zonekit/pkg/dns/provider/njalla"
+	"zonekit/pkg/dns/provider/ovh"
+	"zonekit/pkg/dns/provider/rfc2136"
 	"zonekit/pkg/plugin"
 	"zonekit/pkg/plugin/service"
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/render"
+	"zonekit/pkg/timefmt"
 	"zonekit/pkg/version"
 )
 
 var cfgFile string
 var accountName string
+var productionConfirmed bool
+var configDebug bool
+var noColor bool
+var outputFlag string
+var outputColumns []string
+var utcTimes bool
+var noWarnings bool
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -37,15 +65,33 @@ Current version: ` + version.Version + ` (pre-1.0.0)`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	// Plugins must be registered before argument parsing so their commands exist
+	initPlugins()
+	registerPluginCommands()
+	registerDomainCompletion(rootCmd)
+
+	// The exporter is only set up with the config, but the command's span is
+	// exported as long as it is set by the time the span ends
+	defer shutdownTracing()
+	return traceCommand(context.Background(), os.Args[1:], rootCmd.ExecuteContext)
 }
 
 func init() {
-	cobra.OnInitialize(initConfig, initProviders, initPlugins)
+	cobra.OnInitialize(initialize)
 
 	// Here you will define your flags and configuration settings.
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.zonekit.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (overrides ZONEKIT_CONFIG, ./configs/.zonekit.yaml and $HOME/.zonekit.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&configDebug, "config-debug", false, "print which config files were considered and which one is used")
 	rootCmd.PersistentFlags().StringVar(&accountName, "account", "", "use specific account (default: current account)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(render.FormatTable), "output format: table, json, yaml or csv (csv for list commands)")
+	rootCmd.PersistentFlags().StringSliceVar(&outputColumns, "columns", nil, "columns to show in table and csv output, e.g. --columns hostname,value")
+	rootCmd.PersistentFlags().BoolVar(&utcTimes, "utc", false, "show timestamps in UTC instead of the configured or local time zone")
+	rootCmd.PersistentFlags().String("provider", "", "use this registered provider (e.g. cloudflare-work, dynu) instead of the account's")
+	rootCmd.PersistentFlags().BoolVar(&noWarnings, "no-warnings", false, "do not warn about suspicious DNS changes (e.g. removing the only MX record) before applying them")
+	rootCmd.PersistentFlags().BoolVar(&productionConfirmed, "production", false, "confirm destructive operations on accounts with production_guard enabled")
+	rootCmd.PersistentFlags().StringVar(&approvedBy, "approved-by", "", "who approved the change, for policy rules that require approval")
+	rootCmd.PersistentFlags().BoolVar(&emergencyChange, "emergency", false, "apply DNS changes outside the configured change windows; the change history marks them as emergency changes")
 
 	// Legacy flags for backward compatibility (deprecated)
 	rootCmd.PersistentFlags().String("username", "", "Namecheap username (deprecated: use account management)")
@@ -62,43 +108,155 @@ func init() {
 	rootCmd.PersistentFlags().MarkDeprecated("sandbox", "use account management instead")
 }
 
+// initOnce runs the initializers once per process, as zonekit shell executes
+// many commands
+var initOnce sync.Once
+
+// initialize reads the config and registers the providers
+func initialize() {
+	initOnce.Do(func() {
+		migrateLegacyInstall()
+		initConfig()
+		initTracing()
+		initRateLimits()
+		initProviders()
+	})
+}
+
+// migrateLegacyInstall moves the home config and state of namecheap-dns
+// releases to zonekit's locations on first run, and asks users still running
+// the binary under its old name to switch on every run. Project config files are migrated by
+// initConfig when they are used, or with 'zonekit config migrate'.
+func migrateLegacyInstall() {
+	home, _ := os.UserHomeDir()
+	migrations, err := config.MigrateLegacy(home)
+	for _, m := range migrations {
+		fmt.Fprintf(os.Stderr, "Migrated %s to %s\n", m.From, m.To)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Release assets keep their namecheap-dns-<os>-<arch> names when installed
+	// as downloaded. The warning is shown on every run, as documented.
+	if config.IsLegacyBinary(os.Args[0]) {
+		fmt.Fprintf(os.Stderr, "Warning: %s has been renamed to zonekit; this binary will not be released under its old name after the next major version. Install zonekit and update your scripts.\n\n", config.LegacyName)
+	}
+}
+
 // initConfig reads in config file and ENV variables if set.
+// The config file is chosen by config.ResolveConfigPath; see 'zonekit config which'.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// First try to find config in project directory
-		projectConfigPath := config.FindProjectConfigPath()
-		if projectConfigPath != "" {
-			viper.SetConfigFile(projectConfigPath)
-		} else {
-			// Find home directory.
-			home, err := os.UserHomeDir()
-			cobra.CheckErr(err)
-
-			// Search config in home directory with name ".zonekit" (without extension).
-			viper.AddConfigPath(home)
-			viper.SetConfigType("yaml")
-			viper.SetConfigName(".zonekit")
+	resolution := config.ResolveConfigPath(cfgFile)
+	if resolution.Legacy {
+		// The selected config was left by namecheap-dns; use it under its new name
+		migration, err := config.MigrateLegacyProjectConfig(resolution.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if migration != nil {
+			fmt.Fprintf(os.Stderr, "Migrated %s to %s\n", migration.From, migration.To)
+			resolution = config.ResolveConfigPath(cfgFile)
 		}
 	}
+	if configDebug {
+		printConfigResolution(os.Stderr, resolution)
+	}
+
+	if resolution.Path != "" {
+		viper.SetConfigFile(resolution.Path)
+	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// Environment variables use the ZONEKIT_ prefix, e.g. ZONEKIT_API_USER
+	viper.SetEnvPrefix("zonekit")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	} else if configDebug {
+		fmt.Fprintf(os.Stderr, "Config file not loaded: %v\n", err)
+	}
+}
+
+// colorOutput reports whether colored output should be written to stdout
+func colorOutput() bool {
+	return diffview.ColorEnabled(os.Stdout, noColor)
+}
+
+// outputFormat returns the format selected with --output for commands whose
+// result is a document rather than a table
+func outputFormat() (render.Format, error) {
+	output, err := render.ParseFormat(outputFlag)
+	if err != nil {
+		return "", err
+	}
+	if output == render.FormatCSV {
+		return "", fmt.Errorf("csv output is not supported by this command (use table, json or yaml)")
+	}
+	if len(outputColumns) > 0 {
+		return "", fmt.Errorf("--columns is not supported by this command")
+	}
+	return output, nil
+}
+
+// tableOutputFormat returns the format selected with --output for commands
+// that print a table, which can also be written as CSV and narrowed with --columns
+func tableOutputFormat() (render.Format, error) {
+	output, err := render.ParseFormat(outputFlag)
+	if err != nil {
+		return "", err
+	}
+	if len(outputColumns) > 0 && output != render.FormatTable && output != render.FormatCSV {
+		return "", fmt.Errorf("--columns only applies to table and csv output")
+	}
+	return output, nil
+}
+
+// writeOutput writes a command's result to stdout in the selected format:
+// tables and CSV from table, JSON and YAML from v
+func writeOutput(output render.Format, table *render.Table, v interface{}) error {
+	return render.Renderer{Format: output, Columns: outputColumns}.Render(os.Stdout, table, v)
+}
+
+// statusf writes a message for humans (progress, confirmations, prompts and
+// hints) to stderr, so stdout only carries the command's result and can be
+// piped into other tools
+func statusf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// statusln is statusf with fmt.Println formatting
+func statusln(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+}
+
+// timeFormatter returns the formatter for dates and timestamps, configured by the
+// display section of the config file and --utc. Invalid settings fall back to
+// ISO dates in the local time zone with a warning.
+func timeFormatter() *timefmt.Formatter {
+	var display config.DisplayConfig
+	if configManager, err := GetConfigManager(); err == nil {
+		display = configManager.GetDisplay()
+	}
+	if utcTimes {
+		display.Timezone = "UTC"
+	}
+
+	formatter, err := timefmt.New(display.Timezone, display.DateFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		if utcTimes {
+			return timefmt.UTC()
+		}
+		return timefmt.Default()
 	}
+	return formatter
 }
 
 // GetConfigManager returns a configuration manager instance
 func GetConfigManager() (*config.Manager, error) {
-	// If config file is specified via flag, use it
-	if cfgFile != "" {
-		return config.NewManagerWithPath(cfgFile)
-	}
-	return config.NewManager()
+	return config.NewManagerWithPath(config.ResolveConfigPath(cfgFile).Path)
 }
 
 // GetCurrentAccount returns the current account configuration
@@ -117,7 +275,21 @@ func GetCurrentAccount() (*config.AccountConfig, error) {
 	return configManager.GetCurrentAccount()
 }
 
-// initProviders registers all available DNS providers
+// GetCurrentAccountName returns the name of the account in use
+func GetCurrentAccountName() string {
+	if accountName != "" {
+		return accountName
+	}
+
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return ""
+	}
+	return configManager.GetCurrentAccountName()
+}
+
+// initProviders registers all available DNS providers. Providers are registered
+// lazily and only constructed when a command uses them.
 func initProviders() {
 	// Auto-discover and register all REST-based providers from subdirectories
 	// OpenAPI-only approach: providers must have openapi.yaml file
@@ -125,6 +297,101 @@ func initProviders() {
 		// Log but don't fail - some providers might not have OpenAPI specs
 		// This is expected in development or if providers aren't configured
 	}
+
+	// Register hand-written adapters whose credentials are set in the environment
+	if _, err := alidns.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register alidns provider: %v\n", err)
+	}
+	if _, err := bunny.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register bunny provider: %v\n", err)
+	}
+	if _, err := cloudns.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register cloudns provider: %v\n", err)
+	}
+	if _, err := desec.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register desec provider: %v\n", err)
+	}
+	if _, err := gandi.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register gandi provider: %v\n", err)
+	}
+	if _, err := njalla.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register njalla provider: %v\n", err)
+	}
+	if _, err := ovh.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register ovh provider: %v\n", err)
+	}
+	if _, err := rfc2136.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register rfc2136 provider: %v\n", err)
+	}
+	if _, err := dynu.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register dynu provider: %v\n", err)
+	}
+	if _, err := duckdns.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register duckdns provider: %v\n", err)
+	}
+	if _, err := he.RegisterFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register he provider: %v\n", err)
+	}
+
+	registerConfiguredProviders()
+}
+
+// initRateLimits applies the rate overrides of the config file to the
+// providers' API clients
+func initRateLimits() {
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return
+	}
+	rates, err := configManager.GetRateLimits()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring rate_limits: %v\n", err)
+		return
+	}
+	ratelimit.SetOverrides(rates)
+}
+
+// registerConfiguredProviders registers the named provider instances from the
+// config file, e.g. cloudflare-personal and cloudflare-work
+func registerConfiguredProviders() {
+	configManager, err := GetConfigManager()
+	if err != nil {
+		return
+	}
+
+	instances := configManager.GetProviders()
+	names := make([]string, 0, len(instances))
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		instance := instances[name]
+
+		// Credentials are resolved when the instance is first used, so secrets
+		// of providers the command does not touch are never looked up
+		err := dnsprovider.RegisterLazy(name, func() (dnsprovider.Provider, error) {
+			// Instances of type rest declare their API and are built like
+			// providers discovered from specs
+			if instance.Type == config.ProviderTypeREST {
+				cfg, err := instance.RESTConfig(name)
+				if err != nil {
+					return nil, err
+				}
+				return builder.BuildProvider(cfg)
+			}
+
+			credentials, err := instance.ResolveCredentials()
+			if err != nil {
+				return nil, err
+			}
+			return dnsprovider.NewInstance(name, instance.Type, credentials)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: provider %s: %v\n", name, err)
+		}
+	}
 }
 
 // initPlugins registers all built-in plugins
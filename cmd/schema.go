@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"zonekit/pkg/render"
+	"zonekit/pkg/version"
+)
+
+// schemaVersion is the version of the command schema written by schema dump.
+// It changes only when fields are removed or change meaning.
+const schemaVersion = 1
+
+// cliSchema is the machine-readable description of every command
+type cliSchema struct {
+	SchemaVersion int             `json:"schema_version"`
+	Program       string          `json:"program"`
+	Version       string          `json:"version"`
+	GlobalFlags   []flagSchema    `json:"global_flags"`
+	Commands      []commandSchema `json:"commands"`
+}
+
+// commandSchema describes a command and its subcommands
+type commandSchema struct {
+	// Name is the full command path without the program, e.g. "dns add"
+	Name     string          `json:"name"`
+	Usage    string          `json:"usage"`
+	Short    string          `json:"short"`
+	Long     string          `json:"long,omitempty"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Runnable bool            `json:"runnable"`
+	Args     []argSchema     `json:"args,omitempty"`
+	Flags    []flagSchema    `json:"flags,omitempty"`
+	Examples []exampleSchema `json:"examples,omitempty"`
+	Commands []commandSchema `json:"commands,omitempty"`
+}
+
+// argSchema describes a positional argument, as named in the usage line
+type argSchema struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Variadic bool   `json:"variadic"`
+}
+
+// flagSchema describes a flag
+type flagSchema struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage"`
+	Required  bool   `json:"required,omitempty"`
+	// Persistent flags apply to the command's subcommands as well
+	Persistent bool `json:"persistent,omitempty"`
+}
+
+// exampleSchema is an example invocation, also split into arguments so SDKs
+// in other languages can run it without a shell
+type exampleSchema struct {
+	Command string   `json:"command"`
+	Argv    []string `json:"argv"`
+}
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Describe zonekit's commands for other programs",
+	Long:  `Commands that describe zonekit's command line interface in a machine-readable form.`,
+}
+
+// schemaDumpCmd represents the schema dump command
+var schemaDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print a JSON description of every command, argument and flag",
+	Long: `Print a JSON (or with -o yaml, YAML) description of every command with its
+positional arguments, flags and examples, built from the command definitions.
+
+The schema is the contract for programs that drive zonekit: generated SDKs and
+their examples, external plugins and graphical frontends. Example invocations
+come with an argv array that can be executed without a shell. Hidden and
+deprecated commands and flags are left out. schema_version changes only when
+fields are removed or change meaning.`,
+	Example: `  zonekit schema dump > zonekit-schema.json
+  zonekit schema dump | jq '.commands[] | .name'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		if !output.Structured() {
+			output = render.FormatJSON
+		}
+
+		root := cmd.Root()
+		schema := cliSchema{
+			SchemaVersion: schemaVersion,
+			Program:       root.Name(),
+			Version:       version.Version,
+			GlobalFlags:   flagSchemas(root, root.PersistentFlags()),
+			Commands:      commandSchemas(root),
+		}
+		return render.Encode(os.Stdout, output, schema)
+	},
+}
+
+// commandSchemas describes the available subcommands of cmd
+func commandSchemas(cmd *cobra.Command) []commandSchema {
+	var schemas []commandSchema
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+		schemas = append(schemas, commandSchema{
+			Name:     strings.TrimPrefix(sub.CommandPath(), sub.Root().Name()+" "),
+			Usage:    sub.Use,
+			Short:    sub.Short,
+			Long:     sub.Long,
+			Aliases:  sub.Aliases,
+			Runnable: sub.Runnable(),
+			Args:     argSchemas(sub.Use),
+			Flags:    flagSchemas(sub, sub.LocalFlags()),
+			Examples: exampleSchemas(sub),
+			Commands: commandSchemas(sub),
+		})
+	}
+	return schemas
+}
+
+// argSchemas reads the positional arguments from a usage line such as
+// "set <domain> <ns1> <ns2> [ns3...]": <name> is required, [name] optional
+// and a trailing ... repeats
+func argSchemas(use string) []argSchema {
+	fields := strings.Fields(use)
+	var args []argSchema
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		arg := argSchema{Required: strings.HasPrefix(field, "<")}
+		name := strings.Trim(field, "<>[]")
+		if strings.HasSuffix(name, "...") {
+			arg.Variadic = true
+			name = strings.TrimSuffix(name, "...")
+		}
+		if arg.Name = strings.Trim(name, "<>[]"); arg.Name != "" {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// flagSchemas describes the visible flags of a flag set, sorted by name
+func flagSchemas(cmd *cobra.Command, flags *pflag.FlagSet) []flagSchema {
+	var schemas []flagSchema
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" || f.Name == "help" || f.Name == "version" {
+			return
+		}
+		schema := flagSchema{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Usage:      f.Usage,
+			Persistent: cmd.PersistentFlags().Lookup(f.Name) != nil,
+		}
+		if f.DefValue != "" && f.DefValue != "[]" && !(schema.Type == "bool" && f.DefValue == "false") {
+			schema.Default = f.DefValue
+		}
+		if required, ok := f.Annotations[cobra.BashCompOneRequiredFlag]; ok && len(required) > 0 && required[0] == "true" {
+			schema.Required = true
+		}
+		schemas = append(schemas, schema)
+	})
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	return schemas
+}
+
+// exampleSchemas collects the example invocations of a command from its
+// Example field and from the "Examples:" section of its long description
+func exampleSchemas(cmd *cobra.Command) []exampleSchema {
+	lines := strings.Split(cmd.Example, "\n")
+	if _, section, ok := strings.Cut(cmd.Long, "\nExamples:\n"); ok {
+		lines = append(lines, strings.Split(section, "\n")...)
+	}
+
+	prefix := cmd.Root().Name() + " "
+	var examples []exampleSchema
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		argv, err := splitArgs(line)
+		if err != nil {
+			continue
+		}
+		examples = append(examples, exampleSchema{Command: line, Argv: argv})
+	}
+	return examples
+}
+
+// splitArgs splits a command line into arguments the way a POSIX shell would
+// for the quoting used in examples: single quotes, double quotes with
+// backslash escapes and backslashes outside quotes. A line that pipes or
+// redirects ends at the operator.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case (r == '|' || r == '>' || r == '<' || r == '&' || r == ';') && !inArg:
+			return args, nil
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaDumpCmd)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/history"
+	"zonekit/pkg/secret"
+	"zonekit/pkg/server"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a REST API, and optionally a web UI, for the account's domains",
+	Long: `Serve zonekit's REST API over HTTP, so scripts and teammates can read and edit
+the records of the account's domains, or those of any --provider, without the CLI.
+Every API request needs the token given with --token as a bearer token:
+
+  curl -H "Authorization: Bearer $TOKEN" http://localhost:8080/api/domains
+
+  GET    /api/domains                        the served domains
+  GET    /api/domains/{domain}/records       the domain's records
+  POST   /api/domains/{domain}/records       add a record: {"hostname", "type", "value", "ttl", "mx_pref"}
+  DELETE /api/domains/{domain}/records       delete the record in the body
+  GET    /api/domains/{domain}/services      service integrations and whether they are set up
+  GET    /api/domains/{domain}/history       applied changes, newest first
+
+With --ui a small web UI is served at / as well. It lists the domains, their
+records, service setups and change history, and adds and deletes records; it
+asks for the same token. With --read-only the API and the UI cannot change
+records. An account with production_guard is only served writable with
+--production, as the API cannot ask for each change to be confirmed.
+
+The served domains are those given with --domain, or every domain of the
+account. Changes are recorded in the change history like CLI changes.
+
+Examples:
+  zonekit serve --token env:ZONEKIT_TOKEN
+  zonekit serve --ui --read-only --listen 0.0.0.0:8080 --token env:ZONEKIT_TOKEN
+  zonekit serve --ui --provider cloudflare-home --domain home.example.com --token env:ZONEKIT_TOKEN`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, _ := cmd.Flags().GetString("listen")
+		token, _ := cmd.Flags().GetString("token")
+		ui, _ := cmd.Flags().GetBool("ui")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		domains, _ := cmd.Flags().GetStringArray("domain")
+		providerName, _ := cmd.Flags().GetString("provider")
+
+		for _, domainName := range domains {
+			if err := dns.ValidateDomain(domainName); err != nil {
+				return fmt.Errorf("invalid domain %s: %w", domainName, err)
+			}
+		}
+		token, err := secret.Resolve(token)
+		if err != nil {
+			return fmt.Errorf("failed to resolve --token: %w", err)
+		}
+		if token == "" {
+			return fmt.Errorf("--token is required: the API and the UI are only served with token auth")
+		}
+
+		if providerName == "" && !readOnly && !productionConfirmed {
+			if accountConfig, err := GetCurrentAccount(); err == nil && accountConfig.IsGuarded() {
+				return fmt.Errorf("this is a production account: re-run with --production to let the API change its records, or with --read-only")
+			}
+		}
+
+		newService, err := requestDNSService(cmd, args, providerName)
+		if err != nil {
+			return err
+		}
+		if len(domains) == 0 {
+			_, domains, err = resolveAllDomains(cmd, args, false)
+			if err != nil {
+				return fmt.Errorf("failed to list domains, pass them with --domain: %w", err)
+			}
+			if len(domains) == 0 {
+				return fmt.Errorf("there are no domains to serve")
+			}
+		}
+
+		config := server.Config{
+			Token:      token,
+			ReadOnly:   readOnly,
+			UI:         ui,
+			Domains:    domains,
+			NewService: func() server.RecordService { return newService() },
+			Log:        os.Stderr,
+		}
+		// Failing to load them was already reported when the plugins were registered
+		config.Services, _ = loadServiceConfigs()
+		if store, err := openState(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: change history will not be shown: %v\n", err)
+		} else {
+			config.History = history.NewStore(store)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		httpServer := &http.Server{Addr: listen, Handler: server.New(config), ReadHeaderTimeout: 10 * time.Second}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- httpServer.ListenAndServe()
+		}()
+		statusf("Serving the API for %s on http://%s/api\n", strings.Join(domains, ", "), displayAddr(listen))
+		if ui {
+			statusf("Web UI: http://%s/\n", displayAddr(listen))
+		}
+		if readOnly {
+			statusln("Read-only: records cannot be changed")
+		}
+
+		select {
+		case err := <-errCh:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve the API: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return httpServer.Shutdown(shutdownCtx)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("listen", "127.0.0.1:8080", "Address to serve the API on")
+	serveCmd.Flags().String("token", "", "Bearer token required on every request, or a secret reference such as env:ZONEKIT_TOKEN")
+	serveCmd.Flags().Bool("ui", false, "Serve the web UI at /")
+	serveCmd.Flags().Bool("read-only", false, "Reject requests that change records")
+	serveCmd.Flags().StringArray("domain", nil, "Domain to serve (repeatable; default: every domain of the account)")
+}
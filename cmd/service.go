@@ -2,13 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"zonekit/internal/cmdutil"
-	"zonekit/pkg/dns"
 	"zonekit/pkg/plugin"
+	"zonekit/pkg/plugin/service"
 )
 
+// maxPackSize bounds the size of a downloaded template pack
+const maxPackSize = 10 << 20
+
 // serviceCmd represents the service command
 var serviceCmd = &cobra.Command{
 	Use:   "service",
@@ -63,12 +72,17 @@ var serviceInfoCmd = &cobra.Command{
 			return fmt.Errorf("service plugin not found: %w", err)
 		}
 
+		flags := make(map[string]interface{})
+		if params, _ := cmd.Flags().GetStringArray("param"); len(params) > 0 {
+			flags["param"] = params
+		}
+
 		// Create context for info command
 		ctx := &plugin.Context{
 			Domain: "",
 			DNS:    nil,
 			Args:   []string{serviceName},
-			Flags:  make(map[string]interface{}),
+			Flags:  flags,
 			Output: &outputWriter{},
 		}
 
@@ -87,30 +101,25 @@ var serviceInfoCmd = &cobra.Command{
 var serviceSetupCmd = &cobra.Command{
 	Use:   "setup <service-name> <domain>",
 	Short: "Set up DNS records for a service integration",
-	Long:  `Set up all necessary DNS records for a configured service integration.`,
-	Args:  cobra.ExactArgs(2),
+	Long: `Set up all necessary DNS records for a configured service integration.
+
+Templates with several setup modes take parameters, listed by 'service info':
+
+  zonekit service setup microsoft-365 example.com --param autodiscover=srv`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		serviceName := args[0]
 		domainName := args[1]
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
-		}
-
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
-
-		// Create DNS service
-		dnsService := dns.NewService(client)
 
 		// Build flags map
 		flags := make(map[string]interface{})
+		flags["color"] = colorOutput()
 		if cmd.Flags().Changed("dry-run") {
 			val, _ := cmd.Flags().GetBool("dry-run")
 			flags["dry-run"] = val
@@ -119,6 +128,17 @@ var serviceSetupCmd = &cobra.Command{
 			val, _ := cmd.Flags().GetBool("replace")
 			flags["replace"] = val
 		}
+		if params, _ := cmd.Flags().GetStringArray("param"); len(params) > 0 {
+			flags["param"] = params
+		}
+
+		// Replacing existing records is destructive
+		dryRun, _ := flags["dry-run"].(bool)
+		if replace, _ := flags["replace"].(bool); replace && !dryRun {
+			if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "replace existing DNS records"); err != nil {
+				return err
+			}
+		}
 
 		// Get service plugin
 		p, err := plugin.Get("service")
@@ -150,27 +170,20 @@ var serviceSetupCmd = &cobra.Command{
 var serviceVerifyCmd = &cobra.Command{
 	Use:   "verify <service-name> <domain>",
 	Short: "Verify DNS records for a service integration",
-	Long:  `Check if all required DNS records for a service integration are properly configured.`,
-	Args:  cobra.ExactArgs(2),
+	Long: `Check if all required DNS records for a service integration are properly configured.
+
+With --deep, also check that the services behind the records respond: the mail servers
+answer with an SMTP banner on port 25, the MTA-STS policy can be fetched, and www answers HTTP.`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		serviceName := args[0]
 		domainName := args[1]
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
-		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
-		}
-
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
+		// Use the account's provider, or the one selected with --provider
+		dnsService, _, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
-
-		// Create DNS service
-		dnsService := dns.NewService(client)
 
 		// Get service plugin
 		p, err := plugin.Get("service")
@@ -178,12 +191,21 @@ var serviceVerifyCmd = &cobra.Command{
 			return fmt.Errorf("service plugin not found: %w", err)
 		}
 
+		// Build flags map
+		flags := make(map[string]interface{})
+		if deep, _ := cmd.Flags().GetBool("deep"); deep {
+			flags["deep"] = true
+		}
+		if params, _ := cmd.Flags().GetStringArray("param"); len(params) > 0 {
+			flags["param"] = params
+		}
+
 		// Create context
 		ctx := &plugin.Context{
 			Domain: domainName,
 			DNS:    &dnsServiceWrapper{service: dnsService},
 			Args:   []string{serviceName, domainName},
-			Flags:  make(map[string]interface{}),
+			Flags:  flags,
 			Output: &outputWriter{},
 		}
 
@@ -208,21 +230,15 @@ var serviceRemoveCmd = &cobra.Command{
 		serviceName := args[0]
 		domainName := args[1]
 
-		// Get current account configuration
-		accountConfig, err := GetCurrentAccount()
+		// Use the account's provider, or the one selected with --provider
+		dnsService, accountConfig, err := resolveDNSService(cmd, args, domainName, true)
 		if err != nil {
-			return fmt.Errorf("failed to get account configuration: %w", err)
+			return err
 		}
 
-		// Create client and display account info
-		client, err := cmdutil.CreateClient(accountConfig)
-		if err != nil {
+		if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, domainName, "remove service DNS records"); err != nil {
 			return err
 		}
-		cmdutil.DisplayAccountInfo(accountConfig)
-
-		// Create DNS service
-		dnsService := dns.NewService(client)
 
 		// Build flags map
 		flags := make(map[string]interface{})
@@ -230,6 +246,9 @@ var serviceRemoveCmd = &cobra.Command{
 			val, _ := cmd.Flags().GetBool("confirm")
 			flags["confirm"] = val
 		}
+		if params, _ := cmd.Flags().GetStringArray("param"); len(params) > 0 {
+			flags["param"] = params
+		}
 
 		// Get service plugin
 		p, err := plugin.Get("service")
@@ -257,6 +276,160 @@ var serviceRemoveCmd = &cobra.Command{
 	},
 }
 
+// servicePackCmd represents the service pack command
+var servicePackCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Share service templates as packs",
+	Long: `Bundle service templates into a single pack file with metadata and checksums,
+and install packs shared by others, e.g. a team's standard SaaS verification set.`,
+}
+
+// servicePackExportCmd bundles service templates into a pack file
+var servicePackExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export service templates as a pack",
+	Long: `Bundle the installed service templates, or those named with --services, into
+a pack file.
+
+Examples:
+  zonekit service pack export saas.pack.yaml --name saas-verification
+  zonekit service pack export mail.pack.yaml --name mail --services migadu,mailgun --version 1.2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		packFile := args[0]
+
+		servicesDir, _ := cmd.Flags().GetString("dir")
+		if servicesDir == "" {
+			servicesDir = findServicesDirectory()
+		}
+		if servicesDir == "" {
+			return fmt.Errorf("services directory not found")
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(packFile), filepath.Ext(packFile)), ".pack")
+		}
+		description, _ := cmd.Flags().GetString("description")
+		version, _ := cmd.Flags().GetString("version")
+		services, _ := cmd.Flags().GetStringSlice("services")
+
+		pack, err := service.BuildPack(servicesDir, service.PackOptions{
+			Name:        name,
+			Description: description,
+			Version:     version,
+			Services:    services,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build pack: %w", err)
+		}
+
+		data, err := pack.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode pack: %w", err)
+		}
+		if err := os.WriteFile(packFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write pack: %w", err)
+		}
+
+		statusf("Exported %d service template(s) to %s:\n", len(pack.Services), packFile)
+		for _, entry := range pack.Services {
+			statusf("  %s (%s)\n", entry.Name, entry.File)
+		}
+		return nil
+	},
+}
+
+// servicePackInstallCmd installs the service templates of a pack
+var servicePackInstallCmd = &cobra.Command{
+	Use:   "install <file|url>",
+	Short: "Install service templates from a pack",
+	Long: `Install the service templates of a pack file or of a pack downloaded over HTTP(S).
+
+Every template's checksum is verified and every template validated before
+anything is written. Templates that already exist are only replaced with
+--overwrite.
+
+Examples:
+  zonekit service pack install saas.pack.yaml
+  zonekit service pack install https://example.com/packs/saas.pack.yaml --overwrite`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := args[0]
+
+		data, err := readPackSource(source)
+		if err != nil {
+			return err
+		}
+		pack, err := service.ParsePack(data)
+		if err != nil {
+			return fmt.Errorf("invalid pack %s: %w", source, err)
+		}
+
+		servicesDir, _ := cmd.Flags().GetString("dir")
+		if servicesDir == "" {
+			servicesDir = findServicesDirectory()
+		}
+		if servicesDir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("services directory not found: %w", err)
+			}
+			servicesDir = filepath.Join(home, ".zonekit", "services")
+		}
+
+		overwrite, _ := cmd.Flags().GetBool("overwrite")
+		_, err = pack.Install(servicesDir, overwrite)
+		if err != nil {
+			if !overwrite {
+				return fmt.Errorf("failed to install pack: %w (use --overwrite to replace existing templates)", err)
+			}
+			return fmt.Errorf("failed to install pack: %w", err)
+		}
+
+		statusf("Installed pack %s", pack.Name)
+		if pack.Version != "" {
+			statusf(" %s", pack.Version)
+		}
+		statusf(" into %s:\n", servicesDir)
+		for _, entry := range pack.Services {
+			statusf("  %s (%s)\n", entry.Name, entry.File)
+		}
+		return nil
+	},
+}
+
+// readPackSource reads a pack from a file or an HTTP(S) URL
+func readPackSource(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack: %w", err)
+		}
+		return data, nil
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download pack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download pack: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download pack: %w", err)
+	}
+	if len(data) > maxPackSize {
+		return nil, fmt.Errorf("pack is larger than %d bytes", maxPackSize)
+	}
+	return data, nil
+}
+
 func init() {
 	rootCmd.AddCommand(serviceCmd)
 	serviceCmd.AddCommand(serviceListCmd)
@@ -264,9 +437,23 @@ func init() {
 	serviceCmd.AddCommand(serviceSetupCmd)
 	serviceCmd.AddCommand(serviceVerifyCmd)
 	serviceCmd.AddCommand(serviceRemoveCmd)
+	serviceCmd.AddCommand(servicePackCmd)
+	servicePackCmd.AddCommand(servicePackExportCmd)
+	servicePackCmd.AddCommand(servicePackInstallCmd)
 
 	// Flags
 	serviceSetupCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
 	serviceSetupCmd.Flags().Bool("replace", false, "Replace existing records")
+	serviceVerifyCmd.Flags().Bool("deep", false, "Also run functional checks (SMTP banner, MTA-STS policy, HTTP)")
 	serviceRemoveCmd.Flags().BoolP("confirm", "y", false, "Confirm the operation")
+	for _, c := range []*cobra.Command{serviceInfoCmd, serviceSetupCmd, serviceVerifyCmd, serviceRemoveCmd} {
+		c.Flags().StringArray("param", nil, "Set a template parameter (name=value, repeatable); see 'service info'")
+	}
+	servicePackExportCmd.Flags().String("name", "", "Pack name (default: the file name)")
+	servicePackExportCmd.Flags().String("description", "", "Pack description")
+	servicePackExportCmd.Flags().String("version", "", "Pack version")
+	servicePackExportCmd.Flags().StringSlice("services", nil, "Comma-separated services to include (default: all)")
+	servicePackExportCmd.Flags().String("dir", "", "Services directory to export from (default: the templates directory in use)")
+	servicePackInstallCmd.Flags().Bool("overwrite", false, "Replace templates that already exist")
+	servicePackInstallCmd.Flags().String("dir", "", "Services directory to install into (default: the templates directory in use)")
 }
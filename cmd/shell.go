@@ -0,0 +1,496 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"zonekit/pkg/domaincache"
+	"zonekit/pkg/lineedit"
+	"zonekit/pkg/state"
+	"zonekit/pkg/undo"
+)
+
+// shellBuiltins are the commands handled by the shell itself
+var shellBuiltins = []string{"use", "history", "exit", "quit"}
+
+// shellCmd represents the shell command
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Run commands interactively on a domain in use",
+	Long: `Start an interactive shell that runs zonekit commands without the zonekit
+prefix. Select a domain with use, and the dns commands can then be given
+without "dns" and without the domain:
+
+  use example.com
+  list
+  add www A 192.0.2.1
+  delete old CNAME
+
+are run as dns list example.com, dns add example.com www A 192.0.2.1 and so
+on. Other commands are run as typed, e.g. domain info example.com.
+
+Shell commands:
+  use <domain>            work on a domain
+  use --account <name>    use another account for this session
+  use --clear             stop working on a domain
+  use                     show the account and domain in use
+  history                 show earlier command lines
+  exit, quit, Ctrl-D      leave the shell
+
+On a terminal, Tab completes commands, flags and domain names, and the arrow
+keys recall earlier lines; the history is kept in local state. Ctrl-C stops the
+running command (press it again to exit if the command does not stop).
+Changes made in the shell can be reverted with undo and reapplied with redo.
+
+Examples:
+  zonekit shell
+  zonekit shell --account work`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sessionUndo != nil {
+			return fmt.Errorf("already in an interactive session")
+		}
+		session := &shellSession{account: accountName, globals: sessionFlags()}
+		return session.run()
+	},
+}
+
+// shellSession is the state of an interactive shell: the account and domain
+// commands work on and the line editor
+type shellSession struct {
+	account string
+	domain  string
+	// globals are the global flags the shell was started with, e.g. --config,
+	// which apply to every command
+	globals []*pflag.Flag
+	editor  *lineedit.Editor
+}
+
+// sessionFlags returns the global flags set on the command line, apart from
+// --account, which the session keeps itself
+func sessionFlags() []*pflag.Flag {
+	var flags []*pflag.Flag
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed && f.Name != "account" {
+			flags = append(flags, f)
+		}
+	})
+	return flags
+}
+
+// run reads and executes command lines until the input ends or exit
+func (s *shellSession) run() error {
+	s.editor = lineedit.New(os.Stdin, os.Stdout)
+	s.editor.Complete = s.complete
+	s.editor.SetHistory(loadShellHistory())
+
+	sessionUndo = undo.NewStack(undo.DefaultLimit)
+	defer func() {
+		sessionUndo = nil
+		rootCmd.SetArgs(nil)
+	}()
+
+	if s.editor.Terminal() {
+		statusln("zonekit shell: type use <domain> to work on a domain, help for commands, exit to leave")
+	}
+	for {
+		line, err := s.editor.ReadLine(s.prompt())
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s.editor.AddHistory(line)
+		saveShellHistory(s.editor.History())
+
+		args, err := splitArgs(line)
+		if err == nil && len(args) > 0 {
+			var exit bool
+			if exit, err = s.execute(args); exit {
+				return nil
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// prompt shows the account and domain in use, e.g. "work/example.com> "
+func (s *shellSession) prompt() string {
+	context := s.accountName()
+	if context == "" {
+		context = "zonekit"
+	}
+	if s.domain != "" {
+		context += "/" + s.domain
+	}
+	return context + "> "
+}
+
+// accountName returns the account commands run in
+func (s *shellSession) accountName() string {
+	if s.account != "" {
+		return s.account
+	}
+	return GetCurrentAccountName()
+}
+
+// execute runs a builtin or a zonekit command and reports whether the shell
+// should exit
+func (s *shellSession) execute(args []string) (bool, error) {
+	switch args[0] {
+	case "exit", "quit":
+		return true, nil
+	case "use":
+		return false, s.use(args[1:])
+	case "history":
+		for i, line := range s.editor.History() {
+			fmt.Printf("%5d  %s\n", i+1, line)
+		}
+		return false, nil
+	case "shell":
+		return false, fmt.Errorf("already in an interactive session")
+	}
+	// Cobra reports the errors of commands itself
+	_ = executeInShell(s.expand(args))
+	return false, nil
+}
+
+// use sets the account or domain commands work on, or shows them
+func (s *shellSession) use(args []string) error {
+	flags := pflag.NewFlagSet("use", pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	account := flags.String("account", "", "")
+	clear := flags.Bool("clear", false, "")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("use: %w", err)
+	}
+	if flags.NArg() > 1 || (*clear && flags.NArg() > 0) {
+		return fmt.Errorf("usage: use [--account <name>] [<domain>|--clear]")
+	}
+
+	if *account != "" {
+		configManager, err := GetConfigManager()
+		if err != nil {
+			return err
+		}
+		if _, err := configManager.GetAccount(*account); err != nil {
+			return err
+		}
+		// The domain most likely belongs to the previous account
+		s.account, s.domain = *account, ""
+	}
+	if *clear {
+		s.domain = ""
+	}
+	if flags.NArg() == 1 {
+		s.domain = strings.ToLower(strings.TrimSuffix(flags.Arg(0), "."))
+		warnUnknownAccountDomain(s.accountName(), s.domain)
+	}
+
+	if len(args) == 0 {
+		domainName := s.domain
+		if domainName == "" {
+			domainName = "(none)"
+		}
+		fmt.Printf("Account: %s\nDomain:  %s\n", s.accountName(), domainName)
+	}
+	return nil
+}
+
+// expand turns a dns command given without "dns", e.g. "add www A 192.0.2.1",
+// into the full command on the domain in use, and selects the session's
+// account
+func (s *shellSession) expand(args []string) []string {
+	if sub := dnsShortcut(args[0]); sub != nil {
+		full := []string{dnsCmd.Name(), sub.Name()}
+		if s.domain != "" && domainArgPosition(sub.Use) == 0 {
+			full = append(full, s.domain)
+		}
+		args = append(full, args[1:]...)
+	}
+
+	// Flags set on the command line take precedence; flags after "--" are
+	// arguments, so the session's go before it
+	var flags []string
+	for _, f := range s.globals {
+		if !hasFlag(args, f.Name, f.Shorthand) {
+			flags = append(flags, "--"+f.Name+"="+flagValue(f))
+		}
+	}
+	if s.account != "" && !hasFlag(args, "account", "") {
+		flags = append(flags, "--account="+s.account)
+	}
+	end := len(args)
+	for i, arg := range args {
+		if arg == "--" {
+			end = i
+			break
+		}
+	}
+	expanded := append(append([]string{}, args[:end]...), flags...)
+	return append(expanded, args[end:]...)
+}
+
+// hasFlag reports whether args set the flag with the given name or shorthand
+func hasFlag(args []string, name, shorthand string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+		if shorthand != "" && !strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, "-"+shorthand) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value of a flag as it would be given on the command line
+func flagValue(f *pflag.Flag) string {
+	if value, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(value.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
+
+// dnsShortcut returns the dns subcommand a word names, unless a top-level
+// command of that name takes precedence
+func dnsShortcut(name string) *cobra.Command {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return nil
+		}
+	}
+	for _, cmd := range dnsCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// executeInShell runs a command line within the shell. Ctrl-C cancels the
+// command's context instead of ending the process; a second Ctrl-C exits.
+func executeInShell(args []string) error {
+	resetCommandState(rootCmd)
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(interrupts)
+		close(done)
+		cancel()
+	}()
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case <-interrupts:
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	rootCmd.SetArgs(args)
+	return traceCommand(ctx, args, rootCmd.ExecuteContext)
+}
+
+// resetCommandState returns the flags of cmd and its subcommands to their
+// defaults, as cobra keeps flag values in the command tree between executions
+func resetCommandState(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if f.Changed {
+			resetFlag(f)
+			f.Changed = false
+		}
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.SilenceUsage = false
+	for _, sub := range cmd.Commands() {
+		resetCommandState(sub)
+	}
+}
+
+// resetFlag sets a flag's value to its default
+func resetFlag(f *pflag.Flag) {
+	var defaults []string
+	if def := strings.Trim(f.DefValue, "[]"); def != "" {
+		defaults = strings.Split(def, ",")
+	}
+
+	switch value := f.Value.(type) {
+	case pflag.SliceValue:
+		_ = value.Replace(defaults)
+	default:
+		if f.Value.Type() != "stringToString" {
+			_ = f.Value.Set(f.DefValue)
+			return
+		}
+		// Once set, a map flag merges new values into the old ones, so it
+		// gets a new value
+		fresh := pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+		fresh.StringToString(f.Name, nil, "")
+		f.Value = fresh.Lookup(f.Name).Value
+		for _, pair := range defaults {
+			_ = f.Value.Set(pair)
+		}
+	}
+}
+
+// complete returns the completions of the last word of line: shell and
+// zonekit commands for the first word, domain names for use, and otherwise
+// what the command completes, e.g. subcommands, flags and domains
+func (s *shellSession) complete(line string) []string {
+	args, err := splitArgs(line)
+	if err != nil {
+		return nil
+	}
+	word := ""
+	if len(args) > 0 && !strings.HasSuffix(line, " ") {
+		word = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	if len(args) == 0 {
+		return withPrefix(shellWords(), word)
+	}
+	switch args[0] {
+	case "use":
+		if strings.HasPrefix(word, "-") {
+			return withPrefix([]string{"--account", "--clear"}, word)
+		}
+		if len(args) > 1 && args[len(args)-1] == "--account" {
+			configManager, err := GetConfigManager()
+			if err != nil {
+				return nil
+			}
+			return withPrefix(configManager.ListAccounts(), word)
+		}
+		store, err := openState()
+		if err != nil {
+			return nil
+		}
+		return domaincache.New(store).Complete(s.accountName(), word)
+	case "history", "exit", "quit":
+		return nil
+	}
+	return cobraCompletions(append(s.expand(args), word))
+}
+
+// shellWords returns the words a command line can start with
+func shellWords() []string {
+	words := append([]string{}, shellBuiltins...)
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.IsAvailableCommand() && cmd.Name() != "shell" {
+			words = append(words, cmd.Name())
+		}
+	}
+	for _, cmd := range dnsCmd.Commands() {
+		if cmd.IsAvailableCommand() && dnsShortcut(cmd.Name()) != nil {
+			words = append(words, cmd.Name())
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// cobraCompletions asks cobra for the completions of the last argument, as a
+// shell's completion script would
+func cobraCompletions(args []string) []string {
+	var out bytes.Buffer
+	resetCommandState(rootCmd)
+	rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SilenceErrors = true
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SilenceErrors = false
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		// The last line is the completion directive, e.g. ":4"
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		candidate, _, _ := strings.Cut(line, "\t")
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// withPrefix returns the words that start with prefix
+func withPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			matches = append(matches, word)
+		}
+	}
+	return matches
+}
+
+// shellHistoryKey is the key of the shell history in the shell bucket
+const shellHistoryKey = "history"
+
+// loadShellHistory returns the command lines of earlier shells
+func loadShellHistory() []string {
+	store, err := openState()
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	if _, err := store.Get(state.BucketShell, shellHistoryKey, &lines); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read shell history: %v\n", err)
+	}
+	return lines
+}
+
+// saveShellHistory stores the command lines for later shells
+func saveShellHistory(lines []string) {
+	store, err := openState()
+	if err != nil {
+		return
+	}
+	if err := store.Put(state.BucketShell, shellHistoryKey, lines); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save shell history: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
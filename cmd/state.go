@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"zonekit/pkg/history"
+	"zonekit/pkg/render"
+	"zonekit/pkg/state"
+)
+
+var (
+	stateOnce  sync.Once
+	stateStore state.Store
+	stateErr   error
+)
+
+// openState opens the local state store on first use; it stays open until
+// the command finishes
+func openState() (state.Store, error) {
+	stateOnce.Do(func() {
+		stateStore, stateErr = state.Open(state.DefaultDir())
+		if stateErr != nil {
+			stateErr = fmt.Errorf("failed to open local state: %w", stateErr)
+		}
+	})
+	return stateStore, stateErr
+}
+
+// closeState closes the local state store if it was opened. The next
+// openState opens it again, as each command of an interactive shell closes it.
+func closeState() {
+	if stateStore != nil {
+		if err := stateStore.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close local state: %v\n", err)
+		}
+	}
+	stateOnce = sync.Once{}
+	stateStore, stateErr = nil, nil
+}
+
+// stateCmd represents the state command
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and prune local state",
+	Long: `Inspect and prune the state zonekit keeps on this machine: the DNS change
+history, last seen zone versions, the last dynamic DNS addresses,
+the progress of --all-domains runs, the cached domain names used for shell
+completion, the command history of zonekit shell, the comments and tags of
+records at providers that cannot store them and the index of the zone
+snapshots kept under ~/.zonekit/backups.
+
+State is kept in ~/.zonekit/state/state.json, or in the SQLite database
+~/.zonekit/state/state.db when zonekit is built with the sqlite tag
+(go build -tags sqlite, requires cgo). State files of earlier versions are
+imported on first use.`,
+}
+
+// stateInfoCmd represents the state info command
+var stateInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show where local state is kept and what it holds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		store, err := openState()
+		if err != nil {
+			return err
+		}
+		buckets, err := store.Buckets()
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			return writeOutput(output, newBucketTable(buckets), newStateInfoView(store, buckets))
+		}
+
+		fmt.Printf("Backend:  %s\n", store.Backend())
+		fmt.Printf("Location: %s\n", store.Location())
+		if !state.SQLiteAvailable() {
+			fmt.Println("SQLite:   not built in (build with -tags sqlite to enable)")
+		}
+		fmt.Println()
+
+		if len(buckets) == 0 {
+			statusln("No local state recorded yet.")
+			return nil
+		}
+
+		return writeOutput(output, newBucketTable(buckets), nil)
+	},
+}
+
+// newBucketTable builds the table of buckets printed by state info
+func newBucketTable(buckets []state.BucketInfo) *render.Table {
+	table := render.NewTable("BUCKET", "ITEMS", "OLDEST", "NEWEST")
+	times := timeFormatter()
+	for _, b := range buckets {
+		table.AddRow(b.Name, strconv.Itoa(b.Count), times.Timestamp(b.Oldest), times.Timestamp(b.Newest))
+	}
+	return table
+}
+
+// stateListCmd represents the state list command
+var stateListCmd = &cobra.Command{
+	Use:   "list <bucket>",
+	Short: "List the items of a state bucket",
+	Long: `List the keys of a state bucket with the time each was last written.
+Values are included with --values or with --output json/yaml.
+
+Buckets: history, zones, ddns, jobs, domains, shell, notes`,
+	Example: `  zonekit state list zones
+  zonekit state list ddns --values
+  zonekit state list history -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+		showValues, _ := cmd.Flags().GetBool("values")
+
+		store, err := openState()
+		if err != nil {
+			return err
+		}
+		items, err := store.List(args[0])
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			views := make([]stateItemView, 0, len(items))
+			for _, item := range items {
+				views = append(views, stateItemView{Key: item.Key, UpdatedAt: item.UpdatedAt, Value: item.Value})
+			}
+			return writeOutput(output, newItemTable(items, showValues), views)
+		}
+
+		if len(items) == 0 {
+			statusf("No items in bucket %s.\n", args[0])
+			return nil
+		}
+
+		return writeOutput(output, newItemTable(items, showValues), nil)
+	},
+}
+
+// newItemTable builds the table of items printed by state list; values are
+// only included with --values
+func newItemTable(items []state.Item, showValues bool) *render.Table {
+	columns := []string{"KEY", "UPDATED"}
+	if showValues {
+		columns = append(columns, "VALUE")
+	}
+	table := render.NewTable(columns...)
+
+	times := timeFormatter()
+	for _, item := range items {
+		table.AddRow(item.Key, times.Timestamp(item.UpdatedAt), string(item.Value))
+	}
+	return table
+}
+
+// statePruneCmd represents the state prune command
+var statePruneCmd = &cobra.Command{
+	Use:   "prune [bucket]",
+	Short: "Remove old local state",
+	Long: `Remove items last written longer ago than --older-than, from one bucket or
+from all buckets. Pruning history removes old entries from dns changelog.
+
+Record comments and tags (the notes bucket) are not state that goes stale and
+are only pruned when the bucket is named.`,
+	Example: `  zonekit state prune history --older-than 180d
+  zonekit state prune --older-than 365d`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		if olderThan == "" {
+			return fmt.Errorf("--older-than is required (e.g. 90d)")
+		}
+		age, err := history.ParseSince(olderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than value: %w", err)
+		}
+
+		bucket := ""
+		if len(args) > 0 {
+			bucket = args[0]
+		}
+
+		store, err := openState()
+		if err != nil {
+			return err
+		}
+		removed, err := pruneState(store, bucket, time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+
+		scope := "all buckets"
+		if bucket != "" {
+			scope = "bucket " + bucket
+		}
+		statusf("Removed %d item(s) older than %s from %s\n", removed, olderThan, scope)
+		return nil
+	},
+}
+
+// pruneState prunes one bucket, or all buckets but the record notes
+func pruneState(store state.Store, bucket string, before time.Time) (int, error) {
+	if bucket != "" {
+		return store.Prune(bucket, before)
+	}
+	buckets, err := store.Buckets()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, b := range buckets {
+		if b.Name == state.BucketNotes {
+			continue
+		}
+		n, err := store.Prune(b.Name, before)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+func init() {
+	cobra.OnFinalize(closeState)
+
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateInfoCmd)
+	stateCmd.AddCommand(stateListCmd)
+	stateCmd.AddCommand(statePruneCmd)
+
+	stateListCmd.Flags().Bool("values", false, "Show the stored values")
+	statePruneCmd.Flags().String("older-than", "", "Remove items last written longer ago than this (e.g. 90d, 12w)")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"zonekit/pkg/secret"
+	"zonekit/pkg/tracing"
+	"zonekit/pkg/version"
+)
+
+// traceExporter exports the spans of the run when tracing is configured
+var traceExporter *tracing.Exporter
+
+// initTracing starts exporting spans when tracing is set in the config file or
+// through the standard OTEL_* environment variables; the config file wins
+func initTracing() {
+	config := tracing.ConfigFromEnv()
+	if configManager, err := GetConfigManager(); err == nil {
+		if configured := configManager.GetTracing(); configured.Endpoint != "" {
+			headers := make(map[string]string, len(configured.Headers))
+			for name, value := range configured.Headers {
+				resolved, err := secret.Resolve(value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: tracing is disabled: header %s: %v\n", name, err)
+					return
+				}
+				headers[name] = resolved
+			}
+			config = tracing.Config{Endpoint: configured.Endpoint, Headers: headers, ServiceName: configured.ServiceName}
+		}
+	}
+	if !config.Enabled() {
+		return
+	}
+
+	config.ServiceVersion = version.Version
+	exporter, err := tracing.NewExporter(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing is disabled: %v\n", err)
+		return
+	}
+	traceExporter = exporter
+	var warnOnce sync.Once
+	traceExporter.OnError = func(err error) {
+		warnOnce.Do(func() { fmt.Fprintf(os.Stderr, "Warning: %v\n", err) })
+	}
+	tracing.SetExporter(traceExporter)
+}
+
+// traceCommand runs the command line args within a span named after the
+// command, which the spans of provider operations become children of
+func traceCommand(ctx context.Context, args []string, run func(context.Context) error) error {
+	name := rootCmd.Name()
+	if cmd, _, err := rootCmd.Find(args); err == nil {
+		name = cmd.CommandPath()
+	}
+
+	parent := tracing.DefaultParent()
+	ctx, span := tracing.Start(ctx, name)
+	tracing.SetDefaultParent(span)
+	err := run(ctx)
+	span.End(err)
+	tracing.SetDefaultParent(parent)
+	return err
+}
+
+// shutdownTracing exports the spans that are still queued
+func shutdownTracing() {
+	if traceExporter == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := traceExporter.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
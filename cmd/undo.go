@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"zonekit/internal/cmdutil"
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/render"
+	"zonekit/pkg/undo"
+)
+
+// sessionUndo is the undo stack of an interactive session. It is nil when
+// zonekit runs a single command, which leaves nothing to undo within it.
+var sessionUndo *undo.Stack
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last change made in this session",
+	Long: `Revert the most recent change applied in this interactive session (zonekit
+shell) by applying the zone's previous records through the provider. Changes are undone one at a
+time, most recent first, and can be applied again with redo.
+
+If the zone changed since, e.g. by an edit made outside the session, undo stops
+because that later change would be lost as well; --force reverts anyway.
+
+The undo stack is kept in memory for the current session only. It is separate
+from the change history and snapshots on disk (dns changelog, dns undo).
+
+Examples:
+  zonekit undo
+  zonekit undo --list
+  zonekit undo --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sessionUndo == nil {
+			return fmt.Errorf("undo only works within an interactive session (zonekit shell)")
+		}
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			printUndoSteps()
+			return nil
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		return replayStep(sessionUndo.Undo, "undo", "Undid", force)
+	},
+}
+
+// redoCmd represents the redo command
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Apply the last undone change again",
+	Long: `Apply the change most recently reverted with undo again. Making a new change
+discards the changes that could be redone.
+
+If the zone changed since the change was undone, redo stops; --force applies
+the change anyway.
+
+Examples:
+  zonekit redo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sessionUndo == nil {
+			return fmt.Errorf("redo only works within an interactive session (zonekit shell)")
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		return replayStep(sessionUndo.Redo, "redo", "Redid", force)
+	},
+}
+
+// replayStep undoes or redoes a step after the production guard of the
+// account it was made in
+func replayStep(replay func(force bool) (undo.Step, *provider.ApplyResult, error), action, done string, force bool) error {
+	applied, undone := sessionUndo.Steps()
+	next := applied
+	if action == "redo" {
+		next = undone
+	}
+	if len(next) > 0 && next[0].Account != "" {
+		configManager, err := GetConfigManager()
+		if err != nil {
+			return err
+		}
+		if accountConfig, err := configManager.GetAccount(next[0].Account); err == nil {
+			if err := cmdutil.ConfirmProduction(accountConfig, productionConfirmed, next[0].Domain, action+" `"+next[0].Command+"`"); err != nil {
+				return err
+			}
+		}
+	}
+
+	step, result, err := replay(force)
+	if errors.Is(err, undo.ErrChanged) {
+		return fmt.Errorf("%w; run %s --force to %s it anyway", err, action, action)
+	}
+	if result != nil {
+		printApplyResult(result)
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", action, err)
+	}
+	statusf("%s `%s` on %s\n", done, step.Command, step.Domain)
+	return nil
+}
+
+// printUndoSteps prints the steps of the session, newest first
+func printUndoSteps() {
+	done, undone := sessionUndo.Steps()
+	if len(done) == 0 && len(undone) == 0 {
+		statusln("No changes made in this session")
+		return
+	}
+
+	formatter := timeFormatter()
+	table := render.NewTable("STATE", "TIME", "DOMAIN", "COMMAND")
+	for i := len(undone) - 1; i >= 0; i-- {
+		table.AddRow("undone", formatter.Timestamp(undone[i].Time), undone[i].Domain, undone[i].Command)
+	}
+	for _, step := range done {
+		table.AddRow("applied", formatter.Timestamp(step.Time), step.Domain, step.Command)
+	}
+	table.WriteText(os.Stdout)
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+
+	undoCmd.Flags().Bool("force", false, "Revert even if the zone changed since the change was made")
+	undoCmd.Flags().Bool("list", false, "List the changes of this session instead of undoing one")
+	redoCmd.Flags().Bool("force", false, "Apply even if the zone changed since the change was undone")
+	undoCmd.MarkFlagsMutuallyExclusive("force", "list")
+}
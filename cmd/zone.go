@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"zonekit/internal/cmdutil"
+	"zonekit/pkg/dns"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/domain"
+	"zonekit/pkg/render"
+)
+
+// zoneCmd represents the zone command
+var zoneCmd = &cobra.Command{
+	Use:   "zone",
+	Short: "Inspect DNS zones across providers",
+	Long:  `Inspect the DNS zones hosted by the account's provider and by other registered providers.`,
+}
+
+// zoneListCmd represents the zone list command
+var zoneListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the zones served by each provider",
+	Long: `List the zones served by the current account's provider, by the provider
+given with --provider, or with --all-providers by every registered provider
+that can list its zones.
+
+Each zone is matched against the domains registered in the current account, so
+zones hosted for domains registered elsewhere stand out.
+
+Examples:
+  zonekit zone list
+  zonekit zone list --provider cloudflare
+  zonekit zone list --all-providers -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := tableOutputFormat()
+		if err != nil {
+			return err
+		}
+
+		providerName, _ := cmd.Flags().GetString("provider")
+		allProviders, _ := cmd.Flags().GetBool("all-providers")
+
+		// The account is optional when a provider is named, but without it
+		// zones cannot be matched against registered domains
+		var registered map[string]bool
+		var services []*dns.Service
+		accountConfig, accountErr := GetCurrentAccount()
+		if accountErr == nil {
+			dnsService, client, err := accountDNSService(cmd, args, accountConfig)
+			if err != nil {
+				return err
+			}
+			if !output.Structured() {
+				cmdutil.DisplayAccountInfo(accountConfig)
+			}
+
+			// Only Namecheap accounts have registered domains to match
+			if client != nil {
+				domains, err := domain.NewService(client).ListDomains()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to list account domains: %v\n", err)
+				} else {
+					registered = make(map[string]bool, len(domains))
+					for _, d := range domains {
+						registered[strings.ToLower(d.Name)] = true
+					}
+				}
+			}
+
+			if providerName == "" {
+				services = append(services, dnsService)
+			}
+		} else if providerName == "" && !allProviders {
+			return fmt.Errorf("failed to get account configuration: %w", accountErr)
+		}
+
+		switch {
+		case providerName != "":
+			dnsService, err := dns.NewServiceWithProviderName(providerName)
+			if err != nil {
+				return err
+			}
+			services = append(services, dnsService)
+		case allProviders:
+			names := dnsprovider.Names()
+			sort.Strings(names)
+			for _, name := range names {
+				if len(services) > 0 && name == services[0].ProviderName() {
+					continue
+				}
+				p, err := dnsprovider.Get(name)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					continue
+				}
+				// Update-only providers cannot list zones and are left out
+				if _, ok := p.(dnsprovider.ZoneLister); !ok {
+					continue
+				}
+				services = append(services, dns.NewServiceWithProvider(p))
+			}
+		}
+
+		var views []zoneView
+		for _, dnsService := range services {
+			zones, err := dnsService.ListZones()
+			if err != nil {
+				if len(services) == 1 {
+					return fmt.Errorf("failed to list zones: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "Warning: failed to list zones of %s: %v\n", dnsService.ProviderName(), err)
+				continue
+			}
+			for _, zone := range zones {
+				views = append(views, newZoneView(zone, dnsService.ProviderName(), registered))
+			}
+		}
+		sort.SliceStable(views, func(i, j int) bool {
+			if views[i].Zone != views[j].Zone {
+				return views[i].Zone < views[j].Zone
+			}
+			return views[i].Provider < views[j].Provider
+		})
+
+		if output.Structured() {
+			if views == nil {
+				views = []zoneView{}
+			}
+			return writeOutput(output, newZoneTable(views), views)
+		}
+
+		if len(views) == 0 {
+			statusln("No zones found.")
+			return nil
+		}
+
+		return writeOutput(output, newZoneTable(views), nil)
+	},
+}
+
+// newZoneTable builds the table printed by zone list
+func newZoneTable(views []zoneView) *render.Table {
+	table := render.NewTable("ZONE", "PROVIDER", "REGISTERED")
+	for _, v := range views {
+		inAccount := "-"
+		if v.Registered != nil {
+			inAccount = "No"
+			if *v.Registered {
+				inAccount = "Yes"
+			}
+		}
+		table.AddRow(v.Zone, v.Provider, inAccount)
+	}
+	return table
+}
+
+func init() {
+	rootCmd.AddCommand(zoneCmd)
+	zoneCmd.AddCommand(zoneListCmd)
+
+	zoneListCmd.Flags().Bool("all-providers", false, "List the zones of every registered provider")
+	zoneListCmd.MarkFlagsMutuallyExclusive("provider", "all-providers")
+}
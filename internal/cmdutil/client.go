@@ -2,6 +2,7 @@ package cmdutil
 
 import (
 	"fmt"
+	"os"
 
 	"zonekit/pkg/client"
 	"zonekit/pkg/config"
@@ -21,7 +22,8 @@ func CreateClient(accountConfig *config.AccountConfig) (*client.Client, error) {
 	return ncClient, nil
 }
 
-// DisplayAccountInfo displays information about the account being used.
+// DisplayAccountInfo displays information about the account being used. It
+// goes to stderr, so it never mixes with output piped or redirected to a file.
 func DisplayAccountInfo(accountConfig *config.AccountConfig) {
 	if accountConfig == nil {
 		return
@@ -31,6 +33,10 @@ func DisplayAccountInfo(accountConfig *config.AccountConfig) {
 	if description == "" {
 		description = "No description"
 	}
-	fmt.Printf("Using account: %s (%s)\n", accountConfig.Username, description)
-	fmt.Println()
+	if accountConfig.UsesNamecheap() {
+		fmt.Fprintf(os.Stderr, "Using account: %s (%s)\n", accountConfig.Username, description)
+	} else {
+		fmt.Fprintf(os.Stderr, "Using account on %s (%s)\n", accountConfig.GetProvider(), description)
+	}
+	fmt.Fprintln(os.Stderr)
 }
@@ -0,0 +1,32 @@
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"zonekit/pkg/config"
+)
+
+// ConfirmProduction guards a destructive operation on accounts with production_guard enabled.
+// The operation proceeds if the account is unguarded, if production is true (the --production
+// flag), or if the user types the domain name at the prompt. The prompt goes to
+// stderr, so it is seen even when output is redirected.
+func ConfirmProduction(accountConfig *config.AccountConfig, production bool, domainName, action string) error {
+	if accountConfig == nil || !accountConfig.IsGuarded() || production {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  This account is a production account with production_guard enabled.\n")
+	fmt.Fprintf(os.Stderr, "You are about to %s on %s.\n", action, domainName)
+	fmt.Fprintf(os.Stderr, "Type the domain name to confirm (or re-run with --production): ")
+
+	var input string
+	fmt.Scanln(&input)
+	if !strings.EqualFold(strings.TrimSpace(input), domainName) {
+		return fmt.Errorf("confirmation did not match %s, aborting", domainName)
+	}
+
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
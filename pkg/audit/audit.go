@@ -0,0 +1,342 @@
+// Package audit keeps an append-only log of every change zonekit makes: DNS
+// record changes with each record before and after, and registrar changes
+// such as nameserver, contact and glue updates, registrations and renewals,
+// together with who made them, when, and through which account and provider.
+// The log is a JSON Lines file, one entry per change set, that is only ever
+// appended to; entries can also be shipped to a webhook as they are written.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"zonekit/pkg/history"
+)
+
+// OperationDNS is the operation of entries holding DNS record changes
+const OperationDNS = "dns"
+
+// Entry is one change set. DNS record changes are listed in Changes; other
+// operations describe the state before and after in Before and After.
+type Entry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Account  string    `json:"account,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Domain   string    `json:"domain"`
+	Command  string    `json:"command,omitempty"`
+	// Emergency marks emergency changes, which are allowed outside change windows
+	Emergency bool `json:"emergency,omitempty"`
+	// Operation is what was changed, e.g. dns, nameservers or renew
+	Operation string           `json:"operation"`
+	Changes   []history.Change `json:"changes,omitempty"`
+	Before    string           `json:"before,omitempty"`
+	After     string           `json:"after,omitempty"`
+	// Error is set when the operation failed
+	Error string `json:"error,omitempty"`
+}
+
+// Failed returns the number of changes that failed; an operation without
+// record changes counts as one
+func (e Entry) Failed() int {
+	if len(e.Changes) == 0 {
+		if e.Error != "" {
+			return 1
+		}
+		return 0
+	}
+	n := 0
+	for _, c := range e.Changes {
+		if c.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Log is an append-only JSON Lines file of entries
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns ~/.zonekit/audit.jsonl, or "" if the home directory is unknown
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".zonekit", "audit.jsonl")
+}
+
+// NewLog creates a log written to path
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the file the log is written to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes an entry as one line at the end of the log
+func (l *Log) Append(entry Entry) error {
+	if l.path == "" {
+		return fmt.Errorf("audit log path is unknown")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// A single write keeps concurrent writers from interleaving lines
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Filter selects entries; zero fields match all entries
+type Filter struct {
+	Domain  string
+	Account string
+	Since   time.Time
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if f.Domain != "" && !strings.EqualFold(entry.Domain, strings.TrimSuffix(f.Domain, ".")) {
+		return false
+	}
+	if f.Account != "" && !strings.EqualFold(entry.Account, f.Account) {
+		return false
+	}
+	return f.Since.IsZero() || !entry.Time.Before(f.Since)
+}
+
+// Read returns the entries matching filter, oldest first. A missing log has no
+// entries.
+func (l *Log) Read(filter Filter) ([]Entry, error) {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit entry on line %d of %s: %w", line, l.path, err)
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Find returns the entry with id, or the only entry whose ID starts with it
+func (l *Log) Find(id string) (Entry, error) {
+	entries, err := l.Read(Filter{})
+	if err != nil {
+		return Entry{}, err
+	}
+	var found []Entry
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+		if strings.HasPrefix(entry.ID, id) {
+			found = append(found, entry)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Entry{}, fmt.Errorf("audit entry %s not found", id)
+	case 1:
+		return found[0], nil
+	default:
+		return Entry{}, fmt.Errorf("audit entry ID %s is ambiguous, it matches %d entries", id, len(found))
+	}
+}
+
+// Webhook ships entries to an HTTP endpoint as they are written
+type Webhook struct {
+	URL string
+	// Headers are sent with each request, e.g. an Authorization header
+	Headers map[string]string
+	// Client is the HTTP client used; nil uses a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Post sends an entry as JSON and expects a 2xx answer
+func (w *Webhook) Post(ctx context.Context, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.Headers {
+		req.Header.Set(name, value)
+	}
+
+	httpClient := w.Client
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Logger writes entries to a Log, tagging them with who ran which command,
+// and ships them to a webhook. It receives the DNS changes recorded in the
+// change history through history.Auditor.
+type Logger struct {
+	Log     *Log
+	Account string
+	User    string
+	Host    string
+	Command string
+	// Emergency marks the logged changes as emergency changes
+	Emergency bool
+	// Webhook, if set, receives each entry after it is written
+	Webhook *Webhook
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewLogger creates a logger for the current OS user and host
+func NewLogger(log *Log, account, command string) *Logger {
+	host, _ := os.Hostname()
+	return &Logger{
+		Log:     log,
+		Account: account,
+		User:    currentUser(),
+		Host:    host,
+		Command: command,
+		now:     time.Now,
+	}
+}
+
+// Record writes an entry, filling in its ID, time and the logger's user, host,
+// account and command where they are unset
+func (l *Logger) Record(entry Entry) error {
+	if entry.ID == "" {
+		entry.ID = newID()
+	}
+	if entry.Time.IsZero() {
+		now := time.Now
+		if l.now != nil {
+			now = l.now
+		}
+		entry.Time = now().UTC()
+	}
+	if entry.User == "" {
+		entry.User = l.User
+	}
+	if entry.Host == "" {
+		entry.Host = l.Host
+	}
+	if entry.Account == "" {
+		entry.Account = l.Account
+	}
+	if entry.Command == "" {
+		entry.Command = l.Command
+	}
+	entry.Emergency = entry.Emergency || l.Emergency
+	entry.Domain = strings.ToLower(strings.TrimSuffix(entry.Domain, "."))
+
+	if err := l.Log.Append(entry); err != nil {
+		return err
+	}
+	if l.Webhook != nil {
+		if err := l.Webhook.Post(context.Background(), entry); err != nil {
+			return fmt.Errorf("audit entry %s was logged but not shipped: %w", entry.ID, err)
+		}
+	}
+	return nil
+}
+
+// AuditChanges logs the DNS record changes of a change history entry under the
+// same ID
+func (l *Logger) AuditChanges(entry history.Entry) error {
+	return l.Record(Entry{
+		ID:        entry.ID,
+		Time:      entry.Time,
+		User:      entry.User,
+		Host:      entry.Host,
+		Account:   entry.Account,
+		Provider:  entry.Provider,
+		Domain:    entry.Domain,
+		Command:   entry.Command,
+		Emergency: entry.Emergency,
+		Operation: OperationDNS,
+		Changes:   entry.Changes,
+	})
+}
+
+// newID returns a random entry ID
+func newID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%012x", time.Now().UnixNano()&0xffffffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// currentUser returns the OS user name, or an empty string if unknown
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
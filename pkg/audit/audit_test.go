@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/history"
+)
+
+// AuditTestSuite tests the audit log and its logger
+type AuditTestSuite struct {
+	suite.Suite
+	log *Log
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+func (s *AuditTestSuite) SetupTest() {
+	s.log = NewLog(filepath.Join(s.T().TempDir(), "audit", "audit.jsonl"))
+}
+
+func (s *AuditTestSuite) loggerAt(t time.Time) *Logger {
+	return &Logger{Log: s.log, Account: "prod", User: "alice", Host: "ops1", Command: "zonekit domain renew example.com", now: func() time.Time { return t }}
+}
+
+func (s *AuditTestSuite) TestLogger_AuditsHistoryEntries() {
+	existing := []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800}}
+	desired := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.2", TTL: 1800},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.3", TTL: 300},
+	}
+	result := provider.PlanResults("example.com", existing, desired)
+	result.Fail(1, errors.New("provider error"))
+
+	recorder := history.NewRecorder(nil, "prod", "zonekit dns bulk example.com ops.yaml")
+	recorder.Audit = s.loggerAt(time.Now())
+	s.Require().NoError(recorder.Record("Example.com.", "namecheap", result))
+
+	entries, err := s.log.Read(Filter{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+
+	entry := entries[0]
+	s.Require().Len(entry.ID, 12)
+	s.Require().Equal(OperationDNS, entry.Operation)
+	s.Require().Equal("example.com", entry.Domain)
+	s.Require().Equal("namecheap", entry.Provider)
+	s.Require().Equal("zonekit dns bulk example.com ops.yaml", entry.Command)
+	s.Require().Len(entry.Changes, 2)
+	s.Require().Equal("192.0.2.1", entry.Changes[0].Before.Value)
+	s.Require().Equal("192.0.2.2", entry.Changes[0].After.Value)
+	s.Require().Equal("provider error", entry.Changes[1].Error)
+	s.Require().Equal(1, entry.Failed())
+}
+
+func (s *AuditTestSuite) TestLogger_RecordsOperations() {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	logger := s.loggerAt(now)
+	s.Require().NoError(logger.Record(Entry{Domain: "Example.com.", Provider: "namecheap", Operation: "renew", After: "renewed for 1 year"}))
+	s.Require().NoError(logger.Record(Entry{Domain: "example.com", Operation: "nameservers", Before: "dns1.registrar-servers.com", After: "ns1.example.net", Error: "registry timeout"}))
+
+	entries, err := s.log.Read(Filter{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+
+	renew := entries[0]
+	s.Require().Len(renew.ID, 12)
+	s.Require().True(renew.Time.Equal(now))
+	s.Require().Equal("example.com", renew.Domain)
+	s.Require().Equal("alice", renew.User)
+	s.Require().Equal("ops1", renew.Host)
+	s.Require().Equal("prod", renew.Account)
+	s.Require().Equal("zonekit domain renew example.com", renew.Command)
+	s.Require().Zero(renew.Failed())
+
+	s.Require().Equal("dns1.registrar-servers.com", entries[1].Before)
+	s.Require().Equal(1, entries[1].Failed())
+}
+
+func (s *AuditTestSuite) TestLog_AppendsAndFilters() {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	s.Require().NoError(s.loggerAt(old).Record(Entry{Domain: "example.com", Operation: "renew"}))
+	s.Require().NoError(s.loggerAt(recent).Record(Entry{Domain: "example.org", Operation: "renew"}))
+	staging := s.loggerAt(recent)
+	staging.Account = "staging"
+	s.Require().NoError(staging.Record(Entry{Domain: "example.com", Operation: "renew"}))
+
+	// Earlier lines are never rewritten
+	data, err := os.ReadFile(s.log.Path())
+	s.Require().NoError(err)
+	s.Require().Equal(3, strings.Count(string(data), "\n"), "one line per entry")
+	s.Require().NoError(s.loggerAt(recent).Record(Entry{Domain: "example.net", Operation: "renew"}))
+	appended, err := os.ReadFile(s.log.Path())
+	s.Require().NoError(err)
+	s.Require().True(strings.HasPrefix(string(appended), string(data)))
+
+	entries, err := s.log.Read(Filter{Domain: "example.com"})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+	s.Require().True(entries[0].Time.Equal(old), "oldest first")
+
+	entries, err = s.log.Read(Filter{Since: recent, Account: "prod"})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+	s.Require().Equal("example.org", entries[0].Domain)
+
+	found, err := s.log.Find(entries[0].ID[:6])
+	s.Require().NoError(err)
+	s.Require().Equal(entries[0].ID, found.ID)
+	_, err = s.log.Find("nonexistent")
+	s.Require().ErrorContains(err, "not found")
+
+	none, err := NewLog(filepath.Join(s.T().TempDir(), "missing.jsonl")).Read(Filter{})
+	s.Require().NoError(err)
+	s.Require().Empty(none)
+}
+
+func (s *AuditTestSuite) TestLogger_ShipsToWebhook() {
+	var shipped []Entry
+	var authorization string
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		var entry Entry
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&entry))
+		shipped = append(shipped, entry)
+	}))
+	defer server.Close()
+
+	logger := s.loggerAt(time.Now())
+	logger.Emergency = true
+	logger.Webhook = &Webhook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}
+	s.Require().NoError(logger.Record(Entry{Domain: "example.com", Operation: "renew"}))
+
+	s.Require().Len(shipped, 1)
+	s.Require().True(shipped[0].Emergency)
+	s.Require().Equal("Bearer secret", authorization)
+
+	// A failing webhook is reported, but the entry is still logged
+	failing = true
+	s.Require().ErrorContains(logger.Record(Entry{Domain: "example.com", Operation: "renew"}), "not shipped")
+	entries, err := s.log.Read(Filter{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+}
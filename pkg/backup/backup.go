@@ -0,0 +1,332 @@
+// Package backup keeps timestamped snapshots of zones, so a zone can be put
+// back as it was before a change. Each snapshot is a JSON file under
+// <dir>/<domain>/<id>.json holding the zone's records as a desired-state file
+// (see zonestate) with the time, provider, account and reason it was taken, so
+// it can also be applied with dns sync.
+//
+// A store given an index with SetIndex also keeps the metadata of each
+// snapshot in the state store's backups bucket, so listing snapshots does not
+// read every file. The files stay authoritative: snapshots missing from the
+// index, like those taken by earlier versions, are indexed when listed, and
+// entries whose file is gone are dropped.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+	"zonekit/pkg/zonestate"
+)
+
+// idLayout formats the time a snapshot was taken as its ID
+const idLayout = "20060102T150405Z"
+
+// DefaultKeep is the number of automatic snapshots kept per domain by default
+const DefaultKeep = 50
+
+// Snapshot is a saved copy of the records of a zone
+type Snapshot struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Provider string    `json:"provider,omitempty"`
+	Account  string    `json:"account,omitempty"`
+	// Reason says why the snapshot was taken, e.g. the command it was taken before
+	Reason string `json:"reason,omitempty"`
+	// Automatic is set on snapshots taken before a change, as opposed to on demand
+	Automatic bool `json:"automatic,omitempty"`
+	// Count is the number of records in the snapshot; snapshots returned by
+	// List carry it without their records
+	Count int `json:"-"`
+	zonestate.File
+}
+
+// indexEntry is the metadata of a snapshot kept in the index
+type indexEntry struct {
+	ID        string    `json:"id"`
+	Domain    string    `json:"domain"`
+	Time      time.Time `json:"time"`
+	Provider  string    `json:"provider,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Automatic bool      `json:"automatic,omitempty"`
+	Records   int       `json:"records"`
+}
+
+// Store keeps snapshots in a directory, one subdirectory per domain
+type Store struct {
+	dir   string
+	now   func() time.Time
+	index state.Store
+}
+
+// DefaultDir returns ~/.zonekit/backups, or "" if the home directory is unknown
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".zonekit", "backups")
+}
+
+// NewStore creates a store keeping snapshots in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// SetIndex makes the store index its snapshots in index. Failing to update
+// the index is not an error, as it is rebuilt from the files when listed.
+func (s *Store) SetIndex(index state.Store) {
+	s.index = index
+}
+
+// Save writes a snapshot of records, the records of domainName. The snapshot's
+// Time and ID are set from the current time; its other fields are kept.
+func (s *Store) Save(domainName string, records []dnsrecord.Record, snapshot Snapshot) (Snapshot, error) {
+	if s.dir == "" {
+		return Snapshot{}, fmt.Errorf("backup directory is unknown")
+	}
+	domainDir := filepath.Join(s.dir, normalizeDomain(domainName))
+	if err := os.MkdirAll(domainDir, 0700); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	snapshot.Time = s.now().UTC().Truncate(time.Second)
+	snapshot.File = zonestate.FromRecords(normalizeDomain(domainName), records)
+	snapshot.Count = len(records)
+
+	// Snapshots taken within the same second get a counter
+	base := snapshot.Time.Format(idLayout)
+	for n := 1; ; n++ {
+		snapshot.ID = base
+		if n > 1 {
+			snapshot.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		file, err := os.OpenFile(filepath.Join(domainDir, snapshot.ID+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		_, err = file.Write(append(data, '\n'))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		s.indexSnapshot(snapshot)
+		return snapshot, nil
+	}
+}
+
+// List returns the snapshots of domainName, or of every domain when it is
+// empty, newest first. The snapshots carry their Count but not their records;
+// see Load.
+func (s *Store) List(domainName string) ([]Snapshot, error) {
+	if s.dir == "" {
+		return nil, fmt.Errorf("backup directory is unknown")
+	}
+	pattern := filepath.Join(s.dir, "*", "*.json")
+	if domainName != "" {
+		pattern = filepath.Join(s.dir, normalizeDomain(domainName), "*.json")
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	indexed := s.indexed(domainName)
+
+	snapshots := make([]Snapshot, 0, len(paths))
+	for _, path := range paths {
+		key := indexKey(filepath.Base(filepath.Dir(path)), strings.TrimSuffix(filepath.Base(path), ".json"))
+		if entry, ok := indexed[key]; ok {
+			delete(indexed, key)
+			snapshots = append(snapshots, entry.snapshot())
+			continue
+		}
+		snapshot, err := readSnapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		s.indexSnapshot(snapshot)
+		snapshot.Records = nil
+		snapshots = append(snapshots, snapshot)
+	}
+	// The files of the entries left were deleted
+	for key := range indexed {
+		_ = s.index.Delete(state.BucketBackups, key)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].Time.Equal(snapshots[j].Time) {
+			return snapshots[i].Time.After(snapshots[j].Time)
+		}
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// Load returns the snapshot of domainName with id, "latest" for the newest
+// one, and its records. id may also be the path of a snapshot file.
+func (s *Store) Load(domainName, id string) (Snapshot, []dnsrecord.Record, error) {
+	path := id
+	switch {
+	case id == "latest":
+		snapshots, err := s.List(domainName)
+		if err != nil {
+			return Snapshot{}, nil, err
+		}
+		if len(snapshots) == 0 {
+			return Snapshot{}, nil, fmt.Errorf("there are no snapshots of %s", domainName)
+		}
+		path = s.Path(domainName, snapshots[0].ID)
+	case !strings.ContainsAny(id, `/\`) && !strings.HasSuffix(id, ".json"):
+		path = s.Path(domainName, id)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Snapshot{}, nil, fmt.Errorf("snapshot %s of %s not found (see dns backups list)", id, domainName)
+	}
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	records, err := zonestate.Parse(data, domainName)
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	snapshot.Count = len(records)
+	return snapshot, records, nil
+}
+
+// Prune deletes the oldest automatic snapshots of domainName beyond the newest
+// keep. Snapshots taken on demand are kept.
+func (s *Store) Prune(domainName string, keep int) error {
+	snapshots, err := s.List(domainName)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, snapshot := range snapshots {
+		if !snapshot.Automatic {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.Remove(s.Path(domainName, snapshot.ID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+		if s.index != nil {
+			_ = s.index.Delete(state.BucketBackups, indexKey(normalizeDomain(domainName), snapshot.ID))
+		}
+	}
+	return nil
+}
+
+// Path returns the file of the snapshot of domainName with id
+func (s *Store) Path(domainName, id string) string {
+	return filepath.Join(s.dir, normalizeDomain(domainName), id+".json")
+}
+
+// readSnapshot reads the snapshot file at path
+func readSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	snapshot.Count = len(snapshot.Records)
+	return snapshot, nil
+}
+
+// indexSnapshot writes the index entry of snapshot
+func (s *Store) indexSnapshot(snapshot Snapshot) {
+	if s.index == nil {
+		return
+	}
+	entry := indexEntry{
+		ID:        snapshot.ID,
+		Domain:    snapshot.Domain,
+		Time:      snapshot.Time,
+		Provider:  snapshot.Provider,
+		Account:   snapshot.Account,
+		Reason:    snapshot.Reason,
+		Automatic: snapshot.Automatic,
+		Records:   snapshot.Count,
+	}
+	_ = s.index.Put(state.BucketBackups, indexKey(snapshot.Domain, snapshot.ID), entry)
+}
+
+// indexed returns the index entries of domainName, or of every domain when it
+// is empty, by key. Unreadable entries are left out, so their snapshots are
+// indexed again.
+func (s *Store) indexed(domainName string) map[string]indexEntry {
+	entries := map[string]indexEntry{}
+	if s.index == nil {
+		return entries
+	}
+	items, err := s.index.List(state.BucketBackups)
+	if err != nil {
+		return entries
+	}
+	prefix := ""
+	if domainName != "" {
+		prefix = normalizeDomain(domainName) + "/"
+	}
+	for _, item := range items {
+		if !strings.HasPrefix(item.Key, prefix) {
+			continue
+		}
+		var entry indexEntry
+		if err := json.Unmarshal(item.Value, &entry); err != nil {
+			continue
+		}
+		entries[item.Key] = entry
+	}
+	return entries
+}
+
+// snapshot returns the snapshot an index entry describes, without its records
+func (e indexEntry) snapshot() Snapshot {
+	return Snapshot{
+		ID:        e.ID,
+		Time:      e.Time,
+		Provider:  e.Provider,
+		Account:   e.Account,
+		Reason:    e.Reason,
+		Automatic: e.Automatic,
+		Count:     e.Records,
+		File:      zonestate.File{Domain: e.Domain},
+	}
+}
+
+// indexKey returns the index key of the snapshot of a domain with id
+func indexKey(domainName, id string) string {
+	return domainName + "/" + id
+}
+
+// normalizeDomain returns the directory name of a domain
+func normalizeDomain(domainName string) string {
+	return strings.ToLower(strings.TrimSuffix(domainName, "."))
+}
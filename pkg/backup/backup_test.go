@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+	"zonekit/pkg/zonestate"
+)
+
+// BackupTestSuite tests the snapshot store
+type BackupTestSuite struct {
+	suite.Suite
+	dir   string
+	store *Store
+	now   time.Time
+}
+
+func TestBackupSuite(t *testing.T) {
+	suite.Run(t, new(BackupTestSuite))
+}
+
+func (s *BackupTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.store = NewStore(s.dir)
+	s.store.now = func() time.Time { return s.now }
+}
+
+var records = []dnsrecord.Record{
+	{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+	{HostName: "@", RecordType: "MX", Address: "mx.example.com.", TTL: 3600, MXPref: 10},
+	{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 1800},
+}
+
+func (s *BackupTestSuite) TestSaveAndLoad() {
+	saved, err := s.store.Save("Example.com.", records, Snapshot{Provider: "namecheap", Account: "work", Reason: "dns clear"})
+	s.Require().NoError(err)
+	s.Require().Equal("20240301T120000Z", saved.ID)
+	s.Require().FileExists(filepath.Join(s.dir, "example.com", "20240301T120000Z.json"))
+
+	snapshot, loaded, err := s.store.Load("example.com", saved.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(records, loaded)
+	s.Require().Equal("dns clear", snapshot.Reason)
+	s.Require().Equal("work", snapshot.Account)
+	s.Require().True(snapshot.Time.Equal(s.now))
+
+	// A snapshot is a desired-state file as well
+	stateRecords, err := zonestate.ParseFile(filepath.Join(s.dir, "example.com", saved.ID+".json"), "example.com")
+	s.Require().NoError(err)
+	s.Require().Equal(records, stateRecords)
+
+	_, _, err = s.store.Load("example.com", "20240101T000000Z")
+	s.Require().ErrorContains(err, "not found")
+	_, _, err = s.store.Load("example.org", "latest")
+	s.Require().ErrorContains(err, "no snapshots")
+}
+
+func (s *BackupTestSuite) TestSameSecondGetsCounter() {
+	first, err := s.store.Save("example.com", records, Snapshot{})
+	s.Require().NoError(err)
+	second, err := s.store.Save("example.com", records[:1], Snapshot{})
+	s.Require().NoError(err)
+	s.Require().Equal(first.ID+"-2", second.ID)
+
+	// Latest is the last one taken
+	_, loaded, err := s.store.Load("example.com", "latest")
+	s.Require().NoError(err)
+	s.Require().Len(loaded, 1)
+}
+
+func (s *BackupTestSuite) TestList() {
+	for i, domainName := range []string{"example.com", "example.org", "example.com"} {
+		s.now = s.now.Add(time.Duration(i) * time.Hour)
+		_, err := s.store.Save(domainName, records, Snapshot{})
+		s.Require().NoError(err)
+	}
+
+	snapshots, err := s.store.List("example.com")
+	s.Require().NoError(err)
+	s.Require().Len(snapshots, 2)
+	s.Require().True(snapshots[0].Time.After(snapshots[1].Time), "newest first")
+	s.Require().Equal(3, snapshots[0].Count)
+
+	all, err := s.store.List("")
+	s.Require().NoError(err)
+	s.Require().Len(all, 3)
+
+	none, err := NewStore(filepath.Join(s.dir, "missing")).List("")
+	s.Require().NoError(err)
+	s.Require().Empty(none)
+}
+
+func (s *BackupTestSuite) TestPrune() {
+	manual, err := s.store.Save("example.com", records, Snapshot{})
+	s.Require().NoError(err)
+	var automatic []Snapshot
+	for i := 0; i < 3; i++ {
+		s.now = s.now.Add(time.Minute)
+		snapshot, err := s.store.Save("example.com", records, Snapshot{Automatic: true})
+		s.Require().NoError(err)
+		automatic = append(automatic, snapshot)
+	}
+
+	s.Require().NoError(s.store.Prune("example.com", 2))
+
+	snapshots, err := s.store.List("example.com")
+	s.Require().NoError(err)
+	ids := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		ids = append(ids, snapshot.ID)
+	}
+	s.Require().Equal([]string{automatic[2].ID, automatic[1].ID, manual.ID}, ids)
+}
+
+func (s *BackupTestSuite) TestLoadPath() {
+	saved, err := s.store.Save("example.com", records, Snapshot{})
+	s.Require().NoError(err)
+	path := filepath.Join(s.T().TempDir(), "copy.json")
+	data, err := os.ReadFile(filepath.Join(s.dir, "example.com", saved.ID+".json"))
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(path, data, 0600))
+
+	_, loaded, err := s.store.Load("example.com", path)
+	s.Require().NoError(err)
+	s.Require().Equal(records, loaded)
+
+	// A snapshot of another domain is refused
+	_, _, err = s.store.Load("example.org", path)
+	s.Require().ErrorContains(err, "not example.org")
+}
+
+func (s *BackupTestSuite) TestIndex() {
+	index, err := state.Open(s.T().TempDir())
+	s.Require().NoError(err)
+	defer index.Close()
+
+	// A snapshot taken without an index is indexed when listed
+	old, err := s.store.Save("example.com", records, Snapshot{Reason: "before"})
+	s.Require().NoError(err)
+	s.store.SetIndex(index)
+	s.now = s.now.Add(time.Minute)
+	saved, err := s.store.Save("example.com", records, Snapshot{Automatic: true, Reason: "dns clear"})
+	s.Require().NoError(err)
+
+	snapshots, err := s.store.List("example.com")
+	s.Require().NoError(err)
+	s.Require().Len(snapshots, 2)
+	items, err := index.List(state.BucketBackups)
+	s.Require().NoError(err)
+	s.Require().Len(items, 2)
+	s.Require().Equal("example.com/"+old.ID, items[0].Key)
+
+	// Listing reads the index, not the files
+	var entry indexEntry
+	found, err := index.Get(state.BucketBackups, "example.com/"+saved.ID, &entry)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	entry.Reason = "from the index"
+	s.Require().NoError(index.Put(state.BucketBackups, "example.com/"+saved.ID, entry))
+	snapshots, err = s.store.List("example.com")
+	s.Require().NoError(err)
+	s.Require().Equal("from the index", snapshots[0].Reason)
+	s.Require().Equal(3, snapshots[0].Count)
+	s.Require().Equal("example.com", snapshots[0].Domain)
+	s.Require().True(snapshots[0].Automatic)
+
+	// Entries whose file is gone are dropped
+	s.Require().NoError(os.Remove(s.store.Path("example.com", old.ID)))
+	snapshots, err = s.store.List("")
+	s.Require().NoError(err)
+	s.Require().Len(snapshots, 1)
+	items, err = index.List(state.BucketBackups)
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+
+	// Pruned snapshots leave the index
+	s.Require().NoError(s.store.Prune("example.com", 0))
+	items, err = index.List(state.BucketBackups)
+	s.Require().NoError(err)
+	s.Require().Empty(items)
+}
@@ -0,0 +1,253 @@
+// Package challenge serves ACME DNS-01 challenge records over HTTP using the
+// protocol of lego's httpreq DNS provider, so reverse proxies that obtain
+// certificates with lego, such as Traefik, can create and clean up their TXT
+// records through any zonekit provider.
+//
+// A client POSTs {"fqdn": "_acme-challenge.example.com.", "value": "..."} to
+// /present before validation and the same body to /cleanup afterwards. In
+// lego's raw mode the body is {"domain": "example.com", "token": "...",
+// "keyAuth": "..."} and the record value is derived from the key authorization.
+package challenge
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// challengeLabel is the label ACME DNS-01 challenge records are published under
+const challengeLabel = "_acme-challenge"
+
+// maxBodySize bounds request bodies, which hold a name and a short value
+const maxBodySize = 64 << 10
+
+// RecordStore reads and replaces the records of a zone
+type RecordStore interface {
+	GetRecords(domainName string) ([]dnsrecord.Record, error)
+	SetRecords(domainName string, records []dnsrecord.Record) error
+}
+
+// Config describes the zones a Handler serves challenges for
+type Config struct {
+	// Zones are the zones challenge records may be written to; a name is
+	// written to the longest zone it belongs to
+	Zones []string
+	// NewStore returns the record store for one request. A new store per request
+	// keeps per-invocation record caches from serving stale zones.
+	NewStore func() RecordStore
+	// TTL is the TTL of challenge records; zero means the provider default
+	TTL int
+
+	// Username and Password, when set, are required as HTTP basic auth, matching
+	// lego's HTTPREQ_USERNAME and HTTPREQ_PASSWORD
+	Username string
+	Password string
+
+	// Log receives a line per served request; nil discards them
+	Log io.Writer
+}
+
+// Handler serves the /present and /cleanup endpoints
+type Handler struct {
+	config Config
+	zones  []string
+
+	// mu serializes writes, as each replaces a zone's records
+	mu sync.Mutex
+}
+
+// New creates a handler
+func New(config Config) *Handler {
+	if config.Log == nil {
+		config.Log = io.Discard
+	}
+	zones := make([]string, 0, len(config.Zones))
+	for _, zone := range config.Zones {
+		zones = append(zones, normalizeName(zone))
+	}
+	// Longest first, so the most specific zone wins
+	sort.Slice(zones, func(i, j int) bool { return len(zones[i]) > len(zones[j]) })
+	return &Handler{config: config, zones: zones}
+}
+
+// request is the body of a present or cleanup request in either mode
+type request struct {
+	FQDN  string `json:"fqdn"`
+	Value string `json:"value"`
+
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
+}
+
+// ServeHTTP handles POST /present and POST /cleanup
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(r.URL.Path, "/")
+	if action != "present" && action != "cleanup" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="zonekit"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body request
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&body); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	fqdn, value, err := challengeRecord(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, _, err := h.split(fqdn); err != nil {
+		fmt.Fprintf(h.config.Log, "%s %s: %v\n", action, fqdn, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if action == "present" {
+		err = h.Present(fqdn, value)
+	} else {
+		err = h.Cleanup(fqdn, value)
+	}
+	if err != nil {
+		fmt.Fprintf(h.config.Log, "%s %s: %v\n", action, fqdn, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	fmt.Fprintf(h.config.Log, "%s %s\n", action, fqdn)
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized checks the basic auth credentials when they are configured
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.config.Username == "" && h.config.Password == "" {
+		return true
+	}
+	username, password, ok := r.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(h.config.Username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.config.Password)) == 1
+}
+
+// challengeRecord returns the name and value of the TXT record a request is about
+func challengeRecord(body request) (fqdn, value string, err error) {
+	if body.FQDN != "" {
+		if body.Value == "" {
+			return "", "", fmt.Errorf("value is required")
+		}
+		return normalizeName(body.FQDN), body.Value, nil
+	}
+	if body.Domain == "" || body.KeyAuth == "" {
+		return "", "", fmt.Errorf("fqdn and value, or domain and keyAuth, are required")
+	}
+	// The DNS-01 record holds the base64url SHA-256 digest of the key authorization
+	digest := sha256.Sum256([]byte(body.KeyAuth))
+	domain := strings.TrimPrefix(normalizeName(body.Domain), "*.")
+	return challengeLabel + "." + domain, base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+// Present publishes a TXT record with value at fqdn. Other values at the same
+// name are kept, as a certificate for a domain and its wildcard needs two.
+func (h *Handler) Present(fqdn, value string) error {
+	zone, host, err := h.split(fqdn)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	store := h.config.NewStore()
+	records, err := store.GetRecords(zone)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS records of %s: %w", zone, err)
+	}
+	for _, r := range records {
+		if isChallenge(r, host, value) {
+			return nil
+		}
+	}
+	records = append(records, dnsrecord.Record{HostName: host, RecordType: dnsrecord.RecordTypeTXT, Address: value, TTL: h.config.TTL})
+	if err := store.SetRecords(zone, records); err != nil {
+		return fmt.Errorf("failed to add TXT record %s: %w", fqdn, err)
+	}
+	return nil
+}
+
+// Cleanup removes the TXT record with value at fqdn; other values are kept
+func (h *Handler) Cleanup(fqdn, value string) error {
+	zone, host, err := h.split(fqdn)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	store := h.config.NewStore()
+	records, err := store.GetRecords(zone)
+	if err != nil {
+		return fmt.Errorf("failed to get DNS records of %s: %w", zone, err)
+	}
+	kept := make([]dnsrecord.Record, 0, len(records))
+	for _, r := range records {
+		if !isChallenge(r, host, value) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(records) {
+		return nil
+	}
+	if err := store.SetRecords(zone, kept); err != nil {
+		return fmt.Errorf("failed to delete TXT record %s: %w", fqdn, err)
+	}
+	return nil
+}
+
+// split returns the zone of fqdn and the host name within it. Only challenge
+// names are accepted, so the endpoint cannot be used to write other records.
+func (h *Handler) split(fqdn string) (zone, host string, err error) {
+	name := normalizeName(fqdn)
+	if name != challengeLabel && !strings.HasPrefix(name, challengeLabel+".") {
+		return "", "", fmt.Errorf("%s is not an ACME challenge name", name)
+	}
+	for _, zone := range h.zones {
+		if name == zone {
+			return zone, "@", nil
+		}
+		if strings.HasSuffix(name, "."+zone) {
+			return zone, strings.TrimSuffix(name, "."+zone), nil
+		}
+	}
+	return "", "", fmt.Errorf("%s is not in a served zone", name)
+}
+
+// isChallenge reports whether r is the TXT record with value at host
+func isChallenge(r dnsrecord.Record, host, value string) bool {
+	return strings.EqualFold(r.RecordType, dnsrecord.RecordTypeTXT) &&
+		strings.EqualFold(r.HostName, host) && r.Address == value
+}
+
+// normalizeName lower-cases a name and drops its trailing dot
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
@@ -0,0 +1,104 @@
+package challenge
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// fakeStore holds the records of its zones and counts writes
+type fakeStore struct {
+	zones  map[string][]dnsrecord.Record
+	writes int
+}
+
+func (f *fakeStore) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	return append([]dnsrecord.Record(nil), f.zones[domainName]...), nil
+}
+
+func (f *fakeStore) SetRecords(domainName string, records []dnsrecord.Record) error {
+	f.writes++
+	f.zones[domainName] = records
+	return nil
+}
+
+// ChallengeTestSuite tests the httpreq challenge endpoints
+type ChallengeTestSuite struct {
+	suite.Suite
+	store   *fakeStore
+	handler *Handler
+}
+
+func TestChallengeSuite(t *testing.T) {
+	suite.Run(t, new(ChallengeTestSuite))
+}
+
+func (s *ChallengeTestSuite) SetupTest() {
+	s.store = &fakeStore{zones: map[string][]dnsrecord.Record{
+		"example.com":     {{HostName: "@", RecordType: "A", Address: "192.0.2.1"}},
+		"lab.example.com": {},
+	}}
+	s.handler = New(Config{
+		Zones:    []string{"example.com", "Lab.Example.com."},
+		NewStore: func() RecordStore { return s.store },
+		Username: "traefik",
+		Password: "secret",
+	})
+}
+
+func (s *ChallengeTestSuite) post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.SetBasicAuth("traefik", "secret")
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *ChallengeTestSuite) TestPresentAndCleanup() {
+	rec := s.post("/present", `{"fqdn":"_acme-challenge.example.com.","value":"token-1"}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+	// A wildcard certificate needs a second value at the same name
+	rec = s.post("/present", `{"fqdn":"_acme-challenge.example.com.","value":"token-2"}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+	rec = s.post("/present", `{"fqdn":"_acme-challenge.example.com.","value":"token-2"}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Require().Equal(2, s.store.writes)
+	s.Require().Len(s.store.zones["example.com"], 3)
+
+	rec = s.post("/cleanup", `{"fqdn":"_acme-challenge.example.com.","value":"token-1"}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Require().Equal([]dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "_acme-challenge", RecordType: "TXT", Address: "token-2"},
+	}, s.store.zones["example.com"])
+}
+
+func (s *ChallengeTestSuite) TestPresent_RawModeAndLongestZone() {
+	keyAuth := "token.thumbprint"
+	rec := s.post("/present", `{"domain":"*.home.lab.example.com","token":"token","keyAuth":"`+keyAuth+`"}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+
+	digest := sha256.Sum256([]byte(keyAuth))
+	s.Require().Equal([]dnsrecord.Record{
+		{HostName: "_acme-challenge.home", RecordType: "TXT", Address: base64.RawURLEncoding.EncodeToString(digest[:])},
+	}, s.store.zones["lab.example.com"])
+}
+
+func (s *ChallengeTestSuite) TestRejectedRequests() {
+	req := httptest.NewRequest(http.MethodPost, "/present", strings.NewReader(`{"fqdn":"_acme-challenge.example.com","value":"v"}`))
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	s.Require().Equal(http.StatusUnauthorized, rec.Code)
+
+	s.Require().Equal(http.StatusForbidden, s.post("/present", `{"fqdn":"www.example.com","value":"v"}`).Code)
+	s.Require().Equal(http.StatusForbidden, s.post("/present", `{"fqdn":"_acme-challenge.example.org","value":"v"}`).Code)
+	s.Require().Equal(http.StatusBadRequest, s.post("/present", `{"fqdn":"_acme-challenge.example.com"}`).Code)
+	s.Require().Equal(http.StatusNotFound, s.post("/records", `{}`).Code)
+	s.Require().Equal(0, s.store.writes)
+}
@@ -0,0 +1,254 @@
+// Package changewindow restricts DNS changes to maintenance windows, e.g.
+// "production zones: changes only Mon–Thu 09:00–17:00 UTC". Windows are
+// configured per domain or account; a change to a domain that windows apply to
+// is allowed only while one of them is open, unless it is an emergency change.
+package changewindow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// Window is a recurring period in which changes are allowed
+type Window struct {
+	// Name describes the window in messages, e.g. "prod zones"
+	Name string `yaml:"name,omitempty" mapstructure:"name,omitempty"`
+	// Domains are globs of the domains the window applies to; empty is all
+	Domains []string `yaml:"domains,omitempty" mapstructure:"domains,omitempty"`
+	// Accounts are the accounts the window applies to; empty is all
+	Accounts []string `yaml:"accounts,omitempty" mapstructure:"accounts,omitempty"`
+	// Days are the days the window opens, e.g. "mon-thu" or "mon,wed,fri";
+	// empty is every day
+	Days string `yaml:"days,omitempty" mapstructure:"days,omitempty"`
+	// Start and End are the times of day the window opens and closes, e.g.
+	// "09:00" and "17:00". A window whose end is before its start closes on the
+	// next day.
+	Start string `yaml:"start" mapstructure:"start"`
+	End   string `yaml:"end" mapstructure:"end"`
+	// Timezone is an IANA time zone name; the default is UTC
+	Timezone string `yaml:"timezone,omitempty" mapstructure:"timezone,omitempty"`
+}
+
+// dayNames are the weekday abbreviations of Days, starting on Sunday like time.Weekday
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Validate checks the days, times and time zone of the window
+func (w Window) Validate() error {
+	if _, err := w.days(); err != nil {
+		return err
+	}
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("start and end are both %s", w.Start)
+	}
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	for _, glob := range w.Domains {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid domain pattern %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// AppliesTo reports whether the window governs changes to domainName made in
+// account
+func (w Window) AppliesTo(domainName, account string) bool {
+	domainName = strings.ToLower(strings.TrimSuffix(domainName, "."))
+	if len(w.Domains) > 0 {
+		matched := false
+		for _, glob := range w.Domains {
+			if ok, _ := path.Match(strings.ToLower(glob), domainName); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(w.Accounts) > 0 {
+		for _, a := range w.Accounts {
+			if strings.EqualFold(a, account) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Open reports whether the window is open at t
+func (w Window) Open(t time.Time) (bool, error) {
+	if err := w.Validate(); err != nil {
+		return false, err
+	}
+	days, _ := w.days()
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+	location, _ := w.location()
+
+	t = t.In(location)
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	today := t.Weekday()
+	if start < end {
+		return days[today] && now >= start && now < end, nil
+	}
+	// The window spans midnight: it is open after the start on one of its days
+	// and before the end on the following day
+	yesterday := (today + 6) % 7
+	return (days[today] && now >= start) || (days[yesterday] && now < end), nil
+}
+
+// String describes the window, e.g. "prod zones: mon-thu 09:00-17:00 UTC"
+func (w Window) String() string {
+	days := w.Days
+	if days == "" {
+		days = "daily"
+	}
+	timezone := w.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	s := fmt.Sprintf("%s %s-%s %s", days, w.Start, w.End, timezone)
+	if w.Name != "" {
+		s = w.Name + ": " + s
+	}
+	return s
+}
+
+// days returns the weekdays the window opens on
+func (w Window) days() ([7]bool, error) {
+	var days [7]bool
+	if strings.TrimSpace(w.Days) == "" {
+		for i := range days {
+			days[i] = true
+		}
+		return days, nil
+	}
+	for _, part := range strings.Split(w.Days, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := parseDay(from)
+		if err != nil {
+			return days, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseDay(to); err != nil {
+				return days, err
+			}
+		}
+		// Ranges may wrap around the week, e.g. fri-mon
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// location returns the window's time zone
+func (w Window) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+	}
+	return location, nil
+}
+
+// parseDay parses a weekday name such as "mon" or "Monday"
+func parseDay(value string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	for i, day := range dayNames {
+		if name == day || name == strings.ToLower(time.Weekday(i).String()) {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q (use mon, tue, wed, thu, fri, sat or sun)", value)
+}
+
+// parseClock parses a time of day such as "09:00" or "17:30"
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 09:00", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ClosedError rejects a change made while the windows of its domain are closed
+type ClosedError struct {
+	Domain  string
+	Windows []Window
+}
+
+func (e *ClosedError) Error() string {
+	names := make([]string, 0, len(e.Windows))
+	for _, w := range e.Windows {
+		names = append(names, w.String())
+	}
+	return fmt.Sprintf("changes to %s are only allowed in its change window (%s); use --emergency to apply the change anyway",
+		e.Domain, strings.Join(names, "; "))
+}
+
+// Checker checks changes against the change windows; it implements the
+// service's policy hook
+type Checker struct {
+	windows   []Window
+	account   string
+	emergency bool
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewChecker creates a checker for changes made in account. Emergency changes
+// are allowed outside the windows with a warning.
+func NewChecker(windows []Window, account string, emergency bool) *Checker {
+	return &Checker{windows: windows, account: account, emergency: emergency, now: time.Now}
+}
+
+// Check rejects a change to domainName unless a window that applies to it is
+// open, or no window applies. An invalid window rejects every change to the
+// domains it applies to rather than letting them through unchecked.
+func (c *Checker) Check(domainName string, before, after []dnsrecord.Record) ([]string, error) {
+	now := c.now()
+	var closed []Window
+	for _, w := range c.windows {
+		if !w.AppliesTo(domainName, c.account) {
+			continue
+		}
+		open, err := w.Open(now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change window %s: %w", w, err)
+		}
+		if open {
+			return nil, nil
+		}
+		closed = append(closed, w)
+	}
+	if len(closed) == 0 {
+		return nil, nil
+	}
+
+	if !c.emergency {
+		return nil, &ClosedError{Domain: domainName, Windows: closed}
+	}
+	return []string{fmt.Sprintf("%s is outside its change window; applying as an emergency change", domainName)}, nil
+}
@@ -0,0 +1,139 @@
+package changewindow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ChangeWindowTestSuite tests change windows and the checker
+type ChangeWindowTestSuite struct {
+	suite.Suite
+}
+
+func TestChangeWindowSuite(t *testing.T) {
+	suite.Run(t, new(ChangeWindowTestSuite))
+}
+
+// at returns a time in March 2024, when the 4th is a Monday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 3, day, hour, minute, 0, 0, time.UTC)
+}
+
+func (s *ChangeWindowTestSuite) TestOpen() {
+	w := Window{Days: "mon-thu", Start: "09:00", End: "17:00"}
+	for _, tc := range []struct {
+		time time.Time
+		open bool
+	}{
+		{at(4, 9, 0), true},
+		{at(7, 16, 59), true},
+		{at(7, 17, 0), false},
+		{at(4, 8, 59), false},
+		{at(8, 12, 0), false}, // Friday
+		{at(10, 12, 0), false},
+	} {
+		open, err := w.Open(tc.time)
+		s.Require().NoError(err)
+		s.Require().Equal(tc.open, open, tc.time.String())
+	}
+}
+
+func (s *ChangeWindowTestSuite) TestOpen_SpansMidnight() {
+	// Friday and Saturday nights, until 06:00 the next morning
+	w := Window{Days: "fri,sat", Start: "22:00", End: "06:00"}
+	for _, tc := range []struct {
+		time time.Time
+		open bool
+	}{
+		{at(8, 23, 0), true},
+		{at(9, 5, 59), true},
+		{at(10, 3, 0), true}, // Sunday morning, after Saturday night
+		{at(10, 23, 0), false},
+		{at(8, 5, 0), false}, // Friday morning, after Thursday night
+	} {
+		open, err := w.Open(tc.time)
+		s.Require().NoError(err)
+		s.Require().Equal(tc.open, open, tc.time.String())
+	}
+}
+
+func (s *ChangeWindowTestSuite) TestOpen_Timezone() {
+	w := Window{Days: "mon-fri", Start: "09:00", End: "17:00", Timezone: "America/New_York"}
+	open, err := w.Open(at(4, 15, 0)) // 10:00 in New York
+	s.Require().NoError(err)
+	s.Require().True(open)
+	open, err = w.Open(at(4, 10, 0)) // 05:00 in New York
+	s.Require().NoError(err)
+	s.Require().False(open)
+}
+
+func (s *ChangeWindowTestSuite) TestDays() {
+	days, err := Window{Days: "fri-mon, Wednesday"}.days()
+	s.Require().NoError(err)
+	s.Require().Equal([7]bool{true, true, false, true, false, true, true}, days)
+}
+
+func (s *ChangeWindowTestSuite) TestValidate() {
+	for name, w := range map[string]Window{
+		"day":      {Days: "someday", Start: "09:00", End: "17:00"},
+		"start":    {Start: "9am", End: "17:00"},
+		"end":      {Start: "09:00", End: "25:00"},
+		"empty":    {Start: "09:00", End: "09:00"},
+		"timezone": {Start: "09:00", End: "17:00", Timezone: "Mars/Olympus"},
+		"domain":   {Start: "09:00", End: "17:00", Domains: []string{"[example.com"}},
+	} {
+		s.Require().Error(w.Validate(), name)
+	}
+	s.Require().NoError(Window{Days: "mon-thu", Start: "09:00", End: "17:00", Timezone: "Europe/Berlin"}.Validate())
+}
+
+func (s *ChangeWindowTestSuite) TestAppliesTo() {
+	w := Window{Domains: []string{"*.example.com", "example.com"}, Accounts: []string{"prod"}}
+	s.Require().True(w.AppliesTo("Example.com.", "prod"))
+	s.Require().True(w.AppliesTo("shop.example.com", "PROD"))
+	s.Require().False(w.AppliesTo("example.org", "prod"))
+	s.Require().False(w.AppliesTo("example.com", "staging"))
+	s.Require().True(Window{}.AppliesTo("example.org", ""))
+}
+
+func (s *ChangeWindowTestSuite) TestChecker() {
+	windows := []Window{
+		{Name: "prod zones", Accounts: []string{"prod"}, Days: "mon-thu", Start: "09:00", End: "17:00"},
+		{Name: "friday morning", Accounts: []string{"prod"}, Days: "fri", Start: "09:00", End: "12:00"},
+	}
+	checker := NewChecker(windows, "prod", false)
+
+	checker.now = func() time.Time { return at(8, 10, 0) }
+	_, err := checker.Check("example.com", nil, nil)
+	s.Require().NoError(err, "open in the second window")
+
+	checker.now = func() time.Time { return at(8, 13, 0) }
+	_, err = checker.Check("example.com", nil, nil)
+	var closed *ClosedError
+	s.Require().True(errors.As(err, &closed))
+	s.Require().Len(closed.Windows, 2)
+	s.Require().Contains(err.Error(), "prod zones: mon-thu 09:00-17:00 UTC")
+	s.Require().Contains(err.Error(), "--emergency")
+
+	// Other accounts have no window
+	other := NewChecker(windows, "staging", false)
+	other.now = checker.now
+	_, err = other.Check("example.com", nil, nil)
+	s.Require().NoError(err)
+
+	// Emergency changes go through with a warning
+	emergency := NewChecker(windows, "prod", true)
+	emergency.now = checker.now
+	warnings, err := emergency.Check("example.com", nil, nil)
+	s.Require().NoError(err)
+	s.Require().Len(warnings, 1)
+	s.Require().Contains(warnings[0], "emergency change")
+
+	// An invalid window blocks changes
+	broken := NewChecker([]Window{{Start: "soon", End: "17:00"}}, "prod", true)
+	_, err = broken.Check("example.com", nil, nil)
+	s.Require().ErrorContains(err, "invalid change window")
+}
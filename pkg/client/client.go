@@ -2,15 +2,18 @@ package client
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"zonekit/pkg/config"
+	"zonekit/pkg/ratelimit"
 )
 
 // Client wraps the Namecheap SDK client with additional functionality
 type Client struct {
-	nc     *namecheap.Client
-	config *config.AccountConfig
+	nc      *namecheap.Client
+	config  *config.AccountConfig
+	limiter *ratelimit.Limiter
 }
 
 // NewClient creates a new Namecheap client with the given configuration
@@ -20,6 +23,12 @@ func NewClient(accountConfig *config.AccountConfig) (*Client, error) {
 		return nil, fmt.Errorf("invalid configuration: missing required fields")
 	}
 
+	// Resolve references to external secret managers
+	accountConfig, err := accountConfig.ResolveSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	nc := namecheap.NewClient(&namecheap.ClientOptions{
 		UserName:   accountConfig.Username,
 		ApiUser:    accountConfig.APIUser,
@@ -31,6 +40,8 @@ func NewClient(accountConfig *config.AccountConfig) (*Client, error) {
 	return &Client{
 		nc:     nc,
 		config: accountConfig,
+		// Namecheap limits API calls per API user, across all of its clients
+		limiter: ratelimit.For("namecheap:"+strings.ToLower(accountConfig.APIUser), "namecheap"),
 	}, nil
 }
 
@@ -54,7 +65,8 @@ func NewClientForAccount(configManager *config.Manager, accountName string) (*Cl
 	return NewClient(accountConfig)
 }
 
-// GetNamecheapClient returns the underlying Namecheap SDK client
+// GetNamecheapClient returns the underlying Namecheap SDK client. Each call
+// through it must begin with BeginCall, which paces the calls.
 func (c *Client) GetNamecheapClient() *namecheap.Client {
 	return c.nc
 }
@@ -4,8 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/suite"
-	"zonekit/internal/testutil"
 	"zonekit/pkg/config"
+	"zonekit/pkg/testutil"
 )
 
 // ClientTestSuite is a test suite for client package
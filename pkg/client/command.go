@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"zonekit/pkg/tracing"
+)
+
+// apiEnvelope is the outer structure shared by every Namecheap XML response
+type apiEnvelope struct {
+	XMLName         xml.Name   `xml:"ApiResponse"`
+	Status          string     `xml:"Status,attr"`
+	Errors          []APIError `xml:"Errors>Error"`
+	CommandResponse struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"CommandResponse"`
+}
+
+// APIError is a single error entry returned by the Namecheap API
+type APIError struct {
+	Message string `xml:",chardata"`
+	Number  string `xml:"Number,attr"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Number)
+}
+
+// Call executes a raw Namecheap API command that the SDK does not cover.
+// The CommandResponse element is decoded into result, so result fields are tagged
+// relative to it (e.g. `xml:"DomainGetInfoResult"`). result may be nil when the
+// caller only cares about success.
+func (c *Client) Call(command string, params map[string]string, result interface{}) error {
+	raw, err := c.CallRaw(command, params)
+	if err != nil {
+		return err
+	}
+	if result == nil || raw == nil {
+		return nil
+	}
+
+	if err := xml.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("unable to parse %s response: %w", command, err)
+	}
+	return nil
+}
+
+// CallRaw executes a Namecheap API command like Call and returns its
+// CommandResponse element untouched, or nil if the response has none
+func (c *Client) CallRaw(command string, params map[string]string) (raw RawXML, err error) {
+	domainName := params["DomainName"]
+	if domainName == "" && params["SLD"] != "" {
+		domainName = params["SLD"] + "." + params["TLD"]
+	}
+	span := c.BeginCall(command, domainName)
+	defer func() { span.End(err) }()
+
+	body := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		body[k] = v
+	}
+	body["Command"] = command
+
+	var envelope apiEnvelope
+	if _, err = c.nc.DoXML(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	if len(envelope.Errors) > 0 {
+		apiErr := envelope.Errors[0]
+		return nil, TranslateError(&apiErr)
+	}
+
+	if len(envelope.CommandResponse.Inner) == 0 {
+		return nil, nil
+	}
+
+	inner := append([]byte("<CommandResponse>"), envelope.CommandResponse.Inner...)
+	inner = append(inner, "</CommandResponse>"...)
+	return RawXML(inner), nil
+}
+
+// BeginCall waits until the account's rate limit allows another API call and
+// starts the call's trace span, on a domain unless domainName is empty. Every
+// call to the Namecheap SDK must begin with it; end the span with the call's
+// error.
+func (c *Client) BeginCall(command, domainName string) *tracing.Span {
+	_ = c.limiter.Wait(context.Background())
+
+	attributes := []tracing.Attribute{
+		tracing.String(tracing.AttrOperation, command),
+		tracing.String(tracing.AttrProvider, "namecheap"),
+	}
+	if domainName != "" {
+		attributes = append(attributes, tracing.String(tracing.AttrDomain, strings.ToLower(domainName)))
+	}
+	_, span := tracing.StartClient(context.Background(), command, attributes...)
+	return span
+}
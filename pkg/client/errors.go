@@ -0,0 +1,125 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// NamecheapError is a Namecheap API error enriched with a plain-language
+// explanation and a remediation hint
+type NamecheapError struct {
+	Code    string
+	Message string // raw message returned by the API
+	Summary string
+	Hint    string
+}
+
+func (e *NamecheapError) Error() string {
+	msg := fmt.Sprintf("%s (error %s)", e.Summary, e.Code)
+	if e.Hint != "" {
+		msg += "\nHint: " + e.Hint
+	}
+	return msg
+}
+
+// errorTranslation describes how a known Namecheap error code is presented
+type errorTranslation struct {
+	Summary string
+	Hint    string
+}
+
+// knownErrors maps Namecheap error numbers to friendly messages.
+// See https://www.namecheap.com/support/api/error-codes/
+var knownErrors = map[string]errorTranslation{
+	"1010101": {
+		Summary: "API user is missing or invalid",
+		Hint:    "check api_user with 'zonekit account show' and fix it with 'zonekit account edit'",
+	},
+	"1011102": {
+		Summary: "API key is invalid or the client IP is not whitelisted",
+		Hint:    "verify api_key and whitelist client_ip under Profile > Tools > API Access in the Namecheap dashboard",
+	},
+	"1011150": {
+		Summary: "request IP is not whitelisted for API access",
+		Hint:    "add your public IP under Profile > Tools > API Access and update client_ip with 'zonekit account edit'",
+	},
+	"1017105": {
+		Summary: "client IP is disabled or locked",
+		Hint:    "re-enable the IP under Profile > Tools > API Access in the Namecheap dashboard",
+	},
+	"1017101": {
+		Summary: "API access is disabled for this user",
+		Hint:    "enable API access under Profile > Tools > API Access in the Namecheap dashboard",
+	},
+	"1016103": {
+		Summary: "username is not authorized for API access",
+		Hint:    "check that username and api_user refer to the same Namecheap account",
+	},
+	"2019166": {
+		Summary: "domain is not associated with this account",
+		Hint:    "check the domain spelling or select the owning account with --account",
+	},
+	"2016166": {
+		Summary: "domain is not associated with this account",
+		Hint:    "check the domain spelling or select the owning account with --account",
+	},
+	"2030166": {
+		Summary: "domain name is invalid",
+		Hint:    "use the registered domain (e.g. example.com) rather than a subdomain",
+	},
+	"2030280": {
+		Summary: "insufficient account balance for this operation",
+		Hint:    "add funds to your Namecheap account balance and retry",
+	},
+	"2528166": {
+		Summary: "insufficient account balance for this operation",
+		Hint:    "add funds to your Namecheap account balance and retry",
+	},
+	"4011103": {
+		Summary: "domain is not available for registration",
+		Hint:    "pick another name; 'zonekit domain check <name> --popular' suggests alternatives",
+	},
+	"500000": {
+		Summary: "too many requests to the Namecheap API",
+		Hint:    "wait a minute before retrying; the API allows a limited number of calls per minute",
+	},
+}
+
+// sdkErrorPattern matches errors formatted by the SDK as "<message> (<number>)"
+var sdkErrorPattern = regexp.MustCompile(`^(.*) \((\d+)\)$`)
+
+// TranslateError converts raw Namecheap API errors into a *NamecheapError with an
+// actionable message. Errors with unknown codes or non-API errors are returned unchanged.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var translated *NamecheapError
+	if errors.As(err, &translated) {
+		return err
+	}
+
+	var code, message string
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.Number, apiErr.Message
+	} else if m := sdkErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		code, message = m[2], m[1]
+	} else {
+		return err
+	}
+
+	t, ok := knownErrors[code]
+	if !ok {
+		return err
+	}
+
+	return &NamecheapError{
+		Code:    code,
+		Message: message,
+		Summary: t.Summary,
+		Hint:    t.Hint,
+	}
+}
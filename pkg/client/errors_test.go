@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ErrorsTestSuite is a test suite for Namecheap error translation
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+// TestErrorsSuite runs the error translation test suite
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_Nil() {
+	s.Require().Nil(TranslateError(nil))
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_SDKFormattedError() {
+	err := TranslateError(fmt.Errorf("Domain name not found (2019166)"))
+
+	var ncErr *NamecheapError
+	s.Require().ErrorAs(err, &ncErr)
+	s.Require().Equal("2019166", ncErr.Code)
+	s.Require().Equal("Domain name not found", ncErr.Message)
+	s.Require().Contains(err.Error(), "not associated with this account")
+	s.Require().Contains(err.Error(), "--account")
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_APIError() {
+	err := TranslateError(&APIError{Message: "Insufficient funds", Number: "2030280"})
+
+	var ncErr *NamecheapError
+	s.Require().ErrorAs(err, &ncErr)
+	s.Require().Contains(err.Error(), "insufficient account balance")
+	s.Require().Contains(err.Error(), "Hint:")
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_UnknownCodeUnchanged() {
+	original := fmt.Errorf("Something odd (9999999)")
+	s.Require().Equal(original, TranslateError(original))
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_NonAPIErrorUnchanged() {
+	original := fmt.Errorf("connection refused")
+	s.Require().Equal(original, TranslateError(original))
+}
+
+func (s *ErrorsTestSuite) TestTranslateError_AlreadyTranslated() {
+	first := TranslateError(fmt.Errorf("Invalid request IP (1011150)"))
+	s.Require().Equal(first, TranslateError(first))
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RawTestSuite is a test suite for raw API responses
+type RawTestSuite struct {
+	suite.Suite
+}
+
+// TestRawSuite runs the raw response test suite
+func TestRawSuite(t *testing.T) {
+	suite.Run(t, new(RawTestSuite))
+}
+
+func (s *RawTestSuite) TestMarshalJSON_Tree() {
+	raw := RawXML(`<CommandResponse Type="namecheap.domains.getInfo">
+  <DomainGetInfoResult Status="Ok" DomainName="example.com">
+    <DomainDetails>
+      <CreatedDate>02/15/2016</CreatedDate>
+    </DomainDetails>
+    <DnsDetails ProviderType="FREE">
+      <Nameserver>dns1.registrar-servers.com</Nameserver>
+      <Nameserver>dns2.registrar-servers.com</Nameserver>
+    </DnsDetails>
+    <Modificationrights All="true">full</Modificationrights>
+  </DomainGetInfoResult>
+</CommandResponse>`)
+
+	data, err := json.Marshal(raw)
+	s.Require().NoError(err)
+	s.Require().JSONEq(`{"CommandResponse": {
+		"Type": "namecheap.domains.getInfo",
+		"DomainGetInfoResult": {
+			"Status": "Ok",
+			"DomainName": "example.com",
+			"DomainDetails": {"CreatedDate": "02/15/2016"},
+			"DnsDetails": {"ProviderType": "FREE", "Nameserver": ["dns1.registrar-servers.com", "dns2.registrar-servers.com"]},
+			"Modificationrights": {"All": "true", "#text": "full"}
+		}
+	}}`, string(data))
+}
+
+func (s *RawTestSuite) TestMarshalJSON_EmptyAndInvalid() {
+	data, err := json.Marshal(struct {
+		Raw RawXML `json:"raw"`
+	}{})
+	s.Require().NoError(err)
+	s.Require().JSONEq(`{"raw": null}`, string(data))
+
+	_, err = json.Marshal(RawXML(`<Unclosed>`))
+	s.Require().Error(err)
+}
@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"zonekit/pkg/changewindow"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/ratelimit"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,12 +24,67 @@ type AccountConfig struct {
 	ClientIP    string `yaml:"client_ip" mapstructure:"client_ip"`
 	UseSandbox  bool   `yaml:"use_sandbox" mapstructure:"use_sandbox"`
 	Description string `yaml:"description" mapstructure:"description"`
+	// ProductionGuard requires explicit confirmation for destructive operations
+	// when the account is not using the sandbox environment
+	ProductionGuard bool `yaml:"production_guard,omitempty" mapstructure:"production_guard,omitempty"`
+}
+
+// ProviderConfig configures a named DNS provider instance. Several instances of
+// the same provider type can be configured with different credentials.
+type ProviderConfig struct {
+	// Type is the provider type, e.g. "cloudflare" or "alidns"
+	Type string `yaml:"type" mapstructure:"type"`
+	// Credentials are passed to the provider; the keys depend on the type. Values
+	// may be secret references such as "env:CF_TOKEN" or "vault:secret/dns#token".
+	Credentials map[string]string `yaml:"credentials,omitempty" mapstructure:"credentials,omitempty"`
+	Description string            `yaml:"description,omitempty" mapstructure:"description,omitempty"`
+
+	// The fields below declare the API of a provider of type "rest", which is
+	// built by the generic REST adapter instead of a built-in provider type.
+	// Auth is the authentication method, e.g. "bearer" or "api_key", and
+	// Credentials hold its credentials.
+	BaseURL   string                     `yaml:"base_url,omitempty" mapstructure:"base_url,omitempty"`
+	Auth      string                     `yaml:"auth,omitempty" mapstructure:"auth,omitempty"`
+	Endpoints map[string]string          `yaml:"endpoints,omitempty" mapstructure:"endpoints,omitempty"`
+	Headers   map[string]string          `yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
+	Mappings  *dnsprovider.FieldMappings `yaml:"mappings,omitempty" mapstructure:"mappings,omitempty"`
+	Settings  map[string]interface{}     `yaml:"settings,omitempty" mapstructure:"settings,omitempty"`
+	Timeout   int                        `yaml:"timeout,omitempty" mapstructure:"timeout,omitempty"` // seconds
+	Retries   int                        `yaml:"retries,omitempty" mapstructure:"retries,omitempty"`
 }
 
+// ProviderTypeREST is the type of provider instances that declare their REST
+// API in the config file
+const ProviderTypeREST = "rest"
+
 // Config represents the complete configuration structure
 type Config struct {
 	Accounts       map[string]*AccountConfig `yaml:"accounts" mapstructure:"accounts"`
 	CurrentAccount string                    `yaml:"current_account" mapstructure:"current_account"`
+	// Providers are named provider instances, e.g. "cloudflare-work"
+	Providers map[string]*ProviderConfig `yaml:"providers,omitempty" mapstructure:"providers,omitempty"`
+	// Contacts are named contact profiles used to register domains
+	Contacts map[string]*ContactProfile `yaml:"contacts,omitempty" mapstructure:"contacts,omitempty"`
+	// Display controls how dates and timestamps are shown
+	Display *DisplayConfig `yaml:"display,omitempty" mapstructure:"display,omitempty"`
+	// DDNS configures how dns ddns detects the public address
+	DDNS *DDNSConfig `yaml:"ddns,omitempty" mapstructure:"ddns,omitempty"`
+	// Policy is the policy file DNS changes are checked against before they
+	// are applied, relative to the config file's directory unless absolute
+	Policy string `yaml:"policy,omitempty" mapstructure:"policy,omitempty"`
+	// WarmResolvers are resolvers, e.g. corporate ones, that --warm primes
+	// along with the major public resolvers
+	WarmResolvers []string `yaml:"warm_resolvers,omitempty" mapstructure:"warm_resolvers,omitempty"`
+	// ChangeWindows restrict DNS changes to maintenance windows per domain or
+	// account; changes outside them need --emergency
+	ChangeWindows []changewindow.Window `yaml:"change_windows,omitempty" mapstructure:"change_windows,omitempty"`
+	// Audit configures the audit log of changes
+	Audit *AuditConfig `yaml:"audit,omitempty" mapstructure:"audit,omitempty"`
+	// RateLimits override the rates API requests are paced at, keyed by provider
+	// instance or type, e.g. namecheap: 30/min or namecheap: 30/min,500/h
+	RateLimits map[string]string `yaml:"rate_limits,omitempty" mapstructure:"rate_limits,omitempty"`
+	// Tracing exports spans of provider operations over OTLP
+	Tracing *TracingConfig `yaml:"tracing,omitempty" mapstructure:"tracing,omitempty"`
 
 	// Legacy fields for backward compatibility
 	Username   string `yaml:"username" mapstructure:"username"`
@@ -38,25 +98,14 @@ type Config struct {
 type Manager struct {
 	configPath string
 	config     *Config
+	// loaded is true when the configuration was read from configPath
+	loaded bool
 }
 
-// NewManager creates a new configuration manager
+// NewManager creates a new configuration manager using the config file
+// selected by ResolveConfigPath
 func NewManager() (*Manager, error) {
-	// First try to find config in project directory
-	projectConfigPath := FindProjectConfigPath()
-
-	// Fall back to home directory if project config not found
-	homeConfigPath := findHomeConfigPath()
-
-	// Determine which config to use
-	var configPath string
-	if projectConfigPath != "" {
-		configPath = projectConfigPath
-	} else {
-		configPath = homeConfigPath
-	}
-
-	return NewManagerWithPath(configPath)
+	return NewManagerWithPath(ResolveConfigPath("").Path)
 }
 
 // NewManagerWithPath creates a new configuration manager with a specific config path
@@ -100,7 +149,12 @@ func (m *Manager) Load() error {
 		return err
 	}
 
-	return yaml.Unmarshal(data, m.config)
+	if err := yaml.Unmarshal(data, m.config); err != nil {
+		return err
+	}
+
+	m.loaded = true
+	return nil
 }
 
 // Save writes the configuration to file
@@ -130,22 +184,35 @@ func (m *Manager) GetCurrentAccount() (*AccountConfig, error) {
 		m.config.CurrentAccount = "default"
 	}
 
-	account, exists := m.config.Accounts[m.config.CurrentAccount]
-	if !exists {
+	if _, exists := m.config.Accounts[m.config.CurrentAccount]; !exists {
 		return nil, fmt.Errorf("current account '%s' not found", m.config.CurrentAccount)
 	}
 
-	return account, nil
+	return m.GetAccount(m.config.CurrentAccount)
 }
 
-// GetAccount returns a specific account by name
+// GetAccount returns a specific account by name, with ZONEKIT_ACCOUNT_<NAME>_<FIELD>
+// environment overrides applied
 func (m *Manager) GetAccount(name string) (*AccountConfig, error) {
 	account, exists := m.config.Accounts[name]
-	if !exists {
+	if !exists || account == nil {
+		return nil, fmt.Errorf("account '%s' not found", name)
+	}
+
+	return applyEnvOverrides(name, account)
+}
+
+// GetAccountRaw returns a copy of an account as stored in the config file,
+// without environment overrides. Use it for accounts that are edited and saved
+// back with UpdateAccount, so overrides never end up in the file.
+func (m *Manager) GetAccountRaw(name string) (*AccountConfig, error) {
+	account, exists := m.config.Accounts[name]
+	if !exists || account == nil {
 		return nil, fmt.Errorf("account '%s' not found", name)
 	}
 
-	return account, nil
+	stored := *account
+	return &stored, nil
 }
 
 // SetCurrentAccount changes the currently selected account
@@ -207,21 +274,17 @@ func (m *Manager) RemoveAccount(name string) error {
 		return fmt.Errorf("cannot remove the last account")
 	}
 
-	// If removing current account, switch to another one
+	delete(m.config.Accounts, name)
+
+	// If removing current account, switch to the first remaining one by name
 	if m.config.CurrentAccount == name {
-		for accountName := range m.config.Accounts {
-			if accountName != name {
-				m.config.CurrentAccount = accountName
-				break
-			}
-		}
+		m.config.CurrentAccount = m.ListAccounts()[0]
 	}
 
-	delete(m.config.Accounts, name)
 	return m.Save()
 }
 
-// ListAccounts returns all account names
+// ListAccounts returns all account names in sorted order
 func (m *Manager) ListAccounts() []string {
 	if m.config.Accounts == nil {
 		return []string{}
@@ -231,10 +294,153 @@ func (m *Manager) ListAccounts() []string {
 	for name := range m.config.Accounts {
 		accounts = append(accounts, name)
 	}
+	sort.Strings(accounts)
 
 	return accounts
 }
 
+// DisplayConfig controls how dates and timestamps are shown
+type DisplayConfig struct {
+	// Timezone is "local" (the default), "UTC" or an IANA name such as "Europe/Berlin"
+	Timezone string `yaml:"timezone,omitempty" mapstructure:"timezone,omitempty"`
+	// DateFormat is "iso" (the default), "us", "eu" or a Go layout such as "Jan 2, 2006"
+	DateFormat string `yaml:"date_format,omitempty" mapstructure:"date_format,omitempty"`
+}
+
+// GetDisplay returns the display settings; unset settings are empty
+func (m *Manager) GetDisplay() DisplayConfig {
+	if m.config.Display == nil {
+		return DisplayConfig{}
+	}
+	return *m.config.Display
+}
+
+// GetPolicyPath returns the path of the policy file, or "" if none is set
+func (m *Manager) GetPolicyPath() string {
+	if m.config.Policy == "" || filepath.IsAbs(m.config.Policy) {
+		return m.config.Policy
+	}
+	return filepath.Join(filepath.Dir(m.configPath), m.config.Policy)
+}
+
+// GetWarmResolvers returns the resolvers --warm primes besides the public ones
+func (m *Manager) GetWarmResolvers() []string {
+	return m.config.WarmResolvers
+}
+
+// GetChangeWindows returns the configured change windows
+func (m *Manager) GetChangeWindows() []changewindow.Window {
+	return m.config.ChangeWindows
+}
+
+// AuditConfig configures the audit log of changes
+type AuditConfig struct {
+	// Path is the log file, relative to the config file's directory unless
+	// absolute; the default is ~/.zonekit/audit.jsonl
+	Path string `yaml:"path,omitempty" mapstructure:"path,omitempty"`
+	// Webhook ships each entry to an HTTP endpoint as it is written
+	Webhook *AuditWebhookConfig `yaml:"webhook,omitempty" mapstructure:"webhook,omitempty"`
+}
+
+// AuditWebhookConfig is an HTTP endpoint audit entries are posted to
+type AuditWebhookConfig struct {
+	URL string `yaml:"url" mapstructure:"url"`
+	// Headers are sent with each entry; values may be secret references such as
+	// env:AUDIT_TOKEN
+	Headers map[string]string `yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
+}
+
+// GetAudit returns the audit log settings with the log path resolved; unset
+// settings are empty
+func (m *Manager) GetAudit() AuditConfig {
+	if m.config.Audit == nil {
+		return AuditConfig{}
+	}
+	audit := *m.config.Audit
+	if audit.Path != "" && !filepath.IsAbs(audit.Path) {
+		audit.Path = filepath.Join(filepath.Dir(m.configPath), audit.Path)
+	}
+	return audit
+}
+
+// GetRateLimits returns the configured rate overrides, keyed by provider
+// instance or type
+func (m *Manager) GetRateLimits() (map[string][]ratelimit.Rate, error) {
+	return parseRateLimits(m.config.RateLimits)
+}
+
+// parseRateLimits parses rate overrides, e.g. "50/min" or "50/min,700/h"
+func parseRateLimits(values map[string]string) (map[string][]ratelimit.Rate, error) {
+	rates := make(map[string][]ratelimit.Rate, len(values))
+	for name, value := range values {
+		rate, err := ratelimit.ParseRates(value)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit for %s: %w", name, err)
+		}
+		rates[name] = rate
+	}
+	return rates, nil
+}
+
+// TracingConfig configures the export of OpenTelemetry spans
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP base URL of a collector, e.g.
+	// http://localhost:4318
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	// Headers are sent with each export; values may be secret references such
+	// as env:HONEYCOMB_API_KEY
+	Headers map[string]string `yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
+	// ServiceName names zonekit in the traces; the default is "zonekit"
+	ServiceName string `yaml:"service_name,omitempty" mapstructure:"service_name,omitempty"`
+}
+
+// GetTracing returns the tracing settings; unset settings are empty
+func (m *Manager) GetTracing() TracingConfig {
+	if m.config.Tracing == nil {
+		return TracingConfig{}
+	}
+	return *m.config.Tracing
+}
+
+// DDNSConfig configures public address detection for dns ddns
+type DDNSConfig struct {
+	// Consensus is how many sources must report the same address before a
+	// record is updated; 0 and 1 use the first answer
+	Consensus int `yaml:"consensus,omitempty" mapstructure:"consensus,omitempty"`
+	// IPv4 configures detection for A records
+	IPv4 DDNSFamilyConfig `yaml:"ipv4,omitempty" mapstructure:"ipv4,omitempty"`
+	// IPv6 configures detection for AAAA records
+	IPv6 DDNSFamilyConfig `yaml:"ipv6,omitempty" mapstructure:"ipv6,omitempty"`
+}
+
+// DDNSFamilyConfig configures detection for one address family
+type DDNSFamilyConfig struct {
+	// Enabled selects the family for dns ddns; by default only IPv4 is updated
+	Enabled *bool `yaml:"enabled,omitempty" mapstructure:"enabled,omitempty"`
+	// PreferPrivacy publishes a temporary IPv6 privacy address instead of the
+	// stable address; it only applies to IPv6
+	PreferPrivacy bool `yaml:"prefer_privacy,omitempty" mapstructure:"prefer_privacy,omitempty"`
+	// Sources are address source specs tried in order, e.g.
+	// "https://api.ipify.org", "iface:eth0", "upnp" or "exec:<command>"
+	Sources []string `yaml:"sources,omitempty" mapstructure:"sources,omitempty"`
+}
+
+// GetDDNS returns the dynamic DNS settings; unset settings are empty
+func (m *Manager) GetDDNS() DDNSConfig {
+	if m.config.DDNS == nil {
+		return DDNSConfig{}
+	}
+	return *m.config.DDNS
+}
+
+// GetProviders returns the configured provider instances by name
+func (m *Manager) GetProviders() map[string]*ProviderConfig {
+	if m.config.Providers == nil {
+		return map[string]*ProviderConfig{}
+	}
+	return m.config.Providers
+}
+
 // GetConfigPath returns the configuration file path
 func (m *Manager) GetConfigPath() string {
 	return m.configPath
@@ -242,10 +448,13 @@ func (m *Manager) GetConfigPath() string {
 
 // GetConfigLocation returns a human-readable description of where the config is located
 func (m *Manager) GetConfigLocation() string {
-	if filepath.Dir(m.configPath) == filepath.Join(os.Getenv("HOME"), "configs") {
+	if project := FindProjectConfigPath(); project != "" && filepath.Clean(project) == filepath.Clean(m.configPath) {
 		return "project directory (configs/.zonekit.yaml)"
 	}
-	return "home directory (~/.zonekit.yaml)"
+	if filepath.Clean(findHomeConfigPath()) == filepath.Clean(m.configPath) {
+		return "home directory (~/.zonekit.yaml)"
+	}
+	return m.configPath
 }
 
 // GetCurrentAccountName returns the name of the currently selected account
@@ -313,16 +522,34 @@ func (m *Manager) migrateLegacyConfig() error {
 	return nil
 }
 
+// DefaultProvider is the provider of accounts that do not name one
+const DefaultProvider = "namecheap"
+
 // GetProvider returns the provider name for an account, defaulting to "namecheap" for backward compatibility
 func (a *AccountConfig) GetProvider() string {
 	if a.Provider == "" {
-		return "namecheap" // Default provider for backward compatibility
+		return DefaultProvider // Default provider for backward compatibility
 	}
 	return a.Provider
 }
 
+// UsesNamecheap reports whether the account's DNS is managed through its
+// Namecheap API credentials rather than another registered provider
+func (a *AccountConfig) UsesNamecheap() bool {
+	return a.GetProvider() == DefaultProvider
+}
+
+// IsGuarded reports whether destructive operations on this account need explicit confirmation
+func (a *AccountConfig) IsGuarded() bool {
+	return a.ProductionGuard && !a.UseSandbox
+}
+
 // ValidateAccount validates an account configuration
 func (m *Manager) ValidateAccount(account *AccountConfig) error {
+	// Accounts bound to another provider take their credentials from it
+	if !account.UsesNamecheap() {
+		return nil
+	}
 	if account.Username == "" {
 		return fmt.Errorf("username is required")
 	}
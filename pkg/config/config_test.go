@@ -7,7 +7,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/yaml.v3"
-	"zonekit/internal/testutil"
+	"zonekit/pkg/testutil"
 )
 
 // ConfigTestSuite is a test suite for config package
@@ -121,6 +121,16 @@ func (s *ConfigTestSuite) TestManager_ValidateAccount_MissingAPIKey() {
 	s.Require().Error(err)
 }
 
+func (s *ConfigTestSuite) TestManager_ValidateAccount_OtherProvider() {
+	// Accounts bound to another provider need no Namecheap credentials
+	account := &AccountConfig{Provider: "cloudflare-work", Description: "Work zones"}
+	s.Require().NoError(s.manager.ValidateAccount(account))
+	s.Require().False(account.UsesNamecheap())
+
+	account.Provider = DefaultProvider
+	s.Require().Error(s.manager.ValidateAccount(account))
+}
+
 func (s *ConfigTestSuite) TestManager_ValidateAccount_MissingClientIP() {
 	fixture := testutil.AccountConfigFixtureWithValues("test", "test", "test", "", true)
 	account := &AccountConfig{
@@ -218,6 +228,53 @@ func (s *ConfigTestSuite) TestManager_RemoveAccount() {
 	s.Require().Error(err)
 }
 
+func (s *ConfigTestSuite) TestManager_RemoveAccount_SwitchesCurrent() {
+	fixture := testutil.AccountConfigFixture()
+	account := &AccountConfig{Username: fixture.Username, APIUser: fixture.APIUser, APIKey: fixture.APIKey, ClientIP: fixture.ClientIP}
+
+	s.Require().NoError(s.manager.AddAccount("work", account))
+	s.Require().NoError(s.manager.AddAccount("client", account))
+	s.Require().NoError(s.manager.SetCurrentAccount("work"))
+
+	// The first remaining account by name becomes current
+	s.Require().NoError(s.manager.RemoveAccount("work"))
+	s.Require().Equal("client", s.manager.GetCurrentAccountName())
+	s.Require().Equal([]string{"client", "default"}, s.manager.ListAccounts())
+
+	// Removing another account keeps the current one
+	s.Require().NoError(s.manager.RemoveAccount("default"))
+	s.Require().Equal("client", s.manager.GetCurrentAccountName())
+}
+
+func (s *ConfigTestSuite) TestManager_ExportAccount() {
+	account := &AccountConfig{Username: "user", APIUser: "user", APIKey: "secret-key", ClientIP: "192.0.2.1", Description: "Work"}
+	s.Require().NoError(s.manager.AddAccount("work", account))
+
+	data, err := s.manager.ExportAccount("work", false)
+	s.Require().NoError(err)
+	s.Require().NotContains(string(data), "secret-key")
+
+	var exported Config
+	s.Require().NoError(yaml.Unmarshal(data, &exported))
+	s.Require().Equal(RedactedValue, exported.Accounts["work"].APIKey)
+	s.Require().Equal("Work", exported.Accounts["work"].Description)
+	s.Require().Equal("secret-key", account.APIKey)
+
+	data, err = s.manager.ExportAccount("work", true)
+	s.Require().NoError(err)
+	s.Require().NoError(yaml.Unmarshal(data, &exported))
+	s.Require().Equal("secret-key", exported.Accounts["work"].APIKey)
+
+	// Secret references are kept
+	s.Require().NoError(s.manager.UpdateAccount("work", &AccountConfig{Username: "user", APIUser: "user", APIKey: "env:NC_API_KEY", ClientIP: "192.0.2.1"}))
+	data, err = s.manager.ExportAccount("work", false)
+	s.Require().NoError(err)
+	s.Require().Contains(string(data), "env:NC_API_KEY")
+
+	_, err = s.manager.ExportAccount("missing", false)
+	s.Require().Error(err)
+}
+
 func (s *ConfigTestSuite) TestManager_SetCurrentAccount() {
 	fixture := testutil.AccountConfigFixture()
 	account := &AccountConfig{
@@ -246,6 +303,210 @@ func (s *ConfigTestSuite) TestManager_SetCurrentAccount() {
 }
 
 // Helper method for testing
+func (s *ConfigTestSuite) TestManager_GetAccount_EnvOverrides() {
+	fixture := testutil.AccountConfigFixture()
+	s.Require().NoError(s.manager.AddAccount("work-ci", &AccountConfig{
+		Username: fixture.Username,
+		APIUser:  fixture.APIUser,
+		APIKey:   fixture.APIKey,
+		ClientIP: fixture.ClientIP,
+	}))
+
+	s.T().Setenv("ZONEKIT_ACCOUNT_WORK_CI_API_KEY", "injected-key")
+	s.T().Setenv("ZONEKIT_ACCOUNT_WORK_CI_USE_SANDBOX", "true")
+
+	account, err := s.manager.GetAccount("work-ci")
+	s.Require().NoError(err)
+	s.Require().Equal("injected-key", account.APIKey)
+	s.Require().True(account.UseSandbox)
+	s.Require().Equal(fixture.APIUser, account.APIUser)
+
+	// Overrides must never be persisted
+	s.Require().NoError(s.manager.Save())
+	data, err := os.ReadFile(s.configPath)
+	s.Require().NoError(err)
+	s.Require().NotContains(string(data), "injected-key")
+
+	sources, err := s.manager.AccountSources("work-ci")
+	s.Require().NoError(err)
+	for _, v := range sources {
+		if v.Field == "api_key" {
+			s.Require().Equal("environment (ZONEKIT_ACCOUNT_WORK_CI_API_KEY)", v.Source)
+		}
+	}
+}
+
+func (s *ConfigTestSuite) TestManager_GetAccountRaw_IgnoresEnvOverrides() {
+	fixture := testutil.AccountConfigFixture()
+	s.Require().NoError(s.manager.AddAccount("work-ci", &AccountConfig{
+		Username: fixture.Username,
+		APIUser:  fixture.APIUser,
+		APIKey:   fixture.APIKey,
+		ClientIP: fixture.ClientIP,
+	}))
+	s.T().Setenv("ZONEKIT_ACCOUNT_WORK_CI_API_KEY", "injected-key")
+
+	account, err := s.manager.GetAccountRaw("work-ci")
+	s.Require().NoError(err)
+	s.Require().Equal(fixture.APIKey, account.APIKey)
+
+	// Saving an edited copy, as 'account edit' does, keeps the stored key
+	account.Description = "edited"
+	s.Require().NoError(s.manager.UpdateAccount("work-ci", account))
+	data, err := os.ReadFile(s.configPath)
+	s.Require().NoError(err)
+	s.Require().NotContains(string(data), "injected-key")
+	s.Require().Contains(string(data), "edited")
+
+	_, err = s.manager.GetAccountRaw("missing")
+	s.Require().Error(err)
+}
+
+func (s *ConfigTestSuite) TestManager_GetAccount_InvalidEnvOverride() {
+	s.T().Setenv("ZONEKIT_ACCOUNT_DEFAULT_USE_SANDBOX", "maybe")
+
+	_, err := s.manager.GetAccount("default")
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "ZONEKIT_ACCOUNT_DEFAULT_USE_SANDBOX")
+}
+
+func (s *ConfigTestSuite) TestManager_GetProviders() {
+	content := `providers:
+  cloudflare-personal:
+    type: cloudflare
+    credentials:
+      token: env:ZONEKIT_TEST_CF_PERSONAL
+  cloudflare-work:
+    type: cloudflare
+    credentials:
+      token: plain-token
+`
+	s.Require().NoError(os.WriteFile(s.configPath, []byte(content), 0600))
+	manager, err := NewManagerWithPath(s.configPath)
+	s.Require().NoError(err)
+
+	providers := manager.GetProviders()
+	s.Require().Len(providers, 2)
+	s.Require().Equal("cloudflare", providers["cloudflare-work"].Type)
+
+	s.T().Setenv("ZONEKIT_TEST_CF_PERSONAL", "personal-token")
+	credentials, err := providers["cloudflare-personal"].ResolveCredentials()
+	s.Require().NoError(err)
+	s.Require().Equal("personal-token", credentials["token"])
+	s.Require().Equal("env:ZONEKIT_TEST_CF_PERSONAL", providers["cloudflare-personal"].Credentials["token"])
+
+	credentials, err = providers["cloudflare-work"].ResolveCredentials()
+	s.Require().NoError(err)
+	s.Require().Equal("plain-token", credentials["token"])
+}
+
+func (s *ConfigTestSuite) TestProviderConfig_RESTConfig() {
+	content := `providers:
+  internal-dns:
+    type: rest
+    base_url: https://dns.internal.example/api
+    auth: bearer
+    credentials:
+      token: env:ZONEKIT_TEST_REST_TOKEN
+    headers:
+      X-Tenant: env:ZONEKIT_TEST_REST_TENANT
+    endpoints:
+      get_records: /zones/{domain}/records
+      create_record: /zones/{domain}/records
+    mappings:
+      response:
+        hostname: name
+        address: content
+      list_path: data
+    timeout: 10
+  cloudflare-work:
+    type: cloudflare
+`
+	s.Require().NoError(os.WriteFile(s.configPath, []byte(content), 0600))
+	manager, err := NewManagerWithPath(s.configPath)
+	s.Require().NoError(err)
+
+	s.T().Setenv("ZONEKIT_TEST_REST_TOKEN", "secret-token")
+	s.T().Setenv("ZONEKIT_TEST_REST_TENANT", "acme")
+	cfg, err := manager.GetProviders()["internal-dns"].RESTConfig("internal-dns")
+	s.Require().NoError(err)
+	s.Require().Equal("internal-dns", cfg.Name)
+	s.Require().Equal("rest", cfg.Type)
+	s.Require().Equal("bearer", cfg.Auth.Method)
+	s.Require().Equal("secret-token", cfg.Auth.Credentials["token"])
+	s.Require().Equal("https://dns.internal.example/api", cfg.API.BaseURL)
+	s.Require().Equal("acme", cfg.API.Headers["X-Tenant"])
+	s.Require().Equal("/zones/{domain}/records", cfg.API.Endpoints["get_records"])
+	s.Require().Equal(10, cfg.API.Timeout)
+	s.Require().Equal("content", cfg.Mappings.Response.Address)
+	s.Require().Equal("data", cfg.Mappings.ListPath)
+
+	_, err = manager.GetProviders()["cloudflare-work"].RESTConfig("cloudflare-work")
+	s.Require().ErrorContains(err, `not "rest"`)
+}
+
+func (s *ConfigTestSuite) TestManager_GetContactProfile() {
+	content := `contacts:
+  personal:
+    registrant:
+      first_name: Jane
+      last_name: Doe
+      address1: 1 Main St
+      city: Springfield
+      state_province: IL
+      postal_code: "62701"
+      country: US
+      phone: "+1.5555550100"
+      email: jane@example.com
+    tech:
+      first_name: Tom
+      last_name: Doe
+      address1: 1 Main St
+      city: Springfield
+      state_province: IL
+      postal_code: "62701"
+      country: US
+      phone: "+1.5555550101"
+      email: tom@example.com
+`
+	s.Require().NoError(os.WriteFile(s.configPath, []byte(content), 0600))
+	manager, err := NewManagerWithPath(s.configPath)
+	s.Require().NoError(err)
+
+	profile, err := manager.GetContactProfile("personal")
+	s.Require().NoError(err)
+	s.Require().NoError(profile.Validate())
+	s.Require().Equal("Tom", profile.Tech.FirstName)
+	s.Require().Equal("Jane", profile.Admin.FirstName)
+	s.Require().Equal("Jane", profile.Billing.FirstName)
+
+	_, err = manager.GetContactProfile("work")
+	s.Require().ErrorContains(err, "available: personal")
+
+	profile.Tech.Phone = "555-0101"
+	s.Require().ErrorContains(profile.Validate(), "tech contact: phone")
+}
+
 func (c *Config) marshal() ([]byte, error) {
 	return yaml.Marshal(c)
 }
+
+func (s *ConfigTestSuite) TestManager_GetPolicyPath() {
+	s.Require().Empty(s.manager.GetPolicyPath())
+
+	s.manager.config.Policy = "policy.yaml"
+	s.Require().Equal(filepath.Join(filepath.Dir(s.configPath), "policy.yaml"), s.manager.GetPolicyPath())
+
+	s.manager.config.Policy = "/etc/zonekit/policy.rego"
+	s.Require().Equal("/etc/zonekit/policy.rego", s.manager.GetPolicyPath())
+}
+
+func (s *ConfigTestSuite) TestManager_GetAudit() {
+	s.Require().Empty(s.manager.GetAudit().Path)
+
+	s.manager.config.Audit = &AuditConfig{Path: "audit.jsonl", Webhook: &AuditWebhookConfig{URL: "https://audit.example.net/zonekit"}}
+	audit := s.manager.GetAudit()
+	s.Require().Equal(filepath.Join(filepath.Dir(s.configPath), "audit.jsonl"), audit.Path)
+	s.Require().Equal("https://audit.example.net/zonekit", audit.Webhook.URL)
+	s.Require().Equal("audit.jsonl", s.manager.config.Audit.Path, "the config is not changed")
+}
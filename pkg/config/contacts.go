@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// phonePattern is the +CountryCode.Number format registries expect, e.g. +1.6613102107
+var phonePattern = regexp.MustCompile(`^\+\d{1,3}\.\d{4,14}$`)
+
+// Contact is a person or organization named in a domain registration
+type Contact struct {
+	FirstName     string `yaml:"first_name" mapstructure:"first_name"`
+	LastName      string `yaml:"last_name" mapstructure:"last_name"`
+	Organization  string `yaml:"organization,omitempty" mapstructure:"organization,omitempty"`
+	JobTitle      string `yaml:"job_title,omitempty" mapstructure:"job_title,omitempty"`
+	Address1      string `yaml:"address1" mapstructure:"address1"`
+	Address2      string `yaml:"address2,omitempty" mapstructure:"address2,omitempty"`
+	City          string `yaml:"city" mapstructure:"city"`
+	StateProvince string `yaml:"state_province" mapstructure:"state_province"`
+	PostalCode    string `yaml:"postal_code" mapstructure:"postal_code"`
+	// Country is the two-letter ISO 3166-1 country code
+	Country string `yaml:"country" mapstructure:"country"`
+	// Phone is in the form +CountryCode.Number, e.g. +1.6613102107
+	Phone string `yaml:"phone" mapstructure:"phone"`
+	Email string `yaml:"email" mapstructure:"email"`
+}
+
+// ContactProfile holds the contacts used to register domains. The admin, tech
+// and billing contacts default to the registrant when they are omitted.
+type ContactProfile struct {
+	Registrant *Contact `yaml:"registrant" mapstructure:"registrant"`
+	Admin      *Contact `yaml:"admin,omitempty" mapstructure:"admin,omitempty"`
+	Tech       *Contact `yaml:"tech,omitempty" mapstructure:"tech,omitempty"`
+	Billing    *Contact `yaml:"billing,omitempty" mapstructure:"billing,omitempty"`
+}
+
+// GetContactProfile returns a contact profile by name, with the admin, tech and
+// billing contacts defaulted to the registrant
+func (m *Manager) GetContactProfile(name string) (*ContactProfile, error) {
+	profile, exists := m.config.Contacts[name]
+	if !exists || profile == nil {
+		names := make([]string, 0, len(m.config.Contacts))
+		for n := range m.config.Contacts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("contact profile '%s' not found: no contact profiles are configured", name)
+		}
+		return nil, fmt.Errorf("contact profile '%s' not found (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	resolved := *profile
+	if resolved.Admin == nil {
+		resolved.Admin = resolved.Registrant
+	}
+	if resolved.Tech == nil {
+		resolved.Tech = resolved.Registrant
+	}
+	if resolved.Billing == nil {
+		resolved.Billing = resolved.Registrant
+	}
+	return &resolved, nil
+}
+
+// Validate checks that every contact of the profile is complete
+func (p *ContactProfile) Validate() error {
+	if p.Registrant == nil {
+		return fmt.Errorf("registrant contact is required")
+	}
+	for _, c := range []struct {
+		role    string
+		contact *Contact
+	}{
+		{"registrant", p.Registrant},
+		{"admin", p.Admin},
+		{"tech", p.Tech},
+		{"billing", p.Billing},
+	} {
+		if c.contact == nil {
+			continue
+		}
+		if err := c.contact.Validate(); err != nil {
+			return fmt.Errorf("%s contact: %w", c.role, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the contact has the fields registries require
+func (c *Contact) Validate() error {
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"first_name", c.FirstName},
+		{"last_name", c.LastName},
+		{"address1", c.Address1},
+		{"city", c.City},
+		{"state_province", c.StateProvince},
+		{"postal_code", c.PostalCode},
+		{"country", c.Country},
+		{"phone", c.Phone},
+		{"email", c.Email},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%s is required", field.name)
+		}
+	}
+
+	if len(c.Country) != 2 {
+		return fmt.Errorf("country must be a two-letter country code, got '%s'", c.Country)
+	}
+	if !phonePattern.MatchString(c.Phone) {
+		return fmt.Errorf("phone must be in the form +CountryCode.Number (e.g. +1.6613102107), got '%s'", c.Phone)
+	}
+	if !strings.Contains(c.Email, "@") {
+		return fmt.Errorf("email '%s' is not valid", c.Email)
+	}
+	return nil
+}
@@ -1,10 +1,16 @@
 package config
 
+import "zonekit/pkg/secret"
+
 // MaskAPIKey masks an API key for display, showing only first 4 and last 4 characters.
+// Secret manager references are not secret themselves and are shown unchanged.
 func MaskAPIKey(apiKey string) string {
 	if apiKey == "" {
 		return "(not set)"
 	}
+	if secret.IsReference(apiKey) {
+		return apiKey
+	}
 	if len(apiKey) <= 8 {
 		return "***"
 	}
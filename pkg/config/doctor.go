@@ -0,0 +1,485 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"zonekit/pkg/ddns"
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/timefmt"
+)
+
+// Severity indicates how serious a diagnostic finding is
+type Severity string
+
+const (
+	SeverityOK      Severity = "ok"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Finding is a single result of a configuration diagnostic check
+type Finding struct {
+	Check    string
+	Severity Severity
+	Message  string
+	// Fix describes how to resolve the problem, empty if nothing needs to be done
+	Fix string
+}
+
+// DoctorOptions controls which checks Diagnose performs
+type DoctorOptions struct {
+	// ConfigPath is the config file in use
+	ConfigPath string
+	// ProjectConfigPath and HomeConfigPath are the candidate locations checked for conflicts
+	ProjectConfigPath string
+	HomeConfigPath    string
+	// KnownProviders lists provider names that are available; nil skips the provider check
+	KnownProviders []string
+}
+
+// placeholderValues are values written by createDefaultConfig and the example config
+var placeholderValues = map[string]bool{
+	"your-provider-username":  true,
+	"your-namecheap-username": true,
+	"your-api-username":       true,
+	"your-api-key-here":       true,
+	"your-api-key":            true,
+	"your.public.ip.address":  true,
+	RedactedValue:             true,
+}
+
+// DefaultDoctorOptions returns options describing the config locations zonekit would use
+func DefaultDoctorOptions(configPath string) DoctorOptions {
+	return DoctorOptions{
+		ConfigPath:        ResolveConfigPath(configPath).Path,
+		ProjectConfigPath: FindProjectConfigPath(),
+		HomeConfigPath:    findHomeConfigPath(),
+	}
+}
+
+// Diagnose inspects the configuration file and its surroundings and reports problems
+// together with suggested fixes. It never modifies the configuration.
+func Diagnose(opts DoctorOptions) []Finding {
+	var findings []Finding
+
+	info, err := os.Stat(opts.ConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return append(findings, Finding{
+				Check:    "config file",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("config file %s does not exist", opts.ConfigPath),
+				Fix:      "run 'zonekit config init' or 'zonekit account add'",
+			})
+		}
+		return append(findings, Finding{
+			Check:    "config file",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("cannot access %s: %v", opts.ConfigPath, err),
+		})
+	}
+
+	findings = append(findings, checkPermissions(opts.ConfigPath, info))
+
+	data, err := os.ReadFile(opts.ConfigPath)
+	if err != nil {
+		return append(findings, Finding{
+			Check:    "config file",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("cannot read %s: %v", opts.ConfigPath, err),
+			Fix:      fmt.Sprintf("check ownership of %s", opts.ConfigPath),
+		})
+	}
+
+	cfg, syntax := checkYAML(data)
+	findings = append(findings, syntax...)
+	if cfg == nil {
+		return findings
+	}
+
+	findings = append(findings, checkAccounts(cfg)...)
+	findings = append(findings, checkLegacyFields(cfg)...)
+	findings = append(findings, checkDisplay(cfg)...)
+	findings = append(findings, checkDDNS(cfg)...)
+	findings = append(findings, checkChangeWindows(cfg)...)
+	findings = append(findings, checkRateLimits(cfg)...)
+	if opts.KnownProviders != nil {
+		findings = append(findings, checkProviders(cfg, opts.KnownProviders)...)
+	}
+	findings = append(findings, checkKeyring())
+	findings = append(findings, checkConflicts(opts)...)
+
+	return findings
+}
+
+// HasErrors reports whether any finding has error severity
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPermissions warns when the config file is readable by other users
+func checkPermissions(path string, info os.FileInfo) Finding {
+	if runtime.GOOS == "windows" {
+		return Finding{Check: "permissions", Severity: SeverityInfo, Message: "permission check skipped on Windows"}
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0077 != 0 {
+		return Finding{
+			Check:    "permissions",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("config file is accessible by other users (mode %04o)", mode),
+			Fix:      fmt.Sprintf("chmod 600 %s", path),
+		}
+	}
+
+	return Finding{Check: "permissions", Severity: SeverityOK, Message: fmt.Sprintf("mode %04o", mode)}
+}
+
+// checkYAML parses the config strictly and reports syntax errors, duplicate keys and unknown fields
+func checkYAML(data []byte) (*Config, []Finding) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, []Finding{{
+			Check:    "yaml",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("invalid YAML: %v", err),
+			Fix:      "fix the syntax error; duplicate account names are reported here as 'already defined'",
+		}}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var strict Config
+	if err := decoder.Decode(&strict); err != nil && !errors.Is(err, io.EOF) {
+		return &cfg, []Finding{{
+			Check:    "yaml",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("unknown or misspelled fields: %v", err),
+			Fix:      "remove or rename the fields listed above",
+		}}
+	}
+
+	return &cfg, []Finding{{Check: "yaml", Severity: SeverityOK, Message: "valid"}}
+}
+
+// checkAccounts reports ghost accounts, placeholders, duplicates and a dangling current account
+func checkAccounts(cfg *Config) []Finding {
+	var findings []Finding
+
+	if len(cfg.Accounts) == 0 {
+		return []Finding{{
+			Check:    "accounts",
+			Severity: SeverityError,
+			Message:  "no accounts configured",
+			Fix:      "run 'zonekit account add'",
+		}}
+	}
+
+	names := make([]string, 0, len(cfg.Accounts))
+	for name := range cfg.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	credentials := make(map[string][]string)
+	var credentialOrder []string
+	for _, name := range names {
+		account := cfg.Accounts[name]
+		if account == nil {
+			findings = append(findings, Finding{
+				Check:    "accounts",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("account '%s' has no settings", name),
+				Fix:      fmt.Sprintf("remove it with 'zonekit account remove %s' or fill it in with 'zonekit account edit %s'", name, name),
+			})
+			continue
+		}
+
+		if placeholders := placeholderFields(account); len(placeholders) > 0 {
+			findings = append(findings, Finding{
+				Check:    "accounts",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("account '%s' still uses placeholder values for %s", name, strings.Join(placeholders, ", ")),
+				Fix:      fmt.Sprintf("run 'zonekit account edit %s'", name),
+			})
+			continue
+		}
+
+		if account.APIUser != "" && account.APIKey != "" {
+			key := account.GetProvider() + "|" + account.APIUser + "|" + account.APIKey
+			if _, seen := credentials[key]; !seen {
+				credentialOrder = append(credentialOrder, key)
+			}
+			credentials[key] = append(credentials[key], name)
+		}
+	}
+
+	for _, key := range credentialOrder {
+		if dup := credentials[key]; len(dup) > 1 {
+			findings = append(findings, Finding{
+				Check:    "accounts",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("accounts %s share the same credentials", strings.Join(dup, ", ")),
+				Fix:      "remove the duplicates with 'zonekit account remove <name>'",
+			})
+		}
+	}
+
+	if cfg.CurrentAccount != "" {
+		if _, ok := cfg.Accounts[cfg.CurrentAccount]; !ok {
+			findings = append(findings, Finding{
+				Check:    "accounts",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("current account '%s' does not exist", cfg.CurrentAccount),
+				Fix:      "select an existing account with 'zonekit account switch <name>'",
+			})
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, Finding{
+			Check:    "accounts",
+			Severity: SeverityOK,
+			Message:  fmt.Sprintf("%d account(s) configured", len(cfg.Accounts)),
+		})
+	}
+
+	return findings
+}
+
+// placeholderFields returns the names of fields that still hold example values
+func placeholderFields(account *AccountConfig) []string {
+	var fields []string
+	if placeholderValues[account.Username] {
+		fields = append(fields, "username")
+	}
+	if placeholderValues[account.APIUser] {
+		fields = append(fields, "api_user")
+	}
+	if placeholderValues[account.APIKey] {
+		fields = append(fields, "api_key")
+	}
+	if placeholderValues[account.ClientIP] {
+		fields = append(fields, "client_ip")
+	}
+	return fields
+}
+
+// checkLegacyFields reports top-level single-account fields left over after migration
+func checkLegacyFields(cfg *Config) []Finding {
+	var stale []string
+	if cfg.Username != "" {
+		stale = append(stale, "username")
+	}
+	if cfg.APIUser != "" {
+		stale = append(stale, "api_user")
+	}
+	if cfg.APIKey != "" {
+		stale = append(stale, "api_key")
+	}
+	if cfg.ClientIP != "" {
+		stale = append(stale, "client_ip")
+	}
+	if cfg.UseSandbox {
+		stale = append(stale, "use_sandbox")
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	return []Finding{{
+		Check:    "legacy fields",
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("top-level legacy fields are ignored while accounts exist: %s", strings.Join(stale, ", ")),
+		Fix:      "move the values into an account and delete the top-level fields",
+	}}
+}
+
+// checkDisplay reports display settings that cannot be used; dates are then
+// shown with the defaults
+func checkDisplay(cfg *Config) []Finding {
+	if cfg.Display == nil {
+		return nil
+	}
+	if _, err := timefmt.New(cfg.Display.Timezone, cfg.Display.DateFormat); err != nil {
+		return []Finding{{
+			Check:    "display",
+			Severity: SeverityWarning,
+			Message:  err.Error(),
+			Fix:      "fix display.timezone or display.date_format; dates are shown as ISO dates in the local time zone until then",
+		}}
+	}
+	return nil
+}
+
+// checkDDNS reports address sources that cannot be used and a consensus that
+// more sources than configured would have to reach
+func checkDDNS(cfg *Config) []Finding {
+	if cfg.DDNS == nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, family := range []struct {
+		key    string
+		config DDNSFamilyConfig
+	}{{"ipv4", cfg.DDNS.IPv4}, {"ipv6", cfg.DDNS.IPv6}} {
+		for _, spec := range family.config.Sources {
+			if _, err := ddns.ParseSource(spec); err != nil {
+				findings = append(findings, Finding{
+					Check:    "ddns",
+					Severity: SeverityError,
+					Message:  err.Error(),
+					Fix:      fmt.Sprintf("fix ddns.%s.sources; dns ddns fails until then", family.key),
+				})
+			}
+		}
+		if n := len(family.config.Sources); n > 0 && cfg.DDNS.Consensus > n {
+			findings = append(findings, Finding{
+				Check:    "ddns",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("ddns.consensus is %d but ddns.%s has only %d sources", cfg.DDNS.Consensus, family.key, n),
+				Fix:      fmt.Sprintf("add sources to ddns.%s.sources or lower ddns.consensus", family.key),
+			})
+		}
+	}
+	return findings
+}
+
+// checkChangeWindows reports change windows that cannot be evaluated
+func checkChangeWindows(cfg *Config) []Finding {
+	var findings []Finding
+	for i, w := range cfg.ChangeWindows {
+		if err := w.Validate(); err != nil {
+			findings = append(findings, Finding{
+				Check:    "change windows",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("change window %d (%s): %v", i+1, w, err),
+				Fix:      "fix change_windows; changes to the domains it applies to are blocked until then",
+			})
+		}
+	}
+	return findings
+}
+
+// checkRateLimits reports rate overrides that cannot be parsed
+func checkRateLimits(cfg *Config) []Finding {
+	var findings []Finding
+	for name, value := range cfg.RateLimits {
+		if _, err := ratelimit.ParseRates(value); err != nil {
+			findings = append(findings, Finding{
+				Check:    "rate limits",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("rate limit for %s: %v", name, err),
+				Fix:      "fix rate_limits, e.g. " + name + ": 30/min; until then no override applies",
+			})
+		}
+	}
+	return findings
+}
+
+// checkProviders reports accounts that reference providers which are not
+// available. Named provider instances from the config file count as available.
+func checkProviders(cfg *Config, known []string) []Finding {
+	available := make(map[string]bool, len(known))
+	for _, name := range known {
+		available[name] = true
+	}
+	for name := range cfg.Providers {
+		available[name] = true
+	}
+
+	var findings []Finding
+	names := make([]string, 0, len(cfg.Accounts))
+	for name := range cfg.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		account := cfg.Accounts[name]
+		if account == nil {
+			continue
+		}
+		if !available[account.GetProvider()] {
+			findings = append(findings, Finding{
+				Check:    "providers",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("account '%s' uses provider '%s', which is not available", name, account.GetProvider()),
+				Fix:      fmt.Sprintf("use one of: %s, or add an openapi.yaml for the provider", strings.Join(known, ", ")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// checkKeyring reports whether an OS keyring is available for storing secrets
+func checkKeyring() Finding {
+	var tool string
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux", "freebsd", "openbsd":
+		tool = "secret-tool"
+	case "windows":
+		return Finding{Check: "keyring", Severity: SeverityOK, Message: "Windows Credential Manager available"}
+	default:
+		return Finding{Check: "keyring", Severity: SeverityInfo, Message: "no supported keyring on " + runtime.GOOS}
+	}
+
+	if _, err := exec.LookPath(tool); err != nil {
+		return Finding{
+			Check:    "keyring",
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("OS keyring tool '%s' not found; API keys are stored in the config file", tool),
+			Fix:      "keep the config file private (chmod 600)",
+		}
+	}
+
+	return Finding{Check: "keyring", Severity: SeverityOK, Message: fmt.Sprintf("'%s' available", tool)}
+}
+
+// checkConflicts reports when a project config shadows a home config
+func checkConflicts(opts DoctorOptions) []Finding {
+	if opts.ProjectConfigPath == "" || opts.HomeConfigPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(opts.HomeConfigPath); err != nil {
+		return nil
+	}
+	if filepath.Clean(opts.ProjectConfigPath) == filepath.Clean(opts.HomeConfigPath) {
+		return nil
+	}
+
+	used := opts.ProjectConfigPath
+	ignored := opts.HomeConfigPath
+	if filepath.Clean(opts.ConfigPath) == filepath.Clean(opts.HomeConfigPath) {
+		used, ignored = ignored, used
+	}
+
+	return []Finding{{
+		Check:    "conflicts",
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("both %s and %s exist; %s is ignored", used, ignored, ignored),
+		Fix:      "remove one of the files or select one explicitly with --config",
+	}}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// DoctorTestSuite is a test suite for configuration diagnostics
+type DoctorTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+// TestDoctorSuite runs the doctor test suite
+func TestDoctorSuite(t *testing.T) {
+	suite.Run(t, new(DoctorTestSuite))
+}
+
+func (s *DoctorTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+func (s *DoctorTestSuite) writeConfig(name, content string, mode os.FileMode) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), mode))
+	s.Require().NoError(os.Chmod(path, mode))
+	return path
+}
+
+func (s *DoctorTestSuite) findingsFor(findings []Finding, check string) []Finding {
+	var matched []Finding
+	for _, f := range findings {
+		if f.Check == check {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+const healthyConfig = `accounts:
+  default:
+    provider: namecheap
+    username: user
+    api_user: apiuser
+    api_key: key1234567890
+    client_ip: 203.0.113.10
+    use_sandbox: false
+    description: Main
+current_account: default
+`
+
+func (s *DoctorTestSuite) TestDiagnose_MissingFile() {
+	findings := Diagnose(DoctorOptions{ConfigPath: filepath.Join(s.dir, "missing.yaml")})
+
+	s.Require().Len(findings, 1)
+	s.Require().Equal(SeverityError, findings[0].Severity)
+	s.Require().True(HasErrors(findings))
+}
+
+func (s *DoctorTestSuite) TestDiagnose_HealthyConfig() {
+	path := s.writeConfig("config.yaml", healthyConfig, 0600)
+
+	findings := Diagnose(DoctorOptions{ConfigPath: path, KnownProviders: []string{"namecheap"}})
+
+	s.Require().False(HasErrors(findings))
+	for _, f := range findings {
+		s.Require().NotEqual(SeverityWarning, f.Severity, f.Message)
+	}
+}
+
+func (s *DoctorTestSuite) TestDiagnose_Permissions() {
+	path := s.writeConfig("config.yaml", healthyConfig, 0644)
+
+	perms := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "permissions")
+	s.Require().Len(perms, 1)
+	s.Require().Equal(SeverityWarning, perms[0].Severity)
+	s.Require().Contains(perms[0].Fix, "chmod 600")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_InvalidYAML() {
+	path := s.writeConfig("config.yaml", "accounts: [\n", 0600)
+
+	findings := Diagnose(DoctorOptions{ConfigPath: path})
+	s.Require().True(HasErrors(findings))
+	s.Require().Equal(SeverityError, s.findingsFor(findings, "yaml")[0].Severity)
+}
+
+func (s *DoctorTestSuite) TestDiagnose_DuplicateAccountKey() {
+	content := `accounts:
+  default:
+    username: a
+  default:
+    username: b
+`
+	path := s.writeConfig("config.yaml", content, 0600)
+
+	yamlFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "yaml")
+	s.Require().Equal(SeverityError, yamlFindings[0].Severity)
+	s.Require().Contains(yamlFindings[0].Message, "already defined")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_UnknownField() {
+	path := s.writeConfig("config.yaml", healthyConfig+"api_secret: nope\n", 0600)
+
+	yamlFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "yaml")
+	s.Require().Equal(SeverityWarning, yamlFindings[0].Severity)
+	s.Require().Contains(yamlFindings[0].Message, "api_secret")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_Display() {
+	path := s.writeConfig("config.yaml", healthyConfig+"display:\n  timezone: Europe/Nowhere\n", 0600)
+
+	displayFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "display")
+	s.Require().Len(displayFindings, 1)
+	s.Require().Equal(SeverityWarning, displayFindings[0].Severity)
+	s.Require().Contains(displayFindings[0].Message, "Europe/Nowhere")
+
+	path = s.writeConfig("config.yaml", healthyConfig+"display:\n  timezone: UTC\n  date_format: eu\n", 0600)
+	s.Require().Empty(s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "display"))
+}
+
+func (s *DoctorTestSuite) TestDiagnose_DDNS() {
+	path := s.writeConfig("config.yaml", healthyConfig+"ddns:\n  consensus: 3\n  ipv4:\n    sources: [upnp, \"router\"]\n", 0600)
+
+	ddnsFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "ddns")
+	s.Require().Len(ddnsFindings, 2)
+	s.Require().Contains(ddnsFindings[0].Message, "router")
+	s.Require().Contains(ddnsFindings[1].Message, "only 2 sources")
+
+	path = s.writeConfig("config.yaml", healthyConfig+"ddns:\n  consensus: 2\n  ipv4:\n    sources: [upnp, \"iface:eth0\"]\n", 0600)
+	s.Require().Empty(s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "ddns"))
+}
+
+func (s *DoctorTestSuite) TestDiagnose_ChangeWindows() {
+	path := s.writeConfig("config.yaml", healthyConfig+"change_windows:\n  - name: prod\n    days: mon-thu\n    start: \"09:00\"\n    end: \"5pm\"\n", 0600)
+
+	windowFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "change windows")
+	s.Require().Len(windowFindings, 1)
+	s.Require().Equal(SeverityError, windowFindings[0].Severity)
+	s.Require().Contains(windowFindings[0].Message, "invalid end")
+
+	path = s.writeConfig("config.yaml", healthyConfig+"change_windows:\n  - days: mon-thu\n    start: \"09:00\"\n    end: \"17:00\"\n", 0600)
+	s.Require().Empty(s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "change windows"))
+}
+
+func (s *DoctorTestSuite) TestDiagnose_RateLimits() {
+	path := s.writeConfig("config.yaml", healthyConfig+"rate_limits:\n  namecheap: 30/min\n  cf-work: fast\n", 0600)
+
+	rateFindings := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "rate limits")
+	s.Require().Len(rateFindings, 1)
+	s.Require().Equal(SeverityError, rateFindings[0].Severity)
+	s.Require().Contains(rateFindings[0].Message, "cf-work")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_AccountProblems() {
+	content := `accounts:
+  ghost:
+  placeholder:
+    username: your-provider-username
+    api_user: your-api-username
+    api_key: your-api-key-here
+    client_ip: your.public.ip.address
+  one:
+    api_user: apiuser
+    api_key: samekey
+  two:
+    api_user: apiuser
+    api_key: samekey
+current_account: missing
+`
+	path := s.writeConfig("config.yaml", content, 0600)
+
+	accounts := s.findingsFor(Diagnose(DoctorOptions{ConfigPath: path}), "accounts")
+	messages := make([]string, 0, len(accounts))
+	for _, f := range accounts {
+		messages = append(messages, f.Message)
+	}
+
+	s.Require().Contains(messages, "account 'ghost' has no settings")
+	s.Require().Contains(messages, "account 'placeholder' still uses placeholder values for username, api_user, api_key, client_ip")
+	s.Require().Contains(messages, "accounts one, two share the same credentials")
+	s.Require().Contains(messages, "current account 'missing' does not exist")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_LegacyFieldsAndProviders() {
+	content := healthyConfig + `username: legacy
+api_key: legacykey
+`
+	path := s.writeConfig("config.yaml", content, 0600)
+
+	findings := Diagnose(DoctorOptions{ConfigPath: path, KnownProviders: []string{"cloudflare"}})
+
+	legacy := s.findingsFor(findings, "legacy fields")
+	s.Require().Len(legacy, 1)
+	s.Require().Contains(legacy[0].Message, "username, api_key")
+
+	providers := s.findingsFor(findings, "providers")
+	s.Require().Len(providers, 1)
+	s.Require().Contains(providers[0].Message, "'namecheap'")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_ProjectAndHomeConflict() {
+	project := s.writeConfig("project.yaml", healthyConfig, 0600)
+	home := s.writeConfig("home.yaml", healthyConfig, 0600)
+
+	conflicts := s.findingsFor(Diagnose(DoctorOptions{
+		ConfigPath:        project,
+		ProjectConfigPath: project,
+		HomeConfigPath:    home,
+	}), "conflicts")
+
+	s.Require().Len(conflicts, 1)
+	s.Require().Contains(conflicts[0].Message, home+" is ignored")
+}
+
+func (s *DoctorTestSuite) TestDiagnose_ProviderInstances() {
+	content := `current_account: work
+accounts:
+  work:
+    provider: cloudflare-work
+    username: user
+    api_user: user
+    api_key: key
+    client_ip: 192.0.2.1
+providers:
+  cloudflare-work:
+    type: cloudflare
+    credentials:
+      token: env:CF_WORK_TOKEN
+`
+	path := s.writeConfig("config.yaml", content, 0600)
+
+	findings := Diagnose(DoctorOptions{ConfigPath: path, KnownProviders: []string{"namecheap"}})
+	s.Require().Empty(s.findingsFor(findings, "providers"))
+}
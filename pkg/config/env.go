@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// accountEnvPrefix prefixes per-account environment overrides,
+// e.g. ZONEKIT_ACCOUNT_WORK_API_KEY patches api_key of the "work" account
+const accountEnvPrefix = "ZONEKIT_ACCOUNT_"
+
+// accountEnvField maps a config field to the setter used for environment overrides
+type accountEnvField struct {
+	name  string
+	apply func(account *AccountConfig, value string) error
+}
+
+var accountEnvFields = []accountEnvField{
+	{"provider", func(a *AccountConfig, v string) error { a.Provider = v; return nil }},
+	{"username", func(a *AccountConfig, v string) error { a.Username = v; return nil }},
+	{"api_user", func(a *AccountConfig, v string) error { a.APIUser = v; return nil }},
+	{"api_key", func(a *AccountConfig, v string) error { a.APIKey = v; return nil }},
+	{"client_ip", func(a *AccountConfig, v string) error { a.ClientIP = v; return nil }},
+	{"description", func(a *AccountConfig, v string) error { a.Description = v; return nil }},
+	{"use_sandbox", func(a *AccountConfig, v string) error { return parseEnvBool(v, &a.UseSandbox) }},
+	{"production_guard", func(a *AccountConfig, v string) error { return parseEnvBool(v, &a.ProductionGuard) }},
+}
+
+func parseEnvBool(value string, target *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("expected true or false, got %q", value)
+	}
+	*target = b
+	return nil
+}
+
+// AccountEnvVar returns the environment variable that overrides field of the named account.
+// Account names are upper-cased and non-alphanumeric characters become underscores.
+func AccountEnvVar(accountName, field string) string {
+	normalize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+				return r
+			default:
+				return '_'
+			}
+		}, s)
+	}
+	return accountEnvPrefix + normalize(accountName) + "_" + normalize(field)
+}
+
+// accountEnvOverrides returns the environment variables set for an account, keyed by field
+func accountEnvOverrides(accountName string) map[string]string {
+	overrides := make(map[string]string)
+	for _, f := range accountEnvFields {
+		name := AccountEnvVar(accountName, f.name)
+		if _, ok := os.LookupEnv(name); ok {
+			overrides[f.name] = name
+		}
+	}
+	return overrides
+}
+
+// applyEnvOverrides returns a copy of account patched with its environment overrides.
+// The stored account is left untouched so overrides are never written to the config file.
+func applyEnvOverrides(accountName string, account *AccountConfig) (*AccountConfig, error) {
+	overrides := accountEnvOverrides(accountName)
+	if len(overrides) == 0 {
+		return account, nil
+	}
+
+	patched := *account
+	for _, f := range accountEnvFields {
+		name, ok := overrides[f.name]
+		if !ok {
+			continue
+		}
+		if err := f.apply(&patched, os.Getenv(name)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return &patched, nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	"zonekit/pkg/secret"
+)
+
+// RedactedValue replaces secrets in exported accounts
+const RedactedValue = "REDACTED"
+
+// accountExport is the config file fragment written by ExportAccount
+type accountExport struct {
+	Accounts map[string]*AccountConfig `yaml:"accounts"`
+}
+
+// ExportAccount returns the named account as stored in the config file, as a
+// YAML fragment that can be merged into another config file. The API key is
+// replaced by RedactedValue unless withSecrets is set; secret references such
+// as "env:NC_API_KEY" hold no secret and are always kept.
+func (m *Manager) ExportAccount(name string, withSecrets bool) ([]byte, error) {
+	account, exists := m.config.Accounts[name]
+	if !exists || account == nil {
+		return nil, fmt.Errorf("account '%s' not found", name)
+	}
+
+	exported := *account
+	if !withSecrets && exported.APIKey != "" && !secret.IsReference(exported.APIKey) {
+		exported.APIKey = RedactedValue
+	}
+
+	data, err := yaml.Marshal(accountExport{Accounts: map[string]*AccountConfig{name: &exported}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal account: %w", err)
+	}
+	return data, nil
+}
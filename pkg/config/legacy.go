@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LegacyName is the name zonekit was released under while it only managed
+// Namecheap; its binary, config file and state directory were named after it
+const LegacyName = "namecheap-dns"
+
+// legacyConfigName is the config file name of LegacyName releases
+const legacyConfigName = "." + LegacyName + ".yaml"
+
+// IsLegacyBinary reports whether the binary at path runs under a LegacyName
+// name: namecheap-dns itself or one of the namecheap-dns-<os>-<arch> release
+// assets
+func IsLegacyBinary(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), ".exe")
+	return name == LegacyName || strings.HasPrefix(name, LegacyName+"-")
+}
+
+// Migration is a config file or directory moved from its legacy location
+type Migration struct {
+	From string
+	To   string
+}
+
+// MigrateLegacy moves the home config file and local state of LegacyName
+// releases to zonekit's locations: ~/.namecheap-dns.yaml to ~/.zonekit.yaml
+// and the ~/.namecheap-dns directory to ~/.zonekit. Nothing is moved where
+// zonekit already has a file, so it is safe to call on every run. The legacy
+// config file is kept with a .migrated suffix. Project config files are only
+// migrated with MigrateLegacyProjectConfig.
+func MigrateLegacy(home string) ([]Migration, error) {
+	var migrations []Migration
+	move := func(m *Migration, err error) error {
+		if m != nil {
+			migrations = append(migrations, *m)
+		}
+		return err
+	}
+
+	if home == "" {
+		return nil, nil
+	}
+	if err := move(migrateConfigFile(filepath.Join(home, legacyConfigName), filepath.Join(home, ".zonekit.yaml"))); err != nil {
+		return migrations, err
+	}
+	if err := move(migrateDir(filepath.Join(home, "."+LegacyName), filepath.Join(home, ".zonekit"))); err != nil {
+		return migrations, err
+	}
+	return migrations, nil
+}
+
+// MigrateLegacyProjectConfig copies a project's configs/.namecheap-dns.yaml
+// to configs/.zonekit.yaml beside it, unless that exists, and keeps the legacy
+// file with a .migrated suffix. It returns nil if nothing was migrated.
+func MigrateLegacyProjectConfig(legacy string) (*Migration, error) {
+	if filepath.Base(legacy) != legacyConfigName {
+		return nil, fmt.Errorf("%s is not a %s config file", legacy, LegacyName)
+	}
+	return migrateConfigFile(legacy, filepath.Join(filepath.Dir(legacy), ".zonekit.yaml"))
+}
+
+// migrateConfigFile copies a legacy config file to path unless path exists,
+// and renames the legacy file with a .migrated suffix
+func migrateConfigFile(legacy, path string) (*Migration, error) {
+	if !exists(legacy) || exists(path) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy config %s: %w", legacy, err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to migrate legacy config %s: %w", legacy, err)
+	}
+	if err := os.Rename(legacy, legacy+".migrated"); err != nil {
+		return nil, fmt.Errorf("failed to rename legacy config %s: %w", legacy, err)
+	}
+	return &Migration{From: legacy, To: path}, nil
+}
+
+// migrateDir renames a legacy directory to path unless path exists
+func migrateDir(legacy, path string) (*Migration, error) {
+	info, err := os.Stat(legacy)
+	if err != nil || !info.IsDir() || exists(path) {
+		return nil, nil
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", legacy, err)
+	}
+	return &Migration{From: legacy, To: path}, nil
+}
+
+// FindLegacyProjectConfig looks for a LegacyName config file in the configs
+// directory of the working directory or one of its parents, stopping at the
+// first configs directory holding a zonekit config
+func FindLegacyProjectConfig() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if exists(filepath.Join(cwd, "configs", ".zonekit.yaml")) {
+			return ""
+		}
+		legacy := filepath.Join(cwd, "configs", legacyConfigName)
+		if exists(legacy) {
+			return legacy
+		}
+		parent := filepath.Dir(cwd)
+		if parent == cwd {
+			return ""
+		}
+		cwd = parent
+	}
+}
+
+// exists reports whether a file or directory exists at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
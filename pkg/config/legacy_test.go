@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LegacyTestSuite is a test suite for migrating namecheap-dns installs
+type LegacyTestSuite struct {
+	suite.Suite
+	home    string
+	workDir string
+}
+
+// TestLegacySuite runs the legacy migration test suite
+func TestLegacySuite(t *testing.T) {
+	suite.Run(t, new(LegacyTestSuite))
+}
+
+func (s *LegacyTestSuite) SetupTest() {
+	s.home = s.T().TempDir()
+	s.workDir = s.T().TempDir()
+
+	wd, err := os.Getwd()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Chdir(s.workDir))
+	s.T().Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func (s *LegacyTestSuite) write(path, content string) {
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+}
+
+func (s *LegacyTestSuite) TestMigrateLegacy_MovesConfigAndState() {
+	s.write(filepath.Join(s.home, ".namecheap-dns.yaml"), "current_account: work\n")
+	s.write(filepath.Join(s.home, ".namecheap-dns", "state", "state.json"), "{}")
+	s.write(filepath.Join(s.workDir, "configs", ".namecheap-dns.yaml"), "current_account: project\n")
+
+	migrations, err := MigrateLegacy(s.home)
+	s.Require().NoError(err)
+	s.Require().Len(migrations, 2)
+
+	data, err := os.ReadFile(filepath.Join(s.home, ".zonekit.yaml"))
+	s.Require().NoError(err)
+	s.Require().Equal("current_account: work\n", string(data))
+	s.Require().FileExists(filepath.Join(s.home, ".namecheap-dns.yaml.migrated"))
+	s.Require().NoFileExists(filepath.Join(s.home, ".namecheap-dns.yaml"))
+	s.Require().FileExists(filepath.Join(s.home, ".zonekit", "state", "state.json"))
+
+	// Project configs are left alone
+	s.Require().NoFileExists(filepath.Join(s.workDir, "configs", ".zonekit.yaml"))
+	s.Require().FileExists(filepath.Join(s.workDir, "configs", ".namecheap-dns.yaml"))
+
+	// A second run has nothing left to move
+	migrations, err = MigrateLegacy(s.home)
+	s.Require().NoError(err)
+	s.Require().Empty(migrations)
+}
+
+func (s *LegacyTestSuite) TestMigrateLegacyProjectConfig() {
+	legacy := filepath.Join(s.workDir, "configs", ".namecheap-dns.yaml")
+	s.write(legacy, "current_account: project\n")
+
+	found, err := filepath.EvalSymlinks(FindLegacyProjectConfig())
+	s.Require().NoError(err)
+	expected, err := filepath.EvalSymlinks(legacy)
+	s.Require().NoError(err)
+	s.Require().Equal(expected, found)
+
+	migration, err := MigrateLegacyProjectConfig(legacy)
+	s.Require().NoError(err)
+	s.Require().NotNil(migration)
+	data, err := os.ReadFile(filepath.Join(s.workDir, "configs", ".zonekit.yaml"))
+	s.Require().NoError(err)
+	s.Require().Equal("current_account: project\n", string(data))
+	s.Require().FileExists(legacy + ".migrated")
+	s.Require().Empty(FindLegacyProjectConfig())
+
+	_, err = MigrateLegacyProjectConfig(filepath.Join(s.workDir, "configs", ".zonekit.yaml"))
+	s.Require().Error(err, "only namecheap-dns config files are migrated")
+}
+
+func (s *LegacyTestSuite) TestResolveConfigPath_SelectsLegacyProjectConfig() {
+	s.T().Setenv(ConfigEnvVar, "")
+	s.T().Setenv("HOME", s.home)
+	legacy := filepath.Join(s.workDir, "configs", ".namecheap-dns.yaml")
+	s.write(legacy, "current_account: project\n")
+
+	resolution := ResolveConfigPath("")
+	s.Require().True(resolution.Legacy)
+	s.Require().Equal(SourceProject, resolution.Source)
+	s.Require().Equal(".namecheap-dns.yaml", filepath.Base(resolution.Path))
+
+	// An explicit config file wins, and the legacy one is not selected
+	explicit := filepath.Join(s.home, "other.yaml")
+	resolution = ResolveConfigPath(explicit)
+	s.Require().False(resolution.Legacy)
+	s.Require().Equal(explicit, resolution.Path)
+
+	// A zonekit project config takes precedence over a legacy one
+	s.write(filepath.Join(s.workDir, "configs", ".zonekit.yaml"), "current_account: new\n")
+	resolution = ResolveConfigPath("")
+	s.Require().False(resolution.Legacy)
+	s.Require().Equal(".zonekit.yaml", filepath.Base(resolution.Path))
+}
+
+func (s *LegacyTestSuite) TestMigrateLegacy_KeepsZonekitFiles() {
+	s.write(filepath.Join(s.home, ".namecheap-dns.yaml"), "current_account: old\n")
+	s.write(filepath.Join(s.home, ".zonekit.yaml"), "current_account: new\n")
+	s.write(filepath.Join(s.home, ".namecheap-dns", "state", "state.json"), "{}")
+	s.write(filepath.Join(s.home, ".zonekit", "state", "state.json"), "{}")
+
+	migrations, err := MigrateLegacy(s.home)
+	s.Require().NoError(err)
+	s.Require().Empty(migrations)
+
+	data, err := os.ReadFile(filepath.Join(s.home, ".zonekit.yaml"))
+	s.Require().NoError(err)
+	s.Require().Equal("current_account: new\n", string(data))
+	s.Require().FileExists(filepath.Join(s.home, ".namecheap-dns.yaml"))
+}
+
+func (s *LegacyTestSuite) TestIsLegacyBinary() {
+	for _, path := range []string{"namecheap-dns", "/usr/local/bin/namecheap-dns", "namecheap-dns-linux-amd64", "namecheap-dns-windows-amd64.exe"} {
+		s.True(IsLegacyBinary(path), path)
+	}
+	for _, path := range []string{"zonekit", "/usr/local/bin/zonekit-linux-amd64", "namecheap-dnsx"} {
+		s.False(IsLegacyBinary(path), path)
+	}
+}
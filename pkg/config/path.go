@@ -5,6 +5,92 @@ import (
 	"path/filepath"
 )
 
+// ConfigEnvVar is the environment variable that selects a config file
+const ConfigEnvVar = "ZONEKIT_CONFIG"
+
+// ConfigSource identifies where a config file location came from
+type ConfigSource string
+
+const (
+	SourceFlag    ConfigSource = "--config flag"
+	SourceEnv     ConfigSource = ConfigEnvVar + " environment variable"
+	SourceProject ConfigSource = "project directory"
+	SourceHome    ConfigSource = "home directory"
+)
+
+// ConfigCandidate is a config file location considered while resolving the config path
+type ConfigCandidate struct {
+	Source ConfigSource
+	// Path is empty when the source did not provide a location
+	Path     string
+	Exists   bool
+	Selected bool
+	// Legacy marks a project config file of LegacyName releases
+	Legacy bool
+}
+
+// ConfigResolution describes which config file is used and why
+type ConfigResolution struct {
+	Path   string
+	Source ConfigSource
+	// Legacy is set when Path is a project config file of LegacyName releases,
+	// which should be migrated with MigrateLegacyProjectConfig before use
+	Legacy     bool
+	Candidates []ConfigCandidate
+}
+
+// ResolveConfigPath picks the config file using the documented precedence:
+//
+//  1. the --config flag (flagPath)
+//  2. the ZONEKIT_CONFIG environment variable
+//  3. configs/.zonekit.yaml in the working directory or one of its parents, or
+//     else a configs/.namecheap-dns.yaml left by LegacyName releases
+//  4. ~/.zonekit.yaml
+//
+// Explicit locations (1 and 2) win even if the file does not exist yet, so it is
+// created there on first save. The home directory path is used as the final fallback.
+func ResolveConfigPath(flagPath string) *ConfigResolution {
+	resolution := &ConfigResolution{}
+
+	consider := func(source ConfigSource, path string, explicit, legacy bool) {
+		candidate := ConfigCandidate{Source: source, Path: path, Legacy: legacy}
+		if path != "" {
+			if _, err := os.Stat(path); err == nil {
+				candidate.Exists = true
+			}
+		}
+		if resolution.Path == "" && path != "" && (explicit || candidate.Exists) {
+			candidate.Selected = true
+			resolution.Path = path
+			resolution.Source = source
+			resolution.Legacy = legacy
+		}
+		resolution.Candidates = append(resolution.Candidates, candidate)
+	}
+
+	consider(SourceFlag, flagPath, true, false)
+	consider(SourceEnv, os.Getenv(ConfigEnvVar), true, false)
+	if project := FindProjectConfigPath(); project != "" {
+		consider(SourceProject, project, false, false)
+	} else {
+		legacy := FindLegacyProjectConfig()
+		consider(SourceProject, legacy, false, legacy != "")
+	}
+	consider(SourceHome, findHomeConfigPath(), false, false)
+
+	// Fall back to the home path even if it does not exist yet
+	if resolution.Path == "" {
+		last := &resolution.Candidates[len(resolution.Candidates)-1]
+		if last.Path != "" {
+			last.Selected = true
+			resolution.Path = last.Path
+			resolution.Source = last.Source
+		}
+	}
+
+	return resolution
+}
+
 // FindProjectConfigPath looks for config file in the project directory
 func FindProjectConfigPath() string {
 	// Get current working directory
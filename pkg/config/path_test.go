@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// PathTestSuite is a test suite for config path resolution
+type PathTestSuite struct {
+	suite.Suite
+	home    string
+	workDir string
+}
+
+// TestPathSuite runs the config path test suite
+func TestPathSuite(t *testing.T) {
+	suite.Run(t, new(PathTestSuite))
+}
+
+func (s *PathTestSuite) SetupTest() {
+	s.home = s.T().TempDir()
+	s.workDir = s.T().TempDir()
+	s.T().Setenv("HOME", s.home)
+	s.T().Setenv(ConfigEnvVar, "")
+
+	wd, err := os.Getwd()
+	s.Require().NoError(err)
+	s.Require().NoError(os.Chdir(s.workDir))
+	s.T().Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func (s *PathTestSuite) touch(path string) {
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, []byte("accounts: {}\n"), 0600))
+}
+
+func (s *PathTestSuite) TestResolveConfigPath_FallsBackToHome() {
+	resolution := ResolveConfigPath("")
+
+	s.Require().Equal(filepath.Join(s.home, ".zonekit.yaml"), resolution.Path)
+	s.Require().Equal(SourceHome, resolution.Source)
+	s.Require().Len(resolution.Candidates, 4)
+	s.Require().True(resolution.Candidates[3].Selected)
+	s.Require().False(resolution.Candidates[3].Exists)
+}
+
+func (s *PathTestSuite) TestResolveConfigPath_ProjectBeatsHome() {
+	s.touch(filepath.Join(s.home, ".zonekit.yaml"))
+	project := filepath.Join(s.workDir, "configs", ".zonekit.yaml")
+	s.touch(project)
+
+	resolution := ResolveConfigPath("")
+
+	s.Require().Equal(SourceProject, resolution.Source)
+	s.Require().Equal(filepath.Base(project), filepath.Base(resolution.Path))
+	s.Require().False(resolution.Candidates[3].Selected)
+	s.Require().True(resolution.Candidates[3].Exists)
+}
+
+func (s *PathTestSuite) TestResolveConfigPath_EnvBeatsProject() {
+	s.touch(filepath.Join(s.workDir, "configs", ".zonekit.yaml"))
+	envPath := filepath.Join(s.workDir, "env.yaml")
+	s.T().Setenv(ConfigEnvVar, envPath)
+
+	resolution := ResolveConfigPath("")
+
+	s.Require().Equal(envPath, resolution.Path)
+	s.Require().Equal(SourceEnv, resolution.Source)
+}
+
+func (s *PathTestSuite) TestResolveConfigPath_FlagBeatsEverything() {
+	s.T().Setenv(ConfigEnvVar, filepath.Join(s.workDir, "env.yaml"))
+	flagPath := filepath.Join(s.workDir, "flag.yaml")
+
+	resolution := ResolveConfigPath(flagPath)
+
+	s.Require().Equal(flagPath, resolution.Path)
+	s.Require().Equal(SourceFlag, resolution.Source)
+	s.Require().True(resolution.Candidates[0].Selected)
+	s.Require().False(resolution.Candidates[1].Selected)
+}
+
+func (s *PathTestSuite) TestAccountSources() {
+	path := filepath.Join(s.workDir, "config.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte(`accounts:
+  default:
+    username: user
+    api_user: apiuser
+    api_key: key1234567890
+    client_ip: 203.0.113.10
+current_account: default
+`), 0600))
+
+	manager, err := NewManagerWithPath(path)
+	s.Require().NoError(err)
+
+	sources, err := manager.AccountSources("default")
+	s.Require().NoError(err)
+
+	byField := make(map[string]ValueSource)
+	for _, v := range sources {
+		byField[v.Field] = v
+	}
+	s.Require().Equal("default", byField["provider"].Source)
+	s.Require().Equal("namecheap", byField["provider"].Value)
+	s.Require().Contains(byField["username"].Source, path)
+	s.Require().Equal("key1***7890", byField["api_key"].Value)
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/secret"
+)
+
+// ResolveSecrets returns a copy of the account with secret references
+// (e.g. "vault:secret/dns#api_key" or "op://vault/item/field") replaced by
+// their values. The receiver is not modified, so resolved secrets are never saved.
+func (a *AccountConfig) ResolveSecrets() (*AccountConfig, error) {
+	resolved := *a
+
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"username", &resolved.Username},
+		{"api_user", &resolved.APIUser},
+		{"api_key", &resolved.APIKey},
+		{"client_ip", &resolved.ClientIP},
+	}
+
+	for _, f := range fields {
+		value, err := secret.Resolve(*f.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.value = value
+	}
+
+	return &resolved, nil
+}
+
+// ResolveCredentials returns the provider credentials with secret references
+// replaced by their values. The receiver is not modified.
+func (p *ProviderConfig) ResolveCredentials() (map[string]string, error) {
+	resolved := make(map[string]string, len(p.Credentials))
+	for key, value := range p.Credentials {
+		v, err := secret.Resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		resolved[key] = v
+	}
+	return resolved, nil
+}
+
+// RESTConfig returns the provider configuration of a "rest" instance named
+// name, for the generic REST adapter. Secret references in credentials and
+// headers are replaced by their values.
+func (p *ProviderConfig) RESTConfig(name string) (*dnsprovider.Config, error) {
+	if p.Type != ProviderTypeREST {
+		return nil, fmt.Errorf("provider %s has type %q, not %q", name, p.Type, ProviderTypeREST)
+	}
+
+	credentials, err := p.ResolveCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &dnsprovider.Config{
+		Name:     name,
+		Type:     ProviderTypeREST,
+		Settings: p.Settings,
+		Mappings: p.Mappings,
+	}
+	cfg.Auth.Method = p.Auth
+	cfg.Auth.Credentials = make(map[string]interface{}, len(credentials))
+	for key, value := range credentials {
+		cfg.Auth.Credentials[key] = value
+	}
+
+	cfg.API.BaseURL = p.BaseURL
+	cfg.API.Endpoints = p.Endpoints
+	cfg.API.Timeout = p.Timeout
+	cfg.API.Retries = p.Retries
+	cfg.API.Headers = make(map[string]string, len(p.Headers))
+	for key, value := range p.Headers {
+		v, err := secret.Resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", key, err)
+		}
+		cfg.API.Headers[key] = v
+	}
+
+	return cfg, nil
+}
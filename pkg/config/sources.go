@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ValueSource records the effective value of an account setting and where it came from
+type ValueSource struct {
+	Field  string
+	Value  string
+	Source string
+}
+
+// AccountSources reports the effective value and origin of each setting of an account,
+// including environment overrides. API keys are masked.
+func (m *Manager) AccountSources(name string) ([]ValueSource, error) {
+	account, err := m.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFile := fmt.Sprintf("config file (%s)", m.configPath)
+	if !m.loaded {
+		fromFile = "built-in default (config file not created yet)"
+	}
+	overrides := accountEnvOverrides(name)
+	source := func(field, value string, set bool) ValueSource {
+		if env, ok := overrides[field]; ok {
+			return ValueSource{Field: field, Value: value, Source: fmt.Sprintf("environment (%s)", env)}
+		}
+		if !set {
+			return ValueSource{Field: field, Value: value, Source: "default"}
+		}
+		return ValueSource{Field: field, Value: value, Source: fromFile}
+	}
+
+	return []ValueSource{
+		source("provider", account.GetProvider(), account.Provider != ""),
+		source("username", account.Username, account.Username != ""),
+		source("api_user", account.APIUser, account.APIUser != ""),
+		source("api_key", MaskAPIKey(account.APIKey), account.APIKey != ""),
+		source("client_ip", account.ClientIP, account.ClientIP != ""),
+		source("use_sandbox", strconv.FormatBool(account.UseSandbox), account.UseSandbox),
+		source("production_guard", strconv.FormatBool(account.ProductionGuard), account.ProductionGuard),
+	}, nil
+}
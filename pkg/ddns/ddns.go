@@ -0,0 +1,207 @@
+// Package ddns keeps a host's A and AAAA records pointed at this machine's public
+// address: it detects the address and updates the record through any DNS
+// service, using a provider's single-call address update where available.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// DefaultSources are HTTPS endpoints that answer with the caller's public address
+// as plain text, tried in order
+var DefaultSources = map[string][]string{
+	dnsrecord.RecordTypeA:    {"https://api.ipify.org", "https://ipv4.icanhazip.com"},
+	dnsrecord.RecordTypeAAAA: {"https://api6.ipify.org", "https://ipv6.icanhazip.com"},
+}
+
+// DefaultTimeout bounds each address detection request
+const DefaultTimeout = 10 * time.Second
+
+// Detector finds the public address of this machine
+type Detector struct {
+	// Sources maps a record type to the specs of the sources queried for it, in
+	// order (see Source)
+	Sources map[string][]string
+	// Consensus is how many sources must report the same address before it is
+	// used; 0 and 1 use the first answer
+	Consensus int
+	// PreferIPv6Privacy publishes a temporary IPv6 privacy address. By default
+	// a temporary address of this machine, which HTTPS sources usually see, is
+	// replaced with the stable address of the same interface and prefix.
+	PreferIPv6Privacy bool
+	HTTPClient        *http.Client
+}
+
+// NewDetector creates a detector using DefaultSources
+func NewDetector() *Detector {
+	return &Detector{
+		Sources:    DefaultSources,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Detect returns the public IPv4 (A) or IPv6 (AAAA) address. Sources are tried in
+// order until one answers with an address of the requested family or, with
+// Consensus set, until that many sources have answered with the same address.
+// Answers of sources that fail or disagree are passed over.
+func (d *Detector) Detect(ctx context.Context, recordType string) (string, error) {
+	recordType = strings.ToUpper(recordType)
+	sources, err := d.sources(recordType)
+	if err != nil {
+		return "", err
+	}
+
+	needed := d.Consensus
+	if needed < 1 {
+		needed = 1
+	}
+	if needed > len(sources) {
+		return "", fmt.Errorf("a consensus of %d sources needs at least %d %s sources, %d configured", needed, needed, recordType, len(sources))
+	}
+
+	var errs, answers []string
+	votes := make(map[string]int)
+	for _, source := range sources {
+		address, err := source.Address(ctx, recordType)
+		if err == nil && !MatchesFamily(address, recordType) {
+			err = fmt.Errorf("unexpected answer %q", address)
+		}
+		if err == nil && recordType == dnsrecord.RecordTypeAAAA && !d.PreferIPv6Privacy {
+			address = stableIPv6(address)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", source, err))
+			continue
+		}
+
+		votes[address]++
+		if votes[address] >= needed {
+			return address, nil
+		}
+		answers = append(answers, fmt.Sprintf("%s from %s", address, source))
+	}
+
+	if len(answers) > 0 {
+		errs = append([]string{fmt.Sprintf("no %d sources agreed (%s)", needed, strings.Join(answers, ", "))}, errs...)
+	}
+	return "", fmt.Errorf("failed to detect public address: %s", strings.Join(errs, "; "))
+}
+
+// sources parses the source specs for a record type. HTTP and UPnP sources use
+// the detector's HTTP client.
+func (d *Detector) sources(recordType string) ([]Source, error) {
+	specs := d.Sources[recordType]
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no address sources configured for %s records", recordType)
+	}
+
+	sources := make([]Source, 0, len(specs))
+	for _, spec := range specs {
+		source, err := ParseSource(spec)
+		if err != nil {
+			return nil, err
+		}
+		switch s := source.(type) {
+		case *HTTPSource:
+			s.HTTPClient = d.HTTPClient
+		case *UPnPSource:
+			s.HTTPClient = d.HTTPClient
+		case *InterfaceSource:
+			s.PreferPrivacy = d.PreferIPv6Privacy
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// MatchesFamily reports whether address is an IPv4 address for A records or an
+// IPv6 address for AAAA records
+func MatchesFamily(address, recordType string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	switch strings.ToUpper(recordType) {
+	case dnsrecord.RecordTypeA:
+		return ip.To4() != nil
+	case dnsrecord.RecordTypeAAAA:
+		return ip.To4() == nil
+	}
+	return false
+}
+
+// Target is the DNS service whose records are updated; *dns.Service implements it
+type Target interface {
+	UpdateAddress(domainName, hostname, recordType, address string) (bool, error)
+}
+
+// Request describes one dynamic update
+type Request struct {
+	Domain     string
+	Host       string
+	RecordType string
+	// Address is the address to publish; when empty it is detected
+	Address string
+}
+
+// Result is the outcome of an update
+type Result struct {
+	Request
+	Changed bool
+}
+
+// Update points the requested record at the given or detected address
+func Update(ctx context.Context, target Target, detector *Detector, req Request) (*Result, error) {
+	req.RecordType = strings.ToUpper(req.RecordType)
+	if req.RecordType == "" {
+		req.RecordType = dnsrecord.RecordTypeA
+	}
+	if req.Host == "" {
+		req.Host = "@"
+	}
+
+	if req.Address == "" {
+		address, err := detector.Detect(ctx, req.RecordType)
+		if err != nil {
+			return nil, err
+		}
+		req.Address = address
+	} else if !MatchesFamily(req.Address, req.RecordType) {
+		return nil, fmt.Errorf("'%s' is not a valid address for %s records", req.Address, req.RecordType)
+	}
+
+	changed, err := target.UpdateAddress(req.Domain, req.Host, req.RecordType, req.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s record: %w", req.RecordType, err)
+	}
+
+	return &Result{Request: req, Changed: changed}, nil
+}
+
+// UpdateAll updates the record of each of recordTypes, e.g. both A and AAAA of a
+// dual-stack host, in one cycle. req.Address, when set, must match every record
+// type. A failed update does not stop the others: the results of the updates
+// that succeeded are returned together with the joined errors of the rest.
+func UpdateAll(ctx context.Context, target Target, detector *Detector, req Request, recordTypes []string) ([]*Result, error) {
+	var results []*Result
+	var errs []error
+	for _, recordType := range recordTypes {
+		typed := req
+		typed.RecordType = recordType
+		result, err := Update(ctx, target, detector, typed)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", strings.ToUpper(recordType), err))
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package ddns
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeTarget records address updates
+type fakeTarget struct {
+	updates []string
+	changed bool
+}
+
+func (f *fakeTarget) UpdateAddress(domainName, hostname, recordType, address string) (bool, error) {
+	f.updates = append(f.updates, domainName+" "+hostname+" "+recordType+" "+address)
+	return f.changed, nil
+}
+
+// DDNSTestSuite tests address detection and updates
+type DDNSTestSuite struct {
+	suite.Suite
+}
+
+func TestDDNSSuite(t *testing.T) {
+	suite.Run(t, new(DDNSTestSuite))
+}
+
+func (s *DDNSTestSuite) TestDetect_FallsBackToNextSource() {
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+	wrongFamily := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("2001:db8::1\n"))
+	}))
+	defer wrongFamily.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("198.51.100.7\n"))
+	}))
+	defer working.Close()
+
+	d := NewDetector()
+	d.Sources = map[string][]string{"A": {broken.URL, wrongFamily.URL, working.URL}}
+
+	address, err := d.Detect(context.Background(), "a")
+	s.Require().NoError(err)
+	s.Require().Equal("198.51.100.7", address)
+
+	_, err = d.Detect(context.Background(), "AAAA")
+	s.Require().Error(err)
+}
+
+func (s *DDNSTestSuite) TestUpdate() {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("198.51.100.7"))
+	}))
+	defer source.Close()
+
+	d := NewDetector()
+	d.Sources = map[string][]string{"A": {source.URL}}
+	target := &fakeTarget{changed: true}
+
+	result, err := Update(context.Background(), target, d, Request{Domain: "example.com"})
+	s.Require().NoError(err)
+	s.Require().True(result.Changed)
+	s.Require().Equal("198.51.100.7", result.Address)
+	s.Require().Equal([]string{"example.com @ A 198.51.100.7"}, target.updates)
+
+	// An explicit address skips detection but must match the record type
+	_, err = Update(context.Background(), target, d, Request{Domain: "example.com", Host: "vpn", RecordType: "aaaa", Address: "2001:db8::1"})
+	s.Require().NoError(err)
+	s.Require().Equal("example.com vpn AAAA 2001:db8::1", target.updates[1])
+
+	_, err = Update(context.Background(), target, d, Request{Domain: "example.com", RecordType: "AAAA", Address: "198.51.100.7"})
+	s.Require().Error(err)
+}
+
+func (s *DDNSTestSuite) TestMatchesFamily() {
+	s.Require().True(MatchesFamily("192.0.2.1", "A"))
+	s.Require().False(MatchesFamily("192.0.2.1", "AAAA"))
+	s.Require().True(MatchesFamily("2001:db8::1", "aaaa"))
+	s.Require().False(MatchesFamily("not-an-ip", "A"))
+	s.Require().False(MatchesFamily("192.0.2.1", "MX"))
+}
@@ -0,0 +1,165 @@
+package ddns
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Address flags of /proc/net/if_inet6 (IFA_F_* in linux/if_addr.h)
+const (
+	ifaTemporary  = 0x01
+	ifaDeprecated = 0x20
+)
+
+// localIPv6 is an IPv6 address assigned to this machine
+type localIPv6 struct {
+	IP        net.IP
+	Interface string
+	PrefixLen int
+	// Temporary marks a privacy address (RFC 8981), which the system rotates
+	// and prefers for outgoing connections
+	Temporary bool
+	// Deprecated marks an address whose preferred lifetime has ended
+	Deprecated bool
+}
+
+// localIPv6Addrs returns the IPv6 addresses of this machine; tests replace it
+var localIPv6Addrs = readLocalIPv6
+
+// readLocalIPv6 reads the addresses with their flags from /proc/net/if_inet6.
+// Elsewhere it lists the interface addresses, which do not tell temporary
+// addresses apart.
+func readLocalIPv6() ([]localIPv6, error) {
+	f, err := os.Open("/proc/net/if_inet6")
+	if err != nil {
+		return listLocalIPv6()
+	}
+	defer f.Close()
+
+	var addrs []localIPv6
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		ip, err := hex.DecodeString(fields[0])
+		if err != nil || len(ip) != net.IPv6len {
+			continue
+		}
+		prefixLen, _ := strconv.ParseUint(fields[2], 16, 8)
+		flags, _ := strconv.ParseUint(fields[4], 16, 32)
+		addrs = append(addrs, localIPv6{
+			IP:         net.IP(ip),
+			Interface:  fields[5],
+			PrefixLen:  int(prefixLen),
+			Temporary:  flags&ifaTemporary != 0,
+			Deprecated: flags&ifaDeprecated != 0,
+		})
+	}
+	return addrs, scanner.Err()
+}
+
+// listLocalIPv6 lists the IPv6 addresses of all interfaces
+func listLocalIPv6() ([]localIPv6, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []localIPv6
+	for _, iface := range ifaces {
+		ifaceAddrs, err := interfaceAddrs(iface.Name)
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() != nil {
+				continue
+			}
+			prefixLen, _ := ipNet.Mask.Size()
+			addrs = append(addrs, localIPv6{IP: ipNet.IP, Interface: iface.Name, PrefixLen: prefixLen})
+		}
+	}
+	return addrs, nil
+}
+
+// publicIPv6 reports whether ip can be published: a global address that is not a
+// unique local (fc00::/7) one
+func publicIPv6(ip net.IP) bool {
+	return ip.To4() == nil && ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// pickIPv6 returns the address to publish from addrs: a stable address, or with
+// preferPrivacy a temporary one, preferring addresses that are not deprecated.
+// It returns nil when addrs has no public address.
+func pickIPv6(addrs []localIPv6, preferPrivacy bool) net.IP {
+	var best *localIPv6
+	rank := func(a *localIPv6) int {
+		r := 0
+		if !a.Deprecated {
+			r += 2
+		}
+		if a.Temporary == preferPrivacy {
+			r++
+		}
+		return r
+	}
+	for i := range addrs {
+		a := &addrs[i]
+		if !publicIPv6(a.IP) {
+			continue
+		}
+		if best == nil || rank(a) > rank(best) {
+			best = a
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.IP
+}
+
+// stableIPv6 replaces a temporary address of this machine with the stable
+// address of the same interface and prefix. Other addresses, such as those of
+// another host or of a network prefix translator, are returned unchanged.
+func stableIPv6(address string) string {
+	ip := net.ParseIP(address)
+	addrs, err := localIPv6Addrs()
+	if ip == nil || err != nil {
+		return address
+	}
+
+	var self *localIPv6
+	for i := range addrs {
+		if addrs[i].IP.Equal(ip) {
+			self = &addrs[i]
+			break
+		}
+	}
+	if self == nil || !self.Temporary {
+		return address
+	}
+
+	prefixLen := self.PrefixLen
+	if prefixLen <= 0 || prefixLen > 128 {
+		prefixLen = 64
+	}
+	prefix := net.IPNet{IP: ip.Mask(net.CIDRMask(prefixLen, 128)), Mask: net.CIDRMask(prefixLen, 128)}
+
+	var siblings []localIPv6
+	for _, a := range addrs {
+		if a.Interface == self.Interface && !a.Temporary && prefix.Contains(a.IP) {
+			siblings = append(siblings, a)
+		}
+	}
+	if stable := pickIPv6(siblings, false); stable != nil {
+		return stable.String()
+	}
+	return address
+}
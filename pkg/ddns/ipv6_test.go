@@ -0,0 +1,71 @@
+package ddns
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// dualStackAddrs are the addresses of a host with a stable and a temporary
+// address in one prefix
+func dualStackAddrs() ([]localIPv6, error) {
+	return []localIPv6{
+		{IP: net.ParseIP("2001:db8::a1"), Interface: "eth0", PrefixLen: 64, Temporary: true},
+		{IP: net.ParseIP("2001:db8::a0"), Interface: "eth0", PrefixLen: 64, Temporary: true, Deprecated: true},
+		{IP: net.ParseIP("2001:db8::1:2ff:fe03:405"), Interface: "eth0", PrefixLen: 64},
+		{IP: net.ParseIP("2001:db8:1::5"), Interface: "eth1", PrefixLen: 64},
+	}, nil
+}
+
+func (s *DDNSTestSuite) TestPickIPv6() {
+	addrs, _ := dualStackAddrs()
+	s.Require().Equal("2001:db8::1:2ff:fe03:405", pickIPv6(addrs[:3], false).String())
+	s.Require().Equal("2001:db8::a1", pickIPv6(addrs[:3], true).String())
+	s.Require().Nil(pickIPv6([]localIPv6{{IP: net.ParseIP("fd00::1")}, {IP: net.ParseIP("fe80::1")}}, false))
+}
+
+func (s *DDNSTestSuite) TestStableIPv6() {
+	defer func(orig func() ([]localIPv6, error)) { localIPv6Addrs = orig }(localIPv6Addrs)
+	localIPv6Addrs = dualStackAddrs
+
+	// A temporary address is replaced with the stable address of its interface
+	s.Require().Equal("2001:db8::1:2ff:fe03:405", stableIPv6("2001:db8::a1"))
+	// Stable addresses and addresses of other hosts are kept
+	s.Require().Equal("2001:db8:1::5", stableIPv6("2001:db8:1::5"))
+	s.Require().Equal("2001:db8:ffff::9", stableIPv6("2001:db8:ffff::9"))
+}
+
+func (s *DDNSTestSuite) TestDetect_PrefersStableIPv6() {
+	defer func(orig func() ([]localIPv6, error)) { localIPv6Addrs = orig }(localIPv6Addrs)
+	localIPv6Addrs = dualStackAddrs
+
+	source := textServer("2001:db8::a1")
+	defer source.Close()
+
+	d := NewDetector()
+	d.Sources = map[string][]string{"AAAA": {source.URL}}
+	address, err := d.Detect(context.Background(), "AAAA")
+	s.Require().NoError(err)
+	s.Require().Equal("2001:db8::1:2ff:fe03:405", address)
+
+	d.PreferIPv6Privacy = true
+	address, err = d.Detect(context.Background(), "AAAA")
+	s.Require().NoError(err)
+	s.Require().Equal("2001:db8::a1", address)
+}
+
+func (s *DDNSTestSuite) TestUpdateAll_DualStack() {
+	v4 := textServer("198.51.100.7")
+	defer v4.Close()
+
+	d := NewDetector()
+	d.Sources = map[string][]string{"A": {v4.URL}, "AAAA": {"iface:missing0"}}
+	target := &fakeTarget{changed: true}
+
+	// The AAAA failure is reported without stopping the A update
+	results, err := UpdateAll(context.Background(), target, d, Request{Domain: "example.com", Host: "home"}, []string{"A", "AAAA"})
+	s.Require().Error(err)
+	s.Require().True(strings.HasPrefix(err.Error(), "AAAA: "))
+	s.Require().Len(results, 1)
+	s.Require().Equal([]string{"example.com home A 198.51.100.7"}, target.updates)
+}
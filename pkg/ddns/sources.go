@@ -0,0 +1,381 @@
+package ddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// Source answers with this machine's public address. Sources are written as
+// specs in configuration and flags:
+//
+//	https://api.ipify.org   an HTTPS endpoint answering with the address as plain text
+//	iface:eth0              the first global address of a local network interface
+//	upnp                    the external address of the router, asked over UPnP IGD
+//	upnp:<url>              the same, with the router's device description URL
+//	exec:<command>          the first line a shell command prints
+type Source interface {
+	// Address returns the address for A (IPv4) or AAAA (IPv6) records
+	Address(ctx context.Context, recordType string) (string, error)
+	// String returns the source's spec
+	String() string
+}
+
+// ParseSource parses a source spec
+func ParseSource(spec string) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	kind, value, _ := strings.Cut(spec, ":")
+	switch strings.ToLower(kind) {
+	case "http", "https":
+		if _, err := url.ParseRequestURI(spec); err != nil {
+			return nil, fmt.Errorf("invalid address source %q: %w", spec, err)
+		}
+		return &HTTPSource{URL: spec}, nil
+	case "iface", "interface":
+		if value == "" {
+			return nil, fmt.Errorf("invalid address source %q: interface name is missing", spec)
+		}
+		return &InterfaceSource{Interface: value}, nil
+	case "upnp":
+		if value != "" {
+			if _, err := url.ParseRequestURI(value); err != nil {
+				return nil, fmt.Errorf("invalid address source %q: %w", spec, err)
+			}
+		}
+		return &UPnPSource{Location: value}, nil
+	case "exec":
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid address source %q: command is missing", spec)
+		}
+		return &CommandSource{Command: value}, nil
+	}
+	return nil, fmt.Errorf("unknown address source %q: use an https:// URL, iface:<name>, upnp or exec:<command>", spec)
+}
+
+// HTTPSource asks an HTTPS endpoint that answers with the caller's address as
+// plain text
+type HTTPSource struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// Address queries the endpoint
+func (s *HTTPSource) Address(ctx context.Context, recordType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient(s.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (s *HTTPSource) String() string { return s.URL }
+
+// interfaceAddrs returns the addresses of a network interface; tests replace it
+var interfaceAddrs = func(name string) ([]net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return iface.Addrs()
+}
+
+// InterfaceSource reads the address of a local network interface, for hosts
+// that hold the public address themselves, such as a router or a host with a
+// routed IPv6 prefix. Loopback and link-local addresses are ignored, and so are
+// unique local IPv6 addresses.
+type InterfaceSource struct {
+	Interface string
+	// PreferPrivacy picks a temporary IPv6 privacy address over the stable one
+	PreferPrivacy bool
+}
+
+// Address returns the interface's first global IPv4 address for A records, or
+// its stable (with PreferPrivacy, temporary) global IPv6 address for AAAA records
+func (s *InterfaceSource) Address(ctx context.Context, recordType string) (string, error) {
+	if strings.ToUpper(recordType) == dnsrecord.RecordTypeAAAA {
+		return s.ipv6Address()
+	}
+
+	addrs, err := interfaceAddrs(s.Interface)
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		var ip net.IP
+		switch a := addr.(type) {
+		case *net.IPNet:
+			ip = a.IP
+		case *net.IPAddr:
+			ip = a.IP
+		}
+		if ip == nil || !ip.IsGlobalUnicast() {
+			continue
+		}
+		if MatchesFamily(ip.String(), recordType) {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("interface %s has no global %s address", s.Interface, familyName(recordType))
+}
+
+// ipv6Address picks the interface's IPv6 address to publish
+func (s *InterfaceSource) ipv6Address() (string, error) {
+	addrs, err := localIPv6Addrs()
+	if err != nil {
+		return "", err
+	}
+	var own []localIPv6
+	for _, a := range addrs {
+		if a.Interface == s.Interface {
+			own = append(own, a)
+		}
+	}
+	if ip := pickIPv6(own, s.PreferPrivacy); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("interface %s has no global IPv6 address", s.Interface)
+}
+
+func (s *InterfaceSource) String() string { return "iface:" + s.Interface }
+
+// CommandSource runs a shell command and uses the first line it prints
+type CommandSource struct {
+	Command string
+}
+
+// Address runs the command
+func (s *CommandSource) Address(ctx context.Context, recordType string) (string, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", s.Command).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+func (s *CommandSource) String() string { return "exec:" + s.Command }
+
+// ssdpAddr is the UPnP discovery multicast address
+const ssdpAddr = "239.255.255.250:1900"
+
+// UPnPSource asks the router for its external address over UPnP IGD (the
+// GetExternalIPAddress action of the WANIPConnection or WANPPPConnection
+// service). The router is discovered with SSDP unless Location, the URL of its
+// device description, is set. IGD only reports IPv4 addresses.
+type UPnPSource struct {
+	Location   string
+	HTTPClient *http.Client
+}
+
+// Address asks the router for its external address
+func (s *UPnPSource) Address(ctx context.Context, recordType string) (string, error) {
+	if strings.ToUpper(recordType) != dnsrecord.RecordTypeA {
+		return "", fmt.Errorf("UPnP routers only report IPv4 addresses")
+	}
+
+	location := s.Location
+	if location == "" {
+		var err error
+		if location, err = discoverGateway(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	serviceType, controlURL, err := s.wanService(ctx, location)
+	if err != nil {
+		return "", err
+	}
+	return s.externalAddress(ctx, serviceType, controlURL)
+}
+
+func (s *UPnPSource) String() string {
+	if s.Location == "" {
+		return "upnp"
+	}
+	return "upnp:" + s.Location
+}
+
+// discoverGateway finds an Internet gateway device with an SSDP search and
+// returns the URL of its device description
+func discoverGateway(ctx context.Context) (string, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(3 * time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	dst, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return "", err
+	}
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n\r\n"
+	if _, err := conn.WriteTo([]byte(search), dst); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return "", fmt.Errorf("no UPnP gateway answered: %w", err)
+		}
+		for _, line := range strings.Split(string(buf[:n]), "\r\n") {
+			if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "location") {
+				return strings.TrimSpace(value), nil
+			}
+		}
+	}
+}
+
+// igdDevice is a device in a UPnP device description
+type igdDevice struct {
+	Services []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	Devices []igdDevice `xml:"deviceList>device"`
+}
+
+// wanService finds the WAN connection service in the device description at
+// location and returns its type and absolute control URL
+func (s *UPnPSource) wanService(ctx context.Context, location string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := httpClient(s.HTTPClient).Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("device description: status %d", resp.StatusCode)
+	}
+
+	var root struct {
+		URLBase string    `xml:"URLBase"`
+		Device  igdDevice `xml:"device"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&root); err != nil {
+		return "", "", fmt.Errorf("invalid device description: %w", err)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return "", "", err
+	}
+	if root.URLBase != "" {
+		if b, err := url.Parse(root.URLBase); err == nil {
+			base = b
+		}
+	}
+
+	devices := []igdDevice{root.Device}
+	for len(devices) > 0 {
+		device := devices[0]
+		devices = append(devices[1:], device.Devices...)
+		for _, service := range device.Services {
+			if !strings.Contains(service.ServiceType, ":WANIPConnection:") && !strings.Contains(service.ServiceType, ":WANPPPConnection:") {
+				continue
+			}
+			control, err := base.Parse(strings.TrimSpace(service.ControlURL))
+			if err != nil {
+				return "", "", err
+			}
+			return strings.TrimSpace(service.ServiceType), control.String(), nil
+		}
+	}
+	return "", "", fmt.Errorf("router has no WAN connection service")
+}
+
+// externalAddress calls GetExternalIPAddress on the WAN connection service
+func (s *UPnPSource) externalAddress(ctx context.Context, serviceType, controlURL string) (string, error) {
+	body := `<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+		`<s:Body><u:GetExternalIPAddress xmlns:u="` + serviceType + `"/></s:Body></s:Envelope>`
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, controlURL, bytes.NewBufferString(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+serviceType+`#GetExternalIPAddress"`)
+
+	resp, err := httpClient(s.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GetExternalIPAddress: status %d", resp.StatusCode)
+	}
+
+	decoder := xml.NewDecoder(io.LimitReader(resp.Body, 1<<16))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("router did not report an external address")
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "NewExternalIPAddress" {
+			var address string
+			if err := decoder.DecodeElement(&address, &start); err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(address), nil
+		}
+	}
+}
+
+// httpClient returns client, or a client with DefaultTimeout when it is nil
+func httpClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// familyName names the address family of a record type
+func familyName(recordType string) string {
+	if strings.ToUpper(recordType) == dnsrecord.RecordTypeAAAA {
+		return "IPv6"
+	}
+	return "IPv4"
+}
@@ -0,0 +1,162 @@
+package ddns
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+)
+
+// textServer answers every request with body
+func textServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+}
+
+func (s *DDNSTestSuite) TestParseSource() {
+	for spec, want := range map[string]Source{
+		"https://api.ipify.org": &HTTPSource{URL: "https://api.ipify.org"},
+		"iface:eth0":            &InterfaceSource{Interface: "eth0"},
+		"upnp":                  &UPnPSource{},
+		"upnp:http://192.168.1.1:5000/rootDesc.xml": &UPnPSource{Location: "http://192.168.1.1:5000/rootDesc.xml"},
+		"exec:curl -s https://example.net/ip":       &CommandSource{Command: "curl -s https://example.net/ip"},
+	} {
+		source, err := ParseSource(spec)
+		s.Require().NoError(err, spec)
+		s.Require().Equal(want, source, spec)
+		s.Require().Equal(spec, source.String())
+	}
+
+	source, err := ParseSource("interface:wan")
+	s.Require().NoError(err)
+	s.Require().Equal(&InterfaceSource{Interface: "wan"}, source)
+
+	for _, spec := range []string{"", "iface:", "exec: ", "upnp:not a url", "dig +short myip.opendns.com"} {
+		_, err := ParseSource(spec)
+		s.Require().Error(err, spec)
+	}
+}
+
+func (s *DDNSTestSuite) TestDetect_Consensus() {
+	first := textServer("198.51.100.7")
+	defer first.Close()
+	disagreeing := textServer("203.0.113.9")
+	defer disagreeing.Close()
+	agreeing := textServer("198.51.100.7\n")
+	defer agreeing.Close()
+
+	d := NewDetector()
+	d.Consensus = 2
+	d.Sources = map[string][]string{"A": {first.URL, disagreeing.URL, agreeing.URL}}
+
+	address, err := d.Detect(context.Background(), "A")
+	s.Require().NoError(err)
+	s.Require().Equal("198.51.100.7", address)
+
+	// Without a second matching answer nothing is detected
+	d.Sources = map[string][]string{"A": {first.URL, disagreeing.URL}}
+	_, err = d.Detect(context.Background(), "A")
+	s.Require().ErrorContains(err, "no 2 sources agreed")
+
+	d.Sources = map[string][]string{"A": {first.URL}}
+	_, err = d.Detect(context.Background(), "A")
+	s.Require().ErrorContains(err, "at least 2")
+
+	// An invalid spec is reported rather than skipped
+	d.Sources = map[string][]string{"A": {first.URL, "router"}}
+	_, err = d.Detect(context.Background(), "A")
+	s.Require().ErrorContains(err, "unknown address source")
+}
+
+func (s *DDNSTestSuite) TestInterfaceSource() {
+	defer func(orig func(string) ([]net.Addr, error)) { interfaceAddrs = orig }(interfaceAddrs)
+	interfaceAddrs = func(name string) ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1")},
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+			&net.IPNet{IP: net.ParseIP("198.51.100.7")},
+			&net.IPNet{IP: net.ParseIP("2001:db8::7")},
+		}, nil
+	}
+
+	source := &InterfaceSource{Interface: "wan"}
+	address, err := source.Address(context.Background(), "A")
+	s.Require().NoError(err)
+	s.Require().Equal("198.51.100.7", address)
+
+	defer func(orig func() ([]localIPv6, error)) { localIPv6Addrs = orig }(localIPv6Addrs)
+	localIPv6Addrs = func() ([]localIPv6, error) {
+		return []localIPv6{
+			{IP: net.ParseIP("fe80::1"), Interface: "wan", PrefixLen: 64},
+			{IP: net.ParseIP("fd00::7"), Interface: "wan", PrefixLen: 64},
+			{IP: net.ParseIP("2001:db8::a1"), Interface: "wan", PrefixLen: 64, Temporary: true},
+			{IP: net.ParseIP("2001:db8::7"), Interface: "wan", PrefixLen: 64},
+			{IP: net.ParseIP("2001:db8:1::7"), Interface: "lan", PrefixLen: 64},
+		}, nil
+	}
+
+	address, err = source.Address(context.Background(), "AAAA")
+	s.Require().NoError(err)
+	s.Require().Equal("2001:db8::7", address)
+
+	source.PreferPrivacy = true
+	address, err = source.Address(context.Background(), "AAAA")
+	s.Require().NoError(err)
+	s.Require().Equal("2001:db8::a1", address)
+}
+
+func (s *DDNSTestSuite) TestCommandSource() {
+	address, err := (&CommandSource{Command: "printf '198.51.100.7\\nextra\\n'"}).Address(context.Background(), "A")
+	s.Require().NoError(err)
+	s.Require().Equal("198.51.100.7", address)
+
+	_, err = (&CommandSource{Command: "echo broken >&2; exit 3"}).Address(context.Background(), "A")
+	s.Require().ErrorContains(err, "broken")
+}
+
+func (s *DDNSTestSuite) TestUPnPSource() {
+	var soapAction, soapBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rootDesc.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <deviceList><device>
+      <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+      <deviceList><device>
+        <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+        <serviceList><service>
+          <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+          <controlURL>/ctl/IPConn</controlURL>
+        </service></serviceList>
+      </device></deviceList>
+    </device></deviceList>
+  </device>
+</root>`))
+	})
+	mux.HandleFunc("/ctl/IPConn", func(w http.ResponseWriter, r *http.Request) {
+		soapAction = r.Header.Get("SOAPAction")
+		body, _ := io.ReadAll(r.Body)
+		soapBody = string(body)
+		w.Write([]byte(`<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>
+<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">
+<NewExternalIPAddress>198.51.100.7</NewExternalIPAddress>
+</u:GetExternalIPAddressResponse></s:Body></s:Envelope>`))
+	})
+	router := httptest.NewServer(mux)
+	defer router.Close()
+
+	source := &UPnPSource{Location: router.URL + "/rootDesc.xml"}
+	address, err := source.Address(context.Background(), "A")
+	s.Require().NoError(err)
+	s.Require().Equal("198.51.100.7", address)
+	s.Require().Equal(`"urn:schemas-upnp-org:service:WANIPConnection:1#GetExternalIPAddress"`, soapAction)
+	s.Require().Contains(soapBody, "GetExternalIPAddress")
+
+	_, err = source.Address(context.Background(), "AAAA")
+	s.Require().ErrorContains(err, "IPv4")
+}
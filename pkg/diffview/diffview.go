@@ -0,0 +1,270 @@
+// Package diffview renders record-level differences between two sets of DNS
+// records in one consistent format, as aligned +/~/- lines or as JSON.
+package diffview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// Kind is the kind of change for a single record
+type Kind string
+
+const (
+	Added     Kind = "add"
+	Changed   Kind = "change"
+	Removed   Kind = "remove"
+	Unchanged Kind = "unchanged"
+)
+
+// symbols are the line prefixes for each kind
+var symbols = map[Kind]string{
+	Added:     "+",
+	Changed:   "~",
+	Removed:   "-",
+	Unchanged: " ",
+}
+
+// ANSI colors for each kind
+var colors = map[Kind]string{
+	Added:   "\033[32m",
+	Changed: "\033[33m",
+	Removed: "\033[31m",
+}
+
+const colorReset = "\033[0m"
+
+// Entry is a single line of a diff
+type Entry struct {
+	Kind   Kind
+	Record dnsrecord.Record
+	// Previous is the record being replaced when Kind is Changed
+	Previous *dnsrecord.Record
+}
+
+// Diff is the difference between the current and desired records of a domain
+type Diff struct {
+	Domain  string
+	Entries []Entry
+}
+
+// Options controls rendering
+type Options struct {
+	// Color enables ANSI colors; see ColorEnabled
+	Color bool
+	// ShowUnchanged includes records that do not change
+	ShowUnchanged bool
+}
+
+// Compute returns the diff between the current and desired records of a domain
+func Compute(domainName string, current, desired []dnsrecord.Record) *Diff {
+	return FromApplyResult(provider.PlanResults(domainName, current, desired))
+}
+
+// FromApplyResult converts a planned or applied result into a diff. Failed records
+// are shown as the change that was attempted.
+func FromApplyResult(result *provider.ApplyResult) *Diff {
+	d := &Diff{Domain: result.Domain}
+
+	for _, r := range result.Records {
+		status := r.Status
+		if status == provider.ApplyFailed {
+			status = r.Action
+		}
+
+		entry := Entry{Record: r.Record}
+		switch status {
+		case provider.ApplyCreated:
+			entry.Kind = Added
+		case provider.ApplyUpdated:
+			entry.Kind = Changed
+			entry.Previous = r.Previous
+		case provider.ApplyDeleted:
+			entry.Kind = Removed
+		default:
+			entry.Kind = Unchanged
+		}
+		d.Entries = append(d.Entries, entry)
+	}
+
+	sort.SliceStable(d.Entries, func(i, j int) bool {
+		a, b := d.Entries[i].Record, d.Entries[j].Record
+		if !strings.EqualFold(a.HostName, b.HostName) {
+			return strings.ToLower(a.HostName) < strings.ToLower(b.HostName)
+		}
+		return a.RecordType < b.RecordType
+	})
+
+	return d
+}
+
+// Count returns the number of entries of the given kind
+func (d *Diff) Count(kind Kind) int {
+	n := 0
+	for _, e := range d.Entries {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// HasChanges reports whether any record is added, changed or removed
+func (d *Diff) HasChanges() bool {
+	return d.Count(Added)+d.Count(Changed)+d.Count(Removed) > 0
+}
+
+// Summary returns a short summary such as "2 to add, 1 to change, 0 to remove"
+func (d *Diff) Summary() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to remove", d.Count(Added), d.Count(Changed), d.Count(Removed))
+}
+
+// Render writes the diff as aligned lines prefixed with +, ~ or -, followed by a summary
+func Render(w io.Writer, d *Diff, opts Options) {
+	var rows [][]string
+	var kinds []Kind
+	for _, e := range d.Entries {
+		if e.Kind == Unchanged && !opts.ShowUnchanged {
+			continue
+		}
+
+		value := e.Record.Address
+		ttl := dnsrecord.FormatTTL(e.Record.TTL)
+		if e.Kind == Changed && e.Previous != nil {
+			if e.Previous.Address != e.Record.Address {
+				value = e.Previous.Address + " → " + e.Record.Address
+			}
+			if e.Previous.TTL != e.Record.TTL && e.Record.TTL > 0 {
+				ttl = dnsrecord.FormatTTL(e.Previous.TTL) + " → " + ttl
+			}
+		}
+
+		pref := ""
+		if e.Record.MXPref > 0 {
+			pref = "pref " + strconv.Itoa(e.Record.MXPref)
+		}
+
+		rows = append(rows, []string{symbols[e.Kind], e.Record.HostName, e.Record.RecordType, value, ttl, pref})
+		kinds = append(kinds, e.Kind)
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	for r, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			sb.WriteString(cell)
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-len([]rune(cell))+2))
+			}
+		}
+		line := strings.TrimRight(sb.String(), " ")
+
+		if color, ok := colors[kinds[r]]; ok && opts.Color {
+			line = color + line + colorReset
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", d.Summary())
+}
+
+// jsonRecord is the JSON form of a record
+type jsonRecord struct {
+	HostName string `json:"hostname"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl,omitempty"`
+	MXPref   int    `json:"mx_pref,omitempty"`
+}
+
+// jsonEntry is the JSON form of an entry
+type jsonEntry struct {
+	Op       Kind        `json:"op"`
+	Record   jsonRecord  `json:"record"`
+	Previous *jsonRecord `json:"previous,omitempty"`
+}
+
+func toJSONRecord(r dnsrecord.Record) jsonRecord {
+	return jsonRecord{HostName: r.HostName, Type: r.RecordType, Value: r.Address, TTL: r.TTL, MXPref: r.MXPref}
+}
+
+// Document is the structured form of a diff written by RenderJSON
+type Document struct {
+	Domain  string      `json:"domain"`
+	Changes []jsonEntry `json:"changes"`
+	Summary struct {
+		Add       int `json:"add"`
+		Change    int `json:"change"`
+		Remove    int `json:"remove"`
+		Unchanged int `json:"unchanged"`
+	} `json:"summary"`
+}
+
+// NewDocument returns the structured form of the diff, for encoders other than JSON
+func NewDocument(d *Diff, opts Options) *Document {
+	doc := &Document{Domain: d.Domain, Changes: []jsonEntry{}}
+
+	for _, e := range d.Entries {
+		if e.Kind == Unchanged && !opts.ShowUnchanged {
+			continue
+		}
+		entry := jsonEntry{Op: e.Kind, Record: toJSONRecord(e.Record)}
+		if e.Previous != nil && e.Kind == Changed {
+			prev := toJSONRecord(*e.Previous)
+			entry.Previous = &prev
+		}
+		doc.Changes = append(doc.Changes, entry)
+	}
+	doc.Summary.Add = d.Count(Added)
+	doc.Summary.Change = d.Count(Changed)
+	doc.Summary.Remove = d.Count(Removed)
+	doc.Summary.Unchanged = d.Count(Unchanged)
+
+	return doc
+}
+
+// RenderJSON writes the diff as an indented JSON document
+func RenderJSON(w io.Writer, d *Diff, opts Options) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDocument(d, opts))
+}
+
+// ColorEnabled reports whether colored output should be used for w: never when
+// noColor is set, NO_COLOR is set or TERM is "dumb", otherwise only for terminals
+func ColorEnabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
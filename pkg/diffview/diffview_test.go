@@ -0,0 +1,112 @@
+package diffview
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// DiffViewTestSuite is a test suite for diff rendering
+type DiffViewTestSuite struct {
+	suite.Suite
+	diff *Diff
+}
+
+// TestDiffViewSuite runs the diff view test suite
+func TestDiffViewSuite(t *testing.T) {
+	suite.Run(t, new(DiffViewTestSuite))
+}
+
+func (s *DiffViewTestSuite) SetupTest() {
+	current := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 1800},
+		{HostName: "old", RecordType: "CNAME", Address: "example.net.", TTL: 1800},
+	}
+	desired := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.2", TTL: 3600},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 1800},
+		{HostName: "@", RecordType: "MX", Address: "mx.example.net.", TTL: 300, MXPref: 10},
+	}
+	s.diff = Compute("example.com", current, desired)
+}
+
+func (s *DiffViewTestSuite) TestCompute() {
+	s.Require().Equal(1, s.diff.Count(Added))
+	s.Require().Equal(1, s.diff.Count(Changed))
+	s.Require().Equal(1, s.diff.Count(Removed))
+	s.Require().Equal(1, s.diff.Count(Unchanged))
+	s.Require().True(s.diff.HasChanges())
+
+	// Sorted by hostname, then type
+	s.Require().Equal("@", s.diff.Entries[0].Record.HostName)
+	s.Require().Equal("MX", s.diff.Entries[0].Record.RecordType)
+	s.Require().Equal("www", s.diff.Entries[3].Record.HostName)
+}
+
+func (s *DiffViewTestSuite) TestRender() {
+	var buf bytes.Buffer
+	Render(&buf, s.diff, Options{})
+
+	s.Require().Equal(`+  @    MX     mx.example.net.        5m        pref 10
+-  old  CNAME  example.net.           30m
+~  www  A      192.0.2.1 → 192.0.2.2  30m → 1h
+
+1 to add, 1 to change, 1 to remove
+`, buf.String())
+	s.Require().NotContains(buf.String(), "\033[")
+}
+
+func (s *DiffViewTestSuite) TestRender_ColorAndUnchanged() {
+	var buf bytes.Buffer
+	Render(&buf, s.diff, Options{Color: true, ShowUnchanged: true})
+
+	out := buf.String()
+	s.Require().Contains(out, "\033[32m+  @")
+	s.Require().Contains(out, "\033[31m-  old")
+	s.Require().Contains(out, "\033[33m~  www")
+	s.Require().Contains(out, "   @    TXT")
+}
+
+func (s *DiffViewTestSuite) TestRender_NoChanges() {
+	records := []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}}
+
+	var buf bytes.Buffer
+	Render(&buf, Compute("example.com", records, records), Options{})
+	s.Require().Equal("No changes.\n", buf.String())
+}
+
+func (s *DiffViewTestSuite) TestRenderJSON() {
+	var buf bytes.Buffer
+	s.Require().NoError(RenderJSON(&buf, s.diff, Options{}))
+
+	var doc struct {
+		Domain  string `json:"domain"`
+		Changes []struct {
+			Op       string         `json:"op"`
+			Record   map[string]any `json:"record"`
+			Previous map[string]any `json:"previous"`
+		} `json:"changes"`
+		Summary map[string]int `json:"summary"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &doc))
+
+	s.Require().Equal("example.com", doc.Domain)
+	s.Require().Len(doc.Changes, 3)
+	s.Require().Equal("change", doc.Changes[2].Op)
+	s.Require().Equal("192.0.2.1", doc.Changes[2].Previous["value"])
+	s.Require().Equal(map[string]int{"add": 1, "change": 1, "remove": 1, "unchanged": 1}, doc.Summary)
+}
+
+func (s *DiffViewTestSuite) TestColorEnabled() {
+	s.Require().False(ColorEnabled(&bytes.Buffer{}, false))
+	s.Require().False(ColorEnabled(&strings.Builder{}, true))
+
+	s.T().Setenv("NO_COLOR", "1")
+	s.Require().False(ColorEnabled(&bytes.Buffer{}, false))
+}
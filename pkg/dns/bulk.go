@@ -0,0 +1,82 @@
+package dns
+
+import (
+	"strings"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// BulkOperationsFor returns the bulk operations that turn current into desired
+// when applied with PlanBulk or BulkApply. Record sets (records of one
+// hostname and type) that are unchanged produce no operation. Deletes come
+// first, then updates, then adds.
+//
+// Bulk operations address records by hostname and type only, so a changed set
+// of one record becomes an update, and any other changed set is deleted as a
+// whole and added back with its desired values.
+func BulkOperationsFor(domainName string, current, desired []dnsrecord.Record) []BulkOperation {
+	type set struct {
+		current, desired []dnsrecord.Record
+	}
+	sets := make(map[string]*set)
+	var keys []string
+	setOf := func(r dnsrecord.Record) *set {
+		key := strings.ToLower(r.HostName) + " " + strings.ToUpper(r.RecordType)
+		s, ok := sets[key]
+		if !ok {
+			s = &set{}
+			sets[key] = s
+			keys = append(keys, key)
+		}
+		return s
+	}
+	for _, r := range current {
+		s := setOf(r)
+		s.current = append(s.current, r)
+	}
+	for _, r := range desired {
+		s := setOf(r)
+		s.desired = append(s.desired, r)
+	}
+
+	var deletes, updates, adds []BulkOperation
+	for _, key := range keys {
+		s := sets[key]
+		if !setChanged(domainName, s.current, s.desired) {
+			continue
+		}
+		switch {
+		case len(s.current) == 0:
+			for _, r := range s.desired {
+				adds = append(adds, BulkOperation{Action: BulkActionAdd, Record: r})
+			}
+		case len(s.desired) == 0:
+			deletes = append(deletes, BulkOperation{Action: BulkActionDelete, Record: s.current[0]})
+		case len(s.current) == 1 && len(s.desired) == 1:
+			// Keep the current spelling of the hostname and type, which
+			// PlanBulk matches exactly
+			r := s.desired[0]
+			r.HostName, r.RecordType = s.current[0].HostName, s.current[0].RecordType
+			updates = append(updates, BulkOperation{Action: BulkActionUpdate, Record: r})
+		default:
+			deletes = append(deletes, BulkOperation{Action: BulkActionDelete, Record: s.current[0]})
+			for _, r := range s.desired {
+				adds = append(adds, BulkOperation{Action: BulkActionAdd, Record: r})
+			}
+		}
+	}
+
+	operations := append(deletes, updates...)
+	return append(operations, adds...)
+}
+
+// setChanged reports whether applying desired over current changes any record
+func setChanged(domainName string, current, desired []dnsrecord.Record) bool {
+	for _, r := range provider.PlanResults(domainName, current, desired).Records {
+		if r.Status != provider.ApplyUnchanged {
+			return true
+		}
+	}
+	return false
+}
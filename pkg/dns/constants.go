@@ -17,6 +17,9 @@ const (
 	MaxMXPref     = 65535
 )
 
+// MaxTXTLength is the longest TXT value accepted, enough for a 4096-bit DKIM key
+const MaxTXTLength = 4096
+
 // Email type for DNS records
 const (
 	EmailTypeMX = "MX"
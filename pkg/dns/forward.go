@@ -0,0 +1,114 @@
+package dns
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+)
+
+// IsForward reports whether a record type is a URL redirect
+func IsForward(recordType string) bool {
+	switch strings.ToUpper(recordType) {
+	case dnsrecord.RecordTypeURL, dnsrecord.RecordTypeURL301, dnsrecord.RecordTypeFRAME:
+		return true
+	}
+	return false
+}
+
+// ForwardType returns the record type of a redirect: FRAME when masked, which
+// shows the target in a frame under the domain's own address, URL301 when
+// permanent and URL, a temporary redirect, otherwise
+func ForwardType(mask, permanent bool) string {
+	switch {
+	case mask:
+		return dnsrecord.RecordTypeFRAME
+	case permanent:
+		return dnsrecord.RecordTypeURL301
+	default:
+		return dnsrecord.RecordTypeURL
+	}
+}
+
+// ValidateForwardTarget checks that a redirect target is an absolute http or
+// https URL
+func ValidateForwardTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("forwarding target must be an http:// or https:// URL: %s", target)
+	}
+	return nil
+}
+
+// Forwards returns the URL redirect records of a domain
+func (s *Service) Forwards(domainName string) ([]dnsrecord.Record, error) {
+	records, err := s.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	var forwards []dnsrecord.Record
+	for _, r := range records {
+		if IsForward(r.RecordType) {
+			forwards = append(forwards, r)
+		}
+	}
+	return forwards, nil
+}
+
+// PlanForward returns the records of a domain with hostname redirected to
+// target by a record of recordType (see ForwardType). The host's redirect and
+// address records (A, AAAA and CNAME), which would answer instead of the
+// redirect, are replaced. An empty target only removes the host's redirect.
+func (s *Service) PlanForward(domainName, hostname, target, recordType string) (current, desired []dnsrecord.Record, err error) {
+	if !s.Capabilities().Forwarding {
+		return nil, nil, errors.NewInvalidInput("provider", fmt.Sprintf("%s does not support URL forwarding", s.ProviderName()))
+	}
+	if target != "" {
+		if !IsForward(recordType) {
+			return nil, nil, errors.NewInvalidInput("record_type", fmt.Sprintf("%s is not a forwarding record type", recordType))
+		}
+		if err := ValidateForwardTarget(target); err != nil {
+			return nil, nil, errors.NewInvalidInput("target", err.Error())
+		}
+	}
+
+	current, err = s.GetRecords(domainName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get existing records: %w", err)
+	}
+
+	found := false
+	desired = make([]dnsrecord.Record, 0, len(current)+1)
+	for _, r := range current {
+		if strings.EqualFold(r.HostName, hostname) {
+			if IsForward(r.RecordType) {
+				found = true
+				continue
+			}
+			if target != "" && isAddressRecord(r.RecordType) {
+				continue
+			}
+		}
+		desired = append(desired, r)
+	}
+	if target == "" {
+		if !found {
+			return nil, nil, errors.NewNotFound("forwarding", qualifiedName(hostname, domainName))
+		}
+		return current, desired, nil
+	}
+
+	forward, _ := s.ResolveTTL(dnsrecord.Record{HostName: hostname, RecordType: recordType, Address: target})
+	return current, append(desired, forward), nil
+}
+
+// isAddressRecord reports whether a record type answers address lookups
+func isAddressRecord(recordType string) bool {
+	switch strings.ToUpper(recordType) {
+	case dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeCNAME:
+		return true
+	}
+	return false
+}
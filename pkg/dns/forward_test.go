@@ -0,0 +1,65 @@
+package dns
+
+import (
+	"testing"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	zkerrors "zonekit/pkg/errors"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+// forwardingProvider is a mock provider that serves URL redirect records
+type forwardingProvider struct {
+	*mockProvider
+}
+
+func (m *forwardingProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Forwarding: true}
+}
+
+func TestPlanForward(t *testing.T) {
+	mock := &forwardingProvider{mockProvider: newMockProvider("forwarding")}
+	mock.records["example.com"] = []dnsrecord.Record{
+		testutil.A("@", "192.0.2.1"),
+		testutil.MX("@", 10, "mx.example.com"),
+		testutil.NewRecord("@", "URL", "http://old.example").Build(),
+		testutil.A("www", "192.0.2.1"),
+	}
+	service := NewServiceWithProvider(mock)
+
+	_, desired, err := service.PlanForward("example.com", "@", "https://example.org", ForwardType(true, false))
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		testutil.MX("@", 10, "mx.example.com"),
+		testutil.A("www", "192.0.2.1"),
+		testutil.NewRecord("@", "FRAME", "https://example.org").TTL(DefaultTTL).Build(),
+	}, desired)
+
+	_, desired, err = service.PlanForward("example.com", "@", "", "")
+	require.NoError(t, err)
+	require.Len(t, desired, 3)
+
+	_, _, err = service.PlanForward("example.com", "www", "", "")
+	var notFound *zkerrors.ErrNotFound
+	require.ErrorAs(t, err, &notFound)
+
+	_, _, err = service.PlanForward("example.com", "@", "ftp://example.org", dnsrecord.RecordTypeURL)
+	require.ErrorContains(t, err, "http:// or https:// URL")
+}
+
+func TestPlanForward_Unsupported(t *testing.T) {
+	service := NewServiceWithProvider(newMockProvider("plain"))
+	_, _, err := service.PlanForward("example.com", "@", "https://example.org", dnsrecord.RecordTypeURL)
+	require.ErrorContains(t, err, "plain does not support URL forwarding")
+}
+
+func TestForwardType(t *testing.T) {
+	require.Equal(t, dnsrecord.RecordTypeURL, ForwardType(false, false))
+	require.Equal(t, dnsrecord.RecordTypeURL301, ForwardType(false, true))
+	require.Equal(t, dnsrecord.RecordTypeFRAME, ForwardType(true, true))
+	require.True(t, IsForward("url301"))
+	require.False(t, IsForward(dnsrecord.RecordTypeCNAME))
+}
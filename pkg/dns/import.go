@@ -0,0 +1,94 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+)
+
+// ImportMode controls how imported records are combined with the records
+// already in a zone
+type ImportMode string
+
+const (
+	// ImportSafe adds the imported records and fails with a conflict error if
+	// that would change or remove any existing record (the default)
+	ImportSafe ImportMode = "safe"
+	// ImportMerge adds the imported records, replacing existing records with the
+	// same hostname and type, and keeps all other records
+	ImportMerge ImportMode = "merge"
+	// ImportPrune makes the zone match the imported records, removing records
+	// that are not imported
+	ImportPrune ImportMode = "prune"
+)
+
+// PlanImport returns the current records of a domain and the records that would
+// result from importing records in mode, without changing anything. When a safe
+// import conflicts with existing records, the plan is returned together with the
+// conflict error so the changes can be shown.
+func (s *Service) PlanImport(domainName string, imported []dnsrecord.Record, mode ImportMode) (current, desired []dnsrecord.Record, err error) {
+	current, err = s.GetRecords(domainName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get existing records: %w", err)
+	}
+
+	if mode == ImportPrune {
+		return current, imported, nil
+	}
+	desired = MergeRecords(current, imported)
+
+	if mode == ImportSafe {
+		var conflicts []string
+		seen := make(map[string]bool)
+		for _, r := range provider.PlanResults(domainName, current, desired).Records {
+			if r.Status != provider.ApplyUpdated && r.Status != provider.ApplyDeleted {
+				continue
+			}
+			set := fmt.Sprintf("%s %s", r.Record.HostName, r.Record.RecordType)
+			if !seen[set] {
+				seen[set] = true
+				conflicts = append(conflicts, set)
+			}
+		}
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			return current, desired, errors.NewConflict("zone", domainName,
+				fmt.Sprintf("import would change %d existing record set(s): %s", len(conflicts), strings.Join(conflicts, ", ")))
+		}
+	}
+
+	return current, desired, nil
+}
+
+// MergeRecords returns current with imported merged in: imported records replace
+// the existing records with the same hostname and type, and a CNAME replaces
+// every other record at its hostname, as the two cannot coexist
+func MergeRecords(current, imported []dnsrecord.Record) []dnsrecord.Record {
+	sets := make(map[string]bool)
+	hosts := make(map[string]string)
+	for _, r := range imported {
+		host := strings.ToLower(r.HostName)
+		sets[host+" "+strings.ToUpper(r.RecordType)] = true
+		if hosts[host] == "" || r.RecordType == dnsrecord.RecordTypeCNAME {
+			hosts[host] = r.RecordType
+		}
+	}
+
+	var merged []dnsrecord.Record
+	for _, r := range current {
+		host := strings.ToLower(r.HostName)
+		if sets[host+" "+strings.ToUpper(r.RecordType)] {
+			continue
+		}
+		if importedType, ok := hosts[host]; ok &&
+			(importedType == dnsrecord.RecordTypeCNAME || r.RecordType == dnsrecord.RecordTypeCNAME) {
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return append(merged, imported...)
+}
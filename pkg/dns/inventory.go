@@ -0,0 +1,161 @@
+package dns
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// maxConcurrentInventory bounds the number of zones fetched in parallel
+const maxConcurrentInventory = 5
+
+// RecordFilter selects records by type, hostname, value and tags. Empty fields match everything.
+type RecordFilter struct {
+	// Type matches the record type exactly (case-insensitive)
+	Type string
+	// Host matches the hostname exactly, or as a glob pattern if it contains *, ? or [
+	Host string
+	// Value matches records whose value contains it (case-insensitive)
+	Value string
+	// Tags matches records that carry every one of them
+	Tags []string
+}
+
+// Match reports whether a record satisfies the filter
+func (f RecordFilter) Match(record dnsrecord.Record) bool {
+	if f.Type != "" && !strings.EqualFold(record.RecordType, f.Type) {
+		return false
+	}
+
+	if f.Host != "" {
+		host := strings.ToLower(record.HostName)
+		pattern := strings.ToLower(f.Host)
+		if strings.ContainsAny(pattern, "*?[") {
+			if ok, err := path.Match(pattern, host); err != nil || !ok {
+				return false
+			}
+		} else if host != pattern {
+			return false
+		}
+	}
+
+	if f.Value != "" && !strings.Contains(strings.ToLower(record.Address), strings.ToLower(f.Value)) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !record.HasTag(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Filter returns the records matching the filter
+func (f RecordFilter) Filter(records []dnsrecord.Record) []dnsrecord.Record {
+	var matched []dnsrecord.Record
+	for _, record := range records {
+		if f.Match(record) {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// InventoryRecord is a DNS record tagged with the domain it belongs to
+type InventoryRecord struct {
+	Domain   string `json:"domain"`
+	ID       string `json:"id,omitempty"`
+	HostName string `json:"hostname"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl,omitempty"`
+	MXPref   int    `json:"mx_pref,omitempty"`
+	// SRV holds the fields of an SRV record's value
+	SRV     *dnsrecord.SRV `json:"srv,omitempty"`
+	Comment string         `json:"comment,omitempty"`
+	Tags    []string       `json:"tags,omitempty"`
+}
+
+// InventoryError records a domain whose zone could not be read
+type InventoryError struct {
+	Domain string `json:"domain"`
+	Error  string `json:"error"`
+}
+
+// Inventory is an account-wide listing of DNS records
+type Inventory struct {
+	Records []InventoryRecord `json:"records"`
+	// Versions holds the version of each zone that was read, keyed by domain
+	Versions map[string]ZoneVersion `json:"versions"`
+	Errors   []InventoryError       `json:"errors,omitempty"`
+	// Raw holds the provider's API responses keyed by domain, when they were
+	// requested; see Service.RawRecords
+	Raw map[string]interface{} `json:"raw,omitempty"`
+}
+
+// Inventory fetches the records of several domains in parallel and returns those
+// matching the filter, sorted by domain. Domains that fail are reported in Errors.
+func (s *Service) Inventory(domains []string, filter RecordFilter) *Inventory {
+	perDomain := make([][]InventoryRecord, len(domains))
+	versions := make([]ZoneVersion, len(domains))
+	errs := make([]error, len(domains))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentInventory)
+	for i, domainName := range domains {
+		wg.Add(1)
+		go func(i int, domainName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			records, err := s.GetRecords(domainName)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			versions[i] = s.ZoneVersion(domainName, records)
+			for _, record := range filter.Filter(records) {
+				item := InventoryRecord{
+					Domain:   domainName,
+					ID:       record.ID,
+					HostName: record.HostName,
+					Type:     record.RecordType,
+					Value:    record.Address,
+					TTL:      record.TTL,
+					MXPref:   record.MXPref,
+					Comment:  record.Comment,
+					Tags:     record.Tags,
+				}
+				if record.RecordType == dnsrecord.RecordTypeSRV {
+					if srv, err := dnsrecord.ParseSRV(record.Address); err == nil {
+						item.SRV = &srv
+					}
+				}
+				perDomain[i] = append(perDomain[i], item)
+			}
+		}(i, domainName)
+	}
+	wg.Wait()
+
+	inventory := &Inventory{Records: []InventoryRecord{}, Versions: make(map[string]ZoneVersion)}
+	for i, domainName := range domains {
+		if errs[i] != nil {
+			inventory.Errors = append(inventory.Errors, InventoryError{Domain: domainName, Error: errs[i].Error()})
+			continue
+		}
+		inventory.Records = append(inventory.Records, perDomain[i]...)
+		inventory.Versions[domainName] = versions[i]
+	}
+
+	sort.SliceStable(inventory.Records, func(a, b int) bool {
+		return inventory.Records[a].Domain < inventory.Records[b].Domain
+	})
+
+	return inventory
+}
@@ -0,0 +1,394 @@
+// Package lookup queries DNS servers directly and converts their answers into
+// records, so live resolver answers can be compared with provider and file state.
+package lookup
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"zonekit/pkg/dnsrecord"
+)
+
+// DefaultTimeout bounds a single query
+const DefaultTimeout = 5 * time.Second
+
+// FallbackServer is used when no resolver is configured on the system
+const FallbackServer = "1.1.1.1:53"
+
+// Record types dnsmessage does not parse
+const (
+	TypeCAA   dnsmessage.Type = 257
+	TypeTLSA  dnsmessage.Type = 52
+	TypeSSHFP dnsmessage.Type = 44
+	TypeDS    dnsmessage.Type = 43
+	TypeNAPTR dnsmessage.Type = 35
+)
+
+// queryTypes are the record types Lookup can query
+var queryTypes = map[string]dnsmessage.Type{
+	dnsrecord.RecordTypeA:     dnsmessage.TypeA,
+	dnsrecord.RecordTypeAAAA:  dnsmessage.TypeAAAA,
+	dnsrecord.RecordTypeCNAME: dnsmessage.TypeCNAME,
+	dnsrecord.RecordTypeMX:    dnsmessage.TypeMX,
+	dnsrecord.RecordTypeTXT:   dnsmessage.TypeTXT,
+	dnsrecord.RecordTypeNS:    dnsmessage.TypeNS,
+	dnsrecord.RecordTypeSRV:   dnsmessage.TypeSRV,
+	dnsrecord.RecordTypeCAA:   TypeCAA,
+	dnsrecord.RecordTypePTR:   dnsmessage.TypePTR,
+	dnsrecord.RecordTypeTLSA:  TypeTLSA,
+	dnsrecord.RecordTypeSSHFP: TypeSSHFP,
+	dnsrecord.RecordTypeDS:    TypeDS,
+	dnsrecord.RecordTypeNAPTR: TypeNAPTR,
+}
+
+// Supports reports whether Lookup can query records of the given type
+func Supports(recordType string) bool {
+	_, ok := queryTypes[strings.ToUpper(recordType)]
+	return ok
+}
+
+// Resolver sends queries to a single DNS server
+type Resolver struct {
+	// Server is host:port; a host without a port uses port 53
+	Server  string
+	Timeout time.Duration
+}
+
+// NewResolver creates a resolver for server, or for the system's first
+// configured name server if server is empty
+func NewResolver(server string) *Resolver {
+	if server == "" {
+		server = SystemServer()
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &Resolver{Server: server, Timeout: DefaultTimeout}
+}
+
+// SystemServer returns the first name server in /etc/resolv.conf, or
+// FallbackServer if there is none
+func SystemServer() string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return FallbackServer
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+	return FallbackServer
+}
+
+// Lookup queries the records of one type at a host of zone and returns them
+// with host names relative to the zone. A name that does not exist yields no
+// records. Answers for other names, such as the targets of a CNAME chain, are
+// left out.
+func (r *Resolver) Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error) {
+	qtype, ok := queryTypes[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("cannot query %s records", recordType)
+	}
+
+	origin := FQDN(zone)
+	fullName := origin
+	if hostname != "" && hostname != "@" {
+		fullName = FQDN(hostname + "." + zone)
+	}
+	name, err := dnsmessage.NewName(fullName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %s: %w", fullName, err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := r.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query, err := buildQuery(id, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := r.exchange(ctx, "udp", query)
+	if err == nil && truncated(msg) {
+		msg, err = r.exchange(ctx, "tcp", query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s %s at %s: %w", fullName, recordType, r.Server, err)
+	}
+
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", r.Server, err)
+	}
+	if header.ID != id {
+		return nil, fmt.Errorf("invalid response from %s: unexpected message ID", r.Server)
+	}
+	switch header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("query for %s %s failed at %s: %s", fullName, recordType, r.Server, header.RCode)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", r.Server, err)
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", r.Server, err)
+	}
+
+	var records []dnsrecord.Record
+	for _, rr := range answers {
+		if rr.Header.Type != qtype || !strings.EqualFold(rr.Header.Name.String(), fullName) {
+			continue
+		}
+		if record, ok := ToRecord(rr, origin); ok {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// exchange sends a query over network and returns the response
+func (r *Resolver) exchange(ctx context.Context, network string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, r.Server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		if err := WriteMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return ReadMessage(conn)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// truncated reports whether a UDP response was cut short and must be retried over TCP
+func truncated(msg []byte) bool {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	return err == nil && header.Truncated
+}
+
+// buildQuery builds a recursive query for one name and type
+func buildQuery(id uint16, name dnsmessage.Name, qtype dnsmessage.Type) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// ToRecord turns a resource into a record relative to origin. Record types
+// zonekit does not manage are reported as not ok.
+func ToRecord(rr dnsmessage.Resource, origin string) (dnsrecord.Record, bool) {
+	record := dnsrecord.Record{
+		HostName: RelativeName(rr.Header.Name.String(), origin),
+		TTL:      int(rr.Header.TTL),
+	}
+
+	switch body := rr.Body.(type) {
+	case *dnsmessage.AResource:
+		record.RecordType = dnsrecord.RecordTypeA
+		record.Address = net.IP(body.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		record.RecordType = dnsrecord.RecordTypeAAAA
+		record.Address = net.IP(body.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		record.RecordType = dnsrecord.RecordTypeCNAME
+		record.Address = body.CNAME.String()
+	case *dnsmessage.MXResource:
+		record.RecordType = dnsrecord.RecordTypeMX
+		record.Address = body.MX.String()
+		record.MXPref = int(body.Pref)
+	case *dnsmessage.NSResource:
+		record.RecordType = dnsrecord.RecordTypeNS
+		record.Address = body.NS.String()
+	case *dnsmessage.TXTResource:
+		record.RecordType = dnsrecord.RecordTypeTXT
+		record.Address = strings.Join(body.TXT, "")
+	case *dnsmessage.SRVResource:
+		record.RecordType = dnsrecord.RecordTypeSRV
+		record.Address = fmt.Sprintf("%d %d %d %s", body.Priority, body.Weight, body.Port, body.Target.String())
+	case *dnsmessage.PTRResource:
+		record.RecordType = dnsrecord.RecordTypePTR
+		record.Address = body.PTR.String()
+	case *dnsmessage.UnknownResource:
+		recordType, value, ok := parseUnknown(body)
+		if !ok {
+			return record, false
+		}
+		record.RecordType = recordType
+		record.Address = value
+	default:
+		return record, false
+	}
+
+	return record, true
+}
+
+// parseUnknown formats the data of the record types dnsmessage does not parse
+func parseUnknown(body *dnsmessage.UnknownResource) (recordType, value string, ok bool) {
+	data := body.Data
+	switch body.Type {
+	case TypeCAA:
+		value, ok = parseCAA(data)
+		return dnsrecord.RecordTypeCAA, value, ok
+	case TypeTLSA:
+		if len(data) < 4 {
+			return "", "", false
+		}
+		recordType = dnsrecord.RecordTypeTLSA
+		value = fmt.Sprintf("%d %d %d %x", data[0], data[1], data[2], data[3:])
+	case TypeSSHFP:
+		if len(data) < 3 {
+			return "", "", false
+		}
+		recordType = dnsrecord.RecordTypeSSHFP
+		value = fmt.Sprintf("%d %d %x", data[0], data[1], data[2:])
+	case TypeDS:
+		if len(data) < 5 {
+			return "", "", false
+		}
+		recordType = dnsrecord.RecordTypeDS
+		value = fmt.Sprintf("%d %d %d %x", binary.BigEndian.Uint16(data), data[2], data[3], data[4:])
+	case TypeNAPTR:
+		naptr, ok := parseNAPTR(data)
+		if !ok {
+			return "", "", false
+		}
+		recordType, value = dnsrecord.RecordTypeNAPTR, naptr.String()
+	default:
+		return "", "", false
+	}
+	// Only values zonekit would accept itself
+	if _, err := dnsrecord.ParseValue(recordType, value); err != nil {
+		return "", "", false
+	}
+	return recordType, value, true
+}
+
+// parseNAPTR reads NAPTR record data (RFC 3403), whose replacement is an
+// uncompressed name
+func parseNAPTR(data []byte) (dnsrecord.NAPTR, bool) {
+	if len(data) < 4 {
+		return dnsrecord.NAPTR{}, false
+	}
+	naptr := dnsrecord.NAPTR{Order: int(binary.BigEndian.Uint16(data)), Preference: int(binary.BigEndian.Uint16(data[2:]))}
+	rest := data[4:]
+	for _, field := range []*string{&naptr.Flags, &naptr.Service, &naptr.Regexp} {
+		if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+			return dnsrecord.NAPTR{}, false
+		}
+		*field = string(rest[1 : 1+int(rest[0])])
+		rest = rest[1+int(rest[0]):]
+	}
+
+	var labels []string
+	for {
+		if len(rest) < 1 || len(rest) < 1+int(rest[0]) || rest[0] > 63 {
+			return dnsrecord.NAPTR{}, false
+		}
+		n := int(rest[0])
+		if n == 0 {
+			break
+		}
+		labels = append(labels, string(rest[1:1+n]))
+		rest = rest[1+n:]
+	}
+	naptr.Replacement = strings.Join(labels, ".") + "."
+	return naptr, true
+}
+
+// parseCAA formats CAA record data (RFC 8659) as `flags tag "value"`
+func parseCAA(data []byte) (string, bool) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return "", false
+	}
+	tagLen := int(data[1])
+	return fmt.Sprintf("%d %s %q", data[0], data[2:2+tagLen], data[2+tagLen:]), true
+}
+
+// RelativeName returns name relative to origin, "@" for the apex
+func RelativeName(name, origin string) string {
+	if strings.EqualFold(name, origin) {
+		return "@"
+	}
+	if strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(origin)) {
+		return name[:len(name)-len(origin)-1]
+	}
+	return name
+}
+
+// FQDN returns name with a trailing dot
+func FQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// WriteMessage writes a length-prefixed DNS message (RFC 1035 section 4.2.2)
+func WriteMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadMessage reads a length-prefixed DNS message
+func ReadMessage(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
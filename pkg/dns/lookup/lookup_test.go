@@ -0,0 +1,122 @@
+package lookup
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveUDP answers queries on a local UDP socket with the resources registered
+// for the question's name, NXDOMAIN for unknown names
+func serveUDP(t *testing.T, zone map[string][]dnsmessage.Resource) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+
+			resources, ok := zone[q.Name.String()]
+			rcode := dnsmessage.RCodeSuccess
+			if !ok {
+				rcode = dnsmessage.RCodeNameError
+			}
+
+			b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RCode: rcode})
+			b.StartQuestions()
+			b.Question(q)
+			b.StartAnswers()
+			for _, rr := range resources {
+				switch body := rr.Body.(type) {
+				case *dnsmessage.AResource:
+					b.AResource(rr.Header, *body)
+				case *dnsmessage.CNAMEResource:
+					b.CNAMEResource(rr.Header, *body)
+				case *dnsmessage.TXTResource:
+					b.TXTResource(rr.Header, *body)
+				}
+			}
+			msg, err := b.Finish()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(msg, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func header(name string, rrType dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: rrType, Class: dnsmessage.ClassINET, TTL: 300}
+}
+
+func TestLookup(t *testing.T) {
+	server := serveUDP(t, map[string][]dnsmessage.Resource{
+		"example.com.": {
+			{Header: header("example.com.", dnsmessage.TypeA), Body: &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}},
+			{Header: header("example.com.", dnsmessage.TypeTXT), Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}}},
+		},
+		// A query for www follows the CNAME; only www's own records are returned
+		"www.example.com.": {
+			{Header: header("www.example.com.", dnsmessage.TypeCNAME), Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("example.com.")}},
+			{Header: header("example.com.", dnsmessage.TypeA), Body: &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}},
+		},
+	})
+	resolver := NewResolver(server)
+
+	records, err := resolver.Lookup(context.Background(), "example.com", "@", "A")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300}}, records)
+
+	records, err = resolver.Lookup(context.Background(), "example.com", "@", "TXT")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "v=spf1 -all", records[0].Address)
+
+	records, err = resolver.Lookup(context.Background(), "example.com", "www", "CNAME")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300}}, records)
+
+	records, err = resolver.Lookup(context.Background(), "example.com", "www", "A")
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	records, err = resolver.Lookup(context.Background(), "example.com", "missing", "A")
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	_, err = resolver.Lookup(context.Background(), "example.com", "@", "URL")
+	require.ErrorContains(t, err, "cannot query URL records")
+}
+
+func TestNewResolver_DefaultsPort(t *testing.T) {
+	require.Equal(t, "192.0.2.53:53", NewResolver("192.0.2.53").Server)
+	require.Equal(t, "[2001:db8::53]:53", NewResolver("2001:db8::53").Server)
+	require.Equal(t, "192.0.2.53:5353", NewResolver("192.0.2.53:5353").Server)
+}
+
+func TestRelativeName(t *testing.T) {
+	require.Equal(t, "@", RelativeName("Example.com.", "example.com."))
+	require.Equal(t, "_dmarc", RelativeName("_dmarc.example.com.", "example.com."))
+	require.Equal(t, "other.org.", RelativeName("other.org.", "example.com."))
+}
@@ -0,0 +1,141 @@
+// Package mxcheck checks the live target of an MX record before it is written,
+// catching the classic mistake of pointing MX at a web host: a target without
+// an address, a target that is a CNAME (forbidden by RFC 2181 section 10.3)
+// and a target that does not accept SMTP connections.
+package mxcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+// DefaultDialTimeout bounds connecting to the target's SMTP port and reading
+// its greeting
+const DefaultDialTimeout = 10 * time.Second
+
+// Checker checks MX targets
+type Checker struct {
+	Resolver    zonecompare.Resolver
+	DialTimeout time.Duration
+	// dial connects to the SMTP port; tests replace it
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// New creates a checker that resolves targets outside the zone with resolver
+func New(resolver zonecompare.Resolver) *Checker {
+	dialer := &net.Dialer{}
+	return &Checker{Resolver: resolver, DialTimeout: DefaultDialTimeout, dial: dialer.DialContext}
+}
+
+// Check returns warnings about target, the exchange of an MX record in zone.
+// Targets within the zone are looked up in records, the records the zone will
+// hold once the change is applied, as the change is not live yet; others are
+// resolved. No warnings means the target has an address and greeted on port 25.
+func (c *Checker) Check(ctx context.Context, zone, target string, records []dnsrecord.Record) []string {
+	name := strings.ToLower(strings.TrimSuffix(target, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	var addresses []string
+	var isCNAME bool
+	var warnings []string
+	if host, ok := inZone(name, zone); ok {
+		for _, r := range records {
+			if !strings.EqualFold(relative(r.HostName, zone), host) {
+				continue
+			}
+			switch strings.ToUpper(r.RecordType) {
+			case dnsrecord.RecordTypeCNAME:
+				isCNAME = true
+			case dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA:
+				addresses = append(addresses, r.Address)
+			}
+		}
+	} else {
+		cnames, err := c.Resolver.Lookup(ctx, name, "@", dnsrecord.RecordTypeCNAME)
+		if err != nil {
+			return []string{fmt.Sprintf("MX target %s could not be resolved: %v", name, err)}
+		}
+		isCNAME = len(cnames) > 0
+		for _, recordType := range []string{dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA} {
+			found, err := c.Resolver.Lookup(ctx, name, "@", recordType)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("MX target %s could not be resolved: %v", name, err))
+				continue
+			}
+			for _, r := range found {
+				addresses = append(addresses, r.Address)
+			}
+		}
+	}
+
+	switch {
+	case isCNAME:
+		return append(warnings, fmt.Sprintf("MX target %s is a CNAME; MX records must point at a name with A or AAAA records (RFC 2181), and some mail servers refuse to deliver", name))
+	case len(addresses) == 0:
+		return append(warnings, fmt.Sprintf("MX target %s has no A or AAAA record; mail for the domain cannot be delivered", name))
+	}
+
+	if warning := c.checkSMTP(ctx, name, addresses[0]); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// checkSMTP connects to port 25 of address and reads the SMTP greeting
+func (c *Checker) checkSMTP(ctx context.Context, name, address string) string {
+	timeout := c.DialTimeout
+	if timeout == 0 {
+		timeout = DefaultDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx, "tcp", net.JoinHostPort(address, "25"))
+	if err != nil {
+		return fmt.Sprintf("MX target %s (%s) refuses SMTP connections: %v (port 25 may also be blocked on this network)", name, address, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	greeting, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Sprintf("MX target %s (%s) accepted a connection on port 25 but sent no SMTP greeting: %v", name, address, err)
+	}
+	_, _ = conn.Write([]byte("QUIT\r\n"))
+	if !strings.HasPrefix(greeting, "220") {
+		return fmt.Sprintf("MX target %s (%s) does not greet as a mail server on port 25: %q", name, address, strings.TrimSpace(greeting))
+	}
+	return ""
+}
+
+// inZone returns name relative to zone, "@" for the apex, if it is in zone
+func inZone(name, zone string) (string, bool) {
+	switch {
+	case name == zone:
+		return "@", true
+	case strings.HasSuffix(name, "."+zone):
+		return strings.TrimSuffix(name, "."+zone), true
+	}
+	return "", false
+}
+
+// relative returns a record's hostname relative to zone
+func relative(host, zone string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return "@"
+	}
+	if rel, ok := inZone(host, zone); ok {
+		return rel
+	}
+	return host
+}
@@ -0,0 +1,87 @@
+package mxcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"zonekit/pkg/dnsrecord"
+)
+
+// fakeResolver answers from records keyed by name and type
+type fakeResolver map[string][]dnsrecord.Record
+
+func (f fakeResolver) Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error) {
+	return f[zone+" "+recordType], nil
+}
+
+// smtpServer accepts connections and sends greeting, or refuses them when
+// greeting is empty
+func smtpServer(greeting string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if greeting == "" {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		go func() {
+			_, _ = server.Write([]byte(greeting))
+			buf := make([]byte, 64)
+			_, _ = server.Read(buf)
+			server.Close()
+		}()
+		return client, nil
+	}
+}
+
+func TestCheck_External(t *testing.T) {
+	resolver := fakeResolver{
+		"mx.example.net A":       {{HostName: "@", RecordType: "A", Address: "192.0.2.25"}},
+		"www.example.net CNAME":  {{HostName: "@", RecordType: "CNAME", Address: "web.example.net"}},
+		"web.example.net A":      {{HostName: "@", RecordType: "A", Address: "192.0.2.80"}},
+		"nomail.example.net TXT": {{HostName: "@", RecordType: "TXT", Address: "x"}},
+	}
+	checker := New(resolver)
+	checker.dial = smtpServer("220 mx.example.net ESMTP\r\n")
+
+	require.Empty(t, checker.Check(context.Background(), "example.com", "mx.example.net.", nil))
+
+	warnings := checker.Check(context.Background(), "example.com", "www.example.net", nil)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "is a CNAME")
+
+	warnings = checker.Check(context.Background(), "example.com", "nomail.example.net", nil)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "has no A or AAAA record")
+
+	// A web host refuses SMTP or answers with something else
+	checker.dial = smtpServer("")
+	warnings = checker.Check(context.Background(), "example.com", "web.example.net", nil)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "refuses SMTP connections")
+
+	checker.dial = smtpServer("HTTP/1.1 400 Bad Request\r\n")
+	warnings = checker.Check(context.Background(), "example.com", "web.example.net", nil)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "does not greet as a mail server")
+}
+
+func TestCheck_InZone(t *testing.T) {
+	checker := New(fakeResolver{})
+	checker.dial = smtpServer("220 ready\r\n")
+
+	records := []dnsrecord.Record{
+		{HostName: "mail", RecordType: "A", Address: "192.0.2.25"},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com"},
+	}
+	require.Empty(t, checker.Check(context.Background(), "example.com", "mail.example.com", records))
+
+	warnings := checker.Check(context.Background(), "example.com", "www.example.com.", records)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "is a CNAME")
+
+	warnings = checker.Check(context.Background(), "example.com", "mx.example.com", records)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "has no A or AAAA record")
+}
@@ -0,0 +1,117 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+	"zonekit/pkg/zonecompare"
+)
+
+// Note is the comment and tags of a record
+type Note struct {
+	Comment string   `json:"comment,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// NoteStore keeps the comments and tags of records in local state, for
+// providers that cannot store them. Notes are kept per zone and matched to
+// records by hostname, type and value, so a record whose value changes loses
+// its note unless the new record carries one.
+type NoteStore struct {
+	state state.Store
+	mu    sync.Mutex
+}
+
+// NewNoteStore creates a note store backed by a state store
+func NewNoteStore(st state.Store) *NoteStore {
+	return &NoteStore{state: st}
+}
+
+// noteKey identifies a record within a zone
+func noteKey(r dnsrecord.Record) string {
+	host := strings.ToLower(strings.TrimSuffix(r.HostName, "."))
+	if host == "" {
+		host = "@"
+	}
+	return host + " " + strings.ToUpper(r.RecordType) + " " + zonecompare.Normalize(r)
+}
+
+// load returns the notes of a zone keyed by noteKey
+func (n *NoteStore) load(providerName, domainName string) (map[string]Note, error) {
+	notes := make(map[string]Note)
+	if _, err := n.state.Get(state.BucketNotes, versionKey(providerName, domainName), &notes); err != nil {
+		return nil, fmt.Errorf("failed to read record notes: %w", err)
+	}
+	return notes, nil
+}
+
+// Annotate returns records with the comments and tags kept for them filled in
+func (n *NoteStore) Annotate(providerName, domainName string, records []dnsrecord.Record) ([]dnsrecord.Record, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	notes, err := n.load(providerName, domainName)
+	if err != nil || len(notes) == 0 {
+		return records, err
+	}
+	annotated := make([]dnsrecord.Record, len(records))
+	for i, r := range records {
+		if note, ok := notes[noteKey(r)]; ok {
+			r = keepNotes(dnsrecord.Record{Comment: note.Comment, Tags: append([]string(nil), note.Tags...)}, r)
+		}
+		annotated[i] = r
+	}
+	return annotated, nil
+}
+
+// Save keeps the comments and tags of a zone's records after they were
+// applied. A record without a comment or without tags keeps those it had;
+// notes of records that are gone are dropped.
+func (n *NoteStore) Save(providerName, domainName string, records []dnsrecord.Record) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	previous, err := n.load(providerName, domainName)
+	if err != nil {
+		return err
+	}
+	notes := make(map[string]Note)
+	for _, r := range records {
+		key := noteKey(r)
+		note := previous[key]
+		if r.Comment != "" {
+			note.Comment = r.Comment
+		}
+		if len(r.Tags) > 0 {
+			note.Tags = append([]string(nil), r.Tags...)
+			sort.Strings(note.Tags)
+		}
+		if note.Comment != "" || len(note.Tags) > 0 {
+			notes[key] = note
+		}
+	}
+
+	key := versionKey(providerName, domainName)
+	if len(notes) == 0 {
+		return n.state.Delete(state.BucketNotes, key)
+	}
+	if err := n.state.Put(state.BucketNotes, key, notes); err != nil {
+		return fmt.Errorf("failed to write record notes: %w", err)
+	}
+	return nil
+}
+
+// stripNotes returns records without comments and tags, for providers that
+// cannot store them
+func stripNotes(records []dnsrecord.Record) []dnsrecord.Record {
+	stripped := make([]dnsrecord.Record, len(records))
+	for i, r := range records {
+		r.Comment, r.Tags = "", nil
+		stripped[i] = r
+	}
+	return stripped
+}
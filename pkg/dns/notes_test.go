@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+)
+
+// commentProvider is a mock provider that stores comments and tags itself
+type commentProvider struct {
+	*mockProvider
+}
+
+func (p *commentProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Comments: true}
+}
+
+// NotesTestSuite is a test suite for record comments and tags
+type NotesTestSuite struct {
+	suite.Suite
+	state state.Store
+}
+
+// TestNotesSuite runs the record notes test suite
+func TestNotesSuite(t *testing.T) {
+	suite.Run(t, new(NotesTestSuite))
+}
+
+func (s *NotesTestSuite) SetupTest() {
+	s.state = state.NewFileStore(filepath.Join(s.T().TempDir(), "state.json"))
+}
+
+func (s *NotesTestSuite) TestService_KeepsNotesLocally() {
+	mock := newMockProvider("namecheap")
+	service := NewServiceWithProvider(mock)
+	service.SetNoteStore(NewNoteStore(s.state))
+
+	www := dnsrecord.Record{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800, Comment: "web server", Tags: []string{"prod", "owner:web"}}
+	s.Require().NoError(service.AddRecord("example.com", www))
+
+	// The provider never sees the notes
+	s.Require().Len(mock.records["example.com"], 1)
+	s.Require().False(mock.records["example.com"][0].Annotated())
+
+	// A fresh service reads them back from local state
+	service = NewServiceWithProvider(mock)
+	service.SetNoteStore(NewNoteStore(s.state))
+	records, err := service.GetRecords("example.com")
+	s.Require().NoError(err)
+	s.Require().Equal("web server", records[0].Comment)
+	s.Require().Equal([]string{"owner:web", "prod"}, records[0].Tags)
+
+	// Records written without notes keep theirs; deleted records lose them
+	s.Require().NoError(service.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "WWW", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.2", TTL: 300, Comment: "api"},
+	}))
+	var notes map[string]Note
+	found, err := s.state.Get(state.BucketNotes, "namecheap/example.com", &notes)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Require().Equal(map[string]Note{
+		"www A 192.0.2.1": {Comment: "web server", Tags: []string{"owner:web", "prod"}},
+		"api A 192.0.2.2": {Comment: "api"},
+	}, notes)
+
+	s.Require().NoError(service.DeleteAllRecords("example.com"))
+	found, err = s.state.Get(state.BucketNotes, "namecheap/example.com", &notes)
+	s.Require().NoError(err)
+	s.Require().False(found)
+}
+
+func (s *NotesTestSuite) TestService_NativeNotes() {
+	mock := &commentProvider{newMockProvider("cloudflare")}
+	service := NewServiceWithProvider(mock)
+	service.SetNoteStore(NewNoteStore(s.state))
+
+	www := dnsrecord.Record{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800, Comment: "web server"}
+	s.Require().NoError(service.AddRecord("example.com", www))
+
+	// Providers that store notes get them, and nothing is kept locally
+	s.Require().Equal("web server", mock.records["example.com"][0].Comment)
+	items, err := s.state.List(state.BucketNotes)
+	s.Require().NoError(err)
+	s.Require().Empty(items)
+}
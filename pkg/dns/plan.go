@@ -0,0 +1,112 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"zonekit/pkg/diffview"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+)
+
+// PlanFormatVersion is the version of the plan file format written by SavePlan
+const PlanFormatVersion = 1
+
+// Plan is a change to a zone saved for review, so it can be applied later
+// exactly as it was reviewed, possibly by someone else or by CI
+type Plan struct {
+	Version   int       `json:"version"`
+	Domain    string    `json:"domain"`
+	Provider  string    `json:"provider"`
+	Account   string    `json:"account,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// BaseHash is the ZoneHash of the zone the plan was made against; a plan is
+	// only applied to an unchanged zone
+	BaseHash string `json:"base_hash"`
+	// Changes are the reviewed changes, for reading only
+	Changes *diffview.Document `json:"changes"`
+	// Records are the records the zone has after the plan is applied
+	Records []PlanRecord `json:"records"`
+}
+
+// PlanRecord is a record of a plan
+type PlanRecord struct {
+	Hostname string `json:"hostname"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl,omitempty"`
+	MXPref   int    `json:"mx_pref,omitempty"`
+}
+
+// NewPlan returns the plan that turns current into desired
+func NewPlan(domainName, providerName string, current, desired []dnsrecord.Record) *Plan {
+	records := make([]PlanRecord, 0, len(desired))
+	for _, r := range desired {
+		records = append(records, PlanRecord{Hostname: r.HostName, Type: r.RecordType, Value: r.Address, TTL: r.TTL, MXPref: r.MXPref})
+	}
+	return &Plan{
+		Version:   PlanFormatVersion,
+		Domain:    domainName,
+		Provider:  providerName,
+		CreatedAt: time.Now().UTC(),
+		BaseHash:  ZoneHash(current),
+		Changes:   diffview.NewDocument(diffview.Compute(domainName, current, desired), diffview.Options{}),
+		Records:   records,
+	}
+}
+
+// DesiredRecords returns the records the zone has after the plan is applied
+func (p *Plan) DesiredRecords() []dnsrecord.Record {
+	records := make([]dnsrecord.Record, 0, len(p.Records))
+	for _, r := range p.Records {
+		records = append(records, dnsrecord.Record{HostName: r.Hostname, RecordType: r.Type, Address: r.Value, TTL: r.TTL, MXPref: r.MXPref})
+	}
+	return records
+}
+
+// SavePlan writes a plan as JSON
+func SavePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// LoadPlan reads a plan written by SavePlan
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if plan.Version != PlanFormatVersion {
+		return nil, fmt.Errorf("unsupported plan file version %d (expected %d)", plan.Version, PlanFormatVersion)
+	}
+	if plan.Domain == "" || plan.BaseHash == "" {
+		return nil, fmt.Errorf("plan file is missing its domain or base hash")
+	}
+	return &plan, nil
+}
+
+// CheckPlan returns the current records of the plan's domain, or a conflict
+// error if the zone changed since the plan was made
+func (s *Service) CheckPlan(plan *Plan) ([]dnsrecord.Record, error) {
+	current, err := s.GetRecords(plan.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing records: %w", err)
+	}
+	if ZoneHash(current) != plan.BaseHash {
+		return current, errors.NewConflict("zone", plan.Domain, "the zone changed since the plan was made; create a new plan")
+	}
+	return current, nil
+}
@@ -0,0 +1,57 @@
+package dns
+
+import (
+	"path/filepath"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+	zkerrors "zonekit/pkg/errors"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_SaveLoadApply(t *testing.T) {
+	mock := newMockProvider("mock")
+	mock.records["example.com"] = testutil.NewZone("example.com").TTL(300).Add(
+		testutil.A("@", "192.0.2.1"),
+		testutil.TXT("old", "gone"),
+	).Records()
+	service := NewServiceWithProvider(mock)
+
+	current, err := service.GetRecords("example.com")
+	require.NoError(t, err)
+	desired := []dnsrecord.Record{
+		testutil.NewRecord("@", "A", "192.0.2.1").TTL(300).Build(),
+		testutil.NewRecord("mail", "MX", "mx.example.com").MXPref(10).TTL(300).Build(),
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, SavePlan(path, NewPlan("example.com", "mock", current, desired)))
+
+	plan, err := LoadPlan(path)
+	require.NoError(t, err)
+	require.Equal(t, "mock", plan.Provider)
+	require.Equal(t, 1, plan.Changes.Summary.Add)
+	require.Equal(t, 1, plan.Changes.Summary.Remove)
+	require.Equal(t, desired, plan.DesiredRecords())
+
+	_, err = service.CheckPlan(plan)
+	require.NoError(t, err)
+
+	// Once the zone changes, the plan is stale
+	require.NoError(t, service.AddRecord("example.com", testutil.A("www", "192.0.2.2")))
+	_, err = service.CheckPlan(plan)
+	var conflict *zkerrors.ErrConflict
+	require.ErrorAs(t, err, &conflict)
+}
+
+func TestLoadPlan_Version(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := NewPlan("example.com", "mock", nil, nil)
+	plan.Version = 99
+	require.NoError(t, SavePlan(path, plan))
+
+	_, err := LoadPlan(path)
+	require.ErrorContains(t, err, "unsupported plan file version 99")
+}
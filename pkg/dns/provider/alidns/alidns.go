@@ -0,0 +1,319 @@
+// Package alidns implements the DNS provider for Alibaba Cloud DNS (Alidns).
+//
+// Alidns uses Alibaba Cloud's RPC API: every call is a GET with an Action
+// parameter, authenticated by an HMAC-SHA1 signature over the query string.
+// Records are addressed by their RecordId, so updates and deletions are
+// applied per record rather than by replacing the zone.
+package alidns
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "alidns"
+
+	// DefaultEndpoint is the global Alidns API endpoint
+	DefaultEndpoint = "https://alidns.aliyuncs.com"
+
+	// APIVersion is the Alidns API version this adapter speaks
+	APIVersion = "2015-01-09"
+
+	// pageSize is the largest page DescribeDomainRecords accepts
+	pageSize = 500
+	// domainsPageSize is the largest page DescribeDomains accepts
+	domainsPageSize = 100
+	// defaultTTL is the TTL Alidns gives records written without one
+	defaultTTL = 600
+)
+
+// Environment variables read by RegisterFromEnv; the names match the Alibaba Cloud CLI and SDKs
+const (
+	EnvAccessKeyID     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	EnvAccessKeySecret = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	EnvEndpoint        = "ALIDNS_ENDPOINT"
+)
+
+func init() {
+	_ = auth.RegisterSigner(Name, NewSigner)
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:            name,
+			AccessKeyID:     credentials["access_key_id"],
+			AccessKeySecret: credentials["access_key_secret"],
+			Endpoint:        credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the Alidns credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name            string
+	AccessKeyID     string
+	AccessKeySecret string
+	// Endpoint overrides DefaultEndpoint, e.g. for a regional endpoint
+	Endpoint string
+}
+
+// AlidnsProvider implements the DNS Provider interface for Alibaba Cloud DNS
+type AlidnsProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new Alidns provider
+func New(config Config) (*AlidnsProvider, error) {
+	signer, err := auth.NewSigner(Name, auth.Credentials{
+		"access_key_id":     config.AccessKeyID,
+		"access_key_secret": config.AccessKeySecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &AlidnsProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL:     endpoint,
+			Signer:      signer,
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *AlidnsProvider) Name() string {
+	return p.name
+}
+
+// record is a DNS record as returned by DescribeDomainRecords
+type record struct {
+	RecordID string `json:"RecordId"`
+	RR       string `json:"RR"`
+	Type     string `json:"Type"`
+	Value    string `json:"Value"`
+	TTL      int    `json:"TTL"`
+	Priority int    `json:"Priority"`
+}
+
+// describeResponse is the response of DescribeDomainRecords
+type describeResponse struct {
+	TotalCount    int `json:"TotalCount"`
+	DomainRecords struct {
+		Record []record `json:"Record"`
+	} `json:"DomainRecords"`
+}
+
+// GetRecords retrieves all DNS records for a domain
+func (p *AlidnsProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	var records []dnsrecord.Record
+
+	for page := 1; ; page++ {
+		var resp describeResponse
+		err := p.call("DescribeDomainRecords", map[string]string{
+			"DomainName": domainName,
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   strconv.Itoa(pageSize),
+		}, &resp)
+		if err != nil {
+			return nil, errors.NewAPI("DescribeDomainRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+		}
+
+		for _, r := range resp.DomainRecords.Record {
+			rec := dnsrecord.Record{
+				ID:         r.RecordID,
+				HostName:   r.RR,
+				RecordType: r.Type,
+				Address:    r.Value,
+				TTL:        r.TTL,
+			}
+			if r.Type == dnsrecord.RecordTypeMX {
+				rec.MXPref = r.Priority
+			}
+			records = append(records, rec)
+		}
+
+		if len(resp.DomainRecords.Record) == 0 || len(records) >= resp.TotalCount {
+			break
+		}
+	}
+
+	if records == nil {
+		records = []dnsrecord.Record{}
+	}
+	return records, nil
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Updated records keep their RecordId; a failing record does not stop the others.
+func (p *AlidnsProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	for i, r := range result.Records {
+		var err error
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			params := recordParams(r.Record)
+			params["DomainName"] = domainName
+			err = p.call("AddDomainRecord", params, nil)
+		case dnsprovider.ApplyUpdated:
+			params := recordParams(r.Record)
+			params["RecordId"] = r.Previous.ID
+			err = p.call("UpdateDomainRecord", params, nil)
+		case dnsprovider.ApplyDeleted:
+			err = p.call("DeleteDomainRecord", map[string]string{"RecordId": r.Previous.ID}, nil)
+		default:
+			continue
+		}
+		if err != nil {
+			result.Fail(i, err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// domainsResponse is the response of DescribeDomains
+type domainsResponse struct {
+	TotalCount int `json:"TotalCount"`
+	Domains    struct {
+		Domain []struct {
+			DomainName string `json:"DomainName"`
+		} `json:"Domain"`
+	} `json:"Domains"`
+}
+
+// ListZones returns the domains managed in Alibaba Cloud DNS
+func (p *AlidnsProvider) ListZones() ([]string, error) {
+	zones := []string{}
+
+	for page := 1; ; page++ {
+		var resp domainsResponse
+		err := p.call("DescribeDomains", map[string]string{
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   strconv.Itoa(domainsPageSize),
+		}, &resp)
+		if err != nil {
+			return nil, errors.NewAPI("DescribeDomains", "failed to list domains", err)
+		}
+
+		for _, d := range resp.Domains.Domain {
+			zones = append(zones, d.DomainName)
+		}
+
+		if len(resp.Domains.Domain) == 0 || len(zones) >= resp.TotalCount {
+			break
+		}
+	}
+
+	return zones, nil
+}
+
+// Capabilities reports the default TTL of Alidns records
+func (p *AlidnsProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *AlidnsProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("alidns client is not initialized")
+	}
+	return nil
+}
+
+// recordParams returns the API parameters describing a record
+func recordParams(r dnsrecord.Record) map[string]string {
+	params := map[string]string{
+		"RR":    r.HostName,
+		"Type":  r.RecordType,
+		"Value": r.Address,
+	}
+	if r.TTL > 0 {
+		params["TTL"] = strconv.Itoa(r.TTL)
+	}
+	if r.RecordType == dnsrecord.RecordTypeMX && r.MXPref > 0 {
+		params["Priority"] = strconv.Itoa(r.MXPref)
+	}
+	return params
+}
+
+// call invokes an API action and decodes the JSON response into target, if given
+func (p *AlidnsProvider) call(action string, params map[string]string, target interface{}) error {
+	query := map[string]string{
+		"Action":  action,
+		"Version": APIVersion,
+	}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	resp, err := p.client.Get(context.Background(), "/", query)
+	if err != nil {
+		return err
+	}
+
+	if target == nil {
+		resp.Body.Close()
+		return nil
+	}
+	return httpprovider.ParseJSONResponse(resp, target)
+}
+
+// Register registers an Alidns provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Alidns provider when its credentials are set in
+// the environment. It reports whether the provider was registered;
+// the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		AccessKeyID:     os.Getenv(EnvAccessKeyID),
+		AccessKeySecret: os.Getenv(EnvAccessKeySecret),
+		Endpoint:        os.Getenv(EnvEndpoint),
+	}
+	if config.AccessKeyID == "" || config.AccessKeySecret == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure AlidnsProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*AlidnsProvider)(nil)
+	_ dnsprovider.ZoneLister = (*AlidnsProvider)(nil)
+)
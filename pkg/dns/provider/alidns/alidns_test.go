@@ -0,0 +1,141 @@
+package alidns
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_DocumentedExample(t *testing.T) {
+	// Example from the Alidns signature documentation
+	s := &Signer{
+		AccessKeyID:     "testid",
+		AccessKeySecret: "testsecret",
+		nonce:           func() string { return "f59ed6a9-83fc-473b-9cc6-99c95df3856e" },
+	}
+
+	u, err := url.Parse("https://alidns.aliyuncs.com/?Action=DescribeDomainRecords&DomainName=example.com&Version=2015-01-09")
+	require.NoError(t, err)
+
+	req := &auth.SignableRequest{
+		Method:    http.MethodGet,
+		URL:       u,
+		Header:    http.Header{},
+		Timestamp: time.Date(2016, 3, 24, 16, 41, 54, 0, time.UTC),
+	}
+	// The documented example requests XML; sign the same parameters
+	require.NoError(t, s.Sign(req))
+	query := req.URL.Query()
+	query.Set("Format", "XML")
+	query.Del("Signature")
+
+	require.Equal(t, "uRpHwaSEt3J+6KQD//svCh/x+pI=", signature(http.MethodGet, canonicalQuery(query), "testsecret"))
+	require.Equal(t, "testid", req.URL.Query().Get("AccessKeyId"))
+	require.Equal(t, "2016-03-24T16:41:54Z", req.URL.Query().Get("Timestamp"))
+	require.NotEmpty(t, req.URL.Query().Get("Signature"))
+}
+
+func TestNewSigner_RequiresCredentials(t *testing.T) {
+	_, err := NewSigner(auth.Credentials{"access_key_id": "id"})
+	require.Error(t, err)
+
+	_, err = New(Config{AccessKeyID: "id"})
+	require.Error(t, err)
+}
+
+// fakeAlidns serves the subset of the Alidns API used by the provider and
+// verifies that every request is signed
+type fakeAlidns struct {
+	mu      sync.Mutex
+	records []record
+	calls   []url.Values
+}
+
+func (f *fakeAlidns) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := r.URL.Query()
+	sig := q.Get("Signature")
+	q.Del("Signature")
+	if sig != signature(r.Method, canonicalQuery(q), "secret") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Code":"SignatureDoesNotMatch"}`))
+		return
+	}
+	f.calls = append(f.calls, q)
+
+	switch q.Get("Action") {
+	case "DescribeDomainRecords":
+		// Serve one record per page to exercise pagination
+		page := 0
+		if q.Get("PageNumber") == "2" {
+			page = 1
+		}
+		resp := describeResponse{TotalCount: len(f.records)}
+		if page < len(f.records) {
+			resp.DomainRecords.Record = f.records[page : page+1]
+		}
+		json.NewEncoder(w).Encode(resp)
+	case "AddDomainRecord", "UpdateDomainRecord", "DeleteDomainRecord":
+		w.Write([]byte(`{"RequestId":"r"}`))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAlidnsProvider_GetAndSetRecords(t *testing.T) {
+	fake := &fakeAlidns{records: []record{
+		{RecordID: "1", RR: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+		{RecordID: "2", RR: "@", Type: "MX", Value: "mx.example.com", TTL: 600, Priority: 10},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p, err := New(Config{AccessKeyID: "id", AccessKeySecret: "secret", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "1", HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 600},
+		{ID: "2", HostName: "@", RecordType: "MX", Address: "mx.example.com", TTL: 600, MXPref: 10},
+	}, records)
+
+	fake.calls = nil
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.9", TTL: 600},
+		{HostName: "api", RecordType: "CNAME", Address: "www.example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	var actions []string
+	for _, call := range fake.calls {
+		actions = append(actions, call.Get("Action"))
+		switch call.Get("Action") {
+		case "UpdateDomainRecord":
+			require.Equal(t, "1", call.Get("RecordId"))
+			require.Equal(t, "192.0.2.9", call.Get("Value"))
+		case "AddDomainRecord":
+			require.Equal(t, "example.com", call.Get("DomainName"))
+			require.Equal(t, "api", call.Get("RR"))
+			require.Empty(t, call.Get("TTL"))
+		case "DeleteDomainRecord":
+			require.Equal(t, "2", call.Get("RecordId"))
+		}
+	}
+	require.Equal(t, []string{"DescribeDomainRecords", "DescribeDomainRecords", "UpdateDomainRecord", "AddDomainRecord", "DeleteDomainRecord"}, actions)
+}
@@ -0,0 +1,96 @@
+package alidns
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dns/provider/auth"
+)
+
+// Signer implements Alibaba Cloud's RPC signature (version 1.0, HMAC-SHA1).
+// The common parameters are added to the query string, which is then signed
+// as a whole and the result appended as the Signature parameter.
+type Signer struct {
+	AccessKeyID     string
+	AccessKeySecret string
+
+	nonce func() string
+}
+
+// NewSigner creates a signer from "access_key_id" and "access_key_secret" credentials
+func NewSigner(credentials auth.Credentials) (auth.RequestSigner, error) {
+	id, _ := credentials["access_key_id"].(string)
+	secret, _ := credentials["access_key_secret"].(string)
+	if id == "" || secret == "" {
+		return nil, fmt.Errorf("alidns requires access_key_id and access_key_secret")
+	}
+
+	return &Signer{AccessKeyID: id, AccessKeySecret: secret, nonce: randomNonce}, nil
+}
+
+// Sign adds the common parameters and the signature to the request's query string
+func (s *Signer) Sign(req *auth.SignableRequest) error {
+	query := req.URL.Query()
+	query.Del("Signature")
+	query.Set("AccessKeyId", s.AccessKeyID)
+	query.Set("Format", "JSON")
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("SignatureVersion", "1.0")
+	query.Set("SignatureNonce", s.nonce())
+	query.Set("Timestamp", req.Timestamp.UTC().Format(time.RFC3339))
+	if query.Get("Version") == "" {
+		query.Set("Version", APIVersion)
+	}
+
+	canonical := canonicalQuery(query)
+	req.URL.RawQuery = canonical + "&Signature=" + percentEncode(signature(req.Method, canonical, s.AccessKeySecret))
+	return nil
+}
+
+// signature computes the base64 HMAC-SHA1 of the string to sign
+func signature(method, canonicalQuery, secret string) string {
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(canonicalQuery)
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalQuery encodes the parameters sorted by name, percent-encoded per RFC 3986
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(query.Get(k)))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// percentEncode escapes s the way Alibaba Cloud expects: spaces as %20, '*' as %2A, '~' kept
+func percentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	encoded = strings.ReplaceAll(encoded, "%7E", "~")
+	return encoded
+}
+
+// randomNonce returns a random hex string; each request needs a unique nonce
+func randomNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -15,6 +15,8 @@ const (
 	MethodBasic  Method = "basic"
 	MethodBearer Method = "bearer"
 	MethodCustom Method = "custom"
+	MethodSigned Method = "signed"
+	MethodQuery  Method = "query"
 )
 
 // Credentials holds authentication credentials
@@ -28,6 +30,13 @@ type Authenticator interface {
 	Validate() error
 }
 
+// QueryParamsProvider is implemented by authenticators that send credentials
+// as query parameters instead of (or in addition to) headers
+type QueryParamsProvider interface {
+	// GetQueryParams returns parameters to add to every request URL
+	GetQueryParams() map[string]string
+}
+
 // NewAuthenticator creates an authenticator based on method and credentials
 func NewAuthenticator(method string, credentials Credentials) (Authenticator, error) {
 	switch Method(method) {
@@ -41,6 +50,8 @@ func NewAuthenticator(method string, credentials Credentials) (Authenticator, er
 		return NewOAuthAuthenticator(credentials)
 	case MethodCustom:
 		return NewCustomAuthenticator(credentials)
+	case MethodQuery:
+		return NewQueryAuthenticator(credentials)
 	default:
 		return nil, fmt.Errorf("unsupported authentication method: %s", method)
 	}
@@ -220,6 +231,53 @@ func (a *CustomAuthenticator) Validate() error {
 	return nil
 }
 
+// QueryAuthenticator sends credentials as URL query parameters, as APIs such
+// as ClouDNS require
+type QueryAuthenticator struct {
+	Params map[string]string
+}
+
+// NewQueryAuthenticator creates a query parameter authenticator from a
+// "params" map, or from an "api_key" sent as the "param" parameter
+// (api_key by default)
+func NewQueryAuthenticator(credentials Credentials) (*QueryAuthenticator, error) {
+	params := make(map[string]string)
+
+	if paramsMap, ok := credentials["params"].(map[string]interface{}); ok {
+		for k, v := range paramsMap {
+			params[k] = getEnvOrValue(v)
+		}
+	}
+	if apiKey := getEnvOrValue(credentials["api_key"]); apiKey != "" {
+		params[getStringValue(credentials["param"], "api_key")] = apiKey
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("params or api_key is required for query authentication")
+	}
+
+	return &QueryAuthenticator{Params: params}, nil
+}
+
+// GetHeaders returns no headers; the credentials travel in the query string
+func (a *QueryAuthenticator) GetHeaders() map[string]string {
+	return map[string]string{}
+}
+
+// GetQueryParams returns the credential parameters
+func (a *QueryAuthenticator) GetQueryParams() map[string]string {
+	return a.Params
+}
+
+func (a *QueryAuthenticator) Validate() error {
+	for k, v := range a.Params {
+		if v == "" {
+			return fmt.Errorf("query parameter %s is empty", k)
+		}
+	}
+	return nil
+}
+
 // Helper functions
 
 func getEnvOrValue(value interface{}) string {
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OVHSigner implements OVH's API request signature. Every request carries the
+// application and consumer keys, a Unix timestamp and a SHA-1 signature over
+// the application secret, consumer key, method, full URL, body and timestamp.
+type OVHSigner struct {
+	ApplicationKey    string
+	ApplicationSecret string
+	ConsumerKey       string
+}
+
+// NewOVHSigner creates a signer from "application_key", "application_secret"
+// and "consumer_key" credentials
+func NewOVHSigner(credentials Credentials) (RequestSigner, error) {
+	signer := &OVHSigner{
+		ApplicationKey:    getEnvOrValue(credentials["application_key"]),
+		ApplicationSecret: getEnvOrValue(credentials["application_secret"]),
+		ConsumerKey:       getEnvOrValue(credentials["consumer_key"]),
+	}
+	if signer.ApplicationKey == "" || signer.ApplicationSecret == "" || signer.ConsumerKey == "" {
+		return nil, fmt.Errorf("ovh requires application_key, application_secret and consumer_key")
+	}
+	return signer, nil
+}
+
+// Sign adds the OVH authentication headers to the request
+func (s *OVHSigner) Sign(req *SignableRequest) error {
+	timestamp := strconv.FormatInt(req.Timestamp.Unix(), 10)
+
+	req.Header.Set("X-Ovh-Application", s.ApplicationKey)
+	req.Header.Set("X-Ovh-Consumer", s.ConsumerKey)
+	req.Header.Set("X-Ovh-Timestamp", timestamp)
+	req.Header.Set("X-Ovh-Signature", OVHSignature(s.ApplicationSecret, s.ConsumerKey, req.Method, req.URL.String(), string(req.Body), timestamp))
+	return nil
+}
+
+// OVHSignature computes the value of the X-Ovh-Signature header
+func OVHSignature(applicationSecret, consumerKey, method, url, body, timestamp string) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{applicationSecret, consumerKey, method, url, body, timestamp}, "+")))
+	return "$1$" + hex.EncodeToString(sum[:])
+}
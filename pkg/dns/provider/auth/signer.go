@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SignableRequest is the view of an outgoing request given to a RequestSigner.
+// Signers may modify Header and URL (e.g. to add signature query parameters).
+type SignableRequest struct {
+	Method    string
+	URL       *url.URL
+	Header    http.Header
+	Body      []byte // encoded request body, nil when the request has none
+	Timestamp time.Time
+}
+
+// RequestSigner signs individual requests for providers whose authentication
+// cannot be expressed as static headers (OVH, DNS Made Easy, Alibaba Cloud, ...).
+// Sign is called for every attempt, including retries, with a fresh timestamp.
+type RequestSigner interface {
+	Sign(req *SignableRequest) error
+}
+
+// SignerFunc adapts a function to the RequestSigner interface
+type SignerFunc func(req *SignableRequest) error
+
+// Sign calls f(req)
+func (f SignerFunc) Sign(req *SignableRequest) error {
+	return f(req)
+}
+
+// SignerFactory creates a RequestSigner from the provider's credentials
+type SignerFactory func(credentials Credentials) (RequestSigner, error)
+
+var (
+	signers   = make(map[string]SignerFactory)
+	signersMu sync.RWMutex
+)
+
+// RegisterSigner registers the signer factory used by a provider configured
+// with the "signed" authentication method
+func RegisterSigner(provider string, factory SignerFactory) error {
+	if provider == "" {
+		return fmt.Errorf("provider name is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("signer factory for provider '%s' is nil", provider)
+	}
+
+	signersMu.Lock()
+	defer signersMu.Unlock()
+
+	if _, exists := signers[provider]; exists {
+		return fmt.Errorf("signer for provider '%s' is already registered", provider)
+	}
+
+	signers[provider] = factory
+	return nil
+}
+
+// UnregisterSigner removes the signer registered for a provider
+func UnregisterSigner(provider string) {
+	signersMu.Lock()
+	defer signersMu.Unlock()
+	delete(signers, provider)
+}
+
+// NewSigner creates the request signer registered for a provider
+func NewSigner(provider string, credentials Credentials) (RequestSigner, error) {
+	signersMu.RLock()
+	factory, exists := signers[provider]
+	signersMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("no request signer registered for provider '%s'", provider)
+	}
+
+	return factory(credentials)
+}
+
+// SignerProviders returns the names of providers with a registered signer
+func SignerProviders() []string {
+	signersMu.RLock()
+	defer signersMu.RUnlock()
+
+	names := make([]string, 0, len(signers))
+	for name := range signers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	dnsprovider "zonekit/pkg/dns/provider"
 	"zonekit/pkg/dns/provider/builder"
@@ -11,7 +12,9 @@ import (
 )
 
 // DiscoverAndRegister discovers all providers from subdirectories and registers them
-// Scans pkg/dns/provider/*/ directories for openapi.yaml files (OpenAPI-only approach)
+// Scans pkg/dns/provider/*/ directories for openapi.yaml files (OpenAPI-only approach).
+// Providers are registered lazily: a spec is only loaded and its provider built
+// when the provider, or a named instance of it, is first used.
 func DiscoverAndRegister(baseDir string) error {
 	if baseDir == "" {
 		baseDir = findProviderDirectory()
@@ -25,7 +28,6 @@ func DiscoverAndRegister(baseDir string) error {
 		return fmt.Errorf("failed to read provider directory: %w", err)
 	}
 
-	var errors []error
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -53,37 +55,73 @@ func DiscoverAndRegister(baseDir string) error {
 			continue
 		}
 
-		// Load OpenAPI spec and convert to config
-		spec, err := openapi.LoadSpec(specPath)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to load OpenAPI spec for %s: %w", name, err))
-			continue
-		}
+		loadConfig := specLoader(name, specPath)
 
-		cfg, err := spec.ToProviderConfig(name)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to convert OpenAPI spec for %s: %w", name, err))
-			continue
-		}
+		// Allow named instances of this provider with their own credentials
+		_ = dnsprovider.RegisterFactory(name, instanceFactory(name, loadConfig))
+
+		// Provider might already be registered, that's okay
+		_ = dnsprovider.RegisterLazy(name, func() (dnsprovider.Provider, error) {
+			cfg, err := loadConfig()
+			if err != nil {
+				return nil, err
+			}
+			return builder.BuildProvider(cfg)
+		})
+	}
+
+	return nil
+}
 
-		provider, err := builder.BuildProvider(cfg)
+// specLoader returns a function that loads the provider config from an OpenAPI
+// spec the first time it is called
+func specLoader(name, specPath string) func() (*dnsprovider.Config, error) {
+	var (
+		once sync.Once
+		cfg  *dnsprovider.Config
+		err  error
+	)
+
+	return func() (*dnsprovider.Config, error) {
+		once.Do(func() {
+			var spec *openapi.Spec
+			spec, err = openapi.LoadSpec(specPath)
+			if err != nil {
+				err = fmt.Errorf("failed to load OpenAPI spec for %s: %w", name, err)
+				return
+			}
+
+			cfg, err = spec.ToProviderConfig(name)
+			if err != nil {
+				err = fmt.Errorf("failed to convert OpenAPI spec for %s: %w", name, err)
+			}
+		})
+		return cfg, err
+	}
+}
+
+// instanceFactory returns a factory building instances of the provider base
+// whose config loadConfig returns. Instance credentials override the spec's
+// default credentials.
+func instanceFactory(base string, loadConfig func() (*dnsprovider.Config, error)) dnsprovider.Factory {
+	return func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		cfg, err := loadConfig()
 		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to build %s provider: %w", name, err))
-			continue
+			return nil, err
 		}
 
-		if err := dnsprovider.Register(provider); err != nil {
-			// Provider might already be registered, that's okay
-			continue
+		instance := *cfg
+		instance.Name = name
+		instance.Base = base
+		instance.Auth.Credentials = make(map[string]interface{}, len(cfg.Auth.Credentials)+len(credentials))
+		for k, v := range cfg.Auth.Credentials {
+			instance.Auth.Credentials[k] = v
 		}
+		for k, v := range credentials {
+			instance.Auth.Credentials[k] = v
+		}
+		return builder.BuildProvider(&instance)
 	}
-
-	// Return first error if any, but don't fail completely
-	if len(errors) > 0 {
-		return errors[0]
-	}
-
-	return nil
 }
 
 // findProviderDirectory finds the provider directory
@@ -0,0 +1,141 @@
+// Package axfr reads whole zones with a DNS zone transfer (AXFR, RFC 5936).
+// It is used by providers whose API cannot list records but whose name
+// servers allow transfers, such as Hurricane Electric's free DNS.
+package axfr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/dns/dnsmessage"
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dnsrecord"
+)
+
+// DefaultTimeout bounds a whole transfer
+const DefaultTimeout = 30 * time.Second
+
+// Transfer requests the zone from server (host:port) and returns its records
+// with host names relative to the zone ("@" for the apex). The SOA record is
+// omitted, as are record types zonekit does not manage.
+func Transfer(ctx context.Context, server, zone string) ([]dnsrecord.Record, error) {
+	return TransferWithKey(ctx, server, zone, nil)
+}
+
+// TransferWithKey is Transfer with the query signed by a TSIG key, for servers
+// that only allow transfers to key holders; every response message must be
+// signed with the key as well. A nil key sends an unsigned query.
+func TransferWithKey(ctx context.Context, server, zone string, key *Key) ([]dnsrecord.Record, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	origin := dns.Fqdn(zone)
+	if _, ok := dns.IsDomainName(origin); !ok {
+		return nil, fmt.Errorf("invalid zone %s", zone)
+	}
+
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+	conn := &dns.Conn{Conn: netConn}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	query := new(dns.Msg).SetAxfr(origin)
+	var out []byte
+	var mac string
+	if key != nil {
+		key.Sign(query)
+		out, mac, err = dns.TsigGenerate(query, key.Secret, "", false)
+	} else {
+		out, err = query.Pack()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build AXFR query: %w", err)
+	}
+	if _, err := conn.Write(out); err != nil {
+		return nil, fmt.Errorf("failed to send AXFR query: %w", err)
+	}
+
+	// The transfer is a stream of messages framed by the zone's SOA record.
+	// miekg/dns's Transfer passes unsigned messages through, so the messages
+	// are read and verified here.
+	var records []dnsrecord.Record
+	soaSeen := 0
+	for first := true; soaSeen < 2; first = false {
+		raw, err := conn.ReadMsgHeader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AXFR response from %s: %w", server, err)
+		}
+		msg := new(dns.Msg)
+		if err := msg.Unpack(raw); err != nil {
+			return nil, fmt.Errorf("invalid AXFR response: %w", err)
+		}
+		if msg.Id != query.Id {
+			return nil, fmt.Errorf("invalid AXFR response: unexpected message ID")
+		}
+		if key != nil {
+			// Messages after the first are signed over the TSIG timers only
+			if err := key.Verify(raw, msg, mac, !first); err != nil {
+				return nil, fmt.Errorf("unverified AXFR response from %s (%s): %w", server, dns.RcodeToString[msg.Rcode], err)
+			}
+			mac = msg.IsTsig().MAC
+		}
+		if msg.Rcode != dns.RcodeSuccess {
+			return nil, fmt.Errorf("zone transfer of %s refused by %s: %s", zone, server, dns.RcodeToString[msg.Rcode])
+		}
+		if len(msg.Answer) == 0 {
+			return nil, fmt.Errorf("zone transfer of %s from %s returned no records", zone, server)
+		}
+
+		for _, rr := range msg.Answer {
+			if rr.Header().Rrtype == dns.TypeSOA {
+				soaSeen++
+			}
+		}
+		converted, err := toRecords(raw, origin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AXFR response: %w", err)
+		}
+		records = append(records, converted...)
+	}
+
+	return records, nil
+}
+
+// toRecords reads the answers of a response with lookup.ToRecord, so that
+// transferred records compare equal to those of other lookups. SOA records
+// and types zonekit does not manage are left out.
+func toRecords(raw []byte, origin string) ([]dnsrecord.Record, error) {
+	var p dnsmessage.Parser
+	if _, err := p.Start(raw); err != nil {
+		return nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil, err
+	}
+	var records []dnsrecord.Record
+	for _, rr := range answers {
+		if rr.Header.Type == dnsmessage.TypeSOA {
+			continue
+		}
+		if record, ok := lookup.ToRecord(rr, origin); ok {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,155 @@
+package axfr
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// axfrServer answers AXFR queries on a local TCP listener, sending the records
+// split over two messages, framed by the SOA record. With a key, queries must
+// be signed with it and so are the first signed response messages; secret, if
+// set, signs them with another secret instead.
+type axfrServer struct {
+	key     *Key
+	secret  string
+	signed  int
+	rcode   int
+	records []dns.RR
+}
+
+func (f *axfrServer) serve(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{Listener: ln, Net: "tcp", Handler: dns.HandlerFunc(f.handle), NotifyStartedFunc: func() { close(started) }}
+	if f.key != nil {
+		secret := f.key.Secret
+		if f.secret != "" {
+			secret = f.secret
+		}
+		server.TsigSecret = map[string]string{f.key.Name: secret}
+	}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return ln.Addr().String()
+}
+
+func (f *axfrServer) handle(w dns.ResponseWriter, r *dns.Msg) {
+	// With the wrong secret the query cannot be verified either, but it is
+	// answered all the same
+	if f.key != nil && (r.IsTsig() == nil || (w.TsigStatus() != nil && f.secret == "")) {
+		w.WriteMsg(new(dns.Msg).SetRcode(r, dns.RcodeRefused))
+		return
+	}
+
+	soa := &dns.SOA{
+		Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+		Ns:  "ns1.he.net.", Mbox: "hostmaster.he.net.",
+		Serial: 1, Refresh: 3600, Retry: 600, Expire: 86400, Minttl: 300,
+	}
+	half := len(f.records) / 2
+	batches := [][]dns.RR{
+		append([]dns.RR{soa}, f.records[:half]...),
+		append(append([]dns.RR{}, f.records[half:]...), soa),
+	}
+	if f.rcode != dns.RcodeSuccess {
+		batches = [][]dns.RR{nil}
+	}
+
+	for i, batch := range batches {
+		m := new(dns.Msg).SetRcode(r, f.rcode)
+		m.Compress = true
+		m.Answer = batch
+		if f.key != nil && i < f.signed {
+			m.SetTsig(f.key.Name, f.key.Algorithm, Fudge, time.Now().Unix())
+		}
+		// Messages after the first are signed over the TSIG timers only
+		w.TsigTimersOnly(i > 0)
+		if err := w.WriteMsg(m); err != nil {
+			return
+		}
+	}
+}
+
+func rr(t *testing.T, s string) dns.RR {
+	record, err := dns.NewRR(s)
+	require.NoError(t, err)
+	return record
+}
+
+func TestTransfer(t *testing.T) {
+	server := (&axfrServer{records: []dns.RR{
+		rr(t, "example.com. 300 IN A 192.0.2.1"),
+		rr(t, "example.com. 300 IN MX 10 mail.example.com."),
+		rr(t, "home.example.com. 300 IN A 198.51.100.7"),
+		rr(t, `_acme-challenge.example.com. 300 IN TXT "abc" "def"`),
+		rr(t, `example.com. 300 IN CAA 0 issue "letsencrypt.org"`),
+	}}).serve(t)
+
+	records, err := Transfer(context.Background(), server, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{HostName: "@", RecordType: "MX", Address: "mail.example.com.", TTL: 300, MXPref: 10},
+		{HostName: "home", RecordType: "A", Address: "198.51.100.7", TTL: 300},
+		{HostName: "_acme-challenge", RecordType: "TXT", Address: "abcdef", TTL: 300},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 300},
+	}, records)
+}
+
+func TestTransfer_Refused(t *testing.T) {
+	server := (&axfrServer{rcode: dns.RcodeRefused}).serve(t)
+
+	_, err := Transfer(context.Background(), server, "example.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "refused")
+}
+
+func TestTransferWithKey_VerifiesResponse(t *testing.T) {
+	key, err := ParseKey("zonekit", "", "c2VjcmV0")
+	require.NoError(t, err)
+	records := []dns.RR{
+		rr(t, "example.com. 300 IN A 192.0.2.1"),
+		rr(t, "www.example.com. 300 IN A 192.0.2.2"),
+	}
+
+	transferred, err := TransferWithKey(context.Background(), (&axfrServer{key: key, signed: 2, records: records}).serve(t), "example.com", key)
+	require.NoError(t, err)
+	require.Len(t, transferred, 2)
+
+	// Without the key the transfer is refused
+	_, err = Transfer(context.Background(), (&axfrServer{key: key, signed: 2, records: records}).serve(t), "example.com")
+	require.ErrorContains(t, err, "refused")
+
+	_, err = TransferWithKey(context.Background(), (&axfrServer{key: key, signed: 0, records: records}).serve(t), "example.com", key)
+	require.ErrorContains(t, err, "response is not signed")
+
+	// Every message must be signed, not only the first
+	_, err = TransferWithKey(context.Background(), (&axfrServer{key: key, signed: 1, records: records}).serve(t), "example.com", key)
+	require.ErrorContains(t, err, "response is not signed")
+
+	_, err = TransferWithKey(context.Background(), (&axfrServer{key: key, secret: "b3RoZXI=", signed: 2, records: records}).serve(t), "example.com", key)
+	require.ErrorContains(t, err, "response signature does not match key zonekit.")
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey("Zonekit", "HMAC-SHA512", "c2VjcmV0")
+	require.NoError(t, err)
+	require.Equal(t, &Key{Name: "zonekit.", Algorithm: dns.HmacSHA512, Secret: "c2VjcmV0"}, key)
+
+	_, err = ParseKey("", "", "c2VjcmV0")
+	require.ErrorContains(t, err, "name is required")
+	_, err = ParseKey("zonekit", "hmac-md5", "c2VjcmV0")
+	require.ErrorContains(t, err, "unsupported TSIG algorithm")
+	_, err = ParseKey("zonekit", "", "not base64!")
+	require.ErrorContains(t, err, "base64")
+}
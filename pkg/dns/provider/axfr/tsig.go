@@ -0,0 +1,105 @@
+package axfr
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Fudge is the clock skew, in seconds, signatures are made and accepted with
+const Fudge = 300
+
+// algorithms are the TSIG algorithms keys may use (RFC 8945 section 6)
+var algorithms = []string{dns.HmacSHA1, dns.HmacSHA256, dns.HmacSHA384, dns.HmacSHA512}
+
+// Key is a TSIG key (RFC 8945) as configured on the name server, which signs
+// zone transfers and dynamic updates
+type Key struct {
+	// Name is the key name, e.g. "zonekit." for `key "zonekit" { ... }` in BIND
+	Name string
+	// Algorithm is one of the HMAC algorithm names, e.g. hmac-sha256.
+	Algorithm string
+	// Secret is the base64 key secret
+	Secret string
+}
+
+// ParseKey creates a key from its name, algorithm and base64 secret, as they
+// appear in BIND and Knot configuration. The algorithm defaults to hmac-sha256;
+// the trailing dot of names is optional.
+func ParseKey(name, algorithm, secret string) (*Key, error) {
+	if name == "" {
+		return nil, fmt.Errorf("TSIG key name is required")
+	}
+	if algorithm == "" {
+		algorithm = dns.HmacSHA256
+	}
+	algorithm = dns.CanonicalName(algorithm)
+	supported := false
+	for _, a := range algorithms {
+		supported = supported || a == algorithm
+	}
+	if !supported {
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q: use hmac-sha1, hmac-sha256, hmac-sha384 or hmac-sha512", strings.TrimSuffix(algorithm, "."))
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(decoded) == 0 {
+		return nil, fmt.Errorf("TSIG secret for key %s must be base64 encoded", name)
+	}
+	return &Key{Name: dns.CanonicalName(name), Algorithm: algorithm, Secret: secret}, nil
+}
+
+// Secrets returns the key in the form miekg/dns clients and servers take it
+func (k *Key) Secrets() map[string]string {
+	return map[string]string{k.Name: k.Secret}
+}
+
+// Sign adds the TSIG record a request is signed with when it is sent by a
+// client holding Secrets
+func (k *Key) Sign(msg *dns.Msg) {
+	msg.SetTsig(k.Name, k.Algorithm, Fudge, time.Now().Unix())
+}
+
+// Verify checks that raw, received as msg in response to a request signed
+// with the key, is signed by the key too. requestMAC is the MAC of the request,
+// or of the previous message of a multi-message response, whose later messages
+// are signed over the TSIG timers only.
+func (k *Key) Verify(raw []byte, msg *dns.Msg, requestMAC string, timersOnly bool) error {
+	var err error
+	if msg.IsTsig() != nil {
+		err = dns.TsigVerify(raw, k.Secret, requestMAC, timersOnly)
+	}
+	return k.Check(msg, err)
+}
+
+// Check checks that msg, a response to a request signed with the key, is
+// signed by the key too. verifyErr is the error miekg/dns verifying the
+// signature returned, if any.
+func (k *Key) Check(msg *dns.Msg, verifyErr error) error {
+	rr := msg.IsTsig()
+	if rr == nil {
+		return fmt.Errorf("response is not signed")
+	}
+	if dns.CanonicalName(rr.Hdr.Name) != k.Name || dns.CanonicalName(rr.Algorithm) != k.Algorithm {
+		return fmt.Errorf("response is signed with key %s (%s), not %s (%s)",
+			rr.Hdr.Name, strings.TrimSuffix(rr.Algorithm, "."), k.Name, strings.TrimSuffix(k.Algorithm, "."))
+	}
+	if rr.Error != dns.RcodeSuccess {
+		return fmt.Errorf("server rejected the request signature: %s", dns.RcodeToString[int(rr.Error)])
+	}
+	switch {
+	case errors.Is(verifyErr, dns.ErrAuth):
+		// miekg/dns does not verify NOTAUTH responses; they report a failure
+		// either way, which is left to the caller
+		return nil
+	case errors.Is(verifyErr, dns.ErrTime):
+		return fmt.Errorf("signature time is more than %ds off; check the clocks", rr.Fudge)
+	case verifyErr != nil:
+		return fmt.Errorf("response signature does not match key %s: %w", k.Name, verifyErr)
+	}
+	return nil
+}
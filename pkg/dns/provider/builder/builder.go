@@ -9,6 +9,7 @@ import (
 	httpprovider "zonekit/pkg/dns/provider/http"
 	"zonekit/pkg/dns/provider/mapper"
 	"zonekit/pkg/dns/provider/rest"
+	"zonekit/pkg/ratelimit"
 )
 
 // BuildProvider creates a DNS provider from configuration
@@ -17,18 +18,32 @@ func BuildProvider(config *dnsprovider.Config) (dnsprovider.Provider, error) {
 		return nil, fmt.Errorf("invalid provider config: %w", err)
 	}
 
-	// Create authenticator
-	authenticator, err := auth.NewAuthenticator(config.Auth.Method, config.Auth.Credentials)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create authenticator: %w", err)
-	}
+	// Providers using the "signed" method authenticate each request with the
+	// signer they registered; all other methods use static auth headers
+	var signer auth.RequestSigner
+	authHeaders := map[string]string{}
+	var authQuery map[string]string
+	if auth.Method(config.Auth.Method) == auth.MethodSigned {
+		var err error
+		signer, err = auth.NewSigner(config.Name, config.Auth.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request signer: %w", err)
+		}
+	} else {
+		authenticator, err := auth.NewAuthenticator(config.Auth.Method, config.Auth.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create authenticator: %w", err)
+		}
 
-	if err := authenticator.Validate(); err != nil {
-		return nil, fmt.Errorf("authenticator validation failed: %w", err)
-	}
+		if err := authenticator.Validate(); err != nil {
+			return nil, fmt.Errorf("authenticator validation failed: %w", err)
+		}
 
-	// Get auth headers
-	authHeaders := authenticator.GetHeaders()
+		authHeaders = authenticator.GetHeaders()
+		if q, ok := authenticator.(auth.QueryParamsProvider); ok {
+			authQuery = q.GetQueryParams()
+		}
+	}
 
 	// Merge with configured headers
 	headers := make(map[string]string)
@@ -39,12 +54,21 @@ func BuildProvider(config *dnsprovider.Config) (dnsprovider.Provider, error) {
 		headers[k] = v
 	}
 
+	// Instances share their base provider's default rate, but not its limiter
+	base := config.Base
+	if base == "" {
+		base = config.Name
+	}
+
 	// Create HTTP client
 	httpClient := httpprovider.NewClient(httpprovider.ClientConfig{
-		BaseURL: config.API.BaseURL,
-		Headers: headers,
-		Timeout: time.Duration(config.API.Timeout) * time.Second,
-		Retries: config.API.Retries,
+		BaseURL:     config.API.BaseURL,
+		Headers:     headers,
+		Query:       authQuery,
+		Timeout:     time.Duration(config.API.Timeout) * time.Second,
+		Retries:     config.API.Retries,
+		Signer:      signer,
+		RateLimiter: ratelimit.For(config.Name, base),
 	})
 
 	// Build provider based on type
@@ -127,6 +151,10 @@ func buildMappings(configMappings *dnsprovider.FieldMappings) mapper.Mappings {
 		m.Request.ID = ""
 	}
 
+	// Providers without comment or tags fields have none by default
+	m.Request.Comment = configMappings.Request.Comment
+	m.Request.Tags = configMappings.Request.Tags
+
 	// Response mappings
 	if configMappings.Response.HostName != "" {
 		m.Response.HostName = configMappings.Response.HostName
@@ -164,6 +192,9 @@ func buildMappings(configMappings *dnsprovider.FieldMappings) mapper.Mappings {
 		m.Response.ID = ""
 	}
 
+	m.Response.Comment = configMappings.Response.Comment
+	m.Response.Tags = configMappings.Response.Tags
+
 	return m
 }
 
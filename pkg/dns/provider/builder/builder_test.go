@@ -1,8 +1,11 @@
 package builder
 
 import (
+	"fmt"
 	"testing"
 
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
 	"zonekit/pkg/dns/provider/openapi"
 
 	"github.com/stretchr/testify/require"
@@ -23,3 +26,27 @@ func TestBuildProvider_FromCloudflareSpec(t *testing.T) {
 	// Validate provider
 	require.NoError(t, prov.Validate())
 }
+
+func TestBuildProvider_SignedRequiresRegisteredSigner(t *testing.T) {
+	cfg := &dnsprovider.Config{Name: "exotic", Type: "rest"}
+	cfg.Auth.Method = string(auth.MethodSigned)
+	cfg.Auth.Credentials = map[string]interface{}{"app_key": "k", "app_secret": "s"}
+	cfg.API.BaseURL = "https://api.example.com"
+	cfg.API.Endpoints = map[string]string{"get_records": "/zones/{zone}/records"}
+
+	_, err := BuildProvider(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no request signer registered for provider 'exotic'")
+
+	var gotCredentials auth.Credentials
+	require.NoError(t, auth.RegisterSigner("exotic", func(credentials auth.Credentials) (auth.RequestSigner, error) {
+		gotCredentials = credentials
+		return auth.SignerFunc(func(req *auth.SignableRequest) error { return nil }), nil
+	}))
+	defer auth.UnregisterSigner("exotic")
+
+	_, err = BuildProvider(cfg)
+	require.NotContains(t, fmt.Sprint(err), "signer")
+	require.Equal(t, "k", gotCredentials["app_key"])
+	require.Error(t, auth.RegisterSigner("exotic", func(auth.Credentials) (auth.RequestSigner, error) { return nil, nil }))
+}
@@ -0,0 +1,403 @@
+// Package bunny implements the DNS provider for Bunny DNS (bunny.net).
+//
+// Bunny addresses zones and records by numeric ID and encodes record types as
+// integers. Records of Bunny-specific types (redirects, pull zones, scripts
+// and flattened CNAMEs) are not returned, so they are never changed or
+// deleted. Records are applied one by one.
+package bunny
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "bunny"
+
+	// DefaultEndpoint is the Bunny API endpoint
+	DefaultEndpoint = "https://api.bunny.net"
+
+	// defaultTTL is the TTL of records written without one
+	defaultTTL = 300
+
+	// zonesPerPage is the largest page the zone list returns
+	zonesPerPage = 1000
+)
+
+// Environment variables read by RegisterFromEnv; the name matches lego's bunny provider
+const (
+	EnvAPIKey = "BUNNY_API_KEY"
+)
+
+// recordTypes maps Bunny's record type numbers to record types. Types Bunny
+// adds on top of DNS (5 redirect, 6 flatten, 7 pull zone, 11 script) are left out.
+var recordTypes = map[int]string{
+	0:  dnsrecord.RecordTypeA,
+	1:  dnsrecord.RecordTypeAAAA,
+	2:  dnsrecord.RecordTypeCNAME,
+	3:  dnsrecord.RecordTypeTXT,
+	4:  dnsrecord.RecordTypeMX,
+	8:  dnsrecord.RecordTypeSRV,
+	9:  dnsrecord.RecordTypeCAA,
+	10: dnsrecord.RecordTypePTR,
+	12: dnsrecord.RecordTypeNS,
+}
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			APIKey:   credentials["api_key"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the Bunny credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name string
+	// APIKey is the account API key
+	APIKey string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// BunnyProvider implements the DNS Provider interface for Bunny DNS
+type BunnyProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new Bunny provider
+func New(config Config) (*BunnyProvider, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("bunny requires an API key")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &BunnyProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL: strings.TrimSuffix(endpoint, "/"),
+			Headers: map[string]string{
+				"AccessKey": config.APIKey,
+				"Accept":    "application/json",
+			},
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *BunnyProvider) Name() string {
+	return p.name
+}
+
+// zone is a DNS zone as returned by the zone endpoints
+type zone struct {
+	ID      int64    `json:"Id"`
+	Domain  string   `json:"Domain"`
+	Records []record `json:"Records,omitempty"`
+}
+
+// zoneList is a page of the zone list
+type zoneList struct {
+	Items        []zone `json:"Items"`
+	HasMoreItems bool   `json:"HasMoreItems"`
+}
+
+// record is a DNS record as the Bunny API represents it
+type record struct {
+	ID       int64  `json:"Id,omitempty"`
+	Type     int    `json:"Type"`
+	Name     string `json:"Name"`
+	Value    string `json:"Value"`
+	TTL      int    `json:"Ttl"`
+	Priority int    `json:"Priority"`
+	Weight   int    `json:"Weight"`
+	Port     int    `json:"Port"`
+	Flags    int    `json:"Flags"`
+	Tag      string `json:"Tag"`
+}
+
+// GetRecords retrieves all DNS records for a domain
+func (p *BunnyProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	z, err := p.zone(domainName)
+	if err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	records := make([]dnsrecord.Record, 0, len(z.Records))
+	for _, r := range z.Records {
+		if rec, ok := toRecord(r); ok {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// toRecord converts a Bunny record, whose apex name is empty. It reports false
+// for records of Bunny-specific types.
+func toRecord(r record) (dnsrecord.Record, bool) {
+	recordType, ok := recordTypes[r.Type]
+	if !ok {
+		return dnsrecord.Record{}, false
+	}
+	rec := dnsrecord.Record{
+		ID:         strconv.FormatInt(r.ID, 10),
+		HostName:   r.Name,
+		RecordType: recordType,
+		Address:    r.Value,
+		TTL:        r.TTL,
+	}
+	if rec.HostName == "" {
+		rec.HostName = "@"
+	}
+	switch recordType {
+	case dnsrecord.RecordTypeMX:
+		rec.MXPref = r.Priority
+	case dnsrecord.RecordTypeSRV:
+		rec.Address = fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Value)
+	case dnsrecord.RecordTypeCAA:
+		rec.Address = fmt.Sprintf("%d %s %q", r.Flags, r.Tag, r.Value)
+	}
+	return rec, true
+}
+
+// fromRecord converts a record into a Bunny record
+func fromRecord(r dnsrecord.Record) (record, error) {
+	out := record{Name: r.HostName, Value: r.Address, TTL: r.TTL, Type: -1}
+	if out.Name == "@" {
+		out.Name = ""
+	}
+	if out.TTL <= 0 {
+		out.TTL = defaultTTL
+	}
+	for number, recordType := range recordTypes {
+		if strings.EqualFold(recordType, r.RecordType) {
+			out.Type = number
+		}
+	}
+	if out.Type < 0 {
+		return out, fmt.Errorf("bunny does not support %s records", r.RecordType)
+	}
+
+	switch recordTypes[out.Type] {
+	case dnsrecord.RecordTypeMX:
+		out.Priority = r.MXPref
+	case dnsrecord.RecordTypeSRV:
+		fields := strings.Fields(r.Address)
+		if len(fields) != 4 {
+			return out, fmt.Errorf("invalid SRV record %q: expected priority, weight, port and target", r.Address)
+		}
+		var numbers [3]int
+		for i := range numbers {
+			n, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return out, fmt.Errorf("invalid SRV record %q: %w", r.Address, err)
+			}
+			numbers[i] = n
+		}
+		out.Priority, out.Weight, out.Port, out.Value = numbers[0], numbers[1], numbers[2], fields[3]
+	case dnsrecord.RecordTypeCAA:
+		fields := strings.SplitN(strings.TrimSpace(r.Address), " ", 3)
+		if len(fields) != 3 {
+			return out, fmt.Errorf(`invalid CAA record %q: expected flags, tag and "value"`, r.Address)
+		}
+		flags, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return out, fmt.Errorf("invalid CAA record %q: %w", r.Address, err)
+		}
+		value := fields[2]
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		out.Flags, out.Tag, out.Value = flags, fields[1], value
+	}
+	return out, nil
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Updated records keep their ID; a failing record does not stop the others.
+func (p *BunnyProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	z, err := p.zone(domainName)
+	if err != nil {
+		return nil, errors.NewAPI("SetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+	var existing []dnsrecord.Record
+	for _, r := range z.Records {
+		if rec, ok := toRecord(r); ok {
+			existing = append(existing, rec)
+		}
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+	recordsPath := fmt.Sprintf("/dnszone/%d/records", z.ID)
+
+	for i, r := range result.Records {
+		var err error
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			var body record
+			if body, err = fromRecord(r.Record); err == nil {
+				err = discard(p.client.Put(context.Background(), recordsPath, body))
+			}
+		case dnsprovider.ApplyUpdated:
+			var body record
+			if body, err = fromRecord(r.Record); err == nil {
+				body.ID, _ = strconv.ParseInt(r.Previous.ID, 10, 64)
+				err = discard(p.client.Post(context.Background(), recordsPath+"/"+r.Previous.ID, body))
+			}
+		case dnsprovider.ApplyDeleted:
+			err = discard(p.client.Delete(context.Background(), recordsPath+"/"+r.Previous.ID))
+		default:
+			continue
+		}
+		if err != nil {
+			result.Fail(i, err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// ListZones returns the zones of the Bunny account
+func (p *BunnyProvider) ListZones() ([]string, error) {
+	var zones []string
+	for page := 1; ; page++ {
+		list, err := p.listZones(page, "")
+		if err != nil {
+			return nil, errors.NewAPI("ListZones", "failed to list zones", err)
+		}
+		for _, z := range list.Items {
+			zones = append(zones, z.Domain)
+		}
+		if !list.HasMoreItems || len(list.Items) == 0 {
+			return zones, nil
+		}
+	}
+}
+
+// Capabilities reports Bunny's default TTL and the record types it manages
+func (p *BunnyProvider) Capabilities() dnsprovider.Capabilities {
+	types := make([]string, 0, len(recordTypes))
+	for number := 0; number <= 12; number++ {
+		if recordType, ok := recordTypes[number]; ok {
+			types = append(types, recordType)
+		}
+	}
+	return dnsprovider.Capabilities{SupportedRecordTypes: types, DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *BunnyProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("bunny client is not initialized")
+	}
+	return nil
+}
+
+// zone looks up a zone by domain name and returns it with its records
+func (p *BunnyProvider) zone(domainName string) (*zone, error) {
+	domainName = strings.TrimSuffix(domainName, ".")
+	for page := 1; ; page++ {
+		list, err := p.listZones(page, domainName)
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range list.Items {
+			if !strings.EqualFold(z.Domain, domainName) {
+				continue
+			}
+			resp, err := p.client.Get(context.Background(), fmt.Sprintf("/dnszone/%d", z.ID), nil)
+			if err != nil {
+				return nil, err
+			}
+			var full zone
+			if err := httpprovider.ParseJSONResponse(resp, &full); err != nil {
+				return nil, err
+			}
+			return &full, nil
+		}
+		if !list.HasMoreItems || len(list.Items) == 0 {
+			return nil, fmt.Errorf("zone %s not found", domainName)
+		}
+	}
+}
+
+// listZones returns a page of zones, optionally filtered by a search term
+func (p *BunnyProvider) listZones(page int, search string) (*zoneList, error) {
+	query := map[string]string{
+		"page":    strconv.Itoa(page),
+		"perPage": strconv.Itoa(zonesPerPage),
+	}
+	if search != "" {
+		query["search"] = search
+	}
+	resp, err := p.client.Get(context.Background(), "/dnszone", query)
+	if err != nil {
+		return nil, err
+	}
+	var list zoneList
+	if err := httpprovider.ParseJSONResponse(resp, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// discard closes the body of a successful write response
+func discard(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Register registers a Bunny provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Bunny provider when an API key is set in the
+// environment. It reports whether the provider was registered; the provider
+// itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{APIKey: os.Getenv(EnvAPIKey)}
+	if config.APIKey == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure BunnyProvider implements Provider, ZoneLister and CapabilitiesProvider interfaces
+var (
+	_ dnsprovider.Provider             = (*BunnyProvider)(nil)
+	_ dnsprovider.ZoneLister           = (*BunnyProvider)(nil)
+	_ dnsprovider.CapabilitiesProvider = (*BunnyProvider)(nil)
+)
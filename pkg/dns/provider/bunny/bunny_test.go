@@ -0,0 +1,139 @@
+package bunny
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBunny serves zone 42, example.com, and records every write
+type fakeBunny struct {
+	writes []string
+	bodies []record
+	fail   bool
+}
+
+func (f *fakeBunny) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("AccessKey") != "secret-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/dnszone":
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"Items":[{"Id":43,"Domain":"example.org"}],"HasMoreItems":false}`))
+			return
+		}
+		w.Write([]byte(`{"Items":[{"Id":41,"Domain":"sub.example.com"},{"Id":42,"Domain":"example.com"}],"HasMoreItems":true}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/dnszone/42":
+		w.Write([]byte(`{"Id":42,"Domain":"example.com","Records":[
+			{"Id":1,"Type":0,"Name":"","Value":"192.0.2.1","Ttl":300},
+			{"Id":2,"Type":4,"Name":"","Value":"mail.example.com","Ttl":3600,"Priority":10},
+			{"Id":3,"Type":8,"Name":"_sip._tcp","Value":"sip.example.com","Ttl":300,"Priority":10,"Weight":5,"Port":5060},
+			{"Id":4,"Type":9,"Name":"","Value":"letsencrypt.org","Ttl":300,"Flags":0,"Tag":"issue"},
+			{"Id":5,"Type":7,"Name":"cdn","Value":"pullzone","Ttl":300},
+			{"Id":6,"Type":3,"Name":"old","Value":"gone","Ttl":300}
+		]}`))
+	case r.Method == http.MethodPut || r.Method == http.MethodPost || r.Method == http.MethodDelete:
+		f.writes = append(f.writes, r.Method+" "+r.URL.Path)
+		var body record
+		json.NewDecoder(r.Body).Decode(&body)
+		f.bodies = append(f.bodies, body)
+		if f.fail && r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ErrorKey":"validation_error","Message":"Invalid record"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestProvider(t *testing.T) (*BunnyProvider, *fakeBunny) {
+	fake := &fakeBunny{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	p, err := New(Config{APIKey: "secret-key", Endpoint: server.URL})
+	require.NoError(t, err)
+	return p, fake
+}
+
+func TestBunnyProvider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "1", HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{ID: "2", HostName: "@", RecordType: "MX", Address: "mail.example.com", TTL: 3600, MXPref: 10},
+		{ID: "3", HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com", TTL: 300},
+		{ID: "4", HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 300},
+		{ID: "6", HostName: "old", RecordType: "TXT", Address: "gone", TTL: 300},
+	}, records, "the pull zone record is left out")
+
+	_, err = p.GetRecords("missing.com")
+	require.ErrorContains(t, err, "zone missing.com not found")
+}
+
+func TestBunnyProvider_SetRecords(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 300},
+		{HostName: "@", RecordType: "MX", Address: "mail.example.com", MXPref: 10},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com"},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Count(dnsprovider.ApplyUnchanged))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	require.Equal(t, []string{
+		"POST /dnszone/42/records/1",
+		"PUT /dnszone/42/records",
+		"DELETE /dnszone/42/records/6",
+	}, fake.writes)
+	require.Equal(t, record{ID: 1, Type: 0, Value: "192.0.2.2", TTL: 300}, fake.bodies[0])
+	require.Equal(t, record{Type: 2, Name: "www", Value: "example.com", TTL: defaultTTL}, fake.bodies[1])
+}
+
+func TestBunnyProvider_SetRecords_FailedRecordDoesNotStopOthers(t *testing.T) {
+	p, fake := newTestProvider(t)
+	fake.fail = true
+
+	existing, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	result, err := p.SetRecords("example.com", append(existing[:4], dnsrecord.Record{HostName: "new", RecordType: "A", Address: "192.0.2.9"}))
+	require.ErrorContains(t, err, "Invalid record")
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyFailed))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+}
+
+func TestBunnyProvider_ListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"sub.example.com", "example.com", "example.org"}, zones)
+}
+
+func TestFromRecord_Unsupported(t *testing.T) {
+	_, err := fromRecord(dnsrecord.Record{HostName: "@", RecordType: "URL", Address: "https://example.com"})
+	require.ErrorContains(t, err, "does not support URL records")
+
+	r, err := fromRecord(dnsrecord.Record{HostName: "@", RecordType: "caa", Address: `128 iodef "mailto:a@example.com"`, TTL: 60})
+	require.NoError(t, err)
+	require.Equal(t, record{Type: 9, Flags: 128, Tag: "iodef", Value: "mailto:a@example.com", TTL: 60}, r)
+}
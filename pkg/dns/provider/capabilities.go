@@ -0,0 +1,64 @@
+package provider
+
+import "strings"
+
+// Capabilities describes what a provider can do. Providers with a restricted
+// feature set implement CapabilitiesProvider; all others get FullCapabilities.
+type Capabilities struct {
+	// SupportedRecordTypes lists the record types the provider can manage; empty means all
+	SupportedRecordTypes []string
+
+	// UpdateOnly providers can only point existing hosts at a new address. They
+	// cannot enumerate, create or delete records (e.g. Dynu or DuckDNS update URLs).
+	UpdateOnly bool
+
+	// MaxRecords is the most records a zone can hold; 0 means no known limit
+	MaxRecords int
+
+	// DefaultTTL is the TTL the provider applies to records written without one;
+	// 0 means zonekit's own default is written instead
+	DefaultTTL int
+
+	// Forwarding providers serve URL redirect records (URL, URL301 and FRAME)
+	Forwarding bool
+
+	// Comments providers store record comments and tags with the records;
+	// for the others zonekit keeps them in local state
+	Comments bool
+}
+
+// FullCapabilities are the capabilities of a provider that manages whole zones
+var FullCapabilities = Capabilities{}
+
+// CapabilitiesProvider is implemented by providers that restrict what they support
+type CapabilitiesProvider interface {
+	Capabilities() Capabilities
+}
+
+// AddressUpdater is implemented by providers that point a host at a new IP
+// address in a single call, as used by dynamic DNS clients
+type AddressUpdater interface {
+	// UpdateAddress sets the A or AAAA record of hostName in domainName to address
+	UpdateAddress(domainName, hostName, recordType, address string) error
+}
+
+// CapabilitiesOf returns the capabilities of p
+func CapabilitiesOf(p Provider) Capabilities {
+	if cp, ok := p.(CapabilitiesProvider); ok {
+		return cp.Capabilities()
+	}
+	return FullCapabilities
+}
+
+// SupportsRecordType reports whether records of the given type can be managed
+func (c Capabilities) SupportsRecordType(recordType string) bool {
+	if len(c.SupportedRecordTypes) == 0 {
+		return true
+	}
+	for _, t := range c.SupportedRecordTypes {
+		if strings.EqualFold(t, recordType) {
+			return true
+		}
+	}
+	return false
+}
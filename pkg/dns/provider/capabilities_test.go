@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type restrictedProvider struct {
+	*mockProviderForRegistry
+}
+
+func (p *restrictedProvider) Capabilities() Capabilities {
+	return Capabilities{SupportedRecordTypes: []string{"A", "AAAA"}, UpdateOnly: true}
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	full := CapabilitiesOf(&mockProviderForRegistry{name: "full"})
+	require.False(t, full.UpdateOnly)
+	require.True(t, full.SupportsRecordType("TXT"))
+
+	restricted := CapabilitiesOf(&restrictedProvider{mockProviderForRegistry: &mockProviderForRegistry{name: "ddns"}})
+	require.True(t, restricted.UpdateOnly)
+	require.True(t, restricted.SupportsRecordType("aaaa"))
+	require.False(t, restricted.SupportsRecordType("MX"))
+}
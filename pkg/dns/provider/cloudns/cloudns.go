@@ -0,0 +1,436 @@
+// Package cloudns implements the DNS provider for ClouDNS (cloudns.net).
+//
+// ClouDNS authenticates every call with query parameters (auth-id or
+// sub-auth-id, and auth-password) instead of headers, and reports errors in
+// the response body with HTTP 200. Records are addressed by their ID and
+// applied one by one. TTLs are limited to a fixed set of values; other TTLs
+// are rounded up to the next allowed one.
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "cloudns"
+
+	// DefaultEndpoint is the ClouDNS API endpoint
+	DefaultEndpoint = "https://api.cloudns.net"
+
+	// defaultTTL is the TTL of records written without one
+	defaultTTL = 3600
+
+	// zonesPerPage is the largest page list-zones returns
+	zonesPerPage = 100
+)
+
+// allowedTTLs are the TTLs ClouDNS accepts, in ascending order
+var allowedTTLs = []int{60, 300, 900, 1800, 3600, 21600, 43200, 86400, 172800, 259200, 604800, 1209600, 2592000}
+
+// Environment variables read by RegisterFromEnv; the names match lego's cloudns provider
+const (
+	EnvAuthID       = "CLOUDNS_AUTH_ID"
+	EnvSubAuthID    = "CLOUDNS_SUB_AUTH_ID"
+	EnvAuthPassword = "CLOUDNS_AUTH_PASSWORD"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:         name,
+			AuthID:       credentials["auth_id"],
+			SubAuthID:    credentials["sub_auth_id"],
+			AuthPassword: credentials["auth_password"],
+			Endpoint:     credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the ClouDNS API user credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name string
+	// AuthID is the ID of an API user; SubAuthID that of an API sub-user.
+	// Exactly one of them is required.
+	AuthID       string
+	SubAuthID    string
+	AuthPassword string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// ClouDNSProvider implements the DNS Provider interface for ClouDNS
+type ClouDNSProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new ClouDNS provider
+func New(config Config) (*ClouDNSProvider, error) {
+	params := map[string]interface{}{"auth-password": config.AuthPassword}
+	switch {
+	case config.AuthID != "" && config.SubAuthID != "":
+		return nil, fmt.Errorf("cloudns takes either an auth ID or a sub-user auth ID, not both")
+	case config.AuthID != "":
+		params["auth-id"] = config.AuthID
+	case config.SubAuthID != "":
+		params["sub-auth-id"] = config.SubAuthID
+	default:
+		return nil, fmt.Errorf("cloudns requires an auth ID or a sub-user auth ID")
+	}
+
+	authenticator, err := auth.NewQueryAuthenticator(auth.Credentials{"params": params})
+	if err != nil {
+		return nil, err
+	}
+	if err := authenticator.Validate(); err != nil {
+		return nil, fmt.Errorf("cloudns requires an auth password")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &ClouDNSProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL:     strings.TrimSuffix(endpoint, "/"),
+			Headers:     map[string]string{"Accept": "application/json"},
+			Query:       authenticator.GetQueryParams(),
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *ClouDNSProvider) Name() string {
+	return p.name
+}
+
+// number decodes the numeric fields ClouDNS returns as strings, numbers or empty strings
+type number int
+
+func (n *number) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = number(v)
+	return nil
+}
+
+// record is a DNS record as returned by records.json
+type record struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Host     string `json:"host"`
+	Record   string `json:"record"`
+	TTL      number `json:"ttl"`
+	Priority number `json:"priority"`
+	Weight   number `json:"weight"`
+	Port     number `json:"port"`
+	CAAFlag  number `json:"caa_flag"`
+	CAAType  string `json:"caa_type"`
+	CAAValue string `json:"caa_value"`
+}
+
+// status is the envelope of write responses and of every error
+type status struct {
+	Status            string `json:"status"`
+	StatusDescription string `json:"statusDescription"`
+}
+
+func (s status) err() error {
+	if strings.EqualFold(s.Status, "Failed") {
+		return fmt.Errorf("%s", s.StatusDescription)
+	}
+	return nil
+}
+
+// GetRecords retrieves all DNS records for a domain
+func (p *ClouDNSProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	var raw json.RawMessage
+	if err := p.call(http.MethodGet, "/dns/records.json", map[string]string{"domain-name": domainName}, &raw); err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	// Records are keyed by ID; a zone without records is an empty array
+	var byID map[string]record
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "{") {
+		if err := json.Unmarshal(raw, &byID); err != nil {
+			return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+		}
+	}
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+
+	records := make([]dnsrecord.Record, 0, len(ids))
+	for _, id := range ids {
+		r := byID[id]
+		if r.ID == "" {
+			r.ID = id
+		}
+		records = append(records, toRecord(r))
+	}
+	return records, nil
+}
+
+// toRecord converts a ClouDNS record, whose apex host is empty
+func toRecord(r record) dnsrecord.Record {
+	rec := dnsrecord.Record{
+		ID:         r.ID,
+		HostName:   r.Host,
+		RecordType: strings.ToUpper(r.Type),
+		Address:    r.Record,
+		TTL:        int(r.TTL),
+	}
+	if rec.HostName == "" {
+		rec.HostName = "@"
+	}
+	switch rec.RecordType {
+	case dnsrecord.RecordTypeMX:
+		rec.MXPref = int(r.Priority)
+	case dnsrecord.RecordTypeSRV:
+		rec.Address = fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Record)
+	case dnsrecord.RecordTypeCAA:
+		rec.Address = fmt.Sprintf("%d %s %q", r.CAAFlag, r.CAAType, r.CAAValue)
+	}
+	return rec
+}
+
+// recordParams returns the API parameters describing a record
+func recordParams(domainName string, r dnsrecord.Record) (map[string]string, error) {
+	params := map[string]string{
+		"domain-name": domainName,
+		"record-type": strings.ToUpper(r.RecordType),
+		"host":        r.HostName,
+		"record":      r.Address,
+		"ttl":         strconv.Itoa(roundTTL(r.TTL)),
+	}
+	if params["host"] == "@" {
+		params["host"] = ""
+	}
+
+	switch params["record-type"] {
+	case dnsrecord.RecordTypeMX:
+		params["priority"] = strconv.Itoa(r.MXPref)
+	case dnsrecord.RecordTypeSRV:
+		fields := strings.Fields(r.Address)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid SRV record %q: expected priority, weight, port and target", r.Address)
+		}
+		params["priority"], params["weight"], params["port"], params["record"] = fields[0], fields[1], fields[2], fields[3]
+	case dnsrecord.RecordTypeCAA:
+		fields := strings.SplitN(strings.TrimSpace(r.Address), " ", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf(`invalid CAA record %q: expected flags, tag and "value"`, r.Address)
+		}
+		value := fields[2]
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		delete(params, "record")
+		params["caa_flag"], params["caa_type"], params["caa_value"] = fields[0], fields[1], value
+	}
+	return params, nil
+}
+
+// roundTTL rounds a TTL up to the next one ClouDNS accepts; 0 means the default
+func roundTTL(ttl int) int {
+	if ttl <= 0 {
+		return defaultTTL
+	}
+	for _, allowed := range allowedTTLs {
+		if ttl <= allowed {
+			return allowed
+		}
+	}
+	return allowedTTLs[len(allowedTTLs)-1]
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Updated records keep their ID; a failing record does not stop the others.
+func (p *ClouDNSProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare with the TTLs ClouDNS will store, so rounded TTLs do not count as changes
+	desired := make([]dnsrecord.Record, len(records))
+	for i, r := range records {
+		if r.TTL > 0 {
+			r.TTL = roundTTL(r.TTL)
+		}
+		desired[i] = r
+	}
+	result := dnsprovider.PlanResults(domainName, existing, desired)
+
+	for i, r := range result.Records {
+		var err error
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			var params map[string]string
+			if params, err = recordParams(domainName, r.Record); err == nil {
+				err = p.call(http.MethodPost, "/dns/add-record.json", params, nil)
+			}
+		case dnsprovider.ApplyUpdated:
+			var params map[string]string
+			if params, err = recordParams(domainName, r.Record); err == nil {
+				delete(params, "record-type")
+				params["record-id"] = r.Previous.ID
+				err = p.call(http.MethodPost, "/dns/mod-record.json", params, nil)
+			}
+		case dnsprovider.ApplyDeleted:
+			err = p.call(http.MethodPost, "/dns/delete-record.json", map[string]string{
+				"domain-name": domainName,
+				"record-id":   r.Previous.ID,
+			}, nil)
+		default:
+			continue
+		}
+		if err != nil {
+			result.Fail(i, err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// ListZones returns the zones of the ClouDNS account
+func (p *ClouDNSProvider) ListZones() ([]string, error) {
+	var zones []string
+	for page := 1; ; page++ {
+		var list []struct {
+			Name string `json:"name"`
+		}
+		err := p.call(http.MethodGet, "/dns/list-zones.json", map[string]string{
+			"page":          strconv.Itoa(page),
+			"rows-per-page": strconv.Itoa(zonesPerPage),
+		}, &list)
+		if err != nil {
+			return nil, errors.NewAPI("ListZones", "failed to list zones", err)
+		}
+		for _, z := range list {
+			zones = append(zones, z.Name)
+		}
+		if len(list) < zonesPerPage {
+			return zones, nil
+		}
+	}
+}
+
+// Capabilities reports ClouDNS's default TTL
+func (p *ClouDNSProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *ClouDNSProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("cloudns client is not initialized")
+	}
+	return nil
+}
+
+// call invokes an API method with the given parameters and decodes the
+// response into target, if given. ClouDNS reports errors in the response body
+// with HTTP 200.
+func (p *ClouDNSProvider) call(method, path string, params map[string]string, target interface{}) error {
+	resp, err := p.client.Do(context.Background(), httpprovider.RequestOptions{
+		Method: method,
+		Path:   path,
+		Query:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	var body json.RawMessage
+	if err := httpprovider.ParseJSONResponse(resp, &body); err != nil {
+		return err
+	}
+
+	var envelope status
+	if json.Unmarshal(body, &envelope) == nil {
+		if err := envelope.err(); err != nil {
+			return err
+		}
+	}
+
+	if target == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", path, err)
+	}
+	return nil
+}
+
+// Register registers a ClouDNS provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the ClouDNS provider when its credentials are set
+// in the environment. It reports whether the provider was registered; the
+// provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		AuthID:       os.Getenv(EnvAuthID),
+		SubAuthID:    os.Getenv(EnvSubAuthID),
+		AuthPassword: os.Getenv(EnvAuthPassword),
+	}
+	if config.AuthPassword == "" || (config.AuthID == "" && config.SubAuthID == "") {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure ClouDNSProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*ClouDNSProvider)(nil)
+	_ dnsprovider.ZoneLister = (*ClouDNSProvider)(nil)
+)
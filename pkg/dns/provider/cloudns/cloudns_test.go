@@ -0,0 +1,171 @@
+package cloudns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClouDNS serves the records of example.com and records every write call
+type fakeClouDNS struct {
+	url   string
+	calls []url.Values
+	paths []string
+	fail  string
+}
+
+func (f *fakeClouDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("auth-id") != "1234" || q.Get("auth-password") != "secret" {
+		w.Write([]byte(`{"status":"Failed","statusDescription":"Invalid authentication, incorrect auth-id or auth-password."}`))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/dns/records.json":
+		switch q.Get("domain-name") {
+		case "example.com":
+			w.Write([]byte(`{
+				"11":{"id":"11","type":"A","host":"","record":"192.0.2.1","ttl":"3600","status":1},
+				"12":{"id":"12","type":"MX","host":"","record":"mail.example.com","ttl":"3600","priority":"10","status":1},
+				"13":{"id":"13","type":"SRV","host":"_sip._tcp","record":"sip.example.com","ttl":"300","priority":10,"weight":5,"port":5060,"status":1},
+				"14":{"id":"14","type":"CAA","host":"","record":"","ttl":"3600","caa_flag":"0","caa_type":"issue","caa_value":"letsencrypt.org","status":1},
+				"9":{"id":"9","type":"TXT","host":"old","record":"gone","ttl":"60","status":1}
+			}`))
+		case "empty.com":
+			w.Write([]byte(`[]`))
+		default:
+			w.Write([]byte(`{"status":"Failed","statusDescription":"Missing domain-name"}`))
+		}
+	case "/dns/list-zones.json":
+		w.Write([]byte(`[{"name":"example.com","type":"master","zone":"domain","status":"1"},{"name":"example.org","type":"master","zone":"domain","status":"1"}]`))
+	case "/dns/add-record.json", "/dns/mod-record.json", "/dns/delete-record.json":
+		f.calls = append(f.calls, q)
+		f.paths = append(f.paths, r.URL.Path)
+		if f.fail == r.URL.Path {
+			w.Write([]byte(`{"status":"Failed","statusDescription":"Invalid record."}`))
+			return
+		}
+		w.Write([]byte(`{"status":"Success","statusDescription":"The record was updated successfully."}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestProvider(t *testing.T) (*ClouDNSProvider, *fakeClouDNS) {
+	fake := &fakeClouDNS{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	fake.url = server.URL
+
+	p, err := New(Config{AuthID: "1234", AuthPassword: "secret", Endpoint: server.URL})
+	require.NoError(t, err)
+	return p, fake
+}
+
+func TestClouDNSProvider_GetRecords(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "9", HostName: "old", RecordType: "TXT", Address: "gone", TTL: 60},
+		{ID: "11", HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{ID: "12", HostName: "@", RecordType: "MX", Address: "mail.example.com", TTL: 3600, MXPref: 10},
+		{ID: "13", HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com", TTL: 300},
+		{ID: "14", HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 3600},
+	}, records)
+
+	records, err = p.GetRecords("empty.com")
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	_, err = p.GetRecords("missing.com")
+	require.ErrorContains(t, err, "Missing domain-name")
+
+	wrong, err := New(Config{AuthID: "1234", AuthPassword: "wrong", Endpoint: fake.url})
+	require.NoError(t, err)
+	_, err = wrong.GetRecords("example.com")
+	require.ErrorContains(t, err, "Invalid authentication")
+}
+
+func TestClouDNSProvider_SetRecords(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 600},
+		{HostName: "@", RecordType: "MX", Address: "mail.example.com", MXPref: 10, TTL: 3000},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com", TTL: 300},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`},
+		{HostName: "www", RecordType: "CAA", Address: `128 iodef "mailto:security@example.com"`},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Count(dnsprovider.ApplyUnchanged), "TTLs are compared after rounding")
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	require.Equal(t, []string{"/dns/mod-record.json", "/dns/add-record.json", "/dns/delete-record.json"}, fake.paths)
+	update := fake.calls[0]
+	require.Equal(t, "11", update.Get("record-id"))
+	require.Equal(t, "", update.Get("host"))
+	require.Equal(t, "192.0.2.2", update.Get("record"))
+	require.Equal(t, "900", update.Get("ttl"))
+
+	create := fake.calls[1]
+	require.Equal(t, "CAA", create.Get("record-type"))
+	require.Equal(t, "128", create.Get("caa_flag"))
+	require.Equal(t, "iodef", create.Get("caa_type"))
+	require.Equal(t, "mailto:security@example.com", create.Get("caa_value"))
+	require.Equal(t, "3600", create.Get("ttl"))
+
+	require.Equal(t, "9", fake.calls[2].Get("record-id"))
+	require.Equal(t, "example.com", fake.calls[2].Get("domain-name"))
+}
+
+func TestClouDNSProvider_SetRecords_FailedRecordDoesNotStopOthers(t *testing.T) {
+	p, fake := newTestProvider(t)
+	fake.fail = "/dns/add-record.json"
+
+	existing, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	result, err := p.SetRecords("example.com", append(existing[1:], dnsrecord.Record{HostName: "new", RecordType: "A", Address: "192.0.2.9"}))
+	require.ErrorContains(t, err, "Invalid record")
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyFailed))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+}
+
+func TestClouDNSProvider_ListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org"}, zones)
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(Config{SubAuthID: "77", AuthPassword: "secret"})
+	require.NoError(t, err)
+	require.Equal(t, Name, p.Name())
+
+	_, err = New(Config{AuthPassword: "secret"})
+	require.ErrorContains(t, err, "auth ID")
+	_, err = New(Config{AuthID: "1", SubAuthID: "2", AuthPassword: "secret"})
+	require.ErrorContains(t, err, "not both")
+	_, err = New(Config{AuthID: "1"})
+	require.ErrorContains(t, err, "password")
+}
+
+func TestRoundTTL(t *testing.T) {
+	require.Equal(t, 3600, roundTTL(0))
+	require.Equal(t, 60, roundTTL(1))
+	require.Equal(t, 300, roundTTL(300))
+	require.Equal(t, 900, roundTTL(301))
+	require.Equal(t, 2592000, roundTTL(9999999))
+}
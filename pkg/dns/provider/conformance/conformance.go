@@ -0,0 +1,474 @@
+// Package conformance checks that a DNS provider behaves the way zonekit
+// expects of every provider: records written are read back as written, updates
+// and deletes take effect, applying the same records again changes nothing and
+// records zonekit did not touch are left alone.
+//
+// The suite writes test records to a zone and removes them again, so it must
+// run against a zone set aside for testing.
+package conformance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	// StatusSkip is a check that does not apply to the provider or could not
+	// run because an earlier check failed
+	StatusSkip Status = "skip"
+)
+
+// Check names, in the order they run
+const (
+	CheckValidate   = "validate"
+	CheckZones      = "zones"
+	CheckRead       = "read"
+	CheckCreate     = "create"
+	CheckTTL        = "ttl"
+	CheckIdempotent = "idempotent"
+	CheckUpdate     = "update"
+	CheckDelete     = "delete"
+	CheckPreserve   = "preserve"
+)
+
+// TestHost is the hostname test records are written at; record type checks
+// use TestHost-<type>
+const TestHost = "zonekit-conformance"
+
+// testTTL is the TTL of test records, which providers should keep
+const testTTL = 600
+
+// typeRecords are the records written to check each record type
+var typeRecords = []dnsrecord.Record{
+	{RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.20"},
+	{RecordType: dnsrecord.RecordTypeAAAA, Address: "2001:db8::20"},
+	{RecordType: dnsrecord.RecordTypeCNAME, Address: "target.example.net"},
+	{RecordType: dnsrecord.RecordTypeMX, Address: "mail.example.net", MXPref: 10},
+	{RecordType: dnsrecord.RecordTypeTXT, Address: "zonekit conformance"},
+	{RecordType: dnsrecord.RecordTypeSRV, Address: "10 5 5060 sip.example.net"},
+	{RecordType: dnsrecord.RecordTypeCAA, Address: `0 issue "letsencrypt.org"`},
+}
+
+// Checks returns the names of all checks in the order they run; record type
+// checks are named after the type
+func Checks() []string {
+	checks := []string{CheckValidate, CheckZones, CheckRead, CheckCreate, CheckTTL, CheckIdempotent, CheckUpdate}
+	for _, r := range typeRecords {
+		checks = append(checks, r.RecordType)
+	}
+	return append(checks, CheckDelete, CheckPreserve)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report is the outcome of the suite against one provider
+type Report struct {
+	Provider string        `json:"provider"`
+	Zone     string        `json:"zone"`
+	Results  []Result      `json:"results"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Result returns the result of a check
+func (r *Report) Result(check string) (Result, bool) {
+	for _, result := range r.Results {
+		if result.Check == check {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// Failed returns the results of the failed checks
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Target is a provider and the zone the suite may write to
+type Target struct {
+	Name     string
+	Provider dnsprovider.Provider
+	Zone     string
+}
+
+// RunAll runs the suite against every target concurrently and returns the
+// reports in the order of targets
+func RunAll(targets []Target) []*Report {
+	reports := make([]*Report, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			reports[i] = Run(target.Name, target.Provider, target.Zone)
+		}(i, target)
+	}
+	wg.Wait()
+	return reports
+}
+
+// run is one run of the suite
+type run struct {
+	p      dnsprovider.Provider
+	zone   string
+	report *Report
+}
+
+func (r *run) pass(check, format string, args ...any) {
+	r.report.Results = append(r.report.Results, Result{Check: check, Status: StatusPass, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (r *run) fail(check, format string, args ...any) {
+	r.report.Results = append(r.report.Results, Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf(format, args...)})
+}
+
+// skipRest marks every check that has no result yet as skipped
+func (r *run) skipRest(reason string) {
+	for _, check := range Checks() {
+		if _, ok := r.report.Result(check); !ok {
+			r.report.Results = append(r.report.Results, Result{Check: check, Status: StatusSkip, Detail: reason})
+		}
+	}
+}
+
+// Run runs the suite against a provider, writing test records to zone and
+// removing them again. Test records left behind by an interrupted run are
+// removed too. The results are in the order of Checks.
+func Run(name string, p dnsprovider.Provider, zone string) *Report {
+	start := time.Now()
+	r := &run{p: p, zone: zone, report: &Report{Provider: name, Zone: zone}}
+	r.suite()
+	r.skipRest("")
+	r.sortResults()
+	r.report.Duration = time.Since(start)
+	return r.report
+}
+
+// sortResults orders the results like Checks
+func (r *run) sortResults() {
+	order := make(map[string]int)
+	for i, check := range Checks() {
+		order[check] = i
+	}
+	sort.SliceStable(r.report.Results, func(i, j int) bool {
+		return order[r.report.Results[i].Check] < order[r.report.Results[j].Check]
+	})
+}
+
+func (r *run) suite() {
+	if err := r.p.Validate(); err != nil {
+		r.fail(CheckValidate, "%v", err)
+		r.skipRest("provider is misconfigured")
+		return
+	}
+	r.pass(CheckValidate, "")
+
+	caps := dnsprovider.CapabilitiesOf(r.p)
+	if caps.UpdateOnly {
+		r.skipRest("update-only provider")
+		return
+	}
+
+	if lister, ok := r.p.(dnsprovider.ZoneLister); ok {
+		zones, err := lister.ListZones()
+		switch {
+		case err != nil:
+			r.fail(CheckZones, "%v", err)
+		case !containsZone(zones, r.zone):
+			r.fail(CheckZones, "%s is not among the %d zone(s) listed", r.zone, len(zones))
+		default:
+			r.pass(CheckZones, "%d zone(s)", len(zones))
+		}
+	} else {
+		r.report.Results = append(r.report.Results, Result{Check: CheckZones, Status: StatusSkip, Detail: "provider cannot list zones"})
+	}
+
+	original, err := r.p.GetRecords(r.zone)
+	if err != nil {
+		r.fail(CheckRead, "%v", err)
+		r.skipRest("zone could not be read")
+		return
+	}
+	r.pass(CheckRead, "%d record(s)", len(original))
+
+	// Records of an interrupted earlier run are not part of the zone
+	var base []dnsrecord.Record
+	for _, record := range original {
+		if !isTestRecord(record, r.zone) {
+			base = append(base, record)
+		}
+	}
+	defer r.cleanup(base)
+
+	if !r.create(base) {
+		r.skipRest("test record could not be created")
+		return
+	}
+	r.update(base)
+	r.types(base, caps)
+	r.delete(base)
+}
+
+// create writes a test record and reads it back, checking its TTL and that
+// writing it again changes nothing
+func (r *run) create(base []dnsrecord.Record) bool {
+	record := dnsrecord.Record{HostName: TestHost, RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.10", TTL: testTTL}
+	desired := append(append([]dnsrecord.Record(nil), base...), record)
+	if _, err := r.p.SetRecords(r.zone, desired); err != nil {
+		r.fail(CheckCreate, "%v", err)
+		return false
+	}
+	records, err := r.p.GetRecords(r.zone)
+	if err != nil {
+		r.fail(CheckCreate, "records could not be read back: %v", err)
+		return false
+	}
+	got, ok := find(records, record, r.zone)
+	if !ok {
+		r.fail(CheckCreate, "%s A %s was not read back", TestHost, record.Address)
+		return false
+	}
+	r.pass(CheckCreate, "")
+
+	if got.TTL == testTTL {
+		r.pass(CheckTTL, "")
+	} else {
+		r.fail(CheckTTL, "TTL %d was read back as %d", testTTL, got.TTL)
+	}
+
+	result, err := r.p.SetRecords(r.zone, desired)
+	switch {
+	case err != nil:
+		r.fail(CheckIdempotent, "%v", err)
+	case result != nil && result.Count(dnsprovider.ApplyUnchanged) < len(result.Records):
+		r.fail(CheckIdempotent, "applying the same records again changed %d record(s)", len(result.Records)-result.Count(dnsprovider.ApplyUnchanged))
+	default:
+		r.pass(CheckIdempotent, "")
+	}
+	return true
+}
+
+// update changes the value of the test record
+func (r *run) update(base []dnsrecord.Record) {
+	record := dnsrecord.Record{HostName: TestHost, RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.11", TTL: testTTL}
+	desired := append(append([]dnsrecord.Record(nil), base...), record)
+	if _, err := r.p.SetRecords(r.zone, desired); err != nil {
+		r.fail(CheckUpdate, "%v", err)
+		return
+	}
+	records, err := r.p.GetRecords(r.zone)
+	if err != nil {
+		r.fail(CheckUpdate, "records could not be read back: %v", err)
+		return
+	}
+	if _, ok := find(records, record, r.zone); !ok {
+		r.fail(CheckUpdate, "the new value %s was not read back", record.Address)
+		return
+	}
+	if _, ok := find(records, dnsrecord.Record{HostName: TestHost, RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.10"}, r.zone); ok {
+		r.fail(CheckUpdate, "the old value is still there")
+		return
+	}
+	r.pass(CheckUpdate, "")
+}
+
+// types writes a record of each record type the provider supports in one
+// apply and checks each is read back as written
+func (r *run) types(base []dnsrecord.Record, caps dnsprovider.Capabilities) {
+	desired := append([]dnsrecord.Record(nil), base...)
+	var written []dnsrecord.Record
+	for _, record := range typeRecords {
+		if !caps.SupportsRecordType(record.RecordType) {
+			r.report.Results = append(r.report.Results, Result{Check: record.RecordType, Status: StatusSkip, Detail: "not supported"})
+			continue
+		}
+		record.HostName = TestHost + "-" + strings.ToLower(record.RecordType)
+		record.TTL = testTTL
+		written = append(written, record)
+		desired = append(desired, record)
+	}
+	if len(written) == 0 {
+		return
+	}
+
+	result, err := r.p.SetRecords(r.zone, desired)
+	failed := make(map[string]error)
+	if result != nil {
+		for _, f := range result.Failed() {
+			failed[strings.ToUpper(f.Record.RecordType)] = f.Err
+		}
+	} else if err != nil {
+		for _, record := range written {
+			failed[record.RecordType] = err
+		}
+	}
+
+	records, readErr := r.p.GetRecords(r.zone)
+	for _, record := range written {
+		switch {
+		case failed[record.RecordType] != nil:
+			r.fail(record.RecordType, "%v", failed[record.RecordType])
+		case readErr != nil:
+			r.fail(record.RecordType, "records could not be read back: %v", readErr)
+		default:
+			if _, ok := find(records, record, r.zone); ok {
+				r.pass(record.RecordType, "")
+			} else {
+				r.fail(record.RecordType, "%s was not read back as written%s", record.Address, readBack(records, record, r.zone))
+			}
+		}
+	}
+}
+
+// delete removes the test records and checks that the zone holds what it held
+// before
+func (r *run) delete(base []dnsrecord.Record) {
+	if _, err := r.p.SetRecords(r.zone, base); err != nil {
+		r.fail(CheckDelete, "%v", err)
+		return
+	}
+	records, err := r.p.GetRecords(r.zone)
+	if err != nil {
+		r.fail(CheckDelete, "records could not be read back: %v", err)
+		return
+	}
+
+	left := 0
+	var rest []dnsrecord.Record
+	for _, record := range records {
+		if isTestRecord(record, r.zone) {
+			left++
+		} else {
+			rest = append(rest, record)
+		}
+	}
+	if left > 0 {
+		r.fail(CheckDelete, "%d test record(s) are still there", left)
+	} else {
+		r.pass(CheckDelete, "")
+	}
+
+	if missing, extra := diff(base, rest, r.zone); len(missing)+len(extra) > 0 {
+		r.fail(CheckPreserve, "other records changed: %d missing, %d unexpected (e.g. %s)", len(missing), len(extra), strings.Join(append(missing, extra...)[:1], ""))
+	} else {
+		r.pass(CheckPreserve, "%d record(s) unchanged", len(base))
+	}
+}
+
+// cleanup restores the zone if a check failed before the test records were
+// deleted
+func (r *run) cleanup(base []dnsrecord.Record) {
+	if result, ok := r.report.Result(CheckDelete); ok && result.Status == StatusPass {
+		return
+	}
+	_, _ = r.p.SetRecords(r.zone, base)
+}
+
+// isTestRecord reports whether a record was written by the suite
+func isTestRecord(record dnsrecord.Record, zone string) bool {
+	return strings.HasPrefix(relativeHost(record.HostName, zone), TestHost)
+}
+
+// relativeHost returns a hostname relative to zone, "@" for the apex, as
+// providers return either form
+func relativeHost(host, zone string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	switch {
+	case host == "" || host == "@" || host == zone:
+		return "@"
+	case strings.HasSuffix(host, "."+zone):
+		return strings.TrimSuffix(host, "."+zone)
+	}
+	return host
+}
+
+// key identifies a record by host, type and normalized value
+func key(record dnsrecord.Record, zone string) string {
+	return relativeHost(record.HostName, zone) + " " + strings.ToUpper(record.RecordType) + " " + zonecompare.Normalize(record)
+}
+
+// find returns the record of records matching want by host, type and value
+func find(records []dnsrecord.Record, want dnsrecord.Record, zone string) (dnsrecord.Record, bool) {
+	k := key(want, zone)
+	for _, record := range records {
+		if key(record, zone) == k {
+			return record, true
+		}
+	}
+	return dnsrecord.Record{}, false
+}
+
+// readBack describes what was read back at the host and type of want
+func readBack(records []dnsrecord.Record, want dnsrecord.Record, zone string) string {
+	var values []string
+	for _, record := range records {
+		if relativeHost(record.HostName, zone) == relativeHost(want.HostName, zone) && strings.EqualFold(record.RecordType, want.RecordType) {
+			values = append(values, zonecompare.Normalize(record))
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return " (read back: " + strings.Join(values, ", ") + ")"
+}
+
+// diff returns the records of want missing from got and those of got not in
+// want, compared by host, type, value and TTL
+func diff(want, got []dnsrecord.Record, zone string) (missing, extra []string) {
+	ttlKey := func(record dnsrecord.Record) string {
+		return fmt.Sprintf("%s (ttl %d)", key(record, zone), record.TTL)
+	}
+	counts := make(map[string]int)
+	for _, record := range want {
+		counts[ttlKey(record)]++
+	}
+	for _, record := range got {
+		k := ttlKey(record)
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		extra = append(extra, k)
+	}
+	for k, n := range counts {
+		for ; n > 0; n-- {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return missing, extra
+}
+
+// containsZone reports whether zones lists zone
+func containsZone(zones []string, zone string) bool {
+	for _, z := range zones {
+		if strings.EqualFold(strings.TrimSuffix(z, "."), strings.TrimSuffix(zone, ".")) {
+			return true
+		}
+	}
+	return false
+}
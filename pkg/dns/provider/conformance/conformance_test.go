@@ -0,0 +1,132 @@
+package conformance
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// memoryProvider is a provider keeping records in memory
+type memoryProvider struct {
+	records map[string][]dnsrecord.Record
+	// ttl, when set, replaces the TTL of every record written
+	ttl int
+	// dropType drops records of a type when written
+	dropType string
+	types    []string
+}
+
+func newMemoryProvider() *memoryProvider {
+	return &memoryProvider{records: map[string][]dnsrecord.Record{
+		"example.com": {
+			{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+			{HostName: "www", RecordType: "CNAME", Address: "example.com", TTL: 300},
+			// Left behind by an interrupted run
+			{HostName: "zonekit-conformance-txt", RecordType: "TXT", Address: "zonekit conformance", TTL: 600},
+		},
+	}}
+}
+
+func (m *memoryProvider) Name() string { return "memory" }
+
+func (m *memoryProvider) Validate() error { return nil }
+
+func (m *memoryProvider) ListZones() ([]string, error) { return []string{"example.com"}, nil }
+
+func (m *memoryProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{SupportedRecordTypes: m.types}
+}
+
+func (m *memoryProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	return append([]dnsrecord.Record(nil), m.records[domainName]...), nil
+}
+
+func (m *memoryProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	var stored []dnsrecord.Record
+	for _, r := range records {
+		if strings.EqualFold(r.RecordType, m.dropType) {
+			continue
+		}
+		if m.ttl > 0 {
+			r.TTL = m.ttl
+		}
+		stored = append(stored, r)
+	}
+	result := dnsprovider.PlanResults(domainName, m.records[domainName], stored)
+	m.records[domainName] = stored
+	return result, nil
+}
+
+func statuses(report *Report) map[string]Status {
+	s := make(map[string]Status)
+	for _, r := range report.Results {
+		s[r.Check] = r.Status
+	}
+	return s
+}
+
+func TestRun_Conformant(t *testing.T) {
+	p := newMemoryProvider()
+	p.types = []string{"A", "AAAA", "CNAME", "MX", "TXT", "SRV"}
+	before := p.records["example.com"][:2]
+
+	report := Run("memory", p, "example.com")
+	require.Empty(t, report.Failed())
+	require.Len(t, report.Results, len(Checks()))
+	for i, check := range Checks() {
+		require.Equal(t, check, report.Results[i].Check)
+	}
+	require.Equal(t, StatusSkip, statuses(report)["CAA"])
+
+	// The zone holds what it held before, without the leftover test record
+	require.Equal(t, before, p.records["example.com"])
+}
+
+func TestRun_Failures(t *testing.T) {
+	p := newMemoryProvider()
+	p.ttl = 3600
+	p.dropType = "SRV"
+	before := p.records["example.com"][:2]
+
+	report := Run("memory", p, "example.com")
+	s := statuses(report)
+	require.Equal(t, StatusFail, s[CheckTTL])
+	require.Equal(t, StatusFail, s["SRV"])
+	require.Equal(t, StatusPass, s["MX"])
+	require.Equal(t, StatusPass, s[CheckDelete])
+
+	// The TTL of untouched records was rewritten
+	require.Equal(t, StatusFail, s[CheckPreserve])
+	require.Equal(t, before[0].Address, p.records["example.com"][0].Address)
+}
+
+// brokenProvider is a provider that is misconfigured
+type brokenProvider struct {
+	memoryProvider
+}
+
+func (b *brokenProvider) Validate() error { return errors.New("missing API token") }
+
+func TestRun_Invalid(t *testing.T) {
+	report := Run("broken", &brokenProvider{*newMemoryProvider()}, "example.com")
+	failed := report.Failed()
+	require.Len(t, failed, 1)
+	require.Equal(t, CheckValidate, failed[0].Check)
+	require.Equal(t, StatusSkip, statuses(report)[CheckCreate])
+}
+
+func TestRunAll(t *testing.T) {
+	reports := RunAll([]Target{
+		{Name: "a", Provider: newMemoryProvider(), Zone: "example.com"},
+		{Name: "b", Provider: newMemoryProvider(), Zone: "example.org"},
+	})
+	require.Len(t, reports, 2)
+	require.Equal(t, "a", reports[0].Provider)
+	require.Empty(t, reports[0].Failed())
+	require.Equal(t, "b", reports[1].Provider)
+	require.Equal(t, StatusFail, statuses(reports[1])[CheckZones])
+}
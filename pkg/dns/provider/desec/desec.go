@@ -0,0 +1,291 @@
+// Package desec implements the DNS provider for deSEC (desec.io).
+//
+// deSEC manages records as RRsets: all values of one name and type share a TTL
+// and are written together. Each value is returned as its own record, and
+// records are merged back into RRsets when they are written (see
+// mapper.SplitRRSet and mapper.MergeRRSets). The changed RRsets are written in
+// a single bulk request, which deSEC applies atomically.
+package desec
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dns/provider/mapper"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "desec"
+
+	// DefaultEndpoint is the deSEC API endpoint
+	DefaultEndpoint = "https://desec.io/api/v1"
+
+	// defaultTTL is deSEC's minimum TTL, which RRsets written without one get
+	defaultTTL = 3600
+)
+
+// Environment variables read by RegisterFromEnv; the name matches lego's desec provider
+const (
+	EnvToken = "DESEC_TOKEN"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			Token:    credentials["token"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the deSEC credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name  string
+	Token string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// DeSECProvider implements the DNS Provider interface for deSEC
+type DeSECProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new deSEC provider
+func New(config Config) (*DeSECProvider, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("desec requires an API token")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &DeSECProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL: strings.TrimSuffix(endpoint, "/"),
+			Headers: map[string]string{
+				"Authorization": "Token " + config.Token,
+				"Accept":        "application/json",
+			},
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *DeSECProvider) Name() string {
+	return p.name
+}
+
+// rrset is an RRset as the deSEC API represents it. An RRset written without
+// records is deleted.
+type rrset struct {
+	Subname string   `json:"subname"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl,omitempty"`
+	Records []string `json:"records"`
+}
+
+// GetRecords retrieves all DNS records for a domain, one record per RRset value
+func (p *DeSECProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	resp, err := p.client.Get(context.Background(), rrsetsPath(domainName), nil)
+	if err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	var sets []rrset
+	if err := httpprovider.ParseJSONResponse(resp, &sets); err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	var records []dnsrecord.Record
+	for _, set := range sets {
+		records = append(records, mapper.SplitRRSet(toRRSet(set))...)
+	}
+	return records, nil
+}
+
+// toRRSet converts a deSEC RRset, whose apex subname is empty
+func toRRSet(set rrset) mapper.RRSet {
+	host := set.Subname
+	if host == "" {
+		host = "@"
+	}
+	return mapper.RRSet{HostName: host, RecordType: set.Type, TTL: set.TTL, Values: set.Records}
+}
+
+// fromRRSet converts an RRSet into a deSEC RRset. deSEC only accepts fully
+// qualified targets, so relative names are qualified with domainName.
+func fromRRSet(domainName string, set mapper.RRSet) rrset {
+	out := rrset{Subname: set.HostName, Type: set.RecordType, TTL: set.TTL, Records: []string{}}
+	if out.Subname == "@" {
+		out.Subname = ""
+	}
+	for _, value := range set.Values {
+		out.Records = append(out.Records, qualifyTarget(domainName, set.RecordType, value))
+	}
+	return out
+}
+
+// qualifyTarget makes the host name in a CNAME, NS or MX value fully qualified
+func qualifyTarget(domainName, recordType, value string) string {
+	prefix, target := "", value
+	switch recordType {
+	case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS:
+	case dnsrecord.RecordTypeMX:
+		if pref, rest, ok := strings.Cut(value, " "); ok {
+			prefix, target = pref+" ", rest
+		}
+	default:
+		return value
+	}
+	switch {
+	case target == "" || strings.HasSuffix(target, "."):
+	case !strings.Contains(target, "."):
+		target += "." + domainName + "."
+	default:
+		target += "."
+	}
+	return prefix + target
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Records are merged into RRsets and the RRsets with a changed record are
+// written in one atomic bulk request, so either all of them fail or none does.
+func (p *DeSECProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	desired := make(map[mapper.RRSetKey]mapper.RRSet)
+	for _, set := range mapper.MergeRRSets(records) {
+		desired[set.Key()] = set
+	}
+	current := make(map[mapper.RRSetKey]mapper.RRSet)
+	for _, set := range mapper.MergeRRSets(existing) {
+		current[set.Key()] = set
+	}
+
+	keys := result.ChangedSets()
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	sets := make([]rrset, 0, len(keys))
+	for _, key := range keys {
+		set, ok := desired[key]
+		if !ok {
+			// Writing an RRset without records deletes it
+			set = current[key]
+			set.Values = nil
+		}
+		if set.TTL == 0 {
+			set.TTL = current[key].TTL
+		}
+		if set.TTL == 0 {
+			set.TTL = defaultTTL
+		}
+		sets = append(sets, fromRRSet(domainName, set))
+	}
+
+	resp, err := p.client.Patch(context.Background(), rrsetsPath(domainName), sets)
+	if err != nil {
+		err = errors.NewAPI("SetRecords", fmt.Sprintf("failed to write DNS records for %s", domainName), err)
+		for i, r := range result.Records {
+			if r.Status != dnsprovider.ApplyUnchanged {
+				result.Fail(i, err)
+			}
+		}
+		return result, result.Err()
+	}
+	resp.Body.Close()
+	return result, nil
+}
+
+// ListZones returns the domains of the deSEC account
+func (p *DeSECProvider) ListZones() ([]string, error) {
+	resp, err := p.client.Get(context.Background(), "/domains/", nil)
+	if err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list domains", err)
+	}
+
+	var domains []struct {
+		Name string `json:"name"`
+	}
+	if err := httpprovider.ParseJSONResponse(resp, &domains); err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list domains", err)
+	}
+
+	zones := make([]string, 0, len(domains))
+	for _, d := range domains {
+		zones = append(zones, d.Name)
+	}
+	return zones, nil
+}
+
+// Capabilities reports deSEC's minimum TTL as the default TTL
+func (p *DeSECProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *DeSECProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("desec client is not initialized")
+	}
+	return nil
+}
+
+func rrsetsPath(domainName string) string {
+	return "/domains/" + url.PathEscape(domainName) + "/rrsets/"
+}
+
+// Register registers a deSEC provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the deSEC provider when a token is set in the
+// environment. It reports whether the provider was registered; the provider
+// itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{Token: os.Getenv(EnvToken)}
+	if config.Token == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure DeSECProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*DeSECProvider)(nil)
+	_ dnsprovider.ZoneLister = (*DeSECProvider)(nil)
+)
@@ -0,0 +1,135 @@
+package desec
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDeSEC serves the RRset endpoints for example.com and records every bulk write
+type fakeDeSEC struct {
+	writes [][]rrset
+	fail   bool
+}
+
+func (f *fakeDeSEC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Token secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"detail":"Invalid token."}`))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/":
+		w.Write([]byte(`[{"name":"example.com","minimum_ttl":3600},{"name":"example.org","minimum_ttl":3600}]`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/example.com/rrsets/":
+		w.Write([]byte(`[
+			{"domain":"example.com","subname":"","name":"example.com.","type":"A","records":["192.0.2.1","192.0.2.2"],"ttl":3600},
+			{"domain":"example.com","subname":"","name":"example.com.","type":"MX","records":["10 mx1.example.net.","20 mx2.example.net."],"ttl":3600},
+			{"domain":"example.com","subname":"","name":"example.com.","type":"TXT","records":["\"v=spf1 \" \"-all\""],"ttl":3600},
+			{"domain":"example.com","subname":"old","name":"old.example.com.","type":"CNAME","records":["example.com."],"ttl":7200}
+		]`))
+	case r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail":"Not found."}`))
+	case r.Method == http.MethodPatch && r.URL.Path == "/domains/example.com/rrsets/":
+		var sets []rrset
+		json.NewDecoder(r.Body).Decode(&sets)
+		f.writes = append(f.writes, sets)
+		if f.fail {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`[{"ttl":["Ensure this value is greater than or equal to 3600."]}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestProvider(t *testing.T) (*DeSECProvider, *fakeDeSEC) {
+	fake := &fakeDeSEC{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	p, err := New(Config{Token: "secret-token", Endpoint: server.URL})
+	require.NoError(t, err)
+	return p, fake
+}
+
+func TestDeSECProvider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 3600, MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", TTL: 3600, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 3600},
+		{HostName: "old", RecordType: "CNAME", Address: "example.com.", TTL: 7200},
+	}, records)
+
+	_, err = p.GetRecords("missing.com")
+	require.Error(t, err)
+}
+
+func TestDeSECProvider_SetRecords_WritesChangedRRSetsInOneRequest(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 3600, MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mail", MXPref: 30},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 3600},
+		{HostName: "www", RecordType: "CNAME", Address: "example.net"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	// The MX RRset keeps its TTL; the deleted CNAME is written without records
+	require.Equal(t, [][]rrset{{
+		{Subname: "", Type: "MX", TTL: 3600, Records: []string{"10 mx1.example.net.", "30 mail.example.com."}},
+		{Subname: "old", Type: "CNAME", TTL: 7200, Records: []string{}},
+		{Subname: "www", Type: "CNAME", TTL: 3600, Records: []string{"example.net."}},
+	}}, fake.writes)
+}
+
+func TestDeSECProvider_SetRecords_NoChangesAndFailures(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	existing, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	_, err = p.SetRecords("example.com", existing)
+	require.NoError(t, err)
+	require.Empty(t, fake.writes)
+
+	fake.fail = true
+	result, err := p.SetRecords("example.com", existing[:5])
+	require.Error(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyFailed))
+	require.Equal(t, 5, result.Count(dnsprovider.ApplyUnchanged))
+}
+
+func TestDeSECProvider_ListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org"}, zones)
+}
+
+func TestNew_RequiresToken(t *testing.T) {
+	_, err := New(Config{})
+	require.ErrorContains(t, err, "token")
+}
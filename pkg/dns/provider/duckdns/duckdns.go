@@ -0,0 +1,135 @@
+// Package duckdns implements an update-only DNS provider for DuckDNS. Each
+// DuckDNS domain is a single name below duckdns.org, e.g. "home.duckdns.org".
+package duckdns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dns/provider/updateonly"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "duckdns"
+
+	// DefaultEndpoint is the DuckDNS update URL
+	DefaultEndpoint = "https://www.duckdns.org/update"
+
+	// Zone is the parent domain of all DuckDNS domains
+	Zone = "duckdns.org"
+)
+
+// EnvToken is the environment variable read by RegisterFromEnv
+const EnvToken = "DUCKDNS_TOKEN"
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			Token:    credentials["token"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the DuckDNS credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name  string
+	Token string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// New creates a new DuckDNS provider
+func New(config Config) (*updateonly.Provider, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("duckdns requires a token")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	client := httpprovider.NewClient(httpprovider.ClientConfig{BaseURL: endpoint, RateLimiter: ratelimit.For(name, Name)})
+
+	return updateonly.New(name, func(ctx context.Context, fqdn, recordType, address string) error {
+		subdomain, err := Subdomain(fqdn)
+		if err != nil {
+			return err
+		}
+
+		query := map[string]string{
+			"domains": subdomain,
+			"token":   config.Token,
+		}
+		if recordType == dnsrecord.RecordTypeAAAA {
+			query["ipv6"] = address
+		} else {
+			query["ip"] = address
+		}
+
+		resp, err := client.Get(ctx, "", query)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		// DuckDNS answers "KO" without further detail for a bad token or domain
+		if strings.TrimSpace(string(body)) != "OK" {
+			return fmt.Errorf("duckdns update failed for %s: check the token and domain", fqdn)
+		}
+		return nil
+	}), nil
+}
+
+// Subdomain returns the DuckDNS domain name for fqdn, e.g. "home" for
+// "home.duckdns.org". DuckDNS cannot update names below a DuckDNS domain.
+func Subdomain(fqdn string) (string, error) {
+	lower := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	name := strings.TrimSuffix(lower, "."+Zone)
+	if name == "" || name == lower || strings.Contains(name, ".") {
+		return "", fmt.Errorf("'%s' is not a DuckDNS domain (expected <name>.%s)", fqdn, Zone)
+	}
+	return name, nil
+}
+
+// Register registers a DuckDNS provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the DuckDNS provider when its token is set in the
+// environment. It reports whether the provider was registered;
+// the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{Token: os.Getenv(EnvToken)}
+	if config.Token == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
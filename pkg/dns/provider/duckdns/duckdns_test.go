@@ -0,0 +1,48 @@
+package duckdns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubdomain(t *testing.T) {
+	name, err := Subdomain("Home.DuckDNS.org.")
+	require.NoError(t, err)
+	require.Equal(t, "home", name)
+
+	for _, fqdn := range []string{"example.com", "duckdns.org", "a.home.duckdns.org", "HOME"} {
+		_, err := Subdomain(fqdn)
+		require.Error(t, err, fqdn)
+	}
+}
+
+func TestDuckDNS_UpdateAddress(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		if got.Get("token") != "secret" {
+			w.Write([]byte("KO"))
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Token: "secret", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	require.NoError(t, p.UpdateAddress("home.duckdns.org", "@", "AAAA", "2001:db8::1"))
+	require.Equal(t, "home", got.Get("domains"))
+	require.Equal(t, "2001:db8::1", got.Get("ipv6"))
+	require.Empty(t, got.Get("ip"))
+
+	require.Error(t, p.UpdateAddress("home.duckdns.org", "www", "A", "198.51.100.7"))
+
+	bad, err := New(Config{Token: "wrong", Endpoint: server.URL})
+	require.NoError(t, err)
+	require.Error(t, bad.UpdateAddress("home.duckdns.org", "@", "A", "198.51.100.7"))
+}
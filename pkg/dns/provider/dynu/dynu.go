@@ -0,0 +1,137 @@
+// Package dynu implements an update-only DNS provider for Dynu using its
+// dynamic DNS update protocol.
+package dynu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dns/provider/updateonly"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "dynu"
+
+	// DefaultEndpoint is the Dynu IP update URL
+	DefaultEndpoint = "https://api.dynu.com/nic/update"
+)
+
+// Environment variables read by RegisterFromEnv
+const (
+	EnvUsername = "DYNU_USERNAME"
+	EnvPassword = "DYNU_PASSWORD"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			Username: credentials["username"],
+			Password: credentials["password"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the Dynu credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name     string
+	Username string
+	// Password is the account password or its MD5/SHA256 hash, or an IP update password
+	Password string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// New creates a new Dynu provider
+func New(config Config) (*updateonly.Provider, error) {
+	if config.Username == "" || config.Password == "" {
+		return nil, fmt.Errorf("dynu requires a username and password")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	client := httpprovider.NewClient(httpprovider.ClientConfig{BaseURL: endpoint, RateLimiter: ratelimit.For(name, Name)})
+
+	return updateonly.New(name, func(ctx context.Context, fqdn, recordType, address string) error {
+		query := map[string]string{
+			"hostname": fqdn,
+			"username": config.Username,
+			"password": config.Password,
+		}
+		// "no" leaves the other address family untouched
+		if recordType == dnsrecord.RecordTypeAAAA {
+			query["myip"] = "no"
+			query["myipv6"] = address
+		} else {
+			query["myip"] = address
+			query["myipv6"] = "no"
+		}
+
+		resp, err := client.Get(ctx, "", query)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return checkResponse(strings.TrimSpace(string(body)))
+	}), nil
+}
+
+// checkResponse interprets a dyndns2-style return code
+func checkResponse(body string) error {
+	code := strings.Fields(body)
+	if len(code) > 0 && (code[0] == "good" || code[0] == "nochg") {
+		return nil
+	}
+	if body == "" {
+		body = "empty response"
+	}
+	return fmt.Errorf("dynu update failed: %s", body)
+}
+
+// Register registers a Dynu provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Dynu provider when its credentials are set in
+// the environment. It reports whether the provider was registered;
+// the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		Username: os.Getenv(EnvUsername),
+		Password: os.Getenv(EnvPassword),
+	}
+	if config.Username == "" || config.Password == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
@@ -0,0 +1,50 @@
+package dynu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynu_UpdateAddress(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		if got.Get("password") != "secret" {
+			w.Write([]byte("badauth"))
+			return
+		}
+		w.Write([]byte("good " + got.Get("myip")))
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Username: "user", Password: "secret", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	require.NoError(t, p.UpdateAddress("example.dynu.net", "@", "A", "198.51.100.7"))
+	require.Equal(t, "example.dynu.net", got.Get("hostname"))
+	require.Equal(t, "user", got.Get("username"))
+	require.Equal(t, "198.51.100.7", got.Get("myip"))
+	require.Equal(t, "no", got.Get("myipv6"))
+
+	require.NoError(t, p.UpdateAddress("example.dynu.net", "vpn", "AAAA", "2001:db8::1"))
+	require.Equal(t, "vpn.example.dynu.net", got.Get("hostname"))
+	require.Equal(t, "no", got.Get("myip"))
+	require.Equal(t, "2001:db8::1", got.Get("myipv6"))
+
+	bad, err := New(Config{Username: "user", Password: "wrong", Endpoint: server.URL})
+	require.NoError(t, err)
+	err = bad.UpdateAddress("example.dynu.net", "@", "A", "198.51.100.7")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "badauth")
+}
+
+func TestCheckResponse(t *testing.T) {
+	require.NoError(t, checkResponse("good 198.51.100.7"))
+	require.NoError(t, checkResponse("nochg"))
+	require.Error(t, checkResponse("nohost"))
+	require.Error(t, checkResponse(""))
+}
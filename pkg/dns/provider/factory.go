@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory creates a provider instance called name from its credentials. The
+// credential keys depend on the provider type.
+type Factory func(name string, credentials map[string]string) (Provider, error)
+
+var (
+	factories     = make(map[string]Factory)
+	factoriesLock sync.RWMutex
+)
+
+// RegisterFactory registers the factory for a provider type, allowing instances
+// of the type to be created from configuration
+func RegisterFactory(providerType string, factory Factory) error {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+
+	if providerType == "" {
+		return fmt.Errorf("provider type cannot be empty")
+	}
+
+	if _, exists := factories[providerType]; exists {
+		return fmt.Errorf("provider type %s is already registered", providerType)
+	}
+
+	factories[providerType] = factory
+	return nil
+}
+
+// UnregisterFactory removes the factory for a provider type (mainly for testing)
+func UnregisterFactory(providerType string) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+
+	delete(factories, providerType)
+}
+
+// NewInstance creates a provider of the given type called name
+func NewInstance(name, providerType string, credentials map[string]string) (Provider, error) {
+	factoriesLock.RLock()
+	factory, exists := factories[providerType]
+	factoriesLock.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown provider type %s", providerType)
+	}
+
+	provider, err := factory(name, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s provider %s: %w", providerType, name, err)
+	}
+
+	return provider, nil
+}
+
+// Types returns the provider types instances can be created for, sorted by name
+func Types() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for providerType := range factories {
+		types = append(types, providerType)
+	}
+	sort.Strings(types)
+
+	return types
+}
@@ -0,0 +1,352 @@
+// Package gandi implements the DNS provider for Gandi LiveDNS.
+//
+// LiveDNS manages records as rrsets: all values of one name and type share a
+// TTL and are written together. Each value is returned as its own record, and
+// records are grouped back into record sets when they are written. All rrsets
+// of a hostname with a change are replaced in a single request, so the update
+// of a name is atomic.
+package gandi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "gandi"
+
+	// DefaultEndpoint is the LiveDNS API endpoint
+	DefaultEndpoint = "https://api.gandi.net/v5/livedns"
+
+	// defaultTTL is the TTL LiveDNS gives rrsets written without one
+	defaultTTL = 10800
+)
+
+// Environment variables read by RegisterFromEnv; the names match lego's gandiv5 provider
+const (
+	EnvAPIKey   = "GANDIV5_API_KEY"
+	EnvToken    = "GANDIV5_PERSONAL_ACCESS_TOKEN"
+	EnvEndpoint = "GANDIV5_ENDPOINT"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			APIKey:   credentials["api_key"],
+			Token:    credentials["token"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the Gandi credentials. Either an API key or a personal access
+// token is required; the token is used when both are set.
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name   string
+	APIKey string
+	Token  string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// GandiProvider implements the DNS Provider interface for Gandi LiveDNS
+type GandiProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new Gandi provider
+func New(config Config) (*GandiProvider, error) {
+	var authorization string
+	switch {
+	case config.Token != "":
+		authorization = "Bearer " + config.Token
+	case config.APIKey != "":
+		authorization = "Apikey " + config.APIKey
+	default:
+		return nil, fmt.Errorf("gandi requires an API key or a personal access token")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &GandiProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL: strings.TrimSuffix(endpoint, "/"),
+			Headers: map[string]string{
+				"Authorization": authorization,
+				"Accept":        "application/json",
+			},
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *GandiProvider) Name() string {
+	return p.name
+}
+
+// rrset is a LiveDNS resource record set
+type rrset struct {
+	Name   string   `json:"rrset_name,omitempty"`
+	Type   string   `json:"rrset_type,omitempty"`
+	TTL    int      `json:"rrset_ttl,omitempty"`
+	Values []string `json:"rrset_values"`
+}
+
+// nameItems is the body that replaces all rrsets of a name at once
+type nameItems struct {
+	Items []rrset `json:"items"`
+}
+
+// GetRecords retrieves all DNS records for a domain, one record per rrset value
+func (p *GandiProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	resp, err := p.client.Get(context.Background(), recordsPath(domainName), nil)
+	if err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	var sets []rrset
+	if err := httpprovider.ParseJSONResponse(resp, &sets); err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	var records []dnsrecord.Record
+	for _, set := range sets {
+		for _, value := range set.Values {
+			records = append(records, toRecord(set, value))
+		}
+	}
+	return records, nil
+}
+
+// toRecord converts one value of an rrset into a record
+func toRecord(set rrset, value string) dnsrecord.Record {
+	record := dnsrecord.Record{
+		HostName:   set.Name,
+		RecordType: set.Type,
+		Address:    value,
+		TTL:        set.TTL,
+	}
+
+	switch set.Type {
+	case dnsrecord.RecordTypeMX:
+		if pref, target, ok := strings.Cut(value, " "); ok {
+			if n, err := strconv.Atoi(pref); err == nil {
+				record.MXPref = n
+				record.Address = strings.TrimSpace(target)
+			}
+		}
+	case dnsrecord.RecordTypeTXT:
+		record.Address = unquoteTXT(value)
+	}
+	return record
+}
+
+// toValue converts a record into an rrset value
+func toValue(r dnsrecord.Record) string {
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeMX:
+		return fmt.Sprintf("%d %s", r.MXPref, r.Address)
+	case dnsrecord.RecordTypeTXT:
+		return strconv.Quote(r.Address)
+	default:
+		return r.Address
+	}
+}
+
+// unquoteTXT joins the quoted strings of a TXT value, e.g. `"v=spf1 " "-all"`
+func unquoteTXT(value string) string {
+	if !strings.HasPrefix(value, `"`) {
+		return value
+	}
+
+	var sb strings.Builder
+	rest := strings.TrimSpace(value)
+	for rest != "" {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return value
+		}
+		s, _ := strconv.Unquote(quoted)
+		sb.WriteString(s)
+		rest = strings.TrimSpace(rest[len(quoted):])
+	}
+	return sb.String()
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Records are grouped into rrsets, and all rrsets of a hostname with a changed
+// rrset are replaced in one request, so a name never serves a mix of old and
+// new records. A failing hostname fails all of its changed records but does
+// not stop the others.
+func (p *GandiProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	current := make(map[dnsrecord.SetKey]dnsrecord.RecordSet)
+	for _, set := range dnsrecord.GroupRecordSets(existing) {
+		current[set.Key()] = set
+	}
+	desired := make(map[string][]dnsrecord.RecordSet)
+	for _, set := range dnsrecord.GroupRecordSets(records) {
+		name := set.Key().HostName
+		desired[name] = append(desired[name], set)
+	}
+
+	// Hostnames with a changed rrset, in the order of ChangedSets
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range result.ChangedSets() {
+		if !seen[key.HostName] {
+			seen[key.HostName] = true
+			names = append(names, key.HostName)
+		}
+	}
+
+	for _, name := range names {
+		var err error
+		if sets := desired[name]; len(sets) > 0 {
+			err = p.putName(domainName, sets, current)
+		} else {
+			err = p.deleteName(domainName, name)
+		}
+		if err != nil {
+			result.FailSets(func(key dnsrecord.SetKey) bool { return key.HostName == name }, err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// putName replaces all rrsets of a hostname with sets. An rrset written
+// without a TTL keeps its current TTL.
+func (p *GandiProvider) putName(domainName string, sets []dnsrecord.RecordSet, current map[dnsrecord.SetKey]dnsrecord.RecordSet) error {
+	body := nameItems{}
+	for _, set := range sets {
+		item := rrset{Type: set.Key().RecordType, TTL: set.TTL}
+		if item.TTL == 0 {
+			item.TTL = current[set.Key()].TTL
+		}
+		for _, r := range set.Records {
+			item.Values = append(item.Values, toValue(r))
+		}
+		body.Items = append(body.Items, item)
+	}
+
+	resp, err := p.client.Put(context.Background(), namePath(domainName, sets[0].HostName), body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// deleteName deletes all rrsets of a hostname
+func (p *GandiProvider) deleteName(domainName, name string) error {
+	resp, err := p.client.Delete(context.Background(), namePath(domainName, name))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListZones returns the domains managed in LiveDNS
+func (p *GandiProvider) ListZones() ([]string, error) {
+	resp, err := p.client.Get(context.Background(), "/domains", nil)
+	if err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list domains", err)
+	}
+
+	var domains []struct {
+		FQDN string `json:"fqdn"`
+	}
+	if err := httpprovider.ParseJSONResponse(resp, &domains); err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list domains", err)
+	}
+
+	zones := make([]string, 0, len(domains))
+	for _, d := range domains {
+		zones = append(zones, d.FQDN)
+	}
+	return zones, nil
+}
+
+// Capabilities reports the default TTL of LiveDNS rrsets
+func (p *GandiProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *GandiProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("gandi client is not initialized")
+	}
+	return nil
+}
+
+func recordsPath(domainName string) string {
+	return "/domains/" + url.PathEscape(domainName) + "/records"
+}
+
+func namePath(domainName, name string) string {
+	return recordsPath(domainName) + "/" + url.PathEscape(name)
+}
+
+// Register registers a Gandi provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Gandi provider when an API key or personal
+// access token is set in the environment. It reports whether the provider was
+// registered; the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		APIKey:   os.Getenv(EnvAPIKey),
+		Token:    os.Getenv(EnvToken),
+		Endpoint: os.Getenv(EnvEndpoint),
+	}
+	if config.APIKey == "" && config.Token == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure GandiProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*GandiProvider)(nil)
+	_ dnsprovider.ZoneLister = (*GandiProvider)(nil)
+)
@@ -0,0 +1,181 @@
+package gandi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCall is a request received by fakeGandi
+type fakeCall struct {
+	Method string
+	Path   string
+	Body   nameItems
+}
+
+// fakeGandi serves the LiveDNS record endpoints for example.com and records
+// every write; writes to paths in fail are rejected
+type fakeGandi struct {
+	calls []fakeCall
+	fail  map[string]bool
+}
+
+func (f *fakeGandi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Apikey secret-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domains":
+		w.Write([]byte(`[{"fqdn":"example.com"},{"fqdn":"example.org"}]`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/example.com/records":
+		w.Write([]byte(`[
+			{"rrset_name":"@","rrset_type":"A","rrset_ttl":3600,"rrset_values":["192.0.2.1","192.0.2.2"]},
+			{"rrset_name":"@","rrset_type":"MX","rrset_ttl":10800,"rrset_values":["10 mx1.example.net.","20 mx2.example.net."]},
+			{"rrset_name":"@","rrset_type":"TXT","rrset_ttl":10800,"rrset_values":["\"v=spf1 \" \"-all\""]},
+			{"rrset_name":"old","rrset_type":"CNAME","rrset_ttl":300,"rrset_values":["example.com."]}
+		]`))
+	case r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"Unknown domain","object":"HTTPNotFound","cause":"Not Found"}`))
+	default:
+		call := fakeCall{Method: r.Method, Path: r.URL.Path}
+		if r.Method == http.MethodPut {
+			json.NewDecoder(r.Body).Decode(&call.Body)
+		}
+		f.calls = append(f.calls, call)
+		if f.fail[r.URL.Path] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":400,"message":"Invalid rrset","object":"HTTPBadRequest","cause":"Bad Request"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"message":"DNS Record Created"}`))
+	}
+}
+
+func newTestProvider(t *testing.T) (*GandiProvider, *fakeGandi) {
+	fake := &fakeGandi{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	p, err := New(Config{APIKey: "secret-key", Endpoint: server.URL})
+	require.NoError(t, err)
+	return p, fake
+}
+
+func TestGandiProvider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 10800, MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", TTL: 10800, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 10800},
+		{HostName: "old", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+	}, records)
+
+	_, err = p.GetRecords("other.com")
+	require.ErrorContains(t, err, "Unknown domain")
+}
+
+func TestGandiProvider_SetRecords_ReplacesChangedNames(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		// One value of the A rrset changes; the rrset keeps its TTL
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.3"},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 10800, MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", TTL: 10800, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 10800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 600},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+	require.Equal(t, 4, result.Count(dnsprovider.ApplyUnchanged))
+
+	require.Equal(t, []fakeCall{
+		{Method: http.MethodPut, Path: "/domains/example.com/records/@", Body: nameItems{Items: []rrset{
+			{Type: "A", TTL: 3600, Values: []string{"192.0.2.1", "192.0.2.3"}},
+			{Type: "MX", TTL: 10800, Values: []string{"10 mx1.example.net.", "20 mx2.example.net."}},
+			{Type: "TXT", TTL: 10800, Values: []string{`"v=spf1 -all"`}},
+		}}},
+		{Method: http.MethodDelete, Path: "/domains/example.com/records/old"},
+		{Method: http.MethodPut, Path: "/domains/example.com/records/www", Body: nameItems{Items: []rrset{
+			{Type: "CNAME", TTL: 600, Values: []string{"example.com."}},
+		}}},
+	}, fake.calls)
+}
+
+func TestGandiProvider_SetRecords_QuotesTXTAndJoinsMX(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	_, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 5},
+		{HostName: "@", RecordType: "TXT", Address: `v=spf1 include:"x" -all`},
+		{HostName: "old", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []fakeCall{
+		{Method: http.MethodPut, Path: "/domains/example.com/records/@", Body: nameItems{Items: []rrset{
+			{Type: "A", TTL: 3600, Values: []string{"192.0.2.1", "192.0.2.2"}},
+			{Type: "MX", TTL: 10800, Values: []string{"5 mx1.example.net."}},
+			{Type: "TXT", TTL: 10800, Values: []string{`"v=spf1 include:\"x\" -all"`}},
+		}}},
+	}, fake.calls)
+}
+
+func TestGandiProvider_SetRecords_FailsWholeName(t *testing.T) {
+	p, fake := newTestProvider(t)
+	fake.fail = map[string]bool{"/domains/example.com/records/@": true}
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.9", TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 10800, MXPref: 10},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 10800},
+		{HostName: "old", RecordType: "CNAME", Address: "example.org.", TTL: 300},
+	})
+	require.ErrorContains(t, err, "Invalid rrset")
+
+	// The A and MX changes at the apex fail together; the unchanged records
+	// and the update of old are not affected
+	failed := result.Failed()
+	require.Len(t, failed, 3)
+	for _, r := range failed {
+		require.Equal(t, "@", r.Record.HostName)
+	}
+	require.Equal(t, 2, result.Count(dnsprovider.ApplyUnchanged))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+}
+
+func TestGandiProvider_ListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org"}, zones)
+}
+
+func TestNew_RequiresCredentials(t *testing.T) {
+	_, err := New(Config{})
+	require.Error(t, err)
+
+	p, err := New(Config{Token: "pat"})
+	require.NoError(t, err)
+	require.Equal(t, Name, p.Name())
+}
@@ -0,0 +1,206 @@
+// Package he implements an update-only DNS provider for Hurricane Electric's
+// free DNS service (dns.he.net).
+//
+// HE has no record management API. Records that have dynamic DNS enabled in
+// the web interface can be updated through its update form, each with its own
+// key, and zones are read with a zone transfer from HE's name servers (allow
+// transfers to your address in the zone settings first).
+package he
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/axfr"
+	"zonekit/pkg/dns/provider/updateonly"
+	"zonekit/pkg/dnsrecord"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "he"
+
+	// DefaultUpdateEndpoint is HE's dynamic DNS update form
+	DefaultUpdateEndpoint = "https://dyn.dns.he.net/nic/update"
+
+	// DefaultAXFRServer is the name server zones are transferred from
+	DefaultAXFRServer = "ns1.he.net:53"
+)
+
+// Environment variables read by RegisterFromEnv
+const (
+	EnvKeys       = "HE_DDNS_KEYS"
+	EnvAXFRServer = "HE_AXFR_SERVER"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		keys, err := ParseKeys(credentials["keys"])
+		if err != nil {
+			return nil, err
+		}
+		return New(Config{
+			Name:           name,
+			Keys:           keys,
+			UpdateEndpoint: credentials["update_endpoint"],
+			AXFRServer:     credentials["axfr_server"],
+		})
+	})
+}
+
+// Config holds the per-record dynamic DNS keys
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name string
+	// Keys maps a fully qualified host name to the record's dynamic DNS key. A
+	// key specific to one record type is stored as "TYPE:host", e.g.
+	// "AAAA:home.example.com" or "TXT:_acme-challenge.example.com".
+	Keys map[string]string
+	// UpdateEndpoint overrides DefaultUpdateEndpoint
+	UpdateEndpoint string
+	// AXFRServer overrides DefaultAXFRServer
+	AXFRServer string
+}
+
+// New creates a new Hurricane Electric provider
+func New(config Config) (*updateonly.Provider, error) {
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("he requires at least one dynamic DNS key")
+	}
+
+	keys := make(map[string]string, len(config.Keys))
+	for host, key := range config.Keys {
+		keys[strings.ToLower(host)] = key
+	}
+
+	endpoint := config.UpdateEndpoint
+	if endpoint == "" {
+		endpoint = DefaultUpdateEndpoint
+	}
+	server := config.AXFRServer
+	if server == "" {
+		server = DefaultAXFRServer
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+
+	update := func(ctx context.Context, fqdn, recordType, address string) error {
+		key, ok := keys[strings.ToLower(recordType+":"+fqdn)]
+		if !ok {
+			key, ok = keys[strings.ToLower(fqdn)]
+		}
+		if !ok {
+			return fmt.Errorf("no dynamic DNS key configured for %s %s", recordType, fqdn)
+		}
+
+		form := url.Values{
+			"hostname": {fqdn},
+			"password": {key},
+		}
+		if recordType == dnsrecord.RecordTypeTXT {
+			form.Set("txt", address)
+		} else {
+			form.Set("myip", address)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("he update failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return checkResponse(strings.TrimSpace(string(body)))
+	}
+
+	read := func(ctx context.Context, domainName string) ([]dnsrecord.Record, error) {
+		return axfr.Transfer(ctx, server, domainName)
+	}
+
+	return updateonly.New(name, update,
+		updateonly.WithRecordTypes(dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeTXT),
+		updateonly.WithRecordReader(read),
+	), nil
+}
+
+// checkResponse interprets a dyndns2-style return code
+func checkResponse(body string) error {
+	code := strings.Fields(body)
+	if len(code) > 0 && (code[0] == "good" || code[0] == "nochg") {
+		return nil
+	}
+	switch body {
+	case "":
+		body = "empty response"
+	case "badauth":
+		body = "badauth (wrong key, or dynamic DNS is not enabled for the record)"
+	}
+	return fmt.Errorf("he update failed: %s", body)
+}
+
+// ParseKeys parses a comma-separated list of host=key pairs, where the host may
+// carry a record type prefix: "home.example.com=k1,AAAA:home.example.com=k2"
+func ParseKeys(s string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		host, key, ok := strings.Cut(pair, "=")
+		if !ok || host == "" || key == "" {
+			return nil, fmt.Errorf("invalid key entry '%s' (expected host=key)", pair)
+		}
+		keys[strings.TrimSpace(host)] = strings.TrimSpace(key)
+	}
+	return keys, nil
+}
+
+// Register registers a Hurricane Electric provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Hurricane Electric provider when dynamic DNS
+// keys are set in the environment. It reports whether the provider was registered;
+// the keys are parsed and the provider constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	value := os.Getenv(EnvKeys)
+	if value == "" {
+		return false, nil
+	}
+
+	axfrServer := os.Getenv(EnvAXFRServer)
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		keys, err := ParseKeys(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvKeys, err)
+		}
+		return New(Config{Keys: keys, AXFRServer: axfrServer})
+	})
+}
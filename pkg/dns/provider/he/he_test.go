@@ -0,0 +1,76 @@
+package he
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("home.example.com=k1, AAAA:home.example.com=k2,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"home.example.com": "k1", "AAAA:home.example.com": "k2"}, keys)
+
+	_, err = ParseKeys("home.example.com")
+	require.Error(t, err)
+}
+
+func TestHE_Update(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		got = r.PostForm
+		switch got.Get("password") {
+		case "k1", "k2", "k3":
+			w.Write([]byte("good"))
+		default:
+			w.Write([]byte("badauth"))
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Config{
+		Keys: map[string]string{
+			"home.example.com":                "k1",
+			"AAAA:home.example.com":           "k2",
+			"TXT:_acme-challenge.example.com": "k3",
+			"other.example.com":               "wrong",
+		},
+		UpdateEndpoint: server.URL,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, p.UpdateAddress("example.com", "home", "A", "198.51.100.7"))
+	require.Equal(t, "home.example.com", got.Get("hostname"))
+	require.Equal(t, "k1", got.Get("password"))
+	require.Equal(t, "198.51.100.7", got.Get("myip"))
+
+	require.NoError(t, p.UpdateAddress("example.com", "home", "AAAA", "2001:db8::1"))
+	require.Equal(t, "k2", got.Get("password"))
+
+	require.NoError(t, p.UpdateAddress("example.com", "_acme-challenge", "TXT", "token"))
+	require.Equal(t, "k3", got.Get("password"))
+	require.Equal(t, "token", got.Get("txt"))
+
+	err = p.UpdateAddress("example.com", "other", "A", "198.51.100.7")
+	require.ErrorContains(t, err, "badauth")
+
+	err = p.UpdateAddress("example.com", "unknown", "A", "198.51.100.7")
+	require.ErrorContains(t, err, "no dynamic DNS key")
+
+	caps := dnsprovider.CapabilitiesOf(p)
+	require.True(t, caps.UpdateOnly)
+	require.True(t, caps.SupportsRecordType("TXT"))
+	require.False(t, caps.SupportsRecordType("MX"))
+}
+
+func TestNew_RequiresKeys(t *testing.T) {
+	_, err := New(Config{})
+	require.Error(t, err)
+}
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
+	"zonekit/pkg/dns/provider/auth"
 	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/tracing"
 )
 
 // Client is a generic HTTP client for DNS provider APIs
@@ -17,16 +22,24 @@ type Client struct {
 	httpClient *http.Client
 	baseURL    string
 	headers    map[string]string
+	query      map[string]string
 	timeout    time.Duration
 	retries    int
+	signer     auth.RequestSigner
+	limiter    *ratelimit.Limiter
+	now        func() time.Time
 }
 
 // ClientConfig configures the HTTP client
 type ClientConfig struct {
 	BaseURL string
 	Headers map[string]string
-	Timeout time.Duration // in seconds
+	Query   map[string]string // added to every request, e.g. query parameter credentials
+	Timeout time.Duration     // in seconds
 	Retries int
+	Signer  auth.RequestSigner // optional, signs every request attempt
+	// RateLimiter paces every request attempt; nil does not limit
+	RateLimiter *ratelimit.Limiter
 }
 
 // NewClient creates a new HTTP client with the given configuration
@@ -47,8 +60,12 @@ func NewClient(config ClientConfig) *Client {
 		},
 		baseURL: config.BaseURL,
 		headers: config.Headers,
+		query:   config.Query,
 		timeout: timeout,
 		retries: retries,
+		signer:  config.Signer,
+		limiter: config.RateLimiter,
+		now:     time.Now,
 	}
 }
 
@@ -62,53 +79,32 @@ type RequestOptions struct {
 }
 
 // Do performs an HTTP request with retry logic
-func (c *Client) Do(ctx context.Context, opts RequestOptions) (*http.Response, error) {
+func (c *Client) Do(ctx context.Context, opts RequestOptions) (resp *http.Response, err error) {
 	url := c.baseURL + opts.Path
 
+	ctx, span := c.startSpan(ctx, opts.Method, url)
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
+		}
+		span.End(err)
+	}()
+
 	// Build request body
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	if opts.Body != nil {
-		bodyBytes, err := json.Marshal(opts.Body)
+		var err error
+		bodyBytes, err = json.Marshal(opts.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
-	}
-
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, opts.Method, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set default headers
-	for key, value := range c.headers {
-		req.Header.Set(key, value)
-	}
-
-	// Set request-specific headers
-	for key, value := range opts.Headers {
-		req.Header.Set(key, value)
-	}
-
-	// Set content-type if body is present
-	if opts.Body != nil && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	// Add query parameters
-	if len(opts.Query) > 0 {
-		q := req.URL.Query()
-		for key, value := range opts.Query {
-			q.Add(key, value)
-		}
-		req.URL.RawQuery = q.Encode()
 	}
 
 	// Perform request with retry logic
 	var lastErr error
 	for attempt := 0; attempt <= c.retries; attempt++ {
 		if attempt > 0 {
+			span.SetAttributes(tracing.Int("http.request.resend_count", attempt))
 			// Exponential backoff: 1s, 2s, 4s
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
 			select {
@@ -118,9 +114,20 @@ func (c *Client) Do(ctx context.Context, opts RequestOptions) (*http.Response, e
 			}
 		}
 
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Each attempt gets a fresh request so the body can be re-read and
+		// signatures carry the attempt's timestamp
+		req, err := c.newRequest(ctx, url, opts, bodyBytes)
+		if err != nil {
+			return nil, err
+		}
+
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			lastErr = err
+			lastErr = c.redact(err)
 			continue
 		}
 
@@ -152,6 +159,101 @@ func (c *Client) Do(ctx context.Context, opts RequestOptions) (*http.Response, e
 	)
 }
 
+// newRequest builds and, when a signer is configured, signs a single request attempt
+func (c *Client) newRequest(ctx context.Context, url string, opts RequestOptions, bodyBytes []byte) (*http.Request, error) {
+	var bodyReader io.Reader
+	if bodyBytes != nil {
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, opts.Method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set default headers
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set request-specific headers
+	for key, value := range opts.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set content-type if body is present
+	if bodyBytes != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Add default and request-specific query parameters
+	if len(c.query) > 0 || len(opts.Query) > 0 {
+		q := req.URL.Query()
+		for key, value := range c.query {
+			q.Set(key, value)
+		}
+		for key, value := range opts.Query {
+			q.Add(key, value)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	// Continue the trace in the provider, before signing so that signers that
+	// sign every header cover it
+	if tracing.Enabled() {
+		if span := tracing.SpanFromContext(ctx); span != nil {
+			req.Header.Set("traceparent", span.TraceParent())
+		}
+	}
+
+	if c.signer != nil {
+		if err := c.signer.Sign(&auth.SignableRequest{
+			Method:    req.Method,
+			URL:       req.URL,
+			Header:    req.Header,
+			Body:      bodyBytes,
+			Timestamp: c.now(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// startSpan starts the trace span of a request. The URL is recorded without its
+// query, which may hold credentials.
+func (c *Client) startSpan(ctx context.Context, method, rawURL string) (context.Context, *tracing.Span) {
+	attributes := []tracing.Attribute{tracing.String("http.request.method", method)}
+	if u, err := url.Parse(rawURL); err == nil {
+		u.RawQuery = ""
+		u.User = nil
+		attributes = append(attributes, tracing.String("url.full", u.String()), tracing.String("server.address", u.Hostname()))
+	}
+	return tracing.StartClient(ctx, "HTTP "+method, attributes...)
+}
+
+// redact removes the default query parameters, which may hold credentials,
+// from the URL that transport errors include in their message
+func (c *Client) redact(err error) error {
+	var urlErr *url.Error
+	if len(c.query) == 0 || !stderrors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return &url.Error{Op: urlErr.Op, URL: "(redacted)", Err: urlErr.Err}
+	}
+	q := u.Query()
+	for key := range c.query {
+		if q.Has(key) {
+			q.Set(key, "REDACTED")
+		}
+	}
+	u.RawQuery = q.Encode()
+	return &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+}
+
 // Get performs a GET request
 func (c *Client) Get(ctx context.Context, path string, query map[string]string) (*http.Response, error) {
 	return c.Do(ctx, RequestOptions{
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"zonekit/pkg/dns/provider/auth"
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SignsRequest(t *testing.T) {
+	var gotHeader, gotQuery, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Signature")
+		gotQuery = r.URL.Query().Get("Signature")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var seen *auth.SignableRequest
+	client := NewClient(ClientConfig{
+		BaseURL: server.URL,
+		Signer: auth.SignerFunc(func(req *auth.SignableRequest) error {
+			seen = req
+			req.Header.Set("X-Signature", fmt.Sprintf("%s:%s:%d", req.Method, req.URL.Path, len(req.Body)))
+			q := req.URL.Query()
+			q.Set("Signature", req.Timestamp.Format(time.RFC3339))
+			req.URL.RawQuery = q.Encode()
+			return nil
+		}),
+	})
+	client.now = func() time.Time { return fixed }
+
+	resp, err := client.Post(context.Background(), "/records", map[string]string{"name": "www"})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.NotNil(t, seen)
+	require.Equal(t, http.MethodPost, seen.Method)
+	require.Equal(t, `{"name":"www"}`, string(seen.Body))
+	require.Equal(t, fixed, seen.Timestamp)
+	require.Equal(t, "POST:/records:14", gotHeader)
+	require.Equal(t, "2024-01-02T03:04:05Z", gotQuery)
+	require.Equal(t, `{"name":"www"}`, gotBody)
+}
+
+func TestClient_SignerError(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{
+		BaseURL: server.URL,
+		Signer: auth.SignerFunc(func(req *auth.SignableRequest) error {
+			return fmt.Errorf("missing secret")
+		}),
+	})
+
+	_, err := client.Get(context.Background(), "/records", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sign request: missing secret")
+	require.False(t, called)
+}
+
+func TestClient_QueryParams(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{BaseURL: server.URL, Query: map[string]string{"auth-id": "42", "auth-password": "secret"}})
+	resp, err := client.Get(context.Background(), "/records", map[string]string{"domain-name": "example.com"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "auth-id=42&auth-password=secret&domain-name=example.com", gotQuery)
+
+	// Transport errors do not leak the credentials
+	server.Close()
+	client.retries = 0
+	_, err = client.Get(context.Background(), "/records", nil)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+	require.Contains(t, err.Error(), "auth-password=REDACTED")
+}
+
+func TestClient_TraceParent(t *testing.T) {
+	var gotTraceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := NewClient(ClientConfig{BaseURL: server.URL})
+
+	// Without tracing, no header is added
+	resp, err := client.Get(context.Background(), "/records", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Empty(t, gotTraceParent)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	exporter, err := tracing.NewExporter(tracing.Config{Endpoint: collector.URL})
+	require.NoError(t, err)
+	tracing.SetExporter(exporter)
+	defer func() {
+		tracing.SetExporter(nil)
+		require.NoError(t, exporter.Shutdown(context.Background()))
+	}()
+
+	ctx, parent := tracing.Start(context.Background(), "dns.get_records")
+	resp, err = client.Get(ctx, "/records", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End(nil)
+	require.Regexp(t, "^00-"+parent.TraceID()+"-[0-9a-f]{16}-01$", gotTraceParent)
+}
+
+func TestClient_RateLimiter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := NewClient(ClientConfig{
+		BaseURL:     server.URL,
+		RateLimiter: ratelimit.NewLimiter(ratelimit.Rate{Requests: 1, Per: time.Hour}),
+	})
+
+	resp, err := client.Get(context.Background(), "/records", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The next request waits for a token, which takes longer than the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.Get(ctx, "/records", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, requests, "the paced request is not sent")
+}
@@ -0,0 +1,294 @@
+// Package libdnsbridge exposes any zonekit provider, including the
+// OpenAPI-generated ones, as a libdns provider (github.com/libdns/libdns), the
+// record interfaces Caddy and other ACME clients written in Go build on.
+// Provider implements the libdns RecordGetter, RecordAppender, RecordSetter,
+// RecordDeleter and ZoneLister interfaces.
+//
+// As in libdns, zones are fully qualified ("example.com.") or not, record
+// names are relative to the zone and "@" is the apex. Records are returned as
+// the libdns type of their record type, e.g. libdns.Address or libdns.MX, and
+// as libdns.RR for types libdns has no struct for.
+package libdnsbridge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// Provider adapts a zonekit provider to the libdns methods. Every method reads
+// the zone, changes it and writes it back, so calls through one Provider are
+// serialized; it is safe for concurrent use.
+type Provider struct {
+	provider dnsprovider.Provider
+	mu       sync.Mutex
+}
+
+// New wraps a zonekit provider
+func New(provider dnsprovider.Provider) *Provider {
+	return &Provider{provider: provider}
+}
+
+// NewByName wraps a registered provider, e.g. "cloudflare" or a provider
+// instance from the config file
+func NewByName(name string) (*Provider, error) {
+	provider, err := dnsprovider.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return New(provider), nil
+}
+
+// GetRecords returns all records of the zone
+func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	records, err := p.provider.GetRecords(zoneName(zone))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]libdns.Record, 0, len(records))
+	for _, r := range records {
+		out = append(out, fromRecord(r))
+	}
+	return out, nil
+}
+
+// AppendRecords adds records to the zone and returns the records that were
+// added. Records that already exist are left alone and not returned.
+func (p *Provider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	domainName := zoneName(zone)
+	existing, err := p.provider.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := existing
+	var added []libdns.Record
+	for _, rec := range recs {
+		r, err := toRecord(rec)
+		if err != nil {
+			return nil, err
+		}
+		if contains(desired, r) {
+			continue
+		}
+		desired = append(desired, r)
+		added = append(added, rec)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if _, err := p.provider.SetRecords(domainName, desired); err != nil {
+		return nil, fmt.Errorf("failed to append records to %s: %w", domainName, err)
+	}
+	return added, nil
+}
+
+// SetRecords makes the records of each name and type given in recs exactly
+// those in recs, creating, updating or deleting records as needed. Names and
+// types not in recs are not touched. It returns the records that were set.
+func (p *Provider) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	domainName := zoneName(zone)
+	existing, err := p.provider.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dnsrecord.Record, 0, len(recs))
+	replaced := make(map[[2]string]bool, len(recs))
+	for _, rec := range recs {
+		r, err := toRecord(rec)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+		replaced[keyOf(r)] = true
+	}
+	var desired []dnsrecord.Record
+	for _, r := range existing {
+		if !replaced[keyOf(r)] {
+			desired = append(desired, r)
+		}
+	}
+	desired = append(desired, records...)
+
+	if _, err := p.provider.SetRecords(domainName, desired); err != nil {
+		return nil, fmt.Errorf("failed to set records of %s: %w", domainName, err)
+	}
+	return recs, nil
+}
+
+// DeleteRecords deletes the records of the zone that match one of recs and
+// returns them. The name must match; an empty type, zero TTL or empty value
+// matches any.
+func (p *Provider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	domainName := zoneName(zone)
+	existing, err := p.provider.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []dnsrecord.Record
+	var deleted []libdns.Record
+	for _, r := range existing {
+		if matchesAny(r, recs) {
+			deleted = append(deleted, fromRecord(r))
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+	if _, err := p.provider.SetRecords(domainName, kept); err != nil {
+		return nil, fmt.Errorf("failed to delete records from %s: %w", domainName, err)
+	}
+	return deleted, nil
+}
+
+// ListZones returns the zones of providers that can list them
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	lister, ok := p.provider.(dnsprovider.ZoneLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s cannot list zones", p.provider.Name())
+	}
+	names, err := lister.ListZones()
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]libdns.Zone, 0, len(names))
+	for _, name := range names {
+		zones = append(zones, libdns.Zone{Name: strings.TrimSuffix(name, ".") + "."})
+	}
+	return zones, nil
+}
+
+// zoneName returns a libdns zone as a zonekit domain name
+func zoneName(zone string) string {
+	return strings.TrimSuffix(zone, ".")
+}
+
+// toRecord converts a libdns record; an empty name is the apex
+func toRecord(rec libdns.Record) (dnsrecord.Record, error) {
+	rr := rec.RR()
+	r := dnsrecord.Record{
+		HostName:   rr.Name,
+		RecordType: strings.ToUpper(rr.Type),
+		Address:    rr.Data,
+		TTL:        int(rr.TTL / time.Second),
+	}
+	if r.HostName == "" {
+		r.HostName = "@"
+	}
+	if r.RecordType == dnsrecord.RecordTypeMX {
+		fields := strings.Fields(rr.Data)
+		if len(fields) != 2 {
+			return r, fmt.Errorf("invalid MX record %s %q: expected preference and target", r.HostName, rr.Data)
+		}
+		pref, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return r, fmt.Errorf("invalid MX record %s %q: %w", r.HostName, rr.Data, err)
+		}
+		r.MXPref = int(pref)
+		r.Address = fields[1]
+	}
+	return r, nil
+}
+
+// toRR converts a record into a libdns resource record
+func toRR(r dnsrecord.Record) libdns.RR {
+	rr := libdns.RR{
+		Name: r.HostName,
+		TTL:  time.Duration(r.TTL) * time.Second,
+		Type: strings.ToUpper(r.RecordType),
+		Data: r.Address,
+	}
+	if rr.Type == dnsrecord.RecordTypeMX {
+		rr.Data = fmt.Sprintf("%d %s", r.MXPref, r.Address)
+	}
+	return rr
+}
+
+// fromRecord converts a record into the libdns struct of its type
+func fromRecord(r dnsrecord.Record) libdns.Record {
+	rr := toRR(r)
+	parsed, err := rr.Parse()
+	if err != nil {
+		return rr
+	}
+	return parsed
+}
+
+func keyOf(r dnsrecord.Record) [2]string {
+	return [2]string{strings.ToLower(r.HostName), strings.ToUpper(r.RecordType)}
+}
+
+// contains reports whether records hold r, ignoring IDs and TTLs
+func contains(records []dnsrecord.Record, r dnsrecord.Record) bool {
+	for _, existing := range records {
+		if keyOf(existing) == keyOf(r) && existing.Address == r.Address && existing.MXPref == r.MXPref {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether r is described by one of recs
+func matchesAny(r dnsrecord.Record, recs []libdns.Record) bool {
+	rr := toRR(r)
+	for _, rec := range recs {
+		target := rec.RR()
+		name := target.Name
+		if name == "" {
+			name = "@"
+		}
+		if !strings.EqualFold(name, rr.Name) {
+			continue
+		}
+		if (target.Type == "" || strings.EqualFold(target.Type, rr.Type)) &&
+			(target.TTL == 0 || target.TTL/time.Second == rr.TTL/time.Second) &&
+			(target.Data == "" || target.Data == rr.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure Provider implements the libdns interfaces
+var (
+	_ libdns.RecordGetter   = (*Provider)(nil)
+	_ libdns.RecordAppender = (*Provider)(nil)
+	_ libdns.RecordSetter   = (*Provider)(nil)
+	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
+)
@@ -0,0 +1,127 @@
+package libdnsbridge
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider keeps one zone in memory and counts writes
+type fakeProvider struct {
+	records []dnsrecord.Record
+	writes  int
+}
+
+func (f *fakeProvider) Name() string    { return "fake" }
+func (f *fakeProvider) Validate() error { return nil }
+
+func (f *fakeProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	return append([]dnsrecord.Record(nil), f.records...), nil
+}
+
+func (f *fakeProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	f.writes++
+	result := dnsprovider.PlanResults(domainName, f.records, records)
+	f.records = records
+	return result, nil
+}
+
+func newTestProvider() (*Provider, *fakeProvider) {
+	fake := &fakeProvider{records: []dnsrecord.Record{
+		{ID: "1", HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{ID: "2", HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 10, TTL: 3600},
+		{ID: "3", HostName: "_acme-challenge", RecordType: "TXT", Address: "old", TTL: 60},
+	}}
+	return New(fake), fake
+}
+
+func TestProvider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider()
+
+	records, err := p.GetRecords(context.Background(), "example.com.")
+	require.NoError(t, err)
+	require.Equal(t, []libdns.Record{
+		libdns.Address{Name: "@", IP: netip.MustParseAddr("192.0.2.1"), TTL: 5 * time.Minute},
+		libdns.MX{Name: "@", Preference: 10, Target: "mx1.example.net.", TTL: time.Hour},
+		libdns.TXT{Name: "_acme-challenge", Text: "old", TTL: time.Minute},
+	}, records)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.GetRecords(ctx, "example.com.")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProvider_AppendRecords(t *testing.T) {
+	p, fake := newTestProvider()
+
+	added, err := p.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "old"},
+		libdns.TXT{Name: "_acme-challenge", Text: "new", TTL: time.Minute},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []libdns.Record{libdns.TXT{Name: "_acme-challenge", Text: "new", TTL: time.Minute}}, added)
+	require.Len(t, fake.records, 4)
+	require.Equal(t, dnsrecord.Record{HostName: "_acme-challenge", RecordType: "TXT", Address: "new", TTL: 60}, fake.records[3])
+
+	// Appending only existing records writes nothing
+	_, err = p.AppendRecords(context.Background(), "example.com", []libdns.Record{libdns.TXT{Name: "_acme-challenge", Text: "new"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, fake.writes)
+
+	_, err = p.AppendRecords(context.Background(), "example.com", []libdns.Record{libdns.RR{Name: "@", Type: "MX", Data: "mx2.example.net."}})
+	require.ErrorContains(t, err, "invalid MX record")
+}
+
+func TestProvider_SetRecords_ReplacesOnlyGivenRRSets(t *testing.T) {
+	p, fake := newTestProvider()
+
+	_, err := p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "@", IP: netip.MustParseAddr("192.0.2.2"), TTL: 5 * time.Minute},
+		libdns.Address{Name: "", IP: netip.MustParseAddr("192.0.2.3"), TTL: 5 * time.Minute},
+		libdns.MX{Name: "@", Preference: 20, Target: "mx2.example.net.", TTL: time.Hour},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "3", HostName: "_acme-challenge", RecordType: "TXT", Address: "old", TTL: 60},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 300},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.3", TTL: 300},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", MXPref: 20, TTL: 3600},
+	}, fake.records)
+}
+
+func TestProvider_DeleteRecords(t *testing.T) {
+	p, fake := newTestProvider()
+
+	deleted, err := p.DeleteRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.RR{Name: "@", Type: "A"},
+		libdns.TXT{Name: "_acme-challenge", Text: "other"},
+		libdns.RR{Name: "@", Type: "MX", TTL: time.Minute},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []libdns.Record{
+		libdns.Address{Name: "@", IP: netip.MustParseAddr("192.0.2.1"), TTL: 5 * time.Minute},
+	}, deleted)
+
+	// The name must match; an empty type and value match any
+	deleted, err = p.DeleteRecords(context.Background(), "example.com.", []libdns.Record{libdns.RR{Name: "_acme-challenge"}})
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "2", HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 10, TTL: 3600},
+	}, fake.records)
+}
+
+func TestProvider_ListZones_RequiresZoneLister(t *testing.T) {
+	p, _ := newTestProvider()
+
+	_, err := p.ListZones(context.Background())
+	require.ErrorContains(t, err, "cannot list zones")
+}
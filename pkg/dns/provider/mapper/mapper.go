@@ -3,6 +3,7 @@ package mapper
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"zonekit/pkg/dnsrecord"
@@ -23,6 +24,10 @@ type FieldMapping struct {
 	TTL        string
 	MXPref     string
 	ID         string
+	// Comment and Tags are the provider's fields for record annotations; empty
+	// when the provider has none
+	Comment string
+	Tags    string
 }
 
 // DefaultMappings returns default mappings (no transformation needed)
@@ -70,6 +75,12 @@ func ToProviderFormat(record dnsrecord.Record, mapping FieldMapping) map[string]
 	if mapping.ID != "" && record.ID != "" {
 		result[mapping.ID] = record.ID
 	}
+	if mapping.Comment != "" && record.Comment != "" {
+		result[mapping.Comment] = record.Comment
+	}
+	if mapping.Tags != "" && len(record.Tags) > 0 {
+		result[mapping.Tags] = record.Tags
+	}
 
 	return result
 }
@@ -122,18 +133,46 @@ func FromProviderFormat(data map[string]interface{}, mapping FieldMapping) (dnsr
 	if mapping.ID != "" {
 		record.ID = getString(mapping.ID)
 	}
+	// Providers return null for records without a comment
+	if mapping.Comment != "" {
+		if comment, ok := data[mapping.Comment].(string); ok {
+			record.Comment = comment
+		}
+	}
+	if mapping.Tags != "" {
+		if tags, ok := data[mapping.Tags].([]interface{}); ok {
+			for _, tag := range tags {
+				if s, ok := tag.(string); ok && s != "" {
+					record.Tags = append(record.Tags, s)
+				}
+			}
+		}
+	}
 
 	return record, nil
 }
 
-// ExtractRecords extracts records from a JSON response using the list path
+// ExtractRecords extracts records from a JSON response using the list path.
+// It is lenient: a list path missing from the response yields no records, so
+// listing keeps working when an API omits the list for an empty zone.
 func ExtractRecords(data interface{}, listPath string) ([]map[string]interface{}, error) {
+	return extractRecords(data, listPath, false)
+}
+
+// ExtractRecordsStrict is ExtractRecords, except that a list path missing from
+// the response is an error naming the response's top-level keys, which shows
+// mapping typos instead of hiding them behind an empty list
+func ExtractRecordsStrict(data interface{}, listPath string) ([]map[string]interface{}, error) {
+	return extractRecords(data, listPath, true)
+}
+
+func extractRecords(data interface{}, listPath string, strict bool) ([]map[string]interface{}, error) {
 	if listPath == "" {
 		// Default: assume data is an array
 		if arr, ok := data.([]interface{}); ok {
 			return convertArrayToMaps(arr)
 		}
-		return nil, fmt.Errorf("no list path specified and data is not an array")
+		return nil, fmt.Errorf("no list path specified and data is not an array%s", keysHint(data))
 	}
 
 	// Navigate through the path (e.g., "result" or "data.records")
@@ -150,7 +189,10 @@ func ExtractRecords(data interface{}, listPath string) ([]map[string]interface{}
 			key := reflect.ValueOf(part)
 			current = current.MapIndex(key)
 			if !current.IsValid() {
-				return nil, fmt.Errorf("path '%s' not found in response", listPath)
+				if !strict {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("path '%s' not found in response%s", listPath, keysHint(data))
 			}
 		case reflect.Slice, reflect.Array:
 			// If we hit an array/slice, we're done navigating
@@ -165,8 +207,13 @@ func ExtractRecords(data interface{}, listPath string) ([]map[string]interface{}
 		current = current.Elem()
 	}
 
+	// A null list is an empty one
+	if !current.IsValid() && !strict {
+		return nil, nil
+	}
+
 	if current.Kind() != reflect.Slice && current.Kind() != reflect.Array {
-		return nil, fmt.Errorf("path '%s' does not point to an array", listPath)
+		return nil, fmt.Errorf("path '%s' does not point to an array%s", listPath, keysHint(data))
 	}
 
 	arr := make([]interface{}, current.Len())
@@ -177,6 +224,25 @@ func ExtractRecords(data interface{}, listPath string) ([]map[string]interface{}
 	return convertArrayToMaps(arr)
 }
 
+// keysHint describes the top-level keys of a JSON object response for error
+// messages, or returns "" if the response is not an object
+func keysHint(data interface{}) string {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if len(obj) == 0 {
+		return " (response has no keys)"
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf(" (top-level keys: %s)", strings.Join(keys, ", "))
+}
+
 // convertArrayToMaps converts an array of interfaces to array of maps
 func convertArrayToMaps(arr []interface{}) ([]map[string]interface{}, error) {
 	result := make([]map[string]interface{}, 0, len(arr))
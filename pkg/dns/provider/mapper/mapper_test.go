@@ -48,3 +48,60 @@ func TestToProviderFormat_IncludesID(t *testing.T) {
 	require.Equal(t, "abc123", m["id"])
 	require.Equal(t, "www", m["hostname"])
 }
+
+func TestExtractRecords_MissingListPath(t *testing.T) {
+	data := map[string]interface{}{
+		"success": true,
+		"result":  []interface{}{map[string]interface{}{"name": "www"}},
+	}
+
+	records, err := ExtractRecords(data, "result")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	// Lenient mode reads a missing or null list as an empty zone
+	records, err = ExtractRecords(data, "reslt")
+	require.NoError(t, err)
+	require.Empty(t, records)
+	records, err = ExtractRecords(map[string]interface{}{"result": nil}, "result")
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	// Strict mode names the keys the response has
+	_, err = ExtractRecordsStrict(data, "reslt")
+	require.EqualError(t, err, "path 'reslt' not found in response (top-level keys: result, success)")
+	_, err = ExtractRecordsStrict(data, "data.records")
+	require.ErrorContains(t, err, "top-level keys: result, success")
+	_, err = ExtractRecordsStrict(map[string]interface{}{"result": nil}, "result")
+	require.ErrorContains(t, err, "does not point to an array")
+
+	// Both modes reject a path to something other than a list
+	_, err = ExtractRecords(data, "success")
+	require.ErrorContains(t, err, "does not point to an array")
+}
+
+func TestProviderFormat_CommentAndTags(t *testing.T) {
+	mapping := FieldMapping{HostName: "name", RecordType: "type", Address: "content", Comment: "comment", Tags: "tags"}
+
+	rec, err := FromProviderFormat(map[string]interface{}{
+		"name": "www", "type": "A", "content": "192.0.2.1",
+		"comment": "web server", "tags": []interface{}{"owner:web", "env:prod"},
+	}, mapping)
+	require.NoError(t, err)
+	require.Equal(t, "web server", rec.Comment)
+	require.Equal(t, []string{"owner:web", "env:prod"}, rec.Tags)
+
+	// A null comment is no comment
+	rec, err = FromProviderFormat(map[string]interface{}{"name": "www", "comment": nil, "tags": []interface{}{}}, mapping)
+	require.NoError(t, err)
+	require.Empty(t, rec.Comment)
+	require.Empty(t, rec.Tags)
+
+	m := ToProviderFormat(dnsrecord.Record{HostName: "www", Comment: "web server", Tags: []string{"env:prod"}}, mapping)
+	require.Equal(t, "web server", m["comment"])
+	require.Equal(t, []string{"env:prod"}, m["tags"])
+
+	// Providers without comment fields get none
+	m = ToProviderFormat(dnsrecord.Record{HostName: "www", Comment: "web server"}, FieldMapping{HostName: "name"})
+	require.NotContains(t, m, "comment")
+}
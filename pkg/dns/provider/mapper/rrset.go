@@ -0,0 +1,146 @@
+package mapper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// maxTXTString is the longest character-string a TXT value can hold; longer
+// values are written as several strings
+const maxTXTString = 255
+
+// RRSet is the set of values one name and type hold, as providers that manage
+// records as RRsets store them. Values are in zone file presentation format,
+// e.g. "10 mx.example.com." for MX or `"v=spf1 -all"` for TXT.
+type RRSet struct {
+	HostName   string
+	RecordType string
+	TTL        int
+	Values     []string
+}
+
+// Key returns the name and type an RRSet is identified by
+func (s RRSet) Key() RRSetKey {
+	return dnsrecord.RecordSet{HostName: s.HostName, RecordType: s.RecordType}.Key()
+}
+
+// RRSetKey identifies an RRSet
+type RRSetKey = dnsrecord.SetKey
+
+// RRSetKeyOf returns the key of the RRSet a record belongs to
+func RRSetKeyOf(r dnsrecord.Record) RRSetKey {
+	return dnsrecord.SetKeyOf(r)
+}
+
+// SplitRRSet returns one record per value of set, all with the set's TTL
+func SplitRRSet(set RRSet) []dnsrecord.Record {
+	records := make([]dnsrecord.Record, 0, len(set.Values))
+	for _, value := range set.Values {
+		record := dnsrecord.Record{HostName: set.HostName, RecordType: strings.ToUpper(set.RecordType), TTL: set.TTL}
+		record.Address, record.MXPref = ParseRRValue(record.RecordType, value)
+		records = append(records, record)
+	}
+	return records
+}
+
+// MergeRRSets groups records into RRSets by name and type, in the order each
+// set first appears (see dnsrecord.GroupRecordSets). A set takes the first
+// non-zero TTL of its records.
+func MergeRRSets(records []dnsrecord.Record) []RRSet {
+	groups := dnsrecord.GroupRecordSets(records)
+	sets := make([]RRSet, 0, len(groups))
+	for _, group := range groups {
+		set := RRSet{HostName: group.HostName, RecordType: group.RecordType, TTL: group.TTL}
+		for _, r := range group.Records {
+			set.Values = append(set.Values, FormatRRValue(r))
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// ParseRRValue splits a presentation format value into a record address and MX
+// preference: MX values are split into preference and target, TXT strings
+// are unquoted and joined and TLSA, SSHFP, DS and NAPTR values are made
+// canonical
+func ParseRRValue(recordType, value string) (address string, mxPref int) {
+	switch strings.ToUpper(recordType) {
+	case dnsrecord.RecordTypeMX:
+		if pref, target, ok := strings.Cut(strings.TrimSpace(value), " "); ok {
+			if n, err := strconv.Atoi(pref); err == nil {
+				return strings.TrimSpace(target), n
+			}
+		}
+	case dnsrecord.RecordTypeTXT:
+		return unquoteTXT(value), 0
+	}
+	return dnsrecord.CanonicalValue(recordType, value), 0
+}
+
+// FormatRRValue returns the presentation format value of a record: MX values
+// carry their preference and TXT values are quoted, split into strings of at
+// most 255 bytes
+func FormatRRValue(r dnsrecord.Record) string {
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeMX:
+		return fmt.Sprintf("%d %s", r.MXPref, r.Address)
+	case dnsrecord.RecordTypeTXT:
+		return quoteTXT(r.Address)
+	}
+	return r.Address
+}
+
+// quoteTXT quotes a TXT value, escaping quotes and backslashes
+func quoteTXT(value string) string {
+	var parts []string
+	for {
+		chunk := value
+		if len(chunk) > maxTXTString {
+			chunk = chunk[:maxTXTString]
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(chunk)
+		parts = append(parts, `"`+escaped+`"`)
+		value = value[len(chunk):]
+		if value == "" {
+			return strings.Join(parts, " ")
+		}
+	}
+}
+
+// unquoteTXT joins the quoted strings of a TXT value, e.g. `"v=spf1 " "-all"`.
+// Values that are not quoted are returned unchanged.
+func unquoteTXT(value string) string {
+	rest := strings.TrimSpace(value)
+	if !strings.HasPrefix(rest, `"`) {
+		return value
+	}
+
+	var sb strings.Builder
+	for rest != "" {
+		if rest[0] != '"' {
+			return value
+		}
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' && end+1 < len(rest) {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return value
+		}
+		inner := rest[1:end]
+		for i := 0; i < len(inner); i++ {
+			if inner[i] == '\\' && i+1 < len(inner) {
+				i++
+			}
+			sb.WriteByte(inner[i])
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return sb.String()
+}
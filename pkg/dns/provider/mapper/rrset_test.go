@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"strings"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRRSet(t *testing.T) {
+	records := SplitRRSet(RRSet{HostName: "@", RecordType: "mx", TTL: 3600, Values: []string{"10 mx1.example.com.", "20 mx2.example.com."}})
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.com.", MXPref: 10, TTL: 3600},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.com.", MXPref: 20, TTL: 3600},
+	}, records)
+
+	records = SplitRRSet(RRSet{HostName: "@", RecordType: "TXT", Values: []string{`"v=spf1 " "include:\"x\" -all"`}})
+	require.Equal(t, `v=spf1 include:"x" -all`, records[0].Address)
+}
+
+func TestMergeRRSets(t *testing.T) {
+	sets := MergeRRSets([]dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "@", RecordType: "TXT", Address: `say "hi"`, TTL: 300},
+		{HostName: "WWW", RecordType: "a", Address: "192.0.2.2", TTL: 600},
+	})
+	require.Equal(t, []RRSet{
+		{HostName: "www", RecordType: "A", TTL: 600, Values: []string{"192.0.2.1", "192.0.2.2"}},
+		{HostName: "@", RecordType: "TXT", TTL: 300, Values: []string{`"say \"hi\""`}},
+	}, sets)
+}
+
+func TestFormatRRValue_SplitsLongTXT(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	value := FormatRRValue(dnsrecord.Record{RecordType: "TXT", Address: long})
+	require.Equal(t, `"`+strings.Repeat("a", 255)+`" "`+strings.Repeat("a", 45)+`"`, value)
+
+	address, _ := ParseRRValue("TXT", value)
+	require.Equal(t, long, address)
+}
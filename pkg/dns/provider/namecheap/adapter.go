@@ -1,7 +1,10 @@
 package namecheap
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"zonekit/pkg/client"
@@ -11,6 +14,25 @@ import (
 	"zonekit/pkg/pointer"
 )
 
+// listPageSize is the largest page domains.getList returns
+const listPageSize = 100
+
+// maxHostRecords is the most host records Namecheap keeps for a domain. SetHosts
+// replaces the whole zone in a single call, so zones cannot be written in chunks
+// and larger zones are rejected before anything is written.
+const maxHostRecords = 150
+
+// defaultTTL is the TTL Namecheap gives host records written without one
+const defaultTTL = 1800
+
+// recordTypes are the host record types Namecheap's DNS serves. TLSA, SSHFP,
+// NAPTR, PTR and DS records cannot be created.
+var recordTypes = []string{
+	dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeMX,
+	dnsrecord.RecordTypeTXT, dnsrecord.RecordTypeNS, dnsrecord.RecordTypeSRV, dnsrecord.RecordTypeCAA,
+	dnsrecord.RecordTypeURL, dnsrecord.RecordTypeURL301, dnsrecord.RecordTypeFRAME,
+}
+
 // NamecheapProvider implements the DNS Provider interface for Namecheap
 type NamecheapProvider struct {
 	client *client.Client
@@ -28,13 +50,16 @@ func (p *NamecheapProvider) Name() string {
 	return "namecheap"
 }
 
-// GetRecords retrieves all DNS records for a domain
+// GetRecords retrieves all DNS records for a domain. Namecheap has no stable
+// record IDs, so each record gets a synthetic one derived from its content.
 func (p *NamecheapProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
 	nc := p.client.GetNamecheapClient()
 
+	span := p.client.BeginCall("namecheap.domains.dns.getHosts", domainName)
 	resp, err := nc.DomainsDNS.GetHosts(domainName)
+	span.End(err)
 	if err != nil {
-		return nil, errors.NewAPI("GetHosts", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+		return nil, errors.NewAPI("GetHosts", fmt.Sprintf("failed to get DNS records for %s", domainName), client.TranslateError(err))
 	}
 
 	// Safety check for nil response
@@ -51,15 +76,52 @@ func (p *NamecheapProvider) GetRecords(domainName string) ([]dnsrecord.Record, e
 			TTL:        pointer.Int(host.TTL),
 			MXPref:     pointer.Int(host.MXPref),
 		}
+		record.ID = recordID(record)
 		records = append(records, record)
 	}
 
 	return records, nil
 }
 
-// SetRecords sets DNS records for a domain (replaces all existing records)
-func (p *NamecheapProvider) SetRecords(domainName string, records []dnsrecord.Record) error {
+// GetRawRecords returns the domains.dns.getHosts response as Namecheap sent it
+func (p *NamecheapProvider) GetRawRecords(domainName string) (interface{}, error) {
+	parsed, err := namecheap.ParseDomain(domainName)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := p.client.CallRaw("namecheap.domains.dns.getHosts", map[string]string{
+		"SLD": parsed.SLD,
+		"TLD": parsed.TLD,
+	})
+	if err != nil {
+		return nil, errors.NewAPI("GetHosts", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+	return raw, nil
+}
+
+// recordID returns the synthetic ID of a record: a hash of its host name, type
+// and value, which stays the same as long as the record does
+func recordID(record dnsrecord.Record) string {
+	key := strings.ToLower(record.HostName) + "\x00" + strings.ToUpper(record.RecordType) + "\x00" + record.Address
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// The existing records are read first to report the outcome of each record.
+func (p *NamecheapProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	return p.ReplaceRecords(domainName, existing, records)
+}
+
+// ReplaceRecords sets DNS records for a domain whose current records are existing.
+// Namecheap replaces the whole zone in one call, so on failure every changed record is reported as failed.
+func (p *NamecheapProvider) ReplaceRecords(domainName string, existing, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
 	nc := p.client.GetNamecheapClient()
+	result := dnsprovider.PlanResults(domainName, existing, records)
 
 	// Convert records to Namecheap format
 	hostRecords := make([]namecheap.DomainsDNSHostRecord, len(records))
@@ -99,12 +161,65 @@ func (p *NamecheapProvider) SetRecords(domainName string, records []dnsrecord.Re
 		args.EmailType = namecheap.String("MX")
 	}
 
+	span := p.client.BeginCall("namecheap.domains.dns.setHosts", domainName)
 	_, err := nc.DomainsDNS.SetHosts(args)
+	span.End(err)
 	if err != nil {
-		return errors.NewAPI("SetHosts", fmt.Sprintf("failed to set DNS records for %s", domainName), err)
+		apiErr := errors.NewAPI("SetHosts", fmt.Sprintf("failed to set DNS records for %s", domainName), client.TranslateError(err))
+		for i := range result.Records {
+			if result.Records[i].Status != dnsprovider.ApplyUnchanged {
+				result.Fail(i, apiErr)
+			}
+		}
+		return result, apiErr
 	}
 
-	return nil
+	return result, nil
+}
+
+// Capabilities reports the record types, host record limit and default TTL of
+// Namecheap zones, which can also hold URL redirect records
+func (p *NamecheapProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{
+		SupportedRecordTypes: recordTypes,
+		MaxRecords:           maxHostRecords,
+		DefaultTTL:           defaultTTL,
+		Forwarding:           true,
+	}
+}
+
+// ListZones returns the domains in the account that use Namecheap's DNS servers
+func (p *NamecheapProvider) ListZones() ([]string, error) {
+	nc := p.client.GetNamecheapClient()
+
+	var zones []string
+	for page := 1; ; page++ {
+		span := p.client.BeginCall("namecheap.domains.getList", "")
+		resp, err := nc.Domains.GetList(&namecheap.DomainsGetListArgs{
+			ListType: namecheap.String("ALL"),
+			Page:     namecheap.Int(page),
+			PageSize: namecheap.Int(listPageSize),
+		})
+		span.End(err)
+		if err != nil {
+			return nil, errors.NewAPI("GetList", "failed to list domains", client.TranslateError(err))
+		}
+		if resp == nil || resp.Domains == nil {
+			break
+		}
+
+		for _, d := range *resp.Domains {
+			if pointer.Bool(d.IsOurDNS) {
+				zones = append(zones, pointer.String(d.Name))
+			}
+		}
+
+		if len(*resp.Domains) < listPageSize {
+			break
+		}
+	}
+
+	return zones, nil
 }
 
 // Validate checks if the provider is properly configured
@@ -0,0 +1,22 @@
+package namecheap
+
+import (
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordID(t *testing.T) {
+	www := dnsrecord.Record{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800}
+
+	id := recordID(www)
+	require.Len(t, id, 12)
+
+	// The ID depends on the host, type and value only
+	require.Equal(t, id, recordID(dnsrecord.Record{HostName: "WWW", RecordType: "a", Address: "192.0.2.1", TTL: 300}))
+	require.NotEqual(t, id, recordID(dnsrecord.Record{HostName: "www", RecordType: "A", Address: "192.0.2.2"}))
+	require.NotEqual(t, id, recordID(dnsrecord.Record{HostName: "www", RecordType: "AAAA", Address: "192.0.2.1"}))
+	require.NotEqual(t, id, recordID(dnsrecord.Record{HostName: "api", RecordType: "A", Address: "192.0.2.1"}))
+}
@@ -0,0 +1,288 @@
+// Package njalla implements the DNS provider for Njalla.
+//
+// Njalla exposes a JSON-RPC style API: every call is a POST to a single
+// endpoint with a method name and parameters, authenticated with an API token.
+// Records are addressed by their ID and applied one by one.
+package njalla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "njalla"
+
+	// DefaultEndpoint is the Njalla API endpoint
+	DefaultEndpoint = "https://njal.la/api/1/"
+)
+
+// Environment variables read by RegisterFromEnv
+const (
+	EnvToken    = "NJALLA_API_TOKEN"
+	EnvEndpoint = "NJALLA_ENDPOINT"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:     name,
+			Token:    credentials["token"],
+			Endpoint: credentials["endpoint"],
+		})
+	})
+}
+
+// Config holds the Njalla credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name  string
+	Token string
+	// Endpoint overrides DefaultEndpoint
+	Endpoint string
+}
+
+// NjallaProvider implements the DNS Provider interface for Njalla
+type NjallaProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new Njalla provider
+func New(config Config) (*NjallaProvider, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("njalla requires an API token")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &NjallaProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL: endpoint,
+			Headers: map[string]string{
+				"Authorization": "Njalla " + config.Token,
+				"Accept":        "application/json",
+			},
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *NjallaProvider) Name() string {
+	return p.name
+}
+
+// record is a DNS record as returned by list-records
+type record struct {
+	ID      json.Number `json:"id"`
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Content string      `json:"content"`
+	TTL     int         `json:"ttl"`
+	Prio    int         `json:"prio,omitempty"`
+}
+
+// rpcRequest is the body of an API call
+type rpcRequest struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// rpcResponse is the envelope of an API response; exactly one of Result and Error is set
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GetRecords retrieves all DNS records for a domain
+func (p *NjallaProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	var result struct {
+		Records []record `json:"records"`
+	}
+	if err := p.call("list-records", map[string]interface{}{"domain": domainName}, &result); err != nil {
+		return nil, errors.NewAPI("list-records", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	records := make([]dnsrecord.Record, 0, len(result.Records))
+	for _, r := range result.Records {
+		rec := dnsrecord.Record{
+			ID:         r.ID.String(),
+			HostName:   r.Name,
+			RecordType: r.Type,
+			Address:    r.Content,
+			TTL:        r.TTL,
+		}
+		if r.Type == dnsrecord.RecordTypeMX {
+			rec.MXPref = r.Prio
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Updated records keep their ID; a failing record does not stop the others.
+func (p *NjallaProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	for i, r := range result.Records {
+		var err error
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			params := recordParams(r.Record)
+			params["domain"] = domainName
+			err = p.call("add-record", params, nil)
+		case dnsprovider.ApplyUpdated:
+			params := recordParams(r.Record)
+			params["domain"] = domainName
+			params["id"] = recordID(r.Previous.ID)
+			err = p.call("edit-record", params, nil)
+		case dnsprovider.ApplyDeleted:
+			err = p.call("remove-record", map[string]interface{}{
+				"domain": domainName,
+				"id":     recordID(r.Previous.ID),
+			}, nil)
+		default:
+			continue
+		}
+		if err != nil {
+			result.Fail(i, err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// ListZones returns the domains in the Njalla account
+func (p *NjallaProvider) ListZones() ([]string, error) {
+	var result struct {
+		Domains []struct {
+			Name string `json:"name"`
+		} `json:"domains"`
+	}
+	if err := p.call("list-domains", map[string]interface{}{}, &result); err != nil {
+		return nil, errors.NewAPI("list-domains", "failed to list domains", err)
+	}
+
+	zones := make([]string, 0, len(result.Domains))
+	for _, d := range result.Domains {
+		zones = append(zones, d.Name)
+	}
+	return zones, nil
+}
+
+// Validate checks if the provider is properly configured
+func (p *NjallaProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("njalla client is not initialized")
+	}
+	return nil
+}
+
+// recordParams returns the API parameters describing a record
+func recordParams(r dnsrecord.Record) map[string]interface{} {
+	params := map[string]interface{}{
+		"name":    r.HostName,
+		"type":    r.RecordType,
+		"content": r.Address,
+	}
+	if r.TTL > 0 {
+		params["ttl"] = r.TTL
+	}
+	if r.RecordType == dnsrecord.RecordTypeMX && r.MXPref > 0 {
+		params["prio"] = r.MXPref
+	}
+	return params
+}
+
+// recordID sends numeric IDs as numbers, as returned by the API
+func recordID(id string) interface{} {
+	if n, err := strconv.Atoi(id); err == nil {
+		return n
+	}
+	return id
+}
+
+// call invokes an API method and decodes its result into target, if given.
+// Njalla reports errors in the response body with HTTP 200.
+func (p *NjallaProvider) call(method string, params map[string]interface{}, target interface{}) error {
+	resp, err := p.client.Post(context.Background(), "", rpcRequest{Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	var envelope rpcResponse
+	if err := httpprovider.ParseJSONResponse(resp, &envelope); err != nil {
+		return err
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("%s (code %d)", envelope.Error.Message, envelope.Error.Code)
+	}
+
+	if target == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, target); err != nil {
+		return fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Register registers a Njalla provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the Njalla provider when its token is set in the
+// environment. It reports whether the provider was registered;
+// the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		Token:    os.Getenv(EnvToken),
+		Endpoint: os.Getenv(EnvEndpoint),
+	}
+	if config.Token == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure NjallaProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*NjallaProvider)(nil)
+	_ dnsprovider.ZoneLister = (*NjallaProvider)(nil)
+)
@@ -0,0 +1,98 @@
+package njalla
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNjalla serves the record methods of the Njalla API and records every call
+type fakeNjalla struct {
+	calls []rpcRequest
+}
+
+func (f *fakeNjalla) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Njalla secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.calls = append(f.calls, req)
+
+	switch req.Method {
+	case "list-records":
+		if req.Params["domain"] != "example.com" {
+			w.Write([]byte(`{"error":{"code":404,"message":"domain not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":{"records":[
+			{"id":101,"name":"www","type":"A","content":"192.0.2.1","ttl":3600},
+			{"id":102,"name":"@","type":"MX","content":"mx.example.com","ttl":3600,"prio":10}
+		]}}`))
+	default:
+		w.Write([]byte(`{"result":{}}`))
+	}
+}
+
+func TestNjallaProvider_GetRecords(t *testing.T) {
+	server := httptest.NewServer(&fakeNjalla{})
+	defer server.Close()
+
+	p, err := New(Config{Token: "secret-token", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "101", HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{ID: "102", HostName: "@", RecordType: "MX", Address: "mx.example.com", TTL: 3600, MXPref: 10},
+	}, records)
+
+	_, err = p.GetRecords("other.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "domain not found (code 404)")
+}
+
+func TestNjallaProvider_SetRecords(t *testing.T) {
+	fake := &fakeNjalla{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p, err := New(Config{Token: "secret-token", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.9", TTL: 3600},
+		{HostName: "_dmarc", RecordType: "TXT", Address: "v=DMARC1; p=none"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	require.Len(t, fake.calls, 4)
+	require.Equal(t, "edit-record", fake.calls[1].Method)
+	require.Equal(t, float64(101), fake.calls[1].Params["id"])
+	require.Equal(t, "192.0.2.9", fake.calls[1].Params["content"])
+	require.Equal(t, "add-record", fake.calls[2].Method)
+	require.Equal(t, "_dmarc", fake.calls[2].Params["name"])
+	require.NotContains(t, fake.calls[2].Params, "ttl")
+	require.Equal(t, "remove-record", fake.calls[3].Method)
+	require.Equal(t, float64(102), fake.calls[3].Params["id"])
+}
+
+func TestNew_RequiresToken(t *testing.T) {
+	_, err := New(Config{})
+	require.Error(t, err)
+}
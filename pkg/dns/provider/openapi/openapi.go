@@ -138,11 +138,11 @@ func (s *Spec) extractEndpoints() map[string]string {
 			if endpointKey != "" {
 				// Avoid overwriting existing endpoints with single-item paths (prefer list endpoints)
 				if existing, ok := endpoints[endpointKey]; ok && existing != "" {
-					// Prefer the endpoint without path parameters
-					if strings.Contains(existing, "{") && !strings.Contains(path, "{") {
+					// Prefer the endpoint with fewer path parameters, and of equals
+					// the shorter path, so the result does not depend on map order
+					if preferEndpoint(path, existing) {
 						endpoints[endpointKey] = path
 					}
-					// otherwise keep existing
 				} else {
 					endpoints[endpointKey] = path
 				}
@@ -153,12 +153,31 @@ func (s *Spec) extractEndpoints() map[string]string {
 	return endpoints
 }
 
+// preferEndpoint reports whether path is a better match for an endpoint than
+// existing: collection paths are preferred over single-item paths
+func preferEndpoint(path, existing string) bool {
+	pathParams, existingParams := strings.Count(path, "{"), strings.Count(existing, "{")
+	if pathParams != existingParams {
+		return pathParams < existingParams
+	}
+	if len(path) != len(existing) {
+		return len(path) < len(existing)
+	}
+	return path < existing
+}
+
 // mapOperationToEndpoint maps OpenAPI operations to our endpoint keys
 func (s *Spec) mapOperationToEndpoint(method, operationID, path string) string {
 	method = strings.ToLower(method)
 	operationID = strings.ToLower(operationID)
 	path = strings.ToLower(path)
 
+	// A collection of zones or domains lists the zones served by the provider
+	if method == "get" && !strings.Contains(path, "{") &&
+		(strings.HasSuffix(path, "/zones") || strings.HasSuffix(path, "/domains")) {
+		return "list_zones"
+	}
+
 	// Try to infer from operation ID
 	if strings.Contains(operationID, "list") || strings.Contains(operationID, "get") {
 		if strings.Contains(path, "record") || strings.Contains(path, "dns") {
@@ -234,6 +253,13 @@ func (s *Spec) extractAuthentication() (string, map[string]interface{}) {
 				}
 				return "api_key", credentials
 			}
+			if in == "query" {
+				credentials["api_key"] = fmt.Sprintf("${%s_API_KEY}", strings.ToUpper(name))
+				if keyName != "" {
+					credentials["param"] = keyName
+				}
+				return "query", credentials
+			}
 
 		case "http":
 			// HTTP authentication (Bearer, Basic)
@@ -308,6 +334,12 @@ func (s *Spec) extractMappings() *dnsprovider.FieldMappings {
 			case "id", "recordid", "record_id", "_id":
 				mappings.Request.ID = propName
 				mappings.Response.ID = propName
+			case "comment", "comments", "note", "notes":
+				mappings.Request.Comment = propName
+				mappings.Response.Comment = propName
+			case "tags":
+				mappings.Request.Tags = propName
+				mappings.Response.Tags = propName
 			}
 		}
 
@@ -23,6 +23,9 @@ func TestCloudflareSpec_ToProviderConfig(t *testing.T) {
 	require.Contains(t, cfg.API.Endpoints, "delete_record")
 	require.Equal(t, "/zones/{zone_id}/dns_records/{dns_record_id}", cfg.API.Endpoints["delete_record"])
 
+	// The zone collection lists zones
+	require.Equal(t, "/zones", cfg.API.Endpoints["list_zones"])
+
 	// Mappings
 	require.NotNil(t, cfg.Mappings)
 	require.Equal(t, "id", cfg.Mappings.Response.ID)
@@ -30,3 +33,14 @@ func TestCloudflareSpec_ToProviderConfig(t *testing.T) {
 	// List path should be detected
 	require.Equal(t, "result", cfg.Mappings.ListPath)
 }
+
+func TestExtractAuthentication_QueryAPIKey(t *testing.T) {
+	spec := &Spec{Components: &Components{SecuritySchemes: map[string]interface{}{
+		"cloudns": map[string]interface{}{"type": "apiKey", "in": "query", "name": "auth-password"},
+	}}}
+
+	method, credentials := spec.extractAuthentication()
+	require.Equal(t, "query", method)
+	require.Equal(t, "${CLOUDNS_API_KEY}", credentials["api_key"])
+	require.Equal(t, "auth-password", credentials["param"])
+}
@@ -0,0 +1,337 @@
+// Package ovh implements the DNS provider for OVHcloud DNS zones.
+//
+// The OVH API authenticates every request with a timestamped signature over an
+// application key and secret and a consumer key (see auth.OVHSigner). Records
+// are addressed by numeric ID and written one by one; changes only go live
+// once the zone is refreshed, which SetRecords does after writing.
+package ovh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/ratelimit"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "ovh"
+
+	// DefaultEndpoint is the API endpoint used when none is configured
+	DefaultEndpoint = "ovh-eu"
+
+	// defaultTTL is the TTL OVH zones give records written without one
+	defaultTTL = 3600
+)
+
+// Endpoints maps the names of OVH's API regions to their URLs
+var Endpoints = map[string]string{
+	"ovh-eu":        "https://eu.api.ovh.com/1.0",
+	"ovh-ca":        "https://ca.api.ovh.com/1.0",
+	"ovh-us":        "https://api.us.ovhcloud.com/1.0",
+	"kimsufi-eu":    "https://eu.api.kimsufi.com/1.0",
+	"kimsufi-ca":    "https://ca.api.kimsufi.com/1.0",
+	"soyoustart-eu": "https://eu.api.soyoustart.com/1.0",
+	"soyoustart-ca": "https://ca.api.soyoustart.com/1.0",
+}
+
+// Environment variables read by RegisterFromEnv; the names match the OVH SDKs and lego
+const (
+	EnvEndpoint          = "OVH_ENDPOINT"
+	EnvApplicationKey    = "OVH_APPLICATION_KEY"
+	EnvApplicationSecret = "OVH_APPLICATION_SECRET"
+	EnvConsumerKey       = "OVH_CONSUMER_KEY"
+)
+
+func init() {
+	_ = auth.RegisterSigner(Name, auth.NewOVHSigner)
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:              name,
+			Endpoint:          credentials["endpoint"],
+			ApplicationKey:    credentials["application_key"],
+			ApplicationSecret: credentials["application_secret"],
+			ConsumerKey:       credentials["consumer_key"],
+		})
+	})
+}
+
+// Config holds the OVH credentials
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name string
+	// Endpoint is a region name from Endpoints or an API URL; it defaults to DefaultEndpoint
+	Endpoint          string
+	ApplicationKey    string
+	ApplicationSecret string
+	ConsumerKey       string
+}
+
+// OVHProvider implements the DNS Provider interface for OVH
+type OVHProvider struct {
+	name   string
+	client *httpprovider.Client
+}
+
+// New creates a new OVH provider
+func New(config Config) (*OVHProvider, error) {
+	signer, err := auth.NewSigner(Name, auth.Credentials{
+		"application_key":    config.ApplicationKey,
+		"application_secret": config.ApplicationSecret,
+		"consumer_key":       config.ConsumerKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := resolveEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &OVHProvider{
+		name: name,
+		client: httpprovider.NewClient(httpprovider.ClientConfig{
+			BaseURL:     endpoint,
+			Headers:     map[string]string{"Accept": "application/json"},
+			Signer:      signer,
+			RateLimiter: ratelimit.For(name, Name),
+		}),
+	}, nil
+}
+
+// resolveEndpoint returns the API URL of a region name or URL
+func resolveEndpoint(endpoint string) (string, error) {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if u, ok := Endpoints[strings.ToLower(endpoint)]; ok {
+		return u, nil
+	}
+	if strings.HasPrefix(endpoint, "https://") || strings.HasPrefix(endpoint, "http://") {
+		return strings.TrimSuffix(endpoint, "/"), nil
+	}
+	return "", fmt.Errorf("unknown ovh endpoint %q (use ovh-eu, ovh-ca, ovh-us or an API URL)", endpoint)
+}
+
+// Name returns the provider name
+func (p *OVHProvider) Name() string {
+	return p.name
+}
+
+// record is a zone record as returned by the OVH API
+type record struct {
+	ID        int64  `json:"id,omitempty"`
+	FieldType string `json:"fieldType,omitempty"`
+	SubDomain string `json:"subDomain"`
+	Target    string `json:"target"`
+	TTL       int    `json:"ttl"`
+}
+
+// GetRecords retrieves all DNS records for a domain. OVH lists record IDs, so
+// each record is fetched on its own.
+func (p *OVHProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	resp, err := p.client.Get(context.Background(), recordsPath(domainName), nil)
+	if err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	var ids []int64
+	if err := httpprovider.ParseJSONResponse(resp, &ids); err != nil {
+		return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS records for %s", domainName), err)
+	}
+
+	records := make([]dnsrecord.Record, 0, len(ids))
+	for _, id := range ids {
+		resp, err := p.client.Get(context.Background(), recordPath(domainName, strconv.FormatInt(id, 10)), nil)
+		if err != nil {
+			return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS record %d of %s", id, domainName), err)
+		}
+		var r record
+		if err := httpprovider.ParseJSONResponse(resp, &r); err != nil {
+			return nil, errors.NewAPI("GetRecords", fmt.Sprintf("failed to get DNS record %d of %s", id, domainName), err)
+		}
+		records = append(records, toRecord(r))
+	}
+	return records, nil
+}
+
+// toRecord converts an OVH record into a record
+func toRecord(r record) dnsrecord.Record {
+	rec := dnsrecord.Record{
+		ID:         strconv.FormatInt(r.ID, 10),
+		HostName:   r.SubDomain,
+		RecordType: r.FieldType,
+		Address:    r.Target,
+		TTL:        r.TTL,
+	}
+	if rec.HostName == "" {
+		rec.HostName = "@"
+	}
+
+	switch r.FieldType {
+	case dnsrecord.RecordTypeMX:
+		if pref, target, ok := strings.Cut(r.Target, " "); ok {
+			if n, err := strconv.Atoi(pref); err == nil {
+				rec.MXPref = n
+				rec.Address = strings.TrimSpace(target)
+			}
+		}
+	case dnsrecord.RecordTypeTXT:
+		if unquoted, err := strconv.Unquote(r.Target); err == nil {
+			rec.Address = unquoted
+		}
+	}
+	return rec
+}
+
+// fromRecord converts a record into an OVH record
+func fromRecord(r dnsrecord.Record) record {
+	out := record{
+		FieldType: strings.ToUpper(r.RecordType),
+		SubDomain: r.HostName,
+		Target:    r.Address,
+		TTL:       r.TTL,
+	}
+	if out.SubDomain == "@" {
+		out.SubDomain = ""
+	}
+	if out.FieldType == dnsrecord.RecordTypeMX {
+		out.Target = fmt.Sprintf("%d %s", r.MXPref, r.Address)
+	}
+	return out
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Updated records keep their ID; a failing record does not stop the others.
+// The zone is refreshed afterwards so the changes are published.
+func (p *OVHProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	written := false
+	for i, r := range result.Records {
+		var err error
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			err = discardBody(p.client.Post(context.Background(), recordsPath(domainName), fromRecord(r.Record)))
+		case dnsprovider.ApplyUpdated:
+			// Updates pair records of the same type; OVH rejects a fieldType in PUT
+			update := fromRecord(r.Record)
+			update.FieldType = ""
+			err = discardBody(p.client.Put(context.Background(), recordPath(domainName, r.Previous.ID), update))
+		case dnsprovider.ApplyDeleted:
+			err = discardBody(p.client.Delete(context.Background(), recordPath(domainName, r.Previous.ID)))
+		default:
+			continue
+		}
+		if err != nil {
+			result.Fail(i, err)
+			continue
+		}
+		written = true
+	}
+
+	if written {
+		if err := discardBody(p.client.Post(context.Background(), "/domain/zone/"+url.PathEscape(domainName)+"/refresh", nil)); err != nil {
+			return result, errors.NewAPI("SetRecords", fmt.Sprintf("records were written but refreshing zone %s failed", domainName), err)
+		}
+	}
+
+	return result, result.Err()
+}
+
+// discardBody closes the body of a response whose content is not needed
+func discardBody(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListZones returns the DNS zones of the OVH account
+func (p *OVHProvider) ListZones() ([]string, error) {
+	resp, err := p.client.Get(context.Background(), "/domain/zone", nil)
+	if err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list zones", err)
+	}
+
+	zones := []string{}
+	if err := httpprovider.ParseJSONResponse(resp, &zones); err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list zones", err)
+	}
+	return zones, nil
+}
+
+// Capabilities reports the default TTL of OVH records
+func (p *OVHProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{DefaultTTL: defaultTTL}
+}
+
+// Validate checks if the provider is properly configured
+func (p *OVHProvider) Validate() error {
+	if p.client == nil {
+		return fmt.Errorf("ovh client is not initialized")
+	}
+	return nil
+}
+
+func recordsPath(domainName string) string {
+	return "/domain/zone/" + url.PathEscape(domainName) + "/record"
+}
+
+func recordPath(domainName, id string) string {
+	return recordsPath(domainName) + "/" + url.PathEscape(id)
+}
+
+// Register registers an OVH provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the OVH provider when its credentials are set in
+// the environment. It reports whether the provider was registered;
+// the provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		Endpoint:          os.Getenv(EnvEndpoint),
+		ApplicationKey:    os.Getenv(EnvApplicationKey),
+		ApplicationSecret: os.Getenv(EnvApplicationSecret),
+		ConsumerKey:       os.Getenv(EnvConsumerKey),
+	}
+	if config.ApplicationKey == "" || config.ApplicationSecret == "" || config.ConsumerKey == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure OVHProvider implements Provider and ZoneLister interfaces
+var (
+	_ dnsprovider.Provider   = (*OVHProvider)(nil)
+	_ dnsprovider.ZoneLister = (*OVHProvider)(nil)
+)
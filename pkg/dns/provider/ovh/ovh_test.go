@@ -0,0 +1,163 @@
+package ovh
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/auth"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAppKey      = "app-key"
+	testAppSecret   = "app-secret"
+	testConsumerKey = "consumer-key"
+)
+
+// fakeCall is a write received by fakeOVH
+type fakeCall struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// fakeOVH serves the zone endpoints for example.com, checks the signature of
+// every request and records every write
+type fakeOVH struct {
+	server *httptest.Server
+	calls  []fakeCall
+}
+
+func (f *fakeOVH) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	signature := auth.OVHSignature(testAppSecret, testConsumerKey, r.Method, f.server.URL+r.URL.RequestURI(), string(body), r.Header.Get("X-Ovh-Timestamp"))
+	if r.Header.Get("X-Ovh-Application") != testAppKey || r.Header.Get("X-Ovh-Consumer") != testConsumerKey || r.Header.Get("X-Ovh-Signature") != signature {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Invalid signature"}`))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone":
+		w.Write([]byte(`["example.com","example.org"]`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone/example.com/record":
+		w.Write([]byte(`[1,2,3,4]`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone/example.com/record/1":
+		w.Write([]byte(`{"id":1,"zone":"example.com","subDomain":"","fieldType":"A","target":"192.0.2.1","ttl":0}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone/example.com/record/2":
+		w.Write([]byte(`{"id":2,"zone":"example.com","subDomain":"","fieldType":"MX","target":"10 mx1.example.net.","ttl":3600}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone/example.com/record/3":
+		w.Write([]byte(`{"id":3,"zone":"example.com","subDomain":"","fieldType":"TXT","target":"\"v=spf1 -all\"","ttl":3600}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/domain/zone/example.com/record/4":
+		w.Write([]byte(`{"id":4,"zone":"example.com","subDomain":"old","fieldType":"CNAME","target":"example.com.","ttl":300}`))
+	case r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"This service does not exist"}`))
+	default:
+		call := fakeCall{Method: r.Method, Path: r.URL.Path}
+		if len(body) > 0 {
+			json.Unmarshal(body, &call.Body)
+		}
+		f.calls = append(f.calls, call)
+		w.Write([]byte(`null`))
+	}
+}
+
+func newTestProvider(t *testing.T) (*OVHProvider, *fakeOVH) {
+	fake := &fakeOVH{}
+	fake.server = httptest.NewServer(fake)
+	t.Cleanup(fake.server.Close)
+
+	p, err := New(Config{
+		Endpoint:          fake.server.URL,
+		ApplicationKey:    testAppKey,
+		ApplicationSecret: testAppSecret,
+		ConsumerKey:       testConsumerKey,
+	})
+	require.NoError(t, err)
+	return p, fake
+}
+
+func TestOVHSignature(t *testing.T) {
+	require.Equal(t, "$1$eba73e191a24380e0d8f7ab822e7166c788b2dec", auth.OVHSignature(
+		"EXEgWIz07P0HYwtQDs7cNIqCiQaWSuHF", "MtSwSrPpNjqfVSmJhLbPyr2i45lSwPU1",
+		"GET", "https://eu.api.ovh.com/1.0/domain/zone", "", "1366560945"))
+}
+
+func TestOVHProvider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "1", HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{ID: "2", HostName: "@", RecordType: "MX", Address: "mx1.example.net.", TTL: 3600, MXPref: 10},
+		{ID: "3", HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 3600},
+		{ID: "4", HostName: "old", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+	}, records)
+
+	_, err = p.GetRecords("missing.com")
+	require.Error(t, err)
+}
+
+func TestOVHProvider_SetRecords(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", TTL: 3600, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 3600},
+		{HostName: "www", RecordType: "A", Address: "192.0.2.2", TTL: 600},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	require.Equal(t, []fakeCall{
+		{Method: http.MethodPut, Path: "/domain/zone/example.com/record/2", Body: map[string]interface{}{
+			"subDomain": "", "target": "20 mx2.example.net.", "ttl": float64(3600),
+		}},
+		{Method: http.MethodPost, Path: "/domain/zone/example.com/record", Body: map[string]interface{}{
+			"fieldType": "A", "subDomain": "www", "target": "192.0.2.2", "ttl": float64(600),
+		}},
+		{Method: http.MethodDelete, Path: "/domain/zone/example.com/record/4"},
+		{Method: http.MethodPost, Path: "/domain/zone/example.com/refresh"},
+	}, fake.calls)
+}
+
+func TestOVHProvider_SetRecords_NoChangesSkipsRefresh(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	existing, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	_, err = p.SetRecords("example.com", existing)
+	require.NoError(t, err)
+	require.Empty(t, fake.calls)
+}
+
+func TestOVHProvider_ListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org"}, zones)
+}
+
+func TestNew_RequiresCredentialsAndKnownEndpoint(t *testing.T) {
+	_, err := New(Config{ApplicationKey: testAppKey, ApplicationSecret: testAppSecret})
+	require.ErrorContains(t, err, "consumer_key")
+
+	_, err = New(Config{Endpoint: "ovh-mars", ApplicationKey: testAppKey, ApplicationSecret: testAppSecret, ConsumerKey: testConsumerKey})
+	require.ErrorContains(t, err, "unknown ovh endpoint")
+
+	endpoint, err := resolveEndpoint("OVH-CA")
+	require.NoError(t, err)
+	require.Equal(t, "https://ca.api.ovh.com/1.0", endpoint)
+}
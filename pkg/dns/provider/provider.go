@@ -12,13 +12,54 @@ type Provider interface {
 	// GetRecords retrieves all DNS records for a domain
 	GetRecords(domainName string) ([]dnsrecord.Record, error)
 
-	// SetRecords sets DNS records for a domain (replaces all existing records)
-	SetRecords(domainName string, records []dnsrecord.Record) error
+	// SetRecords sets DNS records for a domain (replaces all existing records).
+	// The result reports what happened to each record; the error is non-nil if
+	// the operation failed as a whole or any record could not be applied.
+	SetRecords(domainName string, records []dnsrecord.Record) (*ApplyResult, error)
 
 	// Validate checks if the provider is properly configured
 	Validate() error
 }
 
+// SerialProvider is implemented by providers that expose the zone's SOA serial
+// (e.g. PowerDNS, RFC2136 or AXFR-capable servers). Providers without it are
+// versioned by a locally computed hash of their records.
+type SerialProvider interface {
+	// ZoneSerial returns the current SOA serial of the zone
+	ZoneSerial(domainName string) (uint32, error)
+}
+
+// ZoneReplacer is implemented by providers that replace a whole zone in one call
+// and read its records only to report what happened to each of them. Callers
+// that already hold the zone's records pass them in, saving that read.
+type ZoneReplacer interface {
+	// ReplaceRecords replaces the records of a zone whose current records are
+	// existing, and reports the outcome as SetRecords does
+	ReplaceRecords(domainName string, existing, records []dnsrecord.Record) (*ApplyResult, error)
+}
+
+// ZoneLister is implemented by providers that can list the zones they serve
+type ZoneLister interface {
+	// ListZones returns the names of the zones managed at the provider
+	ListZones() ([]string, error)
+}
+
+// RawRecordGetter is implemented by providers that can return the records of a
+// zone as their API sent them, including fields zonekit does not model
+type RawRecordGetter interface {
+	// GetRawRecords returns the provider's response listing the records of
+	// domainName, in a form that can be encoded as JSON
+	GetRawRecords(domainName string) (interface{}, error)
+}
+
+// StrictParser is implemented by providers that read API responses leniently
+// and can be switched to strict parsing, which reports mapping mistakes such as
+// a wrong list path instead of returning an empty zone
+type StrictParser interface {
+	// SetStrict turns strict parsing on or off
+	SetStrict(strict bool)
+}
+
 // Config represents provider-specific configuration
 type Config struct {
 	// Provider name (e.g., "namecheap", "cloudflare")
@@ -30,9 +71,13 @@ type Config struct {
 	// Provider type determines which adapter to use
 	Type string `yaml:"type"` // "namecheap", "cloudflare", "godaddy", "rest", etc.
 
+	// Base is the provider a named instance was created from, e.g. "cloudflare"
+	// for cloudflare-work; empty for the provider itself
+	Base string `yaml:"-"`
+
 	// Authentication configuration
 	Auth struct {
-		Method string `yaml:"method"` // "api_key", "oauth", "basic", etc.
+		Method string `yaml:"method"` // "api_key", "oauth", "basic", "signed", etc.
 		// Fields vary by method - stored as map for flexibility
 		Credentials map[string]interface{} `yaml:"credentials"`
 	} `yaml:"auth"`
@@ -63,6 +108,8 @@ type FieldMappings struct {
 		TTL        string `yaml:"ttl,omitempty"`
 		MXPref     string `yaml:"mx_pref,omitempty"` // e.g., "priority" or "preference"
 		ID         string `yaml:"id,omitempty"`      // provider record ID field
+		Comment    string `yaml:"comment,omitempty"` // record comment field, if the provider has one
+		Tags       string `yaml:"tags,omitempty"`    // record tags field (a list of strings), if any
 	} `yaml:"request,omitempty"`
 
 	// Response mappings (provider format -> our format)
@@ -73,6 +120,8 @@ type FieldMappings struct {
 		TTL        string `yaml:"ttl,omitempty"`
 		MXPref     string `yaml:"mx_pref,omitempty"`
 		ID         string `yaml:"id,omitempty"` // provider record ID field
+		Comment    string `yaml:"comment,omitempty"`
+		Tags       string `yaml:"tags,omitempty"`
 	} `yaml:"response,omitempty"`
 
 	// List response structure (for REST providers)
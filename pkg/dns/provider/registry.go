@@ -5,17 +5,56 @@ import (
 	"sync"
 )
 
+// Constructor builds a provider the first time it is used
+type Constructor func() (Provider, error)
+
+// registryEntry holds a provider, or the constructor that builds it on first use
+type registryEntry struct {
+	provider  Provider
+	construct Constructor
+	err       error
+	once      sync.Once
+}
+
+// get returns the provider, constructing it on first use
+func (e *registryEntry) get() (Provider, error) {
+	e.once.Do(func() {
+		if e.construct != nil {
+			e.provider, e.err = e.construct()
+		}
+	})
+	return e.provider, e.err
+}
+
 var (
-	registry     = make(map[string]Provider)
+	registry     = make(map[string]*registryEntry)
 	registryLock sync.RWMutex
 )
 
-// Register registers a DNS provider
+// Register registers a DNS provider under its own name
 func Register(provider Provider) error {
+	return RegisterAs(provider.Name(), provider)
+}
+
+// RegisterAs registers a DNS provider under the given name. Several instances of
+// the same provider type can be registered under different names, e.g.
+// "cloudflare-personal" and "cloudflare-work" with different credentials.
+func RegisterAs(name string, provider Provider) error {
+	return register(name, &registryEntry{provider: provider})
+}
+
+// RegisterLazy registers a provider that is constructed when it is first
+// retrieved, so that commands which never use it do not pay for loading its
+// configuration or resolving its credentials
+func RegisterLazy(name string, construct Constructor) error {
+	return register(name, &registryEntry{construct: construct})
+}
+
+// register adds an entry under name
+func register(name string, entry *registryEntry) error {
 	registryLock.Lock()
 	defer registryLock.Unlock()
 
-	name := provider.Name()
 	if name == "" {
 		return fmt.Errorf("provider name cannot be empty")
 	}
@@ -24,37 +63,49 @@ func Register(provider Provider) error {
 		return fmt.Errorf("provider %s is already registered", name)
 	}
 
-	registry[name] = provider
+	registry[name] = entry
 	return nil
 }
 
-// Get retrieves a provider by name
+// Get retrieves a provider by name, constructing it if it was registered lazily
 func Get(name string) (Provider, error) {
 	registryLock.RLock()
-	defer registryLock.RUnlock()
+	entry, exists := registry[name]
+	registryLock.RUnlock()
 
-	provider, exists := registry[name]
 	if !exists {
 		return nil, fmt.Errorf("DNS provider %s not found", name)
 	}
 
+	provider, err := entry.get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DNS provider %s: %w", name, err)
+	}
+
 	return provider, nil
 }
 
-// List returns all registered providers
+// List returns all registered providers, constructing lazily registered ones.
+// Providers that fail to initialize are left out.
 func List() []Provider {
 	registryLock.RLock()
-	defer registryLock.RUnlock()
+	entries := make([]*registryEntry, 0, len(registry))
+	for _, entry := range registry {
+		entries = append(entries, entry)
+	}
+	registryLock.RUnlock()
 
-	providers := make([]Provider, 0, len(registry))
-	for _, provider := range registry {
-		providers = append(providers, provider)
+	providers := make([]Provider, 0, len(entries))
+	for _, entry := range entries {
+		if provider, err := entry.get(); err == nil {
+			providers = append(providers, provider)
+		}
 	}
 
 	return providers
 }
 
-// Names returns the names of all registered providers
+// Names returns the names of all registered providers without constructing them
 func Names() []string {
 	registryLock.RLock()
 	defer registryLock.RUnlock()
@@ -80,5 +131,5 @@ func Clear() {
 	registryLock.Lock()
 	defer registryLock.Unlock()
 
-	registry = make(map[string]Provider)
+	registry = make(map[string]*registryEntry)
 }
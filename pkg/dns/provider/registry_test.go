@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -34,12 +35,13 @@ func (m *mockProviderForRegistry) GetRecords(domainName string) ([]dnsrecord.Rec
 	return m.records[domainName], nil
 }
 
-func (m *mockProviderForRegistry) SetRecords(domainName string, records []dnsrecord.Record) error {
+func (m *mockProviderForRegistry) SetRecords(domainName string, records []dnsrecord.Record) (*ApplyResult, error) {
 	if m.setRecordsError != nil {
-		return m.setRecordsError
+		return nil, m.setRecordsError
 	}
+	result := PlanResults(domainName, m.records[domainName], records)
 	m.records[domainName] = records
-	return nil
+	return result, nil
 }
 
 func (m *mockProviderForRegistry) Validate() error {
@@ -227,3 +229,76 @@ func (s *RegistryTestSuite) TestConcurrentAccess() {
 	providers := List()
 	s.Require().Len(providers, 2)
 }
+
+func (s *RegistryTestSuite) TestRegisterAs_MultipleInstances() {
+	personal := newMockProviderForRegistry("cloudflare-personal")
+	work := newMockProviderForRegistry("cloudflare-work")
+
+	s.Require().NoError(RegisterAs("cloudflare-personal", personal))
+	s.Require().NoError(RegisterAs("cloudflare-work", work))
+
+	retrieved, err := Get("cloudflare-work")
+	s.Require().NoError(err)
+	s.Require().Same(work, retrieved)
+
+	err = RegisterAs("cloudflare-work", personal)
+	s.Require().ErrorContains(err, "already registered")
+}
+
+func (s *RegistryTestSuite) TestNewInstance() {
+	s.Require().NoError(RegisterFactory("mock", func(name string, credentials map[string]string) (Provider, error) {
+		if credentials["token"] == "" {
+			return nil, errors.New("token is required")
+		}
+		return newMockProviderForRegistry(name), nil
+	}))
+	defer UnregisterFactory("mock")
+
+	s.Require().Contains(Types(), "mock")
+	s.Require().Error(RegisterFactory("mock", nil))
+
+	p, err := NewInstance("mock-work", "mock", map[string]string{"token": "t"})
+	s.Require().NoError(err)
+	s.Require().Equal("mock-work", p.Name())
+
+	_, err = NewInstance("mock-empty", "mock", nil)
+	s.Require().ErrorContains(err, "token is required")
+
+	_, err = NewInstance("other", "unknown", nil)
+	s.Require().ErrorContains(err, "unknown provider type")
+}
+
+func (s *RegistryTestSuite) TestRegisterLazy() {
+	calls := 0
+	s.Require().NoError(RegisterLazy("lazy", func() (Provider, error) {
+		calls++
+		return newMockProviderForRegistry("lazy"), nil
+	}))
+
+	s.Require().Contains(Names(), "lazy")
+	s.Require().Equal(0, calls, "provider should not be constructed on registration")
+
+	first, err := Get("lazy")
+	s.Require().NoError(err)
+	second, err := Get("lazy")
+	s.Require().NoError(err)
+	s.Require().Same(first, second)
+	s.Require().Equal(1, calls)
+
+	s.Require().ErrorContains(RegisterLazy("lazy", nil), "already registered")
+}
+
+func (s *RegistryTestSuite) TestRegisterLazy_ConstructorError() {
+	s.Require().NoError(RegisterLazy("broken", func() (Provider, error) {
+		return nil, errors.New("missing credentials")
+	}))
+	s.Require().NoError(Register(newMockProviderForRegistry("working")))
+
+	_, err := Get("broken")
+	s.Require().ErrorContains(err, "failed to initialize DNS provider broken")
+	s.Require().ErrorContains(err, "missing credentials")
+
+	providers := List()
+	s.Require().Len(providers, 1)
+	s.Require().Equal("working", providers[0].Name())
+}
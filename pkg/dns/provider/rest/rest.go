@@ -19,6 +19,7 @@ type RESTProvider struct {
 	mappings  mapper.Mappings
 	endpoints map[string]string
 	settings  map[string]interface{}
+	strict    bool
 }
 
 // NewRESTProvider creates a new REST-based DNS provider
@@ -43,8 +44,57 @@ func (p *RESTProvider) Name() string {
 	return p.name
 }
 
+// SetStrict switches record extraction between strict and lenient mode. In
+// lenient mode, the default, a response without the configured list path is
+// read as an empty zone.
+func (p *RESTProvider) SetStrict(strict bool) {
+	p.strict = strict
+}
+
 // GetRecords retrieves all DNS records for a domain
 func (p *RESTProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	return p.getRecords(domainName, p.strict)
+}
+
+// getRecords retrieves all DNS records for a domain, extracting them from the
+// response strictly or leniently
+func (p *RESTProvider) getRecords(domainName string, strict bool) ([]dnsrecord.Record, error) {
+	responseData, err := p.fetchRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract records using list path
+	extract := mapper.ExtractRecords
+	if strict {
+		extract = mapper.ExtractRecordsStrict
+	}
+	recordMaps, err := extract(responseData, p.mappings.ListPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract records: %w", err)
+	}
+
+	// Convert to dnsrecord.Record
+	records := make([]dnsrecord.Record, 0, len(recordMaps))
+	for _, recordMap := range recordMaps {
+		record, err := mapper.FromProviderFormat(recordMap, p.mappings.Response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// GetRawRecords returns the decoded response of the get_records endpoint, with
+// all fields the provider sent
+func (p *RESTProvider) GetRawRecords(domainName string) (interface{}, error) {
+	return p.fetchRecords(domainName)
+}
+
+// fetchRecords calls the get_records endpoint and decodes its JSON response
+func (p *RESTProvider) fetchRecords(domainName string) (interface{}, error) {
 	endpoint, ok := p.endpoints["get_records"]
 	if !ok {
 		return nil, fmt.Errorf("get_records endpoint not configured")
@@ -73,56 +123,45 @@ func (p *RESTProvider) GetRecords(domainName string) ([]dnsrecord.Record, error)
 	if err := httpprovider.ParseJSONResponse(resp, &responseData); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	// Extract records using list path
-	recordMaps, err := mapper.ExtractRecords(responseData, p.mappings.ListPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract records: %w", err)
-	}
-
-	// Convert to dnsrecord.Record
-	records := make([]dnsrecord.Record, 0, len(recordMaps))
-	for _, recordMap := range recordMaps {
-		record, err := mapper.FromProviderFormat(recordMap, p.mappings.Response)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert record: %w", err)
-		}
-		records = append(records, record)
-	}
-
-	return records, nil
+	return responseData, nil
 }
 
-// SetRecords sets DNS records for a domain (replaces all existing records)
-func (p *RESTProvider) SetRecords(domainName string, records []dnsrecord.Record) error {
-	// Most REST APIs don't support bulk replace, so we need to:
-	// 1. Get existing records
-	// 2. Delete all existing records
-	// 3. Create new records
-
-	existingRecords, err := p.GetRecords(domainName)
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// Most REST APIs don't support bulk replace, so records are applied one by one:
+// unchanged records are kept, updated and deleted records are removed, and new or
+// updated records are created. A failing record does not stop the others.
+func (p *RESTProvider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	// Existing records are always read strictly: a zone misread as empty would
+	// plan every record as new and none of the stale ones for deletion
+	existingRecords, err := p.getRecords(domainName, true)
 	if err != nil {
-		return fmt.Errorf("failed to get existing records: %w", err)
+		return nil, fmt.Errorf("failed to get existing records: %w", err)
 	}
 
 	ctx := context.Background()
+	result := dnsprovider.PlanResults(domainName, existingRecords, records)
 
-	// Delete existing records
-	for _, record := range existingRecords {
-		if err := p.deleteRecord(ctx, domainName, record); err != nil {
-			// Log but continue - some records might not exist
+	// Remove deleted records and the old versions of updated records first
+	for i, r := range result.Records {
+		if r.Status != dnsprovider.ApplyDeleted && r.Status != dnsprovider.ApplyUpdated {
 			continue
 		}
+		if err := p.deleteRecord(ctx, domainName, *r.Previous); err != nil {
+			result.Fail(i, err)
+		}
 	}
 
-	// Create new records
-	for _, record := range records {
-		if err := p.createRecord(ctx, domainName, record); err != nil {
-			return fmt.Errorf("failed to create record: %w", err)
+	// Create new records and the new versions of updated records
+	for i, r := range result.Records {
+		if r.Status != dnsprovider.ApplyCreated && r.Status != dnsprovider.ApplyUpdated {
+			continue
+		}
+		if err := p.createRecord(ctx, domainName, r.Record); err != nil {
+			result.Fail(i, err)
 		}
 	}
 
-	return nil
+	return result, result.Err()
 }
 
 // createRecord creates a single DNS record
@@ -185,6 +224,53 @@ func (p *RESTProvider) deleteRecord(ctx context.Context, domainName string, reco
 	return nil
 }
 
+// ListZones returns the names of the zones returned by the list_zones endpoint
+func (p *RESTProvider) ListZones() ([]string, error) {
+	endpoint, ok := p.endpoints["list_zones"]
+	if !ok || endpoint == "" {
+		return nil, fmt.Errorf("list_zones endpoint not configured")
+	}
+
+	resp, err := p.client.Get(context.Background(), endpoint, nil)
+	if err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to list zones", err)
+	}
+
+	var data interface{}
+	if err := httpprovider.ParseJSONResponse(resp, &data); err != nil {
+		return nil, errors.NewAPI("ListZones", "failed to parse zones", err)
+	}
+
+	// Zones are either a top-level array or an array under a top-level key
+	// (e.g. Cloudflare's "result" or DigitalOcean's "domains")
+	var items []interface{}
+	switch v := data.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		for _, value := range v {
+			if arr, ok := value.([]interface{}); ok {
+				items = append(items, arr...)
+			}
+		}
+	}
+
+	zones := make([]string, 0, len(items))
+	for _, item := range items {
+		if name := zoneName(item); name != "" {
+			zones = append(zones, name)
+		}
+	}
+
+	return zones, nil
+}
+
+// Capabilities reports record comments as supported when the mappings name
+// the provider's comment field
+func (p *RESTProvider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{Comments: p.mappings.Request.Comment != "" && p.mappings.Response.Comment != ""}
+}
+
 // Validate checks if the provider is properly configured
 func (p *RESTProvider) Validate() error {
 	if p.client == nil {
@@ -271,6 +357,24 @@ func (p *RESTProvider) getZoneID(domainName string) (string, error) {
 	return "", nil
 }
 
+// zoneNameFields are the fields that commonly hold a zone's name
+var zoneNameFields = []string{"name", "zone", "domain", "zone_name"}
+
+// zoneName returns the name of a zone object, without a trailing dot
+func zoneName(item interface{}) string {
+	obj, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, field := range zoneNameFields {
+		if v, ok := obj[field].(string); ok && v != "" {
+			return strings.TrimSuffix(v, ".")
+		}
+	}
+	return ""
+}
+
 // extractIDForDomain tries to extract an 'id' field from an object if it matches the provided domain name
 func extractIDForDomain(item interface{}, domainName string) string {
 	obj, ok := item.(map[string]interface{})
@@ -279,8 +383,7 @@ func extractIDForDomain(item interface{}, domainName string) string {
 	}
 
 	// Check common name fields
-	nameCandidates := []string{"name", "zone", "domain", "zone_name"}
-	for _, nc := range nameCandidates {
+	for _, nc := range zoneNameFields {
 		if v, ok := obj[nc]; ok {
 			if vs, ok := v.(string); ok && strings.EqualFold(strings.TrimSuffix(vs, "."), domainName) {
 				// Found matching name; extract id
@@ -297,5 +400,10 @@ func extractIDForDomain(item interface{}, domainName string) string {
 	return ""
 }
 
-// Ensure RESTProvider implements Provider interface
-var _ dnsprovider.Provider = (*RESTProvider)(nil)
+// Ensure RESTProvider implements Provider, ZoneLister, StrictParser and RawRecordGetter interfaces
+var (
+	_ dnsprovider.Provider        = (*RESTProvider)(nil)
+	_ dnsprovider.ZoneLister      = (*RESTProvider)(nil)
+	_ dnsprovider.StrictParser    = (*RESTProvider)(nil)
+	_ dnsprovider.RawRecordGetter = (*RESTProvider)(nil)
+)
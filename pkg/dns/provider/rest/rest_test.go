@@ -2,10 +2,12 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	dnsprovider "zonekit/pkg/dns/provider"
 	httpclient "zonekit/pkg/dns/provider/http"
 	"zonekit/pkg/dns/provider/mapper"
 	"zonekit/pkg/dnsrecord"
@@ -41,3 +43,112 @@ func TestDeleteRecord_MissingID_Error(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "requires record_id")
 }
+
+func TestSetRecords_PerRecordResults(t *testing.T) {
+	// Existing zone: www (unchanged), mail (updated), old (deleted)
+	var created []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/records":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"records":[
+				{"id":"1","hostname":"www","record_type":"A","address":"192.0.2.1","ttl":300},
+				{"id":"2","hostname":"mail","record_type":"A","address":"192.0.2.2","ttl":300},
+				{"id":"3","hostname":"old","record_type":"A","address":"192.0.2.3","ttl":300}
+			]}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["hostname"] == "bad" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"error":"invalid record"}`))
+				return
+			}
+			created = append(created, body["hostname"].(string))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := httpclient.NewClient(httpclient.ClientConfig{BaseURL: ts.URL})
+	mappings := mapper.DefaultMappings()
+	mappings.Response.ID = "id"
+	p := NewRESTProvider("test", client, mappings, map[string]string{
+		"get_records":   "/records",
+		"create_record": "/records",
+		"delete_record": "/records/{record_id}",
+	}, nil)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{HostName: "mail", RecordType: "A", Address: "192.0.2.20", TTL: 300},
+		{HostName: "new", RecordType: "A", Address: "192.0.2.4", TTL: 300},
+		{HostName: "bad", RecordType: "A", Address: "192.0.2.5", TTL: 300},
+	})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 of 4 record changes failed")
+	require.NotNil(t, result)
+	require.Equal(t, []string{"mail", "new"}, created)
+
+	require.Equal(t, dnsprovider.ApplyUnchanged, result.Records[0].Status)
+	require.Equal(t, dnsprovider.ApplyUpdated, result.Records[1].Status)
+	require.Equal(t, dnsprovider.ApplyCreated, result.Records[2].Status)
+	require.Equal(t, dnsprovider.ApplyFailed, result.Records[3].Status)
+	require.Equal(t, dnsprovider.ApplyCreated, result.Records[3].Action)
+	require.Equal(t, dnsprovider.ApplyDeleted, result.Records[4].Status)
+	require.Equal(t, "old", result.Records[4].Record.HostName)
+}
+
+func TestGetRecords_MissingListPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result":[{"hostname":"www","record_type":"A","address":"192.0.2.1"}]}`))
+	}))
+	defer ts.Close()
+
+	client := httpclient.NewClient(httpclient.ClientConfig{BaseURL: ts.URL})
+	p := NewRESTProvider("test", client, mapper.DefaultMappings(), map[string]string{
+		"get_records":   "/records",
+		"create_record": "/records",
+	}, map[string]interface{}{"zone_id": "z"})
+
+	// Listing is lenient unless strict mode is on
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	p.SetStrict(true)
+	_, err = p.GetRecords("example.com")
+	require.ErrorContains(t, err, "top-level keys: result")
+	p.SetStrict(false)
+
+	// Writes always read the existing zone strictly
+	_, err = p.SetRecords("example.com", []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}})
+	require.ErrorContains(t, err, "path 'records' not found")
+}
+
+func TestGetRawRecords_KeepsUnmappedFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"records":[{"hostname":"www","record_type":"A","address":"192.0.2.1","proxied":true}]}`))
+	}))
+	defer ts.Close()
+
+	client := httpclient.NewClient(httpclient.ClientConfig{BaseURL: ts.URL})
+	p := NewRESTProvider("test", client, mapper.DefaultMappings(), map[string]string{
+		"get_records": "/records",
+	}, map[string]interface{}{"zone_id": "z"})
+
+	raw, err := p.GetRawRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"hostname": "www", "record_type": "A", "address": "192.0.2.1", "proxied": true},
+		},
+	}, raw)
+}
@@ -51,3 +51,23 @@ func TestGetZoneID_NoMatch_ReturnsEmpty(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "", id)
 }
+
+func TestListZones(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/zones", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"result":[{"id":"z-1","name":"example.com"},{"id":"z-2","name":"example.org."}]}`))
+	}))
+	defer ts.Close()
+
+	client := httpclient.NewClient(httpclient.ClientConfig{BaseURL: ts.URL})
+	p := NewRESTProvider("test", client, mapper.DefaultMappings(), map[string]string{"list_zones": "/zones"}, nil)
+
+	zones, err := p.ListZones()
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org"}, zones)
+
+	p = NewRESTProvider("test", client, mapper.DefaultMappings(), map[string]string{}, nil)
+	_, err = p.ListZones()
+	require.ErrorContains(t, err, "list_zones endpoint not configured")
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// ApplyStatus describes what happened to a single record when records were applied
+type ApplyStatus string
+
+const (
+	ApplyCreated   ApplyStatus = "created"
+	ApplyUpdated   ApplyStatus = "updated"
+	ApplyDeleted   ApplyStatus = "deleted"
+	ApplyUnchanged ApplyStatus = "unchanged"
+	ApplyFailed    ApplyStatus = "failed"
+)
+
+// RecordResult is the outcome of applying a single record
+type RecordResult struct {
+	Record dnsrecord.Record
+	// Previous is the record that was replaced or deleted, if any
+	Previous *dnsrecord.Record
+	Status   ApplyStatus
+	// Action is the change that was attempted when Status is ApplyFailed
+	Action ApplyStatus
+	Err    error
+}
+
+// ApplyResult collects the per-record outcome of SetRecords
+type ApplyResult struct {
+	Domain  string
+	Records []RecordResult
+}
+
+// Count returns the number of records with the given status
+func (r *ApplyResult) Count(status ApplyStatus) int {
+	if r == nil {
+		return 0
+	}
+	n := 0
+	for _, rec := range r.Records {
+		if rec.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the records that could not be applied
+func (r *ApplyResult) Failed() []RecordResult {
+	if r == nil {
+		return nil
+	}
+	var failed []RecordResult
+	for _, rec := range r.Records {
+		if rec.Status == ApplyFailed {
+			failed = append(failed, rec)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing failed records, or nil if all records were applied
+func (r *ApplyResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(failed))
+	for _, f := range failed {
+		reasons = append(reasons, fmt.Sprintf("%s %s %s: %v", f.Action, f.Record.HostName, f.Record.RecordType, f.Err))
+	}
+
+	return fmt.Errorf("%d of %d record changes failed for %s: %s",
+		len(failed), r.changes(), r.Domain, strings.Join(reasons, "; "))
+}
+
+// changes returns the number of records that required a change
+func (r *ApplyResult) changes() int {
+	return len(r.Records) - r.Count(ApplyUnchanged)
+}
+
+// Summary returns a short human-readable summary such as "2 created, 1 updated, 1 failed"
+func (r *ApplyResult) Summary() string {
+	var parts []string
+	for _, status := range []ApplyStatus{ApplyCreated, ApplyUpdated, ApplyDeleted, ApplyUnchanged, ApplyFailed} {
+		if n := r.Count(status); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fail marks result i as failed with err, remembering the attempted action
+func (r *ApplyResult) Fail(i int, err error) {
+	r.Records[i].Action = r.Records[i].Status
+	r.Records[i].Status = ApplyFailed
+	r.Records[i].Err = err
+}
+
+// ChangedSets returns the keys of the record sets holding a record that is
+// created, updated or deleted, sorted by hostname and type. Providers that
+// store records as sets write only these.
+func (r *ApplyResult) ChangedSets() []dnsrecord.SetKey {
+	seen := make(map[dnsrecord.SetKey]bool)
+	var keys []dnsrecord.SetKey
+	for _, rec := range r.Records {
+		key := dnsrecord.SetKeyOf(rec.Record)
+		if rec.Status == ApplyUnchanged || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].HostName != keys[j].HostName {
+			return keys[i].HostName < keys[j].HostName
+		}
+		return keys[i].RecordType < keys[j].RecordType
+	})
+	return keys
+}
+
+// FailSets marks the changed records of the sets matching keep as failed with
+// err, as a set is written as a whole
+func (r *ApplyResult) FailSets(keep func(dnsrecord.SetKey) bool, err error) {
+	for i, rec := range r.Records {
+		if rec.Status != ApplyUnchanged && rec.Status != ApplyFailed && keep(dnsrecord.SetKeyOf(rec.Record)) {
+			r.Fail(i, err)
+		}
+	}
+}
+
+// PlanResults classifies the desired records against the existing ones.
+// Identical records are unchanged, records sharing host and type with an existing
+// record are updates, the rest are created; existing records left over are deleted.
+func PlanResults(domainName string, existing, desired []dnsrecord.Record) *ApplyResult {
+	result := &ApplyResult{Domain: domainName}
+	used := make([]bool, len(existing))
+	planned := make([]*RecordResult, len(desired))
+
+	// Exact matches first, so updates pair with the closest existing record
+	for i, rec := range desired {
+		for j, old := range existing {
+			if !used[j] && sameRecord(old, rec) {
+				used[j] = true
+				prev := old
+				planned[i] = &RecordResult{Record: rec, Previous: &prev, Status: ApplyUnchanged}
+				break
+			}
+		}
+	}
+
+	for i, rec := range desired {
+		if planned[i] != nil {
+			continue
+		}
+		planned[i] = &RecordResult{Record: rec, Status: ApplyCreated}
+		for j, old := range existing {
+			if !used[j] && strings.EqualFold(old.HostName, rec.HostName) && strings.EqualFold(old.RecordType, rec.RecordType) {
+				used[j] = true
+				prev := old
+				planned[i].Previous = &prev
+				planned[i].Status = ApplyUpdated
+				break
+			}
+		}
+	}
+
+	for _, p := range planned {
+		result.Records = append(result.Records, *p)
+	}
+	for j, old := range existing {
+		if !used[j] {
+			prev := old
+			result.Records = append(result.Records, RecordResult{Record: old, Previous: &prev, Status: ApplyDeleted})
+		}
+	}
+
+	return result
+}
+
+// sameRecord reports whether desired record b matches existing record a, ignoring
+// the provider ID. A desired TTL of 0 means the provider default and matches any TTL.
+// A desired record without a comment or tags keeps those of the existing one.
+func sameRecord(a, b dnsrecord.Record) bool {
+	return strings.EqualFold(a.HostName, b.HostName) &&
+		strings.EqualFold(a.RecordType, b.RecordType) &&
+		a.Address == b.Address &&
+		(a.TTL == b.TTL || b.TTL == 0) &&
+		a.MXPref == b.MXPref &&
+		(a.Comment == b.Comment || b.Comment == "") &&
+		(sameTags(a.Tags, b.Tags) || len(b.Tags) == 0)
+}
+
+// sameTags reports whether two records carry the same tags, in any order
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, tag := range b {
+		if !(dnsrecord.Record{Tags: a}).HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// ResultTestSuite is a test suite for per-record apply results
+type ResultTestSuite struct {
+	suite.Suite
+}
+
+// TestResultSuite runs the apply result test suite
+func TestResultSuite(t *testing.T) {
+	suite.Run(t, new(ResultTestSuite))
+}
+
+func (s *ResultTestSuite) TestPlanResults_CommentsAndTags() {
+	existing := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800, Comment: "web", Tags: []string{"a", "b"}},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.2", TTL: 1800, Comment: "api"},
+	}
+	desired := []dnsrecord.Record{
+		// Without a comment or tags the existing ones are kept
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800, Tags: []string{"B", "a"}},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.2", TTL: 1800, Comment: "public api"},
+	}
+
+	result := PlanResults("example.com", existing, desired)
+
+	s.Require().Equal(ApplyUnchanged, result.Records[0].Status)
+	s.Require().Equal(ApplyUpdated, result.Records[1].Status)
+}
+
+func (s *ResultTestSuite) TestPlanResults() {
+	existing := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 1800},
+		{HostName: "old", RecordType: "TXT", Address: "v=1", TTL: 1800},
+	}
+	desired := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "www", RecordType: "CNAME", Address: "app.example.net.", TTL: 300},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.2"},
+	}
+
+	result := PlanResults("example.com", existing, desired)
+
+	s.Require().Len(result.Records, 4)
+	s.Require().Equal(ApplyUnchanged, result.Records[0].Status)
+	s.Require().Equal(ApplyUpdated, result.Records[1].Status)
+	s.Require().Equal("example.com.", result.Records[1].Previous.Address)
+	s.Require().Equal(ApplyCreated, result.Records[2].Status)
+	s.Require().Nil(result.Records[2].Previous)
+	s.Require().Equal(ApplyDeleted, result.Records[3].Status)
+	s.Require().Equal("old", result.Records[3].Record.HostName)
+
+	s.Require().Equal("1 created, 1 updated, 1 deleted, 1 unchanged", result.Summary())
+	s.Require().NoError(result.Err())
+}
+
+func (s *ResultTestSuite) TestFailAndErr() {
+	result := PlanResults("example.com", nil, []dnsrecord.Record{
+		{HostName: "a", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "b", RecordType: "A", Address: "192.0.2.2"},
+	})
+
+	result.Fail(1, errors.New("rejected"))
+
+	s.Require().Len(result.Failed(), 1)
+	s.Require().Equal(ApplyCreated, result.Failed()[0].Action)
+	s.Require().EqualError(result.Err(), "1 of 2 record changes failed for example.com: created b A: rejected")
+	s.Require().Equal("1 created, 1 failed", result.Summary())
+}
+
+func (s *ResultTestSuite) TestNilResult() {
+	var result *ApplyResult
+	s.Require().Zero(result.Count(ApplyCreated))
+	s.Require().Nil(result.Failed())
+	s.Require().NoError(result.Err())
+}
@@ -0,0 +1,239 @@
+package rfc2136
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dnsrecord"
+)
+
+// qualify makes the target host name of CNAME, NS, MX, PTR, SRV and NAPTR
+// records fully qualified, the form zone transfers return them in, so that
+// unchanged records compare equal. Names without a dot are relative to
+// domainName. TLSA, SSHFP and DS values are made canonical for the same reason.
+func qualify(domainName string, r dnsrecord.Record) dnsrecord.Record {
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS, dnsrecord.RecordTypeMX, dnsrecord.RecordTypePTR:
+		r.Address = qualifyName(domainName, r.Address)
+	case dnsrecord.RecordTypeSRV:
+		if fields := strings.Fields(r.Address); len(fields) == 4 {
+			fields[3] = qualifyName(domainName, fields[3])
+			r.Address = strings.Join(fields, " ")
+		}
+	case dnsrecord.RecordTypeNAPTR:
+		if naptr, err := dnsrecord.ParseNAPTR(r.Address); err == nil {
+			if naptr.Replacement != "." {
+				naptr.Replacement = qualifyName(domainName, naptr.Replacement)
+			}
+			r.Address = naptr.String()
+		}
+	case dnsrecord.RecordTypeTLSA, dnsrecord.RecordTypeSSHFP, dnsrecord.RecordTypeDS:
+		r.Address = dnsrecord.CanonicalValue(r.RecordType, r.Address)
+	}
+	return r
+}
+
+func qualifyName(domainName, name string) string {
+	switch {
+	case name == "@":
+		return lookup.FQDN(domainName)
+	case name == "" || strings.HasSuffix(name, "."):
+		return name
+	case !strings.Contains(name, "."):
+		return name + "." + lookup.FQDN(domainName)
+	default:
+		return name + "."
+	}
+}
+
+// ownerName returns the fully qualified name of a record's host
+func ownerName(domainName, hostName string) (string, error) {
+	name := lookup.FQDN(domainName)
+	switch {
+	case hostName == "" || hostName == "@":
+	case strings.HasSuffix(hostName, "."):
+		name = hostName
+	default:
+		name = hostName + "." + name
+	}
+	if _, ok := dns.IsDomainName(name); !ok {
+		return "", fmt.Errorf("not a valid domain name")
+	}
+	return name, nil
+}
+
+// targetName checks a host name in record data, which must be fully qualified
+func targetName(name string) (string, error) {
+	if _, ok := dns.IsDomainName(name); !ok || !dns.IsFqdn(name) {
+		return "", fmt.Errorf("%q is not a fully qualified domain name", name)
+	}
+	return name, nil
+}
+
+// newRR converts r to the resource record sent in the update section
+func newRR(domainName string, r dnsrecord.Record, ttl uint32) (dns.RR, error) {
+	name, err := ownerName(domainName, r.HostName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host name %s: %w", r.HostName, err)
+	}
+	rrType, ok := dns.StringToType[strings.ToUpper(r.RecordType)]
+	if !ok {
+		return nil, fmt.Errorf("rfc2136 does not support %s records", r.RecordType)
+	}
+	h := dns.RR_Header{Name: name, Rrtype: rrType, Class: dns.ClassINET, Ttl: ttl}
+	rr, err := rdata(h, r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s record %s %q: %w", r.RecordType, r.HostName, r.Address, err)
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("rfc2136 does not support %s records", r.RecordType)
+	}
+	return rr, nil
+}
+
+// rdata builds the record of type h.Rrtype from the value of r; it returns a
+// nil record for types zonekit does not write with dynamic updates
+func rdata(h dns.RR_Header, r dnsrecord.Record) (dns.RR, error) {
+	switch h.Rrtype {
+	case dns.TypeA:
+		ip := net.ParseIP(r.Address).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("not an IPv4 address")
+		}
+		return &dns.A{Hdr: h, A: ip}, nil
+	case dns.TypeAAAA:
+		ip := net.ParseIP(r.Address)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("not an IPv6 address")
+		}
+		return &dns.AAAA{Hdr: h, AAAA: ip}, nil
+	case dns.TypeCNAME:
+		target, err := targetName(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.CNAME{Hdr: h, Target: target}, nil
+	case dns.TypeNS:
+		target, err := targetName(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.NS{Hdr: h, Ns: target}, nil
+	case dns.TypeMX:
+		target, err := targetName(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.MX{Hdr: h, Preference: uint16(r.MXPref), Mx: target}, nil
+	case dns.TypeTXT:
+		txt := splitTXT(r.Address)
+		for i := range txt {
+			txt[i] = escape(txt[i])
+		}
+		return &dns.TXT{Hdr: h, Txt: txt}, nil
+	case dns.TypeSRV:
+		return parseSRV(h, r.Address)
+	case dns.TypeCAA:
+		return parseCAA(h, r.Address)
+	case dns.TypePTR:
+		target, err := targetName(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.PTR{Hdr: h, Ptr: target}, nil
+	case dns.TypeTLSA:
+		tlsa, err := dnsrecord.ParseTLSA(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.TLSA{Hdr: h, Usage: uint8(tlsa.Usage), Selector: uint8(tlsa.Selector), MatchingType: uint8(tlsa.MatchingType), Certificate: tlsa.Data}, nil
+	case dns.TypeSSHFP:
+		sshfp, err := dnsrecord.ParseSSHFP(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.SSHFP{Hdr: h, Algorithm: uint8(sshfp.Algorithm), Type: uint8(sshfp.Type), FingerPrint: sshfp.Fingerprint}, nil
+	case dns.TypeDS:
+		ds, err := dnsrecord.ParseDS(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.DS{Hdr: h, KeyTag: uint16(ds.KeyTag), Algorithm: uint8(ds.Algorithm), DigestType: uint8(ds.DigestType), Digest: ds.Digest}, nil
+	case dns.TypeNAPTR:
+		naptr, err := dnsrecord.ParseNAPTR(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := targetName(naptr.Replacement)
+		if err != nil {
+			return nil, err
+		}
+		return &dns.NAPTR{
+			Hdr: h, Order: uint16(naptr.Order), Preference: uint16(naptr.Preference),
+			Flags: escape(naptr.Flags), Service: escape(naptr.Service), Regexp: escape(naptr.Regexp),
+			Replacement: replacement,
+		}, nil
+	}
+	return nil, nil
+}
+
+// escape escapes backslashes, which miekg/dns reads as escape sequences in
+// character strings
+func escape(s string) string {
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// splitTXT splits a TXT value into the 255-byte strings of the wire format
+func splitTXT(value string) []string {
+	if value == "" {
+		return []string{""}
+	}
+	var parts []string
+	for len(value) > 255 {
+		parts = append(parts, value[:255])
+		value = value[255:]
+	}
+	return append(parts, value)
+}
+
+// parseSRV parses "priority weight port target"
+func parseSRV(h dns.RR_Header, value string) (*dns.SRV, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("expected priority, weight, port and target")
+	}
+	var numbers [3]uint16
+	for i := range numbers {
+		n, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = uint16(n)
+	}
+	target, err := targetName(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	return &dns.SRV{Hdr: h, Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Target: target}, nil
+}
+
+// parseCAA parses `flags tag "value"` (RFC 8659)
+func parseCAA(h dns.RR_Header, value string) (*dns.CAA, error) {
+	fields := strings.SplitN(strings.TrimSpace(value), " ", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf(`expected flags, tag and "value"`)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return nil, err
+	}
+	content := fields[2]
+	if unquoted, err := strconv.Unquote(content); err == nil {
+		content = unquoted
+	}
+	return &dns.CAA{Hdr: h, Flag: uint8(flags), Tag: fields[1], Value: escape(content)}, nil
+}
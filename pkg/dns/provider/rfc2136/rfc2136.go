@@ -0,0 +1,286 @@
+// Package rfc2136 implements a DNS provider that talks to name servers directly
+// instead of an HTTP API: zones are read with a zone transfer (AXFR) and changed
+// with dynamic updates (RFC 2136), both signed with a TSIG key. It works with
+// BIND, Knot, PowerDNS and other servers that accept dynamic updates.
+//
+// All changes of one SetRecords call are sent in a single update message, which
+// the server applies atomically. Records are removed one value at a time, so
+// values of the same name and type that zonekit does not manage are kept.
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/axfr"
+	"zonekit/pkg/dnsrecord"
+)
+
+const (
+	// Name is the provider name used in account configuration
+	Name = "rfc2136"
+
+	// defaultTTL is the TTL of records written without one
+	defaultTTL = 3600
+
+	// DefaultTimeout bounds each exchange with the name server
+	DefaultTimeout = 30 * time.Second
+)
+
+// Environment variables read by RegisterFromEnv; the names match lego's rfc2136 provider
+const (
+	EnvNameserver    = "RFC2136_NAMESERVER"
+	EnvTSIGKey       = "RFC2136_TSIG_KEY"
+	EnvTSIGSecret    = "RFC2136_TSIG_SECRET"
+	EnvTSIGAlgorithm = "RFC2136_TSIG_ALGORITHM"
+)
+
+func init() {
+	_ = dnsprovider.RegisterFactory(Name, func(name string, credentials map[string]string) (dnsprovider.Provider, error) {
+		return New(Config{
+			Name:          name,
+			Nameserver:    credentials["nameserver"],
+			TSIGKey:       credentials["tsig_key"],
+			TSIGSecret:    credentials["tsig_secret"],
+			TSIGAlgorithm: credentials["tsig_algorithm"],
+		})
+	})
+}
+
+// Config holds the name server and its TSIG key
+type Config struct {
+	// Name is the instance name; it defaults to the provider name
+	Name string
+	// Nameserver is the primary server, as host or host:port (port 53 by default)
+	Nameserver string
+	// TSIGKey is the key name; without a key, requests are not signed
+	TSIGKey string
+	// TSIGSecret is the base64 key secret
+	TSIGSecret string
+	// TSIGAlgorithm defaults to hmac-sha256
+	TSIGAlgorithm string
+}
+
+// RFC2136Provider implements the DNS Provider interface with DNS messages
+type RFC2136Provider struct {
+	name    string
+	server  string
+	key     *axfr.Key
+	timeout time.Duration
+}
+
+// New creates a new RFC 2136 provider
+func New(config Config) (*RFC2136Provider, error) {
+	if config.Nameserver == "" {
+		return nil, fmt.Errorf("rfc2136 requires a name server")
+	}
+	server := config.Nameserver
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+
+	var key *axfr.Key
+	if config.TSIGKey != "" || config.TSIGSecret != "" {
+		var err error
+		if key, err = axfr.ParseKey(config.TSIGKey, config.TSIGAlgorithm, config.TSIGSecret); err != nil {
+			return nil, err
+		}
+	}
+
+	name := config.Name
+	if name == "" {
+		name = Name
+	}
+
+	return &RFC2136Provider{name: name, server: server, key: key, timeout: DefaultTimeout}, nil
+}
+
+// Name returns the provider name
+func (p *RFC2136Provider) Name() string {
+	return p.name
+}
+
+// GetRecords reads the zone with a zone transfer
+func (p *RFC2136Provider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	return axfr.TransferWithKey(ctx, p.server, domainName, p.key)
+}
+
+// SetRecords sets DNS records for a domain (replaces all existing records).
+// The changes are sent in one dynamic update, so either all of them are
+// applied or none is.
+func (p *RFC2136Provider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := make([]dnsrecord.Record, len(records))
+	for i, r := range records {
+		desired[i] = qualify(domainName, r)
+	}
+	result := dnsprovider.PlanResults(domainName, existing, desired)
+
+	var deletes, adds []dnsrecord.Record
+	for _, r := range result.Records {
+		switch r.Status {
+		case dnsprovider.ApplyCreated:
+			adds = append(adds, r.Record)
+		case dnsprovider.ApplyUpdated:
+			deletes = append(deletes, *r.Previous)
+			adds = append(adds, r.Record)
+		case dnsprovider.ApplyDeleted:
+			deletes = append(deletes, r.Record)
+		}
+	}
+	if len(deletes) == 0 && len(adds) == 0 {
+		return result, nil
+	}
+
+	if err := p.update(domainName, deletes, adds); err != nil {
+		for i, r := range result.Records {
+			if r.Status != dnsprovider.ApplyUnchanged {
+				result.Fail(i, err)
+			}
+		}
+		return result, result.Err()
+	}
+	return result, nil
+}
+
+// update sends a dynamic update deleting and adding records
+func (p *RFC2136Provider) update(domainName string, deletes, adds []dnsrecord.Record) error {
+	zone := dns.Fqdn(domainName)
+	if _, ok := dns.IsDomainName(zone); !ok {
+		return fmt.Errorf("invalid zone %s", domainName)
+	}
+
+	// Replaced and deleted values are removed one at a time (class NONE)
+	m := new(dns.Msg).SetUpdate(zone)
+	var removes, inserts []dns.RR
+	for _, r := range deletes {
+		rr, err := newRR(domainName, r, 0)
+		if err != nil {
+			return err
+		}
+		removes = append(removes, rr)
+	}
+	for _, r := range adds {
+		ttl := r.TTL
+		if ttl <= 0 {
+			ttl = defaultTTL
+		}
+		rr, err := newRR(domainName, r, uint32(ttl))
+		if err != nil {
+			return err
+		}
+		inserts = append(inserts, rr)
+	}
+	m.Remove(removes)
+	m.Insert(inserts)
+
+	resp, err := p.exchange(m)
+	if err != nil {
+		return fmt.Errorf("dynamic update of %s failed: %w", domainName, err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("dynamic update of %s refused by %s: %s", domainName, p.server, dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
+
+// exchange signs m, sends it over TCP and returns the response. With a TSIG
+// key, the response must be signed with it too.
+func (p *RFC2136Provider) exchange(m *dns.Msg) (*dns.Msg, error) {
+	client := &dns.Client{Net: "tcp", Timeout: p.timeout}
+	if p.key != nil {
+		client.TsigSecret = p.key.Secrets()
+		p.key.Sign(m)
+	}
+
+	resp, _, err := client.Exchange(m, p.server)
+	if err != nil && !errors.Is(err, dns.ErrSig) && !errors.Is(err, dns.ErrTime) && !errors.Is(err, dns.ErrSecret) && !errors.Is(err, dns.ErrAuth) {
+		return nil, err
+	}
+	if p.key != nil {
+		// The client verified the signature if the response has one, so
+		// only its presence and key are left to check
+		if err := p.key.Check(resp, err); err != nil {
+			return nil, fmt.Errorf("unverified response from %s (%s): %w", p.server, dns.RcodeToString[resp.Rcode], err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ZoneSerial returns the SOA serial of the zone on the name server
+func (p *RFC2136Provider) ZoneSerial(domainName string) (uint32, error) {
+	zone := dns.Fqdn(domainName)
+	if _, ok := dns.IsDomainName(zone); !ok {
+		return 0, fmt.Errorf("invalid zone %s", domainName)
+	}
+
+	resp, err := p.exchange(new(dns.Msg).SetQuestion(zone, dns.TypeSOA))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query SOA of %s: %w", domainName, err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return 0, fmt.Errorf("SOA query for %s refused by %s: %s", domainName, p.server, dns.RcodeToString[resp.Rcode])
+	}
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, nil
+		}
+	}
+	return 0, fmt.Errorf("%s returned no SOA record for %s", p.server, domainName)
+}
+
+// Validate checks if the provider is properly configured
+func (p *RFC2136Provider) Validate() error {
+	if p.server == "" {
+		return fmt.Errorf("rfc2136 name server is not configured")
+	}
+	return nil
+}
+
+// Register registers an RFC 2136 provider with the given configuration
+func Register(config Config) error {
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+	return dnsprovider.Register(provider)
+}
+
+// RegisterFromEnv registers the RFC 2136 provider when a name server is set in
+// the environment. It reports whether the provider was registered; the
+// provider itself is constructed on first use.
+func RegisterFromEnv() (bool, error) {
+	config := Config{
+		Nameserver:    os.Getenv(EnvNameserver),
+		TSIGKey:       os.Getenv(EnvTSIGKey),
+		TSIGSecret:    os.Getenv(EnvTSIGSecret),
+		TSIGAlgorithm: os.Getenv(EnvTSIGAlgorithm),
+	}
+	if config.Nameserver == "" {
+		return false, nil
+	}
+	return true, dnsprovider.RegisterLazy(Name, func() (dnsprovider.Provider, error) {
+		return New(config)
+	})
+}
+
+// Ensure RFC2136Provider implements Provider and SerialProvider interfaces
+var (
+	_ dnsprovider.Provider       = (*RFC2136Provider)(nil)
+	_ dnsprovider.SerialProvider = (*RFC2136Provider)(nil)
+)
@@ -0,0 +1,274 @@
+package rfc2136
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/dns/dnsmessage"
+	"zonekit/pkg/dns/lookup"
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dns/provider/axfr"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers AXFR, SOA and UPDATE messages for example.com over TCP.
+// Messages not signed with key are refused, and updates are recorded and
+// answered with rcode. Responses are signed unless unsigned is set; secret, if
+// set, signs them with another secret instead.
+type fakeServer struct {
+	key      *axfr.Key
+	unsigned bool
+	secret   string
+	mu       sync.Mutex
+	updates  [][]dns.RR
+	rcode    int
+}
+
+func (f *fakeServer) serve(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener: ln, Net: "tcp", Handler: dns.HandlerFunc(f.handle),
+		TsigSecret: f.key.Secrets(),
+		// The default refuses updates
+		MsgAcceptFunc:     func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return ln.Addr().String()
+}
+
+func (f *fakeServer) handle(w dns.ResponseWriter, r *dns.Msg) {
+	f.mu.Lock()
+	unsigned, secret, rcode := f.unsigned, f.secret, f.rcode
+	f.mu.Unlock()
+
+	reply := func(rcode int, answers ...dns.RR) {
+		m := new(dns.Msg).SetRcode(r, rcode)
+		m.Answer = answers
+		if !unsigned && r.IsTsig() != nil && w.TsigStatus() == nil {
+			m.SetTsig(f.key.Name, f.key.Algorithm, axfr.Fudge, time.Now().Unix())
+		}
+		if m.IsTsig() != nil && secret != "" {
+			// Sign with the other secret by hand, as the writer would use the key's
+			out, _, err := dns.TsigGenerate(m, secret, r.IsTsig().MAC, false)
+			if err == nil {
+				_, _ = w.Write(out)
+			}
+			return
+		}
+		_ = w.WriteMsg(m)
+	}
+
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		reply(dns.RcodeRefused)
+		return
+	}
+
+	soa := &dns.SOA{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 300},
+		Ns:  "ns1.example.com.", Mbox: "hostmaster.example.com.",
+		Serial: 2024010101, Refresh: 3600, Retry: 600, Expire: 86400, Minttl: 300,
+	}
+	switch {
+	case r.Opcode == dns.OpcodeUpdate:
+		f.mu.Lock()
+		f.updates = append(f.updates, r.Ns)
+		f.mu.Unlock()
+		reply(rcode)
+	case r.Question[0].Qtype == dns.TypeAXFR:
+		reply(dns.RcodeSuccess,
+			soa,
+			&dns.A{Hdr: header("example.com.", dns.TypeA), A: net.IPv4(192, 0, 2, 1)},
+			&dns.MX{Hdr: header("example.com.", dns.TypeMX), Preference: 10, Mx: "mail.example.com."},
+			&dns.TXT{Hdr: header("old.example.com.", dns.TypeTXT), Txt: []string{"gone"}},
+			soa,
+		)
+	case r.Question[0].Qtype == dns.TypeSOA:
+		reply(dns.RcodeSuccess, soa)
+	}
+}
+
+func header(name string, rrType uint16) dns.RR_Header {
+	return dns.RR_Header{Name: name, Rrtype: rrType, Class: dns.ClassINET, Ttl: 300}
+}
+
+func newTestProvider(t *testing.T) (*RFC2136Provider, *fakeServer) {
+	p, err := New(Config{Nameserver: "127.0.0.1", TSIGKey: "zonekit", TSIGSecret: "c2VjcmV0"})
+	require.NoError(t, err)
+	fake := &fakeServer{key: p.key}
+	p.server = fake.serve(t)
+	return p, fake
+}
+
+func TestRFC2136Provider_GetRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	records, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+		{HostName: "@", RecordType: "MX", Address: "mail.example.com.", TTL: 300, MXPref: 10},
+		{HostName: "old", RecordType: "TXT", Address: "gone", TTL: 300},
+	}, records)
+
+	// Without the key the transfer is refused
+	unsigned, err := New(Config{Nameserver: p.server})
+	require.NoError(t, err)
+	_, err = unsigned.GetRecords("example.com")
+	require.ErrorContains(t, err, "refused")
+}
+
+func TestRFC2136Provider_SetRecords_SendsOneUpdate(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 300},
+		{HostName: "@", RecordType: "MX", Address: "mail", MXPref: 10, TTL: 300},
+		{HostName: "www", RecordType: "CNAME", Address: "example.net"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUnchanged), "the relative MX target matches the transferred one")
+
+	require.Len(t, fake.updates, 1)
+	update := fake.updates[0]
+	require.Len(t, update, 4)
+
+	// Replaced and deleted values are removed one at a time with class NONE
+	require.Equal(t, uint16(dns.ClassNONE), update[0].Header().Class)
+	require.Equal(t, "example.com.", update[0].Header().Name)
+	require.Equal(t, "192.0.2.1", update[0].(*dns.A).A.String())
+	require.Equal(t, uint16(dns.ClassNONE), update[1].Header().Class)
+	require.Equal(t, "old.example.com.", update[1].Header().Name)
+
+	require.Equal(t, uint16(dns.ClassINET), update[2].Header().Class)
+	require.Equal(t, "192.0.2.2", update[2].(*dns.A).A.String())
+	require.Equal(t, "www.example.com.", update[3].Header().Name)
+	require.Equal(t, uint32(defaultTTL), update[3].Header().Ttl)
+	require.Equal(t, "example.net.", update[3].(*dns.CNAME).Target)
+}
+
+func TestRFC2136Provider_SetRecords_RefusedUpdateFailsAllChanges(t *testing.T) {
+	p, fake := newTestProvider(t)
+	fake.rcode = dns.RcodeNotAuth
+
+	existing, err := p.GetRecords("example.com")
+	require.NoError(t, err)
+	_, err = p.SetRecords("example.com", existing)
+	require.NoError(t, err)
+	require.Empty(t, fake.updates)
+
+	result, err := p.SetRecords("example.com", append(existing[:2], dnsrecord.Record{HostName: "new", RecordType: "A", Address: "192.0.2.9"}))
+	require.ErrorContains(t, err, "refused")
+	require.Equal(t, 2, result.Count(dnsprovider.ApplyFailed))
+	require.Equal(t, 2, result.Count(dnsprovider.ApplyUnchanged))
+}
+
+func TestRFC2136Provider_ZoneSerial(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	serial, err := p.ZoneSerial("example.com")
+	require.NoError(t, err)
+	require.Equal(t, uint32(2024010101), serial)
+}
+
+func TestRFC2136Provider_VerifiesResponses(t *testing.T) {
+	p, fake := newTestProvider(t)
+
+	fake.unsigned = true
+	_, err := p.ZoneSerial("example.com")
+	require.ErrorContains(t, err, "response is not signed")
+	_, err = p.GetRecords("example.com")
+	require.ErrorContains(t, err, "response is not signed")
+
+	fake.unsigned = false
+	fake.secret = "b3RoZXI="
+	_, err = p.ZoneSerial("example.com")
+	require.ErrorContains(t, err, "response signature does not match")
+	_, err = p.SetRecords("example.com", nil)
+	require.ErrorContains(t, err, "response signature does not match")
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(Config{Nameserver: "ns1.example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "ns1.example.com:53", p.server)
+
+	p, err = New(Config{Nameserver: "2001:db8::53"})
+	require.NoError(t, err)
+	require.Equal(t, "[2001:db8::53]:53", p.server)
+
+	_, err = New(Config{})
+	require.ErrorContains(t, err, "name server")
+	_, err = New(Config{Nameserver: "ns1.example.com", TSIGKey: "zonekit", TSIGSecret: "c2VjcmV0", TSIGAlgorithm: "hmac-md5"})
+	require.ErrorContains(t, err, "unsupported TSIG algorithm")
+}
+
+func TestNewRR(t *testing.T) {
+	m := new(dns.Msg).SetUpdate("example.com.")
+	for _, r := range []dnsrecord.Record{
+		{HostName: "home", RecordType: "AAAA", Address: "2001:db8::1"},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com."},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`},
+		{HostName: "@", RecordType: "NS", Address: "ns1.example.com."},
+		{HostName: "1", RecordType: "PTR", Address: "host.example.com."},
+		{HostName: "_443._tcp", RecordType: "TLSA", Address: "3 1 1 " + strings.Repeat("0f", 32)},
+		{HostName: "host", RecordType: "SSHFP", Address: "4 2 " + strings.Repeat("0f", 32)},
+		{HostName: "sub", RecordType: "DS", Address: "12345 13 2 " + strings.Repeat("0f", 32)},
+		{HostName: "@", RecordType: "NAPTR", Address: `10 0 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+		{HostName: "@", RecordType: "TXT", Address: `v=spf1 \ -all`},
+	} {
+		rr, err := newRR("example.com", r, 300)
+		require.NoError(t, err, r.RecordType)
+		m.Insert([]dns.RR{rr})
+	}
+	_, err := newRR("example.com", dnsrecord.Record{HostName: "@", RecordType: "A", Address: "2001:db8::1"}, 300)
+	require.Error(t, err)
+	_, err = newRR("example.com", dnsrecord.Record{HostName: "www", RecordType: "CNAME", Address: "example.net"}, 300)
+	require.ErrorContains(t, err, "fully qualified")
+	_, err = newRR("example.com", dnsrecord.Record{HostName: "@", RecordType: "URL", Address: "https://example.com"}, 300)
+	require.ErrorContains(t, err, "does not support")
+	msg, err := m.Pack()
+	require.NoError(t, err)
+
+	// Records read back from the wire match those zone transfers return
+	var p dnsmessage.Parser
+	_, err = p.Start(msg)
+	require.NoError(t, err)
+	require.NoError(t, p.SkipAllQuestions())
+	require.NoError(t, p.SkipAllAnswers())
+	authorities, err := p.AllAuthorities()
+	require.NoError(t, err)
+
+	var records []dnsrecord.Record
+	for _, rr := range authorities {
+		record, ok := lookup.ToRecord(rr, "example.com.")
+		require.True(t, ok)
+		records = append(records, record)
+	}
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "home", RecordType: "AAAA", Address: "2001:db8::1", TTL: 300},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com.", TTL: 300},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 300},
+		{HostName: "@", RecordType: "NS", Address: "ns1.example.com.", TTL: 300},
+		{HostName: "1", RecordType: "PTR", Address: "host.example.com.", TTL: 300},
+		{HostName: "_443._tcp", RecordType: "TLSA", Address: "3 1 1 " + strings.Repeat("0f", 32), TTL: 300},
+		{HostName: "host", RecordType: "SSHFP", Address: "4 2 " + strings.Repeat("0f", 32), TTL: 300},
+		{HostName: "sub", RecordType: "DS", Address: "12345 13 2 " + strings.Repeat("0f", 32), TTL: 300},
+		{HostName: "@", RecordType: "NAPTR", Address: `10 0 "S" "SIP+D2U" "" _sip._udp.example.com.`, TTL: 300},
+		{HostName: "@", RecordType: "TXT", Address: `v=spf1 \ -all`, TTL: 300},
+	}, records)
+}
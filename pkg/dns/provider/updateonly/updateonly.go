@@ -0,0 +1,174 @@
+// Package updateonly implements the "update-only" provider category: dynamic
+// DNS services such as Dynu or DuckDNS that can only point a host at a new
+// address through an update URL. They cannot create or delete records, and
+// unless the provider supplies a RecordReader, reads are answered from public
+// DNS. Writes are limited to A and AAAA records unless configured otherwise.
+package updateonly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// UpdateFunc sends an update of the record's address (or value, for TXT records)
+// for the fully qualified host name
+type UpdateFunc func(ctx context.Context, fqdn, recordType, address string) error
+
+// RecordReader reads the records of a domain, e.g. with a zone transfer
+type RecordReader func(ctx context.Context, domainName string) ([]dnsrecord.Record, error)
+
+// Option customizes an update-only provider
+type Option func(*Provider)
+
+// WithRecordTypes sets the record types that can be updated (default A and AAAA)
+func WithRecordTypes(recordTypes ...string) Option {
+	return func(p *Provider) {
+		p.recordTypes = recordTypes
+	}
+}
+
+// WithRecordReader reads records with read instead of public DNS lookups
+func WithRecordReader(read RecordReader) Option {
+	return func(p *Provider) {
+		p.read = read
+	}
+}
+
+// Provider adapts an update URL to the DNS Provider interface
+type Provider struct {
+	name        string
+	update      UpdateFunc
+	read        RecordReader
+	recordTypes []string
+	lookupIP    func(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// New creates an update-only provider that sends updates with update
+func New(name string, update UpdateFunc, opts ...Option) *Provider {
+	p := &Provider{
+		name:        name,
+		update:      update,
+		recordTypes: []string{dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA},
+		lookupIP:    net.DefaultResolver.LookupIP,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Capabilities reports the narrow feature set of update-only providers
+func (p *Provider) Capabilities() dnsprovider.Capabilities {
+	return dnsprovider.Capabilities{
+		SupportedRecordTypes: p.recordTypes,
+		UpdateOnly:           true,
+	}
+}
+
+// GetRecords returns the records read by the provider's RecordReader or, without
+// one, the A and AAAA records of the domain's apex as seen in public DNS.
+func (p *Provider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	if p.read != nil {
+		return p.read(context.Background(), domainName)
+	}
+
+	records := []dnsrecord.Record{}
+
+	for _, lookup := range []struct{ network, recordType string }{
+		{"ip4", dnsrecord.RecordTypeA},
+		{"ip6", dnsrecord.RecordTypeAAAA},
+	} {
+		ips, err := p.lookupIP(context.Background(), lookup.network, domainName)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("failed to resolve %s: %w", domainName, err)
+		}
+		for _, ip := range ips {
+			records = append(records, dnsrecord.Record{
+				HostName:   "@",
+				RecordType: lookup.recordType,
+				Address:    ip.String(),
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// SetRecords applies address changes. Only created or updated records of the
+// supported types can be applied; every other change is reported as failed.
+func (p *Provider) SetRecords(domainName string, records []dnsrecord.Record) (*dnsprovider.ApplyResult, error) {
+	existing, err := p.GetRecords(domainName)
+	if err != nil {
+		return nil, err
+	}
+	result := dnsprovider.PlanResults(domainName, existing, records)
+
+	for i, r := range result.Records {
+		switch r.Status {
+		case dnsprovider.ApplyUnchanged:
+			continue
+		case dnsprovider.ApplyCreated, dnsprovider.ApplyUpdated:
+			if err := p.UpdateAddress(domainName, r.Record.HostName, r.Record.RecordType, r.Record.Address); err != nil {
+				result.Fail(i, err)
+			}
+		default:
+			result.Fail(i, p.unsupported("deleting records"))
+		}
+	}
+
+	return result, result.Err()
+}
+
+// UpdateAddress points hostName in domainName at address; for TXT records
+// address is the new value
+func (p *Provider) UpdateAddress(domainName, hostName, recordType, address string) error {
+	recordType = strings.ToUpper(recordType)
+	if !p.Capabilities().SupportsRecordType(recordType) {
+		return p.unsupported(recordType + " records")
+	}
+
+	return p.update(context.Background(), FQDN(hostName, domainName), recordType, address)
+}
+
+// Validate checks if the provider is properly configured
+func (p *Provider) Validate() error {
+	if p.update == nil {
+		return fmt.Errorf("%s update function is not configured", p.name)
+	}
+	return nil
+}
+
+// unsupported returns the error for operations the provider cannot perform
+func (p *Provider) unsupported(what string) error {
+	return fmt.Errorf("%s is an update-only provider and does not support %s", p.name, what)
+}
+
+// FQDN returns the fully qualified name of hostName within domainName
+func FQDN(hostName, domainName string) string {
+	if hostName == "" || hostName == "@" {
+		return domainName
+	}
+	return hostName + "." + domainName
+}
+
+// Ensure Provider implements the provider interfaces
+var (
+	_ dnsprovider.Provider             = (*Provider)(nil)
+	_ dnsprovider.CapabilitiesProvider = (*Provider)(nil)
+	_ dnsprovider.AddressUpdater       = (*Provider)(nil)
+)
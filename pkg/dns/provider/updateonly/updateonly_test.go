@@ -0,0 +1,68 @@
+package updateonly
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(updates *[]string) *Provider {
+	p := New("test", func(ctx context.Context, fqdn, recordType, address string) error {
+		*updates = append(*updates, fqdn+" "+recordType+" "+address)
+		return nil
+	})
+	p.lookupIP = func(ctx context.Context, network, host string) ([]net.IP, error) {
+		if network == "ip4" {
+			return []net.IP{net.ParseIP("192.0.2.1")}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return p
+}
+
+func TestProvider_GetRecords(t *testing.T) {
+	var updates []string
+	p := newTestProvider(&updates)
+
+	records, err := p.GetRecords("home.example.net")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{{HostName: "@", RecordType: "A", Address: "192.0.2.1"}}, records)
+}
+
+func TestProvider_SetRecords(t *testing.T) {
+	var updates []string
+	p := newTestProvider(&updates)
+
+	result, err := p.SetRecords("home.example.net", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "198.51.100.7"},
+		{HostName: "@", RecordType: "AAAA", Address: "2001:db8::1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUpdated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, []string{"home.example.net A 198.51.100.7", "home.example.net AAAA 2001:db8::1"}, updates)
+
+	// Anything other than an address update is rejected
+	result, err = p.SetRecords("home.example.net", []dnsrecord.Record{
+		{HostName: "@", RecordType: "TXT", Address: "hello"},
+	})
+	require.Error(t, err)
+	require.Len(t, result.Failed(), 2)
+	require.Contains(t, err.Error(), "update-only provider")
+}
+
+func TestProvider_UpdateAddress(t *testing.T) {
+	var updates []string
+	p := newTestProvider(&updates)
+
+	require.NoError(t, p.UpdateAddress("example.net", "home", "aaaa", "2001:db8::1"))
+	require.Equal(t, []string{"home.example.net AAAA 2001:db8::1"}, updates)
+
+	require.Error(t, p.UpdateAddress("example.net", "home", "MX", "mail.example.net"))
+	require.True(t, dnsprovider.CapabilitiesOf(p).UpdateOnly)
+}
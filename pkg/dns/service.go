@@ -1,22 +1,68 @@
 package dns
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 
 	"zonekit/pkg/client"
 	"zonekit/pkg/dns/provider"
 	"zonekit/pkg/dns/provider/namecheap"
 	"zonekit/pkg/dnsrecord"
 	"zonekit/pkg/errors"
+	"zonekit/pkg/tracing"
 )
 
+// recordLimitWarnRatio is the share of a provider's record limit above which
+// writes warn that the zone is close to full
+const recordLimitWarnRatio = 0.9
+
 // Service provides DNS record management operations
 type Service struct {
 	provider provider.Provider
+	recorder Recorder
+	undo     UndoRecorder
+	snapshot Snapshotter
+	policy   Policy
+	notes    *NoteStore
+	// quiet silences the warnings printed before changes are applied
+	quiet bool
+
+	// cache holds the records read per domain. A service lives for a single
+	// command run, so records are fetched at most once until they are written.
+	mu    sync.Mutex
+	cache map[string][]dnsrecord.Record
+}
+
+// Recorder receives the outcome of every apply, e.g. to keep a change history
+type Recorder interface {
+	Record(domainName, providerName string, result *provider.ApplyResult) error
+}
+
+// UndoRecorder is notified of every change set that was applied, with the
+// zone's records before and after it, so that an interactive session can
+// revert it
+type UndoRecorder interface {
+	Applied(domainName, providerName string, before, after []dnsrecord.Record)
+}
+
+// Snapshotter saves the records of a zone before a change set is applied to
+// it, so the change can be reverted later
+type Snapshotter interface {
+	Snapshot(domainName, providerName string, before []dnsrecord.Record)
 }
 
-// NewService creates a new DNS service with Namecheap provider
+// Policy checks a change set before it is applied. Its warnings are printed;
+// an error blocks the change.
+type Policy interface {
+	Check(domainName string, before, after []dnsrecord.Record) (warnings []string, err error)
+}
+
+// NewService creates a new DNS service with Namecheap provider, using the
+// client's account credentials. Accounts bound to another provider use
+// NewServiceWithProviderName.
 func NewService(client *client.Client) *Service {
 	// Register Namecheap provider
 	_ = namecheap.Register(client)
@@ -48,38 +94,446 @@ func NewServiceWithProviderName(providerName string) (*Service, error) {
 	}, nil
 }
 
-// GetRecords retrieves all DNS records for a domain
+// SetRecorder attaches a recorder that is notified after records are applied
+func (s *Service) SetRecorder(recorder Recorder) {
+	s.recorder = recorder
+}
+
+// SetUndoRecorder attaches a recorder that receives the records before and
+// after each applied change set
+func (s *Service) SetUndoRecorder(recorder UndoRecorder) {
+	s.undo = recorder
+}
+
+// SetSnapshotter attaches a snapshotter that receives the records of a zone
+// before each change set that changes them is applied
+func (s *Service) SetSnapshotter(snapshotter Snapshotter) {
+	s.snapshot = snapshotter
+}
+
+// SetPolicy attaches a policy that every change set must pass
+func (s *Service) SetPolicy(policy Policy) {
+	s.policy = policy
+}
+
+// SetNoteStore keeps the comments and tags of records in local state for
+// providers that cannot store them
+func (s *Service) SetNoteStore(notes *NoteStore) {
+	s.notes = notes
+}
+
+// SetQuiet silences the warnings printed before changes are applied, e.g. in
+// automation where nobody reads them
+func (s *Service) SetQuiet(quiet bool) {
+	s.quiet = quiet
+}
+
+// ProviderName returns the name of the DNS provider used by the service
+func (s *Service) ProviderName() string {
+	return s.provider.Name()
+}
+
+// GetRecords retrieves all DNS records for a domain. Records are read from the
+// provider once and served from memory until the domain is written through the
+// service; callers receive their own copy.
 func (s *Service) GetRecords(domainName string) ([]dnsrecord.Record, error) {
-	return s.provider.GetRecords(domainName)
+	key := strings.ToLower(domainName)
+
+	s.mu.Lock()
+	records, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return append([]dnsrecord.Record(nil), records...), nil
+	}
+
+	span := s.startSpan("get_records", domainName)
+	records, err := s.provider.GetRecords(domainName)
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+	span.SetAttributes(tracing.Int("zonekit.records", len(records)))
+	span.End(nil)
+	if s.notes != nil && !s.Capabilities().Comments {
+		if records, err = s.notes.Annotate(s.provider.Name(), domainName, records); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	s.mu.Lock()
+	if s.cache == nil {
+		s.cache = make(map[string][]dnsrecord.Record)
+	}
+	s.cache[key] = append([]dnsrecord.Record(nil), records...)
+	s.mu.Unlock()
+
+	return records, nil
+}
+
+// RefreshRecords reads the records of a domain from the provider again,
+// bypassing the cache, for callers that poll a zone
+func (s *Service) RefreshRecords(domainName string) ([]dnsrecord.Record, error) {
+	s.invalidate(domainName)
+	return s.GetRecords(domainName)
+}
+
+// invalidate drops the cached records of a domain after it was written
+func (s *Service) invalidate(domainName string) {
+	s.mu.Lock()
+	delete(s.cache, strings.ToLower(domainName))
+	s.mu.Unlock()
 }
 
 // SetRecords sets DNS records for a domain (replaces all existing records)
 func (s *Service) SetRecords(domainName string, records []dnsrecord.Record) error {
-	return s.provider.SetRecords(domainName, records)
+	_, err := s.ApplyRecords(domainName, records)
+	return err
+}
+
+// ApplyRecords sets DNS records for a domain and reports the outcome for each record.
+// Zones larger than the provider's record limit are rejected before anything is written,
+// and suspicious changes (see ChangeWarnings) are warned about unless the service is quiet.
+// Changes the policy rejects are not applied.
+func (s *Service) ApplyRecords(domainName string, records []dnsrecord.Record) (*provider.ApplyResult, error) {
+	warning, err := s.CheckRecordLimit(len(records))
+	if err != nil {
+		return nil, err
+	}
+	// The records were usually just read, so this is served from the cache.
+	// Providers that replace the whole zone are given them instead of reading
+	// the zone again.
+	replacer, replaces := s.provider.(provider.ZoneReplacer)
+	var before []dnsrecord.Record
+	var beforeErr error
+	if !s.quiet || s.undo != nil || s.snapshot != nil || s.policy != nil || replaces {
+		if before, beforeErr = s.GetRecords(domainName); beforeErr != nil && s.policy != nil {
+			return nil, fmt.Errorf("failed to get records to check against the policy: %w", beforeErr)
+		}
+	}
+	if err := s.checkPolicy(domainName, before, records); err != nil {
+		return nil, err
+	}
+	if !s.quiet {
+		if warning != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		if before != nil {
+			for _, warning := range ChangeWarnings(domainName, before, records) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+		}
+	}
+
+	if s.snapshot != nil {
+		if beforeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: no snapshot was taken before the change: %v\n", beforeErr)
+		} else if plan := provider.PlanResults(domainName, before, records); plan.Count(provider.ApplyUnchanged) < len(plan.Records) {
+			s.snapshot.Snapshot(domainName, s.provider.Name(), before)
+		}
+	}
+
+	// Comments and tags go to the provider only if it can store them
+	native := s.Capabilities().Comments
+	applied := records
+	if !native {
+		applied = stripNotes(records)
+	}
+	span := s.startSpan("set_records", domainName)
+	var result *provider.ApplyResult
+	if replaces && beforeErr == nil {
+		result, err = replacer.ReplaceRecords(domainName, before, applied)
+	} else {
+		result, err = s.provider.SetRecords(domainName, applied)
+	}
+	if result != nil {
+		span.SetAttributes(
+			tracing.Int("zonekit.created", result.Count(provider.ApplyCreated)),
+			tracing.Int("zonekit.updated", result.Count(provider.ApplyUpdated)),
+			tracing.Int("zonekit.deleted", result.Count(provider.ApplyDeleted)),
+			tracing.Int("zonekit.failed", result.Count(provider.ApplyFailed)),
+		)
+	}
+	span.End(err)
+	s.invalidate(domainName)
+	s.record(domainName, result)
+	if s.notes != nil && !native && err == nil {
+		if err := s.notes.Save(s.provider.Name(), domainName, records); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to keep record comments and tags: %v\n", err)
+		}
+	}
+	if s.undo != nil && before != nil && result != nil && result.Count(provider.ApplyUnchanged) < len(result.Records) {
+		s.undo.Applied(domainName, s.provider.Name(), before, records)
+	}
+	return result, err
+}
+
+// startSpan starts the trace span of a provider operation on a domain
+func (s *Service) startSpan(operation, domainName string) *tracing.Span {
+	_, span := tracing.StartClient(context.Background(), "dns."+operation,
+		tracing.String(tracing.AttrOperation, operation),
+		tracing.String(tracing.AttrProvider, s.provider.Name()),
+		tracing.String(tracing.AttrDomain, strings.ToLower(domainName)),
+	)
+	return span
+}
+
+// checkPolicy checks a change set against the policy and prints its warnings
+func (s *Service) checkPolicy(domainName string, before, after []dnsrecord.Record) error {
+	if s.policy == nil {
+		return nil
+	}
+	warnings, err := s.policy.Check(domainName, before, after)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Policy warning: %s\n", warning)
+	}
+	return err
+}
+
+// record passes an apply result to the recorder. Whatever was applied is recorded,
+// even if some records failed; a history failure must not turn an applied change
+// into an error.
+func (s *Service) record(domainName string, result *provider.ApplyResult) {
+	if s.recorder == nil || result == nil {
+		return
+	}
+	if err := s.recorder.Record(domainName, s.provider.Name(), result); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record change history: %v\n", err)
+	}
+}
+
+// Capabilities returns what the service's provider supports
+func (s *Service) Capabilities() provider.Capabilities {
+	return provider.CapabilitiesOf(s.provider)
+}
+
+// DefaultTTL returns the TTL written for records without one: the provider's own
+// default if it declares one, otherwise zonekit's DefaultTTL
+func (s *Service) DefaultTTL() int {
+	if ttl := s.Capabilities().DefaultTTL; ttl > 0 {
+		return ttl
+	}
+	return DefaultTTL
+}
+
+// ResolveTTL fills in the default TTL of a record written without one, so the TTL
+// stored at the provider is known before writing and later diffs compare equal. It
+// returns a warning when the provider's default differs from zonekit's DefaultTTL.
+func (s *Service) ResolveTTL(record dnsrecord.Record) (dnsrecord.Record, string) {
+	if record.TTL > 0 {
+		return record, ""
+	}
+	record.TTL = s.DefaultTTL()
+	if record.TTL == DefaultTTL {
+		return record, ""
+	}
+	return record, fmt.Sprintf("no TTL given for %s %s; using %s's default of %s instead of zonekit's %s",
+		record.HostName, record.RecordType, s.provider.Name(), dnsrecord.FormatTTL(record.TTL), dnsrecord.FormatTTL(DefaultTTL))
+}
+
+// CheckRecordLimit checks a zone of count records against the provider's record
+// limit. It returns an error when the zone exceeds the limit, and a warning when
+// it uses more than recordLimitWarnRatio of it.
+func (s *Service) CheckRecordLimit(count int) (string, error) {
+	limit := s.Capabilities().MaxRecords
+	if limit == 0 {
+		return "", nil
+	}
+	if count > limit {
+		return "", errors.NewInvalidInput("records", fmt.Sprintf("%d records exceed the limit of %d records per zone at %s; nothing was written", count, limit, s.provider.Name()))
+	}
+	if float64(count) >= float64(limit)*recordLimitWarnRatio {
+		return fmt.Sprintf("zone has %d of at most %d records at %s", count, limit, s.provider.Name()), nil
+	}
+	return "", nil
+}
+
+// ListZones returns the zones served by the provider, or an error if the
+// provider cannot list its zones
+func (s *Service) ListZones() ([]string, error) {
+	lister, ok := s.provider.(provider.ZoneLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s cannot list zones", s.provider.Name())
+	}
+	return lister.ListZones()
+}
+
+// RawRecords returns the records of a domain as the provider's API sent them,
+// for providers implementing provider.RawRecordGetter. They are always read
+// from the provider.
+func (s *Service) RawRecords(domainName string) (interface{}, error) {
+	getter, ok := s.provider.(provider.RawRecordGetter)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not expose raw API responses", s.provider.Name())
+	}
+	return getter.GetRawRecords(domainName)
+}
+
+// UpdateAddress points the A or AAAA record of hostname at address, as dynamic DNS
+// clients do. Providers implementing provider.AddressUpdater are updated in a single
+// call; for all others the host's records of that type are replaced by one record,
+// keeping the TTL. It reports whether the address changed.
+func (s *Service) UpdateAddress(domainName, hostname, recordType, address string) (bool, error) {
+	recordType = strings.ToUpper(recordType)
+	switch recordType {
+	case dnsrecord.RecordTypeA:
+		if err := ValidateIPv4(address); err != nil {
+			return false, err
+		}
+	case dnsrecord.RecordTypeAAAA:
+		if err := ValidateIPv6(address); err != nil {
+			return false, err
+		}
+	default:
+		return false, errors.NewInvalidInput("record type", "dynamic updates support only A and AAAA records")
+	}
+
+	existing, err := s.GetRecords(domainName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get existing records: %w", err)
+	}
+
+	record := dnsrecord.Record{HostName: hostname, RecordType: recordType, Address: address}
+	var desired, current []dnsrecord.Record
+	for _, r := range existing {
+		if strings.EqualFold(r.HostName, hostname) && strings.EqualFold(r.RecordType, recordType) {
+			current = append(current, r)
+			continue
+		}
+		desired = append(desired, r)
+	}
+	if len(current) == 1 && current[0].Address == address {
+		return false, nil
+	}
+	if len(current) > 0 {
+		record.TTL = current[0].TTL
+	}
+	record, _ = s.ResolveTTL(record)
+	desired = append(desired, record)
+
+	if updater, ok := s.provider.(provider.AddressUpdater); ok {
+		if err := s.checkPolicy(domainName, existing, desired); err != nil {
+			return false, err
+		}
+		span := s.startSpan("update_address", domainName)
+		err := updater.UpdateAddress(domainName, hostname, recordType, address)
+		span.End(err)
+		s.invalidate(domainName)
+		if err != nil {
+			return false, err
+		}
+		s.record(domainName, provider.PlanResults(domainName, existing, desired))
+		return true, nil
+	}
+
+	if _, err := s.ApplyRecords(domainName, desired); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// AddRecord adds a single DNS record to a domain
+// AddMode controls what AddRecordWithMode does when the hostname already has a
+// record of the same type, or the new record conflicts with a CNAME
+type AddMode string
+
+const (
+	// AddFail returns a conflict error (the default)
+	AddFail AddMode = "fail"
+	// AddSkip leaves the existing records untouched and does not add the record
+	AddSkip AddMode = "skip"
+	// AddReplace removes the conflicting records and adds the new one
+	AddReplace AddMode = "replace"
+	// AddAppend adds the record next to existing records of the same type (e.g. a
+	// second MX or TXT record); exact duplicates and CNAME conflicts still fail
+	AddAppend AddMode = "append"
+)
+
+// AddRecord adds a single DNS record to a domain. It fails with a conflict error if
+// the hostname already has a record of the same type or the record conflicts with a CNAME.
 func (s *Service) AddRecord(domainName string, record dnsrecord.Record) error {
+	_, err := s.AddRecordWithMode(domainName, record, AddFail)
+	return err
+}
+
+// AddRecordWithMode adds a single DNS record to a domain, resolving conflicts with
+// existing records according to mode. It reports whether any change was applied.
+func (s *Service) AddRecordWithMode(domainName string, record dnsrecord.Record, mode AddMode) (bool, error) {
 	// Validate record before adding
-	if err := s.ValidateRecord(record); err != nil {
-		return fmt.Errorf("invalid record: %w", err)
+	if err := s.ValidateRecordInZone(domainName, record); err != nil {
+		return false, fmt.Errorf("invalid record: %w", err)
 	}
+	record, _ = s.ResolveTTL(record)
 
 	// Get existing records
 	existingRecords, err := s.GetRecords(domainName)
 	if err != nil {
-		return fmt.Errorf("failed to get existing records: %w", err)
+		return false, fmt.Errorf("failed to get existing records: %w", err)
 	}
 
-	// Add new record
-	allRecords := append(existingRecords, record)
+	id := fmt.Sprintf("%s %s", record.HostName, record.RecordType)
+	var kept []dnsrecord.Record
+	var conflicts []string
+	duplicate := false
+	cnameConflict := false
+
+	for _, existing := range existingRecords {
+		if !strings.EqualFold(existing.HostName, record.HostName) {
+			kept = append(kept, existing)
+			continue
+		}
+
+		switch {
+		case existing.RecordType == record.RecordType && existing.Address == record.Address && existing.MXPref == record.MXPref:
+			duplicate = true
+			kept = append(kept, existing)
+		case existing.RecordType == dnsrecord.RecordTypeCNAME || record.RecordType == dnsrecord.RecordTypeCNAME:
+			cnameConflict = true
+			conflicts = append(conflicts, fmt.Sprintf("%s %s %s", existing.HostName, existing.RecordType, existing.Address))
+		case existing.RecordType == record.RecordType:
+			conflicts = append(conflicts, fmt.Sprintf("%s %s %s", existing.HostName, existing.RecordType, existing.Address))
+			if mode == AddAppend {
+				kept = append(kept, existing)
+			}
+		default:
+			kept = append(kept, existing)
+		}
+	}
+
+	if duplicate {
+		if mode == AddFail {
+			return false, errors.NewConflict("DNS record", id, fmt.Sprintf("%s %s %s already exists", record.HostName, record.RecordType, record.Address))
+		}
+		if mode != AddReplace || len(conflicts) == 0 {
+			return false, nil
+		}
+	}
+
+	if len(conflicts) > 0 {
+		switch {
+		case mode == AddSkip:
+			return false, nil
+		case mode == AddFail || (mode == AddAppend && cnameConflict):
+			message := fmt.Sprintf("existing %s", strings.Join(conflicts, ", "))
+			if cnameConflict {
+				message += "; a CNAME cannot coexist with other records at the same hostname"
+			}
+			return false, errors.NewConflict("DNS record", id, message)
+		}
+	}
+
+	// In replace mode the conflicting records were dropped from kept
+	if !duplicate {
+		kept = append(kept, record)
+	}
 
 	// Set all records
-	return s.SetRecords(domainName, allRecords)
+	return true, s.SetRecords(domainName, kept)
 }
 
-// UpdateRecord updates a DNS record by hostname and type
+// UpdateRecord updates a DNS record by hostname and type. A record without a TTL
+// gets the default TTL.
 func (s *Service) UpdateRecord(domainName string, hostname, recordType string, newRecord dnsrecord.Record) error {
+	newRecord, _ = s.ResolveTTL(newRecord)
+
 	// Get existing records
 	existingRecords, err := s.GetRecords(domainName)
 	if err != nil {
@@ -90,7 +544,7 @@ func (s *Service) UpdateRecord(domainName string, hostname, recordType string, n
 	found := false
 	for i, record := range existingRecords {
 		if record.HostName == hostname && record.RecordType == recordType {
-			existingRecords[i] = newRecord
+			existingRecords[i] = keepNotes(record, newRecord)
 			found = true
 			break
 		}
@@ -104,6 +558,62 @@ func (s *Service) UpdateRecord(domainName string, hostname, recordType string, n
 	return s.SetRecords(domainName, existingRecords)
 }
 
+// keepNotes returns the record replacing old, with the comment and the tags of
+// old unless it has its own
+func keepNotes(old, record dnsrecord.Record) dnsrecord.Record {
+	if record.Comment == "" {
+		record.Comment = old.Comment
+	}
+	if len(record.Tags) == 0 {
+		record.Tags = old.Tags
+	}
+	return record
+}
+
+// UpdateRecordByID replaces the record with the given provider ID. A record
+// without a TTL gets the default TTL.
+func (s *Service) UpdateRecordByID(domainName, id string, newRecord dnsrecord.Record) error {
+	newRecord, _ = s.ResolveTTL(newRecord)
+
+	existingRecords, err := s.GetRecords(domainName)
+	if err != nil {
+		return fmt.Errorf("failed to get existing records: %w", err)
+	}
+
+	i := indexOfID(existingRecords, id)
+	if i < 0 {
+		return errors.NewNotFound("DNS record", id)
+	}
+	existingRecords[i] = keepNotes(existingRecords[i], newRecord)
+
+	return s.SetRecords(domainName, existingRecords)
+}
+
+// DeleteRecordByID removes the record with the given provider ID
+func (s *Service) DeleteRecordByID(domainName, id string) error {
+	existingRecords, err := s.GetRecords(domainName)
+	if err != nil {
+		return fmt.Errorf("failed to get existing records: %w", err)
+	}
+
+	i := indexOfID(existingRecords, id)
+	if i < 0 {
+		return errors.NewNotFound("DNS record", id)
+	}
+
+	return s.SetRecords(domainName, append(existingRecords[:i], existingRecords[i+1:]...))
+}
+
+// indexOfID returns the index of the record with the given ID, or -1
+func indexOfID(records []dnsrecord.Record, id string) int {
+	for i, record := range records {
+		if record.ID != "" && record.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
 // DeleteRecord removes a DNS record by hostname and type
 func (s *Service) DeleteRecord(domainName string, hostname, recordType string) error {
 	// Get existing records
@@ -168,7 +678,8 @@ func (s *Service) ValidateRecord(record dnsrecord.Record) error {
 	}
 
 	// Validate record type
-	validTypes := []string{dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeMX, dnsrecord.RecordTypeTXT, dnsrecord.RecordTypeNS, dnsrecord.RecordTypeSRV}
+	validTypes := []string{dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeMX, dnsrecord.RecordTypeTXT, dnsrecord.RecordTypeNS, dnsrecord.RecordTypeSRV, dnsrecord.RecordTypeCAA,
+		dnsrecord.RecordTypePTR, dnsrecord.RecordTypeTLSA, dnsrecord.RecordTypeSSHFP, dnsrecord.RecordTypeNAPTR, dnsrecord.RecordTypeDS}
 	isValid := false
 	for _, validType := range validTypes {
 		if record.RecordType == validType {
@@ -180,6 +691,9 @@ func (s *Service) ValidateRecord(record dnsrecord.Record) error {
 	if !isValid {
 		return errors.NewInvalidInput("record_type", fmt.Sprintf("invalid type: %s (must be one of: %s)", record.RecordType, strings.Join(validTypes, ", ")))
 	}
+	if !s.Capabilities().SupportsRecordType(record.RecordType) {
+		return errors.NewInvalidInput("record_type", fmt.Sprintf("%s does not support %s records", s.ProviderName(), record.RecordType))
+	}
 
 	// Validate TTL if provided
 	if record.TTL > 0 {
@@ -216,24 +730,105 @@ func (s *Service) ValidateRecord(record dnsrecord.Record) error {
 			return errors.NewInvalidInput("mx_pref", "MX records must have a priority value")
 		}
 		// MX address should be a valid hostname
-		if err := ValidateHostname(record.Address); err != nil {
+		if err := ValidateTarget(record.Address); err != nil {
 			return errors.NewInvalidInput("address", fmt.Sprintf("MX record must have valid hostname: %v", err))
 		}
 	case dnsrecord.RecordTypeCNAME:
 		// CNAME address should be a valid hostname
-		if err := ValidateHostname(record.Address); err != nil {
+		if err := ValidateTarget(record.Address); err != nil {
 			return errors.NewInvalidInput("address", fmt.Sprintf("CNAME record must have valid hostname: %v", err))
 		}
 	case dnsrecord.RecordTypeNS:
 		// NS address should be a valid hostname
-		if err := ValidateHostname(record.Address); err != nil {
+		if err := ValidateTarget(record.Address); err != nil {
 			return errors.NewInvalidInput("address", fmt.Sprintf("NS record must have valid hostname: %v", err))
 		}
+	case dnsrecord.RecordTypeSRV:
+		// SRV records live under the service and protocol, e.g. _sip._tcp
+		if !strings.HasPrefix(record.HostName, "_") {
+			return errors.NewInvalidInput("hostname", "SRV record must be named _service._proto, e.g. _sip._tcp")
+		}
+		srv, err := dnsrecord.ParseSRV(record.Address)
+		if err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+		// A target of "." means the service is not available
+		if srv.Target != "." {
+			if err := ValidateTarget(srv.Target); err != nil {
+				return errors.NewInvalidInput("address", fmt.Sprintf("SRV record must have valid target: %v", err))
+			}
+			if srv.Port == 0 {
+				return errors.NewInvalidInput("address", "SRV record must have a port")
+			}
+		}
+	case dnsrecord.RecordTypeTXT:
+		if err := ValidateTXT(record.Address); err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+	case dnsrecord.RecordTypeCAA:
+		if _, err := dnsrecord.ParseCAA(record.Address); err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+	case dnsrecord.RecordTypePTR:
+		if err := ValidateTarget(record.Address); err != nil {
+			return errors.NewInvalidInput("address", fmt.Sprintf("PTR record must have valid hostname: %v", err))
+		}
+	case dnsrecord.RecordTypeTLSA:
+		// TLSA records live under the port and protocol, e.g. _443._tcp.www
+		if !strings.HasPrefix(record.HostName, "_") {
+			return errors.NewInvalidInput("hostname", "TLSA record must be named _port._proto, e.g. _443._tcp")
+		}
+		if _, err := dnsrecord.ParseTLSA(record.Address); err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+	case dnsrecord.RecordTypeSSHFP:
+		if _, err := dnsrecord.ParseSSHFP(record.Address); err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+	case dnsrecord.RecordTypeDS:
+		// DS records belong to a delegated child zone, never the apex
+		if record.HostName == "@" {
+			return errors.NewInvalidInput("hostname", "DS records delegate a subdomain; set the apex DS at the registrar")
+		}
+		if _, err := dnsrecord.ParseDS(record.Address); err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+	case dnsrecord.RecordTypeNAPTR:
+		naptr, err := dnsrecord.ParseNAPTR(record.Address)
+		if err != nil {
+			return errors.NewInvalidInput("address", err.Error())
+		}
+		if naptr.Replacement != "." {
+			if err := ValidateHostname(naptr.Replacement); err != nil {
+				return errors.NewInvalidInput("address", fmt.Sprintf("NAPTR record must have valid replacement: %v", err))
+			}
+		}
 	}
 
 	return nil
 }
 
+// ValidateRecordInZone validates a DNS record as ValidateRecord does, and also
+// the checks that need the zone it is added to: a CNAME must not point at its
+// own name.
+func (s *Service) ValidateRecordInZone(domainName string, record dnsrecord.Record) error {
+	if err := s.ValidateRecord(record); err != nil {
+		return err
+	}
+
+	if record.RecordType == dnsrecord.RecordTypeCNAME {
+		host := strings.ToLower(record.HostName)
+		own := qualifiedName(host, strings.ToLower(domainName))
+		if strings.HasSuffix(host, ".") {
+			own = strings.TrimSuffix(host, ".")
+		}
+		if target := strings.ToLower(strings.TrimSuffix(record.Address, ".")); target == own {
+			return errors.NewInvalidInput("address", fmt.Sprintf("CNAME record %s cannot point at itself", record.HostName))
+		}
+	}
+	return nil
+}
+
 // BulkOperation represents a bulk DNS operation
 type BulkOperation struct {
 	Action string // Use BulkActionAdd, BulkActionUpdate, or BulkActionDelete constants
@@ -242,10 +837,28 @@ type BulkOperation struct {
 
 // BulkUpdate performs multiple DNS operations in a single API call
 func (s *Service) BulkUpdate(domainName string, operations []BulkOperation) error {
+	_, err := s.BulkApply(domainName, operations)
+	return err
+}
+
+// BulkApply performs multiple DNS operations and reports the outcome for each record
+func (s *Service) BulkApply(domainName string, operations []BulkOperation) (*provider.ApplyResult, error) {
+	_, records, err := s.PlanBulk(domainName, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set all records
+	return s.ApplyRecords(domainName, records)
+}
+
+// PlanBulk returns the current records of a domain and the records that would
+// result from applying operations, without changing anything
+func (s *Service) PlanBulk(domainName string, operations []BulkOperation) (current, desired []dnsrecord.Record, err error) {
 	// Get existing records
 	existingRecords, err := s.GetRecords(domainName)
 	if err != nil {
-		return fmt.Errorf("failed to get existing records: %w", err)
+		return nil, nil, fmt.Errorf("failed to get existing records: %w", err)
 	}
 
 	records := make([]dnsrecord.Record, len(existingRecords))
@@ -255,14 +868,14 @@ func (s *Service) BulkUpdate(domainName string, operations []BulkOperation) erro
 	for _, op := range operations {
 		switch op.Action {
 		case BulkActionAdd:
-			if err := s.ValidateRecord(op.Record); err != nil {
-				return fmt.Errorf("invalid record for add operation: %w", err)
+			if err := s.ValidateRecordInZone(domainName, op.Record); err != nil {
+				return nil, nil, fmt.Errorf("invalid record for add operation: %w", err)
 			}
 			records = append(records, op.Record)
 
 		case BulkActionUpdate:
-			if err := s.ValidateRecord(op.Record); err != nil {
-				return fmt.Errorf("invalid record for update operation: %w", err)
+			if err := s.ValidateRecordInZone(domainName, op.Record); err != nil {
+				return nil, nil, fmt.Errorf("invalid record for update operation: %w", err)
 			}
 			found := false
 			for i, record := range records {
@@ -273,7 +886,7 @@ func (s *Service) BulkUpdate(domainName string, operations []BulkOperation) erro
 				}
 			}
 			if !found {
-				return fmt.Errorf("record not found for update: %s %s", op.Record.HostName, op.Record.RecordType)
+				return nil, nil, fmt.Errorf("record not found for update: %s %s", op.Record.HostName, op.Record.RecordType)
 			}
 
 		case BulkActionDelete:
@@ -287,17 +900,16 @@ func (s *Service) BulkUpdate(domainName string, operations []BulkOperation) erro
 				filteredRecords = append(filteredRecords, record)
 			}
 			if !found {
-				return errors.NewNotFound("DNS record", fmt.Sprintf("%s %s", op.Record.HostName, op.Record.RecordType))
+				return nil, nil, errors.NewNotFound("DNS record", fmt.Sprintf("%s %s", op.Record.HostName, op.Record.RecordType))
 			}
 			records = filteredRecords
 
 		default:
-			return errors.NewInvalidInput("action", fmt.Sprintf("invalid bulk operation action: %s (must be one of: %s, %s, %s)", op.Action, BulkActionAdd, BulkActionUpdate, BulkActionDelete))
+			return nil, nil, errors.NewInvalidInput("action", fmt.Sprintf("invalid bulk operation action: %s (must be one of: %s, %s, %s)", op.Action, BulkActionAdd, BulkActionUpdate, BulkActionDelete))
 		}
 	}
 
-	// Set all records
-	return s.SetRecords(domainName, records)
+	return existingRecords, records, nil
 }
 
 func parseDomain(fullDomain string) (string, string) {
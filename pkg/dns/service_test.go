@@ -2,12 +2,14 @@ package dns
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
-	"zonekit/internal/testutil"
 	"zonekit/pkg/dns/provider"
 	"zonekit/pkg/dnsrecord"
+	zkerrors "zonekit/pkg/errors"
+	"zonekit/pkg/testutil"
 )
 
 // mockProvider is a mock implementation of the Provider interface for testing
@@ -37,12 +39,13 @@ func (m *mockProvider) GetRecords(domainName string) ([]dnsrecord.Record, error)
 	return m.records[domainName], nil
 }
 
-func (m *mockProvider) SetRecords(domainName string, records []dnsrecord.Record) error {
+func (m *mockProvider) SetRecords(domainName string, records []dnsrecord.Record) (*provider.ApplyResult, error) {
 	if m.setRecordsError != nil {
-		return m.setRecordsError
+		return nil, m.setRecordsError
 	}
+	result := provider.PlanResults(domainName, m.records[domainName], records)
 	m.records[domainName] = records
-	return nil
+	return result, nil
 }
 
 func (m *mockProvider) Validate() error {
@@ -91,6 +94,46 @@ func (s *ServiceTestSuite) TestService_ValidateRecord_ValidRecords() {
 			name:   "valid TXT record",
 			record: convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeTXT, "v=spf1 include:_spf.example.com ~all", 1800, 0)),
 		},
+		{
+			name:   "valid SRV record",
+			record: testutil.SRV("_sip._tcp", 10, 5, 5060, "sip.example.com"),
+		},
+		{
+			name:   "SRV record without service",
+			record: testutil.SRV("_imap._tcp", 0, 0, 0, "."),
+		},
+		{
+			name:   "valid PTR record",
+			record: testutil.NewRecord("1", dnsrecord.RecordTypePTR, "host.example.com.").Build(),
+		},
+		{
+			name:   "valid TLSA record",
+			record: testutil.NewRecord("_443._tcp.www", dnsrecord.RecordTypeTLSA, "3 1 1 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "valid SSHFP record",
+			record: testutil.NewRecord("host", dnsrecord.RecordTypeSSHFP, "4 2 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "valid DS record",
+			record: testutil.NewRecord("sub", dnsrecord.RecordTypeDS, "12345 13 2 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "valid NAPTR record",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeNAPTR, `10 0 "s" "SIP+D2U" "" _sip._udp.example.com.`).Build(),
+		},
+		{
+			name:   "valid CAA record",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeCAA, `0 issue "letsencrypt.org"`).Build(),
+		},
+		{
+			name:   "long unquoted TXT record",
+			record: testutil.NewRecord("sel._domainkey", dnsrecord.RecordTypeTXT, "v=DKIM1; k=rsa; p="+strings.Repeat("A", 700)).Build(),
+		},
+		{
+			name:   "TXT record of quoted strings",
+			record: testutil.NewRecord("sel._domainkey", dnsrecord.RecordTypeTXT, `"`+strings.Repeat("A", 255)+`" "`+strings.Repeat("B", 100)+`"`).Build(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +205,78 @@ func (s *ServiceTestSuite) TestService_ValidateRecord_InvalidRecords() {
 			name:   "MX preference too high",
 			record: convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeMX, "mail.example.com", 1800, 70000)),
 		},
+		{
+			name:   "SRV record without service name",
+			record: testutil.SRV("sip", 10, 5, 5060, "sip.example.com"),
+		},
+		{
+			name:   "SRV record missing port",
+			record: testutil.NewRecord("_sip._tcp", dnsrecord.RecordTypeSRV, "10 5 sip.example.com").Build(),
+		},
+		{
+			name:   "SRV record with invalid target",
+			record: testutil.SRV("_sip._tcp", 10, 5, 5060, "invalid..hostname"),
+		},
+		{
+			name:   "PTR record with invalid hostname",
+			record: testutil.NewRecord("1", dnsrecord.RecordTypePTR, "invalid..hostname").Build(),
+		},
+		{
+			name:   "TLSA record without port and protocol",
+			record: testutil.NewRecord("www", dnsrecord.RecordTypeTLSA, "3 1 1 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "TLSA record with short digest",
+			record: testutil.NewRecord("_443._tcp", dnsrecord.RecordTypeTLSA, "3 1 1 0f0f").Build(),
+		},
+		{
+			name:   "SSHFP record with unknown algorithm",
+			record: testutil.NewRecord("host", dnsrecord.RecordTypeSSHFP, "9 2 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "DS record at the apex",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeDS, "12345 13 2 "+strings.Repeat("0f", 32)).Build(),
+		},
+		{
+			name:   "NAPTR record with invalid replacement",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeNAPTR, `10 0 "s" "SIP+D2U" "" invalid..hostname`).Build(),
+		},
+		{
+			name:   "MX record pointing at an IP address",
+			record: convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeMX, "192.0.2.1", 1800, 10)),
+		},
+		{
+			name:   "NS record pointing at an IP address",
+			record: convertDNSRecord(testutil.DNSRecordFixtureWithValues("sub", dnsrecord.RecordTypeNS, "192.0.2.1", 1800, 0)),
+		},
+		{
+			name:   "CNAME record pointing at an IP address",
+			record: convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeCNAME, "2001:db8::1", 1800, 0)),
+		},
+		{
+			name:   "SRV record with an IP address target",
+			record: testutil.SRV("_sip._tcp", 10, 5, 5060, "192.0.2.1"),
+		},
+		{
+			name:   "SRV record with port 0",
+			record: testutil.SRV("_sip._tcp", 10, 5, 0, "sip.example.com"),
+		},
+		{
+			name:   "CAA record with unknown tag",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeCAA, `0 issuer "letsencrypt.org"`).Build(),
+		},
+		{
+			name:   "CAA iodef record without URL",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeCAA, `0 iodef "security@example.com"`).Build(),
+		},
+		{
+			name:   "TXT record with an overlong quoted string",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeTXT, `"`+strings.Repeat("A", 256)+`"`).Build(),
+		},
+		{
+			name:   "TXT record too long",
+			record: testutil.NewRecord("@", dnsrecord.RecordTypeTXT, strings.Repeat("A", MaxTXTLength+1)).Build(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +287,40 @@ func (s *ServiceTestSuite) TestService_ValidateRecord_InvalidRecords() {
 	}
 }
 
+// restrictedProvider is a mock provider that manages only some record types
+type restrictedProvider struct {
+	*mockProvider
+}
+
+func (m *restrictedProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{SupportedRecordTypes: []string{dnsrecord.RecordTypeA, dnsrecord.RecordTypeTXT}}
+}
+
+func (s *ServiceTestSuite) TestService_ValidateRecord_SupportedRecordTypes() {
+	service := NewServiceWithProvider(&restrictedProvider{mockProvider: newMockProvider("restricted")})
+
+	s.Require().NoError(service.ValidateRecord(testutil.A("@", "192.0.2.1")))
+	err := service.ValidateRecord(testutil.NewRecord("host", dnsrecord.RecordTypeSSHFP, "4 2 "+strings.Repeat("0f", 32)).Build())
+	s.Require().ErrorContains(err, "restricted does not support SSHFP records")
+}
+
+func (s *ServiceTestSuite) TestService_ValidateRecordInZone() {
+	s.Require().NoError(s.service.ValidateRecordInZone("example.com", testutil.CNAME("www", "example.com")))
+	s.Require().NoError(s.service.ValidateRecordInZone("example.org", testutil.CNAME("www", "www.example.com")))
+
+	for _, record := range []dnsrecord.Record{
+		testutil.CNAME("www", "www.example.com"),
+		testutil.CNAME("WWW", "www.Example.com."),
+		testutil.CNAME("www.example.com.", "www.example.com"),
+	} {
+		err := s.service.ValidateRecordInZone("example.com", record)
+		s.Require().ErrorContains(err, "cannot point at itself", record.HostName)
+	}
+
+	// Checks that need no zone still apply
+	s.Require().Error(s.service.ValidateRecordInZone("example.com", testutil.CNAME("www", "192.0.2.1")))
+}
+
 func (s *ServiceTestSuite) TestService_GetRecords() {
 	domain := testutil.ValidDomainFixture()
 
@@ -189,6 +338,30 @@ func (s *ServiceTestSuite) TestService_GetRecords() {
 	s.Require().Equal(mockRecords, records)
 }
 
+func (s *ServiceTestSuite) TestService_GetRecords_Cached() {
+	domain := testutil.ValidDomainFixture()
+	wwwA := dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1", TTL: 1800}
+	s.mock.records[domain] = []dnsrecord.Record{wwwA}
+
+	records, err := s.service.GetRecords(domain)
+	s.Require().NoError(err)
+	records[0].Address = "192.168.1.9"
+
+	// Changes at the provider are not seen until the service writes the domain,
+	// and callers cannot modify the cached records
+	s.mock.records[domain] = nil
+	records, err = s.service.GetRecords(domain)
+	s.Require().NoError(err)
+	s.Require().Equal([]dnsrecord.Record{wwwA}, records)
+
+	s.Require().NoError(s.service.AddRecord(domain, dnsrecord.Record{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 1800}))
+	s.mock.records[domain] = append(s.mock.records[domain], dnsrecord.Record{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.3", TTL: 1800})
+
+	records, err = s.service.GetRecords(domain)
+	s.Require().NoError(err)
+	s.Require().Len(records, 3)
+}
+
 func (s *ServiceTestSuite) TestService_GetRecords_Error() {
 	domain := testutil.ValidDomainFixture()
 	expectedError := errors.New("provider error")
@@ -211,6 +384,116 @@ func (s *ServiceTestSuite) TestService_SetRecords() {
 	s.Require().Equal(records, s.mock.records[domain])
 }
 
+// replacingProvider replaces whole zones and counts the reads of their records
+type replacingProvider struct {
+	*mockProvider
+	reads    int
+	replaced [][]dnsrecord.Record
+}
+
+func (p *replacingProvider) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	p.reads++
+	return p.mockProvider.GetRecords(domainName)
+}
+
+func (p *replacingProvider) ReplaceRecords(domainName string, existing, records []dnsrecord.Record) (*provider.ApplyResult, error) {
+	p.replaced = append(p.replaced, existing)
+	result := provider.PlanResults(domainName, existing, records)
+	p.records[domainName] = records
+	return result, nil
+}
+
+func (s *ServiceTestSuite) TestService_AddRecord_ZoneReplacerReadsOnce() {
+	domain := testutil.ValidDomainFixture()
+	existing := convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeA, "192.168.1.1", 1800, 0))
+	replacer := &replacingProvider{mockProvider: newMockProvider("mock")}
+	replacer.records[domain] = []dnsrecord.Record{existing}
+	service := NewServiceWithProvider(replacer)
+	service.SetQuiet(true)
+
+	recorder := &recordingRecorder{}
+	service.SetRecorder(recorder)
+	err := service.AddRecord(domain, convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeA, "192.168.1.2", 1800, 0)))
+	s.Require().NoError(err)
+
+	s.Require().Equal(1, replacer.reads)
+	s.Require().Equal([][]dnsrecord.Record{{existing}}, replacer.replaced)
+	s.Require().Len(recorder.results, 1)
+	s.Require().Equal(1, recorder.results[0].Count(provider.ApplyCreated))
+	s.Require().Equal(1, recorder.results[0].Count(provider.ApplyUnchanged))
+}
+
+// recordingRecorder captures apply results passed to the service recorder
+type recordingRecorder struct {
+	results []*provider.ApplyResult
+}
+
+func (r *recordingRecorder) Record(domainName, providerName string, result *provider.ApplyResult) error {
+	r.results = append(r.results, result)
+	return nil
+}
+
+func (s *ServiceTestSuite) TestService_SetRecords_Recorder() {
+	domain := testutil.ValidDomainFixture()
+	recorder := &recordingRecorder{}
+	s.service.SetRecorder(recorder)
+
+	err := s.service.AddRecord(domain, convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeA, "192.168.1.1", 1800, 0)))
+	s.Require().NoError(err)
+
+	s.Require().Len(recorder.results, 1)
+	s.Require().Equal(1, recorder.results[0].Count(provider.ApplyCreated))
+}
+
+// recordingSnapshotter captures the records passed to the service snapshotter
+type recordingSnapshotter struct {
+	snapshots [][]dnsrecord.Record
+}
+
+func (r *recordingSnapshotter) Snapshot(domainName, providerName string, before []dnsrecord.Record) {
+	r.snapshots = append(r.snapshots, before)
+}
+
+func (s *ServiceTestSuite) TestService_SetRecords_Snapshotter() {
+	domain := testutil.ValidDomainFixture()
+	existing := []dnsrecord.Record{
+		convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeA, "192.168.1.1", 1800, 0)),
+	}
+	s.mock.records[domain] = existing
+	snapshotter := &recordingSnapshotter{}
+	s.service.SetSnapshotter(snapshotter)
+
+	// Applying the records the zone already has takes no snapshot
+	s.Require().NoError(s.service.SetRecords(domain, existing))
+	s.Require().Empty(snapshotter.snapshots)
+
+	err := s.service.AddRecord(domain, convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeA, "192.168.1.2", 1800, 0)))
+	s.Require().NoError(err)
+	s.Require().Len(snapshotter.snapshots, 1)
+	s.Require().Equal(existing, snapshotter.snapshots[0])
+}
+
+// blockingPolicy rejects every change
+type blockingPolicy struct {
+	checked int
+}
+
+func (p *blockingPolicy) Check(domainName string, before, after []dnsrecord.Record) ([]string, error) {
+	p.checked++
+	return nil, errors.New("blocked")
+}
+
+func (s *ServiceTestSuite) TestService_SetRecords_Policy() {
+	domain := testutil.ValidDomainFixture()
+	policy := &blockingPolicy{}
+	s.service.SetPolicy(policy)
+
+	err := s.service.AddRecord(domain, convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeA, "192.168.1.1", 1800, 0)))
+	s.Require().EqualError(err, "blocked")
+	s.Require().Equal(1, policy.checked)
+	s.Require().Empty(s.mock.records[domain])
+}
+
 func (s *ServiceTestSuite) TestService_SetRecords_Error() {
 	domain := testutil.ValidDomainFixture()
 	expectedError := errors.New("provider error")
@@ -246,6 +529,103 @@ func (s *ServiceTestSuite) TestService_AddRecord() {
 	s.Require().Contains(records, newRecord)
 }
 
+func (s *ServiceTestSuite) TestService_AddRecord_Conflicts() {
+	domain := testutil.ValidDomainFixture()
+	wwwA := dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1", TTL: 1800}
+	mx := dnsrecord.Record{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx1.example.com", TTL: 1800, MXPref: 10}
+
+	tests := []struct {
+		name     string
+		existing []dnsrecord.Record
+		record   dnsrecord.Record
+		mode     AddMode
+		wantErr  bool
+		added    bool
+		want     []dnsrecord.Record
+	}{
+		{
+			name:     "exact duplicate fails by default",
+			existing: []dnsrecord.Record{wwwA},
+			record:   wwwA,
+			mode:     AddFail,
+			wantErr:  true,
+			want:     []dnsrecord.Record{wwwA},
+		},
+		{
+			name:     "same host and type fails by default",
+			existing: []dnsrecord.Record{wwwA},
+			record:   dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 1800},
+			mode:     AddFail,
+			wantErr:  true,
+			want:     []dnsrecord.Record{wwwA},
+		},
+		{
+			name:     "if absent skips",
+			existing: []dnsrecord.Record{wwwA},
+			record:   dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 1800},
+			mode:     AddSkip,
+			want:     []dnsrecord.Record{wwwA},
+		},
+		{
+			name:     "replace existing swaps the record",
+			existing: []dnsrecord.Record{wwwA, mx},
+			record:   dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 1800},
+			mode:     AddReplace,
+			added:    true,
+			want:     []dnsrecord.Record{mx, {HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 1800}},
+		},
+		{
+			name:     "append adds a second MX",
+			existing: []dnsrecord.Record{mx},
+			record:   dnsrecord.Record{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx2.example.com", TTL: 1800, MXPref: 20},
+			mode:     AddAppend,
+			added:    true,
+			want:     []dnsrecord.Record{mx, {HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx2.example.com", TTL: 1800, MXPref: 20}},
+		},
+		{
+			name:     "append never duplicates",
+			existing: []dnsrecord.Record{mx},
+			record:   mx,
+			mode:     AddAppend,
+			want:     []dnsrecord.Record{mx},
+		},
+		{
+			name:     "CNAME conflicts with other types",
+			existing: []dnsrecord.Record{wwwA},
+			record:   dnsrecord.Record{HostName: "WWW", RecordType: dnsrecord.RecordTypeCNAME, Address: "example.net", TTL: 1800},
+			mode:     AddAppend,
+			wantErr:  true,
+			want:     []dnsrecord.Record{wwwA},
+		},
+		{
+			name:     "replace existing replaces a conflicting CNAME",
+			existing: []dnsrecord.Record{{HostName: "www", RecordType: dnsrecord.RecordTypeCNAME, Address: "example.net", TTL: 1800}, mx},
+			record:   wwwA,
+			mode:     AddReplace,
+			added:    true,
+			want:     []dnsrecord.Record{mx, wwwA},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.mock.records[domain] = append([]dnsrecord.Record(nil), tt.existing...)
+			service := NewServiceWithProvider(s.mock)
+
+			added, err := service.AddRecordWithMode(domain, tt.record, tt.mode)
+			if tt.wantErr {
+				s.Require().Error(err)
+				var conflict *zkerrors.ErrConflict
+				s.Require().ErrorAs(err, &conflict)
+			} else {
+				s.Require().NoError(err)
+			}
+			s.Require().Equal(tt.added, added)
+			s.Require().Equal(tt.want, s.mock.records[domain])
+		})
+	}
+}
+
 func (s *ServiceTestSuite) TestService_UpdateRecord() {
 	domain := testutil.ValidDomainFixture()
 
@@ -282,6 +662,25 @@ func (s *ServiceTestSuite) TestService_UpdateRecord_NotFound() {
 	s.Require().Error(err)
 }
 
+func (s *ServiceTestSuite) TestService_RecordByID() {
+	domain := testutil.ValidDomainFixture()
+	mx1 := dnsrecord.Record{ID: "a1", HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx1.example.com", TTL: 1800, MXPref: 10}
+	mx2 := dnsrecord.Record{ID: "b2", HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx2.example.com", TTL: 1800, MXPref: 20}
+	s.mock.records[domain] = []dnsrecord.Record{mx1, mx2}
+
+	// The second of two records with the same host and type can be selected
+	updated := dnsrecord.Record{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx3.example.com", TTL: 1800, MXPref: 30}
+	s.Require().NoError(s.service.UpdateRecordByID(domain, "b2", updated))
+	s.Require().Equal([]dnsrecord.Record{mx1, updated}, s.mock.records[domain])
+
+	s.Require().NoError(s.service.DeleteRecordByID(domain, "a1"))
+	s.Require().Equal([]dnsrecord.Record{updated}, s.mock.records[domain])
+
+	var notFound *zkerrors.ErrNotFound
+	s.Require().ErrorAs(s.service.DeleteRecordByID(domain, "zz"), &notFound)
+	s.Require().ErrorAs(s.service.UpdateRecordByID(domain, "", updated), &notFound)
+}
+
 func (s *ServiceTestSuite) TestService_DeleteRecord() {
 	domain := testutil.ValidDomainFixture()
 
@@ -657,6 +1056,49 @@ func (s *ServiceTestSuite) TestService_NewServiceWithProviderName_NotFound() {
 	s.Require().Nil(service)
 }
 
+func (s *ServiceTestSuite) TestRecordFilter_Match() {
+	record := convertDNSRecord(testutil.DNSRecordFixtureWithValues("_acme-challenge", dnsrecord.RecordTypeTXT, "google-site-verification=abc", 300, 0))
+
+	s.Require().True(RecordFilter{}.Match(record))
+	s.Require().True(RecordFilter{Type: "txt"}.Match(record))
+	s.Require().False(RecordFilter{Type: dnsrecord.RecordTypeA}.Match(record))
+	s.Require().True(RecordFilter{Host: "_acme*"}.Match(record))
+	s.Require().True(RecordFilter{Host: "_ACME-challenge"}.Match(record))
+	s.Require().False(RecordFilter{Host: "_acme"}.Match(record))
+	s.Require().True(RecordFilter{Value: "Site-Verification"}.Match(record))
+	s.Require().False(RecordFilter{Value: "spf"}.Match(record))
+}
+
+func (s *ServiceTestSuite) TestService_Inventory() {
+	s.mock.records["b.com"] = []dnsrecord.Record{
+		convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeTXT, "v=spf1 -all", 1800, 0)),
+		convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeTXT, "google-site-verification=b", 1800, 0)),
+	}
+	s.mock.records["a.com"] = []dnsrecord.Record{
+		convertDNSRecord(testutil.DNSRecordFixtureWithValues("www", dnsrecord.RecordTypeA, "192.168.1.1", 1800, 0)),
+		convertDNSRecord(testutil.DNSRecordFixtureWithValues("@", dnsrecord.RecordTypeTXT, "google-site-verification=a", 1800, 0)),
+	}
+
+	inventory := s.service.Inventory([]string{"b.com", "a.com"}, RecordFilter{Type: dnsrecord.RecordTypeTXT, Value: "verification"})
+
+	s.Require().Empty(inventory.Errors)
+	s.Require().Len(inventory.Records, 2)
+	s.Require().Equal("a.com", inventory.Records[0].Domain)
+	s.Require().Equal("google-site-verification=a", inventory.Records[0].Value)
+	s.Require().Equal("b.com", inventory.Records[1].Domain)
+}
+
+func (s *ServiceTestSuite) TestService_Inventory_Errors() {
+	s.mock.getRecordsError = errors.New("provider error")
+
+	inventory := s.service.Inventory([]string{"a.com"}, RecordFilter{})
+
+	s.Require().Empty(inventory.Records)
+	s.Require().Len(inventory.Errors, 1)
+	s.Require().Equal("a.com", inventory.Errors[0].Domain)
+	s.Require().Equal("provider error", inventory.Errors[0].Error)
+}
+
 // convertDNSRecord converts testutil DNS record to dnsrecord.Record
 func convertDNSRecord(fixture testutil.TestDNSRecord) dnsrecord.Record {
 	return dnsrecord.Record{
@@ -667,3 +1109,267 @@ func convertDNSRecord(fixture testutil.TestDNSRecord) dnsrecord.Record {
 		MXPref:     fixture.MXPref,
 	}
 }
+
+// addressUpdaterProvider is a mock update-only provider
+type addressUpdaterProvider struct {
+	*mockProvider
+	updates []string
+}
+
+func (m *addressUpdaterProvider) UpdateAddress(domainName, hostName, recordType, address string) error {
+	m.updates = append(m.updates, hostName+" "+recordType+" "+address)
+	return nil
+}
+
+func (s *ServiceTestSuite) TestService_UpdateAddress() {
+	domain := testutil.ValidDomainFixture()
+	s.mock.records[domain] = []dnsrecord.Record{
+		{HostName: "home", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.1", TTL: 300},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+	}
+
+	changed, err := s.service.UpdateAddress(domain, "home", "a", "192.0.2.1")
+	s.Require().NoError(err)
+	s.Require().False(changed)
+
+	changed, err = s.service.UpdateAddress(domain, "home", "A", "198.51.100.7")
+	s.Require().NoError(err)
+	s.Require().True(changed)
+	s.Require().ElementsMatch([]dnsrecord.Record{
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+		{HostName: "home", RecordType: dnsrecord.RecordTypeA, Address: "198.51.100.7", TTL: 300},
+	}, s.mock.records[domain])
+
+	_, err = s.service.UpdateAddress(domain, "home", "AAAA", "198.51.100.7")
+	s.Require().Error(err)
+	_, err = s.service.UpdateAddress(domain, "home", "CNAME", "example.com")
+	s.Require().Error(err)
+}
+
+func (s *ServiceTestSuite) TestService_UpdateAddress_AddressUpdater() {
+	domain := testutil.ValidDomainFixture()
+	updater := &addressUpdaterProvider{mockProvider: newMockProvider("ddns")}
+	service := NewServiceWithProvider(updater)
+	recorder := &recordingRecorder{}
+	service.SetRecorder(recorder)
+
+	changed, err := service.UpdateAddress(domain, "@", "AAAA", "2001:db8::1")
+	s.Require().NoError(err)
+	s.Require().True(changed)
+	s.Require().Equal([]string{"@ AAAA 2001:db8::1"}, updater.updates)
+	s.Require().Empty(updater.records[domain], "SetRecords must not be used")
+	s.Require().Len(recorder.results, 1)
+	s.Require().Equal(1, recorder.results[0].Count(provider.ApplyCreated))
+}
+
+// zoneListerProvider is a mock provider that can list its zones
+type zoneListerProvider struct {
+	*mockProvider
+}
+
+func (m *zoneListerProvider) ListZones() ([]string, error) {
+	zones := make([]string, 0, len(m.records))
+	for zone := range m.records {
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+func (s *ServiceTestSuite) TestService_ListZones() {
+	_, err := s.service.ListZones()
+	s.Require().ErrorContains(err, "cannot list zones")
+
+	lister := &zoneListerProvider{mockProvider: newMockProvider("lister")}
+	lister.records["example.com"] = nil
+	lister.records["example.org"] = nil
+
+	zones, err := NewServiceWithProvider(lister).ListZones()
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]string{"example.com", "example.org"}, zones)
+}
+
+// rawProvider is a mock provider that returns its records as a raw response
+type rawProvider struct {
+	*mockProvider
+}
+
+func (m *rawProvider) GetRawRecords(domainName string) (interface{}, error) {
+	return map[string]interface{}{"domain": domainName, "count": len(m.records[domainName])}, nil
+}
+
+func (s *ServiceTestSuite) TestService_RawRecords() {
+	_, err := s.service.RawRecords("example.com")
+	s.Require().ErrorContains(err, "does not expose raw API responses")
+
+	raw := &rawProvider{mockProvider: newMockProvider("raw")}
+	raw.records["example.com"] = []dnsrecord.Record{{HostName: "@", RecordType: "A", Address: "192.0.2.1"}}
+
+	data, err := NewServiceWithProvider(raw).RawRecords("example.com")
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]interface{}{"domain": "example.com", "count": 1}, data)
+}
+
+// limitedProvider is a mock provider with a record limit
+type limitedProvider struct {
+	*mockProvider
+	maxRecords int
+}
+
+func (m *limitedProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{MaxRecords: m.maxRecords}
+}
+
+func (s *ServiceTestSuite) TestService_CheckRecordLimit() {
+	warning, err := s.service.CheckRecordLimit(10000)
+	s.Require().NoError(err)
+	s.Require().Empty(warning)
+
+	limited := &limitedProvider{mockProvider: newMockProvider("limited"), maxRecords: 10}
+	service := NewServiceWithProvider(limited)
+
+	warning, err = service.CheckRecordLimit(8)
+	s.Require().NoError(err)
+	s.Require().Empty(warning)
+
+	warning, err = service.CheckRecordLimit(9)
+	s.Require().NoError(err)
+	s.Require().Contains(warning, "9 of at most 10 records")
+
+	_, err = service.CheckRecordLimit(11)
+	var invalid *zkerrors.ErrInvalidInput
+	s.Require().ErrorAs(err, &invalid)
+}
+
+func (s *ServiceTestSuite) TestService_ApplyRecords_OverLimitWritesNothing() {
+	limited := &limitedProvider{mockProvider: newMockProvider("limited"), maxRecords: 1}
+	service := NewServiceWithProvider(limited)
+	records := []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1"},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2"},
+	}
+
+	result, err := service.ApplyRecords("example.com", records)
+	s.Require().ErrorContains(err, "exceed the limit of 1 records")
+	s.Require().Nil(result)
+	s.Require().Empty(limited.records)
+}
+
+// defaultTTLProvider is a mock provider with its own default TTL
+type defaultTTLProvider struct {
+	*mockProvider
+	defaultTTL int
+}
+
+func (m *defaultTTLProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{DefaultTTL: m.defaultTTL}
+}
+
+func (s *ServiceTestSuite) TestService_ResolveTTL() {
+	record, warning := s.service.ResolveTTL(dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1"})
+	s.Require().Equal(DefaultTTL, record.TTL)
+	s.Require().Empty(warning)
+
+	service := NewServiceWithProvider(&defaultTTLProvider{mockProvider: newMockProvider("short"), defaultTTL: 600})
+	s.Require().Equal(600, service.DefaultTTL())
+
+	record, warning = service.ResolveTTL(dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1"})
+	s.Require().Equal(600, record.TTL)
+	s.Require().Contains(warning, "short's default of 10m instead of zonekit's 30m")
+
+	record, warning = service.ResolveTTL(dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1", TTL: 300})
+	s.Require().Equal(300, record.TTL)
+	s.Require().Empty(warning)
+}
+
+func (s *ServiceTestSuite) TestService_AddRecord_WritesDefaultTTL() {
+	mock := &defaultTTLProvider{mockProvider: newMockProvider("short"), defaultTTL: 600}
+	service := NewServiceWithProvider(mock)
+
+	s.Require().NoError(service.AddRecord("example.com", dnsrecord.Record{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1"}))
+	s.Require().NoError(service.AddRecord("example.com", dnsrecord.Record{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.2", TTL: 300}))
+	s.Require().NoError(service.UpdateRecord("example.com", "api", dnsrecord.RecordTypeA, dnsrecord.Record{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.3"}))
+
+	s.Require().Equal([]dnsrecord.Record{
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.1", TTL: 600},
+		{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.168.1.3", TTL: 600},
+	}, mock.records["example.com"])
+}
+
+func (s *ServiceTestSuite) TestService_PlanImport() {
+	s.mock.records["example.com"] = []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.1", TTL: 1800},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeCNAME, Address: "example.com.", TTL: 1800},
+		{HostName: "old", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.9", TTL: 1800},
+	}
+	imported := []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+		{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.3", TTL: 1800},
+	}
+
+	// Safe imports return the plan with the conflict so it can be shown
+	_, desired, err := s.service.PlanImport("example.com", imported, ImportSafe)
+	var conflict *zkerrors.ErrConflict
+	s.Require().ErrorAs(err, &conflict)
+	s.Require().Contains(err.Error(), "2 existing record set(s): @ A, www CNAME")
+	s.Require().Len(desired, 4)
+
+	_, desired, err = s.service.PlanImport("example.com", imported, ImportMerge)
+	s.Require().NoError(err)
+	s.Require().Equal(append([]dnsrecord.Record{
+		{HostName: "old", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.9", TTL: 1800},
+	}, imported...), desired)
+
+	_, desired, err = s.service.PlanImport("example.com", imported, ImportPrune)
+	s.Require().NoError(err)
+	s.Require().Equal(imported, desired)
+}
+
+func (s *ServiceTestSuite) TestService_PlanImport_SafeAddsNewRecords() {
+	existing := dnsrecord.Record{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.1", TTL: 1800}
+	s.mock.records["example.com"] = []dnsrecord.Record{existing}
+	imported := []dnsrecord.Record{
+		existing,
+		{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.3", TTL: 1800},
+	}
+
+	current, desired, err := s.service.PlanImport("example.com", imported, ImportSafe)
+	s.Require().NoError(err)
+	s.Require().Equal([]dnsrecord.Record{existing}, current)
+	s.Require().Equal(imported, desired)
+}
+
+func (s *ServiceTestSuite) TestBulkOperationsFor_RoundTrip() {
+	domain := testutil.ValidDomainFixture()
+	current := []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.1", TTL: 1800},
+		{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx1.example.com", TTL: 1800, MXPref: 10},
+		{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx2.example.com", TTL: 1800, MXPref: 20},
+		{HostName: "old", RecordType: dnsrecord.RecordTypeCNAME, Address: "example.com", TTL: 1800},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+	}
+	desired := []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.9", TTL: 1800},
+		{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx1.example.com", TTL: 1800, MXPref: 10},
+		{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx3.example.com", TTL: 1800, MXPref: 30},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.2", TTL: 1800},
+		{HostName: "api", RecordType: dnsrecord.RecordTypeA, Address: "192.0.2.3", TTL: 300},
+	}
+
+	operations := BulkOperationsFor(domain, current, desired)
+	actions := make([]string, len(operations))
+	for i, op := range operations {
+		actions[i] = op.Action + " " + op.Record.HostName + " " + op.Record.RecordType
+	}
+	s.Require().Equal([]string{
+		"delete @ MX", "delete old CNAME", "update @ A", "add @ MX", "add @ MX", "add api A",
+	}, actions)
+
+	// Applying the operations yields the desired records
+	s.mock.records[domain] = current
+	_, planned, err := s.service.PlanBulk(domain, operations)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch(desired, planned)
+
+	s.Require().Empty(BulkOperationsFor(domain, current, current))
+}
@@ -29,7 +29,8 @@ func ValidateHostname(hostname string) error {
 		return fmt.Errorf("hostname too long (max 253 characters)")
 	}
 
-	parts := strings.Split(hostname, ".")
+	// A trailing dot marks a fully qualified name
+	parts := strings.Split(strings.TrimSuffix(hostname, "."), ".")
 	for _, part := range parts {
 		if len(part) == 0 {
 			return fmt.Errorf("invalid hostname format: empty label")
@@ -42,6 +43,35 @@ func ValidateHostname(hostname string) error {
 	return nil
 }
 
+// ValidateTarget validates a hostname a record points at, such as an MX
+// exchange or CNAME target. It must be a name, not an IP address: an address
+// there is looked up as a hostname and never resolves.
+func ValidateTarget(target string) error {
+	if net.ParseIP(strings.TrimSuffix(target, ".")) != nil {
+		return fmt.Errorf("%s is an IP address, not a hostname; point at a name with an A or AAAA record", target)
+	}
+	return ValidateHostname(target)
+}
+
+// ValidateTXT validates a TXT value. Quoted strings, as in zone files, hold at
+// most 255 bytes each; an unquoted value is split into such strings by the
+// provider.
+func ValidateTXT(value string) error {
+	if len(value) > MaxTXTLength {
+		return fmt.Errorf("TXT value too long (%d bytes, max %d)", len(value), MaxTXTLength)
+	}
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, `"`) || !strings.HasSuffix(trimmed, `"`) || len(trimmed) < 2 {
+		return nil
+	}
+	for _, part := range strings.Split(trimmed[1:len(trimmed)-1], `" "`) {
+		if len(part) > MaxTXTStringLength {
+			return fmt.Errorf("TXT string too long (%d bytes, max %d); split it into quoted strings or leave it unquoted", len(part), MaxTXTStringLength)
+		}
+	}
+	return nil
+}
+
 // ValidateIPv4 validates an IPv4 address.
 func ValidateIPv4(ip string) error {
 	parsed := net.ParseIP(ip)
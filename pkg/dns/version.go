@@ -0,0 +1,170 @@
+package dns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+)
+
+// ZoneVersion identifies the state of a zone at a point in time
+type ZoneVersion struct {
+	// Serial is the SOA serial, 0 if the provider does not expose it
+	Serial uint32 `json:"serial,omitempty"`
+	// Hash is a digest of the zone's records, independent of record order
+	Hash string `json:"hash"`
+}
+
+// String returns the serial if known, otherwise a short form of the hash
+func (v ZoneVersion) String() string {
+	if v.Serial != 0 {
+		return fmt.Sprintf("serial %d", v.Serial)
+	}
+	if len(v.Hash) > 12 {
+		return "hash " + v.Hash[:12]
+	}
+	return "hash " + v.Hash
+}
+
+// Equal reports whether two versions describe the same zone content.
+// Serials are compared when both are known, hashes otherwise.
+func (v ZoneVersion) Equal(other ZoneVersion) bool {
+	if v.Serial != 0 && other.Serial != 0 {
+		return v.Serial == other.Serial
+	}
+	return v.Hash == other.Hash
+}
+
+// ZoneHash computes an order-independent SHA-256 digest of a set of records.
+// Provider record IDs are ignored so that the hash only reflects zone content.
+func ZoneHash(records []dnsrecord.Record) string {
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%d\t%d",
+			strings.ToLower(r.HostName), strings.ToUpper(r.RecordType), r.Address, r.TTL, r.MXPref))
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ZoneVersion returns the version of a zone given its current records, using the
+// provider's SOA serial when available
+func (s *Service) ZoneVersion(domainName string, records []dnsrecord.Record) ZoneVersion {
+	version := ZoneVersion{Hash: ZoneHash(records)}
+	if serial, err := s.ZoneSerial(domainName); err == nil {
+		version.Serial = serial
+	}
+	return version
+}
+
+// ZoneSerial returns the zone's SOA serial, which is much cheaper to read than
+// its records. It fails if the provider does not expose serials.
+func (s *Service) ZoneSerial(domainName string) (uint32, error) {
+	sp, ok := s.provider.(provider.SerialProvider)
+	if !ok {
+		return 0, fmt.Errorf("provider %s does not expose zone serials", s.provider.Name())
+	}
+	return sp.ZoneSerial(domainName)
+}
+
+// ZoneVersionRecord is a zone version as last seen locally
+type ZoneVersionRecord struct {
+	ZoneVersion
+	SeenAt time.Time `json:"seen_at"`
+	// ChangedAt is when a different version was first observed
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// VersionStore persists the last seen version of each zone so that later runs
+// can tell quickly whether anything changed
+type VersionStore struct {
+	state state.Store
+	mu    sync.Mutex
+	zones map[string]ZoneVersionRecord
+	dirty map[string]bool
+}
+
+// NewVersionStore loads the zone versions kept in a state store
+func NewVersionStore(st state.Store) (*VersionStore, error) {
+	store := &VersionStore{state: st, zones: make(map[string]ZoneVersionRecord), dirty: make(map[string]bool)}
+
+	items, err := st.List(state.BucketZones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zone versions: %w", err)
+	}
+	for _, item := range items {
+		var rec ZoneVersionRecord
+		if err := json.Unmarshal(item.Value, &rec); err != nil {
+			return nil, fmt.Errorf("invalid zone version %s: %w", item.Key, err)
+		}
+		store.zones[item.Key] = rec
+	}
+
+	return store, nil
+}
+
+// versionKey scopes versions by provider so the same domain on two providers is tracked separately
+func versionKey(providerName, domainName string) string {
+	return providerName + "/" + strings.ToLower(domainName)
+}
+
+// Get returns the last seen version of a zone
+func (s *VersionStore) Get(providerName, domainName string) (ZoneVersionRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.zones[versionKey(providerName, domainName)]
+	return rec, ok
+}
+
+// Observe records the current version of a zone and returns the previous record.
+// changed is true if a previous version was known and differs from the current one.
+func (s *VersionStore) Observe(providerName, domainName string, version ZoneVersion) (previous ZoneVersionRecord, known, changed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := versionKey(providerName, domainName)
+	previous, known = s.zones[key]
+	changed = known && !previous.Equal(version)
+
+	now := time.Now().UTC()
+	current := ZoneVersionRecord{ZoneVersion: version, SeenAt: now, ChangedAt: now}
+	if known && !changed {
+		current.ChangedAt = previous.ChangedAt
+	}
+	s.zones[key] = current
+	s.dirty[key] = true
+
+	return previous, known, changed
+}
+
+// Save writes the observed versions to the state store
+func (s *VersionStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.dirty))
+	for key := range s.dirty {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := s.state.Put(state.BucketZones, key, s.zones[key]); err != nil {
+			return fmt.Errorf("failed to write zone version: %w", err)
+		}
+		delete(s.dirty, key)
+	}
+
+	return nil
+}
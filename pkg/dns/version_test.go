@@ -0,0 +1,113 @@
+package dns
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+)
+
+// serialProvider is a mock provider that exposes an SOA serial
+type serialProvider struct {
+	*mockProvider
+	serial uint32
+}
+
+func (p *serialProvider) ZoneSerial(domainName string) (uint32, error) {
+	return p.serial, nil
+}
+
+// VersionTestSuite is a test suite for zone version tracking
+type VersionTestSuite struct {
+	suite.Suite
+}
+
+// TestVersionSuite runs the zone version test suite
+func TestVersionSuite(t *testing.T) {
+	suite.Run(t, new(VersionTestSuite))
+}
+
+func (s *VersionTestSuite) records() []dnsrecord.Record {
+	return []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 1800},
+	}
+}
+
+func (s *VersionTestSuite) TestZoneHash_OrderIndependent() {
+	records := s.records()
+	reversed := []dnsrecord.Record{records[1], records[0]}
+
+	s.Require().Equal(ZoneHash(records), ZoneHash(reversed))
+	s.Require().Len(ZoneHash(records), 64)
+}
+
+func (s *VersionTestSuite) TestZoneHash_IgnoresIDsButNotContent() {
+	records := s.records()
+	withIDs := s.records()
+	withIDs[0].ID = "123"
+	s.Require().Equal(ZoneHash(records), ZoneHash(withIDs))
+
+	changed := s.records()
+	changed[0].TTL = 300
+	s.Require().NotEqual(ZoneHash(records), ZoneHash(changed))
+}
+
+func (s *VersionTestSuite) TestService_ZoneVersion() {
+	hashOnly := NewServiceWithProvider(newMockProvider("mock")).ZoneVersion("example.com", s.records())
+	s.Require().Zero(hashOnly.Serial)
+	s.Require().Equal("hash "+hashOnly.Hash[:12], hashOnly.String())
+
+	withSerial := NewServiceWithProvider(&serialProvider{mockProvider: newMockProvider("pdns"), serial: 2026101601}).
+		ZoneVersion("example.com", s.records())
+	s.Require().Equal(uint32(2026101601), withSerial.Serial)
+	s.Require().Equal("serial 2026101601", withSerial.String())
+}
+
+func (s *VersionTestSuite) TestService_ZoneSerial() {
+	_, err := NewServiceWithProvider(newMockProvider("mock")).ZoneSerial("example.com")
+	s.Require().Error(err)
+
+	serial, err := NewServiceWithProvider(&serialProvider{mockProvider: newMockProvider("pdns"), serial: 7}).ZoneSerial("example.com")
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(7), serial)
+}
+
+func (s *VersionTestSuite) TestZoneVersion_Equal() {
+	s.Require().True(ZoneVersion{Serial: 5, Hash: "a"}.Equal(ZoneVersion{Serial: 5, Hash: "b"}))
+	s.Require().False(ZoneVersion{Serial: 5, Hash: "a"}.Equal(ZoneVersion{Serial: 6, Hash: "a"}))
+	s.Require().True(ZoneVersion{Hash: "a"}.Equal(ZoneVersion{Serial: 6, Hash: "a"}))
+}
+
+func (s *VersionTestSuite) TestVersionStore_Observe() {
+	st := state.NewFileStore(filepath.Join(s.T().TempDir(), "state.json"))
+	store, err := NewVersionStore(st)
+	s.Require().NoError(err)
+
+	v1 := ZoneVersion{Hash: ZoneHash(s.records())}
+	_, known, changed := store.Observe("namecheap", "Example.com", v1)
+	s.Require().False(known)
+	s.Require().False(changed)
+	s.Require().NoError(store.Save())
+
+	reloaded, err := NewVersionStore(st)
+	s.Require().NoError(err)
+
+	first, ok := reloaded.Get("namecheap", "example.com")
+	s.Require().True(ok)
+	s.Require().Equal(v1.Hash, first.Hash)
+
+	_, known, changed = reloaded.Observe("namecheap", "example.com", v1)
+	s.Require().True(known)
+	s.Require().False(changed)
+	unchanged, _ := reloaded.Get("namecheap", "example.com")
+	s.Require().Equal(first.ChangedAt, unchanged.ChangedAt)
+
+	_, _, changed = reloaded.Observe("namecheap", "example.com", ZoneVersion{Hash: "different"})
+	s.Require().True(changed)
+
+	_, known, _ = reloaded.Observe("cloudflare", "example.com", v1)
+	s.Require().False(known)
+}
@@ -0,0 +1,178 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// SPF limits of RFC 7208
+const (
+	// MaxSPFLookups is the number of DNS lookups an SPF check may make
+	MaxSPFLookups = 10
+	// MaxTXTStringLength is the length of a single TXT character string
+	MaxTXTStringLength = 255
+)
+
+// ChangeWarnings returns warnings about suspicious parts of changing a zone
+// from current to desired: removing the last MX records of a name, changing
+// the NS records at the apex, a new CNAME next to other records of its name
+// and SPF records that break RFC 7208 limits. The change is not refused; the
+// warnings are for a human to double-check it.
+func ChangeWarnings(domainName string, current, desired []dnsrecord.Record) []string {
+	before := groupByName(current)
+	after := groupByName(desired)
+
+	names := make([]string, 0, len(after))
+	for name := range after {
+		names = append(names, name)
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		was, will := before[name], after[name]
+		fqdn := qualifiedName(name, domainName)
+
+		if mx := was[dnsrecord.RecordTypeMX]; len(mx) > 0 && len(will[dnsrecord.RecordTypeMX]) == 0 {
+			what := "the only MX record"
+			if len(mx) > 1 {
+				what = fmt.Sprintf("all %d MX records", len(mx))
+			}
+			warnings = append(warnings, fmt.Sprintf("removes %s of %s; mail for it will no longer be delivered", what, fqdn))
+		}
+
+		if name == "@" && !sameValues(was[dnsrecord.RecordTypeNS], will[dnsrecord.RecordTypeNS]) {
+			warnings = append(warnings, fmt.Sprintf("changes the NS records at the apex of %s, which can take the whole zone offline", domainName))
+		}
+
+		if cnames := will[dnsrecord.RecordTypeCNAME]; len(cnames) > 0 && len(will) > 1 && !(len(was[dnsrecord.RecordTypeCNAME]) > 0 && len(was) > 1) {
+			warnings = append(warnings, fmt.Sprintf("adds a CNAME at %s next to its %s records; a CNAME cannot coexist with other records", fqdn, strings.Join(otherTypes(will), ", ")))
+		}
+
+		warnings = append(warnings, spfWarnings(fqdn, was[dnsrecord.RecordTypeTXT], will[dnsrecord.RecordTypeTXT])...)
+	}
+	return warnings
+}
+
+// spfWarnings checks the SPF records of a name that are new or changed
+func spfWarnings(fqdn string, was, will []dnsrecord.Record) []string {
+	existing := make(map[string]bool, len(was))
+	for _, r := range was {
+		existing[r.Address] = true
+	}
+
+	var spf []string
+	changed := false
+	for _, r := range will {
+		if !isSPF(r.Address) {
+			continue
+		}
+		spf = append(spf, r.Address)
+		if !existing[r.Address] {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	var warnings []string
+	if len(spf) > 1 {
+		warnings = append(warnings, fmt.Sprintf("%s has %d SPF records; receivers treat more than one as a permanent error", fqdn, len(spf)))
+	}
+	for _, value := range spf {
+		if lookups := spfLookups(value); lookups > MaxSPFLookups {
+			warnings = append(warnings, fmt.Sprintf("the SPF record of %s needs %d DNS lookups, more than the %d allowed", fqdn, lookups, MaxSPFLookups))
+		}
+		if len(value) > MaxTXTStringLength {
+			warnings = append(warnings, fmt.Sprintf("the SPF record of %s is %d characters long; TXT strings over %d characters must be split", fqdn, len(value), MaxTXTStringLength))
+		}
+	}
+	return warnings
+}
+
+// isSPF reports whether a TXT value is an SPF record
+func isSPF(value string) bool {
+	value = strings.ToLower(strings.Trim(value, `"`))
+	return value == "v=spf1" || strings.HasPrefix(value, "v=spf1 ")
+}
+
+// spfLookups counts the terms of an SPF record that cause DNS lookups
+func spfLookups(value string) int {
+	lookups := 0
+	for _, term := range strings.Fields(strings.ToLower(strings.Trim(value, `"`))) {
+		term = strings.TrimLeft(term, "+-~?")
+		mechanism, _, _ := strings.Cut(term, ":")
+		mechanism, _, _ = strings.Cut(mechanism, "/")
+		if name, _, ok := strings.Cut(term, "="); ok && name == "redirect" {
+			lookups++
+			continue
+		}
+		switch mechanism {
+		case "include", "a", "mx", "ptr", "exists":
+			lookups++
+		}
+	}
+	return lookups
+}
+
+// groupByName groups records by lower-cased hostname and then by type
+func groupByName(records []dnsrecord.Record) map[string]map[string][]dnsrecord.Record {
+	groups := make(map[string]map[string][]dnsrecord.Record)
+	for _, r := range records {
+		name := strings.ToLower(r.HostName)
+		if groups[name] == nil {
+			groups[name] = make(map[string][]dnsrecord.Record)
+		}
+		recordType := strings.ToUpper(r.RecordType)
+		groups[name][recordType] = append(groups[name][recordType], r)
+	}
+	return groups
+}
+
+// sameValues reports whether two record sets hold the same values
+func sameValues(a, b []dnsrecord.Record) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := make(map[string]int, len(a))
+	for _, r := range a {
+		values[strings.ToLower(strings.TrimSuffix(r.Address, "."))]++
+	}
+	for _, r := range b {
+		key := strings.ToLower(strings.TrimSuffix(r.Address, "."))
+		if values[key] == 0 {
+			return false
+		}
+		values[key]--
+	}
+	return true
+}
+
+// otherTypes returns the record types of a name other than CNAME, sorted
+func otherTypes(types map[string][]dnsrecord.Record) []string {
+	var others []string
+	for recordType := range types {
+		if recordType != dnsrecord.RecordTypeCNAME {
+			others = append(others, recordType)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// qualifiedName returns hostname as a name within domainName
+func qualifiedName(hostname, domainName string) string {
+	if hostname == "@" || hostname == "" {
+		return domainName
+	}
+	return hostname + "." + domainName
+}
@@ -0,0 +1,62 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeWarnings(t *testing.T) {
+	zone := []dnsrecord.Record{
+		testutil.A("@", "192.0.2.1"),
+		testutil.NS("@", "ns1.example.net."),
+		testutil.NS("@", "ns2.example.net."),
+		testutil.MX("@", 10, "mx.example.com."),
+		testutil.A("www", "192.0.2.2"),
+		testutil.TXT("@", "v=spf1 include:_spf.example.net -all"),
+	}
+	without := func(recordType string) []dnsrecord.Record {
+		var kept []dnsrecord.Record
+		for _, r := range zone {
+			if r.RecordType != recordType {
+				kept = append(kept, r)
+			}
+		}
+		return kept
+	}
+
+	tests := []struct {
+		name    string
+		desired []dnsrecord.Record
+		want    []string
+	}{
+		{"unchanged", zone, nil},
+		{"only MX removed", without("MX"), []string{"removes the only MX record of example.com"}},
+		{"apex NS changed", append(without("NS"), testutil.NS("@", "ns1.other.net.")), []string{"changes the NS records at the apex"}},
+		{"NS spelling", append(without("NS"), testutil.NS("@", "NS1.example.net"), testutil.NS("@", "ns2.example.net")), nil},
+		{"CNAME next to A", append(zone, testutil.CNAME("www", "example.com.")), []string{"adds a CNAME at www.example.com next to its A records"}},
+		{"two SPF records", append(zone, testutil.TXT("@", "v=spf1 mx -all")), []string{"example.com has 2 SPF records"}},
+		{"SPF lookups", append(without("TXT"), testutil.TXT("@", "v=spf1 a mx ptr include:a.example include:b.example include:c.example include:d.example include:e.example exists:%{i}.example a:x.example redirect=f.example")), []string{"needs 11 DNS lookups"}},
+		{"SPF length", append(without("TXT"), testutil.TXT("@", "v=spf1 ip4:192.0.2.1"+strings.Repeat(" ip4:192.0.2.1", 20)+" -all")), []string{"TXT strings over 255 characters must be split"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := ChangeWarnings("example.com", zone, tt.desired)
+			require.Len(t, warnings, len(tt.want), warnings)
+			for i, want := range tt.want {
+				require.Contains(t, warnings[i], want)
+			}
+		})
+	}
+}
+
+func TestChangeWarnings_ExistingSPFIsNotRechecked(t *testing.T) {
+	spf := testutil.TXT("@", "v=spf1 "+strings.Repeat("include:x.example ", 11)+"-all")
+	current := []dnsrecord.Record{spf}
+	desired := []dnsrecord.Record{spf, testutil.A("www", "192.0.2.1")}
+	require.Empty(t, ChangeWarnings("example.com", current, desired))
+}
@@ -0,0 +1,64 @@
+package dnsrecord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CAA holds the fields of a CAA record. Records keep them in Address in zone
+// file order, `flags tag "value"`.
+type CAA struct {
+	Flags int    `json:"flags"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// CAA property tags (RFC 8659)
+const (
+	CAATagIssue     = "issue"
+	CAATagIssueWild = "issuewild"
+	CAATagIodef     = "iodef"
+)
+
+// ParseCAA parses a CAA value such as `0 issue "letsencrypt.org"`. The tag must
+// be issue, issuewild or iodef, and an iodef value a mailto:, http: or https:
+// URL. The value may be quoted.
+func ParseCAA(value string) (CAA, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return CAA{}, fmt.Errorf("invalid CAA value %q: expected flags, tag and value", value)
+	}
+
+	flags, err := strconv.Atoi(fields[0])
+	if err != nil || flags < 0 || flags > 255 {
+		return CAA{}, fmt.Errorf("invalid CAA flags %q: must be a number from 0 to 255", fields[0])
+	}
+
+	caa := CAA{Flags: flags, Tag: strings.ToLower(fields[1])}
+	switch caa.Tag {
+	case CAATagIssue, CAATagIssueWild, CAATagIodef:
+	default:
+		return CAA{}, fmt.Errorf("invalid CAA tag %q: must be %s, %s or %s", fields[1], CAATagIssue, CAATagIssueWild, CAATagIodef)
+	}
+
+	// The value is the rest of the record, which may contain spaces when quoted
+	rest := strings.TrimSpace(value)
+	for i := 0; i < 2; i++ {
+		rest = strings.TrimSpace(rest[len(strings.Fields(rest)[0]):])
+	}
+	caa.Value = strings.TrimSuffix(strings.TrimPrefix(rest, `"`), `"`)
+
+	if caa.Tag == CAATagIodef {
+		lower := strings.ToLower(caa.Value)
+		if !strings.HasPrefix(lower, "mailto:") && !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			return CAA{}, fmt.Errorf("invalid CAA iodef value %q: must be a mailto:, http: or https: URL", caa.Value)
+		}
+	}
+	return caa, nil
+}
+
+// String returns the value in `flags tag "value"` form
+func (c CAA) String() string {
+	return fmt.Sprintf("%d %s %q", c.Flags, c.Tag, c.Value)
+}
@@ -0,0 +1,27 @@
+package dnsrecord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCAA(t *testing.T) {
+	caa, err := ParseCAA(`0 issue "letsencrypt.org"`)
+	require.NoError(t, err)
+	require.Equal(t, CAA{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}, caa)
+	require.Equal(t, `0 issue "letsencrypt.org"`, caa.String())
+
+	caa, err = ParseCAA(`128 IODEF "mailto:security@example.com"`)
+	require.NoError(t, err)
+	require.Equal(t, CAA{Flags: 128, Tag: "iodef", Value: "mailto:security@example.com"}, caa)
+
+	caa, err = ParseCAA(`0 issue "ca.example.net; account=12 3"`)
+	require.NoError(t, err)
+	require.Equal(t, "ca.example.net; account=12 3", caa.Value)
+
+	for _, value := range []string{"", "0 issue", `256 issue "ca.example.net"`, `0 issuer "ca.example.net"`, `0 iodef "security@example.com"`} {
+		_, err := ParseCAA(value)
+		require.Error(t, err, value)
+	}
+}
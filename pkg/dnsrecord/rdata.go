@@ -0,0 +1,288 @@
+package dnsrecord
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The fields of TLSA, SSHFP, DS and NAPTR records. Records keep them in
+// Address in zone file (presentation) order; Parse* checks a value and String
+// returns it in canonical form, with hex data in lower case and unsplit.
+
+// TLSA binds a TLS certificate or public key to a service (RFC 6698), e.g.
+// "3 1 1 <sha-256 hex>" at _443._tcp.www
+type TLSA struct {
+	Usage        int    `json:"usage"`
+	Selector     int    `json:"selector"`
+	MatchingType int    `json:"matching_type"`
+	Data         string `json:"data"`
+}
+
+// SSHFP publishes an SSH host key fingerprint (RFC 4255), e.g. "4 2 <sha-256 hex>"
+type SSHFP struct {
+	Algorithm   int    `json:"algorithm"`
+	Type        int    `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// DS delegates a DNSSEC signing key to a child zone (RFC 4034), e.g.
+// "12345 13 2 <sha-256 hex>"
+type DS struct {
+	KeyTag     int    `json:"key_tag"`
+	Algorithm  int    `json:"algorithm"`
+	DigestType int    `json:"digest_type"`
+	Digest     string `json:"digest"`
+}
+
+// NAPTR rewrites a name into a service URI or replacement (RFC 3403), e.g.
+// `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`
+type NAPTR struct {
+	Order       int    `json:"order"`
+	Preference  int    `json:"preference"`
+	Flags       string `json:"flags"`
+	Service     string `json:"service"`
+	Regexp      string `json:"regexp"`
+	Replacement string `json:"replacement"`
+}
+
+// tlsaDataSizes are the data sizes in bytes of the TLSA matching types that
+// hash the certificate: SHA-256 and SHA-512
+var tlsaDataSizes = map[int]int{1: 32, 2: 64}
+
+// sshfpSizes are the fingerprint sizes of the SSHFP types SHA-1 and SHA-256
+var sshfpSizes = map[int]int{1: 20, 2: 32}
+
+// sshfpAlgorithms are the SSHFP key algorithms: RSA, DSA, ECDSA, Ed25519 and Ed448
+var sshfpAlgorithms = map[int]bool{1: true, 2: true, 3: true, 4: true, 6: true}
+
+// dsDigestSizes are the digest sizes of the DS digest types SHA-1, SHA-256,
+// GOST R 34.11-94 and SHA-384
+var dsDigestSizes = map[int]int{1: 20, 2: 32, 3: 32, 4: 48}
+
+// ParseTLSA parses a TLSA value such as "3 1 1 0123...cdef"
+func ParseTLSA(value string) (TLSA, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 4 {
+		return TLSA{}, fmt.Errorf("invalid TLSA value %q: expected usage, selector, matching type and data", value)
+	}
+	usage, err := parseField("TLSA usage", fields[0], 0, 3)
+	if err != nil {
+		return TLSA{}, err
+	}
+	selector, err := parseField("TLSA selector", fields[1], 0, 1)
+	if err != nil {
+		return TLSA{}, err
+	}
+	matchingType, err := parseField("TLSA matching type", fields[2], 0, 2)
+	if err != nil {
+		return TLSA{}, err
+	}
+	data, err := parseHex("TLSA data", fields[3:], tlsaDataSizes[matchingType])
+	if err != nil {
+		return TLSA{}, err
+	}
+	return TLSA{Usage: usage, Selector: selector, MatchingType: matchingType, Data: data}, nil
+}
+
+// String returns the value in "usage selector matching-type data" form
+func (t TLSA) String() string {
+	return fmt.Sprintf("%d %d %d %s", t.Usage, t.Selector, t.MatchingType, t.Data)
+}
+
+// ParseSSHFP parses an SSHFP value such as "4 2 0123...cdef"
+func ParseSSHFP(value string) (SSHFP, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return SSHFP{}, fmt.Errorf("invalid SSHFP value %q: expected algorithm, type and fingerprint", value)
+	}
+	algorithm, err := parseField("SSHFP algorithm", fields[0], 1, 6)
+	if err != nil {
+		return SSHFP{}, err
+	}
+	if !sshfpAlgorithms[algorithm] {
+		return SSHFP{}, fmt.Errorf("invalid SSHFP algorithm %d: must be 1 (RSA), 2 (DSA), 3 (ECDSA), 4 (Ed25519) or 6 (Ed448)", algorithm)
+	}
+	fpType, err := parseField("SSHFP type", fields[1], 1, 2)
+	if err != nil {
+		return SSHFP{}, err
+	}
+	fingerprint, err := parseHex("SSHFP fingerprint", fields[2:], sshfpSizes[fpType])
+	if err != nil {
+		return SSHFP{}, err
+	}
+	return SSHFP{Algorithm: algorithm, Type: fpType, Fingerprint: fingerprint}, nil
+}
+
+// String returns the value in "algorithm type fingerprint" form
+func (s SSHFP) String() string {
+	return fmt.Sprintf("%d %d %s", s.Algorithm, s.Type, s.Fingerprint)
+}
+
+// ParseDS parses a DS value such as "12345 13 2 0123...cdef"
+func ParseDS(value string) (DS, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 4 {
+		return DS{}, fmt.Errorf("invalid DS value %q: expected key tag, algorithm, digest type and digest", value)
+	}
+	keyTag, err := parseField("DS key tag", fields[0], 0, 65535)
+	if err != nil {
+		return DS{}, err
+	}
+	algorithm, err := parseField("DS algorithm", fields[1], 1, 255)
+	if err != nil {
+		return DS{}, err
+	}
+	digestType, err := parseField("DS digest type", fields[2], 1, 255)
+	if err != nil {
+		return DS{}, err
+	}
+	size, ok := dsDigestSizes[digestType]
+	if !ok {
+		return DS{}, fmt.Errorf("invalid DS digest type %d: must be 1 (SHA-1), 2 (SHA-256), 3 (GOST) or 4 (SHA-384)", digestType)
+	}
+	digest, err := parseHex("DS digest", fields[3:], size)
+	if err != nil {
+		return DS{}, err
+	}
+	return DS{KeyTag: keyTag, Algorithm: algorithm, DigestType: digestType, Digest: digest}, nil
+}
+
+// String returns the value in "key-tag algorithm digest-type digest" form
+func (d DS) String() string {
+	return fmt.Sprintf("%d %d %d %s", d.KeyTag, d.Algorithm, d.DigestType, d.Digest)
+}
+
+// ParseNAPTR parses a NAPTR value such as
+// `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`. A rule either
+// rewrites with a regular expression or replaces the name, so exactly one of
+// regexp and replacement (".") is empty.
+func ParseNAPTR(value string) (NAPTR, error) {
+	fields, err := splitStrings(value)
+	if err != nil {
+		return NAPTR{}, fmt.Errorf("invalid NAPTR value %q: %w", value, err)
+	}
+	if len(fields) != 6 {
+		return NAPTR{}, fmt.Errorf(`invalid NAPTR value %q: expected order, preference, "flags", "service", "regexp" and replacement`, value)
+	}
+	order, err := parseField("NAPTR order", fields[0], 0, 65535)
+	if err != nil {
+		return NAPTR{}, err
+	}
+	preference, err := parseField("NAPTR preference", fields[1], 0, 65535)
+	if err != nil {
+		return NAPTR{}, err
+	}
+	n := NAPTR{Order: order, Preference: preference, Flags: fields[2], Service: fields[3], Regexp: fields[4], Replacement: fields[5]}
+
+	for _, c := range n.Flags {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return NAPTR{}, fmt.Errorf("invalid NAPTR flags %q: only letters and digits are allowed", n.Flags)
+		}
+	}
+	if (n.Regexp == "") == (n.Replacement == ".") {
+		return NAPTR{}, fmt.Errorf("invalid NAPTR value %q: give either a regexp or a replacement other than \".\"", value)
+	}
+	return n, nil
+}
+
+// String returns the value in presentation form, with the flags, service and
+// regexp quoted
+func (n NAPTR) String() string {
+	return fmt.Sprintf("%d %d %s %s %s %s", n.Order, n.Preference, quote(n.Flags), quote(n.Service), quote(n.Regexp), n.Replacement)
+}
+
+// CanonicalValue returns a TLSA, SSHFP, DS or NAPTR value in canonical form, so
+// that values written differently by providers and zone files compare equal.
+// Other values, and values that do not parse, are returned unchanged.
+func CanonicalValue(recordType, value string) string {
+	if canonical, err := ParseValue(recordType, value); err == nil {
+		return canonical
+	}
+	return value
+}
+
+// ParseValue checks a TLSA, SSHFP, DS or NAPTR value and returns it in
+// canonical form. Values of other types are returned unchanged.
+func ParseValue(recordType, value string) (string, error) {
+	var canonical fmt.Stringer
+	var err error
+	switch strings.ToUpper(recordType) {
+	case RecordTypeTLSA:
+		canonical, err = ParseTLSA(value)
+	case RecordTypeSSHFP:
+		canonical, err = ParseSSHFP(value)
+	case RecordTypeDS:
+		canonical, err = ParseDS(value)
+	case RecordTypeNAPTR:
+		canonical, err = ParseNAPTR(value)
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return canonical.String(), nil
+}
+
+// parseField parses a numeric field within [min, max]
+func parseField(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid %s %q: must be a number from %d to %d", name, value, min, max)
+	}
+	return n, nil
+}
+
+// parseHex joins hex data split across fields and checks it holds size bytes,
+// or any number of bytes when size is 0
+func parseHex(name string, fields []string, size int) (string, error) {
+	data := strings.ToLower(strings.Join(fields, ""))
+	decoded, err := hex.DecodeString(data)
+	if err != nil || len(decoded) == 0 {
+		return "", fmt.Errorf("invalid %s %q: must be hexadecimal", name, data)
+	}
+	if size > 0 && len(decoded) != size {
+		return "", fmt.Errorf("invalid %s: expected %d bytes (%d hex digits), got %d", name, size, 2*size, len(decoded))
+	}
+	return data, nil
+}
+
+// splitStrings splits a value into fields, reading quoted character strings
+// with their \X escapes as one field
+func splitStrings(value string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(value); {
+		switch c := value[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				sb.WriteByte(value[i])
+			}
+			if i == len(value) {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			fields = append(fields, sb.String())
+			i++
+		default:
+			start := i
+			for i < len(value) && value[i] != ' ' && value[i] != '\t' {
+				i++
+			}
+			fields = append(fields, value[start:i])
+		}
+	}
+	return fields, nil
+}
+
+// quote returns s as a quoted character string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,74 @@
+package dnsrecord
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var sha256Hex = strings.Repeat("ab", 32)
+
+func TestParseTLSA(t *testing.T) {
+	tlsa, err := ParseTLSA("3 1 1 " + strings.ToUpper(sha256Hex[:32]) + " " + sha256Hex[32:])
+	require.NoError(t, err)
+	require.Equal(t, TLSA{Usage: 3, Selector: 1, MatchingType: 1, Data: sha256Hex}, tlsa)
+	require.Equal(t, "3 1 1 "+sha256Hex, tlsa.String())
+
+	for _, value := range []string{"3 1 1", "4 1 1 " + sha256Hex, "3 1 1 abcd", "3 1 0 xyz"} {
+		_, err := ParseTLSA(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseSSHFP(t *testing.T) {
+	sshfp, err := ParseSSHFP("4 2 " + sha256Hex)
+	require.NoError(t, err)
+	require.Equal(t, SSHFP{Algorithm: 4, Type: 2, Fingerprint: sha256Hex}, sshfp)
+
+	for _, value := range []string{"5 2 " + sha256Hex, "4 1 " + sha256Hex, "4 2"} {
+		_, err := ParseSSHFP(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseDS(t *testing.T) {
+	ds, err := ParseDS("12345 13 2 " + sha256Hex)
+	require.NoError(t, err)
+	require.Equal(t, DS{KeyTag: 12345, Algorithm: 13, DigestType: 2, Digest: sha256Hex}, ds)
+	require.Equal(t, "12345 13 2 "+sha256Hex, ds.String())
+
+	for _, value := range []string{"70000 13 2 " + sha256Hex, "12345 13 5 " + sha256Hex, "12345 13 1 " + sha256Hex} {
+		_, err := ParseDS(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseNAPTR(t *testing.T) {
+	naptr, err := ParseNAPTR(`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`)
+	require.NoError(t, err)
+	require.Equal(t, NAPTR{Order: 100, Preference: 10, Flags: "U", Service: "E2U+sip", Regexp: "!^.*$!sip:info@example.com!", Replacement: "."}, naptr)
+	require.Equal(t, `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`, naptr.String())
+
+	naptr, err = ParseNAPTR(`10 0 "s" "SIP+D2U" "" _sip._udp.example.com.`)
+	require.NoError(t, err)
+	require.Equal(t, "_sip._udp.example.com.", naptr.Replacement)
+
+	for _, value := range []string{
+		`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" sip.example.com.`,
+		`100 10 "U" "E2U+sip" "" .`,
+		`100 10 "U!" "E2U+sip" "" sip.example.com.`,
+		`100 10 "U" "E2U+sip`,
+		`100 10 "U" .`,
+	} {
+		_, err := ParseNAPTR(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	require.Equal(t, "12345 13 2 "+sha256Hex, CanonicalValue("ds", "12345  13 2 "+strings.ToUpper(sha256Hex)))
+	require.Equal(t, `10 0 "s" "SIP+D2U" "" sip.example.com.`, CanonicalValue(RecordTypeNAPTR, `10 0 s SIP+D2U "" sip.example.com.`))
+	require.Equal(t, "not a digest", CanonicalValue(RecordTypeDS, "not a digest"))
+	require.Equal(t, "Example.com.", CanonicalValue(RecordTypeCNAME, "Example.com."))
+}
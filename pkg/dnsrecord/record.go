@@ -1,5 +1,7 @@
 package dnsrecord
 
+import "strings"
+
 // Record represents a DNS record
 type Record struct {
 	ID         string
@@ -8,6 +10,25 @@ type Record struct {
 	Address    string
 	TTL        int
 	MXPref     int
+	// Comment and Tags annotate the record. Providers that support it store
+	// them with the record; for the others zonekit keeps them in local state.
+	Comment string
+	Tags    []string
+}
+
+// HasTag reports whether the record is tagged with tag (case-insensitive)
+func (r Record) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Annotated reports whether the record has a comment or tags
+func (r Record) Annotated() bool {
+	return r.Comment != "" || len(r.Tags) > 0
 }
 
 // RecordType constants
@@ -19,4 +40,16 @@ const (
 	RecordTypeTXT   = "TXT"
 	RecordTypeNS    = "NS"
 	RecordTypeSRV   = "SRV"
+	RecordTypeCAA   = "CAA"
+	RecordTypePTR   = "PTR"
+	RecordTypeTLSA  = "TLSA"
+	RecordTypeSSHFP = "SSHFP"
+	RecordTypeNAPTR = "NAPTR"
+	RecordTypeDS    = "DS"
+
+	// URL redirects served by Namecheap's DNS: a temporary (302) redirect, a
+	// permanent (301) one and a masked one that frames the target
+	RecordTypeURL    = "URL"
+	RecordTypeURL301 = "URL301"
+	RecordTypeFRAME  = "FRAME"
 )
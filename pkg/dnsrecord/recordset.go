@@ -0,0 +1,111 @@
+package dnsrecord
+
+import "strings"
+
+// RecordSet is all records of one hostname and type (an RRset). DNS serves a
+// set as a whole and its records share a TTL; providers such as Gandi and
+// deSEC store records as sets and write a set at once.
+type RecordSet struct {
+	HostName   string
+	RecordType string
+	TTL        int
+	Records    []Record
+}
+
+// SetKey identifies a record set: its hostname and type, in lower and upper
+// case respectively
+type SetKey struct {
+	HostName   string
+	RecordType string
+}
+
+// SetKeyOf returns the key of the record set a record belongs to
+func SetKeyOf(r Record) SetKey {
+	return SetKey{HostName: strings.ToLower(r.HostName), RecordType: strings.ToUpper(r.RecordType)}
+}
+
+// Key returns the key of the set
+func (s RecordSet) Key() SetKey {
+	return SetKey{HostName: strings.ToLower(s.HostName), RecordType: strings.ToUpper(s.RecordType)}
+}
+
+// Contains reports whether the set holds a record with the value of r
+func (s RecordSet) Contains(r Record) bool {
+	return s.index(r) >= 0
+}
+
+// Add adds r to the set. It reports false, leaving the set unchanged, if r is
+// of another hostname or type or the set already holds its value. The set
+// takes the TTL of the first record with one.
+func (s *RecordSet) Add(r Record) bool {
+	if SetKeyOf(r) != s.Key() || s.Contains(r) {
+		return false
+	}
+	s.Records = append(s.Records, r)
+	if s.TTL == 0 {
+		s.TTL = r.TTL
+	}
+	return true
+}
+
+// Remove removes the record with the value of r from the set and reports
+// whether there was one
+func (s *RecordSet) Remove(r Record) bool {
+	i := s.index(r)
+	if i < 0 || SetKeyOf(r) != s.Key() {
+		return false
+	}
+	s.Records = append(s.Records[:i:i], s.Records[i+1:]...)
+	return true
+}
+
+// index returns the position of the record with the value of r, or -1
+func (s RecordSet) index(r Record) int {
+	for i, member := range s.Records {
+		if sameValue(member, r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameValue reports whether two records of a set hold the same value. Host
+// names in values compare without case and trailing dot; TXT values exactly.
+func sameValue(a, b Record) bool {
+	if a.MXPref != b.MXPref {
+		return false
+	}
+	x := CanonicalValue(a.RecordType, strings.TrimSpace(a.Address))
+	y := CanonicalValue(b.RecordType, strings.TrimSpace(b.Address))
+	if strings.EqualFold(a.RecordType, RecordTypeTXT) {
+		return x == y
+	}
+	return strings.EqualFold(strings.TrimSuffix(x, "."), strings.TrimSuffix(y, "."))
+}
+
+// GroupRecordSets groups records into sets by hostname and type, in the order
+// each set first appears. Records with the same value are kept once.
+func GroupRecordSets(records []Record) []RecordSet {
+	var sets []RecordSet
+	index := make(map[SetKey]int)
+	for _, r := range records {
+		key := SetKeyOf(r)
+		i, ok := index[key]
+		if !ok {
+			i = len(sets)
+			index[key] = i
+			sets = append(sets, RecordSet{HostName: r.HostName, RecordType: key.RecordType})
+		}
+		sets[i].Add(r)
+	}
+	return sets
+}
+
+// FlattenRecordSets returns the records of sets, in order
+func FlattenRecordSets(sets []RecordSet) []Record {
+	var records []Record
+	for _, set := range sets {
+		records = append(records, set.Records...)
+	}
+	return records
+}
@@ -0,0 +1,51 @@
+package dnsrecord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSet_AddRemove(t *testing.T) {
+	set := RecordSet{HostName: "@", RecordType: RecordTypeMX}
+
+	require.True(t, set.Add(Record{HostName: "@", RecordType: "mx", Address: "mx1.example.com.", MXPref: 10, TTL: 3600}))
+	require.True(t, set.Add(Record{HostName: "@", RecordType: "MX", Address: "mx2.example.com", MXPref: 20}))
+	require.Equal(t, 3600, set.TTL)
+
+	// The same value, another preference, another name or type
+	require.False(t, set.Add(Record{HostName: "@", RecordType: "MX", Address: "MX1.example.com", MXPref: 10}))
+	require.True(t, set.Contains(Record{HostName: "@", RecordType: "MX", Address: "mx2.example.com.", MXPref: 20}))
+	require.False(t, set.Contains(Record{HostName: "@", RecordType: "MX", Address: "mx2.example.com", MXPref: 30}))
+	require.False(t, set.Add(Record{HostName: "www", RecordType: "MX", Address: "mx3.example.com", MXPref: 10}))
+	require.False(t, set.Add(Record{HostName: "@", RecordType: "A", Address: "192.0.2.1"}))
+	require.Len(t, set.Records, 2)
+
+	require.True(t, set.Remove(Record{HostName: "@", RecordType: "MX", Address: "mx1.example.com", MXPref: 10}))
+	require.False(t, set.Remove(Record{HostName: "@", RecordType: "MX", Address: "mx1.example.com", MXPref: 10}))
+	require.Equal(t, []Record{{HostName: "@", RecordType: "MX", Address: "mx2.example.com", MXPref: 20}}, set.Records)
+}
+
+func TestRecordSet_TXTIsCaseSensitive(t *testing.T) {
+	set := RecordSet{HostName: "@", RecordType: RecordTypeTXT}
+	require.True(t, set.Add(Record{HostName: "@", RecordType: "TXT", Address: "token=abc"}))
+	require.True(t, set.Add(Record{HostName: "@", RecordType: "TXT", Address: "token=ABC"}))
+}
+
+func TestGroupRecordSets(t *testing.T) {
+	records := []Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+		{HostName: "@", RecordType: "a", Address: "192.0.2.2", TTL: 600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+	}
+
+	sets := GroupRecordSets(records)
+	require.Len(t, sets, 2)
+	require.Equal(t, SetKey{HostName: "@", RecordType: "A"}, sets[0].Key())
+	require.Equal(t, 600, sets[0].TTL)
+	require.Len(t, sets[0].Records, 2)
+	require.Equal(t, SetKey{HostName: "www", RecordType: "CNAME"}, sets[1].Key())
+
+	require.Equal(t, []Record{records[0], records[2], records[1]}, FlattenRecordSets(sets))
+}
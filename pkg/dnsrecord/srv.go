@@ -0,0 +1,39 @@
+package dnsrecord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SRV holds the fields of an SRV record. Records keep them in Address in
+// zone file order, "priority weight port target".
+type SRV struct {
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+	Target   string `json:"target"`
+}
+
+// ParseSRV parses an SRV value such as "10 5 5060 sip.example.com"
+func ParseSRV(value string) (SRV, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return SRV{}, fmt.Errorf("invalid SRV value %q: expected priority, weight, port and target", value)
+	}
+
+	var numbers [3]int
+	for i, name := range []string{"priority", "weight", "port"} {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil || n < 0 || n > 65535 {
+			return SRV{}, fmt.Errorf("invalid SRV %s %q: must be a number from 0 to 65535", name, fields[i])
+		}
+		numbers[i] = n
+	}
+	return SRV{Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Target: fields[3]}, nil
+}
+
+// String returns the value in "priority weight port target" form
+func (s SRV) String() string {
+	return fmt.Sprintf("%d %d %d %s", s.Priority, s.Weight, s.Port, s.Target)
+}
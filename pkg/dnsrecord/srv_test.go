@@ -0,0 +1,19 @@
+package dnsrecord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSRV(t *testing.T) {
+	srv, err := ParseSRV("  10 5   5060 sip.example.com. ")
+	require.NoError(t, err)
+	require.Equal(t, SRV{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."}, srv)
+	require.Equal(t, "10 5 5060 sip.example.com.", srv.String())
+
+	for _, value := range []string{"", "10 5 5060", "10 5 5060 a b", "x 5 5060 sip.example.com", "10 5 70000 sip.example.com", "-1 5 5060 sip.example.com"} {
+		_, err := ParseSRV(value)
+		require.Error(t, err, value)
+	}
+}
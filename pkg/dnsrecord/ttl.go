@@ -0,0 +1,110 @@
+package dnsrecord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ttlUnits are the duration units accepted and produced for TTLs, largest first
+var ttlUnits = []struct {
+	suffix  byte
+	seconds int
+}{
+	{'w', 7 * 24 * 3600},
+	{'d', 24 * 3600},
+	{'h', 3600},
+	{'m', 60},
+	{'s', 1},
+}
+
+// ParseTTL parses a TTL given in seconds ("300") or as a duration made of
+// w/d/h/m/s units ("30m", "1h", "1d", "1h30m") and returns it in seconds
+func ParseTTL(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("invalid TTL %q: must not be negative", value)
+		}
+		return seconds, nil
+	}
+
+	total := 0
+	rest := value
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, fmt.Errorf("invalid TTL %q: use seconds or a duration like 30m, 1h, 1d", value)
+		}
+
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid TTL %q: %w", value, err)
+		}
+
+		unit := 0
+		for _, u := range ttlUnits {
+			if rest[i] == u.suffix {
+				unit = u.seconds
+				break
+			}
+		}
+		if unit == 0 {
+			return 0, fmt.Errorf("invalid TTL %q: unknown unit %q", value, rest[i])
+		}
+
+		total += n * unit
+		rest = rest[i+1:]
+	}
+
+	return total, nil
+}
+
+// FormatTTL renders a TTL in seconds as a compact duration such as "30m", "1h",
+// "1d" or "1h30m". Zero (the provider default) renders as an empty string.
+func FormatTTL(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, u := range ttlUnits {
+		// Weeks only when exact, so 10 days reads "10d" rather than "1w3d"
+		if u.suffix == 'w' && seconds%u.seconds != 0 {
+			continue
+		}
+		if n := seconds / u.seconds; n > 0 {
+			fmt.Fprintf(&sb, "%d%c", n, u.suffix)
+			seconds -= n * u.seconds
+		}
+	}
+	return sb.String()
+}
+
+// TTL is a TTL in seconds that can be written in YAML as a number of seconds or
+// as a duration string such as "30m", "1h" or "1d"
+type TTL int
+
+// UnmarshalYAML accepts both integer seconds and duration strings
+func (t *TTL) UnmarshalYAML(value *yaml.Node) error {
+	seconds, err := ParseTTL(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*t = TTL(seconds)
+	return nil
+}
+
+// String returns the TTL as a compact duration
+func (t TTL) String() string {
+	return FormatTTL(int(t))
+}
@@ -0,0 +1,74 @@
+package dnsrecord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+// TTLTestSuite is a test suite for TTL parsing and formatting
+type TTLTestSuite struct {
+	suite.Suite
+}
+
+// TestTTLSuite runs the TTL test suite
+func TestTTLSuite(t *testing.T) {
+	suite.Run(t, new(TTLTestSuite))
+}
+
+func (s *TTLTestSuite) TestParseTTL() {
+	tests := map[string]int{
+		"":      0,
+		"300":   300,
+		"45s":   45,
+		"30m":   1800,
+		"1h":    3600,
+		"1H":    3600,
+		"1d":    86400,
+		"1w":    604800,
+		"1h30m": 5400,
+	}
+	for input, expected := range tests {
+		seconds, err := ParseTTL(input)
+		s.Require().NoError(err, input)
+		s.Require().Equal(expected, seconds, input)
+	}
+
+	for _, input := range []string{"-5", "h", "1x", "1h30", "abc"} {
+		_, err := ParseTTL(input)
+		s.Require().Error(err, input)
+	}
+}
+
+func (s *TTLTestSuite) TestFormatTTL() {
+	tests := map[int]string{
+		0:       "",
+		45:      "45s",
+		300:     "5m",
+		1800:    "30m",
+		3600:    "1h",
+		5400:    "1h30m",
+		86400:   "1d",
+		604800:  "1w",
+		864000:  "10d",
+		90061:   "1d1h1m1s",
+		1209600: "2w",
+	}
+	for seconds, expected := range tests {
+		s.Require().Equal(expected, FormatTTL(seconds), seconds)
+	}
+}
+
+func (s *TTLTestSuite) TestTTL_UnmarshalYAML() {
+	var input struct {
+		A TTL `yaml:"a"`
+		B TTL `yaml:"b"`
+	}
+	s.Require().NoError(yaml.Unmarshal([]byte("a: 300\nb: 1h\n"), &input))
+	s.Require().Equal(TTL(300), input.A)
+	s.Require().Equal(TTL(3600), input.B)
+	s.Require().Equal("1h", input.B.String())
+
+	s.Require().Error(yaml.Unmarshal([]byte("a: soon\n"), &input))
+}
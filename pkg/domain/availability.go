@@ -0,0 +1,270 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PopularTLDs is the built-in set of TLDs used when suggesting domains
+var PopularTLDs = []string{"com", "net", "org", "io", "dev", "app", "co", "ai"}
+
+// maxConcurrentChecks bounds the number of parallel availability requests
+const maxConcurrentChecks = 5
+
+// Availability represents the availability of a single domain name
+type Availability struct {
+	Domain    string
+	Available bool
+	IsPremium bool
+	// Price is the one-year registration price, 0 if unknown
+	Price    float64
+	Currency string
+	// PremiumRegistrationPrice and PremiumRenewalPrice are the yearly prices of
+	// a premium name, 0 for other names
+	PremiumRegistrationPrice float64
+	PremiumRenewalPrice      float64
+	// EAPFee is the fee charged on top of the price while the TLD is in its
+	// Early Access Period, and ICANNFee is ICANN's yearly registration fee
+	EAPFee   float64
+	ICANNFee float64
+	Err      error
+}
+
+// domainCheckResponse mirrors the namecheap.domains.check payload
+type domainCheckResponse struct {
+	Results []struct {
+		Domain                   string `xml:"Domain,attr"`
+		Available                bool   `xml:"Available,attr"`
+		ErrorNo                  string `xml:"ErrorNo,attr"`
+		Description              string `xml:"Description,attr"`
+		IsPremiumName            bool   `xml:"IsPremiumName,attr"`
+		PremiumRegistrationPrice string `xml:"PremiumRegistrationPrice,attr"`
+		PremiumRenewalPrice      string `xml:"PremiumRenewalPrice,attr"`
+		IcannFee                 string `xml:"IcannFee,attr"`
+		EapFee                   string `xml:"EapFee,attr"`
+	} `xml:"DomainCheckResult"`
+}
+
+// pricingResponse mirrors the namecheap.users.getPricing payload for domain registrations
+type pricingResponse struct {
+	Result struct {
+		ProductTypes []struct {
+			Categories []struct {
+				Products []struct {
+					Name   string `xml:"Name,attr"`
+					Prices []struct {
+						Duration     int    `xml:"Duration,attr"`
+						DurationType string `xml:"DurationType,attr"`
+						Price        string `xml:"Price,attr"`
+						YourPrice    string `xml:"YourPrice,attr"`
+						Currency     string `xml:"Currency,attr"`
+					} `xml:"Price"`
+				} `xml:"Product"`
+			} `xml:"ProductCategory"`
+		} `xml:"ProductType"`
+	} `xml:"UserGetPricingResult"`
+}
+
+// CheckAvailability checks if a domain is available for registration
+func (s *Service) CheckAvailability(domainName string) (bool, error) {
+	var resp domainCheckResponse
+	err := s.client.Call("namecheap.domains.check", map[string]string{
+		"DomainList": domainName,
+	}, &resp)
+	if err != nil {
+		return false, fmt.Errorf("failed to check availability of %s: %w", domainName, err)
+	}
+
+	for _, r := range resp.Results {
+		if strings.EqualFold(r.Domain, domainName) {
+			return r.Available, nil
+		}
+	}
+
+	return false, fmt.Errorf("no availability result returned for %s", domainName)
+}
+
+// CheckAvailabilityMany checks several domains in parallel and looks up their
+// registration price. Per-domain failures are reported in Availability.Err;
+// results are returned in the order of the input.
+func (s *Service) CheckAvailabilityMany(domainNames []string) []Availability {
+	results := make([]Availability, len(domainNames))
+	prices := newPriceCache(s)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentChecks)
+	for i, name := range domainNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = s.checkOne(name, prices)
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkOne checks a single domain and resolves its price
+func (s *Service) checkOne(domainName string, prices *priceCache) Availability {
+	result := Availability{Domain: domainName}
+
+	var resp domainCheckResponse
+	err := s.client.Call("namecheap.domains.check", map[string]string{
+		"DomainList": domainName,
+	}, &resp)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if len(resp.Results) == 0 {
+		result.Err = fmt.Errorf("no availability result returned")
+		return result
+	}
+
+	r := resp.Results[0]
+	if r.ErrorNo != "" && r.ErrorNo != "0" {
+		result.Err = fmt.Errorf("%s (%s)", r.Description, r.ErrorNo)
+		return result
+	}
+
+	result.Available = r.Available
+	result.IsPremium = r.IsPremiumName
+	result.EAPFee = parseFee(r.EapFee)
+	result.ICANNFee = parseFee(r.IcannFee)
+
+	if r.IsPremiumName {
+		result.PremiumRegistrationPrice = parseFee(r.PremiumRegistrationPrice)
+		result.PremiumRenewalPrice = parseFee(r.PremiumRenewalPrice)
+		if result.PremiumRegistrationPrice > 0 {
+			result.Price = result.PremiumRegistrationPrice
+			result.Currency = "USD"
+		}
+		return result
+	}
+
+	if result.Available {
+		result.Price, result.Currency = prices.get(getTLD(domainName))
+	}
+
+	return result
+}
+
+// parseFee parses a price attribute of a domain check result, which is empty
+// or "0" when it does not apply
+func parseFee(value string) float64 {
+	fee, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return fee
+}
+
+// priceCache memoizes per-TLD registration prices for the duration of a check
+type priceCache struct {
+	service *Service
+	mu      sync.Mutex
+	prices  map[string]*tldPrice
+}
+
+type tldPrice struct {
+	once     sync.Once
+	price    float64
+	currency string
+}
+
+func newPriceCache(s *Service) *priceCache {
+	return &priceCache{
+		service: s,
+		prices:  make(map[string]*tldPrice),
+	}
+}
+
+// get returns the one-year registration price for a TLD, or 0 if unavailable
+func (c *priceCache) get(tld string) (float64, string) {
+	c.mu.Lock()
+	entry, ok := c.prices[tld]
+	if !ok {
+		entry = &tldPrice{}
+		c.prices[tld] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.price, entry.currency = c.service.registrationPrice(tld)
+	})
+
+	return entry.price, entry.currency
+}
+
+// registrationPrice queries the one-year registration price for a TLD
+func (s *Service) registrationPrice(tld string) (float64, string) {
+	return s.productPrice("REGISTER", tld, 1)
+}
+
+// productPrice queries the price of a domain action ("REGISTER" or "RENEW")
+// for a TLD and number of years. It returns 0 if the price is unavailable.
+func (s *Service) productPrice(action, tld string, years int) (float64, string) {
+	var resp pricingResponse
+	err := s.client.Call("namecheap.users.getPricing", map[string]string{
+		"ProductType":     "DOMAIN",
+		"ProductCategory": "DOMAINS",
+		"ActionName":      action,
+		"ProductName":     strings.ToUpper(tld),
+	}, &resp)
+	if err != nil {
+		return 0, ""
+	}
+
+	for _, pt := range resp.Result.ProductTypes {
+		for _, cat := range pt.Categories {
+			for _, product := range cat.Products {
+				if !strings.EqualFold(product.Name, tld) {
+					continue
+				}
+				for _, p := range product.Prices {
+					if p.Duration != years || !strings.EqualFold(p.DurationType, "YEAR") {
+						continue
+					}
+					value := p.YourPrice
+					if value == "" {
+						value = p.Price
+					}
+					if price, err := strconv.ParseFloat(value, 64); err == nil {
+						return price, p.Currency
+					}
+				}
+			}
+		}
+	}
+
+	return 0, ""
+}
+
+// ExpandCandidates builds the list of domains to check from a base name and TLDs.
+// If base already contains a TLD it is stripped first. Duplicate TLDs are ignored.
+func ExpandCandidates(base string, tlds []string) []string {
+	base = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(base)), ".")
+	if strings.Contains(base, ".") {
+		base = getDomain(base)
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, tld := range tlds {
+		tld = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tld)), ".")
+		if tld == "" || seen[tld] {
+			continue
+		}
+		seen[tld] = true
+		candidates = append(candidates, base+"."+tld)
+	}
+
+	return candidates
+}
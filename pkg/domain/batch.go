@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Balance is the account balance renewals are charged to
+type Balance struct {
+	Currency  string
+	Available float64
+}
+
+// balancesResponse mirrors the namecheap.users.getBalances payload
+type balancesResponse struct {
+	Result struct {
+		Currency         string `xml:"Currency,attr"`
+		AvailableBalance string `xml:"AvailableBalance,attr"`
+	} `xml:"UserGetBalancesResult"`
+}
+
+// GetBalance returns the account's available balance
+func (s *Service) GetBalance() (*Balance, error) {
+	var resp balancesResponse
+	if err := s.client.Call("namecheap.users.getBalances", map[string]string{}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	available, err := strconv.ParseFloat(resp.Result.AvailableBalance, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account balance %q: %w", resp.Result.AvailableBalance, err)
+	}
+	return &Balance{Currency: resp.Result.Currency, Available: available}, nil
+}
+
+// RenewalCandidate is a domain that expires soon, with its renewal price
+type RenewalCandidate struct {
+	Domain  Domain
+	Expires time.Time
+	// Price is the renewal price for the requested years, 0 if unknown
+	Price    float64
+	Currency string
+}
+
+// RenewalCandidates returns the account's domains that expire before now plus
+// within, soonest first, priced for a renewal of years. Domains whose expiry
+// date is unknown are left out.
+func (s *Service) RenewalCandidates(within time.Duration, years int, now time.Time) ([]RenewalCandidate, error) {
+	domains, err := s.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := now.Add(within)
+	prices := make(map[string]RenewalCandidate)
+	var candidates []RenewalCandidate
+	for _, d := range domains {
+		expires, ok := d.ExpiresAt()
+		if !ok || expires.After(deadline) {
+			continue
+		}
+
+		tld := getTLD(d.Name)
+		price, known := prices[tld]
+		if !known {
+			price.Price, price.Currency = s.productPrice("RENEW", tld, years)
+			prices[tld] = price
+		}
+		candidates = append(candidates, RenewalCandidate{Domain: d, Expires: expires, Price: price.Price, Currency: price.Currency})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Expires.Before(candidates[j].Expires) })
+	return candidates, nil
+}
+
+// BatchStatus is the outcome of one renewal in a batch
+type BatchStatus string
+
+const (
+	// BatchRenewed means the domain was renewed
+	BatchRenewed BatchStatus = "renewed"
+	// BatchFailed means the renewal was attempted and failed
+	BatchFailed BatchStatus = "failed"
+	// BatchSkipped means the renewal was not attempted because the balance no
+	// longer covered it
+	BatchSkipped BatchStatus = "skipped"
+)
+
+// BatchResult is the outcome of renewing one candidate
+type BatchResult struct {
+	Candidate RenewalCandidate
+	Status    BatchStatus
+	Renewal   *Renewal
+	Err       error
+}
+
+// RenewBatch renews candidates one at a time, in order, and reports each result
+// to report as it happens. The balance is checked before every renewal: once it
+// no longer covers a candidate's price, that candidate and all later ones are
+// skipped rather than attempted, so a batch stops cleanly when funds run out.
+// A failed renewal does not stop the batch.
+func (s *Service) RenewBatch(candidates []RenewalCandidate, years int, balance float64, report func(BatchResult)) []BatchResult {
+	results := make([]BatchResult, 0, len(candidates))
+	exhausted := false
+	for _, c := range candidates {
+		result := BatchResult{Candidate: c}
+		switch {
+		case exhausted || c.Price > balance:
+			exhausted = true
+			result.Status = BatchSkipped
+			result.Err = fmt.Errorf("insufficient balance: %.2f left, renewal costs %.2f", balance, c.Price)
+		default:
+			renewal, err := s.RenewDomain(c.Domain.Name, years, "")
+			switch {
+			case err != nil:
+				result.Status = BatchFailed
+				result.Err = err
+			case !renewal.Renewed:
+				result.Status = BatchFailed
+				result.Renewal = renewal
+				result.Err = fmt.Errorf("renewal was not completed (order %s)", renewal.OrderID)
+			default:
+				result.Status = BatchRenewed
+				result.Renewal = renewal
+				charged := renewal.ChargedAmount
+				if charged == 0 {
+					charged = c.Price
+				}
+				balance -= charged
+			}
+		}
+		results = append(results, result)
+		if report != nil {
+			report(result)
+		}
+	}
+	return results
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"fmt"
+
+	"zonekit/pkg/config"
+)
+
+// apiContact mirrors a contact of the namecheap.domains.getContacts payload
+type apiContact struct {
+	FirstName        string `xml:"FirstName"`
+	LastName         string `xml:"LastName"`
+	OrganizationName string `xml:"OrganizationName"`
+	JobTitle         string `xml:"JobTitle"`
+	Address1         string `xml:"Address1"`
+	Address2         string `xml:"Address2"`
+	City             string `xml:"City"`
+	StateProvince    string `xml:"StateProvince"`
+	PostalCode       string `xml:"PostalCode"`
+	Country          string `xml:"Country"`
+	Phone            string `xml:"Phone"`
+	EmailAddress     string `xml:"EmailAddress"`
+}
+
+func (c apiContact) toContact() *config.Contact {
+	return &config.Contact{
+		FirstName:     c.FirstName,
+		LastName:      c.LastName,
+		Organization:  c.OrganizationName,
+		JobTitle:      c.JobTitle,
+		Address1:      c.Address1,
+		Address2:      c.Address2,
+		City:          c.City,
+		StateProvince: c.StateProvince,
+		PostalCode:    c.PostalCode,
+		Country:       c.Country,
+		Phone:         c.Phone,
+		Email:         c.EmailAddress,
+	}
+}
+
+// domainGetContactsResponse mirrors the namecheap.domains.getContacts payload
+type domainGetContactsResponse struct {
+	Result struct {
+		Domain     string     `xml:"Domain,attr"`
+		Registrant apiContact `xml:"Registrant"`
+		Admin      apiContact `xml:"Admin"`
+		Tech       apiContact `xml:"Tech"`
+		AuxBilling apiContact `xml:"AuxBilling"`
+	} `xml:"DomainContactsResult"`
+}
+
+// domainSetContactsResponse mirrors the namecheap.domains.setContacts payload
+type domainSetContactsResponse struct {
+	Result struct {
+		Domain    string `xml:"Domain,attr"`
+		IsSuccess bool   `xml:"IsSuccess,attr"`
+	} `xml:"DomainSetContactResult"`
+}
+
+// GetContacts returns the registrant, admin, tech and billing contacts of a domain
+func (s *Service) GetContacts(domainName string) (*config.ContactProfile, error) {
+	if err := ValidateDomain(domainName); err != nil {
+		return nil, err
+	}
+
+	var resp domainGetContactsResponse
+	params := map[string]string{"DomainName": domainName}
+	if err := s.client.Call("namecheap.domains.getContacts", params, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get contacts of %s: %w", domainName, err)
+	}
+
+	return &config.ContactProfile{
+		Registrant: resp.Result.Registrant.toContact(),
+		Admin:      resp.Result.Admin.toContact(),
+		Tech:       resp.Result.Tech.toContact(),
+		Billing:    resp.Result.AuxBilling.toContact(),
+	}, nil
+}
+
+// SetContacts replaces the contacts of a domain. Contacts missing from the
+// profile default to the registrant, as the API requires all four roles.
+// Changing the registrant of a gTLD may lock the domain against transfers for
+// 60 days under ICANN policy.
+func (s *Service) SetContacts(domainName string, contacts *config.ContactProfile) error {
+	if err := ValidateDomain(domainName); err != nil {
+		return err
+	}
+	if contacts == nil {
+		return fmt.Errorf("contacts are required")
+	}
+	if err := contacts.Validate(); err != nil {
+		return fmt.Errorf("invalid contacts: %w", err)
+	}
+
+	params := map[string]string{"DomainName": domainName}
+	addProfileParams(params, contacts)
+
+	var resp domainSetContactsResponse
+	if err := s.client.Call("namecheap.domains.setContacts", params, &resp); err != nil {
+		return fmt.Errorf("failed to set contacts of %s: %w", domainName, err)
+	}
+	if !resp.Result.IsSuccess {
+		return fmt.Errorf("failed to set contacts of %s", domainName)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"zonekit/pkg/validation"
+)
+
+// GlueRecord is a nameserver registered at the registry under a domain, with
+// the address resolvers use to reach it while the domain delegates to it
+type GlueRecord struct {
+	Nameserver string
+	IP         string
+	Statuses   []string
+}
+
+// domainNSInfoResponse mirrors the namecheap.domains.ns.getInfo payload. The
+// SDK decodes the update and delete results as create results, so the
+// domains.ns commands are called directly.
+type domainNSInfoResponse struct {
+	Result struct {
+		Nameserver string   `xml:"Nameserver,attr"`
+		IP         string   `xml:"IP,attr"`
+		Statuses   []string `xml:"NameserverStatuses>Status"`
+	} `xml:"DomainNSInfoResult"`
+}
+
+// domainNSCreateResponse mirrors the namecheap.domains.ns.create payload
+type domainNSCreateResponse struct {
+	Result struct {
+		IsSuccess bool `xml:"IsSuccess,attr"`
+	} `xml:"DomainNSCreateResult"`
+}
+
+// domainNSUpdateResponse mirrors the namecheap.domains.ns.update payload
+type domainNSUpdateResponse struct {
+	Result struct {
+		IsSuccess bool `xml:"IsSuccess,attr"`
+	} `xml:"DomainNSUpdateResult"`
+}
+
+// domainNSDeleteResponse mirrors the namecheap.domains.ns.delete payload
+type domainNSDeleteResponse struct {
+	Result struct {
+		IsSuccess bool `xml:"IsSuccess,attr"`
+	} `xml:"DomainNSDeleteResult"`
+}
+
+// GlueNameserver returns nameserver as a fully qualified name under
+// domainName. A bare label such as "ns1" is qualified with the domain; any
+// other name must already be a subdomain of it, as glue can only be
+// registered under the domain itself.
+func GlueNameserver(domainName, nameserver string) (string, error) {
+	domainName = strings.ToLower(strings.TrimSuffix(domainName, "."))
+	nameserver = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(nameserver), "."))
+	if nameserver != "" && !strings.Contains(nameserver, ".") {
+		nameserver += "." + domainName
+	}
+	if err := validation.ValidateHostname(nameserver); err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(nameserver, "."+domainName) {
+		return "", fmt.Errorf("nameserver %s is not under %s", nameserver, domainName)
+	}
+	return nameserver, nil
+}
+
+// validateGlueIP checks that ip is an IP address
+func validateGlueIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP address: %s", ip)
+	}
+	return nil
+}
+
+// glueParams returns the parameters shared by the domains.ns commands
+func glueParams(domainName, nameserver string) map[string]string {
+	return map[string]string{
+		"SLD":        getDomain(domainName),
+		"TLD":        getTLD(domainName),
+		"Nameserver": nameserver,
+	}
+}
+
+// GetGlue returns a nameserver registered under a domain
+func (s *Service) GetGlue(domainName, nameserver string) (*GlueRecord, error) {
+	if err := ValidateDomain(domainName); err != nil {
+		return nil, err
+	}
+	nameserver, err := GlueNameserver(domainName, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp domainNSInfoResponse
+	if err := s.client.Call("namecheap.domains.ns.getInfo", glueParams(domainName, nameserver), &resp); err != nil {
+		return nil, fmt.Errorf("failed to get nameserver %s: %w", nameserver, err)
+	}
+
+	glue := &GlueRecord{Nameserver: resp.Result.Nameserver, IP: resp.Result.IP, Statuses: resp.Result.Statuses}
+	if glue.Nameserver == "" {
+		glue.Nameserver = nameserver
+	}
+	return glue, nil
+}
+
+// ListGlue returns the nameservers registered under a domain. The API cannot
+// enumerate them, so the given nameservers are looked up, or without any the
+// nameservers the domain delegates to that are under the domain itself.
+func (s *Service) ListGlue(domainName string, nameservers []string) ([]GlueRecord, error) {
+	if err := ValidateDomain(domainName); err != nil {
+		return nil, err
+	}
+	if len(nameservers) == 0 {
+		delegated, err := s.GetNameservers(domainName)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range delegated {
+			if _, err := GlueNameserver(domainName, ns); err == nil {
+				nameservers = append(nameservers, ns)
+			}
+		}
+	}
+
+	records := make([]GlueRecord, 0, len(nameservers))
+	for _, ns := range nameservers {
+		glue, err := s.GetGlue(domainName, ns)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *glue)
+	}
+	return records, nil
+}
+
+// CreateGlue registers a nameserver under a domain with its IP address
+func (s *Service) CreateGlue(domainName, nameserver, ip string) error {
+	if err := ValidateDomain(domainName); err != nil {
+		return err
+	}
+	nameserver, err := GlueNameserver(domainName, nameserver)
+	if err != nil {
+		return err
+	}
+	if err := validateGlueIP(ip); err != nil {
+		return err
+	}
+
+	params := glueParams(domainName, nameserver)
+	params["IP"] = ip
+
+	var resp domainNSCreateResponse
+	if err := s.client.Call("namecheap.domains.ns.create", params, &resp); err != nil {
+		return fmt.Errorf("failed to create nameserver %s: %w", nameserver, err)
+	}
+	if !resp.Result.IsSuccess {
+		return fmt.Errorf("failed to create nameserver %s", nameserver)
+	}
+	return nil
+}
+
+// UpdateGlue changes the IP address of a nameserver registered under a
+// domain. The API needs the current address, which is looked up first.
+func (s *Service) UpdateGlue(domainName, nameserver, ip string) error {
+	if err := validateGlueIP(ip); err != nil {
+		return err
+	}
+	current, err := s.GetGlue(domainName, nameserver)
+	if err != nil {
+		return err
+	}
+
+	params := glueParams(domainName, current.Nameserver)
+	params["OldIP"] = current.IP
+	params["IP"] = ip
+
+	var resp domainNSUpdateResponse
+	if err := s.client.Call("namecheap.domains.ns.update", params, &resp); err != nil {
+		return fmt.Errorf("failed to update nameserver %s: %w", current.Nameserver, err)
+	}
+	if !resp.Result.IsSuccess {
+		return fmt.Errorf("failed to update nameserver %s", current.Nameserver)
+	}
+	return nil
+}
+
+// DeleteGlue removes a nameserver registered under a domain. The registry
+// refuses while domains still delegate to it.
+func (s *Service) DeleteGlue(domainName, nameserver string) error {
+	if err := ValidateDomain(domainName); err != nil {
+		return err
+	}
+	nameserver, err := GlueNameserver(domainName, nameserver)
+	if err != nil {
+		return err
+	}
+
+	var resp domainNSDeleteResponse
+	if err := s.client.Call("namecheap.domains.ns.delete", glueParams(domainName, nameserver), &resp); err != nil {
+		return fmt.Errorf("failed to delete nameserver %s: %w", nameserver, err)
+	}
+	if !resp.Result.IsSuccess {
+		return fmt.Errorf("failed to delete nameserver %s", nameserver)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// apiDateLayouts are the date formats used by domains.getInfo and, with a
+// time of day, domains.renew
+var apiDateLayouts = []string{"01/02/2006", "1/2/2006 3:04:05 PM"}
+
+// dateLayout is the representation of dates in Domain, as written by getDateTime
+const dateLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// domainInfoResponse mirrors the namecheap.domains.getInfo payload.
+// The SDK only decodes DNS details, so ownership and dates are read here.
+type domainInfoResponse struct {
+	Result *domainInfoResult `xml:"DomainGetInfoResult"`
+}
+
+type domainInfoResult struct {
+	ID         string `xml:"ID,attr"`
+	Status     string `xml:"Status,attr"`
+	DomainName string `xml:"DomainName,attr"`
+	OwnerName  string `xml:"OwnerName,attr"`
+	IsOwner    bool   `xml:"IsOwner,attr"`
+	IsPremium  bool   `xml:"IsPremium,attr"`
+
+	DomainDetails struct {
+		CreatedDate string `xml:"CreatedDate"`
+		ExpiredDate string `xml:"ExpiredDate"`
+	} `xml:"DomainDetails"`
+
+	Whoisguard struct {
+		Enabled     string `xml:"Enabled,attr"`
+		ExpiredDate string `xml:"ExpiredDate"`
+	} `xml:"Whoisguard"`
+
+	DnsDetails struct {
+		ProviderType  string   `xml:"ProviderType,attr"`
+		IsUsingOurDNS bool     `xml:"IsUsingOurDNS,attr"`
+		Nameservers   []string `xml:"Nameserver"`
+	} `xml:"DnsDetails"`
+}
+
+// toDomain converts the getInfo result into a Domain
+func (r *domainInfoResult) toDomain() *Domain {
+	whoisGuard := "NOTPRESENT"
+	switch strings.ToLower(r.Whoisguard.Enabled) {
+	case "true":
+		whoisGuard = "ENABLED"
+	case "false":
+		whoisGuard = "DISABLED"
+	}
+
+	expires := parseAPIDate(r.DomainDetails.ExpiredDate)
+
+	return &Domain{
+		Name:              r.DomainName,
+		User:              r.OwnerName,
+		Status:            r.Status,
+		Created:           parseAPIDate(r.DomainDetails.CreatedDate),
+		Expires:           expires,
+		IsExpired:         isPast(r.DomainDetails.ExpiredDate),
+		WhoisGuard:        whoisGuard,
+		WhoisGuardExpires: parseAPIDate(r.Whoisguard.ExpiredDate),
+		IsPremium:         r.IsPremium,
+		IsOurDNS:          r.DnsDetails.IsUsingOurDNS,
+		Nameservers:       r.DnsDetails.Nameservers,
+	}
+}
+
+// parseAPIDate converts an API date into the same representation used by ListDomains.
+// Values that cannot be parsed are returned unchanged.
+func parseAPIDate(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	t, ok := parseAPITime(value)
+	if !ok {
+		return value
+	}
+	return getDateTime(&namecheap.DateTime{Time: t})
+}
+
+// isPast reports whether an API date lies in the past
+func isPast(value string) bool {
+	t, ok := parseAPITime(value)
+	return ok && t.Before(time.Now())
+}
+
+// ExpiresAt returns the expiry date of the domain, or false if it is unknown
+func (d Domain) ExpiresAt() (time.Time, bool) {
+	if t, err := time.Parse(dateLayout, d.Expires); err == nil {
+		return t, true
+	}
+	return parseAPITime(d.Expires)
+}
+
+// parseAPITime parses an API date in any of apiDateLayouts
+func parseAPITime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range apiDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"zonekit/pkg/validation"
+)
+
+const (
+	// MinNameservers is the minimum number of custom nameservers a domain needs
+	MinNameservers = 2
+	// MaxNameservers is the maximum number of custom nameservers Namecheap accepts
+	MaxNameservers = 12
+)
+
+// lookupHost resolves a hostname; replaced in tests
+var lookupHost = net.LookupHost
+
+// NormalizeNameservers lowercases and de-duplicates nameservers, validates each
+// as a fully qualified hostname and checks the count against the registrar limits
+func NormalizeNameservers(nameservers []string) ([]string, error) {
+	seen := make(map[string]bool, len(nameservers))
+	normalized := make([]string, 0, len(nameservers))
+
+	for _, ns := range nameservers {
+		ns = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(ns), "."))
+		if err := validation.ValidateHostname(ns); err != nil {
+			return nil, err
+		}
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		normalized = append(normalized, ns)
+	}
+
+	if len(normalized) < MinNameservers {
+		return nil, fmt.Errorf("at least %d distinct nameservers are required, got %d", MinNameservers, len(normalized))
+	}
+	if len(normalized) > MaxNameservers {
+		return nil, fmt.Errorf("at most %d nameservers are allowed, got %d", MaxNameservers, len(normalized))
+	}
+
+	return normalized, nil
+}
+
+// CheckNameserversResolve verifies that every nameserver resolves to an address
+func CheckNameserversResolve(nameservers []string) error {
+	var unresolved []string
+	for _, ns := range nameservers {
+		if addrs, err := lookupHost(ns); err != nil || len(addrs) == 0 {
+			unresolved = append(unresolved, ns)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return fmt.Errorf("nameservers do not resolve: %s", strings.Join(unresolved, ", "))
+	}
+
+	return nil
+}
+
+// ReadDomainList reads domain names, one per line. Blank lines and lines
+// starting with # are skipped, and duplicates are removed.
+func ReadDomainList(r io.Reader) ([]string, error) {
+	var domains []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(scanner.Text()), "."))
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if err := ValidateDomain(name); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		domains = append(domains, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domains: %w", err)
+	}
+	return domains, nil
+}
+
+// NameserverStatus is the outcome of one domain in a nameserver batch
+type NameserverStatus string
+
+const (
+	// NameserversChanged means the domain was pointed at the nameservers
+	NameserversChanged NameserverStatus = "changed"
+	// NameserversUnchanged means the domain already used the nameservers
+	NameserversUnchanged NameserverStatus = "unchanged"
+	// NameserversPending means a dry run would change the domain
+	NameserversPending NameserverStatus = "pending"
+	// NameserversFailed means reading or changing the nameservers failed
+	NameserversFailed NameserverStatus = "failed"
+)
+
+// NameserverResult is the outcome of setting the nameservers of one domain
+type NameserverResult struct {
+	Domain string
+	// Previous are the nameservers the domain used before, if they were read
+	Previous []string
+	Status   NameserverStatus
+	Err      error
+}
+
+// SetNameserversBatch points every domain at nameservers, changing up to
+// concurrency domains at once, and reports each result to report as it
+// happens; report is never called concurrently. Domains that already use the
+// nameservers are left alone, and with dryRun no domain is changed. The
+// results are in the order of domains; an error is only returned for invalid
+// nameservers, before any domain is touched.
+func (s *Service) SetNameserversBatch(domains, nameservers []string, concurrency int, dryRun bool, report func(NameserverResult)) ([]NameserverResult, error) {
+	nameservers, err := NormalizeNameservers(nameservers)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]NameserverResult, len(domains))
+	var reportMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i, domainName := range domains {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, domainName string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			result := s.setBatchNameservers(domainName, nameservers, dryRun)
+			results[i] = result
+			if report != nil {
+				reportMu.Lock()
+				report(result)
+				reportMu.Unlock()
+			}
+		}(i, domainName)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// setBatchNameservers points one domain of a batch at nameservers
+func (s *Service) setBatchNameservers(domainName string, nameservers []string, dryRun bool) NameserverResult {
+	result := NameserverResult{Domain: domainName}
+	previous, err := s.GetNameservers(domainName)
+	if err != nil {
+		result.Status, result.Err = NameserversFailed, err
+		return result
+	}
+	result.Previous = previous
+
+	switch {
+	case sameNameservers(previous, nameservers):
+		result.Status = NameserversUnchanged
+	case dryRun:
+		result.Status = NameserversPending
+	default:
+		if err := s.SetNameservers(domainName, nameservers); err != nil {
+			result.Status, result.Err = NameserversFailed, err
+		} else {
+			result.Status = NameserversChanged
+		}
+	}
+	return result
+}
+
+// sameNameservers reports whether two nameserver lists hold the same hosts,
+// in any order and case
+func sameNameservers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	hosts := make(map[string]bool, len(a))
+	for _, ns := range a {
+		hosts[strings.ToLower(strings.TrimSuffix(ns, "."))] = true
+	}
+	for _, ns := range b {
+		if !hosts[strings.ToLower(strings.TrimSuffix(ns, "."))] {
+			return false
+		}
+	}
+	return true
+}
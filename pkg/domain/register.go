@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/config"
+)
+
+// Registration is the outcome of a domain registration
+type Registration struct {
+	Domain        string
+	Registered    bool
+	ChargedAmount float64
+	DomainID      string
+	OrderID       string
+	TransactionID string
+}
+
+// domainCreateResponse mirrors the namecheap.domains.create payload
+type domainCreateResponse struct {
+	Result struct {
+		Domain        string `xml:"Domain,attr"`
+		Registered    bool   `xml:"Registered,attr"`
+		ChargedAmount string `xml:"ChargedAmount,attr"`
+		DomainID      string `xml:"DomainID,attr"`
+		OrderID       string `xml:"OrderID,attr"`
+		TransactionID string `xml:"TransactionID,attr"`
+	} `xml:"DomainCreateResult"`
+}
+
+// RegisterDomain registers a domain for the given number of years, using the
+// contacts of the profile for the registrant, admin, tech and billing roles.
+// The registration is charged to the account's balance.
+func (s *Service) RegisterDomain(domainName string, years int, contacts *config.ContactProfile) (*Registration, error) {
+	if err := ValidateDomain(domainName); err != nil {
+		return nil, err
+	}
+	if years < 1 || years > 10 {
+		return nil, fmt.Errorf("years must be between 1 and 10")
+	}
+	if contacts == nil {
+		return nil, fmt.Errorf("contact profile is required")
+	}
+	if err := contacts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid contact profile: %w", err)
+	}
+
+	params := map[string]string{
+		"DomainName": domainName,
+		"Years":      strconv.Itoa(years),
+	}
+	addProfileParams(params, contacts)
+
+	var resp domainCreateResponse
+	if err := s.client.Call("namecheap.domains.create", params, &resp); err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", domainName, err)
+	}
+
+	registration := &Registration{
+		Domain:        resp.Result.Domain,
+		Registered:    resp.Result.Registered,
+		DomainID:      resp.Result.DomainID,
+		OrderID:       resp.Result.OrderID,
+		TransactionID: resp.Result.TransactionID,
+	}
+	if amount, err := strconv.ParseFloat(resp.Result.ChargedAmount, 64); err == nil {
+		registration.ChargedAmount = amount
+	}
+	if registration.Domain == "" {
+		registration.Domain = domainName
+	}
+
+	return registration, nil
+}
+
+// addProfileParams adds the contacts of a profile for every role, defaulting
+// missing contacts to the registrant
+func addProfileParams(params map[string]string, contacts *config.ContactProfile) {
+	// The API names the billing contact AuxBilling
+	for prefix, contact := range map[string]*config.Contact{
+		"Registrant": contacts.Registrant,
+		"Admin":      contacts.Admin,
+		"Tech":       contacts.Tech,
+		"AuxBilling": contacts.Billing,
+	} {
+		if contact == nil {
+			contact = contacts.Registrant
+		}
+		addContactParams(params, prefix, contact)
+	}
+}
+
+// addContactParams adds the fields of a contact under the API's role prefix,
+// e.g. RegistrantFirstName
+func addContactParams(params map[string]string, prefix string, c *config.Contact) {
+	fields := map[string]string{
+		"FirstName":        c.FirstName,
+		"LastName":         c.LastName,
+		"OrganizationName": c.Organization,
+		"JobTitle":         c.JobTitle,
+		"Address1":         c.Address1,
+		"Address2":         c.Address2,
+		"City":             c.City,
+		"StateProvince":    c.StateProvince,
+		"PostalCode":       c.PostalCode,
+		"Country":          strings.ToUpper(c.Country),
+		"Phone":            c.Phone,
+		"EmailAddress":     c.Email,
+	}
+	for name, value := range fields {
+		if value != "" {
+			params[prefix+name] = value
+		}
+	}
+}
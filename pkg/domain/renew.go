@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Renewal is the outcome of a domain renewal
+type Renewal struct {
+	Domain        string
+	Renewed       bool
+	ChargedAmount float64
+	// Expires is the new expiry date
+	Expires       string
+	OrderID       string
+	TransactionID string
+}
+
+// domainRenewResponse mirrors the namecheap.domains.renew payload
+type domainRenewResponse struct {
+	Result struct {
+		DomainName    string `xml:"DomainName,attr"`
+		Renew         bool   `xml:"Renew,attr"`
+		ChargedAmount string `xml:"ChargedAmount,attr"`
+		OrderID       string `xml:"OrderID,attr"`
+		TransactionID string `xml:"TransactionID,attr"`
+
+		DomainDetails struct {
+			ExpiredDate string `xml:"ExpiredDate"`
+		} `xml:"DomainDetails"`
+	} `xml:"DomainRenewResult"`
+}
+
+// RenewDomain renews a domain for the given number of years, optionally with a
+// promotion code. The renewal is charged to the account's balance.
+func (s *Service) RenewDomain(domainName string, years int, promoCode string) (*Renewal, error) {
+	if err := ValidateDomain(domainName); err != nil {
+		return nil, err
+	}
+	if years < 1 || years > 10 {
+		return nil, fmt.Errorf("years must be between 1 and 10")
+	}
+
+	params := map[string]string{
+		"DomainName": domainName,
+		"Years":      strconv.Itoa(years),
+	}
+	if promoCode != "" {
+		params["PromotionCode"] = promoCode
+	}
+
+	var resp domainRenewResponse
+	if err := s.client.Call("namecheap.domains.renew", params, &resp); err != nil {
+		return nil, fmt.Errorf("failed to renew %s: %w", domainName, err)
+	}
+
+	renewal := &Renewal{
+		Domain:        resp.Result.DomainName,
+		Renewed:       resp.Result.Renew,
+		Expires:       parseAPIDate(resp.Result.DomainDetails.ExpiredDate),
+		OrderID:       resp.Result.OrderID,
+		TransactionID: resp.Result.TransactionID,
+	}
+	if amount, err := strconv.ParseFloat(resp.Result.ChargedAmount, 64); err == nil {
+		renewal.ChargedAmount = amount
+	}
+	if renewal.Domain == "" {
+		renewal.Domain = domainName
+	}
+
+	return renewal, nil
+}
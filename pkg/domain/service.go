@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/xml"
 	"fmt"
 	"strings"
 
@@ -24,30 +25,38 @@ func NewService(client *client.Client) *Service {
 
 // Domain represents a domain with its details
 type Domain struct {
-	Name       string
-	User       string
-	Created    string
-	Expires    string
-	IsExpired  bool
-	IsLocked   bool
-	AutoRenew  bool
-	WhoisGuard string
-	IsPremium  bool
-	IsOurDNS   bool
+	Name              string
+	User              string
+	Status            string
+	Created           string
+	Expires           string
+	IsExpired         bool
+	IsLocked          bool
+	AutoRenew         bool
+	WhoisGuard        string
+	WhoisGuardExpires string
+	IsPremium         bool
+	IsOurDNS          bool
+	Nameservers       []string
+	// Raw is the domains.getInfo response as Namecheap sent it; it is only
+	// set by GetDomainInfo
+	Raw client.RawXML
 }
 
 // ListDomains retrieves all domains for the authenticated user
 func (s *Service) ListDomains() ([]Domain, error) {
 	nc := s.client.GetNamecheapClient()
 
+	span := s.client.BeginCall("namecheap.domains.getList", "")
 	resp, err := nc.Domains.GetList(&namecheap.DomainsGetListArgs{
 		ListType: namecheap.String("ALL"),
 		Page:     namecheap.Int(1),
 		PageSize: namecheap.Int(100),
 	})
+	span.End(err)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get domain list: %w", err)
+		return nil, fmt.Errorf("failed to get domain list: %w", client.TranslateError(err))
 	}
 
 	domains := make([]Domain, 0, len(*resp.Domains))
@@ -70,62 +79,105 @@ func (s *Service) ListDomains() ([]Domain, error) {
 	return domains, nil
 }
 
-// GetDomainInfo retrieves detailed information about a specific domain
+// GetDomainInfo retrieves detailed information about a specific domain.
+// Ownership, dates and WhoisGuard details come from domains.getInfo, while
+// lock and auto-renew state are only reported by domains.getList and are merged in.
 func (s *Service) GetDomainInfo(domainName string) (*Domain, error) {
-	nc := s.client.GetNamecheapClient()
-
-	resp, err := nc.Domains.GetInfo(domainName)
-
+	raw, err := s.client.CallRaw("namecheap.domains.getInfo", map[string]string{
+		"DomainName": domainName,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get domain info for %s: %w", domainName, err)
 	}
 
-	domain := &Domain{
-		Name:       pointer.String(resp.DomainDNSGetListResult.DomainName),
-		User:       "", // Not available in this response
-		Created:    "", // Not available in this response
-		Expires:    "", // Not available in this response
-		IsExpired:  false,
-		IsLocked:   false,
-		AutoRenew:  false,
-		WhoisGuard: "",
-		IsPremium:  pointer.Bool(resp.DomainDNSGetListResult.IsPremium),
-		IsOurDNS:   pointer.Bool(resp.DomainDNSGetListResult.DnsDetails.IsUsingOurDNS),
+	var resp domainInfoResponse
+	if raw != nil {
+		if err := xml.Unmarshal(raw, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get domain info for %s: unable to parse response: %w", domainName, err)
+		}
+	}
+
+	if resp.Result == nil {
+		return nil, fmt.Errorf("failed to get domain info for %s: empty response", domainName)
+	}
+
+	domain := resp.Result.toDomain()
+	domain.Raw = raw
+
+	entry, err := s.findListEntry(domainName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain status for %s: %w", domainName, err)
+	}
+	if entry != nil {
+		domain.IsLocked = entry.IsLocked
+		domain.AutoRenew = entry.AutoRenew
+		domain.IsExpired = entry.IsExpired
+		if domain.User == "" {
+			domain.User = entry.User
+		}
+		if domain.Created == "" {
+			domain.Created = entry.Created
+		}
+		if domain.Expires == "" {
+			domain.Expires = entry.Expires
+		}
 	}
 
 	return domain, nil
 }
 
-// CheckAvailability checks if a domain is available for registration
-func (s *Service) CheckAvailability(domainName string) (bool, error) {
-	// Note: The current Namecheap SDK (v2.4.1) doesn't implement domain availability checking
-	// This would require implementing a direct API call to the domains.check endpoint
-	// For now, return an informative error
-	return false, fmt.Errorf("domain availability check not supported by current SDK version - please check manually at namecheap.com")
-}
+// findListEntry looks up a single domain in the account's domain list.
+// It returns nil without error if the domain is not listed.
+func (s *Service) findListEntry(domainName string) (*Domain, error) {
+	nc := s.client.GetNamecheapClient()
 
-// RegisterDomain registers a new domain (placeholder - needs contact info)
-func (s *Service) RegisterDomain(domainName string) error {
-	// TODO: Implement domain registration
-	// This requires contact information and payment details
-	return fmt.Errorf("domain registration not yet implemented - requires contact info and payment setup")
-}
+	span := s.client.BeginCall("namecheap.domains.getList", domainName)
+	resp, err := nc.Domains.GetList(&namecheap.DomainsGetListArgs{
+		ListType:   namecheap.String("ALL"),
+		SearchTerm: namecheap.String(domainName),
+		Page:       namecheap.Int(1),
+		PageSize:   namecheap.Int(100),
+	})
+	span.End(err)
+	if err != nil {
+		return nil, client.TranslateError(err)
+	}
 
-// RenewDomain renews an existing domain
-func (s *Service) RenewDomain(domainName string, years int) error {
-	// TODO: Implement domain renewal
-	// The SDK doesn't seem to have a direct renew method in the current version
-	return fmt.Errorf("domain renewal not yet implemented - TODO: add domains.renew API")
+	if resp == nil || resp.Domains == nil {
+		return nil, nil
+	}
+
+	for _, d := range *resp.Domains {
+		if !strings.EqualFold(pointer.String(d.Name), domainName) {
+			continue
+		}
+		return &Domain{
+			Name:       pointer.String(d.Name),
+			User:       pointer.String(d.User),
+			Created:    getDateTime(d.Created),
+			Expires:    getDateTime(d.Expires),
+			IsExpired:  pointer.Bool(d.IsExpired),
+			IsLocked:   pointer.Bool(d.IsLocked),
+			AutoRenew:  pointer.Bool(d.AutoRenew),
+			WhoisGuard: pointer.String(d.WhoisGuard),
+			IsPremium:  pointer.Bool(d.IsPremium),
+			IsOurDNS:   pointer.Bool(d.IsOurDNS),
+		}, nil
+	}
+
+	return nil, nil
 }
 
 // GetNameservers retrieves the nameservers for a domain
 func (s *Service) GetNameservers(domainName string) ([]string, error) {
 	nc := s.client.GetNamecheapClient()
 
+	span := s.client.BeginCall("namecheap.domains.dns.getList", domainName)
 	resp, err := nc.DomainsDNS.GetList(domainName)
+	span.End(err)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nameservers for %s: %w", domainName, err)
+		return nil, fmt.Errorf("failed to get nameservers for %s: %w", domainName, client.TranslateError(err))
 	}
 
 	nameservers := make([]string, 0, len(*resp.DomainDNSGetListResult.Nameservers))
@@ -134,14 +186,26 @@ func (s *Service) GetNameservers(domainName string) ([]string, error) {
 	return nameservers, nil
 }
 
-// SetNameservers sets custom nameservers for a domain
+// SetNameservers sets custom nameservers for a domain. The nameservers are
+// validated and de-duplicated with NormalizeNameservers first.
 func (s *Service) SetNameservers(domainName string, nameservers []string) error {
+	if err := ValidateDomain(domainName); err != nil {
+		return err
+	}
+
+	nameservers, err := NormalizeNameservers(nameservers)
+	if err != nil {
+		return err
+	}
+
 	nc := s.client.GetNamecheapClient()
 
-	_, err := nc.DomainsDNS.SetCustom(domainName, nameservers)
+	span := s.client.BeginCall("namecheap.domains.dns.setCustom", domainName)
+	_, err = nc.DomainsDNS.SetCustom(domainName, nameservers)
+	span.End(err)
 
 	if err != nil {
-		return fmt.Errorf("failed to set nameservers for %s: %w", domainName, err)
+		return fmt.Errorf("failed to set nameservers for %s: %w", domainName, client.TranslateError(err))
 	}
 
 	return nil
@@ -151,10 +215,12 @@ func (s *Service) SetNameservers(domainName string, nameservers []string) error
 func (s *Service) SetToNamecheapDNS(domainName string) error {
 	nc := s.client.GetNamecheapClient()
 
+	span := s.client.BeginCall("namecheap.domains.dns.setDefault", domainName)
 	_, err := nc.DomainsDNS.SetDefault(domainName)
+	span.End(err)
 
 	if err != nil {
-		return fmt.Errorf("failed to set domain %s to use Namecheap DNS: %w", domainName, err)
+		return fmt.Errorf("failed to set domain %s to use Namecheap DNS: %w", domainName, client.TranslateError(err))
 	}
 
 	return nil
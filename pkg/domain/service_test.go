@@ -0,0 +1,703 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/client"
+	"zonekit/pkg/config"
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/testutil"
+)
+
+const getInfoResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.getInfo">
+    <DomainGetInfoResult Status="Ok" ID="12345" DomainName="example.com" OwnerName="owner" IsOwner="true" IsPremium="false">
+      <DomainDetails>
+        <CreatedDate>02/15/2016</CreatedDate>
+        <ExpiredDate>02/15/2099</ExpiredDate>
+        <NumYears>0</NumYears>
+      </DomainDetails>
+      <Whoisguard Enabled="True">
+        <ID>53536</ID>
+        <ExpiredDate>02/11/2099</ExpiredDate>
+      </Whoisguard>
+      <DnsDetails ProviderType="FREE" IsUsingOurDNS="true">
+        <Nameserver>dns1.registrar-servers.com</Nameserver>
+        <Nameserver>dns2.registrar-servers.com</Nameserver>
+      </DnsDetails>
+    </DomainGetInfoResult>
+  </CommandResponse>
+</ApiResponse>`
+
+const getListResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.getList">
+    <DomainGetListResult>
+      <Domain ID="12345" Name="example.com" User="owner" Created="02/15/2016" Expires="02/15/2099" IsExpired="false" IsLocked="true" AutoRenew="true" WhoisGuard="ENABLED" IsPremium="false" IsOurDNS="true"/>
+    </DomainGetListResult>
+    <Paging><TotalItems>1</TotalItems><CurrentPage>1</CurrentPage><PageSize>100</PageSize></Paging>
+  </CommandResponse>
+</ApiResponse>`
+
+const errorResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+  <CommandResponse />
+</ApiResponse>`
+
+// ServiceTestSuite is a test suite for the domain service
+type ServiceTestSuite struct {
+	suite.Suite
+	server    *httptest.Server
+	responses map[string]string
+	// forms holds the parameters of the last request of each command
+	mu    sync.Mutex
+	forms map[string]url.Values
+	// targets lists the domain of each request as "<command>:<domain>"
+	targets []string
+	service *Service
+}
+
+// TestServiceSuite runs the domain service test suite
+func TestServiceSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+func (s *ServiceTestSuite) SetupSuite() {
+	// The suite makes more calls than Namecheap's rate allows in a minute
+	ratelimit.SetOverrides(map[string][]ratelimit.Rate{"namecheap": nil})
+}
+
+func (s *ServiceTestSuite) TearDownSuite() {
+	ratelimit.SetOverrides(nil)
+}
+
+func (s *ServiceTestSuite) SetupTest() {
+	s.responses = map[string]string{}
+	s.forms = map[string]url.Values{}
+	s.targets = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		target := r.FormValue("DomainList")
+		if target == "" && r.FormValue("SLD") != "" {
+			target = r.FormValue("SLD") + "." + r.FormValue("TLD")
+		}
+		s.mu.Lock()
+		s.forms[r.FormValue("Command")] = r.Form
+		s.targets = append(s.targets, r.FormValue("Command")+":"+target)
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "text/xml")
+		// Allow per-domain responses keyed as "<command>:<domain list>" or
+		// "<command>:<sld>.<tld>"
+		if resp, ok := s.responses[r.FormValue("Command")+":"+target]; ok {
+			w.Write([]byte(resp))
+			return
+		}
+		w.Write([]byte(s.responses[r.FormValue("Command")]))
+	}))
+
+	fixture := testutil.AccountConfigFixture()
+	c, err := client.NewClient(&config.AccountConfig{
+		Username:   fixture.Username,
+		APIUser:    fixture.APIUser,
+		APIKey:     fixture.APIKey,
+		ClientIP:   fixture.ClientIP,
+		UseSandbox: fixture.UseSandbox,
+	})
+	s.Require().NoError(err)
+	c.GetNamecheapClient().BaseURL = s.server.URL
+
+	s.service = NewService(c)
+}
+
+func (s *ServiceTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ServiceTestSuite) TestGetDomainInfo_MergesInfoAndListEntry() {
+	s.responses["namecheap.domains.getInfo"] = getInfoResponse
+	s.responses["namecheap.domains.getList"] = getListResponse
+
+	info, err := s.service.GetDomainInfo("example.com")
+	s.Require().NoError(err)
+
+	s.Require().Equal("example.com", info.Name)
+	s.Require().Equal("owner", info.User)
+	s.Require().Equal("Ok", info.Status)
+	s.Require().Contains(info.Created, "2016-02-15")
+	s.Require().Contains(info.Expires, "2099-02-15")
+	s.Require().False(info.IsExpired)
+	s.Require().True(info.IsLocked)
+	s.Require().True(info.AutoRenew)
+	s.Require().Equal("ENABLED", info.WhoisGuard)
+	s.Require().Contains(info.WhoisGuardExpires, "2099-02-11")
+	s.Require().True(info.IsOurDNS)
+	s.Require().Len(info.Nameservers, 2)
+
+	// The response is kept as sent, for fields not modeled in Domain
+	tree, err := info.Raw.Tree()
+	s.Require().NoError(err)
+	s.Require().Contains(tree["CommandResponse"], "DomainGetInfoResult")
+}
+
+func (s *ServiceTestSuite) TestGetDomainInfo_APIError() {
+	s.responses["namecheap.domains.getInfo"] = errorResponse
+
+	_, err := s.service.GetDomainInfo("example.com")
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "2019166")
+}
+
+func (s *ServiceTestSuite) TestParseAPIDate() {
+	s.Require().Equal("", parseAPIDate(""))
+	s.Require().Equal("not-a-date", parseAPIDate("not-a-date"))
+	s.Require().Contains(parseAPIDate("12/31/2030"), "2030-12-31")
+}
+
+func (s *ServiceTestSuite) TestDomainExpiresAt() {
+	expires, ok := Domain{Expires: parseAPIDate("12/31/2030")}.ExpiresAt()
+	s.Require().True(ok)
+	s.Require().Equal(time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC), expires)
+
+	expires, ok = Domain{Expires: "1/20/2031 1:55:24 PM"}.ExpiresAt()
+	s.Require().True(ok)
+	s.Require().Equal(2031, expires.Year())
+
+	_, ok = Domain{}.ExpiresAt()
+	s.Require().False(ok)
+}
+
+func checkResponse(domain, available, premium, premiumPrice string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.check">
+    <DomainCheckResult Domain="` + domain + `" Available="` + available + `" ErrorNo="0" Description="" IsPremiumName="` + premium + `" PremiumRegistrationPrice="` + premiumPrice + `" />
+  </CommandResponse>
+</ApiResponse>`
+}
+
+const pricingResponseXML = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.users.getPricing">
+    <UserGetPricingResult>
+      <ProductType Name="domains">
+        <ProductCategory Name="register">
+          <Product Name="com">
+            <Price Duration="1" DurationType="YEAR" Price="10.98" YourPrice="9.58" Currency="USD" />
+            <Price Duration="2" DurationType="YEAR" Price="21.96" YourPrice="19.16" Currency="USD" />
+          </Product>
+        </ProductCategory>
+      </ProductType>
+    </UserGetPricingResult>
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestCheckAvailabilityMany() {
+	s.responses["namecheap.domains.check:example.com"] = checkResponse("example.com", "true", "false", "0")
+	s.responses["namecheap.domains.check:example.net"] = checkResponse("example.net", "false", "false", "0")
+	s.responses["namecheap.domains.check:example.io"] = checkResponse("example.io", "true", "true", "1500.00")
+	s.responses["namecheap.domains.check:example.dev"] = errorResponse
+	s.responses["namecheap.users.getPricing"] = pricingResponseXML
+
+	results := s.service.CheckAvailabilityMany([]string{"example.com", "example.net", "example.io", "example.dev"})
+	s.Require().Len(results, 4)
+
+	s.Require().Equal("example.com", results[0].Domain)
+	s.Require().True(results[0].Available)
+	s.Require().Equal(9.58, results[0].Price)
+	s.Require().Equal("USD", results[0].Currency)
+
+	s.Require().False(results[1].Available)
+	s.Require().Zero(results[1].Price)
+
+	s.Require().True(results[2].IsPremium)
+	s.Require().Equal(1500.0, results[2].Price)
+
+	s.Require().Error(results[3].Err)
+}
+
+func (s *ServiceTestSuite) TestCheckAvailabilityMany_PremiumAndFees() {
+	s.responses["namecheap.domains.check:example.ai"] = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.check">
+    <DomainCheckResult Domain="example.ai" Available="true" ErrorNo="0" Description="" IsPremiumName="true" PremiumRegistrationPrice="2500.00" PremiumRenewalPrice="89.88" PremiumRestorePrice="65.00" PremiumTransferPrice="89.88" IcannFee="0.18" EapFee="150.00" />
+  </CommandResponse>
+</ApiResponse>`
+	s.responses["namecheap.domains.check:example.com"] = checkResponse("example.com", "true", "false", "0")
+	s.responses["namecheap.users.getPricing"] = pricingResponseXML
+
+	results := s.service.CheckAvailabilityMany([]string{"example.ai", "example.com"})
+	s.Require().NoError(results[0].Err)
+	s.Require().True(results[0].IsPremium)
+	s.Require().Equal(2500.0, results[0].Price)
+	s.Require().Equal(2500.0, results[0].PremiumRegistrationPrice)
+	s.Require().Equal(89.88, results[0].PremiumRenewalPrice)
+	s.Require().Equal(150.0, results[0].EAPFee)
+	s.Require().Equal(0.18, results[0].ICANNFee)
+
+	// Regular names have no premium prices; missing fees are zero
+	s.Require().Equal(9.58, results[1].Price)
+	s.Require().Zero(results[1].PremiumRegistrationPrice)
+	s.Require().Zero(results[1].EAPFee)
+}
+
+func (s *ServiceTestSuite) TestExpandCandidates() {
+	s.Require().Equal([]string{"example.com", "example.io"}, ExpandCandidates("example", []string{"com", ".io", "com"}))
+	s.Require().Equal([]string{"example.net"}, ExpandCandidates("Example.com", []string{"net"}))
+}
+
+func (s *ServiceTestSuite) TestNormalizeNameservers() {
+	nameservers, err := NormalizeNameservers([]string{"NS1.Example.com.", "ns2.example.com", "ns1.example.com"})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"ns1.example.com", "ns2.example.com"}, nameservers)
+
+	_, err = NormalizeNameservers([]string{"ns1.example.com", "ns1.example.com."})
+	s.Require().ErrorContains(err, "at least 2 distinct nameservers")
+
+	_, err = NormalizeNameservers([]string{"ns1.example.com", "not a host"})
+	s.Require().Error(err)
+
+	tooMany := make([]string, 0, MaxNameservers+1)
+	for i := 0; i <= MaxNameservers; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("ns%d.example.com", i))
+	}
+	_, err = NormalizeNameservers(tooMany)
+	s.Require().ErrorContains(err, "at most 12 nameservers")
+}
+
+func (s *ServiceTestSuite) TestCheckNameserversResolve() {
+	original := lookupHost
+	defer func() { lookupHost = original }()
+	lookupHost = func(host string) ([]string, error) {
+		if host == "ns1.example.com" {
+			return []string{"192.0.2.1"}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	s.Require().NoError(CheckNameserversResolve([]string{"ns1.example.com"}))
+	s.Require().ErrorContains(CheckNameserversResolve([]string{"ns1.example.com", "ns9.example.com"}), "ns9.example.com")
+}
+
+func (s *ServiceTestSuite) TestSetNameservers_RejectsInvalidBeforeCallingAPI() {
+	err := s.service.SetNameservers("example.com", []string{"ns1.example.com"})
+	s.Require().ErrorContains(err, "at least 2 distinct nameservers")
+}
+
+func dnsGetListResponse(nameservers ...string) string {
+	hosts := ""
+	for _, ns := range nameservers {
+		hosts += "<Nameserver>" + ns + "</Nameserver>"
+	}
+	return `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.dns.getList">
+    <DomainDNSGetListResult Domain="example.com" IsUsingOurDNS="false">` + hosts + `</DomainDNSGetListResult>
+  </CommandResponse>
+</ApiResponse>`
+}
+
+const setCustomResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.dns.setCustom">
+    <DomainDNSSetCustomResult Domain="example.com" Updated="true" />
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestReadDomainList() {
+	domains, err := ReadDomainList(strings.NewReader("# migrated to the new host\nExample.com.\n\n  example.org \nexample.com\n"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"example.com", "example.org"}, domains)
+
+	_, err = ReadDomainList(strings.NewReader("example.com\nnot a domain\n"))
+	s.Require().ErrorContains(err, "line 2")
+}
+
+func (s *ServiceTestSuite) TestSetNameserversBatch() {
+	s.responses["namecheap.domains.dns.getList"] = dnsGetListResponse("dns1.registrar-servers.com", "dns2.registrar-servers.com")
+	s.responses["namecheap.domains.dns.getList:done.com"] = dnsGetListResponse("NS2.example.net", "ns1.example.net")
+	s.responses["namecheap.domains.dns.getList:missing.com"] = errorResponse
+	s.responses["namecheap.domains.dns.setCustom"] = setCustomResponse
+
+	domains := []string{"one.com", "done.com", "missing.com", "two.com"}
+	reported := 0
+	results, err := s.service.SetNameserversBatch(domains, []string{"ns1.example.net", "ns2.example.net"}, 2, false, func(NameserverResult) { reported++ })
+	s.Require().NoError(err)
+	s.Require().Equal(4, reported)
+
+	statuses := make([]NameserverStatus, 0, len(results))
+	for i, r := range results {
+		s.Require().Equal(domains[i], r.Domain, "results are in the order of the domains")
+		statuses = append(statuses, r.Status)
+	}
+	s.Require().Equal([]NameserverStatus{NameserversChanged, NameserversUnchanged, NameserversFailed, NameserversChanged}, statuses)
+	s.Require().Equal([]string{"dns1.registrar-servers.com", "dns2.registrar-servers.com"}, results[0].Previous)
+	s.Require().ElementsMatch([]string{"namecheap.domains.dns.setCustom:one.com", "namecheap.domains.dns.setCustom:two.com"}, s.requestsOf("namecheap.domains.dns.setCustom"))
+}
+
+func (s *ServiceTestSuite) TestSetNameserversBatch_DryRun() {
+	s.responses["namecheap.domains.dns.getList"] = dnsGetListResponse("dns1.registrar-servers.com", "dns2.registrar-servers.com")
+
+	results, err := s.service.SetNameserversBatch([]string{"one.com"}, []string{"ns1.example.net", "ns2.example.net"}, 4, true, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(NameserversPending, results[0].Status)
+	s.Require().Empty(s.requestsOf("namecheap.domains.dns.setCustom"), "nothing is changed")
+
+	_, err = s.service.SetNameserversBatch([]string{"one.com"}, []string{"ns1.example.net"}, 4, false, nil)
+	s.Require().ErrorContains(err, "at least 2 distinct nameservers")
+}
+
+// requestsOf returns the targets of the requests of command
+func (s *ServiceTestSuite) requestsOf(command string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var targets []string
+	for _, target := range s.targets {
+		if strings.HasPrefix(target, command+":") {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+const createResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.create">
+    <DomainCreateResult Domain="example.com" Registered="true" ChargedAmount="20.3600" DomainID="9007" OrderID="196074" TransactionID="380716" WhoisguardEnable="false" NonRealTimeDomain="false" />
+  </CommandResponse>
+</ApiResponse>`
+
+func contactFixture(firstName string) *config.Contact {
+	return &config.Contact{
+		FirstName:     firstName,
+		LastName:      "Doe",
+		Address1:      "1 Main St",
+		City:          "Springfield",
+		StateProvince: "IL",
+		PostalCode:    "62701",
+		Country:       "us",
+		Phone:         "+1.5555550100",
+		Email:         "jane@example.com",
+	}
+}
+
+func (s *ServiceTestSuite) TestRegisterDomain() {
+	s.responses["namecheap.domains.create"] = createResponse
+
+	registration, err := s.service.RegisterDomain("example.com", 2, &config.ContactProfile{
+		Registrant: contactFixture("Jane"),
+		Tech:       contactFixture("Tom"),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(&Registration{
+		Domain:        "example.com",
+		Registered:    true,
+		ChargedAmount: 20.36,
+		DomainID:      "9007",
+		OrderID:       "196074",
+		TransactionID: "380716",
+	}, registration)
+
+	form := s.forms["namecheap.domains.create"]
+	s.Require().Equal("example.com", form.Get("DomainName"))
+	s.Require().Equal("2", form.Get("Years"))
+	s.Require().Equal("Jane", form.Get("RegistrantFirstName"))
+	s.Require().Equal("Tom", form.Get("TechFirstName"))
+	s.Require().Equal("Jane", form.Get("AdminFirstName"))
+	s.Require().Equal("Jane", form.Get("AuxBillingFirstName"))
+	s.Require().Equal("US", form.Get("RegistrantCountry"))
+	s.Require().Equal("jane@example.com", form.Get("RegistrantEmailAddress"))
+}
+
+func (s *ServiceTestSuite) TestRegisterDomain_InvalidContactsRejectedBeforeCallingAPI() {
+	contact := contactFixture("Jane")
+	contact.Phone = "555-0100"
+
+	_, err := s.service.RegisterDomain("example.com", 1, &config.ContactProfile{Registrant: contact})
+	s.Require().ErrorContains(err, "registrant contact: phone")
+	s.Require().Empty(s.forms)
+}
+
+const renewResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.renew">
+    <DomainRenewResult DomainName="example.com" DomainID="151378" Renew="true" OrderID="23569" TransactionID="25080" ChargedAmount="13.1800">
+      <DomainDetails>
+        <ExpiredDate>1/20/2031 1:55:24 PM</ExpiredDate>
+        <NumYears>0</NumYears>
+      </DomainDetails>
+    </DomainRenewResult>
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestRenewDomain() {
+	s.responses["namecheap.domains.renew"] = renewResponse
+
+	renewal, err := s.service.RenewDomain("example.com", 2, "SAVE10")
+	s.Require().NoError(err)
+	s.Require().Equal("example.com", renewal.Domain)
+	s.Require().True(renewal.Renewed)
+	s.Require().Equal(13.18, renewal.ChargedAmount)
+	s.Require().Contains(renewal.Expires, "2031-01-20")
+	s.Require().Equal("23569", renewal.OrderID)
+
+	form := s.forms["namecheap.domains.renew"]
+	s.Require().Equal("2", form.Get("Years"))
+	s.Require().Equal("SAVE10", form.Get("PromotionCode"))
+}
+
+func (s *ServiceTestSuite) TestRenewDomain_APIError() {
+	s.responses["namecheap.domains.renew"] = errorResponse
+
+	_, err := s.service.RenewDomain("example.com", 1, "")
+	s.Require().ErrorContains(err, "2019166")
+	s.Require().NotContains(s.forms["namecheap.domains.renew"], "PromotionCode")
+}
+
+const getListExpiringResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.getList">
+    <DomainGetListResult>
+      <Domain ID="1" Name="later.com" User="owner" Created="02/15/2016" Expires="01/25/2030" IsExpired="false" IsLocked="true" AutoRenew="false" WhoisGuard="ENABLED" IsPremium="false" IsOurDNS="true"/>
+      <Domain ID="2" Name="sooner.com" User="owner" Created="02/15/2016" Expires="01/10/2030" IsExpired="false" IsLocked="true" AutoRenew="false" WhoisGuard="ENABLED" IsPremium="false" IsOurDNS="true"/>
+      <Domain ID="3" Name="distant.com" User="owner" Created="02/15/2016" Expires="06/01/2031" IsExpired="false" IsLocked="true" AutoRenew="false" WhoisGuard="ENABLED" IsPremium="false" IsOurDNS="true"/>
+    </DomainGetListResult>
+    <Paging><TotalItems>3</TotalItems><CurrentPage>1</CurrentPage><PageSize>100</PageSize></Paging>
+  </CommandResponse>
+</ApiResponse>`
+
+const getBalancesResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.users.getBalances">
+    <UserGetBalancesResult Currency="USD" AvailableBalance="20.50" AccountBalance="20.50" EarningAmount="0.00" WithdrawableAmount="0.00" FundsRequiredForAutoRenew="0.00" />
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestRenewalCandidates() {
+	s.responses["namecheap.domains.getList"] = getListExpiringResponse
+	s.responses["namecheap.users.getPricing"] = pricingResponseXML
+
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	candidates, err := s.service.RenewalCandidates(30*24*time.Hour, 2, now)
+	s.Require().NoError(err)
+	s.Require().Len(candidates, 2)
+	s.Require().Equal("sooner.com", candidates[0].Domain.Name)
+	s.Require().Equal("later.com", candidates[1].Domain.Name)
+	s.Require().Equal(19.16, candidates[0].Price)
+	s.Require().Equal("USD", candidates[0].Currency)
+	s.Require().Equal("RENEW", s.forms["namecheap.users.getPricing"].Get("ActionName"))
+}
+
+func (s *ServiceTestSuite) TestGetBalance() {
+	s.responses["namecheap.users.getBalances"] = getBalancesResponse
+
+	balance, err := s.service.GetBalance()
+	s.Require().NoError(err)
+	s.Require().Equal(&Balance{Currency: "USD", Available: 20.5}, balance)
+}
+
+func (s *ServiceTestSuite) TestRenewBatch_SkipsOnceBalanceRunsOut() {
+	s.responses["namecheap.domains.renew"] = renewResponse
+
+	candidates := []RenewalCandidate{
+		{Domain: Domain{Name: "one.com"}, Price: 13.18},
+		{Domain: Domain{Name: "two.com"}, Price: 13.18},
+		{Domain: Domain{Name: "three.com"}, Price: 1},
+	}
+	var reported []BatchStatus
+	results := s.service.RenewBatch(candidates, 1, 20.5, func(r BatchResult) { reported = append(reported, r.Status) })
+
+	// The second renewal is not covered by the remaining 7.32; nothing after it is attempted
+	s.Require().Equal([]BatchStatus{BatchRenewed, BatchSkipped, BatchSkipped}, reported)
+	s.Require().Equal(13.18, results[0].Renewal.ChargedAmount)
+	s.Require().ErrorContains(results[1].Err, "insufficient balance")
+	s.Require().Equal("one.com", s.forms["namecheap.domains.renew"].Get("DomainName"))
+}
+
+func (s *ServiceTestSuite) TestRenewBatch_FailureDoesNotStopBatch() {
+	s.responses["namecheap.domains.renew"] = errorResponse
+
+	results := s.service.RenewBatch([]RenewalCandidate{
+		{Domain: Domain{Name: "one.com"}, Price: 5},
+		{Domain: Domain{Name: "two.com"}, Price: 5},
+	}, 1, 20, nil)
+	s.Require().Equal(BatchFailed, results[0].Status)
+	s.Require().Equal(BatchFailed, results[1].Status)
+	s.Require().Equal("two.com", s.forms["namecheap.domains.renew"].Get("DomainName"))
+}
+
+const getContactsResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.getContacts">
+    <DomainContactsResult Domain="example.com" domainnameid="3152456">
+      <Registrant ReadOnly="false">
+        <OrganizationName>Example Inc</OrganizationName>
+        <FirstName>Jane</FirstName>
+        <LastName>Doe</LastName>
+        <Address1>1 Main St</Address1>
+        <City>Springfield</City>
+        <StateProvince>IL</StateProvince>
+        <PostalCode>62701</PostalCode>
+        <Country>US</Country>
+        <Phone>+1.5555550100</Phone>
+        <EmailAddress>jane@example.com</EmailAddress>
+      </Registrant>
+      <Tech ReadOnly="false">
+        <FirstName>Tom</FirstName>
+        <LastName>Doe</LastName>
+        <EmailAddress>tom@example.com</EmailAddress>
+      </Tech>
+      <Admin ReadOnly="false">
+        <FirstName>Ann</FirstName>
+      </Admin>
+      <AuxBilling ReadOnly="false">
+        <FirstName>Bill</FirstName>
+      </AuxBilling>
+    </DomainContactsResult>
+  </CommandResponse>
+</ApiResponse>`
+
+const setContactsResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.setContacts">
+    <DomainSetContactResult Domain="example.com" IsSuccess="true" />
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestGetContacts() {
+	s.responses["namecheap.domains.getContacts"] = getContactsResponse
+
+	contacts, err := s.service.GetContacts("example.com")
+	s.Require().NoError(err)
+	s.Require().Equal("Jane", contacts.Registrant.FirstName)
+	s.Require().Equal("Example Inc", contacts.Registrant.Organization)
+	s.Require().Equal("jane@example.com", contacts.Registrant.Email)
+	s.Require().Equal("Ann", contacts.Admin.FirstName)
+	s.Require().Equal("tom@example.com", contacts.Tech.Email)
+	s.Require().Equal("Bill", contacts.Billing.FirstName)
+	s.Require().Equal("example.com", s.forms["namecheap.domains.getContacts"].Get("DomainName"))
+}
+
+func (s *ServiceTestSuite) TestSetContacts() {
+	s.responses["namecheap.domains.setContacts"] = setContactsResponse
+
+	err := s.service.SetContacts("example.com", &config.ContactProfile{
+		Registrant: contactFixture("Jane"),
+		Admin:      contactFixture("Ann"),
+	})
+	s.Require().NoError(err)
+
+	form := s.forms["namecheap.domains.setContacts"]
+	s.Require().Equal("example.com", form.Get("DomainName"))
+	s.Require().Equal("Jane", form.Get("RegistrantFirstName"))
+	s.Require().Equal("Ann", form.Get("AdminFirstName"))
+	s.Require().Equal("Jane", form.Get("TechFirstName"))
+	s.Require().Equal("Jane", form.Get("AuxBillingFirstName"))
+}
+
+func (s *ServiceTestSuite) TestSetContacts_InvalidContactsRejectedBeforeCallingAPI() {
+	contact := contactFixture("Jane")
+	contact.Country = "USA"
+
+	err := s.service.SetContacts("example.com", &config.ContactProfile{Registrant: contact})
+	s.Require().ErrorContains(err, "registrant contact: country")
+	s.Require().Empty(s.forms)
+}
+
+const nsGetInfoResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.ns.getInfo">
+    <DomainNSInfoResult Domain="example.com" Nameserver="ns1.example.com" IP="192.0.2.53">
+      <NameserverStatuses><Status>OK</Status><Status>Linked</Status></NameserverStatuses>
+    </DomainNSInfoResult>
+  </CommandResponse>
+</ApiResponse>`
+
+const nsUpdateResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.ns.update">
+    <DomainNSUpdateResult Domain="example.com" Nameserver="ns1.example.com" IsSuccess="true" />
+  </CommandResponse>
+</ApiResponse>`
+
+const nsDeleteFailedResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.domains.ns.delete">
+    <DomainNSDeleteResult Domain="example.com" Nameserver="ns1.example.com" IsSuccess="false" />
+  </CommandResponse>
+</ApiResponse>`
+
+func (s *ServiceTestSuite) TestGlueNameserver() {
+	ns, err := GlueNameserver("example.com", "NS1")
+	s.Require().NoError(err)
+	s.Require().Equal("ns1.example.com", ns)
+
+	ns, err = GlueNameserver("example.com", "ns2.example.com.")
+	s.Require().NoError(err)
+	s.Require().Equal("ns2.example.com", ns)
+
+	_, err = GlueNameserver("example.com", "ns1.example.net")
+	s.Require().ErrorContains(err, "not under example.com")
+}
+
+func (s *ServiceTestSuite) TestGetGlue() {
+	s.responses["namecheap.domains.ns.getInfo"] = nsGetInfoResponse
+
+	glue, err := s.service.GetGlue("example.com", "ns1")
+	s.Require().NoError(err)
+	s.Require().Equal("ns1.example.com", glue.Nameserver)
+	s.Require().Equal("192.0.2.53", glue.IP)
+	s.Require().Equal([]string{"OK", "Linked"}, glue.Statuses)
+
+	form := s.forms["namecheap.domains.ns.getInfo"]
+	s.Require().Equal("example", form.Get("SLD"))
+	s.Require().Equal("com", form.Get("TLD"))
+	s.Require().Equal("ns1.example.com", form.Get("Nameserver"))
+}
+
+func (s *ServiceTestSuite) TestUpdateGlue_SendsCurrentIP() {
+	s.responses["namecheap.domains.ns.getInfo"] = nsGetInfoResponse
+	s.responses["namecheap.domains.ns.update"] = nsUpdateResponse
+
+	s.Require().NoError(s.service.UpdateGlue("example.com", "ns1.example.com", "192.0.2.54"))
+
+	form := s.forms["namecheap.domains.ns.update"]
+	s.Require().Equal("192.0.2.53", form.Get("OldIP"))
+	s.Require().Equal("192.0.2.54", form.Get("IP"))
+}
+
+func (s *ServiceTestSuite) TestCreateGlue_InvalidIPRejectedBeforeCallingAPI() {
+	s.Require().ErrorContains(s.service.CreateGlue("example.com", "ns1", "not-an-ip"), "invalid IP address")
+	s.Require().Empty(s.forms)
+}
+
+func (s *ServiceTestSuite) TestDeleteGlue_NotSuccessful() {
+	s.responses["namecheap.domains.ns.delete"] = nsDeleteFailedResponse
+
+	s.Require().ErrorContains(s.service.DeleteGlue("example.com", "ns1"), "failed to delete nameserver ns1.example.com")
+}
@@ -0,0 +1,138 @@
+// Package domaincache keeps the domain names of each account in the local
+// state store, so shell completion and "did you mean" suggestions work
+// instantly and without API calls. The cache is refreshed whenever a command
+// lists the account's domains anyway.
+package domaincache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"zonekit/pkg/state"
+)
+
+// Entry is the cached domain list of an account
+type Entry struct {
+	Domains   []string  `json:"domains"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Cache reads and writes cached domain lists
+type Cache struct {
+	store state.Store
+	now   func() time.Time
+}
+
+// New returns a cache kept in store
+func New(store state.Store) *Cache {
+	return &Cache{store: store, now: time.Now}
+}
+
+// Save replaces the cached domains of an account
+func (c *Cache) Save(account string, domains []string) error {
+	names := make([]string, 0, len(domains))
+	for _, d := range domains {
+		names = append(names, strings.ToLower(strings.TrimSuffix(d, ".")))
+	}
+	sort.Strings(names)
+
+	entry := Entry{Domains: names, UpdatedAt: c.now().UTC()}
+	if err := c.store.Put(state.BucketDomains, account, entry); err != nil {
+		return fmt.Errorf("failed to cache domains of %s: %w", account, err)
+	}
+	return nil
+}
+
+// Load returns the cached domains of an account, or nil if none are cached
+func (c *Cache) Load(account string) (*Entry, error) {
+	var entry Entry
+	ok, err := c.store.Get(state.BucketDomains, account, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached domains of %s: %w", account, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Complete returns the cached domains of an account that start with prefix
+func (c *Cache) Complete(account, prefix string) []string {
+	entry, err := c.Load(account)
+	if err != nil || entry == nil {
+		return nil
+	}
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, d := range entry.Domains {
+		if strings.HasPrefix(d, prefix) {
+			matches = append(matches, d)
+		}
+	}
+	return matches
+}
+
+// Contains reports whether name is one of the entry's domains
+func (e *Entry) Contains(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	i := sort.SearchStrings(e.Domains, name)
+	return i < len(e.Domains) && e.Domains[i] == name
+}
+
+// Suggest returns the known domain closest to a mistyped name, or "" if none
+// is close enough. Names within two edits (insertions, deletions,
+// substitutions or swaps of adjacent characters) are considered, and fewer
+// for short names; ties go to the alphabetically first domain.
+func Suggest(name string, known []string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	maxDistance := 2
+	if len(name) < 6 {
+		maxDistance = 1
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range known {
+		candidate = strings.ToLower(candidate)
+		if candidate == name {
+			return ""
+		}
+		if d := distance(name, candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// distance returns the optimal string alignment distance between a and b: the
+// Levenshtein distance where swapping two adjacent characters counts as one
+// edit, the most common typo
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// prev2, prev and cur are the last three rows of the distance matrix
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
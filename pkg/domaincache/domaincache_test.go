@@ -0,0 +1,57 @@
+package domaincache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"zonekit/pkg/state"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_SaveLoadComplete(t *testing.T) {
+	store := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	t.Cleanup(func() { store.Close() })
+	cache := New(store)
+
+	entry, err := cache.Load("work")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	require.NoError(t, cache.Save("work", []string{"Example.org", "example.com.", "other.net"}))
+	entry, err = cache.Load("work")
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com", "example.org", "other.net"}, entry.Domains)
+	require.False(t, entry.UpdatedAt.IsZero())
+	require.True(t, entry.Contains("EXAMPLE.com"))
+	require.False(t, entry.Contains("example.net"))
+
+	require.Equal(t, []string{"example.com", "example.org"}, cache.Complete("work", "Ex"))
+	require.Len(t, cache.Complete("work", ""), 3)
+	require.Empty(t, cache.Complete("personal", ""))
+}
+
+func TestSuggest(t *testing.T) {
+	known := []string{"example.com", "example.org", "zonekit.dev"}
+
+	tests := map[string]string{
+		"exmaple.com": "example.com", // swapped letters
+		"example.con": "example.com",
+		"examle.org":  "example.org",
+		"zonekt.dev":  "zonekit.dev",
+		"example.com": "",
+		"another.net": "",
+		"a.io":        "",
+	}
+	for name, want := range tests {
+		require.Equal(t, want, Suggest(name, known), name)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	require.Equal(t, 0, distance("abc", "abc"))
+	require.Equal(t, 1, distance("abc", "acb"))
+	require.Equal(t, 1, distance("abc", "abcd"))
+	require.Equal(t, 3, distance("", "abc"))
+	require.Equal(t, 3, distance("kitten", "sitting"))
+}
@@ -44,6 +44,26 @@ func NewNotFound(resource, id string) *ErrNotFound {
 	}
 }
 
+// ErrConflict represents a conflict with an existing resource
+type ErrConflict struct {
+	Resource string
+	ID       string
+	Message  string
+}
+
+func (e *ErrConflict) Error() string {
+	return fmt.Sprintf("conflicting %s '%s': %s", e.Resource, e.ID, e.Message)
+}
+
+// NewConflict creates a new conflict error
+func NewConflict(resource, id, message string) *ErrConflict {
+	return &ErrConflict{
+		Resource: resource,
+		ID:       id,
+		Message:  message,
+	}
+}
+
 // ErrConfiguration represents a configuration error
 type ErrConfiguration struct {
 	Message string
@@ -68,10 +88,14 @@ type ErrAPI struct {
 }
 
 func (e *ErrAPI) Error() string {
+	msg := e.Message
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
 	if e.Operation != "" {
-		return fmt.Sprintf("API error in %s: %s", e.Operation, e.Message)
+		return fmt.Sprintf("API error in %s: %s", e.Operation, msg)
 	}
-	return fmt.Sprintf("API error: %s", e.Message)
+	return fmt.Sprintf("API error: %s", msg)
 }
 
 func (e *ErrAPI) Unwrap() error {
@@ -54,6 +54,14 @@ func (s *ErrorsTestSuite) TestErrNotFound_WithoutID() {
 	s.Require().NotContains(err.Error(), "'")
 }
 
+func (s *ErrorsTestSuite) TestErrConflict() {
+	err := NewConflict("DNS record", "www A", "www A 192.0.2.1 already exists")
+	s.Require().NotNil(err)
+	s.Require().Equal("DNS record", err.Resource)
+	s.Require().Equal("www A", err.ID)
+	s.Require().Equal("conflicting DNS record 'www A': www A 192.0.2.1 already exists", err.Error())
+}
+
 func (s *ErrorsTestSuite) TestErrConfiguration() {
 	err := NewConfiguration("missing required field")
 	s.Require().NotNil(err)
@@ -0,0 +1,321 @@
+// Package exporter serves domain expiry, DNS drift and provider health as
+// Prometheus metrics, so monitoring stacks can alert on DNS hygiene.
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"zonekit/pkg/diffview"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/domain"
+	"zonekit/pkg/zonefile"
+)
+
+// DomainLister lists the domains registered in an account
+type DomainLister interface {
+	ListDomains() ([]domain.Domain, error)
+}
+
+// RecordGetter reads the live records of a zone
+type RecordGetter interface {
+	GetRecords(domainName string) ([]dnsrecord.Record, error)
+}
+
+// SerialGetter is implemented by record sources that can read a zone's SOA
+// serial. An unchanged serial lets a refresh skip reading the zone's records.
+type SerialGetter interface {
+	ZoneSerial(domainName string) (uint32, error)
+}
+
+// Config describes what the exporter collects
+type Config struct {
+	// Registrar labels the provider_up metric of the domain listing
+	Registrar string
+	Domains   DomainLister
+
+	// Provider labels the DNS provider serving the zones
+	Provider string
+	// NewRecordGetter returns the record source for one refresh. A new source
+	// per refresh keeps per-invocation record caches from serving stale zones.
+	NewRecordGetter func() RecordGetter
+	// ZoneFiles maps zones to the zone file holding their desired records;
+	// drift is the number of records that differ from the live zone. Drift is
+	// only computed again once the live zone's version or the zone file changes.
+	ZoneFiles map[string]string
+
+	// Log receives refresh errors; nil discards them
+	Log io.Writer
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+}
+
+// Exporter collects metrics on demand or on an interval and serves the result
+// of the last refresh
+type Exporter struct {
+	config Config
+
+	// refreshing serializes refreshes, which update zones
+	refreshing sync.Mutex
+	zones      map[string]zoneDrift
+
+	mu      sync.RWMutex
+	metrics []byte
+}
+
+// zoneDrift is the drift of a zone computed for a live zone version and zone
+// file content
+type zoneDrift struct {
+	live    dns.ZoneVersion
+	desired string
+	records int
+}
+
+// New creates an exporter. Nothing is collected until Refresh or Run is called.
+func New(config Config) *Exporter {
+	if config.Log == nil {
+		config.Log = io.Discard
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Exporter{config: config, zones: make(map[string]zoneDrift)}
+}
+
+// sample is a single metric value with its labels
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// family is a metric with its help text and samples
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// Refresh collects all metrics and replaces the served ones
+func (e *Exporter) Refresh() {
+	e.refreshing.Lock()
+	defer e.refreshing.Unlock()
+	now := e.config.Now()
+
+	expiry := family{name: "zonekit_domain_expiry_days", help: "Days until the domain registration expires; negative once expired."}
+	drift := family{name: "zonekit_zone_drift_records", help: "Records of the live zone that differ from its zone file."}
+	// A provider is up if every call to it succeeded
+	providersUp := make(map[string]bool)
+	markUp := func(provider string, ok bool) {
+		if previous, seen := providersUp[provider]; seen {
+			ok = ok && previous
+		}
+		providersUp[provider] = ok
+	}
+
+	if e.config.Domains != nil {
+		domains, err := e.config.Domains.ListDomains()
+		if err != nil {
+			fmt.Fprintf(e.config.Log, "failed to list domains: %v\n", err)
+		}
+		markUp(e.config.Registrar, err == nil)
+
+		for _, d := range domains {
+			expires, ok := d.ExpiresAt()
+			if !ok {
+				continue
+			}
+			days := math.Floor(expires.Sub(now).Hours() / 24)
+			expiry.samples = append(expiry.samples, sample{labels: [][2]string{{"domain", d.Name}}, value: days})
+		}
+	}
+
+	if len(e.config.ZoneFiles) > 0 && e.config.NewRecordGetter != nil {
+		records := e.config.NewRecordGetter()
+
+		zones := make([]string, 0, len(e.config.ZoneFiles))
+		for zone := range e.config.ZoneFiles {
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+
+		for _, zone := range zones {
+			desired, err := readZoneFile(e.config.ZoneFiles[zone], zone)
+			if err != nil {
+				fmt.Fprintf(e.config.Log, "failed to read zone file of %s: %v\n", zone, err)
+				continue
+			}
+			changed, err := e.zoneDrift(records, zone, desired)
+			if err != nil {
+				fmt.Fprintf(e.config.Log, "failed to get records of %s: %v\n", zone, err)
+				markUp(e.config.Provider, false)
+				continue
+			}
+			markUp(e.config.Provider, true)
+
+			drift.samples = append(drift.samples, sample{
+				labels: [][2]string{{"zone", zone}, {"provider", e.config.Provider}},
+				value:  float64(changed),
+			})
+		}
+	}
+
+	up := family{name: "zonekit_provider_up", help: "Whether the last refresh could reach the provider (1) or not (0)."}
+	providers := make([]string, 0, len(providersUp))
+	for provider := range providersUp {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		up.samples = append(up.samples, sample{labels: [][2]string{{"provider", provider}}, value: boolValue(providersUp[provider])})
+	}
+
+	refreshed := family{
+		name:    "zonekit_last_refresh_timestamp_seconds",
+		help:    "Unix time of the last refresh.",
+		samples: []sample{{value: float64(now.Unix())}},
+	}
+
+	var buf bytes.Buffer
+	for _, f := range []family{expiry, drift, up, refreshed} {
+		writeFamily(&buf, f)
+	}
+
+	e.mu.Lock()
+	e.metrics = buf.Bytes()
+	e.mu.Unlock()
+}
+
+// zoneDrift returns the number of live records of a zone that differ from the
+// desired ones. While the zone file is unchanged, an unchanged SOA serial reuses
+// the last drift without reading the records, and an unchanged record hash
+// reuses it without comparing them.
+func (e *Exporter) zoneDrift(records RecordGetter, zone string, desired []dnsrecord.Record) (int, error) {
+	desiredHash := dns.ZoneHash(desired)
+	last, known := e.zones[zone]
+	known = known && last.desired == desiredHash
+
+	var live dns.ZoneVersion
+	if sg, ok := records.(SerialGetter); ok {
+		if serial, err := sg.ZoneSerial(zone); err == nil {
+			live.Serial = serial
+		}
+	}
+	if known && live.Serial != 0 && live.Serial == last.live.Serial {
+		return last.records, nil
+	}
+
+	current, err := records.GetRecords(zone)
+	if err != nil {
+		return 0, err
+	}
+	live.Hash = dns.ZoneHash(current)
+
+	changed := 0
+	switch {
+	case known && live.Hash == last.live.Hash:
+		changed = last.records
+	case live.Hash == desiredHash:
+		// Identical records; nothing to compare
+	default:
+		diff := diffview.Compute(zone, current, desired)
+		changed = diff.Count(diffview.Added) + diff.Count(diffview.Changed) + diff.Count(diffview.Removed)
+	}
+	e.zones[zone] = zoneDrift{live: live, desired: desiredHash, records: changed}
+	return changed, nil
+}
+
+// Run refreshes the metrics every interval until stop is closed. The first
+// refresh happens immediately.
+func (e *Exporter) Run(interval time.Duration, stop <-chan struct{}) {
+	e.Refresh()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			e.Refresh()
+		}
+	}
+}
+
+// ServeHTTP serves the metrics of the last refresh in the Prometheus text format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	metrics := e.metrics
+	e.mu.RUnlock()
+
+	if metrics == nil {
+		http.Error(w, "metrics are not collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(metrics)
+}
+
+// readZoneFile reads the desired records of a zone, leaving out the apex NS
+// records the provider manages, as dns import does
+func readZoneFile(path, zone string) ([]dnsrecord.Record, error) {
+	parsed, err := zonefile.ParseFile(path, zone)
+	if err != nil {
+		return nil, err
+	}
+	desired := make([]dnsrecord.Record, 0, len(parsed))
+	for _, record := range parsed {
+		if record.HostName == "@" && record.RecordType == dnsrecord.RecordTypeNS {
+			continue
+		}
+		desired = append(desired, record)
+	}
+	return desired, nil
+}
+
+// writeFamily writes a gauge in the Prometheus text exposition format
+func writeFamily(w io.Writer, f family) {
+	if len(f.samples) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", f.name)
+	for _, s := range f.samples {
+		fmt.Fprint(w, f.name)
+		if len(s.labels) > 0 {
+			pairs := make([]string, 0, len(s.labels))
+			for _, l := range s.labels {
+				pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", l[0], escapeLabel(l[1])))
+			}
+			fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+		}
+		fmt.Fprintf(w, " %s\n", formatValue(s.value))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return fmt.Sprintf("%d", int64(value))
+	}
+	return fmt.Sprintf("%g", value)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,220 @@
+package exporter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/domain"
+)
+
+// fakeDomains lists fixed domains
+type fakeDomains struct {
+	domains []domain.Domain
+	err     error
+}
+
+func (f *fakeDomains) ListDomains() ([]domain.Domain, error) {
+	return f.domains, f.err
+}
+
+// fakeRecords serves fixed records per zone and counts reads
+type fakeRecords struct {
+	zones map[string][]dnsrecord.Record
+	reads int
+}
+
+func (f *fakeRecords) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	f.reads++
+	records, ok := f.zones[domainName]
+	if !ok {
+		return nil, errors.New("zone not found")
+	}
+	return records, nil
+}
+
+// serialRecords also serves a fixed SOA serial per zone
+type serialRecords struct {
+	*fakeRecords
+	serials map[string]uint32
+}
+
+func (f *serialRecords) ZoneSerial(domainName string) (uint32, error) {
+	serial, ok := f.serials[domainName]
+	if !ok {
+		return 0, errors.New("zone not found")
+	}
+	return serial, nil
+}
+
+// ExporterTestSuite tests metric collection and exposition
+type ExporterTestSuite struct {
+	suite.Suite
+	now     time.Time
+	domains *fakeDomains
+	records *fakeRecords
+	config  Config
+}
+
+func TestExporterSuite(t *testing.T) {
+	suite.Run(t, new(ExporterTestSuite))
+}
+
+func (s *ExporterTestSuite) SetupTest() {
+	s.now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.domains = &fakeDomains{domains: []domain.Domain{
+		{Name: "example.com", Expires: "2026-01-31 00:00:00 +0000 UTC"},
+		{Name: "old.org", Expires: "2025-12-30 00:00:00 +0000 UTC"},
+		{Name: "unknown.net"},
+	}}
+	s.records = &fakeRecords{zones: map[string][]dnsrecord.Record{
+		"example.com": {
+			{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+			{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 1800},
+			{HostName: "old", RecordType: "A", Address: "192.0.2.9", TTL: 1800},
+		},
+	}}
+
+	zoneFile := filepath.Join(s.T().TempDir(), "example.com.zone")
+	s.writeZoneFile(zoneFile, "192.0.2.2")
+
+	s.config = Config{
+		Registrar:       "namecheap",
+		Domains:         s.domains,
+		Provider:        "namecheap",
+		NewRecordGetter: func() RecordGetter { return s.records },
+		ZoneFiles:       map[string]string{"example.com": zoneFile},
+		Now:             func() time.Time { return s.now },
+	}
+}
+
+// writeZoneFile writes a zone file whose apex A record points at address
+func (s *ExporterTestSuite) writeZoneFile(path, address string) {
+	s.Require().NoError(os.WriteFile(path, []byte(`$ORIGIN example.com.
+$TTL 1800
+@    IN NS    dns1.registrar-servers.com.
+@    IN A     `+address+`
+www  IN CNAME example.com.
+`), 0644))
+}
+
+func (s *ExporterTestSuite) scrape(e *Exporter) (int, string) {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Code, rec.Body.String()
+}
+
+func (s *ExporterTestSuite) TestRefresh() {
+	e := New(s.config)
+	e.Refresh()
+
+	code, body := s.scrape(e)
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, "# TYPE zonekit_domain_expiry_days gauge\n")
+	s.Require().Contains(body, `zonekit_domain_expiry_days{domain="example.com"} 29`+"\n")
+	s.Require().Contains(body, `zonekit_domain_expiry_days{domain="old.org"} -3`+"\n")
+	s.Require().NotContains(body, "unknown.net")
+	// @ A changes and old A is removed; the apex NS record is left to the provider
+	s.Require().Contains(body, `zonekit_zone_drift_records{zone="example.com",provider="namecheap"} 2`+"\n")
+	s.Require().Contains(body, `zonekit_provider_up{provider="namecheap"} 1`+"\n")
+	s.Require().Contains(body, "zonekit_last_refresh_timestamp_seconds 1767268800\n")
+}
+
+func (s *ExporterTestSuite) TestRefresh_ProviderDown() {
+	s.config.Provider = "cloudflare"
+	s.records.zones = nil
+	e := New(s.config)
+	e.Refresh()
+
+	_, body := s.scrape(e)
+	s.Require().Contains(body, `zonekit_provider_up{provider="cloudflare"} 0`+"\n")
+	s.Require().Contains(body, `zonekit_provider_up{provider="namecheap"} 1`+"\n")
+	s.Require().NotContains(body, "zonekit_zone_drift_records")
+}
+
+func (s *ExporterTestSuite) TestRefresh_UsesNewRecordGetterEachTime() {
+	getters := 0
+	s.config.NewRecordGetter = func() RecordGetter {
+		getters++
+		return s.records
+	}
+	e := New(s.config)
+	e.Refresh()
+	e.Refresh()
+	s.Require().Equal(2, getters)
+}
+
+func (s *ExporterTestSuite) TestRefresh_UnchangedSerialSkipsRecords() {
+	records := &serialRecords{fakeRecords: s.records, serials: map[string]uint32{"example.com": 1}}
+	s.config.NewRecordGetter = func() RecordGetter { return records }
+	e := New(s.config)
+	const drift = `zonekit_zone_drift_records{zone="example.com",provider="namecheap"} `
+
+	e.Refresh()
+	e.Refresh()
+	s.Require().Equal(1, s.records.reads)
+	_, body := s.scrape(e)
+	s.Require().Contains(body, drift+"2\n")
+
+	// A new serial means the records are read and compared again
+	records.serials["example.com"] = 2
+	s.records.zones["example.com"] = s.records.zones["example.com"][:2]
+	e.Refresh()
+	s.Require().Equal(2, s.records.reads)
+	_, body = s.scrape(e)
+	s.Require().Contains(body, drift+"1\n")
+
+	// So does a change of the zone file, even with the same serial
+	s.writeZoneFile(s.config.ZoneFiles["example.com"], "192.0.2.1")
+	e.Refresh()
+	s.Require().Equal(3, s.records.reads)
+	_, body = s.scrape(e)
+	s.Require().Contains(body, drift+"0\n")
+}
+
+func (s *ExporterTestSuite) TestRefresh_WithoutSerialReadsRecords() {
+	e := New(s.config)
+	e.Refresh()
+	e.Refresh()
+	s.Require().Equal(2, s.records.reads)
+	_, body := s.scrape(e)
+	s.Require().Contains(body, `zonekit_zone_drift_records{zone="example.com",provider="namecheap"} 2`+"\n")
+}
+
+func (s *ExporterTestSuite) TestServeHTTP_BeforeFirstRefresh() {
+	code, _ := s.scrape(New(s.config))
+	s.Require().Equal(http.StatusServiceUnavailable, code)
+}
+
+func (s *ExporterTestSuite) TestRun_StopsWhenClosed() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	e := New(s.config)
+	go func() {
+		e.Run(time.Hour, stop)
+		close(done)
+	}()
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.Fail("Run did not stop")
+	}
+	code, _ := s.scrape(e)
+	s.Require().Equal(http.StatusOK, code)
+}
+
+func (s *ExporterTestSuite) TestEscapeLabel() {
+	s.Require().Equal(`a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+	var sb strings.Builder
+	writeFamily(&sb, family{name: "m", help: "h", samples: []sample{{value: 0.5}}})
+	s.Require().Equal("# HELP m h\n# TYPE m gauge\nm 0.5\n", sb.String())
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+)
+
+// RenderMarkdown renders entries as a Markdown changelog suitable for incident
+// reviews and change tickets. since is only used for the heading. Times are
+// written with formatTime, or in UTC if it is nil.
+func RenderMarkdown(domainName string, since time.Time, entries []Entry, formatTime func(time.Time) string) string {
+	if formatTime == nil {
+		formatTime = func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") }
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# DNS changelog for %s\n\n", domainName)
+	if !since.IsZero() {
+		fmt.Fprintf(&sb, "_Changes since %s_\n\n", formatTime(since))
+	}
+
+	if len(entries) == 0 {
+		sb.WriteString("No changes recorded.\n")
+		return sb.String()
+	}
+
+	for _, entry := range entries {
+		who := entry.User
+		if who == "" {
+			who = "unknown user"
+		}
+		fmt.Fprintf(&sb, "## %s — %s\n\n", formatTime(entry.Time), who)
+
+		var meta []string
+		if entry.Account != "" {
+			meta = append(meta, fmt.Sprintf("account `%s`", entry.Account))
+		}
+		if entry.Provider != "" {
+			meta = append(meta, fmt.Sprintf("provider `%s`", entry.Provider))
+		}
+		if len(meta) > 0 {
+			fmt.Fprintf(&sb, "- Via %s\n", strings.Join(meta, ", "))
+		}
+		if entry.Command != "" {
+			fmt.Fprintf(&sb, "- Command: `%s`\n", entry.Command)
+		}
+		if entry.Emergency {
+			sb.WriteString("- Emergency change\n")
+		}
+		sb.WriteString("\n")
+
+		for _, c := range entry.Changes {
+			sb.WriteString(formatChange(c))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatChange renders one change as a Markdown list item
+func formatChange(c Change) string {
+	record := fmt.Sprintf("`%s %s`", c.HostName, c.Type)
+	if c.Error != "" {
+		// "created" is the change attempted, e.g. "create"
+		return fmt.Sprintf("- **Failed** to %s %s: %s\n", strings.TrimSuffix(string(c.Action), "d"), record, c.Error)
+	}
+	switch c.Action {
+	case provider.ApplyCreated:
+		return fmt.Sprintf("- **Created** %s → `%s`%s\n", record, c.Value, formatTTL(c.TTL))
+	case provider.ApplyUpdated:
+		detail := fmt.Sprintf("`%s` → `%s`", c.PreviousValue, c.Value)
+		if c.PreviousValue == c.Value {
+			detail = fmt.Sprintf("`%s`", c.Value)
+		}
+		if c.PreviousTTL != c.TTL && c.TTL > 0 {
+			detail += fmt.Sprintf(" (TTL %s → %s)", dnsrecord.FormatTTL(c.PreviousTTL), dnsrecord.FormatTTL(c.TTL))
+		}
+		return fmt.Sprintf("- **Updated** %s: %s\n", record, detail)
+	case provider.ApplyDeleted:
+		return fmt.Sprintf("- **Deleted** %s (was `%s`)\n", record, c.Value)
+	default:
+		return fmt.Sprintf("- %s %s `%s`\n", c.Action, record, c.Value)
+	}
+}
+
+func formatTTL(ttl int) string {
+	if ttl <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (TTL %s)", dnsrecord.FormatTTL(ttl))
+}
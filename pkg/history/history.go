@@ -0,0 +1,344 @@
+// Package history keeps the DNS changes zonekit applies in the local state
+// store: who applied them, when, from which host, through which account and
+// provider, and each record before and after, including changes that failed.
+// Entries are rendered as a human-readable changelog and passed on to the
+// audit log.
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+)
+
+// Record is a DNS record as written to the history
+type Record struct {
+	HostName string   `json:"hostname"`
+	Type     string   `json:"type"`
+	Value    string   `json:"value"`
+	TTL      int      `json:"ttl,omitempty"`
+	MXPref   int      `json:"mx_pref,omitempty"`
+	Comment  string   `json:"comment,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// String formats the record as "host TYPE value"
+func (r Record) String() string {
+	if r.MXPref > 0 {
+		return fmt.Sprintf("%s %s %d %s", r.HostName, r.Type, r.MXPref, r.Value)
+	}
+	return fmt.Sprintf("%s %s %s", r.HostName, r.Type, r.Value)
+}
+
+// Change is a single record change within an entry
+type Change struct {
+	Action        provider.ApplyStatus `json:"action"`
+	HostName      string               `json:"hostname"`
+	Type          string               `json:"type"`
+	Value         string               `json:"value"`
+	TTL           int                  `json:"ttl,omitempty"`
+	PreviousValue string               `json:"previous_value,omitempty"`
+	PreviousTTL   int                  `json:"previous_ttl,omitempty"`
+	// Before and After are the whole record before and after the change;
+	// Before is empty for created records and After for deleted ones. Entries
+	// recorded by older releases have neither.
+	Before *Record `json:"before,omitempty"`
+	After  *Record `json:"after,omitempty"`
+	// Error is set when the change failed; Action is then the change attempted
+	Error string `json:"error,omitempty"`
+}
+
+// Entry is one applied change set
+type Entry struct {
+	// ID identifies the entry; entries recorded by older releases have none
+	ID       string    `json:"id,omitempty"`
+	Time     time.Time `json:"time"`
+	Domain   string    `json:"domain"`
+	Provider string    `json:"provider"`
+	Account  string    `json:"account,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	// Emergency marks emergency changes, which are allowed outside change windows
+	Emergency bool     `json:"emergency,omitempty"`
+	Changes   []Change `json:"changes"`
+}
+
+// Failed returns the number of changes that failed
+func (e Entry) Failed() int {
+	n := 0
+	for _, c := range e.Changes {
+		if c.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Store keeps change sets in the history bucket of the local state store
+type Store struct {
+	state state.Store
+}
+
+// NewStore creates a history backed by a state store
+func NewStore(st state.Store) *Store {
+	return &Store{state: st}
+}
+
+// Append adds an entry to the history
+func (s *Store) Append(entry Entry) error {
+	name := entry.Domain
+	if entry.ID != "" {
+		// Entries for a domain recorded at the same time must not replace each other
+		name += "/" + entry.ID
+	}
+	key := state.TimeKey(entry.Time, name)
+	if err := s.state.Put(state.BucketHistory, key, entry); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// Query returns the entries for a domain recorded at or after since, oldest first.
+// An empty domain matches all domains; a zero since matches all entries.
+func (s *Store) Query(domainName string, since time.Time) ([]Entry, error) {
+	items, err := s.state.List(state.BucketHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	// Keys are time based, so items come oldest first
+	var entries []Entry
+	for _, item := range items {
+		var entry Entry
+		if err := json.Unmarshal(item.Value, &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry %s: %w", item.Key, err)
+		}
+
+		if domainName != "" && !strings.EqualFold(entry.Domain, domainName) {
+			continue
+		}
+		if !since.IsZero() && entry.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Find returns the entry with id, or the only entry whose ID starts with it
+func (s *Store) Find(id string) (Entry, error) {
+	entries, err := s.Query("", time.Time{})
+	if err != nil {
+		return Entry{}, err
+	}
+	var found []Entry
+	for _, entry := range entries {
+		if entry.ID == "" {
+			continue
+		}
+		if entry.ID == id {
+			return entry, nil
+		}
+		if strings.HasPrefix(entry.ID, id) {
+			found = append(found, entry)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Entry{}, fmt.Errorf("history entry %s not found", id)
+	case 1:
+		return found[0], nil
+	default:
+		return Entry{}, fmt.Errorf("history entry ID %s is ambiguous, it matches %d entries", id, len(found))
+	}
+}
+
+// Auditor receives each recorded entry, e.g. to append it to the audit log
+type Auditor interface {
+	AuditChanges(entry Entry) error
+}
+
+// Recorder writes apply results to a Store, tagging them with who ran which
+// command from which host
+type Recorder struct {
+	// Store keeps the entries; without one they only go to Audit
+	Store   *Store
+	Account string
+	User    string
+	Host    string
+	Command string
+	// Emergency marks the recorded changes as emergency changes
+	Emergency bool
+	// Audit, if set, receives each entry after it is written
+	Audit Auditor
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewRecorder creates a recorder for the current OS user and host
+func NewRecorder(store *Store, account, command string) *Recorder {
+	host, _ := os.Hostname()
+	return &Recorder{
+		Store:   store,
+		Account: account,
+		User:    currentUser(),
+		Host:    host,
+		Command: command,
+		now:     time.Now,
+	}
+}
+
+// Record appends the changes of an apply result, including the ones that
+// failed. Unchanged records are skipped; nothing is written if no record was
+// changed or attempted.
+func (r *Recorder) Record(domainName, providerName string, result *provider.ApplyResult) error {
+	if result == nil {
+		return nil
+	}
+
+	var changes []Change
+	for _, rec := range result.Records {
+		if rec.Status == provider.ApplyUnchanged {
+			continue
+		}
+		changes = append(changes, newChange(rec))
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+
+	entry := Entry{
+		ID:        newID(),
+		Time:      now().UTC(),
+		Domain:    strings.ToLower(strings.TrimSuffix(domainName, ".")),
+		Provider:  providerName,
+		Account:   r.Account,
+		User:      r.User,
+		Host:      r.Host,
+		Command:   r.Command,
+		Emergency: r.Emergency,
+		Changes:   changes,
+	}
+	var storeErr error
+	if r.Store != nil {
+		storeErr = r.Store.Append(entry)
+	}
+	if r.Audit != nil {
+		// The audit log gets the entry even if the history could not keep it
+		if err := r.Audit.AuditChanges(entry); err != nil {
+			return errors.Join(storeErr, err)
+		}
+	}
+	return storeErr
+}
+
+// newChange converts the outcome of applying a record
+func newChange(rec provider.RecordResult) Change {
+	change := Change{
+		Action:   rec.Status,
+		HostName: rec.Record.HostName,
+		Type:     rec.Record.RecordType,
+		Value:    rec.Record.Address,
+		TTL:      rec.Record.TTL,
+	}
+	if rec.Status == provider.ApplyFailed {
+		change.Action = rec.Action
+		if rec.Err != nil {
+			change.Error = rec.Err.Error()
+		}
+	}
+
+	switch change.Action {
+	case provider.ApplyCreated:
+		change.After = newRecord(rec.Record)
+	case provider.ApplyDeleted:
+		if rec.Previous != nil {
+			change.Before = newRecord(*rec.Previous)
+		} else {
+			change.Before = newRecord(rec.Record)
+		}
+	default:
+		if rec.Previous != nil {
+			change.Before = newRecord(*rec.Previous)
+			change.PreviousValue = rec.Previous.Address
+			change.PreviousTTL = rec.Previous.TTL
+		}
+		change.After = newRecord(rec.Record)
+	}
+	return change
+}
+
+func newRecord(r dnsrecord.Record) *Record {
+	return &Record{
+		HostName: r.HostName,
+		Type:     r.RecordType,
+		Value:    r.Address,
+		TTL:      r.TTL,
+		MXPref:   r.MXPref,
+		Comment:  r.Comment,
+		Tags:     r.Tags,
+	}
+}
+
+// newID returns a random entry ID
+func newID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%012x", time.Now().UnixNano()&0xffffffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// currentUser returns the OS user name, or an empty string if unknown
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// ParseSince parses a relative duration such as "30d", "2w", "12h" or "90m"
+func ParseSince(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("duration is empty")
+	}
+
+	unit := value[len(value)-1]
+	switch unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	default:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration %q (use e.g. 30d, 2w, 12h)", value)
+		}
+		return d, nil
+	}
+}
@@ -0,0 +1,242 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/state"
+)
+
+// HistoryTestSuite is a test suite for the change history
+type HistoryTestSuite struct {
+	suite.Suite
+	store *Store
+}
+
+// TestHistorySuite runs the history test suite
+func TestHistorySuite(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
+
+func (s *HistoryTestSuite) SetupTest() {
+	s.store = NewStore(state.NewFileStore(filepath.Join(s.T().TempDir(), "state.json")))
+}
+
+func (s *HistoryTestSuite) recorderAt(t time.Time) *Recorder {
+	return &Recorder{Store: s.store, Account: "prod", User: "alice", Host: "ops1", Command: "zonekit dns update example.com www A 192.0.2.2", now: func() time.Time { return t }}
+}
+
+func (s *HistoryTestSuite) TestRecorder_RecordsBeforeAndAfter() {
+	existing := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 1800},
+		{HostName: "old", RecordType: "CNAME", Address: "example.net.", TTL: 1800},
+	}
+	desired := []dnsrecord.Record{
+		{HostName: "www", RecordType: "A", Address: "192.0.2.2", TTL: 1800},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 1800},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.3", TTL: 300},
+	}
+	result := provider.PlanResults("example.com", existing, desired)
+	result.Fail(2, errors.New("provider error"))
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.Require().NoError(s.recorderAt(now).Record("Example.com.", "namecheap", result))
+
+	entries, err := s.store.Query("example.com", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+
+	entry := entries[0]
+	s.Require().Len(entry.ID, 12)
+	s.Require().Equal("example.com", entry.Domain)
+	s.Require().Equal("prod", entry.Account)
+	s.Require().Equal("alice", entry.User)
+	s.Require().Equal("ops1", entry.Host)
+	s.Require().Len(entry.Changes, 3)
+	s.Require().False(entry.Emergency)
+
+	updated := entry.Changes[0]
+	s.Require().Equal(provider.ApplyUpdated, updated.Action)
+	s.Require().Equal("192.0.2.1", updated.PreviousValue)
+	s.Require().Equal("192.0.2.1", updated.Before.Value)
+	s.Require().Equal("192.0.2.2", updated.After.Value)
+
+	failed := entry.Changes[1]
+	s.Require().Equal(provider.ApplyCreated, failed.Action)
+	s.Require().Nil(failed.Before)
+	s.Require().Equal("provider error", failed.Error)
+	s.Require().Equal(1, entry.Failed())
+
+	deleted := entry.Changes[2]
+	s.Require().Equal(provider.ApplyDeleted, deleted.Action)
+	s.Require().Equal("old CNAME example.net.", deleted.Before.String())
+	s.Require().Nil(deleted.After)
+
+	out := RenderMarkdown("example.com", time.Time{}, entries, nil)
+	s.Require().Contains(out, "- **Failed** to create `api A`: provider error")
+}
+
+// auditedEntries collects the entries passed to the audit log
+type auditedEntries struct {
+	entries []Entry
+	err     error
+}
+
+func (a *auditedEntries) AuditChanges(entry Entry) error {
+	a.entries = append(a.entries, entry)
+	return a.err
+}
+
+func (s *HistoryTestSuite) TestRecorder_PassesEntriesToAudit() {
+	audited := &auditedEntries{}
+	recorder := s.recorderAt(time.Now())
+	recorder.Audit = audited
+	created := provider.PlanResults("example.com", nil, []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}})
+	s.Require().NoError(recorder.Record("example.com", "namecheap", created))
+
+	entries, err := s.store.Query("", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Equal(entries, audited.entries)
+
+	// Without a store, entries still reach the audit log
+	recorder.Store = nil
+	audited.err = errors.New("disk full")
+	s.Require().ErrorContains(recorder.Record("example.com", "namecheap", created), "disk full")
+	s.Require().Len(audited.entries, 2)
+}
+
+func (s *HistoryTestSuite) TestStore_Find() {
+	created := provider.PlanResults("example.com", nil, []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}})
+	s.Require().NoError(s.recorderAt(time.Now()).Record("example.com", "namecheap", created))
+	// Entries of older releases have no ID and are never found
+	s.Require().NoError(s.store.Append(Entry{Time: time.Now(), Domain: "example.org", Provider: "namecheap"}))
+
+	entries, err := s.store.Query("example.com", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+
+	found, err := s.store.Find(entries[0].ID[:6])
+	s.Require().NoError(err)
+	s.Require().Equal(entries[0].ID, found.ID)
+	_, err = s.store.Find("nonexistent")
+	s.Require().ErrorContains(err, "not found")
+}
+
+func (s *HistoryTestSuite) TestRecorder_TagsEmergencyChanges() {
+	created := provider.PlanResults("example.com", nil, []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}})
+	recorder := s.recorderAt(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	recorder.Emergency = true
+	s.Require().NoError(recorder.Record("example.com", "namecheap", created))
+
+	entries, err := s.store.Query("example.com", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Require().True(entries[0].Emergency)
+
+	out := RenderMarkdown("example.com", time.Time{}, entries, nil)
+	s.Require().Contains(out, "- Emergency change")
+}
+
+func (s *HistoryTestSuite) TestRecorder_SkipsEmptyResults() {
+	records := []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1", TTL: 1800}}
+	result := provider.PlanResults("example.com", records, records)
+
+	s.Require().NoError(s.recorderAt(time.Now()).Record("example.com", "namecheap", result))
+
+	entries, err := s.store.Query("", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Empty(entries)
+}
+
+func (s *HistoryTestSuite) TestStore_QueryFilters() {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	created := provider.PlanResults("example.com", nil, []dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}})
+
+	s.Require().NoError(s.recorderAt(old).Record("example.com", "namecheap", created))
+	s.Require().NoError(s.recorderAt(recent).Record("example.com", "namecheap", created))
+	s.Require().NoError(s.recorderAt(recent).Record("example.org", "namecheap", created))
+
+	entries, err := s.store.Query("example.com", recent.Add(-24*time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Require().Equal(recent, entries[0].Time)
+
+	all, err := s.store.Query("", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Len(all, 3)
+}
+
+func (s *HistoryTestSuite) TestStore_QueryMissingFile() {
+	entries, err := NewStore(state.NewFileStore(filepath.Join(s.T().TempDir(), "missing.json"))).Query("example.com", time.Time{})
+	s.Require().NoError(err)
+	s.Require().Empty(entries)
+}
+
+func (s *HistoryTestSuite) TestParseSince() {
+	cases := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for input, expected := range cases {
+		d, err := ParseSince(input)
+		s.Require().NoError(err, input)
+		s.Require().Equal(expected, d, input)
+	}
+
+	for _, input := range []string{"", "d", "xd", "-1d", "soon"} {
+		_, err := ParseSince(input)
+		s.Require().Error(err, input)
+	}
+}
+
+func (s *HistoryTestSuite) TestRenderMarkdown() {
+	entries := []Entry{{
+		Time:     time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Domain:   "example.com",
+		Provider: "namecheap",
+		Account:  "prod",
+		User:     "alice",
+		Command:  "zonekit dns bulk example.com ops.yaml",
+		Changes: []Change{
+			{Action: provider.ApplyCreated, HostName: "api", Type: "A", Value: "192.0.2.3", TTL: 300},
+			{Action: provider.ApplyUpdated, HostName: "www", Type: "A", Value: "192.0.2.2", TTL: 1800, PreviousValue: "192.0.2.1", PreviousTTL: 1800},
+			{Action: provider.ApplyDeleted, HostName: "old", Type: "CNAME", Value: "example.net."},
+		},
+	}}
+
+	out := RenderMarkdown("example.com", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), entries, nil)
+
+	s.Require().Contains(out, "# DNS changelog for example.com")
+	s.Require().Contains(out, "_Changes since 2024-04-01 00:00 UTC_")
+	s.Require().Contains(out, "## 2024-05-01 12:30 UTC — alice")
+	s.Require().Contains(out, "- Via account `prod`, provider `namecheap`")
+	s.Require().Contains(out, "- Command: `zonekit dns bulk example.com ops.yaml`")
+	s.Require().Contains(out, "- **Created** `api A` → `192.0.2.3` (TTL 5m)")
+	s.Require().Contains(out, "- **Updated** `www A`: `192.0.2.1` → `192.0.2.2`")
+	s.Require().Contains(out, "- **Deleted** `old CNAME` (was `example.net.`)")
+}
+
+func (s *HistoryTestSuite) TestRenderMarkdown_FormatsTimes() {
+	entries := []Entry{{Time: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), Domain: "example.com"}}
+	formatTime := func(t time.Time) string { return t.Format("02.01.2006") }
+
+	out := RenderMarkdown("example.com", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), entries, formatTime)
+	s.Require().Contains(out, "_Changes since 01.04.2024_")
+	s.Require().Contains(out, "## 01.05.2024 — unknown user")
+}
+
+func (s *HistoryTestSuite) TestRenderMarkdown_NoEntries() {
+	out := RenderMarkdown("example.com", time.Time{}, nil, nil)
+	s.Require().Contains(out, "No changes recorded.")
+	s.Require().NotContains(out, "Changes since")
+}
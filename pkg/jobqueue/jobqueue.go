@@ -0,0 +1,237 @@
+// Package jobqueue runs account-wide operations (one job per domain) under a
+// global and per-provider rate cap, and keeps their progress in the local
+// state store so an interrupted run can be resumed where it stopped.
+package jobqueue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"zonekit/pkg/ratelimit"
+	"zonekit/pkg/state"
+)
+
+// Job is one unit of work of a run, usually the change to one domain
+type Job struct {
+	// Key identifies the job within its run, e.g. the domain name
+	Key string
+	// Provider is the provider the job calls, for its per-provider rate cap
+	Provider string
+	// Run does the work
+	Run func(ctx context.Context) error
+}
+
+// Limits caps how fast jobs are started. Rates are in jobs per second; 0
+// means unlimited. The API calls jobs make are paced separately by the
+// providers' clients, whose ratelimit limiters are shared by all jobs, so a
+// provider needs no job rate to stay within its API limits.
+type Limits struct {
+	// Rate caps the jobs started across all providers
+	Rate float64
+	// ProviderRates caps the jobs started per provider
+	ProviderRates map[string]float64
+	// Concurrency is the number of jobs run at once (1 if 0)
+	Concurrency int
+}
+
+// DefaultLimits returns the limits used unless overridden
+func DefaultLimits() Limits {
+	return Limits{Rate: 5, ProviderRates: make(map[string]float64), Concurrency: 4}
+}
+
+// Progress is the saved state of a run
+type Progress struct {
+	// ID identifies the run, e.g. the command and its arguments
+	ID string `json:"id"`
+	// Total is the number of jobs in the run
+	Total int `json:"total"`
+	// Done holds the keys of the jobs that succeeded
+	Done []string `json:"done"`
+	// Failed holds the error of each job that failed, by key
+	Failed    map[string]string `json:"failed,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	// Finished is set once every job has been run
+	Finished bool `json:"finished"`
+}
+
+// Remaining returns how many jobs have not succeeded yet
+func (p *Progress) Remaining() int {
+	return p.Total - len(p.Done)
+}
+
+// Queue runs the jobs of one run, saving progress to a state store
+type Queue struct {
+	id     string
+	store  state.Store
+	limits Limits
+	now    func() time.Time
+}
+
+// New returns a queue for the run id, saving progress to store. A nil store
+// runs without saving progress.
+func New(id string, store state.Store, limits Limits) *Queue {
+	return &Queue{id: id, store: store, limits: limits, now: time.Now}
+}
+
+// Load returns the saved progress of the run, or nil if there is none
+func (q *Queue) Load() (*Progress, error) {
+	if q.store == nil {
+		return nil, nil
+	}
+	var progress Progress
+	ok, err := q.store.Get(state.BucketJobs, q.id, &progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load progress of %s: %w", q.id, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &progress, nil
+}
+
+// Run runs the jobs and returns the progress of the run. With resume, jobs
+// that succeeded in an earlier run are skipped and failed ones are retried;
+// otherwise any earlier progress is discarded. Progress is saved after every
+// job, and when ctx is cancelled no further jobs are started. report, if not
+// nil, is called after each job with its key and error.
+func (q *Queue) Run(ctx context.Context, jobs []Job, resume bool, report func(key string, err error)) (*Progress, error) {
+	progress := &Progress{ID: q.id, StartedAt: q.now().UTC()}
+	if resume {
+		saved, err := q.Load()
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil {
+			progress = saved
+		}
+	}
+	progress.Total = len(jobs)
+	progress.Finished = false
+	if progress.Failed == nil {
+		progress.Failed = make(map[string]string)
+	}
+
+	done := make(map[string]bool, len(progress.Done))
+	for _, key := range progress.Done {
+		done[key] = true
+	}
+	var pending []Job
+	for _, job := range jobs {
+		if !done[job.Key] {
+			pending = append(pending, job)
+		}
+	}
+	if err := q.save(progress); err != nil {
+		return progress, err
+	}
+
+	limiters := newLimiters(q.limits)
+	workers := q.limits.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		saveErr error
+		wg      sync.WaitGroup
+	)
+	work := make(chan Job)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range work {
+				// A job handed over as the run was interrupted is left for resume
+				if ctx.Err() != nil {
+					continue
+				}
+				err := job.Run(ctx)
+
+				mu.Lock()
+				if err != nil {
+					progress.Failed[job.Key] = err.Error()
+				} else {
+					delete(progress.Failed, job.Key)
+					progress.Done = append(progress.Done, job.Key)
+				}
+				if err := q.save(progress); err != nil && saveErr == nil {
+					saveErr = err
+				}
+				if report != nil {
+					report(job.Key, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+schedule:
+	for _, job := range pending {
+		if err := limiters.wait(ctx, job.Provider); err != nil {
+			break
+		}
+		select {
+		case work <- job:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	sort.Strings(progress.Done)
+	progress.Finished = ctx.Err() == nil
+	if err := q.save(progress); err != nil && saveErr == nil {
+		saveErr = err
+	}
+	if saveErr != nil {
+		return progress, saveErr
+	}
+	return progress, ctx.Err()
+}
+
+// save writes progress to the store
+func (q *Queue) save(progress *Progress) error {
+	if q.store == nil {
+		return nil
+	}
+	if err := q.store.Put(state.BucketJobs, q.id, progress); err != nil {
+		return fmt.Errorf("failed to save progress of %s: %w", q.id, err)
+	}
+	return nil
+}
+
+// limiters holds the global limiter and one limiter per capped provider
+type limiters struct {
+	global    *ratelimit.Limiter
+	providers map[string]*ratelimit.Limiter
+}
+
+func newLimiters(limits Limits) *limiters {
+	l := &limiters{global: newLimiter(limits.Rate), providers: make(map[string]*ratelimit.Limiter)}
+	for name, rate := range limits.ProviderRates {
+		l.providers[name] = newLimiter(rate)
+	}
+	return l
+}
+
+// wait blocks until a job for provider may start
+func (l *limiters) wait(ctx context.Context, provider string) error {
+	if err := l.providers[provider].Wait(ctx); err != nil {
+		return err
+	}
+	return l.global.Wait(ctx)
+}
+
+// newLimiter returns a limiter that starts jobs at least 1/rate seconds
+// apart, or nil (unlimited) for a rate of 0 or less
+func newLimiter(rate float64) *ratelimit.Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return ratelimit.NewLimiter(ratelimit.Rate{Requests: 1, Per: time.Duration(float64(time.Second) / rate)})
+}
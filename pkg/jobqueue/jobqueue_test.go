@@ -0,0 +1,131 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"zonekit/pkg/state"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) state.Store {
+	store := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// jobsFor returns a job per domain that records its runs and fails for the
+// domains in failing
+func jobsFor(domains []string, provider string, ran *[]string, mu *sync.Mutex, failing map[string]bool) []Job {
+	jobs := make([]Job, 0, len(domains))
+	for _, d := range domains {
+		d := d
+		jobs = append(jobs, Job{Key: d, Provider: provider, Run: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*ran = append(*ran, d)
+			if failing[d] {
+				return errors.New("provider error")
+			}
+			return nil
+		}})
+	}
+	return jobs
+}
+
+func TestQueue_RunAndResume(t *testing.T) {
+	store := newStore(t)
+	domains := []string{"a.com", "b.com", "c.com"}
+	var mu sync.Mutex
+	var ran []string
+
+	q := New("dns add @ TXT x", store, Limits{Concurrency: 2})
+	progress, err := q.Run(context.Background(), jobsFor(domains, "namecheap", &ran, &mu, map[string]bool{"b.com": true}), false, nil)
+	require.NoError(t, err)
+	require.True(t, progress.Finished)
+	require.Equal(t, []string{"a.com", "c.com"}, progress.Done)
+	require.Equal(t, map[string]string{"b.com": "provider error"}, progress.Failed)
+	require.Equal(t, 1, progress.Remaining())
+
+	saved, err := q.Load()
+	require.NoError(t, err)
+	require.Equal(t, progress.Done, saved.Done)
+
+	// Resuming retries only the failed domain
+	ran = nil
+	progress, err = q.Run(context.Background(), jobsFor(domains, "namecheap", &ran, &mu, nil), true, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b.com"}, ran)
+	require.Equal(t, domains, progress.Done)
+	require.Empty(t, progress.Failed)
+
+	// Without resume the run starts over
+	ran = nil
+	_, err = q.Run(context.Background(), jobsFor(domains, "namecheap", &ran, &mu, nil), false, nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, domains, ran)
+}
+
+func TestQueue_InterruptedRunIsSaved(t *testing.T) {
+	store := newStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var ran []string
+
+	jobs := jobsFor([]string{"a.com", "b.com", "c.com"}, "namecheap", &ran, &mu, nil)
+	first := jobs[0].Run
+	jobs[0].Run = func(ctx context.Context) error {
+		cancel()
+		return first(ctx)
+	}
+
+	q := New("run", store, Limits{Concurrency: 1, Rate: 100})
+	progress, err := q.Run(ctx, jobs, false, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, progress.Finished)
+	require.Equal(t, []string{"a.com"}, progress.Done)
+
+	saved, err := q.Load()
+	require.NoError(t, err)
+	require.False(t, saved.Finished)
+	require.Equal(t, 3, saved.Total)
+	require.Equal(t, 2, saved.Remaining())
+}
+
+func TestQueue_ProviderRate(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	job := func(key, provider string) Job {
+		return Job{Key: key, Provider: provider, Run: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			starts = append(starts, time.Now())
+			return nil
+		}}
+	}
+
+	// Three jobs for a provider capped at 20 per second take at least 100ms;
+	// the uncapped provider is only held back by the global cap
+	q := New("rate", nil, Limits{Rate: 1000, ProviderRates: map[string]float64{"slow": 20}, Concurrency: 3})
+	begin := time.Now()
+	_, err := q.Run(context.Background(), []Job{job("a", "slow"), job("b", "slow"), job("c", "slow")}, false, nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+
+	begin = time.Now()
+	_, err = q.Run(context.Background(), []Job{job("a", "fast"), job("b", "fast"), job("c", "fast")}, false, nil)
+	require.NoError(t, err)
+	require.Less(t, time.Since(begin), 100*time.Millisecond)
+}
+
+func TestDefaultLimits(t *testing.T) {
+	limits := DefaultLimits()
+	require.Empty(t, limits.ProviderRates, "provider API limits are paced by the providers' clients")
+	limits.ProviderRates["namecheap"] = 1
+	require.Empty(t, DefaultLimits().ProviderRates, "defaults are not shared")
+}
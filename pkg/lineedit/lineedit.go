@@ -0,0 +1,339 @@
+// Package lineedit reads command lines for zonekit's interactive shell. On a
+// terminal lines can be edited, earlier lines are recalled with the arrow
+// keys and Tab completes the word before the cursor. Other input, such as a
+// script piped in, is read line by line.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// DefaultHistoryLimit is the number of lines kept in the history by default
+const DefaultHistoryLimit = 500
+
+// ErrInterrupted is returned by ReadLine when the line is abandoned with Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns the candidates for the last word of line, which is the
+// text before the cursor. Each candidate replaces that word as a whole.
+type Completer func(line string) []string
+
+// Editor reads lines from a terminal or another input
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	terminal bool
+	history  []string
+	limit    int
+
+	// Complete completes words on Tab; Tab does nothing when it is nil
+	Complete Completer
+}
+
+// New creates an editor that reads from in and echoes to out. Editing is only
+// enabled when in is a terminal.
+func New(in *os.File, out io.Writer) *Editor {
+	fd := int(in.Fd())
+	return &Editor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		fd:       fd,
+		terminal: isTerminal(fd),
+		limit:    DefaultHistoryLimit,
+	}
+}
+
+// Terminal reports whether lines are read from a terminal
+func (e *Editor) Terminal() bool {
+	return e.terminal
+}
+
+// SetHistory replaces the history, oldest line first
+func (e *Editor) SetHistory(lines []string) {
+	e.history = nil
+	for _, line := range lines {
+		e.AddHistory(line)
+	}
+}
+
+// AddHistory appends a line to the history. Blank lines and repeats of the
+// previous line are not kept.
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > e.limit {
+		e.history = e.history[len(e.history)-e.limit:]
+	}
+}
+
+// History returns a copy of the history, oldest line first
+func (e *Editor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// ReadLine shows prompt and reads a line without its line ending. It returns
+// io.EOF at the end of the input or on Ctrl-D on an empty line, and
+// ErrInterrupted on Ctrl-C. The prompt is only shown on a terminal.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		return e.readPlain()
+	}
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		return e.readPlain()
+	}
+	defer restore()
+	return e.edit(prompt)
+}
+
+// readPlain reads a line as it is
+func (e *Editor) readPlain() (string, error) {
+	line, err := e.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Control keys
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// line is the state of the line being edited
+type line struct {
+	prompt string
+	buf    []rune
+	pos    int
+	// entry is the history line shown, len(history) for the new line, whose
+	// text is kept in pending while history lines are shown
+	entry   int
+	pending []rune
+}
+
+// edit reads a line from a terminal in raw mode
+func (e *Editor) edit(prompt string) (string, error) {
+	l := &line{prompt: prompt, entry: len(e.history)}
+	fmt.Fprint(e.out, prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(e.out, "\r\n")
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(e.out, "\r\n")
+			return string(l.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			l.deleteAt(l.pos)
+		case keyBackspace, keyCtrlH:
+			if l.pos > 0 {
+				l.pos--
+				l.deleteAt(l.pos)
+			}
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			if l.pos > 0 {
+				l.pos--
+			}
+		case keyCtrlF:
+			if l.pos < len(l.buf) {
+				l.pos++
+			}
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.buf = append([]rune(nil), l.buf[l.pos:]...)
+			l.pos = 0
+		case keyCtrlW:
+			start := l.pos
+			for start > 0 && l.buf[start-1] == ' ' {
+				start--
+			}
+			start = wordStart(l.buf, start)
+			l.buf = append(l.buf[:start], l.buf[l.pos:]...)
+			l.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			e.recall(l, -1)
+		case keyCtrlN:
+			e.recall(l, 1)
+		case keyTab:
+			e.complete(l)
+		case keyEscape:
+			e.escape(l)
+		default:
+			if unicode.IsPrint(r) {
+				l.buf = append(l.buf[:l.pos], append([]rune{r}, l.buf[l.pos:]...)...)
+				l.pos++
+			}
+		}
+		e.redraw(l)
+	}
+}
+
+// escape handles the escape sequences of the arrow, Home, End and Delete keys
+func (e *Editor) escape(l *line) {
+	prefix, err := e.in.ReadByte()
+	if err != nil || (prefix != '[' && prefix != 'O') {
+		return
+	}
+	var params []byte
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return
+		}
+		if b >= 0x40 && b <= 0x7e {
+			switch {
+			case b == 'A':
+				e.recall(l, -1)
+			case b == 'B':
+				e.recall(l, 1)
+			case b == 'C' && l.pos < len(l.buf):
+				l.pos++
+			case b == 'D' && l.pos > 0:
+				l.pos--
+			case b == 'H' || (b == '~' && (string(params) == "1" || string(params) == "7")):
+				l.pos = 0
+			case b == 'F' || (b == '~' && (string(params) == "4" || string(params) == "8")):
+				l.pos = len(l.buf)
+			case b == '~' && string(params) == "3":
+				l.deleteAt(l.pos)
+			}
+			return
+		}
+		params = append(params, b)
+	}
+}
+
+// recall shows the previous (-1) or next (1) history line
+func (e *Editor) recall(l *line, step int) {
+	entry := l.entry + step
+	if entry < 0 || entry > len(e.history) {
+		return
+	}
+	if l.entry == len(e.history) {
+		l.pending = l.buf
+	}
+	l.entry = entry
+	if entry == len(e.history) {
+		l.buf = l.pending
+	} else {
+		l.buf = []rune(e.history[entry])
+	}
+	l.pos = len(l.buf)
+}
+
+// complete replaces the word before the cursor with its only candidate or
+// the candidates' common prefix, and lists the candidates when that does not
+// extend the word
+func (e *Editor) complete(l *line) {
+	if e.Complete == nil {
+		return
+	}
+	candidates := e.Complete(string(l.buf[:l.pos]))
+	start := wordStart(l.buf, l.pos)
+	word := string(l.buf[start:l.pos])
+
+	var replacement string
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, "\a")
+		return
+	case 1:
+		replacement = candidates[0] + " "
+	default:
+		replacement = commonPrefix(candidates)
+		if len(replacement) <= len(word) {
+			fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+			return
+		}
+	}
+	rest := append([]rune(replacement), l.buf[l.pos:]...)
+	l.buf = append(l.buf[:start], rest...)
+	l.pos = start + len([]rune(replacement))
+}
+
+// redraw writes the prompt and the line and moves the cursor into place
+func (e *Editor) redraw(l *line) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// deleteAt removes the rune at i, if any
+func (l *line) deleteAt(i int) {
+	if i < len(l.buf) {
+		l.buf = append(l.buf[:i], l.buf[i+1:]...)
+	}
+}
+
+// wordStart returns the index where the word ending at pos starts, which is
+// pos after a space
+func wordStart(buf []rune, pos int) int {
+	start := pos
+	for start > 0 && buf[start-1] != ' ' {
+		start--
+	}
+	return start
+}
+
+// commonPrefix returns the longest prefix shared by all candidates
+func commonPrefix(candidates []string) string {
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
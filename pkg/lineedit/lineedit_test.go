@@ -0,0 +1,122 @@
+package lineedit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEditor returns an editor in terminal mode that reads keys
+func newTestEditor(keys string) (*Editor, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &Editor{in: bufio.NewReader(strings.NewReader(keys)), out: &out, terminal: true, limit: DefaultHistoryLimit}, &out
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{name: "typed", keys: "list\r", want: "list"},
+		{name: "backspace", keys: "lisx\x7ft\r", want: "list"},
+		{name: "insert at start", keys: "ist\x01l\r", want: "list"},
+		{name: "arrow keys", keys: "lst\x1b[D\x1b[Di\x1b[C\x1b[F!\r", want: "list!"},
+		{name: "delete key", keys: "lisst\x1b[D\x1b[D\x1b[3~\r", want: "list"},
+		{name: "kill to end", keys: "list www\x01\x06\x06\x06\x06\x0b\r", want: "list"},
+		{name: "kill word", keys: "add www A  \x17x\r", want: "add www x"},
+		{name: "unicode", keys: "add txt TXT ünï\r", want: "add txt TXT ünï"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newTestEditor(tt.keys)
+			got, err := e.edit("> ")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEdit_EndKeys(t *testing.T) {
+	e, out := newTestEditor("lis\x03")
+	_, err := e.edit("> ")
+	require.ErrorIs(t, err, ErrInterrupted)
+	require.Contains(t, out.String(), "^C")
+
+	e, _ = newTestEditor("\x04")
+	_, err = e.edit("> ")
+	require.ErrorIs(t, err, io.EOF)
+
+	// Ctrl-D deletes the character under the cursor on a non-empty line
+	e, _ = newTestEditor("lisst\x02\x02\x04\r")
+	got, err := e.edit("> ")
+	require.NoError(t, err)
+	require.Equal(t, "list", got)
+}
+
+func TestEdit_History(t *testing.T) {
+	e, _ := newTestEditor("\x1b[A\x1b[A\r" + "new\x1b[A\x1b[B\r")
+	e.SetHistory([]string{"use example.com", "list", "list", " "})
+	require.Equal(t, []string{"use example.com", "list"}, e.History())
+
+	got, err := e.edit("> ")
+	require.NoError(t, err)
+	require.Equal(t, "use example.com", got)
+
+	// The line being typed is kept while history lines are shown
+	got, err = e.edit("> ")
+	require.NoError(t, err)
+	require.Equal(t, "new", got)
+
+	e.limit = 2
+	e.AddHistory("add www A 192.0.2.1")
+	require.Equal(t, []string{"list", "add www A 192.0.2.1"}, e.History())
+}
+
+func TestEdit_Complete(t *testing.T) {
+	words := []string{"add", "apply", "list"}
+	complete := func(line string) []string {
+		fields := strings.Fields(line)
+		prefix := ""
+		if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+			prefix = fields[len(fields)-1]
+		}
+		var matches []string
+		for _, w := range words {
+			if strings.HasPrefix(w, prefix) {
+				matches = append(matches, w)
+			}
+		}
+		return matches
+	}
+
+	e, _ := newTestEditor("li\tw\r")
+	e.Complete = complete
+	got, err := e.edit("> ")
+	require.NoError(t, err)
+	require.Equal(t, "list w", got)
+
+	// Several candidates are listed when their common prefix adds nothing
+	e, out := newTestEditor("a\td\td\r")
+	e.Complete = complete
+	got, err = e.edit("> ")
+	require.NoError(t, err)
+	require.Equal(t, "add d", got)
+	require.Contains(t, out.String(), "add  apply")
+}
+
+func TestReadLine_Plain(t *testing.T) {
+	e := &Editor{in: bufio.NewReader(strings.NewReader("use example.com\r\nlist")), out: io.Discard, limit: DefaultHistoryLimit}
+	got, err := e.ReadLine("> ")
+	require.NoError(t, err)
+	require.Equal(t, "use example.com", got)
+	got, err = e.ReadLine("> ")
+	require.NoError(t, err)
+	require.Equal(t, "list", got)
+	_, err = e.ReadLine("> ")
+	require.ErrorIs(t, err, io.EOF)
+}
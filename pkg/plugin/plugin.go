@@ -47,10 +47,39 @@ type Command struct {
 	// LongDescription is a detailed description
 	LongDescription string
 
+	// Usage is the argument synopsis shown in help, e.g. "<service-name> <domain>"
+	Usage string
+
+	// MinArgs is the minimum number of positional arguments
+	MinArgs int
+
+	// DomainArg is the 1-based position of the domain argument. Commands with a
+	// domain get Context.Domain and Context.DNS set; 0 means the command does not
+	// operate on a domain and runs without account credentials.
+	DomainArg int
+
+	// Flags are the flags the command accepts; their values are passed in Context.Flags
+	Flags []Flag
+
+	// Complete returns shell completion candidates for the next positional argument
+	Complete CompletionFunc
+
 	// Execute runs the command with the given context
 	Execute CommandFunc
 }
 
+// CompletionFunc returns completion candidates given the arguments typed so far
+type CompletionFunc func(args []string, toComplete string) []string
+
+// Flag describes a command flag. The type of Default selects the flag type;
+// bool, string and []string (a repeatable flag) are supported.
+type Flag struct {
+	Name        string
+	Shorthand   string
+	Description string
+	Default     interface{}
+}
+
 // Context provides the execution context for plugin commands
 type Context struct {
 	// Domain is the domain name being operated on
@@ -7,16 +7,22 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"zonekit/pkg/dnsrecord"
 )
 
 // Config represents a service integration configuration
 type Config struct {
-	Name         string        `yaml:"name"`
-	DisplayName  string        `yaml:"display_name"`
-	Description  string        `yaml:"description"`
-	Category     string        `yaml:"category"` // email, cdn, hosting, etc.
-	Records      Records       `yaml:"records"`
-	Verification *Verification `yaml:"verification,omitempty"`
+	Name        string  `yaml:"name"`
+	DisplayName string  `yaml:"display_name"`
+	Description string  `yaml:"description"`
+	Category    string  `yaml:"category"` // email, cdn, hosting, etc.
+	Records     Records `yaml:"records"`
+	// Params are the parameters that select between the template's setup modes
+	Params []Param `yaml:"params,omitempty"`
+	// Conditionals are records generated only for some parameter values
+	Conditionals []ConditionalBlock `yaml:"conditionals,omitempty"`
+	Verification *Verification      `yaml:"verification,omitempty"`
 }
 
 // Records defines all DNS records for a service integration
@@ -31,25 +37,25 @@ type Records struct {
 
 // MXRecord represents an MX record
 type MXRecord struct {
-	Hostname string `yaml:"hostname"`
-	Server   string `yaml:"server"`
-	Priority int    `yaml:"priority"`
-	TTL      int    `yaml:"ttl,omitempty"`
+	Hostname string        `yaml:"hostname"`
+	Server   string        `yaml:"server"`
+	Priority int           `yaml:"priority"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty"`
 }
 
 // TXTRecord represents a TXT record
 type TXTRecord struct {
-	Hostname string `yaml:"hostname"`
-	Value    string `yaml:"value"`
-	TTL      int    `yaml:"ttl,omitempty"`
+	Hostname string        `yaml:"hostname"`
+	Value    string        `yaml:"value"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty"`
 }
 
 // DKIMRecord represents a DKIM record (can be CNAME or TXT)
 type DKIMRecord struct {
-	Hostname string `yaml:"hostname"`
-	Type     string `yaml:"type"` // CNAME or TXT
-	Value    string `yaml:"value"`
-	TTL      int    `yaml:"ttl,omitempty"`
+	Hostname string        `yaml:"hostname"`
+	Type     string        `yaml:"type"` // CNAME or TXT
+	Value    string        `yaml:"value"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty"`
 }
 
 // AutodiscoverRecord represents autodiscover configuration
@@ -63,17 +69,17 @@ type AutodiscoverRecord struct {
 	Priority int    `yaml:"priority,omitempty"`
 	Weight   int    `yaml:"weight,omitempty"`
 	// For CNAME
-	CNAME string `yaml:"cname,omitempty"`
-	TTL   int    `yaml:"ttl,omitempty"`
+	CNAME string        `yaml:"cname,omitempty"`
+	TTL   dnsrecord.TTL `yaml:"ttl,omitempty"`
 }
 
 // CustomRecord represents a custom DNS record
 type CustomRecord struct {
-	Hostname string `yaml:"hostname"`
-	Type     string `yaml:"type"` // A, AAAA, CNAME, TXT, NS, SRV
-	Value    string `yaml:"value"`
-	TTL      int    `yaml:"ttl,omitempty"`
-	MXPref   int    `yaml:"mx_pref,omitempty"`
+	Hostname string        `yaml:"hostname"`
+	Type     string        `yaml:"type"` // A, AAAA, CNAME, TXT, NS, SRV
+	Value    string        `yaml:"value"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty"`
+	MXPref   int           `yaml:"mx_pref,omitempty"`
 }
 
 // Verification defines how to verify provider setup
@@ -81,13 +87,26 @@ type Verification struct {
 	RequiredRecords []VerificationCheck `yaml:"required_records,omitempty"`
 }
 
-// VerificationCheck defines a single verification check
+// VerificationCheck defines a single verification check. A record of the
+// given type and hostname matches when it satisfies every value assertion set
+// on the check; by default at least one record must match.
 type VerificationCheck struct {
 	Type       string `yaml:"type"`
 	Hostname   string `yaml:"hostname"`
 	Contains   string `yaml:"contains,omitempty"`
 	Equals     string `yaml:"equals,omitempty"`
 	StartsWith string `yaml:"starts_with,omitempty"`
+	// Matches is a regular expression the record value must match
+	Matches string `yaml:"matches,omitempty"`
+	// TTL constrains the record TTL, e.g. ">= 300" or "<= 1h"
+	TTL string `yaml:"ttl,omitempty"`
+	// Count constrains the number of matching records, e.g. "2" or ">= 2"
+	Count string `yaml:"count,omitempty"`
+	// Absent requires that no record matches, e.g. to catch leftovers of a
+	// previous provider
+	Absent bool `yaml:"absent,omitempty"`
+	// When limits the check to some parameter values, like a conditional block
+	When string `yaml:"when,omitempty"`
 }
 
 // LoadConfig loads a service integration configuration from a YAML file
@@ -97,6 +116,11 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return parseConfig(data)
+}
+
+// parseConfig decodes and validates a service integration configuration
+func parseConfig(data []byte) (*Config, error) {
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -151,8 +175,56 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("display_name is required")
 	}
 
+	if err := c.Records.validate(); err != nil {
+		return err
+	}
+
+	// Validate parameters
+	seen := make(map[string]bool, len(c.Params))
+	for i, p := range c.Params {
+		if p.Name == "" {
+			return fmt.Errorf("params[%d].name is required", i)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("params[%d]: parameter %s is declared more than once", i, p.Name)
+		}
+		seen[p.Name] = true
+		if !p.allows(p.Default) {
+			return fmt.Errorf("params[%d]: default %q is not one of %s", i, p.Default, strings.Join(p.Values, ", "))
+		}
+	}
+
+	// Validate conditional blocks
+	for i, block := range c.Conditionals {
+		if err := c.validateCondition(block.When); err != nil {
+			return fmt.Errorf("conditionals[%d].when: %w", i, err)
+		}
+		if err := block.Records.validate(); err != nil {
+			return fmt.Errorf("conditionals[%d].records: %w", i, err)
+		}
+	}
+
+	// Validate verification checks
+	if c.Verification != nil {
+		for i, check := range c.Verification.RequiredRecords {
+			if err := check.validate(); err != nil {
+				return fmt.Errorf("verification.required_records[%d]: %w", i, err)
+			}
+			if check.When != "" {
+				if err := c.validateCondition(check.When); err != nil {
+					return fmt.Errorf("verification.required_records[%d].when: %w", i, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks the records of a template or conditional block
+func (r *Records) validate() error {
 	// Validate MX records
-	for i, mx := range c.Records.MX {
+	for i, mx := range r.MX {
 		if mx.Hostname == "" {
 			return fmt.Errorf("mx[%d].hostname is required", i)
 		}
@@ -165,17 +237,17 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate SPF
-	if c.Records.SPF != nil {
-		if c.Records.SPF.Hostname == "" {
+	if r.SPF != nil {
+		if r.SPF.Hostname == "" {
 			return fmt.Errorf("spf.hostname is required")
 		}
-		if c.Records.SPF.Value == "" {
+		if r.SPF.Value == "" {
 			return fmt.Errorf("spf.value is required")
 		}
 	}
 
 	// Validate DKIM records
-	for i, dkim := range c.Records.DKIM {
+	for i, dkim := range r.DKIM {
 		if dkim.Hostname == "" {
 			return fmt.Errorf("dkim[%d].hostname is required", i)
 		}
@@ -188,17 +260,33 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate DMARC
-	if c.Records.DMARC != nil {
-		if c.Records.DMARC.Hostname == "" {
+	if r.DMARC != nil {
+		if r.DMARC.Hostname == "" {
 			return fmt.Errorf("dmarc.hostname is required")
 		}
-		if c.Records.DMARC.Value == "" {
+		if r.DMARC.Value == "" {
 			return fmt.Errorf("dmarc.value is required")
 		}
 	}
 
+	// Validate autodiscover
+	if r.Autodiscover != nil {
+		switch r.Autodiscover.Type {
+		case "CNAME":
+			if r.Autodiscover.Hostname == "" || r.Autodiscover.CNAME == "" {
+				return fmt.Errorf("autodiscover.hostname and autodiscover.cname are required for CNAME")
+			}
+		case "SRV":
+			if r.Autodiscover.Service == "" || r.Autodiscover.Target == "" {
+				return fmt.Errorf("autodiscover.service and autodiscover.target are required for SRV")
+			}
+		default:
+			return fmt.Errorf("autodiscover.type must be CNAME or SRV")
+		}
+	}
+
 	// Validate custom records
-	for i, custom := range c.Records.Custom {
+	for i, custom := range r.Custom {
 		if custom.Hostname == "" {
 			return fmt.Errorf("custom[%d].hostname is required", i)
 		}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/plugin"
+)
+
+// DefaultHealthTimeout bounds each functional check
+const DefaultHealthTimeout = 10 * time.Second
+
+// HealthChecker performs functional checks against the hosts that DNS records point to
+type HealthChecker struct {
+	Timeout time.Duration
+	// smtpPort is the port used for MX banner checks
+	smtpPort   string
+	dialer     func(ctx context.Context, network, address string) (net.Conn, error)
+	httpClient *http.Client
+}
+
+// NewHealthChecker creates a health checker with the given per-check timeout
+func NewHealthChecker(timeout time.Duration) *HealthChecker {
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	return &HealthChecker{
+		Timeout:    timeout,
+		smtpPort:   "25",
+		dialer:     dialer.DialContext,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check runs the functional checks relevant to a service against the zone records:
+//
+//   - MX: connect to each of the service's mail servers on port 25 and read the SMTP banner
+//   - MTA-STS: fetch the policy from https://mta-sts.<domain>/.well-known/mta-sts.txt when
+//     the zone publishes an _mta-sts TXT record
+//   - www: HTTP-check www.<domain> when it is a CNAME
+func (h *HealthChecker) Check(config *Config, domainName string, records []dnsrecord.Record) []plugin.VerificationCheck {
+	var checks []plugin.VerificationCheck
+
+	for _, server := range mailServers(config, records) {
+		checks = append(checks, h.CheckSMTPBanner(server))
+	}
+
+	if len(config.Records.MX) > 0 && findRecord(records, "_mta-sts", dnsrecord.RecordTypeTXT) != nil {
+		checks = append(checks, h.CheckMTASTS(domainName))
+	}
+
+	if findRecord(records, "www", dnsrecord.RecordTypeCNAME) != nil {
+		checks = append(checks, h.CheckHTTP("www."+domainName))
+	}
+
+	return checks
+}
+
+// CheckSMTPBanner connects to host on the SMTP port and expects a 220 greeting
+func (h *HealthChecker) CheckSMTPBanner(host string) plugin.VerificationCheck {
+	host = strings.TrimSuffix(host, ".")
+	address := net.JoinHostPort(host, h.smtpPort)
+	check := plugin.VerificationCheck{Name: fmt.Sprintf("SMTP banner %s", address)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+	defer cancel()
+
+	conn, err := h.dialer(ctx, "tcp", address)
+	if err != nil {
+		check.Message = fmt.Sprintf("connection failed: %v", err)
+		return check
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(h.Timeout))
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && banner == "" {
+		check.Message = fmt.Sprintf("no banner received: %v", err)
+		return check
+	}
+
+	banner = strings.TrimSpace(banner)
+	if !strings.HasPrefix(banner, "220") {
+		check.Message = fmt.Sprintf("unexpected banner: %s", banner)
+		return check
+	}
+
+	check.Status = true
+	check.Message = banner
+	return check
+}
+
+// CheckMTASTS fetches the MTA-STS policy for a domain and checks that it is a valid STSv1 policy
+func (h *HealthChecker) CheckMTASTS(domainName string) plugin.VerificationCheck {
+	url := fmt.Sprintf("https://mta-sts.%s/.well-known/mta-sts.txt", domainName)
+	check := plugin.VerificationCheck{Name: "MTA-STS policy " + url}
+
+	resp, err := h.httpClient.Get(url)
+	if err != nil {
+		check.Message = fmt.Sprintf("fetch failed: %v", err)
+		return check
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		check.Message = fmt.Sprintf("unexpected status %s", resp.Status)
+		return check
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		check.Message = fmt.Sprintf("failed to read policy: %v", err)
+		return check
+	}
+
+	policy := parseMTASTSPolicy(string(body))
+	if policy["version"] != "STSv1" {
+		check.Message = "policy does not declare version: STSv1"
+		return check
+	}
+
+	check.Status = true
+	check.Message = fmt.Sprintf("mode %s", policy["mode"])
+	return check
+}
+
+// CheckHTTP requests https://host/ (falling back to plain HTTP) and passes if the
+// host answers with a non-5xx status
+func (h *HealthChecker) CheckHTTP(host string) plugin.VerificationCheck {
+	check := plugin.VerificationCheck{Name: "HTTP " + host}
+
+	var lastErr error
+	for _, scheme := range []string{"https", "http"} {
+		resp, err := h.httpClient.Get(scheme + "://" + host + "/")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			check.Message = fmt.Sprintf("%s responded with %s", scheme, resp.Status)
+			return check
+		}
+
+		check.Status = true
+		check.Message = fmt.Sprintf("%s responded with %s", scheme, resp.Status)
+		return check
+	}
+
+	check.Message = fmt.Sprintf("no response: %v", lastErr)
+	return check
+}
+
+// mailServers returns the service's MX servers that are present in the zone
+func mailServers(config *Config, records []dnsrecord.Record) []string {
+	var servers []string
+	for _, mx := range config.Records.MX {
+		target := strings.TrimSuffix(mx.Server, ".")
+		for _, record := range records {
+			if record.RecordType == dnsrecord.RecordTypeMX &&
+				record.HostName == mx.Hostname &&
+				strings.EqualFold(strings.TrimSuffix(record.Address, "."), target) {
+				servers = append(servers, target)
+				break
+			}
+		}
+	}
+	return servers
+}
+
+// findRecord returns the first record with the given host and type, or nil
+func findRecord(records []dnsrecord.Record, hostname, recordType string) *dnsrecord.Record {
+	for i := range records {
+		if strings.EqualFold(records[i].HostName, hostname) && records[i].RecordType == recordType {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// parseMTASTSPolicy parses the "key: value" lines of an MTA-STS policy
+func parseMTASTSPolicy(body string) map[string]string {
+	policy := make(map[string]string)
+	for _, line := range strings.Split(body, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, seen := policy[key]; !seen {
+			policy[key] = strings.TrimSpace(value)
+		}
+	}
+	return policy
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// HealthTestSuite is a test suite for functional service checks
+type HealthTestSuite struct {
+	suite.Suite
+	checker *HealthChecker
+}
+
+// TestHealthSuite runs the health check test suite
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (s *HealthTestSuite) SetupTest() {
+	s.checker = NewHealthChecker(2 * time.Second)
+}
+
+// routeTo sends every connection made by the checker to address
+func (s *HealthTestSuite) routeTo(address string) {
+	dialer := &net.Dialer{Timeout: time.Second}
+	dial := func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	s.checker.dialer = dial
+	s.checker.httpClient = &http.Client{
+		Timeout:   time.Second,
+		Transport: &http.Transport{DialContext: dial},
+	}
+}
+
+// smtpServer accepts one connection and writes banner
+func (s *HealthTestSuite) smtpServer(banner string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, banner)
+	}()
+
+	return listener.Addr().String()
+}
+
+func (s *HealthTestSuite) TestCheckSMTPBanner() {
+	s.routeTo(s.smtpServer("220 mx.example.net ESMTP ready\r\n"))
+
+	check := s.checker.CheckSMTPBanner("mx.example.net.")
+	s.Require().True(check.Status, check.Message)
+	s.Require().Equal("SMTP banner mx.example.net:25", check.Name)
+	s.Require().Equal("220 mx.example.net ESMTP ready", check.Message)
+}
+
+func (s *HealthTestSuite) TestCheckSMTPBanner_Rejected() {
+	s.routeTo(s.smtpServer("554 no service\r\n"))
+
+	check := s.checker.CheckSMTPBanner("mx.example.net")
+	s.Require().False(check.Status)
+	s.Require().Contains(check.Message, "unexpected banner")
+}
+
+func (s *HealthTestSuite) TestCheckSMTPBanner_Unreachable() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	address := listener.Addr().String()
+	listener.Close()
+	s.routeTo(address)
+
+	check := s.checker.CheckSMTPBanner("mx.example.net")
+	s.Require().False(check.Status)
+	s.Require().Contains(check.Message, "connection failed")
+}
+
+func (s *HealthTestSuite) TestCheckMTASTS() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("mta-sts.example.com", r.Host)
+		s.Equal("/.well-known/mta-sts.txt", r.URL.Path)
+		fmt.Fprint(w, "version: STSv1\nmode: enforce\nmx: mx.example.net\nmax_age: 86400\n")
+	}))
+	defer server.Close()
+	s.routeTo(server.Listener.Addr().String())
+	s.checker.httpClient.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	check := s.checker.CheckMTASTS("example.com")
+	s.Require().True(check.Status, check.Message)
+	s.Require().Equal("mode enforce", check.Message)
+}
+
+func (s *HealthTestSuite) TestCheckMTASTS_InvalidPolicy() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>parked</html>")
+	}))
+	defer server.Close()
+	s.routeTo(server.Listener.Addr().String())
+	s.checker.httpClient.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	check := s.checker.CheckMTASTS("example.com")
+	s.Require().False(check.Status)
+	s.Require().Contains(check.Message, "STSv1")
+}
+
+func (s *HealthTestSuite) TestCheckHTTP_FallsBackToPlainHTTP() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	s.routeTo(server.Listener.Addr().String())
+
+	check := s.checker.CheckHTTP("www.example.com")
+	s.Require().True(check.Status, check.Message)
+	s.Require().Equal("http responded with 404 Not Found", check.Message)
+}
+
+func (s *HealthTestSuite) TestCheckHTTP_ServerError() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	s.routeTo(server.Listener.Addr().String())
+
+	check := s.checker.CheckHTTP("www.example.com")
+	s.Require().False(check.Status)
+	s.Require().True(strings.HasSuffix(check.Message, "502 Bad Gateway"), check.Message)
+}
+
+func (s *HealthTestSuite) TestCheck_SelectsChecksFromRecords() {
+	config := &Config{
+		Name:        "mail",
+		DisplayName: "Mail",
+		Records: Records{MX: []MXRecord{
+			{Hostname: "@", Server: "mx1.example.net", Priority: 10},
+			{Hostname: "@", Server: "mx2.example.net", Priority: 20},
+		}},
+	}
+	records := []dnsrecord.Record{
+		{HostName: "@", RecordType: dnsrecord.RecordTypeMX, Address: "mx1.example.net.", MXPref: 10},
+		{HostName: "_mta-sts", RecordType: dnsrecord.RecordTypeTXT, Address: "v=STSv1; id=1"},
+		{HostName: "www", RecordType: dnsrecord.RecordTypeCNAME, Address: "example.github.io."},
+	}
+
+	// Point everything at a closed port so checks fail fast without touching the network
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	address := listener.Addr().String()
+	listener.Close()
+	s.routeTo(address)
+
+	checks := s.checker.Check(config, "example.com", records)
+
+	s.Require().Len(checks, 3)
+	s.Require().Equal("SMTP banner mx1.example.net:25", checks[0].Name)
+	s.Require().Equal("MTA-STS policy https://mta-sts.example.com/.well-known/mta-sts.txt", checks[1].Name)
+	s.Require().Equal("HTTP www.example.com", checks[2].Name)
+}
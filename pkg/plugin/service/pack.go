@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PackFormatVersion is the version of the pack file format
+const PackFormatVersion = 1
+
+// Pack bundles several service templates with metadata, so a curated set of
+// templates can be shared and installed as one file
+type Pack struct {
+	FormatVersion int         `yaml:"format_version"`
+	Name          string      `yaml:"name"`
+	Description   string      `yaml:"description,omitempty"`
+	Version       string      `yaml:"version,omitempty"`
+	CreatedAt     time.Time   `yaml:"created_at"`
+	Services      []PackEntry `yaml:"services"`
+}
+
+// PackEntry is a service template in a pack. The template is kept verbatim so
+// comments survive, with its SHA-256 checksum to detect corruption or tampering.
+type PackEntry struct {
+	Name    string `yaml:"name"`
+	File    string `yaml:"file"`
+	SHA256  string `yaml:"sha256"`
+	Content string `yaml:"content"`
+}
+
+// PackOptions describes a pack to build
+type PackOptions struct {
+	Name        string
+	Description string
+	Version     string
+	// Services limits the pack to the named services; empty means all
+	Services []string
+}
+
+// BuildPack bundles the service templates found in dirPath
+func BuildPack(dirPath string, opts PackOptions) (*Pack, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("pack name is required")
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read services directory: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(opts.Services))
+	for _, name := range opts.Services {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(opts.Services))
+
+	pack := &Pack{
+		FormatVersion: PackFormatVersion,
+		Name:          opts.Name,
+		Description:   opts.Description,
+		Version:       opts.Version,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isTemplateFile(entry.Name()) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		config, err := parseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if len(wanted) > 0 && !wanted[config.Name] {
+			continue
+		}
+		found[config.Name] = true
+
+		pack.Services = append(pack.Services, PackEntry{
+			Name:    config.Name,
+			File:    entry.Name(),
+			SHA256:  checksum(data),
+			Content: string(data),
+		})
+	}
+
+	var missing []string
+	for name := range wanted {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("services not found: %s", strings.Join(missing, ", "))
+	}
+	if len(pack.Services) == 0 {
+		return nil, fmt.Errorf("no service templates found in %s", dirPath)
+	}
+
+	return pack, nil
+}
+
+// Marshal encodes the pack as YAML
+func (p *Pack) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(p); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParsePack decodes a pack and verifies it: the checksum of every template must
+// match, and every template must be a valid service configuration
+func ParsePack(data []byte) (*Pack, error) {
+	var pack Pack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse pack: %w", err)
+	}
+	if pack.FormatVersion == 0 || pack.FormatVersion > PackFormatVersion {
+		return nil, fmt.Errorf("unsupported pack format version %d", pack.FormatVersion)
+	}
+	if pack.Name == "" {
+		return nil, fmt.Errorf("pack name is required")
+	}
+	if len(pack.Services) == 0 {
+		return nil, fmt.Errorf("pack %s contains no services", pack.Name)
+	}
+
+	files := make(map[string]bool, len(pack.Services))
+	for _, entry := range pack.Services {
+		// Files are written into the services directory and must stay inside it
+		if entry.File != filepath.Base(entry.File) || !isTemplateFile(entry.File) || strings.HasPrefix(entry.File, ".") {
+			return nil, fmt.Errorf("service %s has an invalid file name %q", entry.Name, entry.File)
+		}
+		if files[entry.File] {
+			return nil, fmt.Errorf("file %s appears more than once", entry.File)
+		}
+		files[entry.File] = true
+
+		if !strings.EqualFold(checksum([]byte(entry.Content)), entry.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s", entry.File)
+		}
+		config, err := parseConfig([]byte(entry.Content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.File, err)
+		}
+		if config.Name != entry.Name {
+			return nil, fmt.Errorf("%s defines service %s, not %s", entry.File, config.Name, entry.Name)
+		}
+	}
+
+	return &pack, nil
+}
+
+// Install writes the templates of the pack into dirPath and returns the files
+// written. Existing templates are only replaced when overwrite is set; otherwise
+// nothing is written if any of them exists.
+func (p *Pack) Install(dirPath string, overwrite bool) ([]string, error) {
+	if !overwrite {
+		for _, entry := range p.Services {
+			if _, err := os.Stat(filepath.Join(dirPath, entry.File)); err == nil {
+				return nil, fmt.Errorf("%s already exists in %s", entry.File, dirPath)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create services directory: %w", err)
+	}
+
+	written := make([]string, 0, len(p.Services))
+	for _, entry := range p.Services {
+		path := filepath.Join(dirPath, entry.File)
+		if err := os.WriteFile(path, []byte(entry.Content), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", entry.File, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+func isTemplateFile(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// PackTestSuite is a test suite for service template packs
+type PackTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+// TestPackSuite runs the pack test suite
+func TestPackSuite(t *testing.T) {
+	suite.Run(t, new(PackTestSuite))
+}
+
+func (s *PackTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.writeTemplate("alpha.yaml", "alpha")
+	s.writeTemplate("beta.yml", "beta")
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "README.md"), []byte("not a template"), 0644))
+}
+
+func (s *PackTestSuite) writeTemplate(file, name string) {
+	content := "# " + name + " template\nname: " + name + "\ndisplay_name: " + strings.ToUpper(name) + "\nrecords:\n  spf:\n    hostname: \"@\"\n    value: \"v=spf1 -all\"\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, file), []byte(content), 0644))
+}
+
+func (s *PackTestSuite) TestBuildPack_RoundTrip() {
+	pack, err := BuildPack(s.dir, PackOptions{Name: "team", Version: "1.0"})
+	s.Require().NoError(err)
+	s.Require().Len(pack.Services, 2)
+
+	data, err := pack.Marshal()
+	s.Require().NoError(err)
+
+	parsed, err := ParsePack(data)
+	s.Require().NoError(err)
+	s.Require().Equal("team", parsed.Name)
+	s.Require().Equal("1.0", parsed.Version)
+
+	target := filepath.Join(s.T().TempDir(), "services")
+	written, err := parsed.Install(target, false)
+	s.Require().NoError(err)
+	s.Require().Len(written, 2)
+
+	// Templates are installed verbatim, comments included
+	original, err := os.ReadFile(filepath.Join(s.dir, "alpha.yaml"))
+	s.Require().NoError(err)
+	installed, err := os.ReadFile(filepath.Join(target, "alpha.yaml"))
+	s.Require().NoError(err)
+	s.Require().Equal(original, installed)
+
+	configs, err := LoadAllConfigs(target)
+	s.Require().NoError(err)
+	s.Require().Contains(configs, "beta")
+
+	_, err = parsed.Install(target, false)
+	s.Require().ErrorContains(err, "already exists")
+	_, err = parsed.Install(target, true)
+	s.Require().NoError(err)
+}
+
+func (s *PackTestSuite) TestBuildPack_SelectedServices() {
+	pack, err := BuildPack(s.dir, PackOptions{Name: "team", Services: []string{"beta"}})
+	s.Require().NoError(err)
+	s.Require().Len(pack.Services, 1)
+	s.Require().Equal("beta", pack.Services[0].Name)
+
+	_, err = BuildPack(s.dir, PackOptions{Name: "team", Services: []string{"beta", "gamma"}})
+	s.Require().ErrorContains(err, "services not found: gamma")
+}
+
+func (s *PackTestSuite) TestParsePack_Rejects() {
+	pack, err := BuildPack(s.dir, PackOptions{Name: "team", Services: []string{"alpha"}})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name   string
+		modify func(p *Pack)
+		msg    string
+	}{
+		{"tampered content", func(p *Pack) { p.Services[0].Content += "# extra\n" }, "checksum mismatch"},
+		{"path traversal", func(p *Pack) { p.Services[0].File = "../alpha.yaml" }, "invalid file name"},
+		{"not a template", func(p *Pack) { p.Services[0].File = "alpha.sh" }, "invalid file name"},
+		{"name mismatch", func(p *Pack) { p.Services[0].Name = "other" }, "defines service alpha"},
+		{"future format", func(p *Pack) { p.FormatVersion = PackFormatVersion + 1 }, "unsupported pack format"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			modified := *pack
+			modified.Services = append([]PackEntry(nil), pack.Services...)
+			tt.modify(&modified)
+
+			data, err := modified.Marshal()
+			s.Require().NoError(err)
+			_, err = ParsePack(data)
+			s.Require().ErrorContains(err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Param declares a template parameter, set with --param name=value
+type Param struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+	// Values lists the allowed values; empty allows any value
+	Values []string `yaml:"values,omitempty"`
+}
+
+// ConditionalBlock holds records that are only generated when its condition
+// holds for the template parameters. Lists (mx, dkim, custom) are appended to
+// the template's records; single records (spf, dmarc, autodiscover) replace them.
+type ConditionalBlock struct {
+	// When is a condition such as "autodiscover=srv", "dkim!=txt" or
+	// "dkim=cname|txt && autodiscover=srv"
+	When    string  `yaml:"when"`
+	Records Records `yaml:"records"`
+}
+
+// condition is a single clause of a when expression
+type condition struct {
+	name   string
+	negate bool
+	values []string
+}
+
+// parseCondition parses clauses of the form name=value or name!=value, where
+// value may list alternatives separated by |, joined by &&
+func parseCondition(expr string) ([]condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("condition is empty")
+	}
+
+	var conditions []condition
+	for _, clause := range strings.Split(expr, "&&") {
+		clause = strings.TrimSpace(clause)
+		var c condition
+		name, value, found := strings.Cut(clause, "!=")
+		if found {
+			c.negate = true
+		} else if name, value, found = strings.Cut(clause, "="); !found {
+			return nil, fmt.Errorf("invalid condition %q: expected name=value or name!=value", clause)
+		}
+
+		c.name = strings.TrimSpace(name)
+		if c.name == "" {
+			return nil, fmt.Errorf("invalid condition %q: parameter name is missing", clause)
+		}
+		for _, v := range strings.Split(value, "|") {
+			c.values = append(c.values, strings.TrimSpace(v))
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// conditionHolds reports whether a when expression holds for the parameters.
+// Malformed expressions never hold; Config.Validate rejects them on load.
+func conditionHolds(expr string, params map[string]string) bool {
+	conditions, err := parseCondition(expr)
+	if err != nil {
+		return false
+	}
+	for _, c := range conditions {
+		matched := false
+		for _, v := range c.values {
+			if params[c.name] == v {
+				matched = true
+				break
+			}
+		}
+		if matched == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// param returns the declared parameter with the given name
+func (c *Config) param(name string) (Param, bool) {
+	for _, p := range c.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// validateCondition checks that a when expression is well formed and only
+// compares declared parameters with allowed values
+func (c *Config) validateCondition(expr string) error {
+	conditions, err := parseCondition(expr)
+	if err != nil {
+		return err
+	}
+	for _, cond := range conditions {
+		p, ok := c.param(cond.name)
+		if !ok {
+			return fmt.Errorf("condition %q uses undeclared parameter %s", expr, cond.name)
+		}
+		for _, v := range cond.values {
+			if !p.allows(v) {
+				return fmt.Errorf("condition %q compares %s with %q, which is not one of %s", expr, p.Name, v, strings.Join(p.Values, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// allows reports whether value is an allowed value of the parameter
+func (p Param) allows(value string) bool {
+	if len(p.Values) == 0 {
+		return true
+	}
+	for _, v := range p.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveParams parses name=value assignments and fills in the defaults of the
+// parameters that are not assigned
+func (c *Config) resolveParams(assignments []string) (map[string]string, error) {
+	params := make(map[string]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Name] = p.Default
+	}
+
+	for _, assignment := range assignments {
+		name, value, found := strings.Cut(assignment, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid parameter %q: expected name=value", assignment)
+		}
+
+		p, ok := c.param(name)
+		if !ok {
+			if len(c.Params) == 0 {
+				return nil, fmt.Errorf("service %s has no parameters", c.Name)
+			}
+			names := make([]string, 0, len(c.Params))
+			for _, declared := range c.Params {
+				names = append(names, declared.Name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown parameter %s for service %s (available: %s)", name, c.Name, strings.Join(names, ", "))
+		}
+		if !p.allows(value) {
+			return nil, fmt.Errorf("invalid value %q for parameter %s (allowed: %s)", value, name, strings.Join(p.Values, ", "))
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// recordsFor returns the template's records with the conditional blocks that
+// hold for the parameters applied
+func (c *Config) recordsFor(params map[string]string) Records {
+	records := c.Records
+	records.MX = append([]MXRecord(nil), c.Records.MX...)
+	records.DKIM = append([]DKIMRecord(nil), c.Records.DKIM...)
+	records.Custom = append([]CustomRecord(nil), c.Records.Custom...)
+
+	for _, block := range c.Conditionals {
+		if !conditionHolds(block.When, params) {
+			continue
+		}
+		records.MX = append(records.MX, block.Records.MX...)
+		records.DKIM = append(records.DKIM, block.Records.DKIM...)
+		records.Custom = append(records.Custom, block.Records.Custom...)
+		if block.Records.SPF != nil {
+			records.SPF = block.Records.SPF
+		}
+		if block.Records.DMARC != nil {
+			records.DMARC = block.Records.DMARC
+		}
+		if block.Records.Autodiscover != nil {
+			records.Autodiscover = block.Records.Autodiscover
+		}
+	}
+	return records
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// ParamsTestSuite is a test suite for template parameters and conditional blocks
+type ParamsTestSuite struct {
+	suite.Suite
+	config *Config
+}
+
+// TestParamsSuite runs the params test suite
+func TestParamsSuite(t *testing.T) {
+	suite.Run(t, new(ParamsTestSuite))
+}
+
+func (s *ParamsTestSuite) SetupTest() {
+	s.config = &Config{
+		Name:        "mail",
+		DisplayName: "Mail",
+		Records: Records{
+			MX: []MXRecord{{Hostname: "@", Server: "mx.example.net", Priority: 10}},
+		},
+		Params: []Param{
+			{Name: "dkim", Default: "cname", Values: []string{"cname", "txt"}},
+			{Name: "autodiscover", Default: "none", Values: []string{"none", "srv"}},
+		},
+		Conditionals: []ConditionalBlock{
+			{When: "dkim=cname", Records: Records{DKIM: []DKIMRecord{{Hostname: "k1._domainkey", Type: "CNAME", Value: "k1.{domain}.dkim.example.net"}}}},
+			{When: "dkim=txt", Records: Records{DKIM: []DKIMRecord{{Hostname: "k1._domainkey", Type: "TXT", Value: "k=rsa; p=abc"}}}},
+			{When: "autodiscover=srv", Records: Records{Autodiscover: &AutodiscoverRecord{
+				Type: "SRV", Hostname: "@", Service: "_autodiscover._tcp", Target: "auto.example.net", Port: 443,
+			}}},
+		},
+	}
+	s.Require().NoError(s.config.Validate())
+}
+
+func (s *ParamsTestSuite) TestConditionHolds() {
+	params := map[string]string{"dkim": "txt", "autodiscover": "srv"}
+
+	s.Require().True(conditionHolds("dkim=txt", params))
+	s.Require().False(conditionHolds("dkim=cname", params))
+	s.Require().True(conditionHolds("dkim != cname", params))
+	s.Require().True(conditionHolds("dkim=cname|txt", params))
+	s.Require().False(conditionHolds("dkim!=cname|txt", params))
+	s.Require().True(conditionHolds("dkim=txt && autodiscover=srv", params))
+	s.Require().False(conditionHolds("dkim=txt && autodiscover=none", params))
+	s.Require().False(conditionHolds("dkim", params))
+}
+
+func (s *ParamsTestSuite) TestResolveParams() {
+	params, err := s.config.resolveParams(nil)
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]string{"dkim": "cname", "autodiscover": "none"}, params)
+
+	params, err = s.config.resolveParams([]string{"autodiscover=srv"})
+	s.Require().NoError(err)
+	s.Require().Equal("srv", params["autodiscover"])
+	s.Require().Equal("cname", params["dkim"])
+
+	_, err = s.config.resolveParams([]string{"dkim=ed25519"})
+	s.Require().ErrorContains(err, "allowed: cname, txt")
+
+	_, err = s.config.resolveParams([]string{"selector=k2"})
+	s.Require().ErrorContains(err, "unknown parameter selector")
+
+	_, err = s.config.resolveParams([]string{"dkim"})
+	s.Require().ErrorContains(err, "expected name=value")
+}
+
+func (s *ParamsTestSuite) TestGenerateRecords_AppliesConditionalBlocks() {
+	p := NewServicePlugin(map[string]*Config{"mail": s.config})
+
+	params, err := s.config.resolveParams(nil)
+	s.Require().NoError(err)
+	records := p.generateRecords(s.config, "example.com", params)
+	s.Require().Len(records, 2)
+	s.Require().Equal(dnsrecord.RecordTypeCNAME, records[1].RecordType)
+	s.Require().Equal("k1.example.com.dkim.example.net.", records[1].Address)
+
+	params, err = s.config.resolveParams([]string{"dkim=txt", "autodiscover=srv"})
+	s.Require().NoError(err)
+	records = p.generateRecords(s.config, "example.com", params)
+	s.Require().Len(records, 3)
+	s.Require().Equal(dnsrecord.RecordTypeTXT, records[1].RecordType)
+	s.Require().Equal(dnsrecord.Record{
+		HostName:   "_autodiscover._tcp",
+		RecordType: dnsrecord.RecordTypeSRV,
+		Address:    "0 0 443 auto.example.net.",
+		TTL:        records[2].TTL,
+	}, records[2])
+
+	// Conditional records must not leak into the template
+	s.Require().Len(s.config.Records.MX, 1)
+	s.Require().Empty(s.config.Records.DKIM)
+	s.Require().Nil(s.config.Records.Autodiscover)
+}
+
+func (s *ParamsTestSuite) TestValidate_RejectsInvalidConditions() {
+	tests := map[string]func(c *Config){
+		"undeclared parameter": func(c *Config) { c.Conditionals[0].When = "mode=a" },
+		"disallowed value":     func(c *Config) { c.Conditionals[0].When = "dkim=ed25519" },
+		"malformed condition":  func(c *Config) { c.Conditionals[0].When = "dkim" },
+		"invalid block record": func(c *Config) { c.Conditionals[0].Records.DKIM[0].Type = "MX" },
+		"invalid default":      func(c *Config) { c.Params[0].Default = "ed25519" },
+		"duplicate parameter":  func(c *Config) { c.Params[1].Name = "dkim" },
+		"check condition": func(c *Config) {
+			c.Verification = &Verification{RequiredRecords: []VerificationCheck{{Type: "MX", Hostname: "@", When: "mode=a"}}}
+		},
+	}
+
+	for name, mutate := range tests {
+		s.Run(name, func() {
+			s.SetupTest()
+			mutate(s.config)
+			s.Require().Error(s.config.Validate())
+		})
+	}
+}
+
+func (s *ParamsTestSuite) TestBuiltinTemplate_Microsoft365Autodiscover() {
+	config, err := LoadConfig(filepath.Join("services", "microsoft-365.yaml"))
+	s.Require().NoError(err)
+	p := NewServicePlugin(map[string]*Config{config.Name: config})
+
+	autodiscover := func(assignments ...string) []dnsrecord.Record {
+		params, err := config.resolveParams(assignments)
+		s.Require().NoError(err)
+		var found []dnsrecord.Record
+		for _, r := range p.generateRecords(config, "example.com", params) {
+			if r.HostName == "autodiscover" || r.HostName == "_autodiscover._tcp" {
+				found = append(found, r)
+			}
+		}
+		return found
+	}
+
+	records := autodiscover()
+	s.Require().Len(records, 1)
+	s.Require().Equal(dnsrecord.RecordTypeCNAME, records[0].RecordType)
+
+	records = autodiscover("autodiscover=srv")
+	s.Require().Len(records, 1)
+	s.Require().Equal(dnsrecord.RecordTypeSRV, records[0].RecordType)
+	s.Require().Equal("0 0 443 autodiscover.outlook.com.", records[0].Address)
+}
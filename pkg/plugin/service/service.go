@@ -2,8 +2,10 @@ package service
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"zonekit/pkg/diffview"
 	"zonekit/pkg/dns"
 	"zonekit/pkg/dnsrecord"
 	"zonekit/pkg/plugin"
@@ -45,20 +47,46 @@ func (p *ServicePlugin) Commands() []plugin.Command {
 			LongDescription: `Set up all necessary DNS records for a configured service integration.
 Usage: service setup <service-name> <domain>
 
-Available services can be listed with: service list`,
-			Execute: p.setup,
+Templates with several setup modes take parameters, e.g. --param autodiscover=srv;
+'service info' lists them. Available services can be listed with: service list`,
+			Usage:     "<service-name> <domain>",
+			MinArgs:   2,
+			DomainArg: 2,
+			Flags: []plugin.Flag{
+				{Name: "dry-run", Description: "Show what would be done without making changes", Default: false},
+				{Name: "replace", Description: "Replace existing records", Default: false},
+				{Name: "param", Description: "Set a template parameter (name=value, repeatable)", Default: []string{}},
+			},
+			Complete: p.completeServiceName,
+			Execute:  p.setup,
 		},
 		{
 			Name:            "verify",
 			Description:     "Verify DNS records for a service integration",
 			LongDescription: "Check if all required DNS records for a service integration are properly configured.",
-			Execute:         p.verify,
+			Usage:           "<service-name> <domain>",
+			MinArgs:         2,
+			DomainArg:       2,
+			Flags: []plugin.Flag{
+				{Name: "deep", Description: "Also run functional checks (SMTP banner, MTA-STS policy, HTTP)", Default: false},
+				{Name: "param", Description: "Set a template parameter (name=value, repeatable)", Default: []string{}},
+			},
+			Complete: p.completeServiceName,
+			Execute:  p.verify,
 		},
 		{
 			Name:            "remove",
 			Description:     "Remove DNS records for a service integration",
 			LongDescription: "Remove all service-related DNS records from the specified domain.",
-			Execute:         p.remove,
+			Usage:           "<service-name> <domain>",
+			MinArgs:         2,
+			DomainArg:       2,
+			Flags: []plugin.Flag{
+				{Name: "confirm", Shorthand: "y", Description: "Confirm the operation", Default: false},
+				{Name: "param", Description: "Set a template parameter (name=value, repeatable)", Default: []string{}},
+			},
+			Complete: p.completeServiceName,
+			Execute:  p.remove,
 		},
 		{
 			Name:            "list",
@@ -70,11 +98,33 @@ Available services can be listed with: service list`,
 			Name:            "info",
 			Description:     "Show service integration information",
 			LongDescription: "Display detailed information about a specific service integration.",
-			Execute:         p.info,
+			Usage:           "<service-name>",
+			MinArgs:         1,
+			Flags: []plugin.Flag{
+				{Name: "param", Description: "Show the records for a template parameter (name=value, repeatable)", Default: []string{}},
+			},
+			Complete: p.completeServiceName,
+			Execute:  p.info,
 		},
 	}
 }
 
+// completeServiceName completes the service name, which is always the first argument
+func (p *ServicePlugin) completeServiceName(args []string, toComplete string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range p.configs {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // setup implements the setup command
 func (p *ServicePlugin) setup(ctx *plugin.Context) error {
 	if len(ctx.Args) < 2 {
@@ -92,18 +142,22 @@ func (p *ServicePlugin) setup(ctx *plugin.Context) error {
 	dryRun, _ := ctx.Flags["dry-run"].(bool)
 	replace, _ := ctx.Flags["replace"].(bool)
 
-	// Get current records if not replacing
-	var existingRecords []dnsrecord.Record
-	var err error
-	if !replace {
-		existingRecords, err = ctx.DNS.GetRecords(domain)
-		if err != nil {
-			return fmt.Errorf("failed to get existing records: %w", err)
-		}
+	assignments, _ := ctx.Flags["param"].([]string)
+	params, err := config.resolveParams(assignments)
+	if err != nil {
+		return err
+	}
+
+	color, _ := ctx.Flags["color"].(bool)
+
+	// Get current records, to check for conflicts and preview the changes
+	existingRecords, err := ctx.DNS.GetRecords(domain)
+	if err != nil {
+		return fmt.Errorf("failed to get existing records: %w", err)
 	}
 
 	// Generate DNS records from config
-	records := p.generateRecords(config, domain)
+	records := p.generateRecords(config, domain, params)
 
 	ctx.Output.Printf("Setting up %s DNS records for %s\n", config.DisplayName, domain)
 	ctx.Output.Println("=====================================")
@@ -136,15 +190,19 @@ func (p *ServicePlugin) setup(ctx *plugin.Context) error {
 		return nil
 	}
 
-	// Show what will be added
-	ctx.Output.Println("Records to be added:")
-	for _, record := range records {
-		mxPref := ""
-		if record.MXPref > 0 {
-			mxPref = fmt.Sprintf(" (priority: %d)", record.MXPref)
-		}
-		ctx.Output.Printf("  %s %s → %s%s\n", record.HostName, record.RecordType, record.Address, mxPref)
+	var allRecords []dnsrecord.Record
+	if replace {
+		allRecords = records
+	} else {
+		allRecords = append(allRecords, existingRecords...)
+		allRecords = append(allRecords, records...)
 	}
+
+	// Show what will change
+	var preview strings.Builder
+	diffview.Render(&preview, diffview.Compute(domain, existingRecords, allRecords), diffview.Options{Color: color})
+	ctx.Output.Println("Changes:")
+	ctx.Output.Print(preview.String())
 	ctx.Output.Println()
 
 	if dryRun {
@@ -153,13 +211,6 @@ func (p *ServicePlugin) setup(ctx *plugin.Context) error {
 	}
 
 	// Apply changes
-	var allRecords []dnsrecord.Record
-	if replace {
-		allRecords = records
-	} else {
-		allRecords = existingRecords
-		allRecords = append(allRecords, records...)
-	}
 
 	err = ctx.DNS.SetRecords(domain, allRecords)
 	if err != nil {
@@ -190,6 +241,12 @@ func (p *ServicePlugin) verify(ctx *plugin.Context) error {
 		return fmt.Errorf("service '%s' not found. Use 'service list' to see available services", serviceName)
 	}
 
+	assignments, _ := ctx.Flags["param"].([]string)
+	params, err := config.resolveParams(assignments)
+	if err != nil {
+		return err
+	}
+
 	records, err := ctx.DNS.GetRecords(domain)
 	if err != nil {
 		return fmt.Errorf("failed to get DNS records: %w", err)
@@ -202,40 +259,23 @@ func (p *ServicePlugin) verify(ctx *plugin.Context) error {
 	allGood := true
 	if config.Verification != nil && len(config.Verification.RequiredRecords) > 0 {
 		for _, check := range config.Verification.RequiredRecords {
-			found := false
-			for _, record := range records {
-				if record.HostName == check.Hostname && record.RecordType == check.Type {
-					// Check value matches
-					matches := false
-					if check.Contains != "" {
-						matches = strings.Contains(record.Address, check.Contains)
-					} else if check.Equals != "" {
-						matches = record.Address == check.Equals
-					} else if check.StartsWith != "" {
-						matches = strings.HasPrefix(record.Address, check.StartsWith)
-					} else {
-						matches = true // Just check existence
-					}
-
-					if matches {
-						found = true
-						break
-					}
-				}
+			if check.When != "" && !conditionHolds(check.When, params) {
+				continue
 			}
+			passed, detail := check.Evaluate(records)
 
 			status := "FAIL"
-			if found {
+			if passed {
 				status = "PASS"
 			} else {
 				allGood = false
 			}
 
-			ctx.Output.Printf("%s %s %s (%s)\n", status, check.Type, check.Hostname, check.Type)
+			ctx.Output.Printf("%s %s %s: %s (%s)\n", status, check.Type, check.Hostname, check.Describe(), detail)
 		}
 	} else {
 		// Generic verification - check if generated records exist
-		expectedRecords := p.generateRecords(config, domain)
+		expectedRecords := p.generateRecords(config, domain, params)
 		for _, expected := range expectedRecords {
 			found := false
 			for _, actual := range records {
@@ -258,11 +298,31 @@ func (p *ServicePlugin) verify(ctx *plugin.Context) error {
 		}
 	}
 
+	// Functional checks against the hosts the records point to
+	if deep, _ := ctx.Flags["deep"].(bool); deep {
+		checks := NewHealthChecker(DefaultHealthTimeout).Check(config, domain, records)
+
+		ctx.Output.Println()
+		ctx.Output.Println("Functional checks:")
+		if len(checks) == 0 {
+			ctx.Output.Println("  (no functional checks apply)")
+		}
+		for _, check := range checks {
+			status := "FAIL"
+			if check.Status {
+				status = "PASS"
+			} else {
+				allGood = false
+			}
+			ctx.Output.Printf("%s %s: %s\n", status, check.Name, check.Message)
+		}
+	}
+
 	ctx.Output.Println()
 	if allGood {
 		ctx.Output.Printf("All %s DNS records are properly configured!\n", config.DisplayName)
 	} else {
-		ctx.Output.Println("Some required records are missing or incorrect.")
+		ctx.Output.Println("Some required records are missing or incorrect, or a functional check failed.")
 		ctx.Output.Printf("Run 'zonekit service setup %s %s' to fix issues.\n", serviceName, domain)
 	}
 
@@ -285,6 +345,12 @@ func (p *ServicePlugin) remove(ctx *plugin.Context) error {
 
 	confirm, _ := ctx.Flags["confirm"].(bool)
 
+	assignments, _ := ctx.Flags["param"].([]string)
+	params, err := config.resolveParams(assignments)
+	if err != nil {
+		return err
+	}
+
 	if !confirm {
 		ctx.Output.Printf("This will remove all %s DNS records from %s.\n", config.DisplayName, domain)
 		ctx.Output.Println("Use --confirm to proceed.")
@@ -297,7 +363,7 @@ func (p *ServicePlugin) remove(ctx *plugin.Context) error {
 	}
 
 	// Generate expected records to identify what to remove
-	expectedRecords := p.generateRecords(config, domain)
+	expectedRecords := p.generateRecords(config, domain, params)
 	expectedMap := make(map[string]bool)
 	for _, record := range expectedRecords {
 		key := fmt.Sprintf("%s:%s:%s", record.HostName, record.RecordType, record.Address)
@@ -402,8 +468,31 @@ func (p *ServicePlugin) info(ctx *plugin.Context) error {
 		ctx.Output.Printf("Category: %s\n", config.Category)
 	}
 
+	assignments, _ := ctx.Flags["param"].([]string)
+	params, err := config.resolveParams(assignments)
+	if err != nil {
+		return err
+	}
+
+	if len(config.Params) > 0 {
+		ctx.Output.Println("\nParameters:")
+		for _, param := range config.Params {
+			ctx.Output.Printf("  %s", param.Name)
+			if len(param.Values) > 0 {
+				ctx.Output.Printf(" (%s)", strings.Join(param.Values, ", "))
+			}
+			if param.Default != "" {
+				ctx.Output.Printf(" [default: %s]", param.Default)
+			}
+			if param.Description != "" {
+				ctx.Output.Printf(" - %s", param.Description)
+			}
+			ctx.Output.Println()
+		}
+	}
+
 	ctx.Output.Println("\nDNS Records:")
-	records := p.generateRecords(config, "example.com")
+	records := p.generateRecords(config, "example.com", params)
 	for _, record := range records {
 		mxPref := ""
 		if record.MXPref > 0 {
@@ -415,13 +504,15 @@ func (p *ServicePlugin) info(ctx *plugin.Context) error {
 	return nil
 }
 
-// generateRecords generates DNS records from a service integration configuration
-func (p *ServicePlugin) generateRecords(config *Config, domainName string) []dnsrecord.Record {
+// generateRecords generates DNS records from a service integration configuration,
+// including the conditional blocks that hold for the parameters
+func (p *ServicePlugin) generateRecords(config *Config, domainName string, params map[string]string) []dnsrecord.Record {
 	var records []dnsrecord.Record
+	templateRecords := config.recordsFor(params)
 
 	// MX Records
-	for _, mx := range config.Records.MX {
-		ttl := mx.TTL
+	for _, mx := range templateRecords.MX {
+		ttl := int(mx.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
@@ -435,22 +526,22 @@ func (p *ServicePlugin) generateRecords(config *Config, domainName string) []dns
 	}
 
 	// SPF Record
-	if config.Records.SPF != nil {
-		ttl := config.Records.SPF.TTL
+	if templateRecords.SPF != nil {
+		ttl := int(templateRecords.SPF.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
 		records = append(records, dnsrecord.Record{
-			HostName:   config.Records.SPF.Hostname,
+			HostName:   templateRecords.SPF.Hostname,
 			RecordType: dnsrecord.RecordTypeTXT,
-			Address:    config.Records.SPF.Value,
+			Address:    templateRecords.SPF.Value,
 			TTL:        ttl,
 		})
 	}
 
 	// DKIM Records
-	for _, dkim := range config.Records.DKIM {
-		ttl := dkim.TTL
+	for _, dkim := range templateRecords.DKIM {
+		ttl := int(dkim.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
@@ -472,51 +563,58 @@ func (p *ServicePlugin) generateRecords(config *Config, domainName string) []dns
 	}
 
 	// DMARC Record
-	if config.Records.DMARC != nil {
-		ttl := config.Records.DMARC.TTL
+	if templateRecords.DMARC != nil {
+		ttl := int(templateRecords.DMARC.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
 		records = append(records, dnsrecord.Record{
-			HostName:   config.Records.DMARC.Hostname,
+			HostName:   templateRecords.DMARC.Hostname,
 			RecordType: dnsrecord.RecordTypeTXT,
-			Address:    config.Records.DMARC.Value,
+			Address:    templateRecords.DMARC.Value,
 			TTL:        ttl,
 		})
 	}
 
 	// Autodiscover
-	if config.Records.Autodiscover != nil {
-		ttl := config.Records.Autodiscover.TTL
+	if templateRecords.Autodiscover != nil {
+		ttl := int(templateRecords.Autodiscover.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
 
-		switch config.Records.Autodiscover.Type {
+		switch templateRecords.Autodiscover.Type {
 		case "CNAME":
 			records = append(records, dnsrecord.Record{
-				HostName:   config.Records.Autodiscover.Hostname,
+				HostName:   templateRecords.Autodiscover.Hostname,
 				RecordType: dnsrecord.RecordTypeCNAME,
-				Address:    ensureTrailingDot(config.Records.Autodiscover.CNAME),
+				Address:    ensureTrailingDot(templateRecords.Autodiscover.CNAME),
 				TTL:        ttl,
 			})
 		case "SRV":
-			// SRV records are more complex, for now we'll use CNAME
-			// Full SRV support can be added later
-			if config.Records.Autodiscover.Target != "" {
+			// SRV records live under the service name, e.g. _autodiscover._tcp
+			hostName := templateRecords.Autodiscover.Service
+			if h := templateRecords.Autodiscover.Hostname; h != "" && h != "@" {
+				hostName += "." + h
+			}
+			if templateRecords.Autodiscover.Target != "" {
 				records = append(records, dnsrecord.Record{
-					HostName:   config.Records.Autodiscover.Hostname,
-					RecordType: dnsrecord.RecordTypeCNAME,
-					Address:    ensureTrailingDot(config.Records.Autodiscover.Target),
-					TTL:        ttl,
+					HostName:   hostName,
+					RecordType: dnsrecord.RecordTypeSRV,
+					Address: fmt.Sprintf("%d %d %d %s",
+						templateRecords.Autodiscover.Priority,
+						templateRecords.Autodiscover.Weight,
+						templateRecords.Autodiscover.Port,
+						ensureTrailingDot(templateRecords.Autodiscover.Target)),
+					TTL: ttl,
 				})
 			}
 		}
 	}
 
 	// Custom Records
-	for _, custom := range config.Records.Custom {
-		ttl := custom.TTL
+	for _, custom := range templateRecords.Custom {
+		ttl := int(custom.TTL)
 		if ttl == 0 {
 			ttl = dns.DefaultTTL
 		}
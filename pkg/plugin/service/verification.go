@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// comparison is a parsed numeric constraint such as ">= 2"
+type comparison struct {
+	op    string
+	value int
+}
+
+// comparisonOps are the supported operators, longest first so ">=" wins over ">"
+var comparisonOps = []string{">=", "<=", "==", "!=", ">", "<", "="}
+
+// parseComparison parses "N" (exactly N) or "<op> N" with op one of >=, <=, >,
+// <, == and !=. parseValue converts the operand, e.g. dnsrecord.ParseTTL to
+// accept durations.
+func parseComparison(expr string, parseValue func(string) (int, error)) (comparison, error) {
+	expr = strings.TrimSpace(expr)
+	op := "=="
+	for _, candidate := range comparisonOps {
+		if strings.HasPrefix(expr, candidate) {
+			op = candidate
+			expr = strings.TrimSpace(strings.TrimPrefix(expr, candidate))
+			break
+		}
+	}
+	if op == "=" {
+		op = "=="
+	}
+
+	value, err := parseValue(expr)
+	if err != nil || expr == "" {
+		return comparison{}, fmt.Errorf("invalid comparison %q", expr)
+	}
+	return comparison{op: op, value: value}, nil
+}
+
+func (c comparison) holds(n int) bool {
+	switch c.op {
+	case ">=":
+		return n >= c.value
+	case "<=":
+		return n <= c.value
+	case ">":
+		return n > c.value
+	case "<":
+		return n < c.value
+	case "!=":
+		return n != c.value
+	default:
+		return n == c.value
+	}
+}
+
+// validate checks the assertions of a verification check
+func (c VerificationCheck) validate() error {
+	if c.Type == "" {
+		return fmt.Errorf("type is required")
+	}
+	if c.Hostname == "" {
+		return fmt.Errorf("hostname is required")
+	}
+	if c.Matches != "" {
+		if _, err := regexp.Compile(c.Matches); err != nil {
+			return fmt.Errorf("invalid matches pattern: %w", err)
+		}
+	}
+	if c.Count != "" {
+		if c.Absent {
+			return fmt.Errorf("count and absent cannot be combined")
+		}
+		if _, err := parseComparison(c.Count, strconv.Atoi); err != nil {
+			return fmt.Errorf("invalid count: %w", err)
+		}
+	}
+	if c.TTL != "" {
+		if _, err := parseComparison(c.TTL, dnsrecord.ParseTTL); err != nil {
+			return fmt.Errorf("invalid ttl: %w", err)
+		}
+	}
+	return nil
+}
+
+// Evaluate runs the check against a zone's records. Records of the check's
+// type and hostname match when their value satisfies every value assertion
+// (contains, equals, starts_with, matches) and their TTL satisfies ttl. The
+// check passes when at least one record matches, or when the number of
+// matching records satisfies count, or when none matches for absent checks.
+// The returned detail describes the outcome.
+func (c VerificationCheck) Evaluate(records []dnsrecord.Record) (bool, string) {
+	if err := c.validate(); err != nil {
+		return false, err.Error()
+	}
+
+	var pattern *regexp.Regexp
+	if c.Matches != "" {
+		pattern = regexp.MustCompile(c.Matches)
+	}
+	var ttl comparison
+	if c.TTL != "" {
+		ttl, _ = parseComparison(c.TTL, dnsrecord.ParseTTL)
+	}
+
+	matching := 0
+	for _, record := range records {
+		if record.HostName != c.Hostname || !strings.EqualFold(record.RecordType, c.Type) {
+			continue
+		}
+		if c.Contains != "" && !strings.Contains(record.Address, c.Contains) {
+			continue
+		}
+		if c.Equals != "" && record.Address != c.Equals {
+			continue
+		}
+		if c.StartsWith != "" && !strings.HasPrefix(record.Address, c.StartsWith) {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(record.Address) {
+			continue
+		}
+		if c.TTL != "" && !ttl.holds(record.TTL) {
+			continue
+		}
+		matching++
+	}
+
+	detail := fmt.Sprintf("%d matching record(s)", matching)
+	switch {
+	case c.Absent:
+		return matching == 0, detail
+	case c.Count != "":
+		count, _ := parseComparison(c.Count, strconv.Atoi)
+		return count.holds(matching), detail
+	default:
+		return matching > 0, detail
+	}
+}
+
+// Describe summarizes the assertions of the check, e.g. `contains "mx.example.com", count >= 2`
+func (c VerificationCheck) Describe() string {
+	var parts []string
+	if c.Contains != "" {
+		parts = append(parts, fmt.Sprintf("contains %q", c.Contains))
+	}
+	if c.Equals != "" {
+		parts = append(parts, fmt.Sprintf("equals %q", c.Equals))
+	}
+	if c.StartsWith != "" {
+		parts = append(parts, fmt.Sprintf("starts with %q", c.StartsWith))
+	}
+	if c.Matches != "" {
+		parts = append(parts, fmt.Sprintf("matches /%s/", c.Matches))
+	}
+	if c.TTL != "" {
+		parts = append(parts, "ttl "+strings.TrimSpace(c.TTL))
+	}
+	switch {
+	case c.Absent:
+		parts = append(parts, "absent")
+	case c.Count != "":
+		parts = append(parts, "count "+strings.TrimSpace(c.Count))
+	case len(parts) == 0:
+		parts = append(parts, "exists")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetUp reports whether records hold the service's setup: every required
+// record check that applies with the default parameters passes. Services
+// without required records, or with only absent checks, are never set up.
+func (c *Config) SetUp(records []dnsrecord.Record) bool {
+	if c.Verification == nil {
+		return false
+	}
+	params, err := c.resolveParams(nil)
+	if err != nil {
+		return false
+	}
+	present := false
+	for _, check := range c.Verification.RequiredRecords {
+		if check.When != "" && !conditionHolds(check.When, params) {
+			continue
+		}
+		if passed, _ := check.Evaluate(records); !passed {
+			return false
+		}
+		present = present || !check.Absent
+	}
+	return present
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// VerificationTestSuite is a test suite for verification checks
+type VerificationTestSuite struct {
+	suite.Suite
+	records []dnsrecord.Record
+}
+
+// TestVerificationSuite runs the verification test suite
+func TestVerificationSuite(t *testing.T) {
+	suite.Run(t, new(VerificationTestSuite))
+}
+
+func (s *VerificationTestSuite) SetupTest() {
+	s.records = []dnsrecord.Record{
+		{HostName: "@", RecordType: "MX", Address: "aspmx1.migadu.com.", TTL: 3600, MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "aspmx2.migadu.com.", TTL: 3600, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 include:spf.migadu.com -all", TTL: 300},
+	}
+}
+
+func (s *VerificationTestSuite) TestEvaluate() {
+	tests := []struct {
+		name  string
+		check VerificationCheck
+		pass  bool
+	}{
+		{"exists", VerificationCheck{Type: "MX", Hostname: "@"}, true},
+		{"type is case-insensitive", VerificationCheck{Type: "mx", Hostname: "@"}, true},
+		{"contains", VerificationCheck{Type: "TXT", Hostname: "@", Contains: "include:spf.migadu.com"}, true},
+		{"contains missing", VerificationCheck{Type: "TXT", Hostname: "@", Contains: "include:_spf.google.com"}, false},
+		{"matches", VerificationCheck{Type: "MX", Hostname: "@", Matches: `^aspmx[12]\.migadu\.com\.?$`}, true},
+		{"matches none", VerificationCheck{Type: "MX", Hostname: "@", Matches: `^mx\.`}, false},
+		{"exact count", VerificationCheck{Type: "MX", Hostname: "@", Count: "2"}, true},
+		{"exact count mismatch", VerificationCheck{Type: "MX", Hostname: "@", Count: "3"}, false},
+		{"count at least", VerificationCheck{Type: "MX", Hostname: "@", Count: ">= 2"}, true},
+		{"count below", VerificationCheck{Type: "MX", Hostname: "@", Count: "<2"}, false},
+		{"count zero", VerificationCheck{Type: "CNAME", Hostname: "www", Count: "0"}, true},
+		{"count with value assertion", VerificationCheck{Type: "MX", Hostname: "@", Contains: "aspmx1", Count: "1"}, true},
+		{"absent", VerificationCheck{Type: "MX", Hostname: "@", Contains: "registrar-servers.com", Absent: true}, true},
+		{"absent but present", VerificationCheck{Type: "MX", Hostname: "@", Contains: "migadu.com", Absent: true}, false},
+		{"ttl", VerificationCheck{Type: "MX", Hostname: "@", TTL: ">= 1h", Count: "2"}, true},
+		{"ttl too low", VerificationCheck{Type: "TXT", Hostname: "@", TTL: ">= 3600"}, false},
+		{"ttl exact", VerificationCheck{Type: "TXT", Hostname: "@", TTL: "300"}, true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			pass, detail := tt.check.Evaluate(s.records)
+			s.Require().Equal(tt.pass, pass, detail)
+		})
+	}
+}
+
+func (s *VerificationTestSuite) TestEvaluate_InvalidCheckFails() {
+	pass, detail := VerificationCheck{Type: "MX", Hostname: "@", Matches: "("}.Evaluate(s.records)
+	s.Require().False(pass)
+	s.Require().Contains(detail, "invalid matches pattern")
+}
+
+func (s *VerificationTestSuite) TestValidate() {
+	for name, check := range map[string]VerificationCheck{
+		"missing type":     {Hostname: "@"},
+		"missing hostname": {Type: "MX"},
+		"invalid regex":    {Type: "MX", Hostname: "@", Matches: "[a-"},
+		"invalid count":    {Type: "MX", Hostname: "@", Count: ">= two"},
+		"empty count":      {Type: "MX", Hostname: "@", Count: ">="},
+		"invalid ttl":      {Type: "MX", Hostname: "@", TTL: "<= soon"},
+		"count and absent": {Type: "MX", Hostname: "@", Count: "1", Absent: true},
+	} {
+		s.Run(name, func() {
+			s.Require().Error(check.validate())
+		})
+	}
+
+	s.Require().NoError(VerificationCheck{Type: "MX", Hostname: "@", Matches: `^a`, Count: "<= 3", TTL: "> 5m"}.validate())
+}
+
+func (s *VerificationTestSuite) TestDescribe() {
+	s.Require().Equal("exists", VerificationCheck{Type: "MX", Hostname: "@"}.Describe())
+	s.Require().Equal(`contains "registrar-servers.com", absent`,
+		VerificationCheck{Type: "MX", Hostname: "@", Contains: "registrar-servers.com", Absent: true}.Describe())
+	s.Require().Equal(`matches /^aspmx/, ttl >= 300, count 2`,
+		VerificationCheck{Type: "MX", Hostname: "@", Matches: "^aspmx", TTL: ">= 300", Count: "2"}.Describe())
+}
+
+func (s *VerificationTestSuite) TestBuiltinTemplatesAreValid() {
+	files, err := filepath.Glob(filepath.Join("services", "*.yaml"))
+	s.Require().NoError(err)
+	s.Require().NotEmpty(files)
+
+	for _, file := range files {
+		_, err := LoadConfig(file)
+		s.Require().NoError(err, file)
+	}
+}
+
+func (s *VerificationTestSuite) TestSetUp() {
+	config := &Config{Verification: &Verification{RequiredRecords: []VerificationCheck{
+		{Type: "MX", Hostname: "@", Contains: "migadu.com", Count: "2"},
+		{Type: "MX", Hostname: "@", Contains: "registrar-servers.com", Absent: true},
+	}}}
+	s.Require().True(config.SetUp(s.records))
+	s.Require().False(config.SetUp(s.records[:1]))
+
+	// Absent checks alone pass on any zone
+	absent := &Config{Verification: &Verification{RequiredRecords: []VerificationCheck{
+		{Type: "MX", Hostname: "@", Contains: "registrar-servers.com", Absent: true},
+	}}}
+	s.Require().False(absent.SetUp(s.records))
+	s.Require().False((&Config{}).SetUp(s.records))
+}
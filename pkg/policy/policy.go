@@ -0,0 +1,411 @@
+// Package policy checks DNS changes against an organization's rules before
+// they are applied, e.g. a minimum TTL in production, no CNAME at the apex or
+// an approver for MX changes. Rules are written in YAML, or in Rego and
+// evaluated with the opa command. Violations of error rules block a change;
+// violations of warning rules are reported only.
+package policy
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is what a violation of a rule does to a change
+type Severity string
+
+const (
+	// SeverityError blocks the change
+	SeverityError Severity = "error"
+	// SeverityWarning reports the violation and lets the change through
+	SeverityWarning Severity = "warning"
+)
+
+// Policy is a set of rules
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+
+	// rego is the path of a Rego policy, which replaces the rules
+	rego string
+}
+
+// Rule requires something of the records it matches. A rule without a
+// severity is an error rule.
+type Rule struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Severity    Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Match       Match    `json:"match,omitempty" yaml:"match,omitempty"`
+	Require     Require  `json:"require" yaml:"require"`
+}
+
+// Match selects the changes a rule applies to; empty fields match anything.
+// Domains and hosts are globs such as "*.example.com" and "_acme*", with "@"
+// for the apex.
+type Match struct {
+	Domains   []string `json:"domains,omitempty" yaml:"domains,omitempty"`
+	Accounts  []string `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	Providers []string `json:"providers,omitempty" yaml:"providers,omitempty"`
+	// Production selects accounts with production_guard enabled (true) or
+	// without it (false)
+	Production *bool    `json:"production,omitempty" yaml:"production,omitempty"`
+	Hosts      []string `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	Types      []string `json:"types,omitempty" yaml:"types,omitempty"`
+	// PrivateAddress selects A and AAAA records that point at a private,
+	// loopback or link-local address (true) or a public one (false)
+	PrivateAddress *bool `json:"private_address,omitempty" yaml:"private_address,omitempty"`
+}
+
+// Require is what a rule demands of the records it matches that are added or
+// changed
+type Require struct {
+	// MinTTL and MaxTTL bound the TTL in seconds; records written with the
+	// provider's default TTL are not checked
+	MinTTL int `json:"min_ttl,omitempty" yaml:"min_ttl,omitempty"`
+	MaxTTL int `json:"max_ttl,omitempty" yaml:"max_ttl,omitempty"`
+	// Hosts are the only hosts the records may be at
+	Hosts []string `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	// Absent forbids the records altogether
+	Absent bool `json:"absent,omitempty" yaml:"absent,omitempty"`
+	// Approval requires an approver (--approved-by) for adding, changing and
+	// removing the records
+	Approval bool `json:"approval,omitempty" yaml:"approval,omitempty"`
+}
+
+// Context describes where a change is applied and by whom
+type Context struct {
+	Domain     string
+	Account    string
+	Provider   string
+	Production bool
+	// ApprovedBy names who approved the change, if anyone
+	ApprovedBy string
+}
+
+// Violation is a rule broken by a change
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Load reads a policy file: Rego for files ending in .rego, YAML otherwise
+func Load(file string) (*Policy, error) {
+	if strings.EqualFold(filepath.Ext(file), ".rego") {
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		return &Policy{rego: file}, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Parse parses and validates a YAML policy
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Rego returns the path of a Rego policy, or "" for a YAML policy
+func (p *Policy) Rego() string {
+	return p.rego
+}
+
+// Validate checks that every rule is named, requires something and has valid
+// globs and severity. Rule severities and types are normalized.
+func (p *Policy) Validate() error {
+	names := make(map[string]bool, len(p.Rules))
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %s is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityError
+		case SeverityError, SeverityWarning:
+		default:
+			return fmt.Errorf("rule %s: invalid severity %q (use error or warning)", rule.Name, rule.Severity)
+		}
+
+		req := rule.Require
+		if req.MinTTL == 0 && req.MaxTTL == 0 && len(req.Hosts) == 0 && !req.Absent && !req.Approval {
+			return fmt.Errorf("rule %s requires nothing (set min_ttl, max_ttl, hosts, absent or approval)", rule.Name)
+		}
+		if req.MinTTL < 0 || req.MaxTTL < 0 || (req.MaxTTL > 0 && req.MinTTL > req.MaxTTL) {
+			return fmt.Errorf("rule %s: invalid TTL bounds %d to %d", rule.Name, req.MinTTL, req.MaxTTL)
+		}
+
+		for _, globs := range [][]string{rule.Match.Domains, rule.Match.Hosts, req.Hosts} {
+			for _, glob := range globs {
+				if _, err := path.Match(glob, ""); err != nil {
+					return fmt.Errorf("rule %s: invalid pattern %q", rule.Name, glob)
+				}
+			}
+		}
+		for j, t := range rule.Match.Types {
+			rule.Match.Types[j] = strings.ToUpper(t)
+		}
+	}
+	return nil
+}
+
+// Evaluate checks the change of a zone from before to after and returns the
+// violations, errors first
+func (p *Policy) Evaluate(ctx Context, before, after []dnsrecord.Record) ([]Violation, error) {
+	if p.rego != "" {
+		return evaluateRego(p.rego, ctx, before, after)
+	}
+
+	written, removed := changes(before, after)
+	var violations []Violation
+	for _, rule := range p.Rules {
+		if !rule.Match.appliesTo(ctx) {
+			continue
+		}
+		add := func(format string, args ...any) {
+			violations = append(violations, Violation{Rule: rule.Name, Severity: rule.Severity, Message: fmt.Sprintf(format, args...)})
+		}
+
+		matched := rule.Match.records(written)
+		for _, r := range matched {
+			name := recordName(r, ctx.Domain)
+			switch {
+			case rule.Require.Absent:
+				add("%s %s is not allowed", name, r.RecordType)
+			case len(rule.Require.Hosts) > 0 && !matchAny(rule.Require.Hosts, hostOf(r)):
+				add("%s %s is not at an allowed host (%s)", name, r.RecordType, strings.Join(rule.Require.Hosts, ", "))
+			}
+			if r.TTL > 0 && rule.Require.MinTTL > 0 && r.TTL < rule.Require.MinTTL {
+				add("%s %s has TTL %d, below the minimum of %d", name, r.RecordType, r.TTL, rule.Require.MinTTL)
+			}
+			if r.TTL > 0 && rule.Require.MaxTTL > 0 && r.TTL > rule.Require.MaxTTL {
+				add("%s %s has TTL %d, above the maximum of %d", name, r.RecordType, r.TTL, rule.Require.MaxTTL)
+			}
+		}
+
+		if rule.Require.Approval && ctx.ApprovedBy == "" {
+			if changed := append(matched, rule.Match.records(removed)...); len(changed) > 0 {
+				add("changing %s needs an approver (--approved-by)", describe(changed, ctx.Domain))
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Severity == SeverityError && violations[j].Severity != SeverityError
+	})
+	return violations, nil
+}
+
+// appliesTo reports whether the rule applies to changes made in ctx
+func (m Match) appliesTo(ctx Context) bool {
+	if len(m.Domains) > 0 && !matchAny(m.Domains, strings.ToLower(strings.TrimSuffix(ctx.Domain, "."))) {
+		return false
+	}
+	if len(m.Accounts) > 0 && !contains(m.Accounts, ctx.Account) {
+		return false
+	}
+	if len(m.Providers) > 0 && !contains(m.Providers, ctx.Provider) {
+		return false
+	}
+	return m.Production == nil || *m.Production == ctx.Production
+}
+
+// records returns the records the rule matches
+func (m Match) records(records []dnsrecord.Record) []dnsrecord.Record {
+	var matched []dnsrecord.Record
+	for _, r := range records {
+		if len(m.Types) > 0 && !contains(m.Types, strings.ToUpper(r.RecordType)) {
+			continue
+		}
+		if len(m.Hosts) > 0 && !matchAny(m.Hosts, hostOf(r)) {
+			continue
+		}
+		if m.PrivateAddress != nil && !(isAddress(r) && isPrivate(r.Address) == *m.PrivateAddress) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+	return matched
+}
+
+// changes returns the records of after that are new or changed and the
+// records of before that are gone or changed
+func changes(before, after []dnsrecord.Record) (written, removed []dnsrecord.Record) {
+	counts := make(map[string]int, len(before))
+	for _, r := range before {
+		counts[recordKey(r)]++
+	}
+	for _, r := range after {
+		if key := recordKey(r); counts[key] > 0 {
+			counts[key]--
+		} else {
+			written = append(written, r)
+		}
+	}
+	for _, r := range before {
+		if key := recordKey(r); counts[key] > 0 {
+			counts[key]--
+			removed = append(removed, r)
+		}
+	}
+	return written, removed
+}
+
+// recordKey identifies a record by host, type, normalized value, TTL and MX
+// preference
+func recordKey(r dnsrecord.Record) string {
+	return fmt.Sprintf("%s %s %s %d %d", hostOf(r), strings.ToUpper(r.RecordType), zonecompare.Normalize(r), r.TTL, r.MXPref)
+}
+
+// hostOf returns the lower-cased host of a record, "@" for the apex
+func hostOf(r dnsrecord.Record) string {
+	host := strings.ToLower(strings.TrimSuffix(r.HostName, "."))
+	if host == "" {
+		return "@"
+	}
+	return host
+}
+
+// recordName returns the name of a record within domainName
+func recordName(r dnsrecord.Record, domainName string) string {
+	if host := hostOf(r); host != "@" {
+		return host + "." + domainName
+	}
+	return domainName
+}
+
+// describe names the types and hosts of records, e.g. "MX records at example.com"
+func describe(records []dnsrecord.Record, domainName string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range records {
+		name := strings.ToUpper(r.RecordType) + " records at " + recordName(r, domainName)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// isAddress reports whether a record holds an IP address
+func isAddress(r dnsrecord.Record) bool {
+	t := strings.ToUpper(r.RecordType)
+	return t == dnsrecord.RecordTypeA || t == dnsrecord.RecordTypeAAAA
+}
+
+// isPrivate reports whether address is private, loopback or link-local
+func isPrivate(address string) bool {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// matchAny reports whether name matches one of the globs
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(strings.ToLower(glob), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the violation as "[rule] message"
+func (v Violation) String() string {
+	return "[" + v.Rule + "] " + v.Message
+}
+
+// ViolationError is returned for a change that breaks error rules
+type ViolationError struct {
+	Domain     string
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.String())
+	}
+	return fmt.Sprintf("change to %s blocked by policy: %s", e.Domain, strings.Join(messages, "; "))
+}
+
+// Checker checks the changes made in a context against a policy; it is the
+// policy of a DNS service
+type Checker struct {
+	policy *Policy
+	ctx    Context
+}
+
+// NewChecker creates a checker for changes made in ctx; the domain is set per
+// change
+func NewChecker(policy *Policy, ctx Context) *Checker {
+	return &Checker{policy: policy, ctx: ctx}
+}
+
+// Check evaluates a change and returns the violations of warning rules. The
+// violations of error rules are returned as a *ViolationError.
+func (c *Checker) Check(domainName string, before, after []dnsrecord.Record) ([]string, error) {
+	ctx := c.ctx
+	ctx.Domain = domainName
+	violations, err := c.policy.Evaluate(ctx, before, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+
+	var warnings []string
+	var blocking []Violation
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			blocking = append(blocking, v)
+		} else {
+			warnings = append(warnings, v.String())
+		}
+	}
+	if len(blocking) > 0 {
+		return warnings, &ViolationError{Domain: domainName, Violations: blocking}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+const examplePolicy = `
+rules:
+  - name: prod-min-ttl
+    description: TTL must be at least 300 in production
+    match:
+      production: true
+    require:
+      min_ttl: 300
+  - name: no-apex-cname
+    match:
+      hosts: ["@"]
+      types: [cname]
+    require:
+      absent: true
+  - name: mx-approval
+    match:
+      types: [MX]
+    require:
+      approval: true
+  - name: internal-ips
+    severity: warning
+    match:
+      private_address: true
+    require:
+      hosts: ["corp", "*.corp"]
+`
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{name: "no name", policy: "rules:\n  - require: {absent: true}", want: "has no name"},
+		{name: "duplicate", policy: "rules:\n  - {name: a, require: {absent: true}}\n  - {name: a, require: {absent: true}}", want: "defined twice"},
+		{name: "nothing required", policy: "rules:\n  - {name: a, match: {types: [A]}}", want: "requires nothing"},
+		{name: "severity", policy: "rules:\n  - {name: a, severity: fatal, require: {absent: true}}", want: "invalid severity"},
+		{name: "ttl bounds", policy: "rules:\n  - {name: a, require: {min_ttl: 600, max_ttl: 300}}", want: "invalid TTL bounds"},
+		{name: "pattern", policy: "rules:\n  - {name: a, match: {hosts: ['[']}, require: {absent: true}}", want: "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.policy))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(examplePolicy))
+	require.NoError(t, err)
+	require.Equal(t, SeverityError, p.Rules[0].Severity)
+
+	before := []dnsrecord.Record{
+		testutil.NewRecord("@", "A", "192.0.2.1").TTL(60).Build(),
+		testutil.MX("@", 10, "mail.example.com"),
+	}
+	prod := Context{Domain: "example.com", Production: true}
+
+	// Unchanged records are not checked, even if they break a rule
+	violations, err := p.Evaluate(prod, before, before)
+	require.NoError(t, err)
+	require.Empty(t, violations)
+
+	after := append(append([]dnsrecord.Record{}, before[0]),
+		testutil.NewRecord("www", "A", "192.0.2.2").TTL(60).Build(),
+		testutil.CNAME("@", "other.example.net"),
+		testutil.NewRecord("db", "A", "10.0.0.5").TTL(3600).Build(),
+		testutil.NewRecord("db.corp", "A", "10.0.0.6").TTL(3600).Build(),
+	)
+	violations, err = p.Evaluate(prod, before, after)
+	require.NoError(t, err)
+	require.Equal(t, []Violation{
+		{Rule: "prod-min-ttl", Severity: SeverityError, Message: "www.example.com A has TTL 60, below the minimum of 300"},
+		{Rule: "no-apex-cname", Severity: SeverityError, Message: "example.com CNAME is not allowed"},
+		{Rule: "mx-approval", Severity: SeverityError, Message: "changing MX records at example.com needs an approver (--approved-by)"},
+		{Rule: "internal-ips", Severity: SeverityWarning, Message: "db.example.com A is not at an allowed host (corp, *.corp)"},
+	}, violations)
+
+	// Outside production and with an approver only the structural rules apply
+	violations, err = p.Evaluate(Context{Domain: "example.com", ApprovedBy: "alice"}, before, after)
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	require.Equal(t, "no-apex-cname", violations[0].Rule)
+	require.Equal(t, "internal-ips", violations[1].Rule)
+}
+
+func TestEvaluate_MatchContext(t *testing.T) {
+	p, err := Parse([]byte(`
+rules:
+  - name: corp-only
+    match:
+      domains: ["*.example.com"]
+      accounts: [work]
+      providers: [cloudflare]
+    require:
+      max_ttl: 3600
+`))
+	require.NoError(t, err)
+	after := []dnsrecord.Record{testutil.NewRecord("www", "A", "192.0.2.1").TTL(86400).Build()}
+
+	ctx := Context{Domain: "shop.example.com", Account: "work", Provider: "cloudflare"}
+	violations, err := p.Evaluate(ctx, nil, after)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+
+	for _, other := range []Context{
+		{Domain: "example.com", Account: "work", Provider: "cloudflare"},
+		{Domain: "shop.example.com", Account: "home", Provider: "cloudflare"},
+		{Domain: "shop.example.com", Account: "work", Provider: "namecheap"},
+	} {
+		violations, err := p.Evaluate(other, nil, after)
+		require.NoError(t, err)
+		require.Empty(t, violations, "%+v", other)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	p, err := Parse([]byte(examplePolicy))
+	require.NoError(t, err)
+	checker := NewChecker(p, Context{})
+
+	warnings, err := checker.Check("example.com", nil, []dnsrecord.Record{testutil.A("db", "10.0.0.5")})
+	require.NoError(t, err)
+	require.Equal(t, []string{"[internal-ips] db.example.com A is not at an allowed host (corp, *.corp)"}, warnings)
+
+	// Removing MX records needs approval too
+	_, err = checker.Check("example.com", []dnsrecord.Record{testutil.MX("@", 10, "mail.example.com")}, nil)
+	var violation *ViolationError
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, "example.com", violation.Domain)
+	require.EqualError(t, err, "change to example.com blocked by policy: [mx-approval] changing MX records at example.com needs an approver (--approved-by)")
+}
+
+func TestLoad_Rego(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.rego")
+	require.NoError(t, os.WriteFile(file, []byte("package zonekit\n"), 0600))
+	p, err := Load(file)
+	require.NoError(t, err)
+	require.Equal(t, file, p.Rego())
+
+	var input regoInput
+	original := runOPA
+	defer func() { runOPA = original }()
+	runOPA = func(gotFile, query string, data []byte) ([]byte, error) {
+		require.Equal(t, file, gotFile)
+		require.Equal(t, RegoQuery, query)
+		require.NoError(t, json.Unmarshal(data, &input))
+		return []byte(`{"result":[{"expressions":[{"value":{"deny":["no CNAME at the apex"],"warn":["low TTL"]}}]}]}`), nil
+	}
+
+	ctx := Context{Domain: "example.com", Account: "work", ApprovedBy: "alice"}
+	violations, err := p.Evaluate(ctx, nil, []dnsrecord.Record{testutil.CNAME("", "other.example.net")})
+	require.NoError(t, err)
+	require.Equal(t, []Violation{
+		{Rule: "deny", Severity: SeverityError, Message: "no CNAME at the apex"},
+		{Rule: "warn", Severity: SeverityWarning, Message: "low TTL"},
+	}, violations)
+	require.Equal(t, "alice", input.ApprovedBy)
+	require.Equal(t, []regoRecord{{Host: "@", Type: "CNAME", Value: "other.example.net"}}, input.Written)
+	require.Empty(t, input.Removed)
+}
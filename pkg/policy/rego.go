@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// RegoQuery is the query evaluated for Rego policies. The package zonekit
+// defines deny and warn as sets of messages, e.g.
+//
+//	deny contains msg if {
+//		some r in input.written
+//		r.type == "CNAME"
+//		r.host == "@"
+//		msg := "no CNAME at the apex"
+//	}
+const RegoQuery = "data.zonekit"
+
+// regoInput is the input document of a Rego policy
+type regoInput struct {
+	Domain     string       `json:"domain"`
+	Account    string       `json:"account"`
+	Provider   string       `json:"provider"`
+	Production bool         `json:"production"`
+	ApprovedBy string       `json:"approved_by"`
+	Written    []regoRecord `json:"written"`
+	Removed    []regoRecord `json:"removed"`
+	Records    []regoRecord `json:"records"`
+}
+
+// regoRecord is a record in the input of a Rego policy
+type regoRecord struct {
+	Host     string `json:"host"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// runOPA evaluates a query of a Rego file with opa eval and returns its JSON
+// output. It is a variable so tests can replace it.
+var runOPA = func(file, query string, input []byte) ([]byte, error) {
+	if _, err := exec.LookPath("opa"); err != nil {
+		return nil, fmt.Errorf("the opa command, needed for Rego policies, is not in PATH")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("opa", "eval", "--format", "json", "--data", file, "--stdin-input", query)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("opa: %s", msg)
+		}
+		return nil, fmt.Errorf("opa: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// evaluateRego evaluates a Rego policy; its deny messages are errors and its
+// warn messages warnings
+func evaluateRego(file string, ctx Context, before, after []dnsrecord.Record) ([]Violation, error) {
+	written, removed := changes(before, after)
+	input, err := json.Marshal(regoInput{
+		Domain:     ctx.Domain,
+		Account:    ctx.Account,
+		Provider:   ctx.Provider,
+		Production: ctx.Production,
+		ApprovedBy: ctx.ApprovedBy,
+		Written:    regoRecords(written),
+		Removed:    regoRecords(removed),
+		Records:    regoRecords(after),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := runOPA(file, RegoQuery, input)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Result []struct {
+			Expressions []struct {
+				Value struct {
+					Deny []string `json:"deny"`
+					Warn []string `json:"warn"`
+				} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to read opa output: %w", err)
+	}
+
+	var violations []Violation
+	for _, r := range result.Result {
+		for _, e := range r.Expressions {
+			sort.Strings(e.Value.Deny)
+			sort.Strings(e.Value.Warn)
+			for _, msg := range e.Value.Deny {
+				violations = append(violations, Violation{Rule: "deny", Severity: SeverityError, Message: msg})
+			}
+			for _, msg := range e.Value.Warn {
+				violations = append(violations, Violation{Rule: "warn", Severity: SeverityWarning, Message: msg})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// regoRecords converts records for the input of a Rego policy
+func regoRecords(records []dnsrecord.Record) []regoRecord {
+	out := make([]regoRecord, 0, len(records))
+	for _, r := range records {
+		out = append(out, regoRecord{Host: hostOf(r), Type: strings.ToUpper(r.RecordType), Value: r.Address, TTL: r.TTL, Priority: r.MXPref})
+	}
+	return out
+}
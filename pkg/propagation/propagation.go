@@ -0,0 +1,225 @@
+// Package propagation confirms DNS changes by polling a zone's authoritative
+// name servers until they serve the new values of the changed record sets.
+package propagation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+const (
+	// DefaultTimeout bounds how long Wait polls
+	DefaultTimeout = 5 * time.Minute
+	// DefaultInterval is the pause between polling rounds
+	DefaultInterval = 10 * time.Second
+)
+
+// Critical reports whether a change to a record is worth confirming: a wrong
+// MX, NS or apex A record takes mail, delegation or the whole site down
+func Critical(r dnsrecord.Record) bool {
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeMX, dnsrecord.RecordTypeNS:
+		return true
+	case dnsrecord.RecordTypeA:
+		return r.HostName == "" || r.HostName == "@"
+	}
+	return false
+}
+
+// Server is an authoritative name server of a zone
+type Server struct {
+	Name     string
+	Resolver zonecompare.Resolver
+}
+
+// NameServers looks up the name servers of zone with resolver and returns a
+// server for each, queried directly
+func NameServers(ctx context.Context, resolver zonecompare.Resolver, zone string) ([]Server, error) {
+	records, err := resolver.Lookup(ctx, zone, "@", dnsrecord.RecordTypeNS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the name servers of %s: %w", zone, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s has no name servers", zone)
+	}
+
+	servers := make([]Server, 0, len(records))
+	for _, r := range records {
+		name := strings.TrimSuffix(r.Address, ".")
+		servers = append(servers, Server{Name: name, Resolver: lookup.NewResolver(name)})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers, nil
+}
+
+// Status is the propagation of one record set
+type Status struct {
+	zonecompare.Key
+	// Want holds the normalized values the servers must serve; empty for a
+	// deleted record set
+	Want []string
+	// Done reports whether every server served Want before the timeout
+	Done bool
+	// Elapsed is the time until the last server served Want, or the time
+	// waited when it did not
+	Elapsed time.Duration
+	// Pending lists the servers that did not serve Want in time
+	Pending []string
+}
+
+// Waiter polls name servers until they serve the expected record sets
+type Waiter struct {
+	Servers  []Server
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Wait polls the servers until each serves the values records hold for every
+// changed record set, or until the timeout. Values are compared the way
+// zonecompare compares them, ignoring TTLs. The statuses are sorted by record set.
+func (w *Waiter) Wait(ctx context.Context, zone string, changed, records []dnsrecord.Record) []Status {
+	interval, timeout := w.Interval, w.Timeout
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	statuses := sets(changed, records, Critical)
+	// pending[i] holds the servers that have not served statuses[i].Want yet
+	pending := make([]map[string]bool, len(statuses))
+	for i := range statuses {
+		pending[i] = make(map[string]bool, len(w.Servers))
+		for _, server := range w.Servers {
+			pending[i][server.Name] = true
+		}
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+poll:
+	for {
+		remaining := 0
+		for i := range statuses {
+			if statuses[i].Done {
+				continue
+			}
+			for _, server := range w.Servers {
+				if pending[i][server.Name] && serves(ctx, server, zone, statuses[i]) {
+					delete(pending[i], server.Name)
+				}
+			}
+			if len(pending[i]) == 0 {
+				statuses[i].Done = true
+				statuses[i].Elapsed = time.Since(start)
+				continue
+			}
+			remaining++
+		}
+
+		if remaining == 0 || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break poll
+		case <-time.After(interval):
+		}
+	}
+
+	for i := range statuses {
+		if statuses[i].Done {
+			continue
+		}
+		statuses[i].Elapsed = time.Since(start)
+		for name := range pending[i] {
+			statuses[i].Pending = append(statuses[i].Pending, name)
+		}
+		sort.Strings(statuses[i].Pending)
+	}
+	return statuses
+}
+
+// serves reports whether server answers with exactly the wanted values
+func serves(ctx context.Context, server Server, zone string, status Status) bool {
+	answers, err := server.Resolver.Lookup(ctx, zone, status.HostName, status.Type)
+	if err != nil {
+		return false
+	}
+	values := make([]string, 0, len(answers))
+	for _, r := range answers {
+		values = append(values, zonecompare.Normalize(r))
+	}
+	return equal(uniqueSorted(values), status.Want)
+}
+
+// sets returns a status for each record set among changed that include
+// selects, wanting the values records hold for it
+func sets(changed, records []dnsrecord.Record, include func(dnsrecord.Record) bool) []Status {
+	var statuses []Status
+	seen := make(map[zonecompare.Key]bool)
+	for _, r := range changed {
+		key := keyOf(r)
+		if !include(r) || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var want []string
+		for _, current := range records {
+			if keyOf(current) == key {
+				want = append(want, zonecompare.Normalize(current))
+			}
+		}
+		statuses = append(statuses, Status{Key: key, Want: uniqueSorted(want)})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].HostName != statuses[j].HostName {
+			return statuses[i].HostName < statuses[j].HostName
+		}
+		return statuses[i].Type < statuses[j].Type
+	})
+	return statuses
+}
+
+func keyOf(r dnsrecord.Record) zonecompare.Key {
+	host := strings.TrimSuffix(strings.ToLower(r.HostName), ".")
+	if host == "" {
+		host = "@"
+	}
+	return zonecompare.Key{HostName: host, Type: strings.ToUpper(r.RecordType)}
+}
+
+func uniqueSorted(values []string) []string {
+	out := []string{}
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
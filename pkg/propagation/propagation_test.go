@@ -0,0 +1,155 @@
+package propagation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// fakeServer answers with old records until it has been queried staleQueries
+// times, then with new records
+type fakeServer struct {
+	old, new     []dnsrecord.Record
+	staleQueries int
+	queries      int
+}
+
+func (f *fakeServer) Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error) {
+	f.queries++
+	records := f.new
+	if f.queries <= f.staleQueries {
+		records = f.old
+	}
+	var found []dnsrecord.Record
+	for _, r := range records {
+		if r.HostName == hostname && r.RecordType == recordType {
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+// PropagationTestSuite tests waiting for changes to reach name servers
+type PropagationTestSuite struct {
+	suite.Suite
+}
+
+func TestPropagationSuite(t *testing.T) {
+	suite.Run(t, new(PropagationTestSuite))
+}
+
+var (
+	oldMX = dnsrecord.Record{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 10, TTL: 300}
+	newMX = dnsrecord.Record{HostName: "@", RecordType: "MX", Address: "mx2.example.net", MXPref: 10, TTL: 1800}
+	www   = dnsrecord.Record{HostName: "www", RecordType: "A", Address: "192.0.2.1"}
+)
+
+func (s *PropagationTestSuite) TestCritical() {
+	s.Require().True(Critical(newMX))
+	s.Require().True(Critical(dnsrecord.Record{HostName: "@", RecordType: "A"}))
+	s.Require().True(Critical(dnsrecord.Record{HostName: "sub", RecordType: "NS"}))
+	s.Require().False(Critical(www))
+	s.Require().False(Critical(dnsrecord.Record{HostName: "@", RecordType: "TXT"}))
+}
+
+func (s *PropagationTestSuite) TestWait_UntilEveryServerServesNewValues() {
+	fast := &fakeServer{new: []dnsrecord.Record{newMX}}
+	slow := &fakeServer{old: []dnsrecord.Record{oldMX}, new: []dnsrecord.Record{newMX}, staleQueries: 2}
+
+	waiter := &Waiter{
+		Servers:  []Server{{Name: "ns1", Resolver: fast}, {Name: "ns2", Resolver: slow}},
+		Interval: time.Millisecond,
+		Timeout:  time.Second,
+	}
+	statuses := waiter.Wait(context.Background(), "example.com", []dnsrecord.Record{newMX, www}, []dnsrecord.Record{newMX, www})
+
+	// Only the MX record set is critical; servers are not asked again once they serve it
+	s.Require().Len(statuses, 1)
+	s.Require().Equal("@", statuses[0].HostName)
+	s.Require().Equal([]string{"10 mx2.example.net"}, statuses[0].Want)
+	s.Require().True(statuses[0].Done)
+	s.Require().Empty(statuses[0].Pending)
+	s.Require().Equal(1, fast.queries)
+	s.Require().Equal(3, slow.queries)
+}
+
+func (s *PropagationTestSuite) TestWait_DeletedRecordSetAndTimeout() {
+	stale := &fakeServer{old: []dnsrecord.Record{oldMX}, staleQueries: 1000}
+	gone := &fakeServer{}
+
+	waiter := &Waiter{
+		Servers:  []Server{{Name: "ns2", Resolver: stale}, {Name: "ns1", Resolver: gone}},
+		Interval: time.Millisecond,
+		Timeout:  20 * time.Millisecond,
+	}
+	statuses := waiter.Wait(context.Background(), "example.com", []dnsrecord.Record{oldMX}, nil)
+
+	s.Require().Len(statuses, 1)
+	s.Require().Equal([]string{}, statuses[0].Want)
+	s.Require().False(statuses[0].Done)
+	s.Require().Equal([]string{"ns2"}, statuses[0].Pending)
+	s.Require().GreaterOrEqual(statuses[0].Elapsed, 10*time.Millisecond)
+}
+
+func (s *PropagationTestSuite) TestNameServers() {
+	resolver := &fakeServer{new: []dnsrecord.Record{
+		{HostName: "@", RecordType: "NS", Address: "ns2.example.net."},
+		{HostName: "@", RecordType: "NS", Address: "ns1.example.net."},
+	}}
+
+	servers, err := NameServers(context.Background(), resolver, "example.com")
+	s.Require().NoError(err)
+	s.Require().Len(servers, 2)
+	s.Require().Equal("ns1.example.net", servers[0].Name)
+	s.Require().Equal("ns2.example.net", servers[1].Name)
+
+	_, err = NameServers(context.Background(), &fakeServer{}, "example.com")
+	s.Require().ErrorContains(err, "has no name servers")
+}
+
+// failingServer cannot be queried
+type failingServer struct{}
+
+func (failingServer) Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error) {
+	return nil, errors.New("timeout")
+}
+
+func (s *PropagationTestSuite) TestWarm() {
+	current := &fakeServer{new: []dnsrecord.Record{newMX, www}}
+	cached := &fakeServer{new: []dnsrecord.Record{oldMX}}
+
+	servers := []Server{{Name: "current", Resolver: current}, {Name: "cached", Resolver: cached}, {Name: "down", Resolver: failingServer{}}}
+	answers := Warm(context.Background(), servers, "example.com", []dnsrecord.Record{newMX, www}, []dnsrecord.Record{newMX, www})
+
+	// Every changed record set is queried once at each server, not only critical ones
+	s.Require().Len(answers, 6)
+	s.Require().Equal(2, current.queries)
+	s.Require().Equal("@", answers[0].HostName)
+	s.Require().Equal("current", answers[0].Server)
+	s.Require().True(answers[0].Current)
+	s.Require().Equal(1800, answers[0].TTL)
+
+	s.Require().Equal("cached", answers[1].Server)
+	s.Require().False(answers[1].Current)
+	s.Require().Equal([]string{"10 mx1.example.net"}, answers[1].Values)
+	s.Require().Equal(300, answers[1].TTL)
+
+	s.Require().Equal("down", answers[2].Server)
+	s.Require().Error(answers[2].Err)
+	s.Require().False(answers[2].Current)
+
+	s.Require().Equal("www", answers[3].HostName)
+	s.Require().True(answers[3].Current)
+	s.Require().False(answers[4].Current)
+	s.Require().Empty(answers[4].Values)
+}
+
+func (s *PropagationTestSuite) TestResolvers() {
+	s.Require().Len(PublicResolvers(), 4)
+	servers := Resolvers([]string{"10.0.0.53"})
+	s.Require().Equal("10.0.0.53", servers[0].Name)
+}
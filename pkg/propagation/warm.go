@@ -0,0 +1,96 @@
+package propagation
+
+import (
+	"context"
+	"sync"
+
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+// publicResolvers are the major public resolvers Warm primes by default
+var publicResolvers = []struct{ name, address string }{
+	{"Google", "8.8.8.8"},
+	{"Cloudflare", "1.1.1.1"},
+	{"Quad9", "9.9.9.9"},
+	{"OpenDNS", "208.67.222.222"},
+}
+
+// PublicResolvers returns servers for the major public resolvers
+func PublicResolvers() []Server {
+	servers := make([]Server, 0, len(publicResolvers))
+	for _, r := range publicResolvers {
+		servers = append(servers, Server{Name: r.name + " (" + r.address + ")", Resolver: lookup.NewResolver(r.address)})
+	}
+	return servers
+}
+
+// Resolvers returns a server for each address, e.g. a corporate resolver
+func Resolvers(addresses []string) []Server {
+	servers := make([]Server, 0, len(addresses))
+	for _, address := range addresses {
+		servers = append(servers, Server{Name: address, Resolver: lookup.NewResolver(address)})
+	}
+	return servers
+}
+
+// Answer is what a resolver served for a changed record set
+type Answer struct {
+	zonecompare.Key
+	Server string
+	// Values are the normalized values served, empty if the name has no
+	// records of the type
+	Values []string
+	// TTL is the lowest TTL served, which for a cached answer is the time left
+	// until the resolver asks the name servers again
+	TTL int
+	// Current reports whether the values are those the zone now holds
+	Current bool
+	// Err is set when the resolver could not be queried
+	Err error
+}
+
+// Warm queries each server once for every changed record set Lookup supports,
+// which makes resolvers cache the answer, and reports what each served
+// compared with the values records hold. Unlike Wait it covers every changed
+// record set, not only critical ones. The answers are sorted by record set and
+// then in the order of servers.
+func Warm(ctx context.Context, servers []Server, zone string, changed, records []dnsrecord.Record) []Answer {
+	statuses := sets(changed, records, func(r dnsrecord.Record) bool { return lookup.Supports(r.RecordType) })
+
+	answers := make([]Answer, len(statuses)*len(servers))
+	var wg sync.WaitGroup
+	for j, server := range servers {
+		wg.Add(1)
+		go func(j int, server Server) {
+			defer wg.Done()
+			for i, status := range statuses {
+				answers[i*len(servers)+j] = query(ctx, server, zone, status)
+			}
+		}(j, server)
+	}
+	wg.Wait()
+	return answers
+}
+
+// query asks server for a record set
+func query(ctx context.Context, server Server, zone string, status Status) Answer {
+	answer := Answer{Key: status.Key, Server: server.Name}
+	records, err := server.Resolver.Lookup(ctx, zone, status.HostName, status.Type)
+	if err != nil {
+		answer.Err = err
+		return answer
+	}
+
+	values := make([]string, 0, len(records))
+	for i, r := range records {
+		values = append(values, zonecompare.Normalize(r))
+		if i == 0 || r.TTL < answer.TTL {
+			answer.TTL = r.TTL
+		}
+	}
+	answer.Values = uniqueSorted(values)
+	answer.Current = equal(answer.Values, status.Want)
+	return answer
+}
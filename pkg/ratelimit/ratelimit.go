@@ -0,0 +1,282 @@
+// Package ratelimit paces the requests zonekit makes to provider APIs, so bulk
+// operations stay within a provider's limits instead of being answered with
+// 429s or getting the account blocked.
+//
+// Every provider instance has one limiter, shared by all its clients in the
+// process, with a token bucket for each of its rates, e.g. per minute and per
+// day. Its rates are the override set with SetOverrides for the instance or its
+// type, or otherwise the provider type's defaults from Defaults; without either,
+// requests are not paced.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is a number of requests allowed per period. The zero Rate is unlimited.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Unlimited reports whether the rate does not limit requests
+func (r Rate) Unlimited() bool {
+	return r.Requests <= 0 || r.Per <= 0
+}
+
+// String formats the rate as ParseRate reads it, e.g. "50/min"
+func (r Rate) String() string {
+	if r.Unlimited() {
+		return "unlimited"
+	}
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/min", r.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Requests)
+	case day:
+		return fmt.Sprintf("%d/day", r.Requests)
+	}
+	return fmt.Sprintf("%d/%s", r.Requests, r.Per)
+}
+
+// day is the period of daily rates
+const day = 24 * time.Hour
+
+// units are the period names ParseRate accepts besides Go durations
+var units = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+	"d": day, "day": day,
+}
+
+// ParseRate parses a rate such as "50/min", "5/s", "1000/hour", "8000/day" or
+// "1200/5m".
+// "unlimited" (or "0") turns pacing off, e.g. to override a default.
+func ParseRate(value string) (Rate, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "unlimited" || value == "0" {
+		return Rate{}, nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q: want requests/period, e.g. 50/min", value)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests < 1 {
+		return Rate{}, fmt.Errorf("invalid rate %q: the number of requests must be a positive integer", value)
+	}
+	period = strings.TrimSpace(period)
+	per, known := units[period]
+	if !known {
+		per, err = time.ParseDuration(period)
+		if err != nil || per <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q: unknown period %q", value, period)
+		}
+	}
+	return Rate{Requests: requests, Per: per}, nil
+}
+
+// ParseRates parses a comma-separated list of rates that all apply, such as
+// "50/min,700/h". "unlimited" (or "0") turns pacing off and returns no rates.
+func ParseRates(value string) ([]Rate, error) {
+	var rates []Rate
+	for _, part := range strings.Split(value, ",") {
+		rate, err := ParseRate(part)
+		if err != nil {
+			return nil, err
+		}
+		if !rate.Unlimited() {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// FormatRates formats rates as ParseRates reads them
+func FormatRates(rates []Rate) string {
+	if len(rates) == 0 {
+		return "unlimited"
+	}
+	parts := make([]string, len(rates))
+	for i, rate := range rates {
+		parts[i] = rate.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Defaults are the rates of provider types that publish their API limits,
+// used unless overridden. Namecheap caps calls per minute, hour and day.
+var Defaults = map[string][]Rate{
+	"namecheap": {
+		{Requests: 50, Per: time.Minute},
+		{Requests: 700, Per: time.Hour},
+		{Requests: 8000, Per: day},
+	},
+	"cloudflare":   {{Requests: 1200, Per: 5 * time.Minute}},
+	"digitalocean": {{Requests: 250, Per: time.Minute}},
+	"godaddy":      {{Requests: 60, Per: time.Minute}},
+}
+
+var (
+	mu        sync.Mutex
+	overrides = make(map[string][]Rate)
+	limiters  = make(map[string]*Limiter)
+)
+
+// SetOverrides replaces the configured rates, keyed by provider instance or
+// type name; no rates means unlimited. Limiters that have not paced a request
+// yet pick them up.
+func SetOverrides(rates map[string][]Rate) {
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = make(map[string][]Rate, len(rates))
+	for name, rate := range rates {
+		overrides[name] = rate
+	}
+}
+
+// For returns the limiter shared by the clients of the provider instance
+// called name, of type providerType
+func For(name, providerType string) *Limiter {
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := limiters[name]; ok {
+		return l
+	}
+	l := &Limiter{name: name, providerType: providerType, now: time.Now}
+	limiters[name] = l
+	return l
+}
+
+// ratesFor returns the rates of a provider instance
+func ratesFor(name, providerType string) []Rate {
+	mu.Lock()
+	defer mu.Unlock()
+	if rates, ok := overrides[name]; ok {
+		return rates
+	}
+	if rates, ok := overrides[providerType]; ok {
+		return rates
+	}
+	return Defaults[providerType]
+}
+
+// bucket is a token bucket that holds up to a rate's number of requests and
+// refills at that rate
+type bucket struct {
+	rate     Rate
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+// take takes a token at now and returns how long until it is available
+func (b *bucket) take(now time.Time) time.Duration {
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if capacity := float64(b.rate.Requests); b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.last = now
+	b.tokens--
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// Limiter paces requests so that none of its rates is exceeded, with a token
+// bucket per rate. A nil Limiter does not limit.
+type Limiter struct {
+	name         string
+	providerType string
+	now          func() time.Time
+
+	once    sync.Once
+	mu      sync.Mutex
+	buckets []*bucket
+}
+
+// NewLimiter returns a limiter for rates that is not shared, or nil if all
+// rates are unlimited
+func NewLimiter(rates ...Rate) *Limiter {
+	l := &Limiter{now: time.Now}
+	l.once.Do(func() { l.init(rates) })
+	if len(l.buckets) == 0 {
+		return nil
+	}
+	return l
+}
+
+// init fills a bucket for each limited rate
+func (l *Limiter) init(rates []Rate) {
+	now := l.now()
+	for _, rate := range rates {
+		if rate.Unlimited() {
+			continue
+		}
+		l.buckets = append(l.buckets, &bucket{
+			rate:     rate,
+			interval: rate.Per / time.Duration(rate.Requests),
+			tokens:   float64(rate.Requests),
+			last:     now,
+		})
+	}
+}
+
+// Rates returns the rates the limiter paces requests at; none if unlimited
+func (l *Limiter) Rates() []Rate {
+	if l == nil {
+		return nil
+	}
+	l.once.Do(func() { l.init(ratesFor(l.name, l.providerType)) })
+	rates := make([]Rate, len(l.buckets))
+	for i, b := range l.buckets {
+		rates[i] = b.rate
+	}
+	return rates
+}
+
+// Wait blocks until a request may be made, or until ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if len(l.Rates()) == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	// Take a token from every bucket now, even if it is only available later,
+	// so that concurrent callers queue up behind each other; the request waits
+	// for the bucket that refills last
+	var delay time.Duration
+	for _, b := range l.buckets {
+		if wait := b.take(now); wait > delay {
+			delay = wait
+		}
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Return the tokens that were not used
+		l.mu.Lock()
+		for _, b := range l.buckets {
+			b.tokens++
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
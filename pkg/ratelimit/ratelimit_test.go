@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RateLimitTestSuite tests rates and limiters
+type RateLimitTestSuite struct {
+	suite.Suite
+}
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+func (s *RateLimitTestSuite) TearDownTest() {
+	SetOverrides(nil)
+	mu.Lock()
+	limiters = make(map[string]*Limiter)
+	mu.Unlock()
+}
+
+func (s *RateLimitTestSuite) TestParseRate() {
+	cases := map[string]Rate{
+		"50/min":    {Requests: 50, Per: time.Minute},
+		" 5 / s ":   {Requests: 5, Per: time.Second},
+		"1000/hour": {Requests: 1000, Per: time.Hour},
+		"1200/5m":   {Requests: 1200, Per: 5 * time.Minute},
+		"8000/day":  {Requests: 8000, Per: 24 * time.Hour},
+		"unlimited": {},
+		"0":         {},
+	}
+	for value, want := range cases {
+		rate, err := ParseRate(value)
+		s.Require().NoError(err, value)
+		s.Require().Equal(want, rate, value)
+	}
+	s.Require().Equal("50/min", Rate{Requests: 50, Per: time.Minute}.String())
+	s.Require().Equal("1200/5m0s", Rate{Requests: 1200, Per: 5 * time.Minute}.String())
+	s.Require().Equal("8000/day", Rate{Requests: 8000, Per: 24 * time.Hour}.String())
+
+	for _, value := range []string{"50", "-1/min", "ten/min", "5/fortnight", "5/-1s"} {
+		_, err := ParseRate(value)
+		s.Require().Error(err, value)
+	}
+}
+
+func (s *RateLimitTestSuite) TestParseRates() {
+	rates, err := ParseRates("50/min, 700/h,8000/day")
+	s.Require().NoError(err)
+	s.Require().Equal(Defaults["namecheap"], rates)
+	s.Require().Equal("50/min,700/h,8000/day", FormatRates(rates))
+
+	rates, err = ParseRates("unlimited")
+	s.Require().NoError(err)
+	s.Require().Empty(rates)
+	s.Require().Equal("unlimited", FormatRates(rates))
+
+	_, err = ParseRates("50/min,fast")
+	s.Require().Error(err)
+}
+
+func (s *RateLimitTestSuite) TestLimiter_PacesAfterBurst() {
+	limiter := NewLimiter(Rate{Requests: 2, Per: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+	s.Require().NoError(limiter.Wait(ctx))
+	s.Require().NoError(limiter.Wait(ctx))
+	s.Require().Less(time.Since(start), 40*time.Millisecond, "the bucket starts full")
+
+	s.Require().NoError(limiter.Wait(ctx))
+	s.Require().GreaterOrEqual(time.Since(start), 40*time.Millisecond, "the third request waits for a token")
+}
+
+func (s *RateLimitTestSuite) TestLimiter_Canceled() {
+	limiter := NewLimiter(Rate{Requests: 1, Per: time.Hour})
+	s.Require().NoError(limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s.Require().ErrorIs(limiter.Wait(ctx), context.DeadlineExceeded)
+	s.Require().InDelta(0, limiter.buckets[0].tokens, 0.01, "the token of a canceled wait is returned")
+
+	var unlimited *Limiter
+	s.Require().NoError(unlimited.Wait(context.Background()))
+	s.Require().Nil(NewLimiter(Rate{}))
+	s.Require().Nil(NewLimiter())
+}
+
+func (s *RateLimitTestSuite) TestLimiter_SlowestRateWins() {
+	// The per-second rate allows a burst of 10, but the second rate only 2
+	limiter := NewLimiter(Rate{Requests: 10, Per: time.Second}, Rate{Requests: 2, Per: time.Hour})
+	ctx := context.Background()
+	s.Require().NoError(limiter.Wait(ctx))
+	s.Require().NoError(limiter.Wait(ctx))
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	s.Require().ErrorIs(limiter.Wait(ctx), context.DeadlineExceeded, "the third request waits for the hourly bucket")
+	for _, b := range limiter.buckets {
+		s.Require().LessOrEqual(b.tokens, float64(b.rate.Requests))
+	}
+	s.Require().InDelta(0, limiter.buckets[1].tokens, 0.01)
+}
+
+func (s *RateLimitTestSuite) TestFor_SharedWithOverrides() {
+	s.Require().Same(For("namecheap:alice", "namecheap"), For("namecheap:alice", "namecheap"))
+	s.Require().Equal(Defaults["namecheap"], For("namecheap:alice", "namecheap").Rates())
+	s.Require().Len(Defaults["namecheap"], 3, "namecheap caps calls per minute, hour and day")
+
+	SetOverrides(map[string][]Rate{
+		"namecheap": {{Requests: 20, Per: time.Minute}},
+		"cf-work":   nil,
+	})
+	s.Require().Equal([]Rate{{Requests: 20, Per: time.Minute}}, For("namecheap:bob", "namecheap").Rates(), "types can be overridden")
+	s.Require().Empty(For("cf-work", "cloudflare").Rates(), "instances can be overridden")
+	s.Require().Equal(Defaults["cloudflare"], For("cf-home", "cloudflare").Rates())
+	s.Require().Empty(For("my-api", "rest").Rates(), "types without a default are not paced")
+}
@@ -0,0 +1,84 @@
+// Package render writes command results as tables, CSV, JSON or YAML, so every
+// command supports the same output formats and column selection.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format a command prints its result in
+type Format string
+
+const (
+	// FormatTable is the default human-readable output
+	FormatTable Format = "table"
+	// FormatJSON prints the result as an indented JSON document
+	FormatJSON Format = "json"
+	// FormatYAML prints the result as a YAML document
+	FormatYAML Format = "yaml"
+	// FormatCSV prints the rows of a table as comma-separated values
+	FormatCSV Format = "csv"
+)
+
+// ParseFormat parses the value of the --output flag
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV:
+		return format, nil
+	case "":
+		return FormatTable, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q (must be table, json, yaml or csv)", value)
+	}
+}
+
+// Structured reports whether the format is machine-readable. Commands print
+// nothing but the result in structured formats.
+func (f Format) Structured() bool {
+	return f != FormatTable
+}
+
+// Encode writes v as JSON or YAML. YAML documents use the JSON field names, so
+// both formats share the same schema.
+func Encode(w io.Writer, format Format, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(json.RawMessage(data))
+	case FormatYAML:
+		// JSON is valid YAML; parse it into a node tree to keep the field
+		// order, then drop the flow style so it is written as block YAML
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		resetStyle(&node)
+
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("output format %q is not a document format", format)
+	}
+}
+
+// resetStyle clears the style of a node and its children
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RenderTestSuite is a test suite for output formatting
+type RenderTestSuite struct {
+	suite.Suite
+}
+
+// TestRenderSuite runs the render test suite
+func TestRenderSuite(t *testing.T) {
+	suite.Run(t, new(RenderTestSuite))
+}
+
+type outputDoc struct {
+	Name    string   `json:"name"`
+	ID      string   `json:"id"`
+	Enabled bool     `json:"enabled"`
+	Tags    []string `json:"tags"`
+}
+
+func (s *RenderTestSuite) TestParseFormat() {
+	for value, expected := range map[string]Format{
+		"":      FormatTable,
+		"table": FormatTable,
+		"json":  FormatJSON,
+		"yaml":  FormatYAML,
+		"csv":   FormatCSV,
+	} {
+		format, err := ParseFormat(value)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, format)
+	}
+
+	_, err := ParseFormat("xml")
+	s.Require().ErrorContains(err, "invalid output format")
+
+	s.Require().False(FormatTable.Structured())
+	s.Require().True(FormatJSON.Structured())
+	s.Require().True(FormatYAML.Structured())
+	s.Require().True(FormatCSV.Structured())
+}
+
+func (s *RenderTestSuite) TestEncode_JSON() {
+	var buf bytes.Buffer
+	err := Encode(&buf, FormatJSON, outputDoc{Name: "example.com", ID: "42", Tags: []string{}})
+	s.Require().NoError(err)
+	s.Require().Equal(`{
+  "name": "example.com",
+  "id": "42",
+  "enabled": false,
+  "tags": []
+}
+`, buf.String())
+}
+
+func (s *RenderTestSuite) TestEncode_YAML() {
+	var buf bytes.Buffer
+	err := Encode(&buf, FormatYAML, outputDoc{Name: "example.com", ID: "42", Enabled: true, Tags: []string{"a", "b: c"}})
+	s.Require().NoError(err)
+
+	// Field order and names follow the JSON schema; strings that would read as
+	// other types stay quoted
+	s.Require().Equal(`name: example.com
+id: "42"
+enabled: true
+tags:
+  - a
+  - 'b: c'
+`, buf.String())
+}
+
+func (s *RenderTestSuite) TestEncode_Table() {
+	var buf bytes.Buffer
+	s.Require().Error(Encode(&buf, FormatTable, outputDoc{}))
+}
+
+func newTestTable() *Table {
+	table := NewTable("HOSTNAME", "TYPE", "VALUE", "MX_PREF")
+	table.AddRow("@", "MX", "mx1.example.com", "10")
+	table.AddRow("www", "TXT", `v=spf1 "a,b"`)
+	return table
+}
+
+func (s *RenderTestSuite) TestRender_Table() {
+	var buf bytes.Buffer
+	s.Require().NoError(Renderer{Format: FormatTable}.Render(&buf, newTestTable(), nil))
+	s.Require().Equal(`HOSTNAME  TYPE  VALUE            MX_PREF
+@         MX    mx1.example.com  10
+www       TXT   v=spf1 "a,b"     
+`, buf.String())
+}
+
+func (s *RenderTestSuite) TestRender_CSVWithColumns() {
+	var buf bytes.Buffer
+	renderer := Renderer{Format: FormatCSV, Columns: []string{"value", "mx-pref"}}
+	s.Require().NoError(renderer.Render(&buf, newTestTable(), nil))
+	s.Require().Equal("value,mx_pref\nmx1.example.com,10\n\"v=spf1 \"\"a,b\"\"\",\n", buf.String())
+}
+
+func (s *RenderTestSuite) TestRender_DocumentIgnoresTable() {
+	var buf bytes.Buffer
+	renderer := Renderer{Format: FormatJSON, Columns: []string{"value"}}
+	s.Require().NoError(renderer.Render(&buf, newTestTable(), []string{"a"}))
+	s.Require().Equal("[\n  \"a\"\n]\n", buf.String())
+}
+
+func (s *RenderTestSuite) TestSelect_UnknownColumn() {
+	_, err := newTestTable().Select([]string{"ttl"})
+	s.Require().ErrorContains(err, `unknown column "ttl" (available: hostname, type, value, mx_pref)`)
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table is a result with named columns
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// NewTable creates an empty table with the given column headers
+func NewTable(columns ...string) *Table {
+	return &Table{Columns: columns}
+}
+
+// AddRow appends a row; missing cells are left empty
+func (t *Table) AddRow(cells ...string) {
+	row := make([]string, len(t.Columns))
+	copy(row, cells)
+	t.Rows = append(t.Rows, row)
+}
+
+// Select returns a table with only the given columns, in the given order.
+// Column names match headers case-insensitively, with "-" matching "_".
+func (t *Table) Select(columns []string) (*Table, error) {
+	if len(columns) == 0 {
+		return t, nil
+	}
+
+	index := make(map[string]int, len(t.Columns))
+	for i, c := range t.Columns {
+		index[columnKey(c)] = i
+	}
+
+	selected := make([]int, 0, len(columns))
+	out := &Table{}
+	for _, c := range columns {
+		i, ok := index[columnKey(c)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", c, strings.ToLower(strings.Join(t.Columns, ", ")))
+		}
+		selected = append(selected, i)
+		out.Columns = append(out.Columns, t.Columns[i])
+	}
+
+	for _, row := range t.Rows {
+		cells := make([]string, len(selected))
+		for j, i := range selected {
+			cells[j] = row[i]
+		}
+		out.Rows = append(out.Rows, cells)
+	}
+	return out, nil
+}
+
+func columnKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
+
+// WriteText writes the table with aligned columns
+func (t *Table) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Columns, "\t"))
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the table as CSV with a header row of lower-case column names
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = strings.ToLower(c)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// Renderer writes command results in the selected format
+type Renderer struct {
+	Format Format
+	// Columns selects and orders the columns of tables and CSV; empty keeps all
+	Columns []string
+}
+
+// Render writes a result: tables and CSV are written from table, JSON and YAML
+// documents from v
+func (r Renderer) Render(w io.Writer, table *Table, v interface{}) error {
+	switch r.Format {
+	case FormatJSON, FormatYAML:
+		return Encode(w, r.Format, v)
+	}
+
+	selected, err := table.Select(r.Columns)
+	if err != nil {
+		return err
+	}
+	if r.Format == FormatCSV {
+		return selected.WriteCSV(w)
+	}
+	return selected.WriteText(w)
+}
@@ -0,0 +1,104 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runCommand executes an external secret manager CLI and returns its trimmed stdout.
+// It is a variable so tests can replace it.
+var runCommand = func(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s CLI not found in PATH", name)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+func init() {
+	for _, b := range []Backend{vaultBackend{}, onePasswordBackend{}, sopsBackend{}, envBackend{}} {
+		_ = Register(b)
+	}
+}
+
+// splitPathField splits "path#field" references
+func splitPathField(ref, scheme string) (string, string, error) {
+	path, field, ok := strings.Cut(strings.TrimPrefix(ref, scheme+":"), "#")
+	if !ok || path == "" || field == "" {
+		return "", "", fmt.Errorf("invalid reference %q, expected %s:<path>#<field>", ref, scheme)
+	}
+	return path, field, nil
+}
+
+// vaultBackend resolves "vault:<path>#<field>" using the HashiCorp Vault CLI.
+// VAULT_ADDR and VAULT_TOKEN are taken from the environment.
+type vaultBackend struct{}
+
+func (vaultBackend) Scheme() string { return "vault" }
+
+func (vaultBackend) Resolve(ref string) (string, error) {
+	path, field, err := splitPathField(ref, "vault")
+	if err != nil {
+		return "", err
+	}
+	return runCommand("vault", "kv", "get", "-field="+field, path)
+}
+
+// onePasswordBackend resolves "op://<vault>/<item>/<field>" using the 1Password CLI
+type onePasswordBackend struct{}
+
+func (onePasswordBackend) Scheme() string { return "op" }
+
+func (onePasswordBackend) Resolve(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "op://") || strings.Count(ref, "/") < 4 {
+		return "", fmt.Errorf("invalid reference %q, expected op://<vault>/<item>/<field>", ref)
+	}
+	return runCommand("op", "read", "--no-newline", ref)
+}
+
+// sopsBackend resolves "sops:<file>#<key.path>" by decrypting a SOPS-encrypted file
+type sopsBackend struct{}
+
+func (sopsBackend) Scheme() string { return "sops" }
+
+func (sopsBackend) Resolve(ref string) (string, error) {
+	file, key, err := splitPathField(ref, "sops")
+	if err != nil {
+		return "", err
+	}
+
+	var extract strings.Builder
+	for _, part := range strings.Split(key, ".") {
+		extract.WriteString(`["` + part + `"]`)
+	}
+
+	return runCommand("sops", "--decrypt", "--extract", extract.String(), file)
+}
+
+// envBackend resolves "env:<NAME>" from the process environment
+type envBackend struct{}
+
+func (envBackend) Scheme() string { return "env" }
+
+func (envBackend) Resolve(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env:")
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,101 @@
+// Package secret resolves credential references such as
+// "vault:secret/dns#api_key" or "op://vault/item/field" through pluggable
+// secret backends, so credentials do not have to be stored in the config file.
+package secret
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Backend resolves references for a single scheme
+type Backend interface {
+	// Scheme returns the reference prefix handled by the backend, without the colon (e.g. "vault")
+	Scheme() string
+	// Resolve returns the secret value for a full reference (including the scheme)
+	Resolve(ref string) (string, error)
+}
+
+var (
+	backends     = make(map[string]Backend)
+	backendsLock sync.RWMutex
+)
+
+// Register registers a secret backend
+func Register(backend Backend) error {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	scheme := backend.Scheme()
+	if scheme == "" {
+		return fmt.Errorf("secret backend scheme cannot be empty")
+	}
+
+	if _, exists := backends[scheme]; exists {
+		return fmt.Errorf("secret backend %s is already registered", scheme)
+	}
+
+	backends[scheme] = backend
+	return nil
+}
+
+// Unregister removes a secret backend (mainly for testing)
+func Unregister(scheme string) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	delete(backends, scheme)
+}
+
+// Schemes returns the registered schemes in alphabetical order
+func Schemes() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	schemes := make([]string, 0, len(backends))
+	for scheme := range backends {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// lookup returns the backend responsible for value, or nil if value is not a reference
+func lookup(value string) Backend {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil
+	}
+
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	return backends[scheme]
+}
+
+// IsReference reports whether value refers to a registered secret backend
+func IsReference(value string) bool {
+	return lookup(value) != nil
+}
+
+// Resolve returns the secret referenced by value. Values that are not
+// references are returned unchanged.
+func Resolve(value string) (string, error) {
+	backend := lookup(value)
+	if backend == nil {
+		return value, nil
+	}
+
+	resolved, err := backend.Resolve(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", backend.Scheme(), err)
+	}
+	if resolved == "" {
+		return "", fmt.Errorf("%s secret %s is empty", backend.Scheme(), value)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,111 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SecretTestSuite is a test suite for secret reference resolution
+type SecretTestSuite struct {
+	suite.Suite
+	calls    [][]string
+	original func(name string, args ...string) (string, error)
+}
+
+// TestSecretSuite runs the secret test suite
+func TestSecretSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
+
+func (s *SecretTestSuite) SetupTest() {
+	s.calls = nil
+	s.original = runCommand
+	runCommand = func(name string, args ...string) (string, error) {
+		s.calls = append(s.calls, append([]string{name}, args...))
+		return "resolved-" + name, nil
+	}
+}
+
+func (s *SecretTestSuite) TearDownTest() {
+	runCommand = s.original
+}
+
+func (s *SecretTestSuite) TestResolve_PlainValueUnchanged() {
+	for _, value := range []string{"", "abcdef123456", "unknown:thing"} {
+		resolved, err := Resolve(value)
+		s.Require().NoError(err)
+		s.Require().Equal(value, resolved)
+		s.Require().False(IsReference(value))
+	}
+	s.Require().Empty(s.calls)
+}
+
+func (s *SecretTestSuite) TestResolve_Vault() {
+	resolved, err := Resolve("vault:secret/dns#api_key")
+	s.Require().NoError(err)
+	s.Require().Equal("resolved-vault", resolved)
+	s.Require().Equal([]string{"vault", "kv", "get", "-field=api_key", "secret/dns"}, s.calls[0])
+}
+
+func (s *SecretTestSuite) TestResolve_VaultMissingField() {
+	_, err := Resolve("vault:secret/dns")
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "expected vault:<path>#<field>")
+}
+
+func (s *SecretTestSuite) TestResolve_OnePassword() {
+	resolved, err := Resolve("op://Private/Namecheap/api key")
+	s.Require().NoError(err)
+	s.Require().Equal("resolved-op", resolved)
+	s.Require().Equal([]string{"op", "read", "--no-newline", "op://Private/Namecheap/api key"}, s.calls[0])
+
+	_, err = Resolve("op://Private")
+	s.Require().Error(err)
+}
+
+func (s *SecretTestSuite) TestResolve_Sops() {
+	_, err := Resolve("sops:secrets.enc.yaml#dns.api_key")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"sops", "--decrypt", "--extract", `["dns"]["api_key"]`, "secrets.enc.yaml"}, s.calls[0])
+}
+
+func (s *SecretTestSuite) TestResolve_Env() {
+	s.T().Setenv("ZONEKIT_TEST_SECRET", "from-env")
+
+	resolved, err := Resolve("env:ZONEKIT_TEST_SECRET")
+	s.Require().NoError(err)
+	s.Require().Equal("from-env", resolved)
+
+	_, err = Resolve("env:ZONEKIT_TEST_SECRET_UNSET")
+	s.Require().Error(err)
+}
+
+func (s *SecretTestSuite) TestResolve_BackendError() {
+	runCommand = func(name string, args ...string) (string, error) {
+		return "", fmt.Errorf("%s: permission denied", name)
+	}
+
+	_, err := Resolve("vault:secret/dns#api_key")
+	s.Require().Error(err)
+	s.Require().True(strings.HasPrefix(err.Error(), "failed to resolve vault secret"))
+}
+
+type staticBackend struct{}
+
+func (staticBackend) Scheme() string                     { return "static" }
+func (staticBackend) Resolve(ref string) (string, error) { return "value", nil }
+
+func (s *SecretTestSuite) TestRegister_CustomBackend() {
+	s.Require().NoError(Register(staticBackend{}))
+	defer Unregister("static")
+
+	s.Require().Error(Register(staticBackend{}))
+	s.Require().Contains(Schemes(), "static")
+
+	resolved, err := Resolve("static:anything")
+	s.Require().NoError(err)
+	s.Require().Equal("value", resolved)
+}
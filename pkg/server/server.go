@@ -0,0 +1,373 @@
+// Package server serves zonekit's REST API over HTTP, so scripts and other
+// tools can read and edit the records of the served domains without running
+// the CLI. Every API request needs the server's token as a bearer token.
+//
+// With the UI enabled the server also serves a small single-page web UI at /
+// that lists domains, records, service setups and change history and makes
+// simple record edits through the same API.
+//
+//	GET    /api/domains                        the served domains
+//	GET    /api/domains/{domain}/records       the domain's records
+//	POST   /api/domains/{domain}/records       add a record
+//	DELETE /api/domains/{domain}/records       delete the record in the body
+//	GET    /api/domains/{domain}/services      service integrations and whether they are set up
+//	GET    /api/domains/{domain}/history       applied changes, newest first
+package server
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/history"
+	"zonekit/pkg/plugin/service"
+)
+
+// maxBodySize bounds request bodies, which hold a single record
+const maxBodySize = 64 << 10
+
+//go:embed ui/index.html
+var indexHTML []byte
+
+// RecordService reads and edits the records of a zone
+type RecordService interface {
+	GetRecords(domainName string) ([]dnsrecord.Record, error)
+	SetRecords(domainName string, records []dnsrecord.Record) error
+	AddRecordWithMode(domainName string, record dnsrecord.Record, mode dns.AddMode) (bool, error)
+}
+
+// Config describes what a Server serves
+type Config struct {
+	// Token is required as a bearer token on every API request
+	Token string
+	// ReadOnly rejects requests that change records
+	ReadOnly bool
+	// UI serves the web UI at /
+	UI bool
+
+	// Domains are the domains that can be read and edited
+	Domains []string
+	// NewService returns the record service for one request. A new service per
+	// request keeps per-invocation record caches from serving stale zones.
+	NewService func() RecordService
+	// Services are the service integrations checked for each domain
+	Services map[string]*service.Config
+	// History is the change history shown per domain; nil shows none
+	History *history.Store
+
+	// Log receives a line per change and per failed request; nil discards them
+	Log io.Writer
+}
+
+// Server serves the API and the UI
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+
+	// mu serializes writes, as each replaces a zone's records
+	mu sync.Mutex
+}
+
+// New creates a server
+func New(config Config) *Server {
+	if config.Log == nil {
+		config.Log = io.Discard
+	}
+	s := &Server{config: config, mux: http.NewServeMux()}
+
+	s.mux.Handle("GET /api/domains", s.authorized(s.listDomains))
+	s.mux.Handle("GET /api/domains/{domain}/records", s.authorized(s.domain(s.listRecords)))
+	s.mux.Handle("POST /api/domains/{domain}/records", s.authorized(s.writable(s.domain(s.addRecord))))
+	s.mux.Handle("DELETE /api/domains/{domain}/records", s.authorized(s.writable(s.domain(s.deleteRecord))))
+	s.mux.Handle("GET /api/domains/{domain}/services", s.authorized(s.domain(s.listServices)))
+	s.mux.Handle("GET /api/domains/{domain}/history", s.authorized(s.domain(s.listHistory)))
+	if config.UI {
+		s.mux.HandleFunc("GET /{$}", serveUI)
+	}
+	return s
+}
+
+// ServeHTTP dispatches a request to its endpoint
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// domainHandler handles a request about one served domain
+type domainHandler func(w http.ResponseWriter, r *http.Request, domainName string)
+
+// recordView is the JSON form of a record
+type recordView struct {
+	ID       string   `json:"id,omitempty"`
+	HostName string   `json:"hostname"`
+	Type     string   `json:"type"`
+	Value    string   `json:"value"`
+	TTL      int      `json:"ttl,omitempty"`
+	MXPref   int      `json:"mx_pref,omitempty"`
+	Comment  string   `json:"comment,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func newRecordView(r dnsrecord.Record) recordView {
+	return recordView{
+		ID:       r.ID,
+		HostName: r.HostName,
+		Type:     r.RecordType,
+		Value:    r.Address,
+		TTL:      r.TTL,
+		MXPref:   r.MXPref,
+		Comment:  r.Comment,
+		Tags:     r.Tags,
+	}
+}
+
+// record returns the record the view describes; a missing hostname is the apex
+func (v recordView) record() dnsrecord.Record {
+	hostName := strings.TrimSpace(v.HostName)
+	if hostName == "" {
+		hostName = "@"
+	}
+	return dnsrecord.Record{
+		HostName:   hostName,
+		RecordType: strings.ToUpper(strings.TrimSpace(v.Type)),
+		Address:    strings.TrimSpace(v.Value),
+		TTL:        v.TTL,
+		MXPref:     v.MXPref,
+		Comment:    v.Comment,
+		Tags:       v.Tags,
+	}
+}
+
+// serviceView is the JSON form of a service integration checked on a domain
+type serviceView struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	SetUp       bool   `json:"set_up"`
+}
+
+// domainsView is the JSON form of the served domains
+type domainsView struct {
+	Domains  []string `json:"domains"`
+	ReadOnly bool     `json:"read_only"`
+}
+
+func (s *Server) listDomains(w http.ResponseWriter, r *http.Request) {
+	domains := append([]string{}, s.config.Domains...)
+	sort.Strings(domains)
+	writeJSON(w, http.StatusOK, domainsView{Domains: domains, ReadOnly: s.config.ReadOnly})
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request, domainName string) {
+	records, err := s.config.NewService().GetRecords(domainName)
+	if err != nil {
+		s.fail(w, r, fmt.Errorf("failed to get DNS records of %s: %w", domainName, err))
+		return
+	}
+	views := make([]recordView, 0, len(records))
+	for _, record := range records {
+		views = append(views, newRecordView(record))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) addRecord(w http.ResponseWriter, r *http.Request, domainName string) {
+	record, ok := s.decodeRecord(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Append mode keeps other values of the type, as a second MX or TXT record
+	// needs, and accepts a record that already exists
+	added, err := s.config.NewService().AddRecordWithMode(domainName, record, dns.AddAppend)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+		fmt.Fprintf(s.config.Log, "add %s %s %s %s\n", domainName, record.HostName, record.RecordType, record.Address)
+	}
+	writeJSON(w, status, newRecordView(record))
+}
+
+func (s *Server) deleteRecord(w http.ResponseWriter, r *http.Request, domainName string) {
+	target, ok := s.decodeRecord(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dnsService := s.config.NewService()
+	records, err := dnsService.GetRecords(domainName)
+	if err != nil {
+		s.fail(w, r, fmt.Errorf("failed to get DNS records of %s: %w", domainName, err))
+		return
+	}
+	kept, removed := removeRecord(records, target)
+	if !removed {
+		s.fail(w, r, errors.NewNotFound("DNS record", fmt.Sprintf("%s %s %s", target.HostName, target.RecordType, target.Address)))
+		return
+	}
+	if err := dnsService.SetRecords(domainName, kept); err != nil {
+		s.fail(w, r, fmt.Errorf("failed to delete DNS record: %w", err))
+		return
+	}
+	fmt.Fprintf(s.config.Log, "delete %s %s %s %s\n", domainName, target.HostName, target.RecordType, target.Address)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeRecord returns records without the first record holding the value of
+// target, and whether there was one
+func removeRecord(records []dnsrecord.Record, target dnsrecord.Record) ([]dnsrecord.Record, bool) {
+	for i, r := range records {
+		set := dnsrecord.RecordSet{HostName: r.HostName, RecordType: r.RecordType, Records: []dnsrecord.Record{r}}
+		if set.Remove(target) {
+			return append(records[:i:i], records[i+1:]...), true
+		}
+	}
+	return records, false
+}
+
+func (s *Server) listServices(w http.ResponseWriter, r *http.Request, domainName string) {
+	records, err := s.config.NewService().GetRecords(domainName)
+	if err != nil {
+		s.fail(w, r, fmt.Errorf("failed to get DNS records of %s: %w", domainName, err))
+		return
+	}
+	views := make([]serviceView, 0, len(s.config.Services))
+	for name, config := range s.config.Services {
+		views = append(views, serviceView{
+			Name:        name,
+			DisplayName: config.DisplayName,
+			Category:    config.Category,
+			Description: config.Description,
+			SetUp:       config.SetUp(records),
+		})
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) listHistory(w http.ResponseWriter, r *http.Request, domainName string) {
+	entries := []history.Entry{}
+	if s.config.History != nil {
+		found, err := s.config.History.Query(domainName, time.Time{})
+		if err != nil {
+			s.fail(w, r, err)
+			return
+		}
+		entries = append(entries, found...)
+	}
+	slices.Reverse(entries)
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// decodeRecord reads the record in the request body, answering the request
+// when it is invalid
+func (s *Server) decodeRecord(w http.ResponseWriter, r *http.Request) (dnsrecord.Record, bool) {
+	var view recordView
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&view); err != nil {
+		s.fail(w, r, errors.NewInvalidInput("body", err.Error()))
+		return dnsrecord.Record{}, false
+	}
+	record := view.record()
+	if record.RecordType == "" || record.Address == "" {
+		s.fail(w, r, errors.NewInvalidInput("record", "type and value are required"))
+		return dnsrecord.Record{}, false
+	}
+	return record, true
+}
+
+// authorized requires the server's token as a bearer token
+func (s *Server) authorized(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.config.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="zonekit"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// writable rejects changes on a read-only server
+func (s *Server) writable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.ReadOnly {
+			writeError(w, http.StatusForbidden, "the server is read-only")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// domain resolves the {domain} of the path to a served domain
+func (s *Server) domain(next domainHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(r.PathValue("domain"), ".")
+		for _, served := range s.config.Domains {
+			if strings.EqualFold(served, name) {
+				next(w, r, served)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("domain %s is not served", name))
+	}
+}
+
+// fail answers a request with the status matching err and logs it
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+	var invalid *errors.ErrInvalidInput
+	var notFound *errors.ErrNotFound
+	var conflict *errors.ErrConflict
+	switch {
+	case stderrors.As(err, &invalid):
+		status = http.StatusBadRequest
+	case stderrors.As(err, &notFound):
+		status = http.StatusNotFound
+	case stderrors.As(err, &conflict):
+		status = http.StatusConflict
+	}
+	fmt.Fprintf(s.config.Log, "%s %s: %v\n", r.Method, r.URL.Path, err)
+	writeError(w, status, err.Error())
+}
+
+// serveUI serves the single page of the web UI
+func serveUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	_, _ = w.Write(indexHTML)
+}
+
+// writeJSON writes v as the JSON body of a response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response; the body is {"error": message}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
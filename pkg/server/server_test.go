@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dns"
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/errors"
+	"zonekit/pkg/history"
+	"zonekit/pkg/plugin/service"
+	"zonekit/pkg/state"
+)
+
+// fakeService holds the records of its zones
+type fakeService struct {
+	zones map[string][]dnsrecord.Record
+}
+
+func (f *fakeService) GetRecords(domainName string) ([]dnsrecord.Record, error) {
+	return append([]dnsrecord.Record(nil), f.zones[domainName]...), nil
+}
+
+func (f *fakeService) SetRecords(domainName string, records []dnsrecord.Record) error {
+	f.zones[domainName] = records
+	return nil
+}
+
+func (f *fakeService) AddRecordWithMode(domainName string, record dnsrecord.Record, mode dns.AddMode) (bool, error) {
+	if record.RecordType == "CNAME" && record.HostName == "@" {
+		return false, errors.NewConflict("DNS record", "@ CNAME", "a CNAME cannot coexist with other records")
+	}
+	for _, r := range f.zones[domainName] {
+		if r.HostName == record.HostName && r.RecordType == record.RecordType && r.Address == record.Address {
+			return false, nil
+		}
+	}
+	f.zones[domainName] = append(f.zones[domainName], record)
+	return true, nil
+}
+
+// ServerTestSuite tests the REST API and the UI
+type ServerTestSuite struct {
+	suite.Suite
+	dns     *fakeService
+	history *history.Store
+	config  Config
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (s *ServerTestSuite) SetupTest() {
+	s.dns = &fakeService{zones: map[string][]dnsrecord.Record{
+		"example.com": {
+			{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+			{HostName: "@", RecordType: "MX", Address: "aspmx1.migadu.com.", MXPref: 10},
+			{HostName: "@", RecordType: "MX", Address: "aspmx2.migadu.com.", MXPref: 20},
+		},
+	}}
+	s.history = history.NewStore(state.NewFileStore(filepath.Join(s.T().TempDir(), "state.json")))
+	s.config = Config{
+		Token:      "secret",
+		UI:         true,
+		Domains:    []string{"example.com", "example.org"},
+		NewService: func() RecordService { return s.dns },
+		Services: map[string]*service.Config{
+			"migadu": {Name: "migadu", DisplayName: "Migadu", Category: "email", Verification: &service.Verification{
+				RequiredRecords: []service.VerificationCheck{{Type: "MX", Hostname: "@", Contains: "migadu.com"}},
+			}},
+			"sendgrid": {Name: "sendgrid", DisplayName: "SendGrid", Verification: &service.Verification{
+				RequiredRecords: []service.VerificationCheck{{Type: "CNAME", Hostname: "em"}},
+			}},
+		},
+		History: s.history,
+	}
+}
+
+func (s *ServerTestSuite) do(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	New(s.config).ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *ServerTestSuite) decode(rec *httptest.ResponseRecorder, v interface{}) {
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+}
+
+func (s *ServerTestSuite) TestRequiresToken() {
+	for _, header := range []string{"", "Bearer wrong", "Basic c2VjcmV0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/domains", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		New(s.config).ServeHTTP(rec, req)
+		s.Require().Equal(http.StatusUnauthorized, rec.Code, header)
+	}
+
+	// A server without a token accepts no requests
+	s.config.Token = ""
+	req := httptest.NewRequest(http.MethodGet, "/api/domains", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	New(s.config).ServeHTTP(rec, req)
+	s.Require().Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (s *ServerTestSuite) TestListDomains() {
+	rec := s.do(http.MethodGet, "/api/domains", "")
+	s.Require().Equal(http.StatusOK, rec.Code)
+	var view domainsView
+	s.decode(rec, &view)
+	s.Require().Equal([]string{"example.com", "example.org"}, view.Domains)
+	s.Require().False(view.ReadOnly)
+}
+
+func (s *ServerTestSuite) TestListRecords() {
+	rec := s.do(http.MethodGet, "/api/domains/Example.com./records", "")
+	s.Require().Equal(http.StatusOK, rec.Code)
+	var records []recordView
+	s.decode(rec, &records)
+	s.Require().Len(records, 3)
+	s.Require().Equal(recordView{HostName: "@", Type: "A", Value: "192.0.2.1", TTL: 1800}, records[0])
+
+	rec = s.do(http.MethodGet, "/api/domains/example.net/records", "")
+	s.Require().Equal(http.StatusNotFound, rec.Code)
+}
+
+func (s *ServerTestSuite) TestAddRecord() {
+	rec := s.do(http.MethodPost, "/api/domains/example.com/records", `{"hostname": "www", "type": "cname", "value": "example.com."}`)
+	s.Require().Equal(http.StatusCreated, rec.Code, rec.Body.String())
+	s.Require().Equal(dnsrecord.Record{HostName: "www", RecordType: "CNAME", Address: "example.com."}, s.dns.zones["example.com"][3])
+
+	// Adding it again changes nothing
+	rec = s.do(http.MethodPost, "/api/domains/example.com/records", `{"hostname": "www", "type": "CNAME", "value": "example.com."}`)
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Require().Len(s.dns.zones["example.com"], 4)
+
+	rec = s.do(http.MethodPost, "/api/domains/example.com/records", `{"type": "CNAME", "value": "example.net."}`)
+	s.Require().Equal(http.StatusConflict, rec.Code)
+
+	rec = s.do(http.MethodPost, "/api/domains/example.com/records", `{"hostname": "www", "type": "A"}`)
+	s.Require().Equal(http.StatusBadRequest, rec.Code)
+	rec = s.do(http.MethodPost, "/api/domains/example.com/records", `not json`)
+	s.Require().Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (s *ServerTestSuite) TestDeleteRecord() {
+	rec := s.do(http.MethodDelete, "/api/domains/example.com/records", `{"hostname": "@", "type": "MX", "value": "ASPMX1.migadu.com", "mx_pref": 10}`)
+	s.Require().Equal(http.StatusNoContent, rec.Code, rec.Body.String())
+	s.Require().Len(s.dns.zones["example.com"], 2)
+	s.Require().Equal("aspmx2.migadu.com.", s.dns.zones["example.com"][1].Address)
+
+	rec = s.do(http.MethodDelete, "/api/domains/example.com/records", `{"hostname": "@", "type": "MX", "value": "aspmx1.migadu.com.", "mx_pref": 10}`)
+	s.Require().Equal(http.StatusNotFound, rec.Code)
+}
+
+func (s *ServerTestSuite) TestReadOnly() {
+	s.config.ReadOnly = true
+	rec := s.do(http.MethodPost, "/api/domains/example.com/records", `{"hostname": "www", "type": "A", "value": "192.0.2.2"}`)
+	s.Require().Equal(http.StatusForbidden, rec.Code)
+	rec = s.do(http.MethodDelete, "/api/domains/example.com/records", `{"hostname": "@", "type": "A", "value": "192.0.2.1"}`)
+	s.Require().Equal(http.StatusForbidden, rec.Code)
+	s.Require().Len(s.dns.zones["example.com"], 3)
+
+	var view domainsView
+	s.decode(s.do(http.MethodGet, "/api/domains", ""), &view)
+	s.Require().True(view.ReadOnly)
+}
+
+func (s *ServerTestSuite) TestListServices() {
+	rec := s.do(http.MethodGet, "/api/domains/example.com/services", "")
+	s.Require().Equal(http.StatusOK, rec.Code)
+	var services []serviceView
+	s.decode(rec, &services)
+	s.Require().Equal([]serviceView{
+		{Name: "migadu", DisplayName: "Migadu", Category: "email", SetUp: true},
+		{Name: "sendgrid", DisplayName: "SendGrid"},
+	}, services)
+}
+
+func (s *ServerTestSuite) TestListHistory() {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, domainName := range []string{"example.com", "example.org", "example.com"} {
+		s.Require().NoError(s.history.Append(history.Entry{
+			Time:    at.Add(time.Duration(i) * time.Hour),
+			Domain:  domainName,
+			Changes: []history.Change{{Action: provider.ApplyCreated, HostName: "www", Type: "A", Value: "192.0.2.1"}},
+		}))
+	}
+
+	var entries []history.Entry
+	s.decode(s.do(http.MethodGet, "/api/domains/example.com/history", ""), &entries)
+	s.Require().Len(entries, 2)
+	s.Require().True(entries[0].Time.After(entries[1].Time), "newest first")
+
+	s.config.History = nil
+	s.decode(s.do(http.MethodGet, "/api/domains/example.com/history", ""), &entries)
+	s.Require().Empty(entries)
+}
+
+func (s *ServerTestSuite) TestUI() {
+	rec := httptest.NewRecorder()
+	New(s.config).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Require().Contains(rec.Header().Get("Content-Type"), "text/html")
+	s.Require().Contains(rec.Body.String(), "/api/domains")
+
+	s.config.UI = false
+	rec = httptest.NewRecorder()
+	New(s.config).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Require().Equal(http.StatusNotFound, rec.Code)
+}
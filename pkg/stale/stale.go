@@ -0,0 +1,220 @@
+// Package stale finds DNS records that point to dead infrastructure: addresses
+// nothing answers on, CNAME and MX targets that do not resolve and name servers
+// that do not answer for the zones delegated to them.
+package stale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"zonekit/pkg/dns/lookup"
+	"zonekit/pkg/dnsrecord"
+)
+
+// Status is the outcome of checking one record
+type Status string
+
+const (
+	// StatusAlive means the record's target answered
+	StatusAlive Status = "alive"
+	// StatusStale means the record's target did not answer and the record is a
+	// candidate for cleanup
+	StatusStale Status = "stale"
+	// StatusSkipped means the record was not checked, e.g. a private address
+	// that cannot be reached from here
+	StatusSkipped Status = "skipped"
+)
+
+const (
+	// DefaultTimeout bounds each probe
+	DefaultTimeout = 3 * time.Second
+	// maxConcurrentChecks bounds the number of records checked in parallel
+	maxConcurrentChecks = 10
+)
+
+// DefaultPorts are the TCP ports probed on A and AAAA targets
+var DefaultPorts = []int{80, 443}
+
+// Result is the outcome of checking one record
+type Result struct {
+	Record dnsrecord.Record
+	Status Status
+	// Reason explains the status, e.g. "no answer on ports 80, 443"
+	Reason string
+}
+
+// HostResolver resolves host names to addresses
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Checker checks the records of a zone
+type Checker struct {
+	// Resolver resolves CNAME and MX targets
+	Resolver HostResolver
+	// Dial opens TCP connections to A and AAAA targets
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// QueryNS asks a name server for the NS records of zone; a reply of any
+	// kind, including NXDOMAIN, means the server answers
+	QueryNS func(ctx context.Context, server, zone string) error
+	Ports   []int
+	Timeout time.Duration
+}
+
+// NewChecker creates a checker that probes the network
+func NewChecker() *Checker {
+	var dialer net.Dialer
+	return &Checker{
+		Resolver: net.DefaultResolver,
+		Dial:     dialer.DialContext,
+		QueryNS: func(ctx context.Context, server, zone string) error {
+			_, err := lookup.NewResolver(server).Lookup(ctx, zone, "@", dnsrecord.RecordTypeNS)
+			return err
+		},
+		Ports:   DefaultPorts,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Check checks every record of zone whose type can be probed and returns the
+// results in the order of records. Other record types are left out.
+func (c *Checker) Check(ctx context.Context, zone string, records []dnsrecord.Record) []Result {
+	var checked []dnsrecord.Record
+	for _, r := range records {
+		if Checkable(r) {
+			checked = append(checked, r)
+		}
+	}
+
+	results := make([]Result, len(checked))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentChecks)
+	for i, r := range checked {
+		wg.Add(1)
+		go func(i int, r dnsrecord.Record) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = c.check(ctx, zone, r)
+		}(i, r)
+	}
+	wg.Wait()
+	return results
+}
+
+// Checkable reports whether Check probes records of the record's type
+func Checkable(r dnsrecord.Record) bool {
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA, dnsrecord.RecordTypeCNAME,
+		dnsrecord.RecordTypeMX, dnsrecord.RecordTypeNS:
+		return true
+	}
+	return false
+}
+
+func (c *Checker) check(ctx context.Context, zone string, r dnsrecord.Record) Result {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA:
+		return c.checkAddress(ctx, r)
+	case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeMX:
+		return c.checkTarget(ctx, zone, r)
+	default:
+		return c.checkNameServer(ctx, zone, r)
+	}
+}
+
+// checkAddress probes the ports of an address. A refused connection counts as
+// an answer, since only a live host sends one.
+func (c *Checker) checkAddress(ctx context.Context, r dnsrecord.Record) Result {
+	ip := net.ParseIP(strings.TrimSpace(r.Address))
+	if ip == nil {
+		return Result{Record: r, Status: StatusStale, Reason: "not an IP address"}
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return Result{Record: r, Status: StatusSkipped, Reason: "private address"}
+	}
+
+	ports := c.Ports
+	if len(ports) == 0 {
+		ports = DefaultPorts
+	}
+	for _, port := range ports {
+		conn, err := c.Dial(ctx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)))
+		if err == nil {
+			conn.Close()
+			return Result{Record: r, Status: StatusAlive, Reason: fmt.Sprintf("port %d open", port)}
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return Result{Record: r, Status: StatusAlive, Reason: fmt.Sprintf("port %d refused", port)}
+		}
+	}
+	return Result{Record: r, Status: StatusStale, Reason: "no answer on ports " + joinPorts(ports)}
+}
+
+// checkTarget resolves the target of a CNAME or MX record
+func (c *Checker) checkTarget(ctx context.Context, zone string, r dnsrecord.Record) Result {
+	target := targetName(r.Address, zone)
+	if target == "" {
+		// A null MX (RFC 7505) declares that the domain accepts no mail
+		return Result{Record: r, Status: StatusSkipped, Reason: "null target"}
+	}
+	addrs, err := c.Resolver.LookupHost(ctx, target)
+	if err != nil || len(addrs) == 0 {
+		return Result{Record: r, Status: StatusStale, Reason: target + " does not resolve"}
+	}
+	return Result{Record: r, Status: StatusAlive, Reason: fmt.Sprintf("%s resolves to %s", target, addrs[0])}
+}
+
+// checkNameServer asks a name server for the NS records of the zone delegated to it
+func (c *Checker) checkNameServer(ctx context.Context, zone string, r dnsrecord.Record) Result {
+	server := targetName(r.Address, zone)
+	delegated := zone
+	if host := strings.TrimSuffix(r.HostName, "."); host != "" && host != "@" {
+		delegated = host + "." + zone
+	}
+	if err := c.QueryNS(ctx, server, delegated); err != nil {
+		return Result{Record: r, Status: StatusStale, Reason: fmt.Sprintf("%s does not answer for %s", server, delegated)}
+	}
+	return Result{Record: r, Status: StatusAlive, Reason: fmt.Sprintf("%s answers for %s", server, delegated)}
+}
+
+// targetName returns a record value as a host name without a trailing dot.
+// Names without a dot are relative to zone.
+func targetName(value, zone string) string {
+	name := strings.TrimSpace(value)
+	if name == "" || name == "." {
+		return ""
+	}
+	if strings.HasSuffix(name, ".") {
+		return strings.TrimSuffix(name, ".")
+	}
+	if !strings.Contains(name, ".") {
+		return name + "." + zone
+	}
+	return name
+}
+
+func joinPorts(ports []int) string {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+	parts := make([]string, len(sorted))
+	for i, p := range sorted {
+		parts[i] = strconv.Itoa(p)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,112 @@
+package stale
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// fakeResolver resolves the names it holds
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := f[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// StaleTestSuite tests the stale record checks
+type StaleTestSuite struct {
+	suite.Suite
+	dialed []string
+}
+
+func TestStaleSuite(t *testing.T) {
+	suite.Run(t, new(StaleTestSuite))
+}
+
+// newChecker returns a checker whose probes answer from fixed data: open and
+// refused map addresses to the ports that accept or refuse connections, and
+// servers lists the name servers that answer
+func (s *StaleTestSuite) newChecker(open, refused map[string]int, servers ...string) *Checker {
+	s.dialed = nil
+	answering := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		answering[server] = true
+	}
+	return &Checker{
+		Resolver: fakeResolver{"mx1.example.com": {"192.0.2.25"}, "cdn.example.net": {"198.51.100.7"}},
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			s.dialed = append(s.dialed, address)
+			host, port, _ := net.SplitHostPort(address)
+			switch port {
+			case fmt.Sprint(open[host]):
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			case fmt.Sprint(refused[host]):
+				return nil, &net.OpError{Op: "dial", Net: network, Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+			}
+			return nil, context.DeadlineExceeded
+		},
+		QueryNS: func(ctx context.Context, server, zone string) error {
+			if answering[server] {
+				return nil
+			}
+			return fmt.Errorf("i/o timeout")
+		},
+		Ports:   []int{443, 80},
+		Timeout: time.Second,
+	}
+}
+
+func (s *StaleTestSuite) TestCheck_Addresses() {
+	checker := s.newChecker(map[string]int{"192.0.2.1": 443}, map[string]int{"2001:db8::1": 80})
+	results := checker.Check(context.Background(), "example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "v6", RecordType: "AAAA", Address: "2001:db8::1"},
+		{HostName: "old", RecordType: "A", Address: "192.0.2.99"},
+		{HostName: "lan", RecordType: "A", Address: "10.0.0.5"},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all"},
+	})
+
+	s.Require().Len(results, 4)
+	s.Require().Equal(StatusAlive, results[0].Status)
+	s.Require().Equal("port 443 open", results[0].Reason)
+	s.Require().Equal(StatusAlive, results[1].Status)
+	s.Require().Equal("port 80 refused", results[1].Reason)
+	s.Require().Equal(StatusStale, results[2].Status)
+	s.Require().Equal("no answer on ports 80, 443", results[2].Reason)
+	s.Require().Equal(StatusSkipped, results[3].Status)
+	s.Require().NotContains(s.dialed, "10.0.0.5:80")
+}
+
+func (s *StaleTestSuite) TestCheck_TargetsAndNameServers() {
+	checker := s.newChecker(nil, nil, "ns1.example.net")
+	results := checker.Check(context.Background(), "example.com", []dnsrecord.Record{
+		{HostName: "@", RecordType: "MX", Address: "mx1", MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.com.", MXPref: 20},
+		{HostName: "www", RecordType: "CNAME", Address: "cdn.example.net."},
+		{HostName: "blog", RecordType: "CNAME", Address: "gone.example.org."},
+		{HostName: "@", RecordType: "MX", Address: "."},
+		{HostName: "dev", RecordType: "NS", Address: "ns1.example.net."},
+		{HostName: "dev", RecordType: "NS", Address: "ns2.example.net."},
+	})
+
+	statuses := make([]Status, len(results))
+	for i, r := range results {
+		statuses[i] = r.Status
+	}
+	s.Require().Equal([]Status{StatusAlive, StatusStale, StatusAlive, StatusStale, StatusSkipped, StatusAlive, StatusStale}, statuses)
+	s.Require().Equal("mx1.example.com resolves to 192.0.2.25", results[0].Reason)
+	s.Require().Equal("gone.example.org does not resolve", results[3].Reason)
+	s.Require().Equal("ns2.example.net does not answer for dev.example.com", results[6].Reason)
+}
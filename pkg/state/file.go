@@ -0,0 +1,232 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fileItem is an item as written in the JSON file
+type fileItem struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// FileStore keeps all buckets in a single JSON file. The file is read on every
+// call and replaced atomically on every write, so concurrent zonekit processes
+// see each other's writes but the last writer wins.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewFileStore creates a store backed by the JSON file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path, now: time.Now}
+}
+
+// load reads all buckets; a missing file is an empty store
+func (s *FileStore) load() (map[string]map[string]fileItem, error) {
+	buckets := make(map[string]map[string]fileItem)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return buckets, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	return buckets, nil
+}
+
+// save replaces the file with the given buckets, leaving out empty ones
+func (s *FileStore) save(buckets map[string]map[string]fileItem) error {
+	for name, items := range buckets {
+		if len(items) == 0 {
+			delete(buckets, name)
+		}
+	}
+
+	data, err := json.MarshalIndent(buckets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// update loads the buckets, applies fn and saves the result
+func (s *FileStore) update(fn func(buckets map[string]map[string]fileItem)) error {
+	buckets, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(buckets)
+	return s.save(buckets)
+}
+
+// Get decodes the value of a key into value and reports whether it exists
+func (s *FileStore) Get(bucket, key string, value any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	item, ok := buckets[bucket][key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(item.Value, value); err != nil {
+		return false, fmt.Errorf("failed to decode state value %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Put stores value under a key, replacing any previous value
+func (s *FileStore) Put(bucket, key string, value any) error {
+	item, err := marshalItem(key, value, s.now())
+	if err != nil {
+		return err
+	}
+	return s.putItems(bucket, []Item{item})
+}
+
+// putItems stores items with their own timestamps
+func (s *FileStore) putItems(bucket string, items []Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(buckets map[string]map[string]fileItem) {
+		if buckets[bucket] == nil {
+			buckets[bucket] = make(map[string]fileItem)
+		}
+		for _, item := range items {
+			buckets[bucket][item.Key] = fileItem{UpdatedAt: item.UpdatedAt, Value: item.Value}
+		}
+	})
+}
+
+// Delete removes a key; deleting a missing key is not an error
+func (s *FileStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(buckets map[string]map[string]fileItem) {
+		delete(buckets[bucket], key)
+	})
+}
+
+// List returns the items of a bucket ordered by key
+func (s *FileStore) List(bucket string) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(buckets[bucket]))
+	for key, item := range buckets[bucket] {
+		// Values are indented within the file; return them compact
+		var value bytes.Buffer
+		if err := json.Compact(&value, item.Value); err != nil {
+			return nil, fmt.Errorf("invalid state value %s/%s: %w", bucket, key, err)
+		}
+		items = append(items, Item{Key: key, UpdatedAt: item.UpdatedAt, Value: value.Bytes()})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items, nil
+}
+
+// Buckets summarises all non-empty buckets ordered by name
+func (s *FileStore) Buckets() ([]BucketInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]BucketInfo, 0, len(buckets))
+	for name, items := range buckets {
+		if len(items) == 0 {
+			continue
+		}
+		info := BucketInfo{Name: name, Count: len(items)}
+		for _, item := range items {
+			if info.Oldest.IsZero() || item.UpdatedAt.Before(info.Oldest) {
+				info.Oldest = item.UpdatedAt
+			}
+			if item.UpdatedAt.After(info.Newest) {
+				info.Newest = item.UpdatedAt
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Prune removes the items last written before the given time from a bucket,
+// or from all buckets if bucket is empty
+func (s *FileStore) Prune(bucket string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	err := s.update(func(buckets map[string]map[string]fileItem) {
+		for name, items := range buckets {
+			if bucket != "" && name != bucket {
+				continue
+			}
+			for key, item := range items {
+				if item.UpdatedAt.Before(before) {
+					delete(items, key)
+					removed++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Backend returns BackendJSON
+func (s *FileStore) Backend() string {
+	return BackendJSON
+}
+
+// Location returns the path of the JSON file
+func (s *FileStore) Location() string {
+	return s.path
+}
+
+// Close does nothing; the file is not held open
+func (s *FileStore) Close() error {
+	return nil
+}
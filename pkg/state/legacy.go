@@ -0,0 +1,158 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// timeKeyLayout sorts lexically in time order
+const timeKeyLayout = "20060102T150405.000000000Z"
+
+// TimeKey returns a key for append-only buckets such as history, ordering items
+// by time. name disambiguates items written at the same instant.
+func TimeKey(t time.Time, name string) string {
+	return t.UTC().Format(timeKeyLayout) + "/" + name
+}
+
+// legacyFile is a state file written before the store existed. read returns
+// the items of a single bucket, readBuckets those of several.
+type legacyFile struct {
+	path        string
+	bucket      string
+	read        func(path string) ([]Item, error)
+	readBuckets func(path string) (map[string][]Item, error)
+}
+
+// importLegacy moves the state files of earlier versions into the store. Each
+// file is renamed with a .migrated suffix once imported, so it is only read once.
+func importLegacy(store Store, dir string) error {
+	writer, ok := store.(itemWriter)
+	if !ok {
+		return nil
+	}
+
+	files := []legacyFile{
+		{path: filepath.Join(filepath.Dir(dir), "history.jsonl"), bucket: BucketHistory, read: readLegacyHistory},
+		{path: filepath.Join(dir, "zones.json"), bucket: BucketZones, read: readLegacyZones},
+	}
+
+	// A JSON store is imported when switching to SQLite
+	if store.Backend() == BackendSQLite {
+		files = append(files, legacyFile{path: filepath.Join(dir, "state.json"), readBuckets: readFileStore})
+	}
+
+	for _, f := range files {
+		if _, err := os.Stat(f.path); err != nil {
+			continue
+		}
+		if err := importFile(writer, f); err != nil {
+			return fmt.Errorf("failed to import %s: %w", f.path, err)
+		}
+		if err := os.Rename(f.path, f.path+".migrated"); err != nil {
+			return fmt.Errorf("failed to rename imported %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// importFile writes the items of a legacy file to the store
+func importFile(writer itemWriter, f legacyFile) error {
+	if f.readBuckets != nil {
+		buckets, err := f.readBuckets(f.path)
+		if err != nil {
+			return err
+		}
+		for bucket, items := range buckets {
+			if err := writer.putItems(bucket, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	items, err := f.read(f.path)
+	if err != nil {
+		return err
+	}
+	return writer.putItems(f.bucket, items)
+}
+
+// readFileStore reads all buckets of a JSON store
+func readFileStore(path string) (map[string][]Item, error) {
+	store := NewFileStore(path)
+	infos, err := store.Buckets()
+	if err != nil {
+		return nil, err
+	}
+	buckets := make(map[string][]Item, len(infos))
+	for _, info := range infos {
+		items, err := store.List(info.Name)
+		if err != nil {
+			return nil, err
+		}
+		buckets[info.Name] = items
+	}
+	return buckets, nil
+}
+
+// readLegacyHistory reads ~/.zonekit/history.jsonl, one change set per line
+func readLegacyHistory(path string) ([]Item, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []Item
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var entry struct {
+			Time   time.Time `json:"time"`
+			Domain string    `json:"domain"`
+		}
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry on line %d: %w", line, err)
+		}
+		items = append(items, Item{
+			Key:       TimeKey(entry.Time, fmt.Sprintf("%s#%d", entry.Domain, line)),
+			UpdatedAt: entry.Time.UTC(),
+			Value:     json.RawMessage(data),
+		})
+	}
+	return items, scanner.Err()
+}
+
+// readLegacyZones reads ~/.zonekit/state/zones.json, a map of zone versions
+func readLegacyZones(path string) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var zones map[string]json.RawMessage
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(zones))
+	for key, value := range zones {
+		var seen struct {
+			SeenAt time.Time `json:"seen_at"`
+		}
+		_ = json.Unmarshal(value, &seen)
+		items = append(items, Item{Key: key, UpdatedAt: seen.SeenAt.UTC(), Value: value})
+	}
+	return items, nil
+}
@@ -0,0 +1,188 @@
+//go:build sqlite
+
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	// Registers the sqlite3 database/sql driver; requires cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	openSQLite = func(path string) (Store, error) {
+		return OpenSQLiteStore(path)
+	}
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS items (
+	bucket     TEXT    NOT NULL,
+	key        TEXT    NOT NULL,
+	updated_at INTEGER NOT NULL,
+	value      BLOB    NOT NULL,
+	PRIMARY KEY (bucket, key)
+);
+CREATE INDEX IF NOT EXISTS items_updated_at ON items (bucket, updated_at);
+`
+
+// SQLiteStore keeps all buckets in one table of an embedded SQLite database.
+// Unlike FileStore, concurrent zonekit processes never lose each other's writes.
+type SQLiteStore struct {
+	path string
+	db   *sql.DB
+	now  func() time.Time
+}
+
+// OpenSQLiteStore opens or creates the database at path
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	dsn := "file:" + url.PathEscape(path) + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise state database %s: %w", path, err)
+	}
+	return &SQLiteStore{path: path, db: db, now: time.Now}, nil
+}
+
+// Get decodes the value of a key into value and reports whether it exists
+func (s *SQLiteStore) Get(bucket, key string, value any) (bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT value FROM items WHERE bucket = ? AND key = ?`, bucket, key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read state value %s/%s: %w", bucket, key, err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to decode state value %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Put stores value under a key, replacing any previous value
+func (s *SQLiteStore) Put(bucket, key string, value any) error {
+	item, err := marshalItem(key, value, s.now())
+	if err != nil {
+		return err
+	}
+	return s.putItems(bucket, []Item{item})
+}
+
+// putItems stores items with their own timestamps in one transaction
+func (s *SQLiteStore) putItems(bucket string, items []Item) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	// Rolling back after a commit does nothing
+	defer func() { _ = tx.Rollback() }()
+
+	for _, item := range items {
+		_, err := tx.Exec(`INSERT INTO items (bucket, key, updated_at, value) VALUES (?, ?, ?, ?)
+			ON CONFLICT (bucket, key) DO UPDATE SET updated_at = excluded.updated_at, value = excluded.value`,
+			bucket, item.Key, item.UpdatedAt.UnixNano(), []byte(item.Value))
+		if err != nil {
+			return fmt.Errorf("failed to write state value %s/%s: %w", bucket, item.Key, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a key; deleting a missing key is not an error
+func (s *SQLiteStore) Delete(bucket, key string) error {
+	if _, err := s.db.Exec(`DELETE FROM items WHERE bucket = ? AND key = ?`, bucket, key); err != nil {
+		return fmt.Errorf("failed to delete state value %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// List returns the items of a bucket ordered by key
+func (s *SQLiteStore) List(bucket string) ([]Item, error) {
+	rows, err := s.db.Query(`SELECT key, updated_at, value FROM items WHERE bucket = ? ORDER BY key`, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state bucket %s: %w", bucket, err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var (
+			item    Item
+			updated int64
+			value   []byte
+		)
+		if err := rows.Scan(&item.Key, &updated, &value); err != nil {
+			return nil, fmt.Errorf("failed to list state bucket %s: %w", bucket, err)
+		}
+		item.UpdatedAt = time.Unix(0, updated).UTC()
+		item.Value = value
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Buckets summarises all non-empty buckets ordered by name
+func (s *SQLiteStore) Buckets() ([]BucketInfo, error) {
+	rows, err := s.db.Query(`SELECT bucket, COUNT(*), MIN(updated_at), MAX(updated_at) FROM items GROUP BY bucket ORDER BY bucket`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise state: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []BucketInfo
+	for rows.Next() {
+		var (
+			info           BucketInfo
+			oldest, newest int64
+		)
+		if err := rows.Scan(&info.Name, &info.Count, &oldest, &newest); err != nil {
+			return nil, fmt.Errorf("failed to summarise state: %w", err)
+		}
+		info.Oldest = time.Unix(0, oldest).UTC()
+		info.Newest = time.Unix(0, newest).UTC()
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+// Prune removes the items last written before the given time from a bucket,
+// or from all buckets if bucket is empty
+func (s *SQLiteStore) Prune(bucket string, before time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM items WHERE (? = '' OR bucket = ?) AND updated_at < ?`,
+		bucket, bucket, before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune state: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune state: %w", err)
+	}
+	return int(removed), nil
+}
+
+// Backend returns BackendSQLite
+func (s *SQLiteStore) Backend() string {
+	return BackendSQLite
+}
+
+// Location returns the path of the database
+func (s *SQLiteStore) Location() string {
+	return s.path
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
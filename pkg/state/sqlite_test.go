@@ -0,0 +1,34 @@
+//go:build sqlite
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TestSQLiteStoreSuite runs the store test suite against the SQLite backend
+func TestSQLiteStoreSuite(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{open: func(dir string) (Store, error) {
+		return OpenSQLiteStore(filepath.Join(dir, "state.db"))
+	}})
+}
+
+func (s *StoreTestSuite) TestOpen_ImportsJSONStoreIntoSQLite() {
+	dir := s.T().TempDir()
+	s.Require().NoError(NewFileStore(filepath.Join(dir, "state.json")).Put(BucketDDNS, "example.com/@/A", "192.0.2.1"))
+
+	store, err := Open(dir)
+	s.Require().NoError(err)
+	defer store.Close()
+	s.Require().Equal(BackendSQLite, store.Backend())
+
+	var address string
+	found, err := store.Get(BucketDDNS, "example.com/@/A", &address)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Require().Equal("192.0.2.1", address)
+	s.Require().FileExists(filepath.Join(dir, "state.json.migrated"))
+}
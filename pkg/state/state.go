@@ -0,0 +1,140 @@
+// Package state keeps zonekit's local state — change history, zone versions,
+// DDNS addresses, run progress, cached domain names, shell history, record
+// notes and the index of zone snapshots — in one store, organised in buckets
+// of JSON values. The store is a JSON file by default and an embedded SQLite
+// database when zonekit is built with the sqlite tag.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Buckets used by zonekit
+const (
+	// BucketHistory holds applied DNS change sets
+	BucketHistory = "history"
+	// BucketZones holds the last seen version of each zone
+	BucketZones = "zones"
+	// BucketDDNS holds the last address written by dns ddns per record
+	BucketDDNS = "ddns"
+	// BucketJobs holds the progress of account-wide runs, for --resume
+	BucketJobs = "jobs"
+	// BucketDomains holds the cached domain names of each account
+	BucketDomains = "domains"
+	// BucketShell holds the command history of zonekit shell
+	BucketShell = "shell"
+	// BucketNotes holds the comments and tags of records, per zone, for
+	// providers that cannot store them
+	BucketNotes = "notes"
+	// BucketBackups indexes the zone snapshots kept by the backup package
+	BucketBackups = "backups"
+)
+
+// Backend names
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Item is a stored value with its key and the time it was last written
+type Item struct {
+	Key       string          `json:"key"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// BucketInfo summarises the items of a bucket
+type BucketInfo struct {
+	Name   string
+	Count  int
+	Oldest time.Time
+	Newest time.Time
+}
+
+// Store is a bucketed key-value store of JSON values
+type Store interface {
+	// Get decodes the value of a key into value and reports whether it exists
+	Get(bucket, key string, value any) (bool, error)
+	// Put stores value under a key, replacing any previous value
+	Put(bucket, key string, value any) error
+	// Delete removes a key; deleting a missing key is not an error
+	Delete(bucket, key string) error
+	// List returns the items of a bucket ordered by key
+	List(bucket string) ([]Item, error)
+	// Buckets summarises all non-empty buckets ordered by name
+	Buckets() ([]BucketInfo, error)
+	// Prune removes the items last written before the given time from a bucket,
+	// or from all buckets if bucket is empty, and returns how many were removed
+	Prune(bucket string, before time.Time) (int, error)
+
+	// Backend returns BackendJSON or BackendSQLite
+	Backend() string
+	// Location returns the path of the store
+	Location() string
+	Close() error
+}
+
+// itemWriter is implemented by stores to write items with their own timestamps,
+// as needed when importing legacy files
+type itemWriter interface {
+	putItems(bucket string, items []Item) error
+}
+
+// openSQLite opens a SQLite store; it is set when built with the sqlite tag
+var openSQLite func(path string) (Store, error)
+
+// SQLiteAvailable reports whether zonekit was built with SQLite support
+func SQLiteAvailable() bool {
+	return openSQLite != nil
+}
+
+// DefaultDir returns ~/.zonekit/state
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".zonekit", "state")
+}
+
+// Open opens the store in dir: state.db when SQLite support is built in,
+// state.json otherwise. State files written by earlier versions are imported
+// on first open and renamed with a .migrated suffix.
+func Open(dir string) (Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("state directory is unknown")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	var store Store
+	if openSQLite != nil {
+		s, err := openSQLite(filepath.Join(dir, "state.db"))
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	} else {
+		store = NewFileStore(filepath.Join(dir, "state.json"))
+	}
+
+	if err := importLegacy(store, dir); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// marshalItem encodes a value as an item written at the given time
+func marshalItem(key string, value any, at time.Time) (Item, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to marshal state value %s: %w", key, err)
+	}
+	return Item{Key: key, UpdatedAt: at.UTC(), Value: data}, nil
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// StoreTestSuite tests the behaviour every store backend shares
+type StoreTestSuite struct {
+	suite.Suite
+	open  func(dir string) (Store, error)
+	store Store
+}
+
+// TestFileStoreSuite runs the store test suite against the JSON file backend
+func TestFileStoreSuite(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{open: func(dir string) (Store, error) {
+		return NewFileStore(filepath.Join(dir, "state.json")), nil
+	}})
+}
+
+func (s *StoreTestSuite) SetupTest() {
+	store, err := s.open(s.T().TempDir())
+	s.Require().NoError(err)
+	s.store = store
+}
+
+func (s *StoreTestSuite) TearDownTest() {
+	s.Require().NoError(s.store.Close())
+}
+
+// putAt writes a value with the given timestamp
+func (s *StoreTestSuite) putAt(bucket, key string, value any, at time.Time) {
+	item, err := marshalItem(key, value, at)
+	s.Require().NoError(err)
+	s.Require().NoError(s.store.(itemWriter).putItems(bucket, []Item{item}))
+}
+
+func (s *StoreTestSuite) TestPutGetDelete() {
+	type address struct {
+		Address string `json:"address"`
+	}
+
+	var got address
+	found, err := s.store.Get(BucketDDNS, "example.com/@/A", &got)
+	s.Require().NoError(err)
+	s.Require().False(found)
+
+	s.Require().NoError(s.store.Put(BucketDDNS, "example.com/@/A", address{Address: "192.0.2.1"}))
+	s.Require().NoError(s.store.Put(BucketDDNS, "example.com/@/A", address{Address: "192.0.2.2"}))
+
+	found, err = s.store.Get(BucketDDNS, "example.com/@/A", &got)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Require().Equal("192.0.2.2", got.Address)
+
+	// Buckets are separate namespaces
+	found, err = s.store.Get(BucketZones, "example.com/@/A", &got)
+	s.Require().NoError(err)
+	s.Require().False(found)
+
+	s.Require().NoError(s.store.Delete(BucketDDNS, "example.com/@/A"))
+	s.Require().NoError(s.store.Delete(BucketDDNS, "missing"))
+	found, err = s.store.Get(BucketDDNS, "example.com/@/A", &got)
+	s.Require().NoError(err)
+	s.Require().False(found)
+}
+
+func (s *StoreTestSuite) TestListAndBuckets() {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.putAt(BucketHistory, TimeKey(day.Add(time.Hour), "b.com"), "second", day.Add(time.Hour))
+	s.putAt(BucketHistory, TimeKey(day, "a.com"), "first", day)
+	s.putAt(BucketZones, "namecheap/example.com", "abc", day.Add(2*time.Hour))
+
+	items, err := s.store.List(BucketHistory)
+	s.Require().NoError(err)
+	s.Require().Len(items, 2)
+	s.Require().Equal(json.RawMessage(`"first"`), items[0].Value)
+	s.Require().True(day.Equal(items[0].UpdatedAt))
+
+	buckets, err := s.store.Buckets()
+	s.Require().NoError(err)
+	s.Require().Equal([]BucketInfo{
+		{Name: BucketHistory, Count: 2, Oldest: day, Newest: day.Add(time.Hour)},
+		{Name: BucketZones, Count: 1, Oldest: day.Add(2 * time.Hour), Newest: day.Add(2 * time.Hour)},
+	}, buckets)
+
+	items, err = s.store.List("missing")
+	s.Require().NoError(err)
+	s.Require().Empty(items)
+}
+
+func (s *StoreTestSuite) TestPrune() {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.putAt(BucketHistory, "old", 1, day)
+	s.putAt(BucketHistory, "new", 2, day.AddDate(0, 0, 10))
+	s.putAt(BucketZones, "old", 3, day)
+
+	removed, err := s.store.Prune(BucketHistory, day.AddDate(0, 0, 5))
+	s.Require().NoError(err)
+	s.Require().Equal(1, removed)
+
+	items, err := s.store.List(BucketHistory)
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.Require().Equal("new", items[0].Key)
+
+	removed, err = s.store.Prune("", day.AddDate(0, 0, 20))
+	s.Require().NoError(err)
+	s.Require().Equal(2, removed)
+
+	buckets, err := s.store.Buckets()
+	s.Require().NoError(err)
+	s.Require().Empty(buckets)
+}
+
+func (s *StoreTestSuite) TestTimeKeyOrdersByTime() {
+	earlier := time.Date(2026, 1, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	later := time.Date(2026, 1, 1, 8, 30, 0, 0, time.UTC)
+	s.Require().Less(TimeKey(earlier, "z.com"), TimeKey(later, "a.com"))
+}
+
+func (s *StoreTestSuite) TestOpen_ImportsLegacyFiles() {
+	home := s.T().TempDir()
+	dir := filepath.Join(home, "state")
+	s.Require().NoError(os.MkdirAll(dir, 0700))
+
+	s.Require().NoError(os.WriteFile(filepath.Join(home, "history.jsonl"), []byte(
+		`{"time":"2026-01-01T10:00:00Z","domain":"example.com","provider":"namecheap","changes":[]}`+"\n\n"+
+			`{"time":"2026-01-02T10:00:00Z","domain":"example.org","provider":"namecheap","changes":[]}`+"\n"), 0600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "zones.json"), []byte(
+		`{"namecheap/example.com":{"hash":"abc","seen_at":"2026-01-03T00:00:00Z","changed_at":"2026-01-01T00:00:00Z"}}`), 0600))
+
+	store, err := Open(dir)
+	s.Require().NoError(err)
+	defer store.Close()
+
+	history, err := store.List(BucketHistory)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	s.Require().Contains(string(history[0].Value), "example.com")
+	s.Require().True(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC).Equal(history[1].UpdatedAt))
+
+	var zone struct {
+		Hash string `json:"hash"`
+	}
+	found, err := store.Get(BucketZones, "namecheap/example.com", &zone)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Require().Equal("abc", zone.Hash)
+
+	// Legacy files are imported once
+	s.Require().FileExists(filepath.Join(home, "history.jsonl.migrated"))
+	s.Require().NoFileExists(filepath.Join(home, "history.jsonl"))
+	s.Require().NoFileExists(filepath.Join(dir, "zones.json"))
+}
@@ -0,0 +1,180 @@
+// Package terraform writes the records of a zone as Terraform resource blocks,
+// so a zone managed with zonekit can be handed over to Terraform. Cloudflare
+// records are written one resource per record; Route 53 records one resource
+// per record set, as Route 53 manages them.
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// Terraform providers records can be written for
+const (
+	ProviderCloudflare = "cloudflare"
+	ProviderRoute53    = "route53"
+)
+
+// DefaultTTL is written for records without a TTL
+const DefaultTTL = 3600
+
+// Providers returns the supported Terraform providers
+func Providers() []string {
+	return []string{ProviderCloudflare, ProviderRoute53}
+}
+
+// Write writes records of domainName as resource blocks for provider, after a
+// zone_id variable the resources refer to
+func Write(w io.Writer, provider, domainName string, records []dnsrecord.Record) error {
+	var blocks []string
+	names := make(map[string]int)
+	switch provider {
+	case ProviderCloudflare:
+		for _, r := range records {
+			blocks = append(blocks, cloudflareRecord(resourceName(names, r.HostName, r.RecordType), r))
+		}
+	case ProviderRoute53:
+		for _, set := range dnsrecord.GroupRecordSets(records) {
+			blocks = append(blocks, route53Record(resourceName(names, set.HostName, set.RecordType), domainName, set))
+		}
+	default:
+		return fmt.Errorf("unsupported Terraform provider %q (must be %s)", provider, strings.Join(Providers(), " or "))
+	}
+
+	if _, err := fmt.Fprintf(w, "variable \"zone_id\" {\n  description = %s\n  type        = string\n}\n", quote("Zone ID of "+domainName)); err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if _, err := fmt.Fprintf(w, "\n%s", block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloudflareRecord returns a cloudflare_record resource. SRV records are
+// written as a data block, as Cloudflare requires.
+func cloudflareRecord(name string, r dnsrecord.Record) string {
+	attributes := [][2]string{
+		{"zone_id", "var.zone_id"},
+		{"name", quote(r.HostName)},
+		{"type", quote(strings.ToUpper(r.RecordType))},
+		{"ttl", fmt.Sprint(ttl(r.TTL))},
+	}
+
+	srv, err := dnsrecord.ParseSRV(r.Address)
+	var data string
+	switch {
+	case strings.EqualFold(r.RecordType, dnsrecord.RecordTypeSRV) && err == nil:
+		data = "\n  data {\n" + attributeLines("    ", [][2]string{
+			{"priority", fmt.Sprint(srv.Priority)},
+			{"weight", fmt.Sprint(srv.Weight)},
+			{"port", fmt.Sprint(srv.Port)},
+			{"target", quote(srv.Target)},
+		}) + "  }\n"
+	case strings.EqualFold(r.RecordType, dnsrecord.RecordTypeMX):
+		attributes = append(attributes, [2]string{"content", quote(r.Address)}, [2]string{"priority", fmt.Sprint(r.MXPref)})
+	default:
+		attributes = append(attributes, [2]string{"content", quote(r.Address)})
+	}
+	return fmt.Sprintf("resource \"cloudflare_record\" %s {\n%s%s}\n", quote(name), attributeLines("  ", attributes), data)
+}
+
+// route53Record returns an aws_route53_record resource for a record set
+func route53Record(name, domainName string, set dnsrecord.RecordSet) string {
+	values := "[\n"
+	for _, r := range set.Records {
+		value := r.Address
+		if strings.EqualFold(r.RecordType, dnsrecord.RecordTypeMX) {
+			value = fmt.Sprintf("%d %s", r.MXPref, r.Address)
+		}
+		values += "    " + quote(value) + ",\n"
+	}
+	values += "  ]"
+
+	return fmt.Sprintf("resource \"aws_route53_record\" %s {\n%s}\n", quote(name), attributeLines("  ", [][2]string{
+		{"zone_id", "var.zone_id"},
+		{"name", quote(fqdn(set.HostName, domainName))},
+		{"type", quote(set.Key().RecordType)},
+		{"ttl", fmt.Sprint(ttl(set.TTL))},
+		{"records", values},
+	}))
+}
+
+// attributeLines writes attributes one per line with their equals signs
+// aligned, as terraform fmt does
+func attributeLines(indent string, attributes [][2]string) string {
+	width := 0
+	for _, a := range attributes {
+		width = max(width, len(a[0]))
+	}
+	var sb strings.Builder
+	for _, a := range attributes {
+		fmt.Fprintf(&sb, "%s%-*s = %s\n", indent, width, a[0], a[1])
+	}
+	return sb.String()
+}
+
+// invalidName matches runs of characters replaced by an underscore in resource names
+var invalidName = regexp.MustCompile(`[^a-z0-9]+`)
+
+// resourceName returns a unique resource name for a record of host and type,
+// e.g. www_cname or apex_a_2 for the second A record at the apex
+func resourceName(names map[string]int, host, recordType string) string {
+	if host == "@" || host == "" {
+		host = "apex"
+	}
+	host = strings.ReplaceAll(host, "*", "wildcard")
+	name := strings.Trim(invalidName.ReplaceAllString(strings.ToLower(host+"_"+recordType), "_"), "_")
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "r_" + name
+	}
+
+	names[name]++
+	if n := names[name]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+// fqdn returns the fully qualified name of host in domainName
+func fqdn(host, domainName string) string {
+	if host == "@" || host == "" {
+		return domainName
+	}
+	return host + "." + domainName
+}
+
+// ttl returns seconds, or DefaultTTL if it is not set
+func ttl(seconds int) int {
+	if seconds == 0 {
+		return DefaultTTL
+	}
+	return seconds
+}
+
+// quote returns s as an HCL string literal, escaping template sequences
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{':
+			sb.WriteByte(c)
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,110 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+var records = []dnsrecord.Record{
+	{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 300},
+	{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 300},
+	{HostName: "@", RecordType: "MX", Address: "mx.example.com.", MXPref: 10},
+	{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 60 5060 sip.example.com.", TTL: 3600},
+	{HostName: "*", RecordType: "TXT", Address: `v=spf1 include:"x" ${not-a-template}`, TTL: 3600},
+}
+
+func TestWrite_Cloudflare(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, Write(&sb, ProviderCloudflare, "example.com", records))
+	require.Equal(t, `variable "zone_id" {
+  description = "Zone ID of example.com"
+  type        = string
+}
+
+resource "cloudflare_record" "apex_a" {
+  zone_id = var.zone_id
+  name    = "@"
+  type    = "A"
+  ttl     = 300
+  content = "192.0.2.1"
+}
+
+resource "cloudflare_record" "apex_a_2" {
+  zone_id = var.zone_id
+  name    = "@"
+  type    = "A"
+  ttl     = 300
+  content = "192.0.2.2"
+}
+
+resource "cloudflare_record" "apex_mx" {
+  zone_id  = var.zone_id
+  name     = "@"
+  type     = "MX"
+  ttl      = 3600
+  content  = "mx.example.com."
+  priority = 10
+}
+
+resource "cloudflare_record" "sip_tcp_srv" {
+  zone_id = var.zone_id
+  name    = "_sip._tcp"
+  type    = "SRV"
+  ttl     = 3600
+
+  data {
+    priority = 10
+    weight   = 60
+    port     = 5060
+    target   = "sip.example.com."
+  }
+}
+
+resource "cloudflare_record" "wildcard_txt" {
+  zone_id = var.zone_id
+  name    = "*"
+  type    = "TXT"
+  ttl     = 3600
+  content = "v=spf1 include:\"x\" $${not-a-template}"
+}
+`, sb.String())
+}
+
+func TestWrite_Route53GroupsRecordSets(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, Write(&sb, ProviderRoute53, "example.com", records[:3]))
+	require.Equal(t, `variable "zone_id" {
+  description = "Zone ID of example.com"
+  type        = string
+}
+
+resource "aws_route53_record" "apex_a" {
+  zone_id = var.zone_id
+  name    = "example.com"
+  type    = "A"
+  ttl     = 300
+  records = [
+    "192.0.2.1",
+    "192.0.2.2",
+  ]
+}
+
+resource "aws_route53_record" "apex_mx" {
+  zone_id = var.zone_id
+  name    = "example.com"
+  type    = "MX"
+  ttl     = 3600
+  records = [
+    "10 mx.example.com.",
+  ]
+}
+`, sb.String())
+}
+
+func TestWrite_UnsupportedProvider(t *testing.T) {
+	require.ErrorContains(t, Write(&strings.Builder{}, "gandi", "example.com", records), "unsupported Terraform provider")
+}
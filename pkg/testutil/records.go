@@ -0,0 +1,84 @@
+package testutil
+
+import (
+	"fmt"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// RecordBuilder builds a dnsrecord.Record for tests
+type RecordBuilder struct {
+	record dnsrecord.Record
+}
+
+// NewRecord starts a record with the given hostname, type and value
+func NewRecord(hostName, recordType, address string) *RecordBuilder {
+	return &RecordBuilder{record: dnsrecord.Record{
+		HostName:   hostName,
+		RecordType: recordType,
+		Address:    address,
+	}}
+}
+
+// TTL sets the record's TTL in seconds
+func (b *RecordBuilder) TTL(ttl int) *RecordBuilder {
+	b.record.TTL = ttl
+	return b
+}
+
+// MXPref sets the record's MX preference
+func (b *RecordBuilder) MXPref(pref int) *RecordBuilder {
+	b.record.MXPref = pref
+	return b
+}
+
+// ID sets the provider-assigned record ID
+func (b *RecordBuilder) ID(id string) *RecordBuilder {
+	b.record.ID = id
+	return b
+}
+
+// Build returns the record
+func (b *RecordBuilder) Build() dnsrecord.Record {
+	return b.record
+}
+
+// A returns an A record
+func A(hostName, ip string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeA, ip).Build()
+}
+
+// AAAA returns an AAAA record
+func AAAA(hostName, ip string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeAAAA, ip).Build()
+}
+
+// CNAME returns a CNAME record
+func CNAME(hostName, target string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeCNAME, target).Build()
+}
+
+// MX returns an MX record with the given preference
+func MX(hostName string, pref int, exchange string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeMX, exchange).MXPref(pref).Build()
+}
+
+// TXT returns a TXT record
+func TXT(hostName, text string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeTXT, text).Build()
+}
+
+// NS returns an NS record
+func NS(hostName, nameserver string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeNS, nameserver).Build()
+}
+
+// SRV returns an SRV record with its value in "priority weight port target" form
+func SRV(hostName string, priority, weight, port int, target string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeSRV, fmt.Sprintf("%d %d %d %s", priority, weight, port, target)).Build()
+}
+
+// CAA returns a CAA record with its value in `flags tag "value"` form
+func CAA(hostName string, flags int, tag, value string) dnsrecord.Record {
+	return NewRecord(hostName, dnsrecord.RecordTypeCAA, fmt.Sprintf("%d %s %q", flags, tag, value)).Build()
+}
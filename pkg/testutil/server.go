@@ -0,0 +1,357 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"zonekit/pkg/dns/provider/mapper"
+	"zonekit/pkg/dnsrecord"
+)
+
+// Shape describes how a provider's API lays out records in JSON
+type Shape struct {
+	// Fields names the JSON field of each record attribute
+	Fields mapper.FieldMapping
+	// ListPath is the dotted path to the record list ("" for a top-level array)
+	ListPath string
+	// ZonesPath is the dotted path to the zone list (ListPath when empty)
+	ZonesPath string
+	// Envelope holds extra top-level fields sent with every response
+	Envelope map[string]interface{}
+}
+
+// Mappings returns the mapper configuration that reads and writes this shape
+func (s Shape) Mappings() mapper.Mappings {
+	return mapper.Mappings{Request: s.Fields, Response: s.Fields, ListPath: s.ListPath}
+}
+
+// CloudflareShape returns the shape of Cloudflare-style APIs: records under
+// "result" next to "success" and "errors"
+func CloudflareShape() Shape {
+	return Shape{
+		Fields:   mapper.FieldMapping{HostName: "name", RecordType: "type", Address: "content", TTL: "ttl", MXPref: "priority", ID: "id"},
+		ListPath: "result",
+		Envelope: map[string]interface{}{"success": true, "errors": []interface{}{}},
+	}
+}
+
+// DigitalOceanShape returns the shape of DigitalOcean-style APIs: records
+// under "domain_records" and zones under "domains"
+func DigitalOceanShape() Shape {
+	return Shape{
+		Fields:    mapper.FieldMapping{HostName: "name", RecordType: "type", Address: "data", TTL: "ttl", MXPref: "priority", ID: "id"},
+		ListPath:  "domain_records",
+		ZonesPath: "domains",
+	}
+}
+
+// DataShape returns the shape of APIs that nest records under a "data"
+// object, e.g. {"data": {"records": [...]}}, and list zones under "data"
+func DataShape() Shape {
+	return Shape{
+		Fields:    mapper.FieldMapping{HostName: "name", RecordType: "type", Address: "target", TTL: "ttl_sec", MXPref: "priority", ID: "id"},
+		ListPath:  "data.records",
+		ZonesPath: "data",
+	}
+}
+
+// ArrayShape returns the shape of APIs that answer with bare arrays and use
+// the default mapper field names
+func ArrayShape() Shape {
+	fields := mapper.DefaultMappings().Response
+	fields.ID = "id"
+	return Shape{Fields: fields}
+}
+
+// Request is a request received by a FakeAPI
+type Request struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// FakeAPI is an in-memory DNS REST API served over HTTP. Zones are listed at
+// /zones, and records are read and written at /zones/{zone}/records and
+// /zones/{zone}/records/{id}, where {zone} is a zone ID or name. Requests and
+// responses use the layout of the API's Shape, so provider adapters can be
+// tested against it without a hand-written handler.
+type FakeAPI struct {
+	*httptest.Server
+
+	shape    Shape
+	mu       sync.Mutex
+	zones    []*fakeZone
+	nextID   int
+	headers  map[string]string
+	query    map[string]string
+	failures map[string]int
+	requests []Request
+}
+
+type fakeZone struct {
+	id      string
+	name    string
+	records []dnsrecord.Record
+}
+
+// NewFakeAPI starts a FakeAPI serving the given zones. Records without an ID
+// are given one. The server is closed when the test ends.
+func NewFakeAPI(t testing.TB, shape Shape, zones ...*ZoneBuilder) *FakeAPI {
+	t.Helper()
+	api := &FakeAPI{
+		shape:    shape,
+		headers:  make(map[string]string),
+		query:    make(map[string]string),
+		failures: make(map[string]int),
+	}
+	for i, z := range zones {
+		zone := &fakeZone{id: fmt.Sprintf("zone-%d", i+1), name: z.Domain()}
+		for _, r := range z.Records() {
+			zone.records = append(zone.records, api.withID(r))
+		}
+		api.zones = append(api.zones, zone)
+	}
+
+	api.Server = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// Endpoints returns the endpoint templates of the generic REST provider for
+// this API
+func (a *FakeAPI) Endpoints() map[string]string {
+	return map[string]string{
+		"list_zones":    "/zones",
+		"get_records":   "/zones/{zone_id}/records",
+		"create_record": "/zones/{zone_id}/records",
+		"update_record": "/zones/{zone_id}/records/{record_id}",
+		"delete_record": "/zones/{zone_id}/records/{record_id}",
+	}
+}
+
+// RequireHeader makes the API answer 401 to requests without the header value
+func (a *FakeAPI) RequireHeader(name, value string) *FakeAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.headers[name] = value
+	return a
+}
+
+// RequireQuery makes the API answer 401 to requests without the query
+// parameter value
+func (a *FakeAPI) RequireQuery(name, value string) *FakeAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.query[name] = value
+	return a
+}
+
+// Fail makes the API answer every request with method with status. A status
+// of 0 clears the failure.
+func (a *FakeAPI) Fail(method string, status int) *FakeAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if status == 0 {
+		delete(a.failures, method)
+	} else {
+		a.failures[method] = status
+	}
+	return a
+}
+
+// Records returns the current records of a zone
+func (a *FakeAPI) Records(domainName string) []dnsrecord.Record {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	zone := a.zone(domainName)
+	if zone == nil {
+		return nil
+	}
+	return append([]dnsrecord.Record(nil), zone.records...)
+}
+
+// Requests returns the requests received so far
+func (a *FakeAPI) Requests() []Request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Request(nil), a.requests...)
+}
+
+func (a *FakeAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var body map[string]interface{}
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			a.writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+	a.requests = append(a.requests, Request{Method: r.Method, Path: r.URL.Path, Body: body})
+
+	for name, value := range a.headers {
+		if r.Header.Get(name) != value {
+			a.writeError(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+	}
+	for name, value := range a.query {
+		if r.URL.Query().Get(name) != value {
+			a.writeError(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+	}
+	if status, ok := a.failures[r.Method]; ok {
+		a.writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 0 || parts[0] != "zones" {
+		a.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			a.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		a.listZones(w, r.URL.Query().Get("name"))
+		return
+	}
+
+	zone := a.zone(parts[1])
+	if zone == nil {
+		a.writeError(w, http.StatusNotFound, "zone not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && parts[2] == "records":
+		switch r.Method {
+		case http.MethodGet:
+			items := make([]interface{}, 0, len(zone.records))
+			for _, rec := range zone.records {
+				items = append(items, mapper.ToProviderFormat(rec, a.shape.Fields))
+			}
+			a.writeJSON(w, http.StatusOK, a.shape.ListPath, items)
+		case http.MethodPost:
+			rec, _ := mapper.FromProviderFormat(body, a.shape.Fields)
+			rec.ID = ""
+			rec = a.withID(rec)
+			zone.records = append(zone.records, rec)
+			a.writeJSON(w, http.StatusCreated, a.shape.ListPath, mapper.ToProviderFormat(rec, a.shape.Fields))
+		default:
+			a.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case len(parts) == 4 && parts[2] == "records":
+		i := zone.index(parts[3])
+		if i < 0 {
+			a.writeError(w, http.StatusNotFound, "record not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			a.writeJSON(w, http.StatusOK, a.shape.ListPath, mapper.ToProviderFormat(zone.records[i], a.shape.Fields))
+		case http.MethodPut, http.MethodPatch:
+			fields := body
+			if r.Method == http.MethodPatch {
+				fields = mapper.ToProviderFormat(zone.records[i], a.shape.Fields)
+				for k, v := range body {
+					fields[k] = v
+				}
+			}
+			rec, _ := mapper.FromProviderFormat(fields, a.shape.Fields)
+			rec.ID = zone.records[i].ID
+			zone.records[i] = rec
+			a.writeJSON(w, http.StatusOK, a.shape.ListPath, mapper.ToProviderFormat(rec, a.shape.Fields))
+		case http.MethodDelete:
+			zone.records = append(zone.records[:i], zone.records[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			a.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		a.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// listZones writes the zones, filtered by name when one is given
+func (a *FakeAPI) listZones(w http.ResponseWriter, name string) {
+	items := make([]interface{}, 0, len(a.zones))
+	for _, z := range a.zones {
+		if name != "" && !strings.EqualFold(z.name, name) {
+			continue
+		}
+		items = append(items, map[string]interface{}{"id": z.id, "name": z.name})
+	}
+
+	path := a.shape.ZonesPath
+	if path == "" {
+		path = a.shape.ListPath
+	}
+	a.writeJSON(w, http.StatusOK, path, items)
+}
+
+// zone returns the zone with the given ID or name
+func (a *FakeAPI) zone(idOrName string) *fakeZone {
+	for _, z := range a.zones {
+		if z.id == idOrName || strings.EqualFold(z.name, idOrName) {
+			return z
+		}
+	}
+	return nil
+}
+
+// withID gives a record without an ID the next free one
+func (a *FakeAPI) withID(r dnsrecord.Record) dnsrecord.Record {
+	if r.ID == "" {
+		a.nextID++
+		r.ID = fmt.Sprintf("rec-%d", a.nextID)
+	}
+	return r
+}
+
+// index returns the position of the record with the given ID, or -1
+func (z *fakeZone) index(id string) int {
+	for i, r := range z.records {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeJSON writes value nested under the dotted path, with the shape's
+// envelope fields at the top level
+func (a *FakeAPI) writeJSON(w http.ResponseWriter, status int, path string, value interface{}) {
+	if path != "" {
+		keys := strings.Split(path, ".")
+		for i := len(keys) - 1; i >= 0; i-- {
+			value = map[string]interface{}{keys[i]: value}
+		}
+	}
+	if top, ok := value.(map[string]interface{}); ok {
+		for k, v := range a.shape.Envelope {
+			top[k] = v
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a JSON error message
+func (a *FakeAPI) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": message})
+}
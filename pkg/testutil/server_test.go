@@ -0,0 +1,106 @@
+package testutil_test
+
+import (
+	"net/http"
+	"testing"
+
+	dnsprovider "zonekit/pkg/dns/provider"
+	httpprovider "zonekit/pkg/dns/provider/http"
+	"zonekit/pkg/dns/provider/rest"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newRESTProvider(api *testutil.FakeAPI, shape testutil.Shape, headers map[string]string) *rest.RESTProvider {
+	client := httpprovider.NewClient(httpprovider.ClientConfig{BaseURL: api.URL, Headers: headers})
+	return rest.NewRESTProvider("fake", client, shape.Mappings(), api.Endpoints(), nil)
+}
+
+func TestFakeAPI_Shapes(t *testing.T) {
+	shapes := map[string]testutil.Shape{
+		"cloudflare":   testutil.CloudflareShape(),
+		"digitalocean": testutil.DigitalOceanShape(),
+		"data":         testutil.DataShape(),
+		"array":        testutil.ArrayShape(),
+	}
+	for name, shape := range shapes {
+		t.Run(name, func(t *testing.T) {
+			zone := testutil.StandardZone("example.com").TTL(300).WithIDs()
+			api := testutil.NewFakeAPI(t, shape, zone, testutil.NewZone("example.org"))
+			p := newRESTProvider(api, shape, nil)
+
+			records, err := p.GetRecords("example.com")
+			require.NoError(t, err)
+			require.Equal(t, zone.Records(), records)
+
+			zones, err := p.ListZones()
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"example.com", "example.org"}, zones)
+		})
+	}
+}
+
+func TestFakeAPI_SetRecords(t *testing.T) {
+	shape := testutil.CloudflareShape()
+	api := testutil.NewFakeAPI(t, shape, testutil.NewZone("example.com").Add(
+		testutil.A("www", "192.0.2.1"),
+		testutil.TXT("old", "gone"),
+	))
+	p := newRESTProvider(api, shape, nil)
+
+	result, err := p.SetRecords("example.com", []dnsrecord.Record{
+		testutil.A("www", "192.0.2.1"),
+		testutil.NewRecord("mail", "MX", "mx.example.com").MXPref(5).TTL(600).Build(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyUnchanged))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyCreated))
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyDeleted))
+
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "rec-1", HostName: "www", RecordType: "A", Address: "192.0.2.1"},
+		{ID: "rec-3", HostName: "mail", RecordType: "MX", Address: "mx.example.com", TTL: 600, MXPref: 5},
+	}, api.Records("example.com"))
+
+	var writes []string
+	for _, r := range api.Requests() {
+		if r.Method != http.MethodGet {
+			writes = append(writes, r.Method+" "+r.Path)
+		}
+	}
+	require.Equal(t, []string{"DELETE /zones/zone-1/records/rec-2", "POST /zones/zone-1/records"}, writes)
+}
+
+func TestFakeAPI_AuthAndFailures(t *testing.T) {
+	shape := testutil.ArrayShape()
+	api := testutil.NewFakeAPI(t, shape, testutil.StandardZone("example.com")).RequireHeader("Authorization", "Bearer token")
+
+	_, err := newRESTProvider(api, shape, nil).GetRecords("example.com")
+	require.ErrorContains(t, err, "invalid credentials")
+
+	p := newRESTProvider(api, shape, map[string]string{"Authorization": "Bearer token"})
+	_, err = p.GetRecords("example.com")
+	require.NoError(t, err)
+
+	api.Fail(http.MethodPost, http.StatusUnprocessableEntity)
+	result, err := p.SetRecords("example.com", append(api.Records("example.com"), testutil.A("new", "192.0.2.9")))
+	require.Error(t, err)
+	require.Equal(t, 1, result.Count(dnsprovider.ApplyFailed))
+	require.Len(t, api.Records("example.com"), 5)
+}
+
+func TestBuilders(t *testing.T) {
+	require.Equal(t, dnsrecord.Record{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 5 5060 sip.example.com"}, testutil.SRV("_sip._tcp", 10, 5, 5060, "sip.example.com"))
+	require.Equal(t, `0 issue "letsencrypt.org"`, testutil.CAA("@", 0, "issue", "letsencrypt.org").Address)
+
+	zone := testutil.NewZone("example.com").TTL(3600).WithIDs().Add(
+		testutil.A("@", "192.0.2.1"),
+		testutil.NewRecord("www", "A", "192.0.2.2").TTL(60).ID("www").Build(),
+	)
+	require.Equal(t, []dnsrecord.Record{
+		{ID: "1", HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{ID: "www", HostName: "www", RecordType: "A", Address: "192.0.2.2", TTL: 60},
+	}, zone.Records())
+}
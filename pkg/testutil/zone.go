@@ -0,0 +1,72 @@
+package testutil
+
+import (
+	"fmt"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// ZoneBuilder builds the records of one zone for tests
+type ZoneBuilder struct {
+	domain  string
+	ttl     int
+	withIDs bool
+	records []dnsrecord.Record
+}
+
+// NewZone starts an empty zone for domain
+func NewZone(domain string) *ZoneBuilder {
+	return &ZoneBuilder{domain: domain}
+}
+
+// StandardZone returns a zone with the records most tests need: apex A and
+// AAAA records, a www CNAME, an MX record and an SPF TXT record
+func StandardZone(domain string) *ZoneBuilder {
+	return NewZone(domain).Add(
+		A("@", "192.0.2.1"),
+		AAAA("@", "2001:db8::1"),
+		CNAME("www", domain+"."),
+		MX("@", 10, "mail."+domain+"."),
+		TXT("@", "v=spf1 mx -all"),
+	)
+}
+
+// TTL sets the TTL given to records added without one
+func (z *ZoneBuilder) TTL(ttl int) *ZoneBuilder {
+	z.ttl = ttl
+	return z
+}
+
+// WithIDs gives records without an ID sequential IDs ("1", "2", ...) in the
+// order they were added, as a provider would
+func (z *ZoneBuilder) WithIDs() *ZoneBuilder {
+	z.withIDs = true
+	return z
+}
+
+// Add appends records to the zone
+func (z *ZoneBuilder) Add(records ...dnsrecord.Record) *ZoneBuilder {
+	z.records = append(z.records, records...)
+	return z
+}
+
+// Domain returns the zone's domain name
+func (z *ZoneBuilder) Domain() string {
+	return z.domain
+}
+
+// Records returns a copy of the zone's records with the default TTL and IDs
+// applied
+func (z *ZoneBuilder) Records() []dnsrecord.Record {
+	records := make([]dnsrecord.Record, len(z.records))
+	for i, r := range z.records {
+		if r.TTL == 0 {
+			r.TTL = z.ttl
+		}
+		if r.ID == "" && z.withIDs {
+			r.ID = fmt.Sprintf("%d", i+1)
+		}
+		records[i] = r
+	}
+	return records
+}
@@ -0,0 +1,116 @@
+// Package timefmt formats dates and timestamps for display, in the user's time
+// zone and preferred date format.
+package timefmt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date format presets accepted by New
+const (
+	FormatISO = "iso" // 2006-01-02
+	FormatUS  = "us"  // 01/02/2006
+	FormatEU  = "eu"  // 02.01.2006
+)
+
+var presets = map[string]string{
+	FormatISO: "2006-01-02",
+	FormatUS:  "01/02/2006",
+	FormatEU:  "02.01.2006",
+}
+
+// parseLayouts are the layouts Parse accepts: Go's time.String form used for
+// domain dates, RFC 3339, ISO dates and the Namecheap API date formats
+var parseLayouts = []string{
+	"2006-01-02 15:04:05 -0700 MST",
+	time.RFC3339,
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006 3:04:05 PM",
+}
+
+// Formatter formats dates and timestamps
+type Formatter struct {
+	// Location is the time zone timestamps are shown in
+	Location *time.Location
+	// DateLayout is the Go layout of the date part
+	DateLayout string
+}
+
+// New creates a formatter. timezone is "local" or empty for the system time
+// zone, "UTC" or an IANA name such as "Europe/Berlin"; dateFormat is one of the
+// presets, empty for FormatISO, or a Go layout such as "Jan 2, 2006".
+func New(timezone, dateFormat string) (*Formatter, error) {
+	location := time.Local
+	switch strings.ToLower(timezone) {
+	case "", "local":
+	case "utc":
+		location = time.UTC
+	default:
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+		location = loc
+	}
+
+	layout := presets[FormatISO]
+	if dateFormat != "" {
+		if preset, ok := presets[strings.ToLower(dateFormat)]; ok {
+			layout = preset
+		} else if strings.Contains(dateFormat, "06") {
+			layout = dateFormat
+		} else {
+			return nil, fmt.Errorf("invalid date format %q (use iso, us, eu or a Go layout such as \"Jan 2, 2006\")", dateFormat)
+		}
+	}
+
+	return &Formatter{Location: location, DateLayout: layout}, nil
+}
+
+// Default returns a formatter for ISO dates in the system time zone
+func Default() *Formatter {
+	return &Formatter{Location: time.Local, DateLayout: presets[FormatISO]}
+}
+
+// UTC returns a formatter for ISO dates in UTC
+func UTC() *Formatter {
+	return &Formatter{Location: time.UTC, DateLayout: presets[FormatISO]}
+}
+
+// Date formats a calendar date such as a domain's expiry date. Dates are not
+// moved into the formatter's time zone, so they never shift by a day.
+func (f *Formatter) Date(t time.Time) string {
+	return t.Format(f.DateLayout)
+}
+
+// Timestamp formats a point in time in the formatter's time zone
+func (f *Formatter) Timestamp(t time.Time) string {
+	return t.In(f.Location).Format(f.DateLayout + " 15:04 MST")
+}
+
+// DateString formats a date given as a string in any of the layouts Parse
+// accepts. Values that cannot be parsed are returned unchanged.
+func (f *Formatter) DateString(value string) string {
+	t, ok := Parse(value)
+	if !ok {
+		return value
+	}
+	return f.Date(t)
+}
+
+// Parse parses a date or timestamp as returned by the providers' APIs
+func Parse(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range parseLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
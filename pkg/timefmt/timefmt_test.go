@@ -0,0 +1,59 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TimeFmtTestSuite tests date and timestamp formatting
+type TimeFmtTestSuite struct {
+	suite.Suite
+}
+
+func TestTimeFmtSuite(t *testing.T) {
+	suite.Run(t, new(TimeFmtTestSuite))
+}
+
+func (s *TimeFmtTestSuite) TestNew() {
+	f, err := New("", "")
+	s.Require().NoError(err)
+	s.Require().Equal(time.Local, f.Location)
+	s.Require().Equal("2006-01-02", f.DateLayout)
+
+	f, err = New("UTC", "us")
+	s.Require().NoError(err)
+	s.Require().Equal(time.UTC, f.Location)
+	s.Require().Equal("01/02/2006", f.DateLayout)
+
+	f, err = New("local", "Jan 2, 2006")
+	s.Require().NoError(err)
+	s.Require().Equal("Jan 2, 2006", f.DateLayout)
+
+	_, err = New("Mars/Olympus", "")
+	s.Require().ErrorContains(err, "invalid time zone")
+	_, err = New("", "yyyy-mm-dd")
+	s.Require().ErrorContains(err, "invalid date format")
+}
+
+func (s *TimeFmtTestSuite) TestTimestamp_UsesLocation() {
+	berlin, err := New("Europe/Berlin", "eu")
+	if err != nil {
+		s.T().Skip("time zone database not available")
+	}
+	t := time.Date(2024, 5, 1, 22, 30, 0, 0, time.UTC)
+
+	s.Require().Equal("02.05.2024 00:30 CEST", berlin.Timestamp(t))
+	s.Require().Equal("2024-05-01 22:30 UTC", UTC().Timestamp(t))
+}
+
+func (s *TimeFmtTestSuite) TestDateString_DoesNotShiftDates() {
+	f := &Formatter{Location: time.FixedZone("EST", -5*3600), DateLayout: presets[FormatUS]}
+
+	s.Require().Equal("12/31/2030", f.DateString("2030-12-31 00:00:00 +0000 UTC"))
+	s.Require().Equal("12/31/2030", f.DateString("12/31/2030"))
+	s.Require().Equal("06/15/2025", f.DateString("6/15/2025 10:20:30 AM"))
+	s.Require().Equal("not a date", f.DateString("not a date"))
+	s.Require().Equal("", f.DateString(""))
+}
@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// FlushInterval is how often queued spans are exported
+	FlushInterval = 5 * time.Second
+	// maxQueued is the number of queued spans that triggers an export
+	maxQueued = 512
+)
+
+// Config configures the export of spans over OTLP/HTTP
+type Config struct {
+	// Endpoint is the OTLP/HTTP base URL of a collector, e.g.
+	// http://localhost:4318; spans are posted to /v1/traces under it
+	Endpoint string
+	// TracesEndpoint is the full URL spans are posted to; it takes precedence
+	// over Endpoint
+	TracesEndpoint string
+	// Headers are sent with each export, e.g. an API key of a tracing service
+	Headers map[string]string
+	// ServiceName names zonekit in the traces; the default is "zonekit"
+	ServiceName string
+	// ServiceVersion is the zonekit version reported with the traces
+	ServiceVersion string
+}
+
+// Enabled reports whether an endpoint is configured
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || c.TracesEndpoint != ""
+}
+
+// URL returns the URL spans are posted to
+func (c Config) URL() string {
+	if c.TracesEndpoint != "" {
+		return c.TracesEndpoint
+	}
+	return strings.TrimSuffix(c.Endpoint, "/") + "/v1/traces"
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry environment variables:
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS (and its TRACES variant) and OTEL_SERVICE_NAME.
+// OTEL_SDK_DISABLED=true disables tracing.
+func ConfigFromEnv() Config {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return Config{}
+	}
+	config := Config{
+		Endpoint:       os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		TracesEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		ServiceName:    os.Getenv("OTEL_SERVICE_NAME"),
+		Headers:        ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+	}
+	for key, value := range ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")) {
+		config.Headers[key] = value
+	}
+	return config
+}
+
+// ParseHeaders parses headers in the OTEL_EXPORTER_OTLP_HEADERS format,
+// comma-separated key=value pairs with URL-encoded values
+func ParseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(val)); err == nil {
+			val = decoded
+		}
+		headers[key] = strings.TrimSpace(val)
+	}
+	return headers
+}
+
+// Exporter exports spans to an OTLP/HTTP collector in batches: every
+// FlushInterval, when many spans are queued, and on Shutdown
+type Exporter struct {
+	provider *sdktrace.TracerProvider
+	// OnError is called with errors of exports; nil ignores them
+	OnError func(error)
+}
+
+// NewExporter creates an exporter and starts exporting in the background
+func NewExporter(config Config) (*Exporter, error) {
+	if config.ServiceName == "" {
+		config.ServiceName = "zonekit"
+	}
+	client, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(config.URL()),
+		otlptracehttp.WithHeaders(config.Headers),
+		otlptracehttp.WithTimeout(10*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	attributes := []attribute.KeyValue{semconv.ServiceName(config.ServiceName)}
+	if config.ServiceVersion != "" {
+		attributes = append(attributes, semconv.ServiceVersion(config.ServiceVersion))
+	}
+	e := &Exporter{}
+	e.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attributes...)),
+		sdktrace.WithBatcher(reportingExporter{SpanExporter: client, exporter: e},
+			sdktrace.WithBatchTimeout(FlushInterval),
+			sdktrace.WithMaxExportBatchSize(maxQueued),
+		),
+	)
+	return e, nil
+}
+
+// Flush exports the spans that have ended
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the remaining spans and stops exporting
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.provider.Shutdown(ctx)
+}
+
+// reportingExporter passes export errors to the exporter's OnError instead of
+// the SDK's global error handler, which logs them
+type reportingExporter struct {
+	sdktrace.SpanExporter
+	exporter *Exporter
+}
+
+func (r reportingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if err := r.SpanExporter.ExportSpans(ctx, spans); err != nil && r.exporter.OnError != nil {
+		r.exporter.OnError(fmt.Errorf("failed to export spans: %w", err))
+	}
+	return nil
+}
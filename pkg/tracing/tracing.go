@@ -0,0 +1,177 @@
+// Package tracing records OpenTelemetry spans of the operations zonekit makes
+// against providers and exports them over OTLP/HTTP with the OpenTelemetry SDK,
+// so long-running daemon modes and CI runs can be traced alongside the rest of
+// an infrastructure.
+//
+// Spans are only exported when an exporter is set with SetExporter by the time
+// they start; without one, spans carry trace IDs for the traceparent header but
+// are not recorded. Spans started without a parent in their context become
+// children of the default parent, usually the span of the command being run, as
+// most services do not pass a context along.
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Common attribute keys of provider operations
+const (
+	AttrOperation = "zonekit.operation"
+	AttrProvider  = "zonekit.provider"
+	AttrDomain    = "zonekit.domain"
+)
+
+// scopeName is the instrumentation scope of zonekit's spans
+const scopeName = "zonekit"
+
+// Attribute is a key and a string, int or bool value
+type Attribute = attribute.KeyValue
+
+// String returns a string attribute
+func String(key, value string) Attribute {
+	return attribute.String(key, value)
+}
+
+// Int returns an integer attribute
+func Int(key string, value int) Attribute {
+	return attribute.Int(key, value)
+}
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return attribute.Bool(key, value)
+}
+
+// Span is a timed operation within a trace
+type Span struct {
+	span trace.Span
+}
+
+var (
+	mu            sync.RWMutex
+	exporter      *Exporter
+	defaultParent *Span
+	// unexported is the provider of spans started without an exporter; it has
+	// no span processors, so its spans get IDs but are dropped
+	unexported = sdktrace.NewTracerProvider()
+)
+
+// SetExporter sets the exporter spans started from now on are exported with;
+// nil stops exporting
+func SetExporter(e *Exporter) {
+	mu.Lock()
+	exporter = e
+	mu.Unlock()
+}
+
+// Enabled reports whether spans are exported
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return exporter != nil
+}
+
+// SetDefaultParent makes spans started without a parent in their context
+// children of span; nil starts new traces instead
+func SetDefaultParent(span *Span) {
+	mu.Lock()
+	defaultParent = span
+	mu.Unlock()
+}
+
+// DefaultParent returns the default parent set with SetDefaultParent
+func DefaultParent() *Span {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultParent
+}
+
+// Start starts an internal span named name, a child of the span in ctx or of
+// the default parent, and returns a context holding it
+func Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, trace.SpanKindInternal, attributes)
+}
+
+// StartClient starts a span of a request to a remote service, like Start
+func StartClient(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, trace.SpanKindClient, attributes)
+}
+
+func start(ctx context.Context, name string, kind trace.SpanKind, attributes []Attribute) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	mu.RLock()
+	provider := unexported
+	if exporter != nil {
+		provider = exporter.provider
+	}
+	parent := defaultParent
+	mu.RUnlock()
+
+	if SpanFromContext(ctx) == nil && parent != nil {
+		ctx = trace.ContextWithSpan(ctx, parent.span)
+	}
+	ctx, span := provider.Tracer(scopeName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+	s := &Span{span: span}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span held by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attributes...)
+}
+
+// End ends the span, with an error status if err is not nil, and hands it to
+// the exporter. Ending a span again has no effect.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.span.SetStatus(codes.Error, err.Error())
+	} else {
+		s.span.SetStatus(codes.Ok, "")
+	}
+	s.span.End()
+}
+
+// TraceParent returns the W3C traceparent header value that continues the
+// span's trace in a remote service
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpan(context.Background(), s.span), carrier)
+	return carrier.Get("traceparent")
+}
+
+// TraceID returns the hex ID of the span's trace
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.span.SpanContext().TraceID().String()
+}
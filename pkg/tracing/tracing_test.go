@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TracingTestSuite tests spans and their export
+type TracingTestSuite struct {
+	suite.Suite
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	headers  []http.Header
+	server   *httptest.Server
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (s *TracingTestSuite) SetupTest() {
+	s.requests = nil
+	s.headers = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("/v1/traces", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		request := &coltracepb.ExportTraceServiceRequest{}
+		s.NoError(proto.Unmarshal(body, request))
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+	}))
+}
+
+func (s *TracingTestSuite) TearDownTest() {
+	SetExporter(nil)
+	SetDefaultParent(nil)
+	s.server.Close()
+}
+
+// valueOf returns the value of the attribute with key
+func valueOf(attributes []*commonpb.KeyValue, key string) *commonpb.AnyValue {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+func (s *TracingTestSuite) TestExport() {
+	exporter, err := NewExporter(Config{Endpoint: s.server.URL + "/", Headers: map[string]string{"X-Api-Key": "secret"}, ServiceVersion: "1.2.3"})
+	s.Require().NoError(err)
+	SetExporter(exporter)
+
+	ctx, command := Start(context.Background(), "zonekit dns add")
+	SetDefaultParent(command)
+	_, operation := StartClient(ctx, "dns.set_records", String(AttrProvider, "namecheap"), String(AttrDomain, "example.com"))
+	operation.SetAttributes(Int("zonekit.changes", 2), Bool("zonekit.dry_run", false))
+	operation.End(errors.New("provider error"))
+	// Without a parent in the context, the default parent is used
+	_, detached := Start(context.Background(), "dns.get_records")
+	detached.End(nil)
+	command.End(nil)
+	command.End(nil)
+
+	s.Require().NoError(exporter.Shutdown(context.Background()))
+	s.Require().Len(s.requests, 1)
+	s.Require().Equal("secret", s.headers[0].Get("X-Api-Key"))
+
+	resourceSpans := s.requests[0].ResourceSpans[0]
+	s.Require().Equal("zonekit", valueOf(resourceSpans.Resource.Attributes, "service.name").GetStringValue())
+	s.Require().Equal("1.2.3", valueOf(resourceSpans.Resource.Attributes, "service.version").GetStringValue())
+	spans := resourceSpans.ScopeSpans[0].Spans
+	s.Require().Len(spans, 3, "a span ended twice is exported once")
+
+	set, get, root := spans[0], spans[1], spans[2]
+	s.Require().Empty(root.ParentSpanId)
+	s.Require().Equal(root.SpanId, set.ParentSpanId)
+	s.Require().Equal(root.SpanId, get.ParentSpanId)
+	s.Require().Equal(root.TraceId, set.TraceId)
+	s.Require().Equal(command.TraceID(), hex.EncodeToString(root.TraceId))
+
+	s.Require().Equal(tracepb.Span_SPAN_KIND_CLIENT, set.Kind)
+	s.Require().Equal(tracepb.Status_STATUS_CODE_ERROR, set.Status.Code)
+	s.Require().Equal("provider error", set.Status.Message)
+	s.Require().Equal(tracepb.Status_STATUS_CODE_OK, get.Status.Code)
+	s.Require().Equal("namecheap", valueOf(set.Attributes, AttrProvider).GetStringValue())
+	s.Require().Equal(int64(2), valueOf(set.Attributes, "zonekit.changes").GetIntValue())
+	s.Require().False(valueOf(set.Attributes, "zonekit.dry_run").GetBoolValue())
+}
+
+func (s *TracingTestSuite) TestExportErrors() {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	exporter, err := NewExporter(Config{TracesEndpoint: failing.URL + "/v1/traces"})
+	s.Require().NoError(err)
+	var errs []error
+	exporter.OnError = func(err error) { errs = append(errs, err) }
+	SetExporter(exporter)
+
+	_, span := Start(context.Background(), "dns.get_records")
+	span.End(nil)
+	s.Require().NoError(exporter.Shutdown(context.Background()))
+	s.Require().Len(errs, 1)
+	s.Require().ErrorContains(errs[0], "failed to export spans")
+}
+
+func (s *TracingTestSuite) TestNoExporter() {
+	_, span := Start(context.Background(), "dns.get_records")
+	span.End(nil)
+
+	var nilSpan *Span
+	nilSpan.SetAttributes(String("a", "b"))
+	nilSpan.End(nil)
+	s.Require().Empty(nilSpan.TraceParent())
+}
+
+func (s *TracingTestSuite) TestTraceParent() {
+	ctx, span := Start(context.Background(), "request")
+	s.Require().Same(span, SpanFromContext(ctx))
+	parts := strings.Split(span.TraceParent(), "-")
+	s.Require().Len(parts, 4)
+	s.Require().Equal(span.TraceID(), parts[1])
+}
+
+func (s *TracingTestSuite) TestConfig() {
+	s.Require().False(Config{}.Enabled())
+	s.Require().Equal("http://collector:4318/v1/traces", Config{Endpoint: "http://collector:4318"}.URL())
+	s.Require().Equal("http://collector/traces", Config{Endpoint: "http://other", TracesEndpoint: "http://collector/traces"}.URL())
+
+	s.T().Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	s.T().Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=a%20b, x-team = dns")
+	s.T().Setenv("OTEL_SERVICE_NAME", "zonekit-ci")
+	config := ConfigFromEnv()
+	s.Require().True(config.Enabled())
+	s.Require().Equal(map[string]string{"api-key": "a b", "x-team": "dns"}, config.Headers)
+	s.Require().Equal("zonekit-ci", config.ServiceName)
+
+	s.T().Setenv("OTEL_SDK_DISABLED", "true")
+	s.Require().False(ConfigFromEnv().Enabled())
+}
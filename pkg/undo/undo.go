@@ -0,0 +1,220 @@
+// Package undo keeps the change sets applied during an interactive session on
+// an in-memory stack, so they can be undone and redone through the provider.
+// It is independent of the change history and backups kept on disk and is
+// gone when the session ends.
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+// DefaultLimit is the number of steps a session keeps by default
+const DefaultLimit = 100
+
+var (
+	// ErrNothingToUndo is returned by Undo when no step was applied
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned by Redo when no step was undone
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrChanged is returned when the zone no longer holds the records a step
+	// left it with, so reverting would also discard a later change
+	ErrChanged = errors.New("zone changed since")
+)
+
+// Zone reads and replaces the records of a zone; *dns.Service implements it
+type Zone interface {
+	RefreshRecords(domainName string) ([]dnsrecord.Record, error)
+	ApplyRecords(domainName string, records []dnsrecord.Record) (*provider.ApplyResult, error)
+}
+
+// Step is one applied change set: the records of a zone before and after it
+type Step struct {
+	Time     time.Time
+	Domain   string
+	Provider string
+	Account  string
+	Command  string
+	Before   []dnsrecord.Record
+	After    []dnsrecord.Record
+	// Zone is the service that applied the step, which reverts it
+	Zone Zone
+}
+
+// Stack holds the applied and the undone steps of a session
+type Stack struct {
+	mu     sync.Mutex
+	limit  int
+	done   []Step
+	undone []Step
+	// replaying is set while a step is undone or redone, whose apply must not
+	// be pushed as a new step
+	replaying bool
+}
+
+// NewStack creates a stack that keeps the last limit steps
+func NewStack(limit int) *Stack {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Stack{limit: limit}
+}
+
+// Push adds an applied step. A new step discards the steps that were undone,
+// as they no longer follow from the zone's records.
+func (s *Stack) Push(step Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.replaying {
+		return
+	}
+
+	step.Before = append([]dnsrecord.Record(nil), step.Before...)
+	step.After = append([]dnsrecord.Record(nil), step.After...)
+	s.done = append(s.done, step)
+	if len(s.done) > s.limit {
+		s.done = s.done[len(s.done)-s.limit:]
+	}
+	s.undone = nil
+}
+
+// Steps returns the applied steps and the undone ones, most recent first
+func (s *Stack) Steps() (done, undone []Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return reversed(s.done), reversed(s.undone)
+}
+
+// Undo reverts the most recent step by applying the records the zone had
+// before it. A zone that changed since the step is only reverted with force.
+func (s *Stack) Undo(force bool) (Step, *provider.ApplyResult, error) {
+	return s.replay(&s.done, &s.undone, ErrNothingToUndo, force, func(step Step) (expected, records []dnsrecord.Record) {
+		return step.After, step.Before
+	})
+}
+
+// Redo applies the most recently undone step again. A zone that changed since
+// it was undone is only changed with force.
+func (s *Stack) Redo(force bool) (Step, *provider.ApplyResult, error) {
+	return s.replay(&s.undone, &s.done, ErrNothingToRedo, force, func(step Step) (expected, records []dnsrecord.Record) {
+		return step.Before, step.After
+	})
+}
+
+// replay applies the records of the last step of from and moves it to to
+func (s *Stack) replay(from, to *[]Step, empty error, force bool, states func(Step) (expected, records []dnsrecord.Record)) (Step, *provider.ApplyResult, error) {
+	s.mu.Lock()
+	if len(*from) == 0 {
+		s.mu.Unlock()
+		return Step{}, nil, empty
+	}
+	step := (*from)[len(*from)-1]
+	s.replaying = true
+	s.mu.Unlock()
+
+	result, err := apply(step, force, states)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replaying = false
+	if err != nil {
+		return step, result, err
+	}
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, step)
+	return step, result, nil
+}
+
+// apply checks that the zone holds the expected records and applies the others
+func apply(step Step, force bool, states func(Step) (expected, records []dnsrecord.Record)) (*provider.ApplyResult, error) {
+	expected, records := states(step)
+	if !force {
+		current, err := step.Zone.RefreshRecords(step.Domain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get records of %s: %w", step.Domain, err)
+		}
+		if !Same(current, expected) {
+			return nil, fmt.Errorf("%s: %w %q", step.Domain, ErrChanged, step.Command)
+		}
+	}
+
+	result, err := step.Zone.ApplyRecords(step.Domain, records)
+	if err != nil {
+		return result, err
+	}
+	return result, result.Err()
+}
+
+// Same reports whether two record sets hold the same records, compared by
+// name, type and normalized value. TTLs are ignored, as providers may round
+// them.
+func Same(a, b []dnsrecord.Record) bool {
+	was, is := recordCounts(a), recordCounts(b)
+	if len(was) != len(is) {
+		return false
+	}
+	for key, count := range was {
+		if is[key] != count {
+			return false
+		}
+	}
+	return true
+}
+
+// recordCounts counts records by name, type and normalized value
+func recordCounts(records []dnsrecord.Record) map[string]int {
+	counts := make(map[string]int, len(records))
+	for _, r := range records {
+		host := strings.TrimSuffix(strings.ToLower(r.HostName), ".")
+		if host == "" {
+			host = "@"
+		}
+		counts[host+" "+strings.ToUpper(r.RecordType)+" "+zonecompare.Normalize(r)]++
+	}
+	return counts
+}
+
+// reversed returns a copy of steps, most recent first
+func reversed(steps []Step) []Step {
+	out := make([]Step, len(steps))
+	for i, step := range steps {
+		out[len(steps)-1-i] = step
+	}
+	return out
+}
+
+// Recorder pushes the change sets applied through a DNS service onto a stack
+type Recorder struct {
+	stack   *Stack
+	zone    Zone
+	account string
+	command string
+	now     func() time.Time
+}
+
+// NewRecorder creates a recorder for the changes zone applies on behalf of
+// command in account
+func NewRecorder(stack *Stack, zone Zone, account, command string) *Recorder {
+	return &Recorder{stack: stack, zone: zone, account: account, command: command, now: time.Now}
+}
+
+// Applied pushes an applied change set
+func (r *Recorder) Applied(domainName, providerName string, before, after []dnsrecord.Record) {
+	r.stack.Push(Step{
+		Time:     r.now(),
+		Domain:   domainName,
+		Provider: providerName,
+		Account:  r.account,
+		Command:  r.command,
+		Before:   before,
+		After:    after,
+		Zone:     r.zone,
+	})
+}
@@ -0,0 +1,116 @@
+package undo
+
+import (
+	"testing"
+
+	"zonekit/pkg/dns/provider"
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeZone is a zone whose applies are pushed onto a stack, like a DNS service
+// with an undo recorder attached
+type fakeZone struct {
+	records  []dnsrecord.Record
+	recorder *Recorder
+	applies  int
+}
+
+func (z *fakeZone) RefreshRecords(domainName string) ([]dnsrecord.Record, error) {
+	return append([]dnsrecord.Record(nil), z.records...), nil
+}
+
+func (z *fakeZone) ApplyRecords(domainName string, records []dnsrecord.Record) (*provider.ApplyResult, error) {
+	before := z.records
+	z.records = append([]dnsrecord.Record(nil), records...)
+	z.applies++
+	if z.recorder != nil {
+		z.recorder.Applied(domainName, "fake", before, records)
+	}
+	return &provider.ApplyResult{Domain: domainName}, nil
+}
+
+func TestStack_UndoRedo(t *testing.T) {
+	stack := NewStack(0)
+	zone := &fakeZone{records: []dnsrecord.Record{testutil.A("@", "192.0.2.1")}}
+	zone.recorder = NewRecorder(stack, zone, "work", "dns add example.com www A 192.0.2.2")
+
+	_, _, err := stack.Undo(false)
+	require.ErrorIs(t, err, ErrNothingToUndo)
+
+	added := append(zone.records, testutil.A("www", "192.0.2.2"))
+	_, err = zone.ApplyRecords("example.com", added)
+	require.NoError(t, err)
+
+	step, _, err := stack.Undo(false)
+	require.NoError(t, err)
+	require.Equal(t, "example.com", step.Domain)
+	require.Equal(t, "work", step.Account)
+	require.Equal(t, []dnsrecord.Record{testutil.A("@", "192.0.2.1")}, zone.records)
+
+	done, undone := stack.Steps()
+	require.Empty(t, done)
+	require.Len(t, undone, 1)
+
+	_, _, err = stack.Redo(false)
+	require.NoError(t, err)
+	require.Equal(t, added, zone.records)
+	_, _, err = stack.Redo(false)
+	require.ErrorIs(t, err, ErrNothingToRedo)
+
+	// A new change discards what was undone
+	_, _, err = stack.Undo(false)
+	require.NoError(t, err)
+	_, err = zone.ApplyRecords("example.com", []dnsrecord.Record{testutil.A("@", "192.0.2.9")})
+	require.NoError(t, err)
+	done, undone = stack.Steps()
+	require.Len(t, done, 1)
+	require.Empty(t, undone)
+}
+
+func TestStack_UndoChangedZone(t *testing.T) {
+	stack := NewStack(0)
+	zone := &fakeZone{}
+	zone.recorder = NewRecorder(stack, zone, "", "dns add example.com www A 192.0.2.2")
+	_, err := zone.ApplyRecords("example.com", []dnsrecord.Record{testutil.A("www", "192.0.2.2")})
+	require.NoError(t, err)
+
+	// Changed outside the session
+	zone.records = []dnsrecord.Record{testutil.A("www", "192.0.2.3")}
+	applies := zone.applies
+
+	_, _, err = stack.Undo(false)
+	require.ErrorIs(t, err, ErrChanged)
+	require.Equal(t, applies, zone.applies)
+
+	_, _, err = stack.Undo(true)
+	require.NoError(t, err)
+	require.Empty(t, zone.records)
+}
+
+func TestStack_Limit(t *testing.T) {
+	stack := NewStack(2)
+	for _, command := range []string{"first", "second", "third"} {
+		stack.Push(Step{Command: command})
+	}
+	done, _ := stack.Steps()
+	require.Len(t, done, 2)
+	require.Equal(t, "third", done[0].Command)
+	require.Equal(t, "second", done[1].Command)
+}
+
+func TestSame(t *testing.T) {
+	a := []dnsrecord.Record{
+		testutil.NewRecord("@", "A", "192.0.2.1").TTL(1800).Build(),
+		testutil.CNAME("www", "example.com."),
+	}
+	b := []dnsrecord.Record{
+		testutil.CNAME("WWW", "Example.com"),
+		testutil.NewRecord("", "A", "192.0.2.1").TTL(300).Build(),
+	}
+	require.True(t, Same(a, b))
+	require.False(t, Same(a, b[:1]))
+	require.False(t, Same(a, append(b, testutil.A("@", "192.0.2.1"))))
+}
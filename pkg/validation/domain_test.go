@@ -80,3 +80,32 @@ func (s *DomainValidationTestSuite) TestValidateDomain() {
 		})
 	}
 }
+
+func (s *DomainValidationTestSuite) TestValidateHostname() {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{
+		{name: "valid nameserver", host: "ns1.example.com", wantErr: false},
+		{name: "trailing dot", host: "ns1.example.com.", wantErr: false},
+		{name: "hyphenated label", host: "dns-1.example-dns.net", wantErr: false},
+		{name: "single label", host: "localhost", wantErr: true},
+		{name: "IP address", host: "192.0.2.1", wantErr: true},
+		{name: "leading hyphen", host: "-ns1.example.com", wantErr: true},
+		{name: "underscore", host: "ns_1.example.com", wantErr: true},
+		{name: "space", host: "ns1 .example.com", wantErr: true},
+		{name: "empty", host: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			err := ValidateHostname(tt.host)
+			if tt.wantErr {
+				s.Require().Error(err)
+			} else {
+				s.Require().NoError(err)
+			}
+		})
+	}
+}
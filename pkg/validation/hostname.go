@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateHostname validates that host is a fully qualified hostname such as
+// ns1.example.com. A single trailing dot is allowed; IP addresses are rejected.
+func ValidateHostname(host string) error {
+	name := strings.TrimSuffix(host, ".")
+	if err := ValidateDomain(name); err != nil {
+		return fmt.Errorf("invalid hostname %q: %w", host, err)
+	}
+
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("invalid hostname %q: IP addresses are not allowed", host)
+	}
+
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("invalid hostname %q: label %q cannot start or end with a hyphen", host, label)
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return fmt.Errorf("invalid hostname %q: label %q contains invalid character %q", host, label, r)
+			}
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return fmt.Errorf("invalid hostname %q: top-level domain cannot be numeric", host)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// PostWebhook posts an event as JSON to url and expects a 2xx answer
+func PostWebhook(ctx context.Context, httpClient *http.Client, url string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RunCommand runs a shell command with the event as JSON on its stdin and
+// ZONEKIT_DOMAIN and ZONEKIT_CHANGES (the number of changed record sets) in
+// its environment
+func RunCommand(ctx context.Context, command string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	c := exec.CommandContext(ctx, "sh", "-c", command)
+	c.Stdin = bytes.NewReader(body)
+	c.Stdout = os.Stderr
+	c.Env = append(os.Environ(),
+		"ZONEKIT_DOMAIN="+event.Domain,
+		"ZONEKIT_CHANGES="+strconv.Itoa(len(event.Changes)),
+	)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("command failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("command failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Package watch polls a zone on an interval and reports the record sets that
+// change between polls, such as edits made outside zonekit or live answers
+// changed by a hijacked delegation.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonecompare"
+)
+
+// Source names
+const (
+	SourceProvider = zonecompare.SourceProvider
+	SourceDNS      = zonecompare.SourceDNS
+)
+
+// Snapshot holds the records of each watched source at one poll
+type Snapshot map[string][]dnsrecord.Record
+
+// Change is a record set of a source that changed between two polls
+type Change struct {
+	Source   string   `json:"source"`
+	HostName string   `json:"hostname"`
+	Type     string   `json:"type"`
+	Before   []string `json:"before"`
+	After    []string `json:"after"`
+}
+
+// Event reports the changes found by a poll
+type Event struct {
+	Domain string    `json:"domain"`
+	Time   time.Time `json:"time"`
+	// Since is the time of the previous poll
+	Since   time.Time `json:"since"`
+	Changes []Change  `json:"changes"`
+	// Recorded reports whether zonekit applied a change to the domain since
+	// the previous poll; provider changes without one were made elsewhere
+	Recorded bool `json:"recorded"`
+}
+
+// Watcher polls a zone and reports the changes between polls
+type Watcher struct {
+	Domain   string
+	Interval time.Duration
+	// Poll returns the records of every watched source
+	Poll func(ctx context.Context) (Snapshot, error)
+	// Recorded reports whether zonekit applied a change to the domain at or
+	// after since; nil reports false
+	Recorded func(since time.Time) bool
+	// Changed is called for every poll that found changes
+	Changed func(Event)
+	// Failed is called when a poll fails; the watch goes on with the next one
+	Failed func(error)
+
+	now func() time.Time
+}
+
+// Run takes a first snapshot and then polls every interval until ctx is done.
+// Only a failing first poll is returned, as there is nothing to watch without it.
+func (w *Watcher) Run(ctx context.Context) error {
+	now := w.now
+	if now == nil {
+		now = time.Now
+	}
+
+	last, err := w.Poll(ctx)
+	if err != nil {
+		return err
+	}
+	lastTime := now()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if w.Failed != nil {
+				w.Failed(err)
+			}
+			continue
+		}
+
+		pollTime := now()
+		if changes := Changes(last, current); len(changes) > 0 && w.Changed != nil {
+			event := Event{Domain: w.Domain, Time: pollTime, Since: lastTime, Changes: changes}
+			if w.Recorded != nil {
+				event.Recorded = w.Recorded(lastTime)
+			}
+			w.Changed(event)
+		}
+		last, lastTime = current, pollTime
+	}
+}
+
+// Changes returns the record sets that differ between two snapshots, by
+// source and then by name and type. Sources missing from either snapshot are
+// not compared. TTLs are ignored, as resolvers count them down.
+func Changes(before, after Snapshot) []Change {
+	sources := make([]string, 0, len(after))
+	for source := range after {
+		if _, ok := before[source]; ok {
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+
+	var changes []Change
+	for _, source := range sources {
+		was, is := recordSets(before[source]), recordSets(after[source])
+		keys := make([]zonecompare.Key, 0, len(is))
+		for key := range is {
+			keys = append(keys, key)
+		}
+		for key := range was {
+			if _, ok := is[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].HostName != keys[j].HostName {
+				return keys[i].HostName < keys[j].HostName
+			}
+			return keys[i].Type < keys[j].Type
+		})
+
+		for _, key := range keys {
+			if !equal(was[key], is[key]) {
+				changes = append(changes, Change{Source: source, HostName: key.HostName, Type: key.Type, Before: was[key], After: is[key]})
+			}
+		}
+	}
+	return changes
+}
+
+// ParseSources parses --source values into source names
+func ParseSources(values []string) ([]string, error) {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			switch name {
+			case SourceProvider, SourceDNS:
+			case "":
+				continue
+			default:
+				return nil, fmt.Errorf("unknown source %q (use provider or dns)", name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				sources = append(sources, name)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no sources to watch (use provider or dns)")
+	}
+	return sources, nil
+}
+
+// recordSets groups records into sorted, normalized values by record set.
+// Unlike zonecompare, NS records at the apex are kept: a changed delegation
+// is what a watch is for.
+func recordSets(records []dnsrecord.Record) map[zonecompare.Key][]string {
+	sets := make(map[zonecompare.Key][]string)
+	for _, r := range records {
+		host := strings.TrimSuffix(strings.ToLower(r.HostName), ".")
+		if host == "" {
+			host = "@"
+		}
+		key := zonecompare.Key{HostName: host, Type: strings.ToUpper(r.RecordType)}
+		sets[key] = append(sets[key], zonecompare.Normalize(r))
+	}
+	for key, values := range sets {
+		sort.Strings(values)
+		unique := values[:0]
+		for i, v := range values {
+			if i == 0 || v != values[i-1] {
+				unique = append(unique, v)
+			}
+		}
+		sets[key] = unique
+	}
+	return sets
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
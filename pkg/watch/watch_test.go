@@ -0,0 +1,130 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	before := Snapshot{
+		SourceProvider: {
+			testutil.NewRecord("@", "A", "192.0.2.1").TTL(1800).Build(),
+			testutil.MX("@", 10, "mx.example.com"),
+		},
+		SourceDNS: {
+			testutil.NS("@", "dns1.registrar-servers.com."),
+		},
+	}
+	after := Snapshot{
+		SourceProvider: {
+			testutil.NewRecord("@", "A", "192.0.2.1").TTL(300).Build(),
+			testutil.MX("@", 10, "MX.example.com."),
+			testutil.TXT("www", "added"),
+		},
+		SourceDNS: {
+			testutil.NS("@", "ns1.attacker.example."),
+		},
+		"unknown": {testutil.A("@", "192.0.2.9")},
+	}
+
+	changes := Changes(before, after)
+	require.Equal(t, []Change{
+		{Source: SourceDNS, HostName: "@", Type: "NS", Before: []string{"dns1.registrar-servers.com"}, After: []string{"ns1.attacker.example"}},
+		{Source: SourceProvider, HostName: "www", Type: "TXT", Before: nil, After: []string{"added"}},
+	}, changes)
+
+	require.Empty(t, Changes(after, after))
+}
+
+func TestWatcher_Run(t *testing.T) {
+	polls := []Snapshot{
+		{SourceProvider: {testutil.A("@", "192.0.2.1")}},
+		nil,
+		{SourceProvider: {testutil.A("@", "192.0.2.2")}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failed []error
+	var events []Event
+	w := &Watcher{
+		Domain:   "example.com",
+		Interval: time.Millisecond,
+		Poll: func(ctx context.Context) (Snapshot, error) {
+			if len(polls) == 0 {
+				return Snapshot{SourceProvider: {testutil.A("@", "192.0.2.2")}}, nil
+			}
+			next := polls[0]
+			polls = polls[1:]
+			if next == nil {
+				return nil, errors.New("rate limited")
+			}
+			return next, nil
+		},
+		Recorded: func(since time.Time) bool { return true },
+		Changed: func(e Event) {
+			events = append(events, e)
+			cancel()
+		},
+		Failed: func(err error) { failed = append(failed, err) },
+	}
+
+	require.NoError(t, w.Run(ctx))
+	require.Len(t, failed, 1)
+	require.Len(t, events, 1)
+	require.Equal(t, "example.com", events[0].Domain)
+	require.True(t, events[0].Recorded)
+	require.Equal(t, []string{"192.0.2.2"}, events[0].Changes[0].After)
+}
+
+func TestWatcher_FirstPollFails(t *testing.T) {
+	w := &Watcher{
+		Interval: time.Millisecond,
+		Poll: func(ctx context.Context) (Snapshot, error) {
+			return nil, errors.New("no credentials")
+		},
+	}
+	require.EqualError(t, w.Run(context.Background()), "no credentials")
+}
+
+func TestParseSources(t *testing.T) {
+	sources, err := ParseSources([]string{"dns,provider", "dns"})
+	require.NoError(t, err)
+	require.Equal(t, []string{SourceDNS, SourceProvider}, sources)
+
+	_, err = ParseSources([]string{"file"})
+	require.ErrorContains(t, err, `unknown source "file"`)
+}
+
+func TestNotify(t *testing.T) {
+	event := Event{Domain: "example.com", Changes: []Change{{Source: SourceProvider, HostName: "@", Type: dnsrecord.RecordTypeA}}}
+
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+	require.NoError(t, PostWebhook(context.Background(), nil, server.URL, event))
+	require.Equal(t, event.Domain, received.Domain)
+
+	out := filepath.Join(t.TempDir(), "event")
+	require.NoError(t, RunCommand(context.Background(), `echo "$ZONEKIT_DOMAIN $ZONEKIT_CHANGES" > `+out, event))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "example.com 1\n", string(data))
+
+	require.ErrorContains(t, RunCommand(context.Background(), "echo broken >&2; exit 3", event), "broken")
+}
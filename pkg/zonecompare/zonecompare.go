@@ -0,0 +1,274 @@
+// Package zonecompare compares two or three views of a zone — the provider's
+// records, live resolver answers and a zone file — record set by record set.
+package zonecompare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// Source names
+const (
+	SourceProvider = "provider"
+	SourceDNS      = "dns"
+	SourceFile     = "file"
+)
+
+// Source is one view of a zone's records
+type Source struct {
+	Name    string
+	Records []dnsrecord.Record
+	// Covers reports whether the source can hold records of a type; nil covers
+	// all types. Record sets of other types are not compared for the source.
+	Covers func(recordType string) bool
+}
+
+func (s Source) covers(recordType string) bool {
+	return s.Covers == nil || s.Covers(recordType)
+}
+
+// Key identifies a record set
+type Key struct {
+	HostName string
+	Type     string
+}
+
+// Row is the comparison of one record set across the sources
+type Row struct {
+	Key
+	// Values holds the normalized values of each source covering the type;
+	// a source without records has an empty list
+	Values map[string][]string
+	Match  bool
+}
+
+// Report is the result of comparing sources
+type Report struct {
+	Domain  string
+	Sources []string
+	Rows    []Row
+}
+
+// Mismatches returns the number of record sets that differ between sources
+func (r *Report) Mismatches() int {
+	n := 0
+	for _, row := range r.Rows {
+		if !row.Match {
+			n++
+		}
+	}
+	return n
+}
+
+// Compare compares the record sets of the sources. TTLs are ignored, as
+// resolvers count them down, and so are the NS records at the apex, which
+// belong to the registrar's delegation rather than the zone's contents.
+func Compare(domainName string, sources ...Source) *Report {
+	report := &Report{Domain: domainName}
+	sets := make(map[Key]map[string][]string)
+
+	for _, source := range sources {
+		report.Sources = append(report.Sources, source.Name)
+		for _, r := range source.Records {
+			key, ok := keyOf(r)
+			if !ok {
+				continue
+			}
+			if sets[key] == nil {
+				sets[key] = make(map[string][]string)
+			}
+			sets[key][source.Name] = append(sets[key][source.Name], Normalize(r))
+		}
+	}
+
+	keys := make([]Key, 0, len(sets))
+	for key := range sets {
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+
+	for _, key := range keys {
+		row := Row{Key: key, Values: make(map[string][]string), Match: true}
+		var first []string
+		compared := 0
+		for _, source := range sources {
+			if !source.covers(key.Type) {
+				continue
+			}
+			values := uniqueSorted(sets[key][source.Name])
+			row.Values[source.Name] = values
+			if compared > 0 && !equal(first, values) {
+				row.Match = false
+			}
+			if compared == 0 {
+				first = values
+			}
+			compared++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	return report
+}
+
+// Resolver answers queries for the records of one type at a host
+type Resolver interface {
+	Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error)
+}
+
+// Resolve queries the resolver for every record set of the given records whose
+// type it supports and returns the answers
+func Resolve(ctx context.Context, resolver Resolver, supports func(recordType string) bool, domainName string, records ...[]dnsrecord.Record) ([]dnsrecord.Record, error) {
+	seen := make(map[Key]bool)
+	var keys []Key
+	for _, list := range records {
+		for _, r := range list {
+			key, ok := keyOf(r)
+			if !ok || seen[key] || !supports(key.Type) {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sortKeys(keys)
+
+	var answers []dnsrecord.Record
+	for _, key := range keys {
+		found, err := resolver.Lookup(ctx, domainName, key.HostName, key.Type)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, found...)
+	}
+	return answers, nil
+}
+
+// keyOf returns the record set of a record; apex NS records are not compared
+func keyOf(r dnsrecord.Record) (Key, bool) {
+	key := Key{HostName: normalizeHost(r.HostName), Type: strings.ToUpper(r.RecordType)}
+	if key.HostName == "@" && key.Type == dnsrecord.RecordTypeNS {
+		return key, false
+	}
+	return key, true
+}
+
+// Normalize returns the value of a record in a form that compares equal across
+// providers, resolvers and zone files: host names in values are lower case
+// without a trailing dot, MX values include the preference, CAA values lose
+// their quotes and hex data is in lower case
+func Normalize(r dnsrecord.Record) string {
+	value := strings.TrimSpace(r.Address)
+	switch strings.ToUpper(r.RecordType) {
+	case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS, dnsrecord.RecordTypePTR:
+		return normalizeName(value)
+	case dnsrecord.RecordTypeMX:
+		return strconv.Itoa(r.MXPref) + " " + normalizeName(value)
+	case dnsrecord.RecordTypeSRV:
+		fields := strings.Fields(value)
+		if len(fields) == 4 {
+			fields[3] = normalizeName(fields[3])
+		}
+		return strings.Join(fields, " ")
+	case dnsrecord.RecordTypeCAA:
+		return strings.ReplaceAll(strings.Join(strings.Fields(value), " "), `"`, "")
+	case dnsrecord.RecordTypeAAAA:
+		return strings.ToLower(value)
+	case dnsrecord.RecordTypeNAPTR:
+		if naptr, err := dnsrecord.ParseNAPTR(value); err == nil {
+			naptr.Replacement = normalizeName(naptr.Replacement)
+			return naptr.String()
+		}
+		return value
+	default:
+		return dnsrecord.CanonicalValue(r.RecordType, value)
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+func normalizeHost(host string) string {
+	if host == "" {
+		return "@"
+	}
+	return normalizeName(host)
+}
+
+func uniqueSorted(values []string) []string {
+	out := []string{}
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].HostName != keys[j].HostName {
+			return keys[i].HostName < keys[j].HostName
+		}
+		return keys[i].Type < keys[j].Type
+	})
+}
+
+// ParseSources parses the sources given with --against. A single source is
+// compared with the provider.
+func ParseSources(values []string) ([]string, error) {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			switch name {
+			case SourceProvider, SourceDNS, SourceFile:
+			case "":
+				continue
+			default:
+				return nil, fmt.Errorf("unknown source %q (use provider, dns or file)", name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				sources = append(sources, name)
+			}
+		}
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, fmt.Errorf("no sources to compare (use provider, dns or file)")
+	case 1:
+		if sources[0] == SourceProvider {
+			return nil, fmt.Errorf("compare the provider against dns or file")
+		}
+		sources = append([]string{SourceProvider}, sources...)
+	}
+
+	// Keep a fixed column order regardless of how the sources were given
+	order := map[string]int{SourceProvider: 0, SourceDNS: 1, SourceFile: 2}
+	sort.Slice(sources, func(i, j int) bool { return order[sources[i]] < order[sources[j]] })
+	return sources, nil
+}
@@ -0,0 +1,138 @@
+package zonecompare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// fakeResolver answers from fixed records and records the queries it receives
+type fakeResolver struct {
+	records []dnsrecord.Record
+	queries []Key
+}
+
+func (f *fakeResolver) Lookup(ctx context.Context, zone, hostname, recordType string) ([]dnsrecord.Record, error) {
+	f.queries = append(f.queries, Key{HostName: hostname, Type: recordType})
+	var found []dnsrecord.Record
+	for _, r := range f.records {
+		if r.HostName == hostname && r.RecordType == recordType {
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+// ZoneCompareTestSuite tests comparing zone sources
+type ZoneCompareTestSuite struct {
+	suite.Suite
+}
+
+func TestZoneCompareSuite(t *testing.T) {
+	suite.Run(t, new(ZoneCompareTestSuite))
+}
+
+func (s *ZoneCompareTestSuite) TestCompare_ThreeWay() {
+	provider := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com", TTL: 1800},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net", MXPref: 10, TTL: 1800},
+		{HostName: "go", RecordType: "URL", Address: "https://example.org", TTL: 1800},
+	}
+	dnsAnswers := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 212},
+		{HostName: "WWW", RecordType: "CNAME", Address: "example.com.", TTL: 1700},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 10, TTL: 300},
+		{HostName: "@", RecordType: "NS", Address: "dns1.registrar-servers.com.", TTL: 300},
+	}
+	file := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 1800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 1800},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 20, TTL: 1800},
+		{HostName: "go", RecordType: "URL", Address: "https://example.org", TTL: 1800},
+	}
+
+	report := Compare("example.com",
+		Source{Name: SourceProvider, Records: provider},
+		Source{Name: SourceDNS, Records: dnsAnswers, Covers: func(t string) bool { return t != "URL" }},
+		Source{Name: SourceFile, Records: file},
+	)
+
+	s.Require().Equal([]string{SourceProvider, SourceDNS, SourceFile}, report.Sources)
+	s.Require().Equal([]Row{
+		{Key: Key{"@", "A"}, Values: map[string][]string{
+			SourceProvider: {"192.0.2.1"}, SourceDNS: {"192.0.2.1"}, SourceFile: {"192.0.2.2"},
+		}},
+		{Key: Key{"@", "MX"}, Values: map[string][]string{
+			SourceProvider: {"10 mx1.example.net"}, SourceDNS: {"10 mx1.example.net"}, SourceFile: {"20 mx1.example.net"},
+		}},
+		// The resolver cannot be asked for URL redirects
+		{Key: Key{"go", "URL"}, Match: true, Values: map[string][]string{
+			SourceProvider: {"https://example.org"}, SourceFile: {"https://example.org"},
+		}},
+		// TTLs, case and trailing dots do not matter
+		{Key: Key{"www", "CNAME"}, Match: true, Values: map[string][]string{
+			SourceProvider: {"example.com"}, SourceDNS: {"example.com"}, SourceFile: {"example.com"},
+		}},
+	}, report.Rows)
+	s.Require().Equal(2, report.Mismatches())
+}
+
+func (s *ZoneCompareTestSuite) TestCompare_MissingRecordSet() {
+	report := Compare("example.com",
+		Source{Name: SourceProvider, Records: []dnsrecord.Record{{HostName: "old", RecordType: "A", Address: "192.0.2.9"}}},
+		Source{Name: SourceFile},
+	)
+	s.Require().Len(report.Rows, 1)
+	s.Require().False(report.Rows[0].Match)
+	s.Require().Equal([]string{}, report.Rows[0].Values[SourceFile])
+}
+
+func (s *ZoneCompareTestSuite) TestNormalize() {
+	s.Require().Equal("0 issue letsencrypt.org", Normalize(dnsrecord.Record{RecordType: "CAA", Address: `0 issue "letsencrypt.org"`}))
+	s.Require().Equal("10 5 443 sip.example.com", Normalize(dnsrecord.Record{RecordType: "SRV", Address: "10 5 443 SIP.example.com."}))
+	s.Require().Equal("2001:db8::1", Normalize(dnsrecord.Record{RecordType: "AAAA", Address: "2001:DB8::1"}))
+	s.Require().Equal("Case Matters", Normalize(dnsrecord.Record{RecordType: "TXT", Address: "Case Matters"}))
+}
+
+func (s *ZoneCompareTestSuite) TestResolve_QueriesEachSupportedRecordSetOnce() {
+	resolver := &fakeResolver{records: []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+	}}
+	provider := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.3"},
+		{HostName: "@", RecordType: "NS", Address: "dns1.registrar-servers.com."},
+		{HostName: "go", RecordType: "URL", Address: "https://example.org"},
+	}
+	file := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2"},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com."},
+	}
+
+	answers, err := Resolve(context.Background(), resolver, func(t string) bool { return t != "URL" }, "example.com", provider, file)
+	s.Require().NoError(err)
+	s.Require().Len(answers, 1)
+	s.Require().Equal([]Key{{"@", "A"}, {"www", "CNAME"}}, resolver.queries)
+}
+
+func (s *ZoneCompareTestSuite) TestParseSources() {
+	sources, err := ParseSources([]string{"file"})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{SourceProvider, SourceFile}, sources)
+
+	sources, err = ParseSources([]string{"file,dns", "provider"})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{SourceProvider, SourceDNS, SourceFile}, sources)
+
+	sources, err = ParseSources([]string{"dns", "file"})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{SourceDNS, SourceFile}, sources)
+
+	_, err = ParseSources([]string{"provider"})
+	s.Require().Error(err)
+	_, err = ParseSources([]string{"whois"})
+	s.Require().ErrorContains(err, "unknown source")
+}
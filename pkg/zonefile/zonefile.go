@@ -0,0 +1,432 @@
+// Package zonefile parses DNS zone files in the BIND master file format
+// (RFC 1035 section 5) into records.
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// ParseError reports a problem on a line of a zone file
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// token is a word of a zone file; quoted tokens are the contents of a
+// character string with escapes resolved
+type token struct {
+	text   string
+	quoted bool
+}
+
+// entry is a logical line of a zone file: a directive or a resource record,
+// which may span several physical lines inside parentheses
+type entry struct {
+	line   int
+	tokens []token
+	// inherit is set when the entry starts with whitespace, so the record uses
+	// the owner name of the previous record
+	inherit bool
+}
+
+// Options adjust parsing to the quirks of zone files exported by providers
+type Options struct {
+	// QualifyZoneNames treats names ending in the zone as fully qualified even
+	// without a trailing dot, as some exports write them without $ORIGIN
+	QualifyZoneNames bool
+}
+
+// ParseFile parses the zone file at path; see Parse
+func ParseFile(path, zone string) ([]dnsrecord.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zone file: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f, zone)
+}
+
+// Parse reads a zone file for zone and returns its records, with host names
+// relative to the zone ("@" for the apex) and target names fully qualified with
+// a trailing dot. $ORIGIN and $TTL are supported; the SOA record is skipped as
+// providers manage it. Records without a TTL use the $TTL default, or 0 (the
+// provider's default) when there is none.
+func Parse(r io.Reader, zone string) ([]dnsrecord.Record, error) {
+	return ParseWithOptions(r, zone, Options{})
+}
+
+// ParseWithOptions parses a zone file like Parse, adjusted by options
+func ParseWithOptions(r io.Reader, zone string, options Options) ([]dnsrecord.Record, error) {
+	entries, err := scan(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{zone: fqdn(zone), origin: fqdn(zone), options: options}
+	var records []dnsrecord.Record
+	for _, e := range entries {
+		record, ok, err := p.parseEntry(e)
+		if err != nil {
+			return nil, &ParseError{Line: e.line, Err: err}
+		}
+		if ok {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// parser holds the state carried between entries
+type parser struct {
+	options    Options
+	zone       string
+	origin     string
+	defaultTTL int
+	owner      string
+}
+
+// parseEntry handles a directive or a record; ok is false for entries that do
+// not produce a record
+func (p *parser) parseEntry(e entry) (dnsrecord.Record, bool, error) {
+	tokens := e.tokens
+	if !e.inherit && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "$") {
+		return dnsrecord.Record{}, false, p.parseDirective(tokens)
+	}
+
+	if !e.inherit {
+		p.owner = p.absolute(tokens[0].text)
+		tokens = tokens[1:]
+	} else if p.owner == "" {
+		return dnsrecord.Record{}, false, fmt.Errorf("record has no owner name")
+	}
+
+	host, err := p.relative(p.owner)
+	if err != nil {
+		return dnsrecord.Record{}, false, err
+	}
+
+	// The TTL and class are optional and may appear in either order
+	ttl, hasTTL := 0, false
+	for len(tokens) > 0 {
+		text := strings.ToUpper(tokens[0].text)
+		if text == "IN" {
+			tokens = tokens[1:]
+			continue
+		}
+		if text == "CH" || text == "HS" || text == "CS" {
+			return dnsrecord.Record{}, false, fmt.Errorf("unsupported class %s", text)
+		}
+		if !hasTTL && text != "" && text[0] >= '0' && text[0] <= '9' {
+			if ttl, err = dnsrecord.ParseTTL(text); err != nil {
+				return dnsrecord.Record{}, false, err
+			}
+			hasTTL = true
+			tokens = tokens[1:]
+			continue
+		}
+		break
+	}
+	if len(tokens) == 0 {
+		return dnsrecord.Record{}, false, fmt.Errorf("record has no type")
+	}
+	if !hasTTL {
+		ttl = p.defaultTTL
+	}
+
+	recordType := strings.ToUpper(tokens[0].text)
+	record := dnsrecord.Record{HostName: host, RecordType: recordType, TTL: ttl}
+	if err := p.parseData(&record, tokens[1:]); err != nil {
+		return dnsrecord.Record{}, false, fmt.Errorf("%s record %s: %w", recordType, host, err)
+	}
+	if recordType == "SOA" {
+		return dnsrecord.Record{}, false, nil
+	}
+
+	return record, true, nil
+}
+
+// parseDirective handles $ORIGIN and $TTL
+func (p *parser) parseDirective(tokens []token) error {
+	directive := strings.ToUpper(tokens[0].text)
+	switch directive {
+	case "$ORIGIN":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$ORIGIN requires a domain name")
+		}
+		p.origin = p.absolute(tokens[1].text)
+	case "$TTL":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$TTL requires a value")
+		}
+		ttl, err := dnsrecord.ParseTTL(tokens[1].text)
+		if err != nil {
+			return err
+		}
+		p.defaultTTL = ttl
+	case "$INCLUDE":
+		return fmt.Errorf("$INCLUDE is not supported")
+	default:
+		return fmt.Errorf("unknown directive %s", directive)
+	}
+	return nil
+}
+
+// parseData fills in the address (and MX preference) of a record from its data
+func (p *parser) parseData(record *dnsrecord.Record, data []token) error {
+	switch record.RecordType {
+	case dnsrecord.RecordTypeA, dnsrecord.RecordTypeAAAA:
+		if len(data) != 1 {
+			return fmt.Errorf("expected an address")
+		}
+		ip := net.ParseIP(data[0].text)
+		isIPv4 := ip != nil && ip.To4() != nil && !strings.Contains(data[0].text, ":")
+		if ip == nil || isIPv4 != (record.RecordType == dnsrecord.RecordTypeA) {
+			return fmt.Errorf("invalid address %q", data[0].text)
+		}
+		record.Address = data[0].text
+	case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS, dnsrecord.RecordTypePTR:
+		if len(data) != 1 {
+			return fmt.Errorf("expected a domain name")
+		}
+		record.Address = p.absolute(data[0].text)
+	case dnsrecord.RecordTypeMX:
+		if len(data) != 2 {
+			return fmt.Errorf("expected a preference and a domain name")
+		}
+		pref, err := parseUint16(data[0].text, "preference")
+		if err != nil {
+			return err
+		}
+		record.MXPref = pref
+		record.Address = p.absolute(data[1].text)
+	case dnsrecord.RecordTypeTXT:
+		if len(data) == 0 {
+			return fmt.Errorf("expected at least one string")
+		}
+		var sb strings.Builder
+		for _, t := range data {
+			sb.WriteString(t.text)
+		}
+		record.Address = sb.String()
+	case dnsrecord.RecordTypeSRV:
+		if len(data) != 4 {
+			return fmt.Errorf("expected priority, weight, port and target")
+		}
+		fields := make([]string, 0, 4)
+		for i, name := range []string{"priority", "weight", "port"} {
+			n, err := parseUint16(data[i].text, name)
+			if err != nil {
+				return err
+			}
+			fields = append(fields, strconv.Itoa(n))
+		}
+		record.Address = strings.Join(append(fields, p.absolute(data[3].text)), " ")
+	case dnsrecord.RecordTypeCAA:
+		if len(data) != 3 {
+			return fmt.Errorf("expected flags, tag and value")
+		}
+		flags, err := strconv.ParseUint(data[0].text, 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid flags %q", data[0].text)
+		}
+		record.Address = fmt.Sprintf("%d %s %q", flags, strings.ToLower(data[1].text), data[2].text)
+	case dnsrecord.RecordTypeTLSA, dnsrecord.RecordTypeSSHFP, dnsrecord.RecordTypeDS:
+		// Hex data may be split into several fields
+		fields := make([]string, 0, len(data))
+		for _, t := range data {
+			fields = append(fields, t.text)
+		}
+		value, err := dnsrecord.ParseValue(record.RecordType, strings.Join(fields, " "))
+		if err != nil {
+			return err
+		}
+		record.Address = value
+	case dnsrecord.RecordTypeNAPTR:
+		if len(data) != 6 {
+			return fmt.Errorf(`expected order, preference, "flags", "service", "regexp" and replacement`)
+		}
+		naptr := dnsrecord.NAPTR{Flags: data[2].text, Service: data[3].text, Regexp: data[4].text, Replacement: data[5].text}
+		if naptr.Replacement != "." {
+			naptr.Replacement = p.absolute(naptr.Replacement)
+		}
+		var err error
+		if naptr.Order, err = parseUint16(data[0].text, "order"); err != nil {
+			return err
+		}
+		if naptr.Preference, err = parseUint16(data[1].text, "preference"); err != nil {
+			return err
+		}
+		if record.Address, err = dnsrecord.ParseValue(record.RecordType, naptr.String()); err != nil {
+			return err
+		}
+	case "SOA":
+		if len(data) != 7 {
+			return fmt.Errorf("expected 7 fields")
+		}
+	default:
+		return fmt.Errorf("unsupported record type")
+	}
+	return nil
+}
+
+// absolute returns name as a fully qualified name with a trailing dot,
+// resolving relative names and "@" against the current origin
+func (p *parser) absolute(name string) string {
+	switch {
+	case name == "@":
+		return p.origin
+	case strings.HasSuffix(name, "."):
+		return strings.ToLower(name)
+	case p.options.QualifyZoneNames && (strings.EqualFold(name+".", p.zone) || strings.HasSuffix(strings.ToLower(name)+".", "."+p.zone)):
+		return strings.ToLower(name) + "."
+	default:
+		return strings.ToLower(name) + "." + p.origin
+	}
+}
+
+// relative returns the host name of a fully qualified name within the zone
+func (p *parser) relative(name string) (string, error) {
+	if name == p.zone {
+		return "@", nil
+	}
+	if strings.HasSuffix(name, "."+p.zone) {
+		return strings.TrimSuffix(name, "."+p.zone), nil
+	}
+	return "", fmt.Errorf("name %s is outside the zone %s", name, strings.TrimSuffix(p.zone, "."))
+}
+
+// scan splits a zone file into entries, removing comments and joining lines
+// inside parentheses
+func scan(r io.Reader) ([]entry, error) {
+	var entries []entry
+	var current *entry
+	depth := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if depth == 0 {
+			current = &entry{line: lineNo, inherit: line != "" && (line[0] == ' ' || line[0] == '\t')}
+		}
+
+		for i := 0; i < len(line); {
+			c := line[i]
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				i++
+			case c == ';':
+				i = len(line)
+			case c == '(':
+				depth++
+				i++
+			case c == ')':
+				if depth == 0 {
+					return nil, &ParseError{Line: lineNo, Err: fmt.Errorf("unbalanced parentheses")}
+				}
+				depth--
+				i++
+			case c == '"':
+				text, n, err := scanQuoted(line[i+1:])
+				if err != nil {
+					return nil, &ParseError{Line: lineNo, Err: err}
+				}
+				current.tokens = append(current.tokens, token{text: text, quoted: true})
+				i += n + 1
+			default:
+				start := i
+				for i < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[i])) {
+					i++
+				}
+				current.tokens = append(current.tokens, token{text: line[start:i]})
+			}
+		}
+
+		if depth == 0 && len(current.tokens) > 0 {
+			entries = append(entries, *current)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read zone file: %w", err)
+	}
+	if depth != 0 {
+		return nil, &ParseError{Line: current.line, Err: fmt.Errorf("unclosed parenthesis")}
+	}
+
+	return entries, nil
+}
+
+// scanQuoted reads a character string up to its closing quote, resolving \X
+// and \DDD escapes. It returns the text and the number of bytes consumed,
+// including the closing quote.
+func scanQuoted(s string) (string, int, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+3 < len(s) && isDigits(s[i+1:i+4]) {
+				n, _ := strconv.Atoi(s[i+1 : i+4])
+				if n > 255 {
+					return "", 0, fmt.Errorf("invalid escape \\%s", s[i+1:i+4])
+				}
+				sb.WriteByte(byte(n))
+				i += 3
+			} else if i+1 < len(s) {
+				sb.WriteByte(s[i+1])
+				i++
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseUint16(value, name string) (int, error) {
+	n, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return int(n), nil
+}
+
+// fqdn returns name in lower case with a trailing dot
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
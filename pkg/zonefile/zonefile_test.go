@@ -0,0 +1,129 @@
+package zonefile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"zonekit/pkg/dnsrecord"
+)
+
+// ZoneFileTestSuite is a test suite for zone file parsing
+type ZoneFileTestSuite struct {
+	suite.Suite
+}
+
+// TestZoneFileSuite runs the zone file test suite
+func TestZoneFileSuite(t *testing.T) {
+	suite.Run(t, new(ZoneFileTestSuite))
+}
+
+const exampleZone = `$ORIGIN example.com.
+$TTL 1h
+@   IN SOA ns1.example.com. hostmaster.example.com. (
+        2024010101 ; serial
+        3600       ; refresh
+        900        ; retry
+        1209600    ; expire
+        300 )      ; minimum
+
+@           IN  NS    ns1.example.net.
+@           IN  A     192.0.2.1
+            IN  AAAA  2001:db8::1
+www     300 IN  CNAME @
+mail        IN  MX    10 mx1
+            IN  MX    20 mx2.example.net.
+@           IN  TXT   "v=spf1 include:_spf.example.net ~all"
+long        IN  TXT   ( "first part "
+                        "second \"part\"" )
+_sip._tcp   IN  SRV   10 60 5060 sip
+@           IN  CAA   0 issue "letsencrypt.org"
+1           IN  PTR   host
+_443._tcp.www IN TLSA 3 1 1 ( 0123456789ABCDEF0123456789ABCDEF
+                              0123456789abcdef0123456789abcdef )
+host        IN  SSHFP 4 2 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+sub         IN  DS    12345 13 2 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+@           IN  NAPTR 100 10 "S" "SIP+D2U" "" _sip._udp
+
+$ORIGIN dev.example.com.
+api     IN 1d A 192.0.2.10
+`
+
+func (s *ZoneFileTestSuite) TestParse() {
+	records, err := Parse(strings.NewReader(exampleZone), "example.com")
+	s.Require().NoError(err)
+
+	s.Require().Equal([]dnsrecord.Record{
+		{HostName: "@", RecordType: "NS", Address: "ns1.example.net.", TTL: 3600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "@", RecordType: "AAAA", Address: "2001:db8::1", TTL: 3600},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+		{HostName: "mail", RecordType: "MX", Address: "mx1.example.com.", TTL: 3600, MXPref: 10},
+		{HostName: "mail", RecordType: "MX", Address: "mx2.example.net.", TTL: 3600, MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 include:_spf.example.net ~all", TTL: 3600},
+		{HostName: "long", RecordType: "TXT", Address: `first part second "part"`, TTL: 3600},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 60 5060 sip.example.com.", TTL: 3600},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 3600},
+		{HostName: "1", RecordType: "PTR", Address: "host.example.com.", TTL: 3600},
+		{HostName: "_443._tcp.www", RecordType: "TLSA", Address: "3 1 1 " + strings.Repeat("0123456789abcdef", 4), TTL: 3600},
+		{HostName: "host", RecordType: "SSHFP", Address: "4 2 " + strings.Repeat("0123456789abcdef", 4), TTL: 3600},
+		{HostName: "sub", RecordType: "DS", Address: "12345 13 2 " + strings.Repeat("0123456789abcdef", 4), TTL: 3600},
+		{HostName: "@", RecordType: "NAPTR", Address: `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`, TTL: 3600},
+		{HostName: "api.dev", RecordType: "A", Address: "192.0.2.10", TTL: 86400},
+	}, records)
+}
+
+func (s *ZoneFileTestSuite) TestParseWithOptions_QualifyZoneNames() {
+	zone := "example.com 3600 IN A 192.0.2.1\nwww.example.com 300 IN CNAME example.com\nftp 300 IN CNAME www\n"
+
+	records, err := ParseWithOptions(strings.NewReader(zone), "example.com", Options{QualifyZoneNames: true})
+	s.Require().NoError(err)
+	s.Require().Equal([]dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+		{HostName: "ftp", RecordType: "CNAME", Address: "www.example.com.", TTL: 300},
+	}, records)
+
+	// Without the option the names are relative to the origin
+	records, err = Parse(strings.NewReader(zone), "example.com")
+	s.Require().NoError(err)
+	s.Require().Equal("example.com", records[0].HostName)
+}
+
+func (s *ZoneFileTestSuite) TestParse_NoDefaultTTL() {
+	records, err := Parse(strings.NewReader("www.example.com. IN A 192.0.2.1\n"), "example.com.")
+	s.Require().NoError(err)
+	s.Require().Equal([]dnsrecord.Record{{HostName: "www", RecordType: "A", Address: "192.0.2.1"}}, records)
+}
+
+func (s *ZoneFileTestSuite) TestParse_Errors() {
+	tests := []struct {
+		name string
+		zone string
+		line int
+		msg  string
+	}{
+		{"outside zone", "www.example.org. IN A 192.0.2.1", 1, "outside the zone"},
+		{"invalid address", "www IN A 2001:db8::1", 1, "invalid address"},
+		{"unsupported type", "www IN HINFO cpu os", 1, "unsupported record type"},
+		{"short DS digest", "sub IN DS 12345 13 2 0123", 1, "expected 32 bytes"},
+		{"NAPTR without replacement", `@ IN NAPTR 100 10 "S" "SIP+D2U" "" .`, 1, "give either a regexp or a replacement"},
+		{"include", "$INCLUDE other.zone", 1, "$INCLUDE is not supported"},
+		{"unclosed parenthesis", "\nwww IN TXT ( \"a\"\n", 2, "unclosed parenthesis"},
+		{"unterminated string", "www IN TXT \"a", 1, "unterminated quoted string"},
+		{"no owner", "   IN A 192.0.2.1", 1, "no owner name"},
+		{"bad MX preference", "@ IN MX high mx1", 1, "invalid preference"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := Parse(strings.NewReader(tt.zone), "example.com")
+			s.Require().ErrorContains(err, tt.msg)
+
+			var parseErr *ParseError
+			s.Require().True(errors.As(err, &parseErr))
+			s.Require().Equal(tt.line, parseErr.Line)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package zoneimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+)
+
+// digitalOceanRecord is a record of the DigitalOcean domain records API
+type digitalOceanRecord struct {
+	Type     string  `json:"type"`
+	Name     string  `json:"name"`
+	Data     *string `json:"data"`
+	Priority *int    `json:"priority"`
+	Port     *int    `json:"port"`
+	Weight   *int    `json:"weight"`
+	Flags    *int    `json:"flags"`
+	Tag      string  `json:"tag"`
+	TTL      int     `json:"ttl"`
+}
+
+// digitalOceanRecords decodes a record list, either bare as doctl writes it or
+// wrapped in domain_records as the API returns it
+func digitalOceanRecords(data []byte) ([]digitalOceanRecord, error) {
+	var records []digitalOceanRecord
+	if err := json.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+	var page struct {
+		DomainRecords []digitalOceanRecord `json:"domain_records"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+	return page.DomainRecords, nil
+}
+
+// isDigitalOcean reports whether JSON data is a DigitalOcean record list
+func isDigitalOcean(data []byte) bool {
+	records, err := digitalOceanRecords(data)
+	if err != nil || len(records) == 0 {
+		return false
+	}
+	return records[0].Type != "" && records[0].Data != nil
+}
+
+// parseDigitalOcean converts a DigitalOcean record list. The SOA record is
+// skipped as providers manage it.
+func parseDigitalOcean(data []byte, zone string) ([]dnsrecord.Record, error) {
+	list, err := digitalOceanRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dnsrecord.Record, 0, len(list))
+	for i, r := range list {
+		recordType := strings.ToUpper(r.Type)
+		if recordType == "SOA" {
+			continue
+		}
+		if r.Data == nil {
+			return nil, fmt.Errorf("record %d: %s record %s has no data", i+1, recordType, r.Name)
+		}
+
+		record := dnsrecord.Record{HostName: relative(r.Name, zone), RecordType: recordType, Address: *r.Data, TTL: r.TTL}
+		switch recordType {
+		case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS, dnsrecord.RecordTypePTR:
+			record.Address = absolute(record.Address, zone)
+		case dnsrecord.RecordTypeMX:
+			record.Address = absolute(record.Address, zone)
+			record.MXPref = value(r.Priority)
+		case dnsrecord.RecordTypeSRV:
+			record.Address = fmt.Sprintf("%d %d %d %s", value(r.Priority), value(r.Weight), value(r.Port), absolute(record.Address, zone))
+		case dnsrecord.RecordTypeCAA:
+			record.Address = fmt.Sprintf("%d %s %s", value(r.Flags), strings.ToLower(r.Tag), strconv.Quote(record.Address))
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// value returns the number n points to, or 0
+func value(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,163 @@
+package zoneimport
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+
+	"gopkg.in/yaml.v3"
+)
+
+// octoRecord is a record of an OctoDNS zone file; it holds one value or a
+// list of values
+type octoRecord struct {
+	Type   string    `yaml:"type"`
+	TTL    int       `yaml:"ttl"`
+	Value  yaml.Node `yaml:"value"`
+	Values yaml.Node `yaml:"values"`
+}
+
+// octoValue holds the fields of the structured values of MX, SRV and CAA
+// records; simple values are plain strings
+type octoValue struct {
+	Preference *int   `yaml:"preference"`
+	Exchange   string `yaml:"exchange"`
+	Priority   int    `yaml:"priority"`
+	Weight     int    `yaml:"weight"`
+	Port       int    `yaml:"port"`
+	Target     string `yaml:"target"`
+	Flags      int    `yaml:"flags"`
+	Tag        string `yaml:"tag"`
+	Value      string `yaml:"value"`
+}
+
+// isOctoDNS reports whether data is a YAML mapping of names to records, or
+// lists of records, with a type
+func isOctoDNS(data []byte) bool {
+	root := yamlDocument(data)
+	if root == nil || root.Kind != yaml.MappingNode || len(root.Content) == 0 {
+		return false
+	}
+	for i := 1; i < len(root.Content); i += 2 {
+		entries := []*yaml.Node{root.Content[i]}
+		if root.Content[i].Kind == yaml.SequenceNode {
+			entries = root.Content[i].Content
+		}
+		for _, entry := range entries {
+			var r octoRecord
+			if entry.Kind != yaml.MappingNode || entry.Decode(&r) != nil || r.Type == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// yamlDocument decodes YAML data into a node, or returns nil
+func yamlDocument(data []byte) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// parseOctoDNS converts an OctoDNS zone file. Names are relative to the zone
+// with "" for the apex; records are returned sorted by name.
+func parseOctoDNS(data []byte, zone string) ([]dnsrecord.Record, error) {
+	var file map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(file))
+	for name := range file {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var records []dnsrecord.Record
+	for _, name := range names {
+		node := file[name]
+		var list []octoRecord
+		if node.Kind == yaml.SequenceNode {
+			if err := node.Decode(&list); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+		} else {
+			var r octoRecord
+			if err := node.Decode(&r); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			list = append(list, r)
+		}
+
+		for _, r := range list {
+			converted, err := octoRecords(relative(name, zone), zone, r)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			records = append(records, converted...)
+		}
+	}
+	return records, nil
+}
+
+// octoRecords returns a record for each value of an OctoDNS record
+func octoRecords(host, zone string, r octoRecord) ([]dnsrecord.Record, error) {
+	recordType := strings.ToUpper(r.Type)
+	values := []*yaml.Node{&r.Value}
+	switch {
+	case r.Values.Kind == yaml.SequenceNode:
+		values = r.Values.Content
+	case r.Values.Kind != 0:
+		values = []*yaml.Node{&r.Values}
+	case r.Value.Kind == 0:
+		return nil, fmt.Errorf("%s record %s has no value", recordType, host)
+	}
+
+	records := make([]dnsrecord.Record, 0, len(values))
+	for _, node := range values {
+		record := dnsrecord.Record{HostName: host, RecordType: recordType, TTL: r.TTL}
+		if node.Kind == yaml.ScalarNode {
+			record.Address = node.Value
+			switch recordType {
+			case dnsrecord.RecordTypeCNAME, dnsrecord.RecordTypeNS, dnsrecord.RecordTypePTR, "ALIAS":
+				record.Address = absolute(record.Address, zone)
+			case dnsrecord.RecordTypeTXT, "SPF":
+				// OctoDNS escapes semicolons in TXT values
+				record.Address = strings.ReplaceAll(record.Address, `\;`, ";")
+			}
+			records = append(records, record)
+			continue
+		}
+
+		var v octoValue
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%s record %s: %w", recordType, host, err)
+		}
+		switch recordType {
+		case dnsrecord.RecordTypeMX:
+			// Older OctoDNS versions write priority and value
+			record.MXPref, record.Address = v.Priority, v.Value
+			if v.Preference != nil {
+				record.MXPref = *v.Preference
+			}
+			if v.Exchange != "" {
+				record.Address = v.Exchange
+			}
+			record.Address = absolute(record.Address, zone)
+		case dnsrecord.RecordTypeSRV:
+			record.Address = fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, absolute(v.Target, zone))
+		case dnsrecord.RecordTypeCAA:
+			record.Address = fmt.Sprintf("%d %s %s", v.Flags, strings.ToLower(v.Tag), strconv.Quote(v.Value))
+		default:
+			return nil, fmt.Errorf("%s record %s: structured values are not supported for %s records", recordType, host, recordType)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
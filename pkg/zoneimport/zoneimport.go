@@ -0,0 +1,174 @@
+// Package zoneimport reads the zone exports of other DNS providers and tools,
+// so a zone can be migrated without editing the export by hand. The format is
+// detected from the content: BIND zone files, Cloudflare's BIND export,
+// DigitalOcean record exports and OctoDNS YAML zone files.
+//
+// Records are returned like zonefile.Parse returns them: host names relative
+// to the zone ("@" for the apex), record types in upper case and target names
+// fully qualified with a trailing dot.
+package zoneimport
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+	"zonekit/pkg/zonefile"
+)
+
+// Format is the format of an export
+type Format string
+
+const (
+	// FormatAuto detects the format from the content
+	FormatAuto Format = "auto"
+	// FormatBIND is a BIND zone file (RFC 1035 master file)
+	FormatBIND Format = "bind"
+	// FormatCloudflare is a zone file exported from the Cloudflare dashboard
+	// or API: names without $ORIGIN and TTL 1 for automatic TTLs
+	FormatCloudflare Format = "cloudflare"
+	// FormatDigitalOcean is the JSON record list of the DigitalOcean API or
+	// doctl compute domain records list -o json
+	FormatDigitalOcean Format = "digitalocean"
+	// FormatOctoDNS is an OctoDNS YAML zone file
+	FormatOctoDNS Format = "octodns"
+)
+
+// cloudflareAutoTTL is the TTL Cloudflare exports for records with an
+// automatic TTL
+const cloudflareAutoTTL = 1
+
+// Formats returns the formats that can be selected explicitly
+func Formats() []Format {
+	return []Format{FormatBIND, FormatCloudflare, FormatDigitalOcean, FormatOctoDNS}
+}
+
+// ParseFormat parses a format name; an empty name is FormatAuto
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "":
+		return FormatAuto, nil
+	case FormatAuto, FormatBIND, FormatCloudflare, FormatDigitalOcean, FormatOctoDNS:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid import format %q (must be auto, bind, cloudflare, digitalocean or octodns)", value)
+	}
+}
+
+// Description returns a human-readable name of the format
+func (f Format) Description() string {
+	switch f {
+	case FormatCloudflare:
+		return "Cloudflare zone export"
+	case FormatDigitalOcean:
+		return "DigitalOcean record export"
+	case FormatOctoDNS:
+		return "OctoDNS YAML"
+	default:
+		return "BIND zone file"
+	}
+}
+
+// Detect returns the format of data. Content that is not recognized as
+// another format is a BIND zone file.
+func Detect(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') && isDigitalOcean(trimmed) {
+		return FormatDigitalOcean
+	}
+	if isOctoDNS(data) {
+		return FormatOctoDNS
+	}
+	if isCloudflare(data) {
+		return FormatCloudflare
+	}
+	return FormatBIND
+}
+
+// ParseFile reads the export at path for zone; see Parse
+func ParseFile(path, zone string, format Format) ([]dnsrecord.Record, Format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Parse(data, zone, format)
+}
+
+// Parse reads an export of zone in format, detecting the format with
+// FormatAuto. It returns the records and the format they were read as.
+func Parse(data []byte, zone string, format Format) ([]dnsrecord.Record, Format, error) {
+	if format == FormatAuto || format == "" {
+		format = Detect(data)
+	}
+
+	var records []dnsrecord.Record
+	var err error
+	switch format {
+	case FormatBIND:
+		records, err = zonefile.Parse(bytes.NewReader(data), zone)
+	case FormatCloudflare:
+		records, err = parseCloudflare(data, zone)
+	case FormatDigitalOcean:
+		records, err = parseDigitalOcean(data, zone)
+	case FormatOctoDNS:
+		records, err = parseOctoDNS(data, zone)
+	default:
+		err = fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to parse %s: %w", format.Description(), err)
+	}
+	return records, format, nil
+}
+
+// isCloudflare reports whether a zone file carries the header or the record
+// tags of a Cloudflare export
+func isCloudflare(data []byte) bool {
+	return bytes.Contains(data, []byte(";; Domain:")) && bytes.Contains(data, []byte(";; Exported:")) ||
+		bytes.Contains(data, []byte("cf_tags="))
+}
+
+// parseCloudflare parses a Cloudflare export. Names are written fully
+// qualified, sometimes without the trailing dot, and TTL 1 stands for an
+// automatic TTL, which becomes the provider's default.
+func parseCloudflare(data []byte, zone string) ([]dnsrecord.Record, error) {
+	records, err := zonefile.ParseWithOptions(bytes.NewReader(data), zone, zonefile.Options{QualifyZoneNames: true})
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].TTL == cloudflareAutoTTL {
+			records[i].TTL = 0
+		}
+	}
+	return records, nil
+}
+
+// absolute returns a target name of zone fully qualified with a trailing dot;
+// "@" is the apex and names without a trailing dot are relative to the zone
+func absolute(name, zone string) string {
+	zone = strings.ToLower(strings.TrimSuffix(zone, ".")) + "."
+	switch {
+	case name == "@" || name == "":
+		return zone
+	case strings.HasSuffix(name, "."):
+		return strings.ToLower(name)
+	default:
+		return strings.ToLower(name) + "." + zone
+	}
+}
+
+// relative returns a host name relative to zone, "@" for the apex
+func relative(name, zone string) string {
+	name = strings.TrimSuffix(name, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	switch {
+	case name == "" || name == "@" || strings.EqualFold(name, zone):
+		return "@"
+	case strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(zone)):
+		return name[:len(name)-len(zone)-1]
+	}
+	return name
+}
@@ -0,0 +1,148 @@
+package zoneimport
+
+import (
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+)
+
+const cloudflareExport = `;;
+;; Domain:     example.com.
+;; Exported:   2026-10-01 12:00:00
+;;
+;; This file is intended for use for informational and archival
+;; purposes ONLY and MUST be edited before use on a production
+;; DNS server.
+;;
+
+;; SOA Record
+example.com	3600	IN	SOA	amy.ns.cloudflare.com. dns.cloudflare.com. 2048 10000 2400 604800 3600
+
+;; NS Records
+example.com.	86400	IN	NS	amy.ns.cloudflare.com.
+
+;; A Records
+example.com.	1	IN	A	192.0.2.1 ; cf_tags=cf-proxied:true
+api.example.com.	300	IN	A	192.0.2.2
+
+;; CNAME Records
+www.example.com.	1	IN	CNAME	example.com. ; cf_tags=cf-proxied:true
+
+;; TXT Records
+example.com.	1	IN	TXT	"v=spf1 -all"
+`
+
+const digitalOceanExport = `{"domain_records":[
+	{"id":1,"type":"SOA","name":"@","data":"1800","priority":null,"port":null,"ttl":1800,"weight":null,"flags":null,"tag":null},
+	{"id":2,"type":"A","name":"@","data":"192.0.2.1","priority":null,"port":null,"ttl":3600,"weight":null,"flags":null,"tag":null},
+	{"id":3,"type":"CNAME","name":"www","data":"@","priority":null,"port":null,"ttl":43200,"weight":null,"flags":null,"tag":null},
+	{"id":4,"type":"MX","name":"@","data":"mx.example.net.","priority":10,"port":null,"ttl":14400,"weight":null,"flags":null,"tag":null},
+	{"id":5,"type":"SRV","name":"_sip._tcp","data":"sip","priority":10,"port":5060,"ttl":1800,"weight":60,"flags":null,"tag":null},
+	{"id":6,"type":"CAA","name":"@","data":"letsencrypt.org","priority":null,"port":null,"ttl":3600,"weight":null,"flags":0,"tag":"issue"}
+],"links":{},"meta":{"total":6}}`
+
+const octoDNSExport = `---
+'':
+  - type: A
+    ttl: 600
+    values:
+      - 192.0.2.1
+      - 192.0.2.2
+  - type: MX
+    values:
+      - exchange: mx1.example.net.
+        preference: 10
+      - priority: 20
+        value: mx2.example.net.
+  - type: TXT
+    value: v=DKIM1\; k=rsa
+  - type: CAA
+    value:
+      flags: 0
+      tag: issue
+      value: letsencrypt.org
+_sip._tcp:
+  type: SRV
+  value:
+    port: 5060
+    priority: 10
+    target: sip.example.com.
+    weight: 60
+www:
+  type: CNAME
+  ttl: 300
+  value: example.com.
+`
+
+func TestDetect(t *testing.T) {
+	require.Equal(t, FormatCloudflare, Detect([]byte(cloudflareExport)))
+	require.Equal(t, FormatDigitalOcean, Detect([]byte(digitalOceanExport)))
+	require.Equal(t, FormatOctoDNS, Detect([]byte(octoDNSExport)))
+	require.Equal(t, FormatBIND, Detect([]byte("$ORIGIN example.com.\n@ 3600 IN A 192.0.2.1\nwww IN AAAA 2001:db8::1\n")))
+	require.Equal(t, FormatBIND, Detect([]byte("www: IN A 192.0.2.1\n")))
+}
+
+func TestParse_Cloudflare(t *testing.T) {
+	records, format, err := Parse([]byte(cloudflareExport), "example.com", FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatCloudflare, format)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "NS", Address: "amy.ns.cloudflare.com.", TTL: 86400},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1"},
+		{HostName: "api", RecordType: "A", Address: "192.0.2.2", TTL: 300},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com."},
+		{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all"},
+	}, records)
+
+	// Read as a plain zone file, the automatic TTL is taken as 1 second
+	records, _, err = Parse([]byte(cloudflareExport), "example.com", FormatBIND)
+	require.NoError(t, err)
+	require.Equal(t, 1, records[1].TTL)
+}
+
+func TestParse_DigitalOcean(t *testing.T) {
+	records, format, err := Parse([]byte(digitalOceanExport), "example.com", FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatDigitalOcean, format)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 3600},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 43200},
+		{HostName: "@", RecordType: "MX", Address: "mx.example.net.", TTL: 14400, MXPref: 10},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 60 5060 sip.example.com.", TTL: 1800},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`, TTL: 3600},
+	}, records)
+}
+
+func TestParse_OctoDNS(t *testing.T) {
+	records, format, err := Parse([]byte(octoDNSExport), "example.com", FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatOctoDNS, format)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 600},
+		{HostName: "@", RecordType: "A", Address: "192.0.2.2", TTL: 600},
+		{HostName: "@", RecordType: "MX", Address: "mx1.example.net.", MXPref: 10},
+		{HostName: "@", RecordType: "MX", Address: "mx2.example.net.", MXPref: 20},
+		{HostName: "@", RecordType: "TXT", Address: "v=DKIM1; k=rsa"},
+		{HostName: "@", RecordType: "CAA", Address: `0 issue "letsencrypt.org"`},
+		{HostName: "_sip._tcp", RecordType: "SRV", Address: "10 60 5060 sip.example.com."},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+	}, records)
+
+	_, _, err = Parse([]byte("www:\n  type: SSHFP\n  value:\n    algorithm: 1\n"), "example.com", FormatOctoDNS)
+	require.ErrorContains(t, err, "structured values are not supported")
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	require.Equal(t, FormatAuto, format)
+
+	format, err = ParseFormat("OctoDNS")
+	require.NoError(t, err)
+	require.Equal(t, FormatOctoDNS, format)
+
+	_, err = ParseFormat("route53")
+	require.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Package zonestate reads desired-state files: the complete set of records a
+// zone should have, written in YAML or JSON, e.g.
+//
+//	domain: example.com
+//	ttl: 30m
+//	records:
+//	  - hostname: "@"
+//	    type: A
+//	    value: 192.0.2.1
+//	  - hostname: mail
+//	    type: MX
+//	    value: mx.example.com
+//	    mx_pref: 10
+//	    ttl: 1h
+//
+// The file may also be a bare list of records. FromRecords builds the file for
+// the records of a zone, which dns export writes as JSON or YAML.
+package zonestate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"zonekit/pkg/dnsrecord"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the top-level form of a desired-state file
+type File struct {
+	// Domain, if set, must be the domain the file is applied to
+	Domain string `yaml:"domain,omitempty" json:"domain,omitempty"`
+	// TTL is the TTL of records that do not set one
+	TTL     dnsrecord.TTL `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Records []Record      `yaml:"records" json:"records"`
+}
+
+// Record is one record of a desired-state file
+type Record struct {
+	Hostname string        `yaml:"hostname" json:"hostname"`
+	Type     string        `yaml:"type" json:"type"`
+	Value    string        `yaml:"value" json:"value"`
+	TTL      dnsrecord.TTL `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	MXPref   int           `yaml:"mx_pref,omitempty" json:"mx_pref,omitempty"`
+}
+
+// FromRecords returns the desired-state file holding records, the records of
+// domainName. Parsing it yields the same records.
+func FromRecords(domainName string, records []dnsrecord.Record) File {
+	file := File{Domain: domainName, Records: make([]Record, 0, len(records))}
+	for _, r := range records {
+		file.Records = append(file.Records, Record{
+			Hostname: r.HostName,
+			Type:     r.RecordType,
+			Value:    r.Address,
+			TTL:      dnsrecord.TTL(r.TTL),
+			MXPref:   r.MXPref,
+		})
+	}
+	return file
+}
+
+// ParseFile parses the desired-state file at path; see Parse
+func ParseFile(path, domainName string) ([]dnsrecord.Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return Parse(data, domainName)
+}
+
+// Parse reads a desired-state file in YAML or JSON for domainName and returns
+// its records, with host names relative to the domain ("@" for the apex) and
+// record types in upper case
+func Parse(data []byte, domainName string) ([]dnsrecord.Record, error) {
+	var file File
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '-') {
+		if err := yaml.Unmarshal(data, &file.Records); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	if file.Domain != "" && !strings.EqualFold(strings.TrimSuffix(file.Domain, "."), domainName) {
+		return nil, fmt.Errorf("state file is for %s, not %s", file.Domain, domainName)
+	}
+
+	records := make([]dnsrecord.Record, 0, len(file.Records))
+	for i, r := range file.Records {
+		if r.Hostname == "" || r.Type == "" || r.Value == "" {
+			return nil, fmt.Errorf("record %d: hostname, type and value are required", i+1)
+		}
+		ttl := int(r.TTL)
+		if ttl == 0 {
+			ttl = int(file.TTL)
+		}
+		records = append(records, dnsrecord.Record{
+			HostName:   relativeName(r.Hostname, domainName),
+			RecordType: strings.ToUpper(r.Type),
+			Address:    r.Value,
+			TTL:        ttl,
+			MXPref:     r.MXPref,
+		})
+	}
+	return records, nil
+}
+
+// relativeName returns hostname relative to domainName, so fully qualified
+// names such as "www.example.com." can be used as well
+func relativeName(hostname, domainName string) string {
+	name := strings.TrimSuffix(hostname, ".")
+	if strings.EqualFold(name, domainName) {
+		return "@"
+	}
+	suffix := "." + strings.ToLower(domainName)
+	if strings.HasSuffix(strings.ToLower(name), suffix) {
+		return name[:len(name)-len(suffix)]
+	}
+	return name
+}
@@ -0,0 +1,73 @@
+package zonestate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"zonekit/pkg/dnsrecord"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParse_YAML(t *testing.T) {
+	records, err := Parse([]byte(`
+domain: example.com
+ttl: 30m
+records:
+  - hostname: "@"
+    type: a
+    value: 192.0.2.1
+  - hostname: www.example.com.
+    type: CNAME
+    value: example.com.
+    ttl: 300
+  - hostname: mail
+    type: MX
+    value: mx.example.com
+    mx_pref: 10
+`), "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "www", RecordType: "CNAME", Address: "example.com.", TTL: 300},
+		{HostName: "mail", RecordType: "MX", Address: "mx.example.com", TTL: 1800, MXPref: 10},
+	}, records)
+}
+
+func TestParse_JSONList(t *testing.T) {
+	records, err := Parse([]byte(`[{"hostname": "example.com", "type": "TXT", "value": "v=spf1 -all", "ttl": "1h"}]`), "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []dnsrecord.Record{{HostName: "@", RecordType: "TXT", Address: "v=spf1 -all", TTL: 3600}}, records)
+}
+
+func TestFromRecords_RoundTrips(t *testing.T) {
+	records := []dnsrecord.Record{
+		{HostName: "@", RecordType: "A", Address: "192.0.2.1", TTL: 1800},
+		{HostName: "mail", RecordType: "MX", Address: "mx.example.com.", TTL: 3600, MXPref: 10},
+		{HostName: "@", RecordType: "TXT", Address: `v=spf1 include:"x" -all`, TTL: 300},
+	}
+
+	data, err := json.Marshal(FromRecords("example.com", records))
+	require.NoError(t, err)
+	parsed, err := Parse(data, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, records, parsed)
+
+	data, err = yaml.Marshal(FromRecords("example.com", records))
+	require.NoError(t, err)
+	parsed, err = Parse(data, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, records, parsed)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte("domain: example.org\nrecords: []\n"), "example.com")
+	require.ErrorContains(t, err, "state file is for example.org")
+
+	_, err = Parse([]byte("records:\n  - hostname: www\n    type: A\n"), "example.com")
+	require.ErrorContains(t, err, "record 1: hostname, type and value are required")
+
+	_, err = Parse([]byte("records:\n  - {hostname: www, type: A, value: 192.0.2.1, ttl: soon}\n"), "example.com")
+	require.Error(t, err)
+}